@@ -0,0 +1,28 @@
+package main
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+
+	"github.com/coocood/freecache"
+	"github.com/sirupsen/logrus"
+)
+
+// RepairGaps fetches the blocks of the gaps from the node again and runs all transforms for them
+func RepairGaps(bt *db.Bigtable, client *rpc.ErigonClient, gaps []db.BlockGap, concurrencyBlocks, concurrencyData int64, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error), cache *freecache.Cache) error {
+	for _, gap := range gaps {
+		logrus.Infof("repairing gap of blocks %v to %v", gap.Start, gap.End)
+
+		_, err := IndexFromNode(bt, client, int64(gap.Start), int64(gap.End), int64(gap.Start), concurrencyBlocks, transforms)
+		if err != nil {
+			return err
+		}
+		err = IndexFromBigtable(bt, int64(gap.Start), int64(gap.End), transforms, concurrencyData, cache)
+		cache.Clear()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/erc20"
+	"eth2-exporter/price"
 	"eth2-exporter/rpc"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
@@ -17,15 +18,20 @@ import (
 	"io/ioutil"
 	"math/big"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis/v8"
 	_ "github.com/jackc/pgx/v4/stdlib"
-	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
 
@@ -52,6 +58,10 @@ func main() {
 	checkDataGaps := flag.Bool("data.gaps", false, "Check for gaps in the data table")
 	checkDataGapsLookback := flag.Int("data.gaps.lookback", 1000000, "Lookback for gaps check of the blocks table")
 
+	repairGaps := flag.Bool("gaps.repair", false, "Index the blocks of the gaps found by the gaps checks again instead of only reporting them")
+	repairGapsInterval := flag.Duration("gaps.repair.interval", 0, "Interval in which the index loop checks the latest blocks for gaps and repairs them, 0 disables the check")
+	repairGapsLookback := flag.Int("gaps.repair.lookback", 100000, "Lookback for the gaps check of the index loop")
+
 	enableBalanceUpdater := flag.Bool("balances.enabled", false, "Enable balance update process")
 	enableFullBalanceUpdater := flag.Bool("balances.full.enabled", false, "Enable full balance update process")
 	balanceUpdaterBatchSize := flag.Int("balances.batch", 1000, "Batch size for balance updates")
@@ -60,8 +70,24 @@ func main() {
 	tokenPriceExportList := flag.String("token.price.list", "", "Tokenlist path to use for the token price export")
 	tokenPriceExportFrequency := flag.Duration("token.price.frequency", time.Hour, "Token price export interval")
 
+	enableContractVerification := flag.Bool("contracts.verification.enabled", false, "Enable fetching the verified source of newly deployed contracts")
+	contractVerificationBatchSize := flag.Int("contracts.verification.batch", 100, "Number of contracts looked up per index run")
+
+	enableNFTMetadata := flag.Bool("nft.metadata.enabled", false, "Enable resolving the queued metadata and thumbnails of nft tokens")
+	nftMetadataBatchSize := flag.Int64("nft.metadata.batch", 100, "Number of nft tokens resolved per index run")
+
+	enableArchive := flag.Bool("archive.enabled", false, "Enable moving old index rows to the archive table")
+	archiveAge := flag.Duration("archive.age", time.Hour*24*365*2, "Age from which on index rows are moved to the archive table")
+	archiveBatchSize := flag.Int64("archive.batch", 10000, "Maximum number of index rows read per index run when moving rows to the archive table")
+
+	enableStream := flag.Bool("stream.enabled", false, "Enable publishing newly indexed blocks to the websocket stream of the frontends through the redis cache")
+
+	enabledPlugins := flag.String("plugins", "", fmt.Sprintf("Comma separated list of the transformer plugins to run, registered plugins: %v", strings.Join(db.RegisteredTransformerPlugins(), ", ")))
+
 	bigtableProject := flag.String("bigtable.project", "", "Bigtable project")
 	bigtableInstance := flag.String("bigtable.instance", "", "Bigtable instance")
+	bigtableWriteConcurrency := flag.Int("bigtable.write.concurrency", 4, "Number of chunks of mutations written to bigtable concurrently")
+	bigtableWriteRetries := flag.Int("bigtable.write.retries", 5, "Number of retries of mutations that failed with a transient bigtable error")
 
 	versionFlag := flag.Bool("version", false, "Print version and exit")
 
@@ -116,6 +142,14 @@ func main() {
 		utils.LogFatal(err, "erigon client creation error", 0)
 	}
 
+	if *enableNFTMetadata {
+		// the token uris are looked up via eth_call on the erigon node
+		rpc.CurrentGethClient, err = rpc.NewGethClient(*erigonEndpoint)
+		if err != nil {
+			utils.LogFatal(err, "geth client creation error", 0)
+		}
+	}
+
 	chainId := strconv.FormatUint(utils.Config.Chain.Config.DepositChainID, 10)
 
 	balanceUpdaterPrefix := chainId + ":B:"
@@ -129,11 +163,26 @@ func main() {
 		logrus.Fatalf("node chain id mismatch, wanted %v got %v", chainId, nodeChainId.String())
 	}
 
+	if *enableArchive && !utils.Config.Bigtable.Archive {
+		logrus.Fatalf("moving index rows to the archive table requires the archive table to be enabled in the bigtable config")
+	}
+
+	// the written mutations use column families that do not exist on deployments created before them
+	db.MustInitBigtableAdmin(context.Background(), *bigtableProject, *bigtableInstance)
+	missing, err := db.BigAdminClient.MissingSchema()
+	if err != nil {
+		logrus.Fatalf("error reading the bigtable schema: %v", err)
+	}
+	if len(missing) > 0 {
+		logrus.Fatalf("the bigtable schema is missing %v, run the bigtable migrations with -schema first", strings.Join(missing, ", "))
+	}
+
 	bt, err := db.InitBigtable(*bigtableProject, *bigtableInstance, chainId)
 	if err != nil {
 		logrus.Fatalf("error connecting to bigtable: %v", err)
 	}
 	defer bt.Close()
+	bt.SetWriteBulkOptions(*bigtableWriteConcurrency, *bigtableWriteRetries)
 
 	if *tokenPriceExport {
 		go func() {
@@ -195,13 +244,48 @@ func main() {
 		bt.TransformERC20,
 		bt.TransformERC721,
 		bt.TransformERC1155,
+		bt.TransformLogs,
 		bt.TransformUncle,
-		bt.TransformWithdrawals)
+		bt.TransformWithdrawals,
+		bt.TransformContractInteractions,
+		bt.TransformNFTMints,
+		bt.TransformNFTHolders,
+		bt.TransformTokenBalances,
+		bt.TransformEns,
+		bt.TransformContractCreations,
+		bt.TransformFundings)
+
+	if utils.Config.WhaleAlerts.EtherThreshold > 0 || len(utils.Config.WhaleAlerts.TokenThresholds) > 0 {
+		transforms = append(transforms, bt.TransformWhaleTransfers)
+	}
+
+	if *enabledPlugins != "" {
+		names := strings.Split(*enabledPlugins, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		plugins, err := bt.InitTransformerPlugins(names)
+		if err != nil {
+			logrus.WithError(err).Fatalf("error initializing transformer plugins")
+		}
+		defer plugins.Close()
+		// the indexer runs until it is stopped, the plugins are closed on the signal
+		go func() {
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+			<-stop
+			logrus.Infof("stopping, closing transformer plugins")
+			plugins.Close()
+			os.Exit(0)
+		}()
+		transforms = append(transforms, plugins.Transforms()...)
+		logrus.Infof("running transformer plugins %v", strings.Join(names, ", "))
+	}
 
 	cache := freecache.NewCache(100 * 1024 * 1024) // 100 MB limit
 
 	if *block != 0 {
-		err = IndexFromNode(bt, client, *block, *block, *concurrencyBlocks)
+		_, err = IndexFromNode(bt, client, *block, *block, *block, *concurrencyBlocks, transforms)
 		if err != nil {
 			logrus.WithError(err).Fatalf("error indexing from node, start: %v end: %v concurrency: %v", *block, *block, *concurrencyBlocks)
 		}
@@ -215,18 +299,36 @@ func main() {
 		return
 	}
 
-	if *checkBlocksGaps {
-		bt.CheckForGapsInBlocksTable(*checkBlocksGapsLookback)
-		return
-	}
+	if *checkBlocksGaps || *checkDataGaps {
+		gaps := []db.BlockGap{}
+		if *checkBlocksGaps {
+			blocksGaps, err := bt.CheckForGapsInBlocksTable(context.Background(), *checkBlocksGapsLookback)
+			if err != nil {
+				logrus.WithError(err).Fatalf("error checking for gaps in the blocks table")
+			}
+			gaps = append(gaps, blocksGaps...)
+		}
+		if *checkDataGaps {
+			dataGaps, err := bt.CheckForGapsInDataTable(context.Background(), *checkDataGapsLookback)
+			if err != nil {
+				logrus.WithError(err).Fatalf("error checking for gaps in the data table")
+			}
+			gaps = append(gaps, dataGaps...)
+		}
+		logrus.Infof("found %v gaps", len(gaps))
 
-	if *checkDataGaps {
-		bt.CheckForGapsInDataTable(*checkDataGapsLookback)
+		if *repairGaps {
+			err = RepairGaps(bt, client, gaps, *concurrencyBlocks, *concurrencyData, transforms, cache)
+			if err != nil {
+				logrus.WithError(err).Fatalf("error repairing gaps")
+			}
+			logrus.Infof("repaired %v gaps", len(gaps))
+		}
 		return
 	}
 
 	if *endBlocks != 0 && *startBlocks < *endBlocks {
-		err = IndexFromNode(bt, client, *startBlocks, *endBlocks, *concurrencyBlocks)
+		_, err = IndexFromNode(bt, client, *startBlocks, *endBlocks, *endBlocks-int64(*reorgDepth), *concurrencyBlocks, transforms)
 		if err != nil {
 			logrus.WithError(err).Fatalf("error indexing from node, start: %v end: %v concurrency: %v", *startBlocks, *endBlocks, *concurrencyBlocks)
 		}
@@ -242,9 +344,24 @@ func main() {
 		return
 	}
 
+	var streamClient *redis.Client
+	if *enableStream {
+		streamClient, err = services.NewEth1StreamClient(context.Background())
+		if err != nil {
+			logrus.WithError(err).Fatalf("error connecting to the eth1 stream")
+		}
+	}
+
 	lastSuccessulBlockIndexingTs := time.Now()
+	lastGapsRepairTs := time.Now()
+	archiveCursor := ""
+	summaryStartMarked := false
+	// the blocks indexed before the start are not published
+	lastPublishedBlock := int64(-1)
+	// the blocks indexed before the start are not purged from the edge cache either
+	lastPurgedBlock := int64(-1)
 	for ; ; time.Sleep(time.Second * 14) {
-		err := HandleChainReorgs(bt, client, *reorgDepth)
+		err := HandleChainReorgs(bt, client, *reorgDepth, transforms)
 		if err != nil {
 			logrus.Errorf("error handling chain reorgs: %v", err)
 			continue
@@ -256,13 +373,13 @@ func main() {
 			continue
 		}
 
-		lastBlockFromBlocksTable, err := bt.GetLastBlockInBlocksTable()
+		lastBlockFromBlocksTable, err := bt.GetLastBlockInBlocksTable(context.Background())
 		if err != nil {
 			logrus.Errorf("error retrieving last blocks from blocks table: %v", err)
 			continue
 		}
 
-		lastBlockFromDataTable, err := bt.GetLastBlockInDataTable()
+		lastBlockFromDataTable, err := bt.GetLastBlockInDataTable(context.Background())
 		if err != nil {
 			logrus.Errorf("error retrieving last blocks from data table: %v", err)
 			continue
@@ -276,10 +393,11 @@ func main() {
 			},
 		).Infof("last blocks")
 
+		replacedFrom := int64(-1)
 		if lastBlockFromBlocksTable < int(lastBlockFromNode) {
 			logrus.Infof("missing blocks %v to %v in blocks table, indexing ...", lastBlockFromBlocksTable, lastBlockFromNode)
 
-			err = IndexFromNode(bt, client, int64(lastBlockFromBlocksTable)-*offsetBlocks, int64(lastBlockFromNode), *concurrencyBlocks)
+			replacedFrom, err = IndexFromNode(bt, client, int64(lastBlockFromBlocksTable)-*offsetBlocks, int64(lastBlockFromNode), int64(lastBlockFromNode)-int64(*reorgDepth), *concurrencyBlocks, transforms)
 			if err != nil {
 				errMsg := "error indexing from node"
 				errFields := map[string]interface{}{
@@ -297,11 +415,30 @@ func main() {
 			}
 		}
 
-		if lastBlockFromDataTable < int(lastBlockFromNode) {
+		dataStart := int64(lastBlockFromDataTable) - *offsetData
+		if replacedFrom >= 0 && replacedFrom < dataStart {
+			// the canonical blocks replacing orphaned ones are below the regular offset and have to be indexed again
+			dataStart = replacedFrom
+		}
+		if lastBlockFromDataTable < int(lastBlockFromNode) || replacedFrom >= 0 {
 			// transforms = append(transforms, bt.TransformTx)
 
-			logrus.Infof("missing blocks %v to %v in data table, indexing ...", lastBlockFromDataTable, lastBlockFromNode)
-			err = IndexFromBigtable(bt, int64(lastBlockFromDataTable)-*offsetData, int64(lastBlockFromNode), transforms, *concurrencyData, cache)
+			if !summaryStartMarked {
+				// the summary deltas are written from the first block indexed by the data indexing on, a start recorded before is kept
+				summaryStart := dataStart
+				if summaryStart < 0 {
+					summaryStart = 0
+				}
+				err = bt.MarkAddressSummaryStart(context.Background(), uint64(summaryStart))
+				if err != nil {
+					logrus.WithError(err).Errorf("error marking the address summary start")
+				} else {
+					summaryStartMarked = true
+				}
+			}
+
+			logrus.Infof("missing blocks %v to %v in data table, indexing ...", dataStart, lastBlockFromNode)
+			err = IndexFromBigtable(bt, dataStart, int64(lastBlockFromNode), transforms, *concurrencyData, cache)
 			if err != nil {
 				logrus.WithError(err).Errorf("error indexing from bigtable")
 				cache.Clear()
@@ -310,10 +447,127 @@ func main() {
 			cache.Clear()
 		}
 
+		if streamClient != nil {
+			if lastPublishedBlock < 0 {
+				lastPublishedBlock = int64(lastBlockFromDataTable)
+			}
+			if lastPublishedBlock < int64(lastBlockFromNode) {
+				err = services.PublishEth1StreamBlocks(context.Background(), streamClient, bt, uint64(lastPublishedBlock+1), lastBlockFromNode)
+				if err != nil {
+					logrus.WithError(err).Errorf("error publishing blocks to the eth1 stream")
+				}
+				// blocks that could not be published are skipped, the stream only carries live updates
+				lastPublishedBlock = int64(lastBlockFromNode)
+			}
+		}
+
+		if services.EdgeCachePurgeEnabled() {
+			if lastPurgedBlock < 0 {
+				lastPurgedBlock = int64(lastBlockFromDataTable)
+			}
+			if lastPurgedBlock < int64(lastBlockFromNode) {
+				err = services.PurgeEdgeCacheBlocks(context.Background(), bt, uint64(lastPurgedBlock+1), lastBlockFromNode)
+				if err != nil {
+					logrus.WithError(err).Errorf("error purging indexed blocks from the edge cache")
+				}
+				// the responses of blocks that could not be purged expire with the max age of the edge cache
+				lastPurgedBlock = int64(lastBlockFromNode)
+			}
+		}
+
+		if *repairGapsInterval > 0 && time.Since(lastGapsRepairTs) > *repairGapsInterval {
+			// blocks missing in the blocks table are also missing in the data table, checking the data table covers both
+			gaps, err := bt.CheckForGapsInDataTable(context.Background(), *repairGapsLookback)
+			if err != nil {
+				logrus.WithError(err).Errorf("error checking for gaps in the data table")
+			} else if len(gaps) > 0 {
+				err = RepairGaps(bt, client, gaps, *concurrencyBlocks, *concurrencyData, transforms, cache)
+				if err != nil {
+					logrus.WithError(err).Errorf("error repairing gaps")
+				}
+			}
+			lastGapsRepairTs = time.Now()
+		}
+
 		if *enableBalanceUpdater {
 			ProcessMetadataUpdates(bt, client, balanceUpdaterPrefix, *balanceUpdaterBatchSize, 10)
 		}
 
+		ensUpdates, err := bt.ProcessEnsUpdates(context.Background(), 10000)
+		if err != nil {
+			logrus.WithError(err).Errorf("error processing ens updates")
+		} else if ensUpdates > 0 {
+			logrus.Infof("processed %v ens updates", ensUpdates)
+		}
+
+		contractDestructions, err := bt.ProcessContractDestructions(context.Background(), 10000)
+		if err != nil {
+			logrus.WithError(err).Errorf("error processing contract destructions")
+		} else if contractDestructions > 0 {
+			logrus.Infof("processed %v contract destructions", contractDestructions)
+		}
+
+		// the data indexing revisits the blocks within its offset, their summary, counterparty, contract interaction and burn deltas are only folded once they are out of reach
+		if finalized := int64(lastBlockFromDataTable) - *offsetData - int64(*reorgDepth); finalized > 0 {
+			summaries, err := bt.ProcessAddressSummaryUpdates(context.Background(), uint64(finalized), 10000)
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing address summary updates")
+			} else if summaries > 0 {
+				logrus.Infof("updated the summaries of %v addresses", summaries)
+			}
+
+			counterparties, err := bt.ProcessCounterpartyUpdates(context.Background(), uint64(finalized), 10000)
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing counterparty updates")
+			} else if counterparties > 0 {
+				logrus.Infof("updated the counterparty rollups of %v address pairs", counterparties)
+			}
+
+			interactions, err := bt.ProcessContractInteractionUpdates(context.Background(), uint64(finalized), 10000)
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing contract interaction updates")
+			} else if interactions > 0 {
+				logrus.Infof("updated the contract interactions of %v address pairs", interactions)
+			}
+
+			burned, err := bt.ProcessBurnedFeesUpdates(context.Background(), uint64(finalized))
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing burned fees updates")
+			} else if burned > 0 {
+				logrus.Infof("added the burned fees of %v blocks to the aggregation", burned)
+			}
+		}
+
+		if *enableContractVerification {
+			verified, err := bt.ProcessContractVerifications(context.Background(), *contractVerificationBatchSize)
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing contract verifications")
+			} else if verified > 0 {
+				logrus.Infof("fetched the verified source of %v contracts", verified)
+			}
+		}
+
+		if *enableNFTMetadata {
+			resolved, err := bt.ProcessNFTMetadataUpdates(context.Background(), *nftMetadataBatchSize)
+			if err != nil {
+				logrus.WithError(err).Errorf("error processing nft metadata updates")
+			} else if resolved > 0 {
+				logrus.Infof("resolved the metadata of %v nft tokens", resolved)
+			}
+		}
+
+		if *enableArchive {
+			next, archived, err := bt.ArchiveIndexRows(context.Background(), archiveCursor, time.Now().Add(-*archiveAge), *archiveBatchSize)
+			if err != nil {
+				logrus.WithError(err).Errorf("error moving index rows to the archive table")
+			} else {
+				if archived > 0 {
+					logrus.Infof("moved %v index rows to the archive table", archived)
+				}
+				archiveCursor = next
+			}
+		}
+
 		logrus.Infof("index run completed")
 		services.ReportStatus("eth1indexer", "Running", nil)
 	}
@@ -335,61 +589,33 @@ func UpdateTokenPrices(bt *db.Bigtable, client *rpc.ErigonClient, tokenListPath
 		return err
 	}
 
-	type defillamaPriceRequest struct {
-		Coins []string `json:"coins"`
-	}
-	coinsList := make([]string, 0, len(tokenList.Tokens))
+	tokens := make(map[common.Address]uint8, len(tokenList.Tokens))
 	for _, token := range tokenList.Tokens {
-		coinsList = append(coinsList, "ethereum:"+token.Address)
-	}
-
-	req := &defillamaPriceRequest{
-		Coins: coinsList,
-	}
-
-	reqEncoded, err := json.Marshal(req)
-	if err != nil {
-		return err
-	}
-
-	httpClient := &http.Client{Timeout: time.Second * 10}
-
-	resp, err := httpClient.Post("https://coins.llama.fi/prices", "application/json", bytes.NewReader(reqEncoded))
-	if err != nil {
-		return err
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("error querying defillama api: %v", resp.Status)
-	}
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	type defillamaCoin struct {
-		Decimals  int64            `json:"decimals"`
-		Price     *decimal.Decimal `json:"price"`
-		Symbol    string           `json:"symbol"`
-		Timestamp int64            `json:"timestamp"`
+		if !common.IsHexAddress(token.Address) {
+			continue
+		}
+		tokens[common.HexToAddress(token.Address)] = uint8(token.Decimals)
 	}
 
-	type defillamaResponse struct {
-		Coins map[string]defillamaCoin `json:"coins"`
+	// the uniswap fallback only knows the pools of mainnet
+	var caller bind.ContractCaller
+	if utils.Config.Chain.Config.DepositChainID == 1 {
+		caller = client.GetNativeClient()
 	}
-
-	respParsed := &defillamaResponse{}
-	err = json.Unmarshal(body, respParsed)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*10)
+	defer cancel()
+	prices, err := price.FetchTokenPrices(ctx, caller, tokens)
 	if err != nil {
 		return err
 	}
 
-	tokenPrices := make([]*types.ERC20TokenPrice, 0, len(respParsed.Coins))
-	for address, data := range respParsed.Coins {
+	now := time.Now()
+	tokenPrices := make([]*types.ERC20TokenPrice, 0, len(prices))
+	for token, p := range prices {
 		tokenPrices = append(tokenPrices, &types.ERC20TokenPrice{
-			Token: common.FromHex(strings.TrimPrefix(address, "ethereum:0x")),
-			Price: []byte(data.Price.String()),
+			Token:     token.Bytes(),
+			Price:     []byte(p.String()),
+			Timestamp: now,
 		})
 	}
 
@@ -413,10 +639,10 @@ func UpdateTokenPrices(bt *db.Bigtable, client *rpc.ErigonClient, tokenListPath
 		return err
 	}
 
-	return bt.SaveERC20TokenPrices(tokenPrices)
+	return bt.SaveERC20TokenPrices(context.Background(), tokenPrices)
 }
 
-func HandleChainReorgs(bt *db.Bigtable, client *rpc.ErigonClient, depth int) error {
+func HandleChainReorgs(bt *db.Bigtable, client *rpc.ErigonClient, depth int, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) error {
 	ctx := context.Background()
 	// get latest block from the node
 	latestNodeBlock, err := client.GetNativeClient().BlockByNumber(ctx, nil)
@@ -432,7 +658,7 @@ func HandleChainReorgs(bt *db.Bigtable, client *rpc.ErigonClient, depth int) err
 			return err
 		}
 
-		dbBlock, err := bt.GetBlockFromBlocksTable(i)
+		dbBlock, err := bt.GetBlockFromBlocksTable(context.Background(), i)
 		if err != nil {
 			if err == db.ErrBlockNotFound { // exit if we hit a block that is not yet in the db
 				return nil
@@ -445,18 +671,42 @@ func HandleChainReorgs(bt *db.Bigtable, client *rpc.ErigonClient, depth int) err
 
 			// delete all blocks starting from the fork block up to the latest block in the db
 			for j := i; j <= latestNodeBlockNumber; j++ {
-				dbBlock, err := bt.GetBlockFromBlocksTable(j)
+				dbBlock, err := bt.GetBlockFromBlocksTable(context.Background(), j)
 				if err != nil {
 					if err == db.ErrBlockNotFound { // exit if we hit a block that is not yet in the db
 						return nil
 					}
 					return err
 				}
+
+				reorg := &types.Reorg{
+					Layer:     types.ExecutionLayerReorg,
+					Height:    dbBlock.Number,
+					Hash:      dbBlock.Hash,
+					Depth:     j - i + 1,
+					BlockTime: dbBlock.Time.AsTime(),
+				}
+				if nodeBlock, err := client.GetNativeClient().HeaderByNumber(ctx, big.NewInt(int64(j))); err == nil {
+					reorg.CanonicalHash = nodeBlock.Hash().Bytes()
+				}
+				err = db.SaveReorg(reorg)
+				if err != nil {
+					logrus.Errorf("error saving reorg of block %v: %v", dbBlock.Number, err)
+				}
 				logrus.Infof("deleting block at height %v with hash %x", dbBlock.Number, dbBlock.Hash)
-				err = bt.DeleteBlock(dbBlock.Number, dbBlock.Hash)
+				err = bt.DeleteOrphanedBlock(context.Background(), dbBlock, transforms)
 				if err != nil {
 					return err
 				}
+				if services.EdgeCachePurgeEnabled() {
+					keys, err := db.EdgeCacheBlockKeys(dbBlock)
+					if err == nil {
+						err = services.PurgeEdgeCache(ctx, keys)
+					}
+					if err != nil {
+						logrus.Errorf("error purging orphaned block %v from the edge cache: %v", dbBlock.Number, err)
+					}
+				}
 			}
 		} else {
 			logrus.Infof("height %v, node block hash: %x, db block hash: %x", i, nodeBlock.Hash().Bytes(), dbBlock.Hash)
@@ -546,7 +796,7 @@ func ProcessMetadataUpdates(bt *db.Bigtable, client *rpc.ErigonClient, prefix st
 	its := 0
 	for {
 		start := time.Now()
-		keys, pairs, err := bt.GetMetadataUpdates(prefix, lastKey, batchSize)
+		keys, pairs, err := bt.GetMetadataUpdates(context.Background(), prefix, lastKey, batchSize)
 		if err != nil {
 			logrus.Errorf("error retrieving metadata updates from bigtable: %v", err)
 			return
@@ -560,6 +810,13 @@ func ProcessMetadataUpdates(bt *db.Bigtable, client *rpc.ErigonClient, prefix st
 		// 	logrus.Infof("retrieved balance %x for token %x of address %x", b.Balance, b.Token, b.Address)
 		// }
 
+		// the balances are retrieved at the latest block, it is only used to assign them to a balance snapshot interval
+		latestBlock, err := client.GetLatestEth1BlockNumber()
+		if err != nil {
+			logrus.Errorf("error retrieving latest block number from node: %v", err)
+			return
+		}
+
 		balances := make([]*types.Eth1AddressBalance, 0, len(pairs))
 		for b := 0; b < len(pairs); b += batchSize {
 			start := b
@@ -579,7 +836,13 @@ func ProcessMetadataUpdates(bt *db.Bigtable, client *rpc.ErigonClient, prefix st
 			balances = append(balances, b...)
 		}
 
-		err = bt.SaveBalances(balances, keys)
+		err = bt.SaveBalanceSnapshots(context.Background(), balances, latestBlock)
+		if err != nil {
+			logrus.Errorf("error saving balance snapshots to bigtable: %v", err)
+			return
+		}
+
+		err = bt.SaveBalances(context.Background(), balances, keys)
 		if err != nil {
 			logrus.Errorf("error saving balances to bigtable: %v", err)
 			return
@@ -654,16 +917,25 @@ func ProcessMetadataUpdates(bt *db.Bigtable, client *rpc.ErigonClient, prefix st
 	// }
 }
 
-func IndexFromNode(bt *db.Bigtable, client *rpc.ErigonClient, start, end, concurrency int64) error {
+// IndexFromNode saves the blocks from start to end to the blocks table. Blocks from replaceFrom on (the reorg window below the head) that
+// replace a different block at an already saved height have the rows of the orphaned block removed, the lowest replaced height is returned
+// (-1 if no block was replaced) so that the data table can be indexed again from there. Older blocks are saved without reading the blocks table.
+func IndexFromNode(bt *db.Bigtable, client *rpc.ErigonClient, start, end, replaceFrom, concurrency int64, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) (int64, error) {
 
 	g := new(errgroup.Group)
 	g.SetLimit(int(concurrency))
 
+	replacedFrom := int64(-1)
+	replacedMux := sync.Mutex{}
+
 	startTs := time.Now()
 	lastTickTs := time.Now()
 
 	processedBlocks := int64(0)
 
+	progress := startIndexProgress("blocks", start, end)
+	defer progress.stop()
+
 	for i := start; i <= end; i++ {
 
 		i := i
@@ -675,11 +947,36 @@ func IndexFromNode(bt *db.Bigtable, client *rpc.ErigonClient, start, end, concur
 			}
 
 			dbStart := time.Now()
-			err = bt.SaveBlock(bc)
+			var orphaned *types.Eth1Block
+			if i >= replaceFrom {
+				orphaned, err = bt.ReplaceBlock(context.Background(), bc, transforms)
+			} else {
+				err = bt.SaveBlock(context.Background(), bc)
+			}
 			if err != nil {
 				return fmt.Errorf("error saving block: %v to bigtable: %w", i, err)
 
 			}
+			if orphaned != nil {
+				err = db.SaveReorg(&types.Reorg{
+					Layer:         types.ExecutionLayerReorg,
+					Height:        orphaned.Number,
+					Hash:          orphaned.Hash,
+					CanonicalHash: bc.Hash,
+					Depth:         1,
+					BlockTime:     orphaned.Time.AsTime(),
+				})
+				if err != nil {
+					logrus.Errorf("error saving reorg of block %v: %v", orphaned.Number, err)
+				}
+
+				replacedMux.Lock()
+				if replacedFrom < 0 || i < replacedFrom {
+					replacedFrom = i
+				}
+				replacedMux.Unlock()
+			}
+			progress.blockProcessed(i)
 			current := atomic.AddInt64(&processedBlocks, 1)
 			if current%100 == 0 {
 				r := end - start
@@ -699,7 +996,8 @@ func IndexFromNode(bt *db.Bigtable, client *rpc.ErigonClient, start, end, concur
 
 	}
 
-	return g.Wait()
+	err := g.Wait()
+	return replacedFrom, err
 }
 
 func IndexFromBigtable(bt *db.Bigtable, start, end int64, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error), concurrency int64, cache *freecache.Cache) error {
@@ -711,12 +1009,15 @@ func IndexFromBigtable(bt *db.Bigtable, start, end int64, transforms []func(blk
 
 	processedBlocks := int64(0)
 
+	progress := startIndexProgress("data", start, end)
+	defer progress.stop()
+
 	logrus.Infof("fetching blocks from %d to %d", start, end)
 	for i := start; i <= end; i++ {
 		i := i
 		g.Go(func() error {
 
-			block, err := bt.GetBlockFromBlocksTable(uint64(i))
+			block, err := bt.GetBlockFromBlocksTable(context.Background(), uint64(i))
 			if err != nil {
 				return fmt.Errorf("error getting block: %v from bigtable blocks table err: %w", block, err)
 			}
@@ -726,7 +1027,7 @@ func IndexFromBigtable(bt *db.Bigtable, start, end int64, transforms []func(blk
 			for _, transform := range transforms {
 				mutsData, mutsMetadataUpdate, err := transform(block, cache)
 				if err != nil {
-					logrus.WithError(err).Error("error transforming block")
+					return fmt.Errorf("error transforming block %v: %w", block.Number, err)
 				}
 				bulkMutsData.Keys = append(bulkMutsData.Keys, mutsData.Keys...)
 				bulkMutsData.Muts = append(bulkMutsData.Muts, mutsData.Muts...)
@@ -737,25 +1038,37 @@ func IndexFromBigtable(bt *db.Bigtable, start, end int64, transforms []func(blk
 				}
 			}
 
+			err = bt.ValidateBlockMutations(block, &bulkMutsData, &bulkMutsMetadataUpdate)
+			if err != nil {
+				return err
+			}
+
+			pendingMutations := len(bulkMutsData.Muts) + len(bulkMutsMetadataUpdate.Muts)
+			progress.addPendingMutations(pendingMutations)
+			defer progress.addPendingMutations(-pendingMutations)
+
 			if len(bulkMutsData.Keys) > 0 {
 				metaKeys := strings.Join(bulkMutsData.Keys, ",") // save block keys in order to be able to handle chain reorgs
-				err = bt.SaveBlockKeys(block.Number, block.Hash, metaKeys)
+				err = bt.SaveBlockKeys(context.Background(), block.Number, block.Hash, metaKeys)
 				if err != nil {
 					return fmt.Errorf("error saving block keys to bigtable metadata updates table: %w", err)
 				}
 
-				err = bt.WriteBulk(&bulkMutsData, bt.GetDataTable())
+				// rows shared with an orphaned block of the same height have been tombstoned
+				db.ReviveRows(&bulkMutsData)
+				err = bt.WriteBulk(context.Background(), &bulkMutsData, bt.GetDataTable())
 				if err != nil {
 					return fmt.Errorf("error writing to bigtable data table: %w", err)
 				}
 			}
 
 			if len(bulkMutsMetadataUpdate.Keys) > 0 {
-				err = bt.WriteBulk(&bulkMutsMetadataUpdate, bt.GetMetadataUpdatesTable())
+				err = bt.WriteBulk(context.Background(), &bulkMutsMetadataUpdate, bt.GetMetadataUpdatesTable())
 				if err != nil {
 					return fmt.Errorf("error writing to bigtable metadata updates table: %w", err)
 				}
 			}
+			progress.blockProcessed(i)
 
 			current := atomic.AddInt64(&processedBlocks, 1)
 			if current%500 == 0 {
@@ -860,7 +1173,7 @@ func ImportMainnetERC20TokenMetadataFromTokenDirectory(bt *db.Bigtable) {
 		meta.OfficialSite = token.Extensions.Link
 		meta.Symbol = token.Symbol
 
-		err = bt.SaveERC20Metadata(address, meta)
+		err = bt.SaveERC20Metadata(context.Background(), address, meta)
 		if err != nil {
 			utils.LogFatal(err, "error while saving ERC20 metadata", 0)
 		}
@@ -895,6 +1208,6 @@ func ImportNameLabels(bt *db.Bigtable) {
 			continue
 		}
 		logrus.Infof("%v: %v", address, name.Name)
-		bt.SaveAddressName(common.FromHex(strings.TrimPrefix(address, "0x")), name.Name)
+		bt.SaveAddressName(context.Background(), common.FromHex(strings.TrimPrefix(address, "0x")), name.Name)
 	}
 }
@@ -0,0 +1,9 @@
+package main
+
+// Transformer plugins are linked into the indexer by blank importing their package here, the init function of the package
+// registers the plugin with db.RegisterTransformerPlugin. Registered plugins only run if they are enabled with the
+// -plugins flag, e.g. -plugins=uniswap_pools. See db.TransformerPlugin for the contract a plugin has to fulfill.
+//
+//	import (
+//		_ "example.com/indexer-plugins/uniswap"
+//	)
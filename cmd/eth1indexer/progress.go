@@ -0,0 +1,95 @@
+package main
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const indexProgressReportInterval = time.Second * 10
+
+// indexProgress instruments an indexing pipeline and periodically reports its progress to the db
+// where it is picked up by the indexer status page of the frontend
+type indexProgress struct {
+	name             string
+	start            int64
+	end              int64
+	startTs          time.Time
+	currentBlock     int64
+	processedBlocks  int64
+	pendingMutations int64
+	done             chan struct{}
+}
+
+func startIndexProgress(name string, start, end int64) *indexProgress {
+	p := &indexProgress{
+		name:    name,
+		start:   start,
+		end:     end,
+		startTs: time.Now(),
+		done:    make(chan struct{}),
+	}
+	go p.reporter()
+	return p
+}
+
+// blockProcessed marks a block as completely written, the current block is the highest processed block
+func (p *indexProgress) blockProcessed(number int64) {
+	atomic.AddInt64(&p.processedBlocks, 1)
+	for {
+		current := atomic.LoadInt64(&p.currentBlock)
+		if number <= current || atomic.CompareAndSwapInt64(&p.currentBlock, current, number) {
+			return
+		}
+	}
+}
+
+// addPendingMutations tracks the mutations that have been transformed but not yet written, use a negative delta once written
+func (p *indexProgress) addPendingMutations(delta int) {
+	atomic.AddInt64(&p.pendingMutations, int64(delta))
+}
+
+func (p *indexProgress) stop() {
+	close(p.done)
+}
+
+func (p *indexProgress) reporter() {
+	ticker := time.NewTicker(indexProgressReportInterval)
+	defer ticker.Stop()
+
+	lastProcessed := int64(0)
+	lastTs := p.startTs
+	for {
+		select {
+		case <-p.done:
+			p.report(lastProcessed, lastTs)
+			return
+		case <-ticker.C:
+			lastProcessed, lastTs = p.report(lastProcessed, lastTs)
+		}
+	}
+}
+
+func (p *indexProgress) report(lastProcessed int64, lastTs time.Time) (int64, time.Time) {
+	now := time.Now()
+	processed := atomic.LoadInt64(&p.processedBlocks)
+
+	err := db.SaveIndexerProgress(&types.IndexerProgress{
+		Name:             p.name,
+		StartBlock:       p.start,
+		EndBlock:         p.end,
+		CurrentBlock:     atomic.LoadInt64(&p.currentBlock),
+		ProcessedBlocks:  processed,
+		BlocksPerSecond:  float64(processed-lastProcessed) / now.Sub(lastTs).Seconds(),
+		PendingMutations: atomic.LoadInt64(&p.pendingMutations),
+		StartedTime:      p.startTs,
+		UpdatedTime:      now,
+	})
+	if err != nil {
+		logrus.Errorf("error reporting %v indexer progress: %v", p.name, err)
+	}
+	return processed, now
+}
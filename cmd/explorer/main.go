@@ -35,7 +35,6 @@ import (
 	"github.com/gorilla/csrf"
 	"github.com/gorilla/mux"
 	_ "github.com/jackc/pgx/v4/stdlib"
-	"github.com/phyber/negroni-gzip/gzip"
 	"github.com/stripe/stripe-go/v72"
 	"github.com/urfave/negroni"
 	"github.com/zesik/proxyaddr"
@@ -164,6 +163,11 @@ func main() {
 			logrus.Fatalf("error connecting to bigtable: %v", err)
 		}
 		db.BigtableClient = bt
+
+		// networks without a bigtable of their own share the connection of the frontend
+		if len(utils.Config.Frontend.Networks) > 0 {
+			db.MustInitNetworks(utils.Config.Frontend.Networks)
+		}
 	}()
 
 	if utils.Config.TieredCacheProvider == "redis" || len(utils.Config.RedisCacheEndpoint) != 0 {
@@ -312,6 +316,10 @@ func main() {
 		apiV1Router.HandleFunc("/validator/eth1/{address}", handlers.ApiValidatorByEth1Address).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validator/withdrawalCredentials/{withdrawalCredentialsOrEth1address}", handlers.ApiWithdrawalCredentialsValidators).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/validators/queue", handlers.ApiValidatorQueue).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/benchmark", handlers.ApiValidatorBenchmark).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/slareport", handlers.ApiValidatorSlaReport).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/slareport/job/{id}", handlers.ApiValidatorSlaReportJob).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/validators/slareport/job/{id}/download", handlers.ApiValidatorSlaReportDownload).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/graffitiwall", handlers.ApiGraffitiwall).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/chart/{chart}", handlers.ApiChart).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/user/token", handlers.APIGetToken).Methods("POST", "OPTIONS")
@@ -329,19 +337,50 @@ func main() {
 		apiV1Router.HandleFunc("/ethstore/{day}", handlers.ApiEthStoreDay).Methods("GET", "OPTIONS")
 
 		apiV1Router.HandleFunc("/execution/gasnow", handlers.ApiEth1GasNowData).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/gas/history", handlers.ApiEth1GasHistory).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/burn", handlers.ApiEth1Burn).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/movements", handlers.ApiEth1TopMovements).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/stats/txtypes", handlers.ApiEth1TxTypeStats).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/stats/contracts", handlers.ApiEth1ContractDeploymentStats).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/create2", handlers.ApiCreate2).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/aa/{role}", handlers.ApiAAOperators).Methods("GET", "OPTIONS")
 		// query params: token
 		apiV1Router.HandleFunc("/execution/block/{blockNumber}", handlers.ApiETH1ExecBlocks).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/tx/{hash}", handlers.ApiEth1TxByHash).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/tx/{hash}/fees", handlers.ApiEth1TxFees).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/transactions", handlers.ApiEth1Transactions).Methods("POST", "OPTIONS")
+		apiV1Router.HandleFunc("/ens/{name}", handlers.ApiEnsLookup).Methods("GET", "OPTIONS")
 		apiV1Router.HandleFunc("/execution/{addressIndexOrPubkey}/produced", handlers.ApiETH1AccountProducedBlocks).Methods("GET", "OPTIONS")
 
-		apiV1Router.HandleFunc("/execution/address/{address}", handlers.ApiEth1Address).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/execution/address/{address}/transactions", handlers.ApiEth1AddressTx).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/execution/address/{address}/internalTx", handlers.ApiEth1AddressItx).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/execution/address/{address}/blocks", handlers.ApiEth1AddressBlocks).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/execution/address/{address}/uncles", handlers.ApiEth1AddressUncles).Methods("GET", "OPTIONS")
-		apiV1Router.HandleFunc("/execution/address/{address}/tokens", handlers.ApiEth1AddressTokens).Methods("GET", "OPTIONS")
+		// the address endpoints read from the bigtable of the network selected with ?chainId=
+		apiV1Router.HandleFunc("/execution/address/{address}", handlers.ApiChainSelector(handlers.ApiEth1Address)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/balance", handlers.ApiEth1AddressBalanceAt).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/transactions", handlers.ApiChainSelector(handlers.ApiEth1AddressTx)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/internalTx", handlers.ApiChainSelector(handlers.ApiEth1AddressItx)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/blocks", handlers.ApiChainSelector(handlers.ApiEth1AddressBlocks)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/uncles", handlers.ApiChainSelector(handlers.ApiEth1AddressUncles)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/tokens", handlers.ApiChainSelector(handlers.ApiEth1AddressTokens)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/tokens/balances", handlers.ApiChainSelector(handlers.ApiEth1AddressTokenBalances)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/logs", handlers.ApiChainSelector(handlers.ApiEth1AddressLogs)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/counterparties", handlers.ApiChainSelector(handlers.ApiEth1AddressCounterparties)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/address/{address}/contracts", handlers.ApiChainSelector(handlers.ApiEth1AddressContracts)).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/transferPath", handlers.ApiEth1TransferPath).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/collection/{address}", handlers.ApiNFTCollection).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/execution/collection/{address}/transfers", handlers.ApiNFTCollectionTransfers).Methods("GET", "OPTIONS")
+		apiV1Router.HandleFunc("/nft/{contract}/{tokenId}/metadata", handlers.ApiNFTMetadata).Methods("GET", "OPTIONS")
+		if utils.Config.Frontend.Snapshots.Enabled {
+			apiV1Router.HandleFunc("/snapshots/{name}", handlers.ApiSnapshot).Methods("GET", "OPTIONS")
+		}
+		if utils.Config.Frontend.NodeCrawler.Enabled {
+			apiV1Router.HandleFunc("/network/crawler", handlers.ApiNodeCrawlerReport).Methods("POST")
+			apiV1Router.HandleFunc("/network/nodes", handlers.ApiNetworkNodes).Methods("GET", "OPTIONS")
+		}
+		if utils.Config.Frontend.RpcProxy.Enabled {
+			apiV1Router.HandleFunc("/execution/rpc", handlers.ApiEth1RpcProxy).Methods("POST", "OPTIONS")
+		}
+		apiV1Router.HandleFunc("/execution/transferPath/job/{id}", handlers.ApiEth1TransferPathJob).Methods("GET", "OPTIONS")
 		// // query params: type={erc20,erc721,erc1155}, address
 
-		// apiV1Router.HandleFunc("/execution/transactions", handlers.ApiEth1Tx).Methods("GET", "OPTIONS")
 		// apiV1Router.HandleFunc("/execution/transaction/{txhash}/itx", handlers.ApiEth1TxItx).Methods("GET", "OPTIONS")
 		// apiV1Router.HandleFunc("/execution/transaction/{txhash}/status", handlers.ApiEth1TxStatus).Methods("GET", "OPTIONS")
 		// apiV1Router.HandleFunc("/execution/token/{token}", handlers.ApiEth1).Methods("GET", "OPTIONS")
@@ -370,20 +409,40 @@ func main() {
 		apiV1AuthRouter.HandleFunc("/validator/{pubkey}/add", handlers.UserValidatorWatchlistAdd).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/validator/{pubkey}/remove", handlers.UserValidatorWatchlistRemove).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/dashboard/save", handlers.UserDashboardWatchlistAdd).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/watchlist/import", handlers.UserWatchlistImport).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/notifications/bundled/subscribe", handlers.MultipleUsersNotificationsSubscribe).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/notifications/bundled/unsubscribe", handlers.MultipleUsersNotificationsUnsubscribe).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/bulk", handlers.ApiUserSubscriptionsBulkSave).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/notifications/bulk/delete", handlers.ApiUserSubscriptionsBulkDelete).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/notifications/subscribe", handlers.UserNotificationsSubscribe).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/notifications/unsubscribe", handlers.UserNotificationsUnsubscribe).Methods("POST", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/notifications", handlers.UserNotificationsSubscribed).Methods("POST", "GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/stats", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/stats/{offset}/{limit}", handlers.ClientStats).Methods("GET", "OPTIONS")
 		apiV1AuthRouter.HandleFunc("/ethpool", handlers.RegisterEthpoolSubscription).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/labels", handlers.ApiUserAddressLabelsExport).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/labels", handlers.ApiUserAddressLabelsImport).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/alertrules/metrics", handlers.ApiUserAlertRuleMetrics).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/alertrules", handlers.ApiUserAlertRules).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/alertrules", handlers.ApiUserAlertRuleCreate).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/alertrules/{id}", handlers.ApiUserAlertRuleDelete).Methods("DELETE", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/contracts/{address}/verify", handlers.ApiUserContractOwnershipChallenge).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/contracts/{address}/verify", handlers.ApiUserContractOwnershipVerify).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/contractalerts", handlers.ApiUserContractAlerts).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/contractalerts", handlers.ApiUserContractAlertCreate).Methods("POST", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/contractalerts/{id}", handlers.ApiUserContractAlertDelete).Methods("DELETE", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/feerecipients", handlers.ApiUserFeeRecipients).Methods("GET", "OPTIONS")
+		apiV1AuthRouter.HandleFunc("/feerecipients", handlers.ApiUserFeeRecipientsUpdate).Methods("PUT", "OPTIONS")
 
 		apiV1AuthRouter.Use(utils.CORSMiddleware)
 		apiV1AuthRouter.Use(utils.AuthorizedAPIMiddleware)
 
 		router.HandleFunc("/api/healthz", handlers.ApiHealthz).Methods("GET", "HEAD")
 		router.HandleFunc("/api/healthz-loadbalancer", handlers.ApiHealthzLoadbalancer).Methods("GET", "HEAD")
+		if utils.Config.Frontend.Eth1Stream.Enabled {
+			services.InitEth1Stream()
+			router.HandleFunc("/ws", handlers.Eth1Stream).Methods("GET")
+		}
 
 		// logrus.Infof("initializing frontend services")
 		// services.Init() // Init frontend services
@@ -442,19 +501,40 @@ func main() {
 			router.HandleFunc("/address/{address}/uncles", handlers.Eth1AddressUnclesMined).Methods("GET")
 			router.HandleFunc("/address/{address}/withdrawals", handlers.Eth1AddressWithdrawals).Methods("GET")
 			router.HandleFunc("/address/{address}/transactions", handlers.Eth1AddressTransactions).Methods("GET")
+			router.HandleFunc("/address/{address}/transactions/export", handlers.Eth1AddressTransactionsExport).Methods("GET")
+			router.HandleFunc("/address/{address}/pending", handlers.Eth1AddressPendingTransactions).Methods("GET")
 			router.HandleFunc("/address/{address}/internalTxns", handlers.Eth1AddressInternalTransactions).Methods("GET")
+			router.HandleFunc("/address/{address}/activity", handlers.Eth1AddressActivity).Methods("GET")
 			router.HandleFunc("/address/{address}/erc20", handlers.Eth1AddressErc20Transactions).Methods("GET")
 			router.HandleFunc("/address/{address}/erc721", handlers.Eth1AddressErc721Transactions).Methods("GET")
 			router.HandleFunc("/address/{address}/erc1155", handlers.Eth1AddressErc1155Transactions).Methods("GET")
+			router.HandleFunc("/address/{address}/nfts", handlers.Eth1AddressNFTs).Methods("GET")
+			router.HandleFunc("/address/{address}/graph", handlers.Eth1AddressGraph).Methods("GET")
+			router.HandleFunc("/address/{address}/balances", handlers.Eth1AddressBalanceHistory).Methods("GET")
+			router.HandleFunc("/address/{address}/code", handlers.Eth1AddressCode).Methods("GET")
+			router.HandleFunc("/address/{address}/storage", handlers.Eth1AddressStorage).Methods("GET")
 			router.HandleFunc("/token/{token}", handlers.Eth1Token).Methods("GET")
 			router.HandleFunc("/token/{token}/transfers", handlers.Eth1TokenTransfers).Methods("GET")
+			router.HandleFunc("/token/{token}/holders", handlers.Eth1TokenHolders).Methods("GET")
+			router.HandleFunc("/collection/{address}", handlers.NFTCollection).Methods("GET")
+			router.HandleFunc("/collection/{address}/transfers", handlers.NFTCollectionTransfers).Methods("GET")
+			router.HandleFunc("/nft/{contract}/{tokenId}/image", handlers.NFTImage).Methods("GET")
 			router.HandleFunc("/transactions", handlers.Eth1Transactions).Methods("GET")
 			router.HandleFunc("/transactions/data", handlers.Eth1TransactionsData).Methods("GET")
 			router.HandleFunc("/block/{block}", handlers.Eth1Block).Methods("GET")
 			router.HandleFunc("/block/{block}/transactions", handlers.BlockTransactionsData).Methods("GET")
+			router.HandleFunc("/block/{block}/internalTxns", handlers.BlockInternalTransactionsData).Methods("GET")
 			router.HandleFunc("/tx/{hash}", handlers.Eth1TransactionTx).Methods("GET")
 			router.HandleFunc("/mempool", handlers.MempoolView).Methods("GET")
 			router.HandleFunc("/burn", handlers.Burn).Methods("GET")
+			router.HandleFunc("/whales", handlers.Whales).Methods("GET")
+			router.HandleFunc("/nfts/mints", handlers.NFTMints).Methods("GET")
+			router.HandleFunc("/aa", handlers.AAOperators).Methods("GET")
+			router.HandleFunc("/reorgs", handlers.Reorgs).Methods("GET")
+			if utils.Config.Frontend.NodeCrawler.Enabled {
+				router.HandleFunc("/nodes", handlers.NetworkNodes).Methods("GET")
+			}
+			router.HandleFunc("/status", handlers.Status).Methods("GET")
 			router.HandleFunc("/burn/data", handlers.BurnPageData).Methods("GET")
 			router.HandleFunc("/gasnow", handlers.GasNow).Methods("GET")
 			router.HandleFunc("/gasnow/data", handlers.GasNowData).Methods("GET")
@@ -519,6 +599,7 @@ func main() {
 			router.HandleFunc("/graffitiwall", handlers.Graffitiwall).Methods("GET")
 			router.HandleFunc("/calculator", handlers.StakingCalculator).Methods("GET")
 			router.HandleFunc("/search", handlers.Search).Methods("POST")
+			router.HandleFunc("/search/suggestions/{search}", handlers.SearchSuggestions).Methods("GET")
 			router.HandleFunc("/search/{type}/{search}", handlers.SearchAhead).Methods("GET")
 			router.HandleFunc("/faq", handlers.Faq).Methods("GET")
 			router.HandleFunc("/imprint", handlers.Imprint).Methods("GET")
@@ -527,6 +608,7 @@ func main() {
 			router.HandleFunc("/mobile", handlers.MobilePage).Methods("GET")
 			router.HandleFunc("/mobile", handlers.MobilePagePost).Methods("POST")
 			router.HandleFunc("/tools/unitConverter", handlers.UnitConverter).Methods("GET")
+			router.HandleFunc("/tools/create2", handlers.Create2).Methods("GET")
 			router.HandleFunc("/tools/broadcast", handlers.Broadcast).Methods("GET")
 			router.HandleFunc("/tools/broadcast", handlers.BroadcastPost).Methods("POST")
 			router.HandleFunc("/tools/broadcast/status/{jobID}", handlers.BroadcastStatus).Methods("GET")
@@ -596,6 +678,8 @@ func main() {
 			authRouter.HandleFunc("/settings/flags", handlers.UserUpdateFlagsPost).Methods("POST")
 			authRouter.HandleFunc("/settings/delete", handlers.UserDeletePost).Methods("POST")
 			authRouter.HandleFunc("/settings/email", handlers.UserUpdateEmailPost).Methods("POST")
+			authRouter.HandleFunc("/settings/api-webhooks", handlers.UsersAddApiWebhook).Methods("POST")
+			authRouter.HandleFunc("/settings/api-webhooks/{webhookID}/delete", handlers.UsersDeleteApiWebhook).Methods("POST")
 			authRouter.HandleFunc("/notifications", handlers.UserNotificationsCenter).Methods("GET")
 			authRouter.HandleFunc("/notifications/channels", handlers.UsersNotificationChannels).Methods("POST")
 			authRouter.HandleFunc("/notifications/data", handlers.UserNotificationsData).Methods("GET")
@@ -613,6 +697,11 @@ func main() {
 			authRouter.HandleFunc("/ad_configuration/delete", handlers.AdConfigurationDeletePost).Methods("POST")
 			authRouter.HandleFunc("/explorer_configuration", handlers.ExplorerConfiguration).Methods("GET")
 			authRouter.HandleFunc("/explorer_configuration", handlers.ExplorerConfigurationPost).Methods("POST")
+			authRouter.HandleFunc("/indexer_status", handlers.IndexerStatus).Methods("GET")
+			authRouter.HandleFunc("/indexer_status/data", handlers.IndexerStatusData).Methods("GET")
+			authRouter.HandleFunc("/support/users/{user}/subscriptions", handlers.SupportUserSubscriptions).Methods("GET")
+			authRouter.HandleFunc("/support/users/{user}/api-usage", handlers.SupportUserApiUsage).Methods("GET")
+			authRouter.HandleFunc("/support/users/{user}/watchlist", handlers.SupportUserWatchlist).Methods("GET")
 
 			authRouter.HandleFunc("/notifications-center", handlers.UserNotificationsCenter).Methods("GET")
 			authRouter.HandleFunc("/notifications-center/removeall", handlers.RemoveAllValidatorsAndUnsubscribe).Methods("POST")
@@ -627,6 +716,13 @@ func main() {
 			authRouter.HandleFunc("/rewards/subscribe", handlers.RewardNotificationSubscribe).Methods("POST")
 			authRouter.HandleFunc("/rewards/unsubscribe", handlers.RewardNotificationUnsubscribe).Methods("POST")
 			authRouter.HandleFunc("/rewards/subscriptions/data", handlers.RewardGetUserSubscriptions).Methods("POST")
+			authRouter.HandleFunc("/dashboards", handlers.UserDashboards).Methods("GET")
+			authRouter.HandleFunc("/dashboards/save", handlers.UserDashboardSave).Methods("POST")
+			authRouter.HandleFunc("/dashboards/{id}/data", handlers.UserDashboardData).Methods("GET")
+			authRouter.HandleFunc("/dashboards/{id}/delete", handlers.UserDashboardDelete).Methods("POST")
+			authRouter.HandleFunc("/filters", handlers.UserSavedFilters).Methods("GET")
+			authRouter.HandleFunc("/filters/save", handlers.UserSavedFilterSave).Methods("POST")
+			authRouter.HandleFunc("/filters/{id}/delete", handlers.UserSavedFilterDelete).Methods("POST")
 			authRouter.HandleFunc("/webhooks", handlers.NotificationWebhookPage).Methods("GET")
 			authRouter.HandleFunc("/webhooks/add", handlers.UsersAddWebhook).Methods("POST")
 			authRouter.HandleFunc("/webhooks/{webhookID}/update", handlers.UsersEditWebhook).Methods("POST")
@@ -681,13 +777,11 @@ func main() {
 		//}
 		//n.Use(frontendLogger)
 
-		n.Use(gzip.Gzip(gzip.DefaultCompression))
-
 		pa := &proxyaddr.ProxyAddr{}
 		pa.Init(proxyaddr.CIDRLoopback)
 		n.Use(pa)
 
-		n.UseHandler(utils.SessionStore.SCS.LoadAndSave(router))
+		n.UseHandler(utils.CompressionMiddleware(utils.SessionStore.SCS.LoadAndSave(handlers.NetworkPrefixMiddleware(router))))
 
 		if utils.Config.Frontend.HttpWriteTimeout == 0 {
 			utils.Config.Frontend.HttpIdleTimeout = time.Second * 15
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/rpc"
 	"eth2-exporter/types"
@@ -9,6 +10,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -21,9 +23,15 @@ func main() {
 
 	start := flag.Uint64("start", 1, "Start epoch")
 	end := flag.Uint64("end", 1, "End epoch")
+	schema := flag.Bool("schema", false, "Create the missing tables and column families and exit")
 
 	flag.Parse()
 
+	if *schema {
+		migrateSchema(*configPath)
+		return
+	}
+
 	if *start == 1 && *end == 1 {
 		monitor(*configPath)
 	}
@@ -54,7 +62,7 @@ func main() {
 		logrus.Infof("exporting epoch %v", i)
 
 		logrus.Infof("deleting existing epoch data")
-		err := bt.DeleteEpoch(i)
+		err := bt.DeleteEpoch(context.Background(), i)
 		if err != nil {
 			utils.LogFatal(err, "deleting epoch error", 0)
 		}
@@ -78,7 +86,7 @@ func main() {
 
 		logrus.Infof("saving sync assignments for %v validators", len(validatorsU64))
 
-		err = db.BigtableClient.SaveSyncCommitteesAssignments(firstSlot, lastSlot, validatorsU64)
+		err = db.BigtableClient.SaveSyncCommitteesAssignments(context.Background(), firstSlot, lastSlot, validatorsU64)
 		if err != nil {
 			logrus.Fatalf("error saving sync committee assignments: %v", err)
 		}
@@ -92,27 +100,27 @@ func main() {
 		g := new(errgroup.Group)
 
 		g.Go(func() error {
-			return bt.SaveValidatorBalances(data.Epoch, data.Validators)
+			return bt.SaveValidatorBalances(context.Background(), data.Epoch, data.Validators)
 		})
 
 		g.Go(func() error {
-			return bt.SaveAttestationAssignments(data.Epoch, data.ValidatorAssignmentes.AttestorAssignments)
+			return bt.SaveAttestationAssignments(context.Background(), data.Epoch, data.ValidatorAssignmentes.AttestorAssignments)
 		})
 
 		g.Go(func() error {
-			return bt.SaveProposalAssignments(data.Epoch, data.ValidatorAssignmentes.ProposerAssignments)
+			return bt.SaveProposalAssignments(context.Background(), data.Epoch, data.ValidatorAssignmentes.ProposerAssignments)
 		})
 
 		g.Go(func() error {
-			return bt.SaveAttestations(data.Blocks)
+			return bt.SaveAttestations(context.Background(), data.Blocks)
 		})
 
 		g.Go(func() error {
-			return bt.SaveProposals(data.Blocks)
+			return bt.SaveProposals(context.Background(), data.Blocks)
 		})
 
 		g.Go(func() error {
-			return bt.SaveSyncComitteeDuties(data.Blocks)
+			return bt.SaveSyncComitteeDuties(context.Background(), data.Blocks)
 		})
 
 		err = g.Wait()
@@ -124,6 +132,33 @@ func main() {
 
 }
 
+func migrateSchema(configPath string) {
+	cfg := &types.Config{}
+	err := utils.ReadConfig(cfg, configPath)
+	if err != nil {
+		logrus.Fatalf("error reading config file: %v", err)
+	}
+	utils.Config = cfg
+
+	db.MustInitBigtableAdmin(context.Background(), utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance)
+
+	missing, err := db.BigAdminClient.MissingSchema()
+	if err != nil {
+		logrus.Fatalf("error reading the bigtable schema: %v", err)
+	}
+	if len(missing) == 0 {
+		logrus.Infof("bigtable schema is up to date")
+		return
+	}
+
+	logrus.Infof("creating %v", strings.Join(missing, ", "))
+	err = db.BigAdminClient.MigrateSchema()
+	if err != nil {
+		logrus.Fatalf("error migrating the bigtable schema: %v", err)
+	}
+	logrus.Infof("bigtable schema migrated")
+}
+
 func monitor(configPath string) {
 
 	cfg := &types.Config{}
@@ -170,27 +205,27 @@ func monitor(configPath string) {
 			g := new(errgroup.Group)
 
 			g.Go(func() error {
-				return bt.SaveValidatorBalances(data.Epoch, data.Validators)
+				return bt.SaveValidatorBalances(context.Background(), data.Epoch, data.Validators)
 			})
 
 			g.Go(func() error {
-				return bt.SaveAttestationAssignments(data.Epoch, data.ValidatorAssignmentes.AttestorAssignments)
+				return bt.SaveAttestationAssignments(context.Background(), data.Epoch, data.ValidatorAssignmentes.AttestorAssignments)
 			})
 
 			g.Go(func() error {
-				return bt.SaveProposalAssignments(data.Epoch, data.ValidatorAssignmentes.ProposerAssignments)
+				return bt.SaveProposalAssignments(context.Background(), data.Epoch, data.ValidatorAssignmentes.ProposerAssignments)
 			})
 
 			g.Go(func() error {
-				return bt.SaveAttestations(data.Blocks)
+				return bt.SaveAttestations(context.Background(), data.Blocks)
 			})
 
 			g.Go(func() error {
-				return bt.SaveProposals(data.Blocks)
+				return bt.SaveProposals(context.Background(), data.Blocks)
 			})
 
 			g.Go(func() error {
-				return bt.SaveSyncComitteeDuties(data.Blocks)
+				return bt.SaveSyncComitteeDuties(context.Background(), data.Blocks)
 			})
 
 			err = g.Wait()
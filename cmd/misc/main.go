@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/exporter"
 	"eth2-exporter/rpc"
@@ -11,6 +12,9 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
+
+	"cloud.google.com/go/storage"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
 
@@ -28,17 +32,36 @@ var opts = struct {
 	StartDay      uint64
 	EndDay        uint64
 	Validator     uint64
+	// snapshot-export, snapshot-restore and snapshot-list
+	SnapshotBucket    string
+	SnapshotPrefix    string
+	SnapshotID        string
+	SnapshotTables    string
+	SnapshotStart     string
+	SnapshotEnd       string
+	SnapshotChunkRows int
+	BigtableProject   string
+	BigtableInstance  string
 }{}
 
 func main() {
 	configPath := flag.String("config", "config/default.config.yml", "Path to the config file")
-	flag.StringVar(&opts.Command, "command", "", "command to run, available: updateAPIKey, applyDbSchema, epoch-export, debug-rewards")
+	flag.StringVar(&opts.Command, "command", "", "command to run, available: updateAPIKey, applyDbSchema, epoch-export, debug-rewards, snapshot-export, snapshot-restore, snapshot-list")
 	flag.Uint64Var(&opts.StartEpoch, "start-epoch", 0, "start epoch")
 	flag.Uint64Var(&opts.EndEpoch, "end-epoch", 0, "end epoch")
 	flag.Uint64Var(&opts.User, "user", 0, "user id")
 	flag.Uint64Var(&opts.StartDay, "day-start", 0, "start day to debug")
 	flag.Uint64Var(&opts.EndDay, "day-end", 0, "end day to debug")
 	flag.Uint64Var(&opts.Validator, "validator", 0, "validator to check for")
+	flag.StringVar(&opts.SnapshotBucket, "snapshot-bucket", "", "bucket the bigtable snapshots are stored in")
+	flag.StringVar(&opts.SnapshotPrefix, "snapshot-prefix", "", "object prefix of the bigtable snapshots within the bucket")
+	flag.StringVar(&opts.SnapshotID, "snapshot-id", "", "id of the snapshot to restore")
+	flag.StringVar(&opts.SnapshotTables, "snapshot-tables", "", "comma separated tables to export or restore, defaults to all tables")
+	flag.StringVar(&opts.SnapshotStart, "snapshot-start", "", "first row key to export, defaults to the first row of each table")
+	flag.StringVar(&opts.SnapshotEnd, "snapshot-end", "", "row key to stop the export at, defaults to the last row of each table")
+	flag.IntVar(&opts.SnapshotChunkRows, "snapshot-chunk-rows", db.DefaultSnapshotChunkRows, "number of rows per snapshot chunk")
+	flag.StringVar(&opts.BigtableProject, "bigtable-project", "", "bigtable project to export from or restore to, defaults to the configured project")
+	flag.StringVar(&opts.BigtableInstance, "bigtable-instance", "", "bigtable instance to export from or restore to, defaults to the configured instance")
 	flag.Int64Var(&opts.TargetVersion, "target-version", -2, "Db migration target version, use -2 to apply up to the latest version, -1 to apply only the next version or the specific versions")
 	flag.Parse()
 
@@ -125,6 +148,11 @@ func main() {
 		}
 	case "debug-rewards":
 		CompareRewards(opts.StartDay, opts.EndDay, opts.Validator)
+	case "snapshot-export", "snapshot-restore", "snapshot-list":
+		err := BigtableSnapshot(opts.Command)
+		if err != nil {
+			logrus.WithError(err).Fatalf("error running %v", opts.Command)
+		}
 
 	default:
 		utils.LogFatal(nil, "unknown command", 0)
@@ -188,7 +216,7 @@ func UpdateAPIKey(user uint64) error {
 // Debugging function to compare Rewards from the Statistic Table with the onces from the Big Table
 func CompareRewards(dayStart uint64, dayEnd uint64, validator uint64) {
 
-	bt, err := db.InitBigtable(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.Config.DepositChainID))
+	bt, err := db.NewBigtable(utils.Config.Bigtable.Project, utils.Config.Bigtable.Instance, fmt.Sprintf("%d", utils.Config.Chain.Config.DepositChainID))
 	if err != nil {
 		logrus.Fatalf("error connecting to bigtable: %v", err)
 	}
@@ -197,7 +225,7 @@ func CompareRewards(dayStart uint64, dayEnd uint64, validator uint64) {
 	for day := dayStart; day <= dayEnd; day++ {
 		startEpoch := day * utils.EpochsPerDay()
 		endEpoch := startEpoch + utils.EpochsPerDay() - 1
-		hist, err := bt.GetValidatorIncomeDetailsHistory([]uint64{validator}, startEpoch, endEpoch)
+		hist, err := bt.GetValidatorIncomeDetailsHistory(context.Background(), []uint64{validator}, startEpoch, endEpoch)
 		if err != nil {
 			logrus.Fatal(err)
 		}
@@ -221,3 +249,72 @@ func CompareRewards(dayStart uint64, dayEnd uint64, validator uint64) {
 	}
 
 }
+
+// BigtableSnapshot exports the bigtable tables to a snapshot, restores a snapshot or lists the available snapshots. Restoring
+// into a different instance allows to clone the dataset into a staging environment.
+func BigtableSnapshot(command string) error {
+	if opts.SnapshotBucket == "" {
+		return fmt.Errorf("no snapshot bucket specified")
+	}
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("error creating storage client: %w", err)
+	}
+	defer client.Close()
+	store := db.NewGCSSnapshotStore(client.Bucket(opts.SnapshotBucket), opts.SnapshotPrefix)
+
+	if command == "snapshot-list" {
+		ids, err := db.ListSnapshots(ctx, store)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			logrus.Infof("snapshot %v", id)
+		}
+		return nil
+	}
+
+	project := utils.Config.Bigtable.Project
+	if opts.BigtableProject != "" {
+		project = opts.BigtableProject
+	}
+	instance := utils.Config.Bigtable.Instance
+	if opts.BigtableInstance != "" {
+		instance = opts.BigtableInstance
+	}
+	bt, err := db.NewBigtable(project, instance, fmt.Sprintf("%d", utils.Config.Chain.Config.DepositChainID))
+	if err != nil {
+		return fmt.Errorf("error connecting to bigtable: %w", err)
+	}
+	defer bt.Close()
+
+	var tables []string
+	if opts.SnapshotTables != "" {
+		tables = strings.Split(opts.SnapshotTables, ",")
+	}
+
+	if command == "snapshot-restore" {
+		if opts.SnapshotID == "" {
+			return fmt.Errorf("no snapshot id specified")
+		}
+		logrus.Infof("restoring snapshot %v to bigtable %v/%v", opts.SnapshotID, project, instance)
+		err = bt.RestoreSnapshot(ctx, store, opts.SnapshotID, tables)
+		if err != nil {
+			return err
+		}
+		logrus.Infof("restored snapshot %v", opts.SnapshotID)
+		return nil
+	}
+
+	manifest, err := bt.ExportSnapshot(ctx, store, tables, opts.SnapshotStart, opts.SnapshotEnd, opts.SnapshotChunkRows)
+	if err != nil {
+		return err
+	}
+	for _, table := range manifest.Tables {
+		logrus.Infof("exported %v rows in %v chunks of table %v", table.Rows, len(table.Chunks), table.Name)
+	}
+	logrus.Infof("created snapshot %v", manifest.ID)
+	return nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
@@ -181,7 +182,7 @@ func export(epoch uint64, bt *db.Bigtable, client *beacon.Client, elClient *stri
 		logrus.Infof("retrieved %v reward details for epoch %v in %v", len(rewards), epoch, time.Since(start))
 	}
 
-	err = bt.SaveValidatorIncomeDetails(uint64(epoch), rewards)
+	err = bt.SaveValidatorIncomeDetails(context.Background(), uint64(epoch), rewards)
 	if err != nil {
 		return fmt.Errorf("error saving reward details to bigtable: %v", err)
 	}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/metrics"
@@ -86,7 +87,7 @@ func ImportSignatures(bt *db.Bigtable, st types.SignatureType) {
 		firstPage = "https://www.4byte.directory/api/v1/event-signatures/"
 	}
 	page := firstPage
-	status, err := bt.GetSignatureImportStatus(st)
+	status, err := bt.GetSignatureImportStatus(context.Background(), st)
 	if err != nil {
 		logrus.Errorf("error getting %v signature import status from bigtable: %v", st, err)
 		return
@@ -131,7 +132,7 @@ func ImportSignatures(bt *db.Bigtable, st types.SignatureType) {
 			}
 		}
 
-		err = db.BigtableClient.SaveSignatures(sigs, st)
+		err = db.BigtableClient.SaveSignatures(context.Background(), sigs, st)
 		if err != nil {
 			metrics.Errors.WithLabelValues(fmt.Sprintf("%v_signatures_save_to_bt_failed", st)).Inc()
 			logrus.Errorf("error saving %v signatures into bigtable: %v", st, err)
@@ -156,7 +157,7 @@ func ImportSignatures(bt *db.Bigtable, st types.SignatureType) {
 		}
 		if status != nil && (status.HasFinished || status.NextPage != nil) {
 			logrus.Infof("Save %v Sig ts: %v next: %v", st, *status.LatestTimestamp, *status.NextPage)
-			err = bt.SaveSignatureImportStatus(*status, st)
+			err = bt.SaveSignatureImportStatus(context.Background(), *status, st)
 			if err != nil {
 				metrics.Errors.WithLabelValues(fmt.Sprintf("%v_signatures_save_status_to_bt_failed", st)).Inc()
 				logrus.Errorf("error saving %v signature status into bigtable: %v", st, err)
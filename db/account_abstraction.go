@@ -0,0 +1,93 @@
+package db
+
+import (
+	"bytes"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lib/pq"
+)
+
+// topic of UserOperationEvent(bytes32 indexed userOpHash, address indexed sender, address indexed paymaster, uint256 nonce, bool success, uint256 actualGasCost, uint256 actualGasUsed)
+var userOperationEventTopic = common.HexToHash("0x49628fd1471006c1482da88028e9ce4dbb080b815c9b0344d39e5a8e6ec1419f").Bytes()
+
+// userOperationsOfTransaction returns the ERC-4337 user operations a transaction submitted to an entry point
+func userOperationsOfTransaction(tx *types.Eth1Transaction) []*types.UserOperation {
+	ops := []*types.UserOperation{}
+	for _, log := range tx.GetLogs() {
+		topics := log.GetTopics()
+		if len(topics) != 4 || !bytes.Equal(topics[0], userOperationEventTopic) || len(log.GetData()) < 128 {
+			continue
+		}
+		data := log.GetData()
+		ops = append(ops, &types.UserOperation{
+			Hash:          topics[1],
+			EntryPoint:    log.GetAddress(),
+			Sender:        common.BytesToAddress(topics[2]).Bytes(),
+			Paymaster:     common.BytesToAddress(topics[3]).Bytes(),
+			Bundler:       tx.GetFrom(),
+			Success:       new(big.Int).SetBytes(data[32:64]).Sign() != 0,
+			ActualGasCost: new(big.Int).SetBytes(data[64:96]),
+			ActualGasUsed: new(big.Int).SetBytes(data[96:128]),
+		})
+	}
+	return ops
+}
+
+// SaveAAOperatorStats replaces the bundler and paymaster statistics of a day
+func SaveAAOperatorStats(day time.Time, stats []*types.AAOperatorStats) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM aa_operators_daily WHERE day = $1`, day)
+	if err != nil {
+		return fmt.Errorf("error deleting aa operator stats of day %v: %w", day, err)
+	}
+
+	roles := make(pq.StringArray, 0, len(stats))
+	addresses := make(pq.ByteaArray, 0, len(stats))
+	operations := make(pq.Int64Array, 0, len(stats))
+	successful := make(pq.Int64Array, 0, len(stats))
+	gasUsed := make(pq.StringArray, 0, len(stats))
+	gasCost := make(pq.StringArray, 0, len(stats))
+	for _, s := range stats {
+		roles = append(roles, s.Role)
+		addresses = append(addresses, s.Address)
+		operations = append(operations, int64(s.Operations))
+		successful = append(successful, int64(s.Successful))
+		gasUsed = append(gasUsed, s.GasUsed.String())
+		gasCost = append(gasCost, s.GasCost.String())
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO aa_operators_daily (day, role, address, operations, successful, gas_used, gas_cost)
+		SELECT $1, UNNEST($2::text[]), UNNEST($3::bytea[]), UNNEST($4::bigint[]), UNNEST($5::bigint[]), UNNEST($6::numeric[]), UNNEST($7::numeric[])`,
+		day, roles, addresses, operations, successful, gasUsed, gasCost)
+	if err != nil {
+		return fmt.Errorf("error saving aa operator stats of day %v: %w", day, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetAAOperatorLeaderboard returns the bundlers or paymasters with the most user operations over the last days, Day is left empty
+func GetAAOperatorLeaderboard(role string, days uint64, limit uint64) ([]*types.AAOperatorStats, error) {
+	stats := []*types.AAOperatorStats{}
+	err := ReaderDb.Select(&stats, `
+		SELECT role, address, SUM(operations) AS operations, SUM(successful) AS successful, SUM(gas_used) AS gas_used, SUM(gas_cost) AS gas_cost
+		FROM aa_operators_daily
+		WHERE role = $1 AND day > NOW() - $2::INT * INTERVAL '1 day'
+		GROUP BY role, address
+		ORDER BY operations DESC, gas_cost DESC
+		LIMIT $3`, role, days, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %v leaderboard: %w", role, err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// GetUserAddressLabels returns all private address labels of a user
+func GetUserAddressLabels(userID uint64) ([]*types.AddressLabel, error) {
+	labels := []*types.AddressLabel{}
+	err := FrontendWriterDB.Select(&labels, `
+		SELECT address, label
+		FROM users_address_labels
+		WHERE user_id = $1
+		ORDER BY address`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting address labels of user %v: %w", userID, err)
+	}
+	return labels, nil
+}
+
+// GetUserAddressLabelsByAddress returns the private labels of a user for the given addresses, keyed by the raw address bytes
+func GetUserAddressLabelsByAddress(userID uint64, addresses [][]byte) (map[string]string, error) {
+	labels := []*types.AddressLabel{}
+	err := FrontendWriterDB.Select(&labels, `
+		SELECT address, label
+		FROM users_address_labels
+		WHERE user_id = $1 AND address = ANY($2)`, userID, pq.ByteaArray(addresses))
+	if err != nil {
+		return nil, fmt.Errorf("error getting address labels of user %v: %w", userID, err)
+	}
+
+	labelsByAddress := make(map[string]string, len(labels))
+	for _, l := range labels {
+		labelsByAddress[string(l.Address)] = l.Label
+	}
+	return labelsByAddress, nil
+}
+
+// GetUserAddressLabelCount returns the number of private address labels of a user
+func GetUserAddressLabelCount(userID uint64) (uint64, error) {
+	var count uint64
+	err := FrontendWriterDB.Get(&count, `SELECT COUNT(*) FROM users_address_labels WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("error getting address label count of user %v: %w", userID, err)
+	}
+	return count, nil
+}
+
+// SaveUserAddressLabels stores the private address labels of a user, existing labels of the same addresses are overwritten.
+// If replace is set all other labels of the user are removed.
+func SaveUserAddressLabels(userID uint64, labels []*types.AddressLabel, replace bool) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	if replace {
+		_, err = tx.Exec(`DELETE FROM users_address_labels WHERE user_id = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("error deleting address labels of user %v: %w", userID, err)
+		}
+	}
+
+	addresses := make(pq.ByteaArray, 0, len(labels))
+	names := make(pq.StringArray, 0, len(labels))
+	for _, l := range labels {
+		addresses = append(addresses, l.Address)
+		names = append(names, l.Label)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users_address_labels (user_id, address, label, created_ts)
+		SELECT $1, UNNEST($2::bytea[]), UNNEST($3::text[]), NOW()
+		ON CONFLICT (user_id, address) DO UPDATE SET label = EXCLUDED.label`,
+		userID, addresses, names)
+	if err != nil {
+		return fmt.Errorf("error saving address labels of user %v: %w", userID, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// alertRuleEventName returns the network specific subscription event name of the alert rules
+func alertRuleEventName(network string) string {
+	return strings.ToLower(network) + ":" + string(types.AlertRuleTriggeredEventName)
+}
+
+// CreateAlertRule stores a new alert rule of a user together with the subscription its notifications are delivered through
+func CreateAlertRule(rule *types.AlertRule, network string) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	rule.CreatedTs = now
+	err = tx.Get(&rule.ID, `
+		INSERT INTO users_alert_rules (user_id, network, name, condition, window_epochs, created_ts)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		rule.UserID, strings.ToLower(network), rule.Name, rule.Condition, rule.Window, now)
+	if err != nil {
+		return fmt.Errorf("error saving alert rule of user %v: %w", rule.UserID, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold)
+		VALUES ($1, $2, $3, $4, $5, 0)
+		ON CONFLICT (user_id, event_name, event_filter) DO NOTHING`,
+		rule.UserID, alertRuleEventName(network), fmt.Sprintf("%v", rule.ID), now, utils.TimeToEpoch(now))
+	if err != nil {
+		return fmt.Errorf("error saving subscription of alert rule %v: %w", rule.ID, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetUserAlertRules returns the alert rules a user has defined on a network
+func GetUserAlertRules(userID uint64, network string) ([]*types.AlertRule, error) {
+	rules := []*types.AlertRule{}
+	err := FrontendWriterDB.Select(&rules, `
+		SELECT id, user_id, name, condition, window_epochs, created_ts
+		FROM users_alert_rules
+		WHERE user_id = $1 AND network = $2
+		ORDER BY id`, userID, strings.ToLower(network))
+	if err != nil {
+		return nil, fmt.Errorf("error getting alert rules of user %v: %w", userID, err)
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule removes an alert rule of a user and its subscription, returns false if the user has no such rule
+func DeleteAlertRule(userID, ruleID uint64, network string) (bool, error) {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM users_alert_rules WHERE id = $1 AND user_id = $2 AND network = $3`, ruleID, userID, strings.ToLower(network))
+	if err != nil {
+		return false, fmt.Errorf("error deleting alert rule %v: %w", ruleID, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if deleted == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`DELETE FROM users_subscriptions WHERE user_id = $1 AND event_name = $2 AND event_filter = $3`, userID, alertRuleEventName(network), fmt.Sprintf("%v", ruleID))
+	if err != nil {
+		return false, fmt.Errorf("error deleting subscription of alert rule %v: %w", ruleID, err)
+	}
+
+	return true, tx.Commit()
+}
+
+// SubscribedAlertRule is an alert rule together with the subscription state of its notifications
+type SubscribedAlertRule struct {
+	types.AlertRule
+	SubscriptionID  uint64         `db:"subscription_id"`
+	UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
+}
+
+// GetDueAlertRules returns the alert rules of a network that have not been triggered within their window before the given epoch
+func GetDueAlertRules(network string, epoch uint64) ([]*SubscribedAlertRule, error) {
+	rules := []*SubscribedAlertRule{}
+	err := FrontendWriterDB.Select(&rules, `
+		SELECT r.id, r.user_id, r.name, r.condition, r.window_epochs, r.created_ts, us.id AS subscription_id, ENCODE(us.unsubscribe_hash, 'hex') AS unsubscribe_hash
+		FROM users_alert_rules r
+		INNER JOIN users_subscriptions us ON us.user_id = r.user_id AND us.event_name = $1 AND us.event_filter = r.id::TEXT
+		WHERE r.network = $2 AND (us.last_sent_epoch IS NULL OR us.last_sent_epoch <= $3 - r.window_epochs)`,
+		alertRuleEventName(network), strings.ToLower(network), epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting due alert rules: %w", err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+)
+
+// CreateApiWebhook stores a new operational webhook of an api key owner. New webhooks only receive incidents announced after their creation.
+func CreateApiWebhook(webhook *types.ApiWebhook) error {
+	webhook.CreatedTs = time.Now()
+	err := FrontendWriterDB.Get(&webhook.ID, `
+		INSERT INTO api_webhooks (user_id, url, events, lag_threshold, last_incident_id, created_ts)
+		VALUES ($1, $2, $3, $4, (SELECT COALESCE(MAX(id), 0) FROM api_incidents), $5)
+		RETURNING id`,
+		webhook.UserID, webhook.Url, webhook.Events, webhook.LagThreshold, webhook.CreatedTs)
+	if err != nil {
+		return fmt.Errorf("error saving api webhook of user %v: %w", webhook.UserID, err)
+	}
+	return nil
+}
+
+// GetUserApiWebhooks returns the operational webhooks of a user
+func GetUserApiWebhooks(userID uint64) ([]*types.ApiWebhook, error) {
+	webhooks := []*types.ApiWebhook{}
+	err := FrontendWriterDB.Select(&webhooks, `
+		SELECT id, user_id, url, events, lag_threshold, last_lag_sent, last_incident_id, retries, created_ts
+		FROM api_webhooks
+		WHERE user_id = $1
+		ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api webhooks of user %v: %w", userID, err)
+	}
+	return webhooks, nil
+}
+
+// DeleteApiWebhook removes an operational webhook of a user, returns false if the user has no such webhook
+func DeleteApiWebhook(userID, webhookID uint64) (bool, error) {
+	res, err := FrontendWriterDB.Exec(`DELETE FROM api_webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return false, fmt.Errorf("error deleting api webhook %v: %w", webhookID, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+// GetApiWebhooksForEvent returns all operational webhooks subscribed to an event
+func GetApiWebhooksForEvent(event types.ApiWebhookEvent) ([]*types.ApiWebhook, error) {
+	webhooks := []*types.ApiWebhook{}
+	err := FrontendWriterDB.Select(&webhooks, `
+		SELECT id, user_id, url, events, lag_threshold, last_lag_sent, last_incident_id, retries, created_ts
+		FROM api_webhooks
+		WHERE $1 = ANY(events)`, string(event))
+	if err != nil {
+		return nil, fmt.Errorf("error getting api webhooks for event %v: %w", event, err)
+	}
+	return webhooks, nil
+}
+
+// UpdateApiWebhookDelivery records the outcome of a delivery attempt, the retries are reset after a successful delivery
+func UpdateApiWebhookDelivery(webhookID uint64, success bool) error {
+	var err error
+	if success {
+		_, err = FrontendWriterDB.Exec(`UPDATE api_webhooks SET retries = 0 WHERE id = $1`, webhookID)
+	} else {
+		_, err = FrontendWriterDB.Exec(`UPDATE api_webhooks SET retries = retries + 1 WHERE id = $1`, webhookID)
+	}
+	if err != nil {
+		return fmt.Errorf("error updating delivery of api webhook %v: %w", webhookID, err)
+	}
+	return nil
+}
+
+// SetApiWebhookLagSent stores when the last index_lag event was sent to a webhook
+func SetApiWebhookLagSent(webhookID uint64, ts time.Time) error {
+	_, err := FrontendWriterDB.Exec(`UPDATE api_webhooks SET last_lag_sent = $2 WHERE id = $1`, webhookID, ts)
+	if err != nil {
+		return fmt.Errorf("error updating last lag event of api webhook %v: %w", webhookID, err)
+	}
+	return nil
+}
+
+// SetApiWebhookLastIncident stores the id of the last incident delivered to a webhook
+func SetApiWebhookLastIncident(webhookID, incidentID uint64) error {
+	_, err := FrontendWriterDB.Exec(`UPDATE api_webhooks SET last_incident_id = $2 WHERE id = $1 AND last_incident_id < $2`, webhookID, incidentID)
+	if err != nil {
+		return fmt.Errorf("error updating last incident of api webhook %v: %w", webhookID, err)
+	}
+	return nil
+}
+
+// GetApiIncidentsAfter returns the announced incidents with an id greater than the given one, ordered by id
+func GetApiIncidentsAfter(incidentID uint64) ([]*types.ApiIncident, error) {
+	incidents := []*types.ApiIncident{}
+	err := FrontendWriterDB.Select(&incidents, `
+		SELECT id, kind, title, description, start_ts, end_ts, created_ts
+		FROM api_incidents
+		WHERE id > $1
+		ORDER BY id`, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api incidents: %w", err)
+	}
+	return incidents, nil
+}
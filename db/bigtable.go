@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	gcp_bigtable "cloud.google.com/go/bigtable"
@@ -17,7 +18,9 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// BigtableClient is the process wide instance that is set by InitBigtable
 var BigtableClient *Bigtable
+var bigtableClientMux = &sync.Mutex{}
 
 const (
 	DEFAULT_FAMILY                = "f"
@@ -46,11 +49,36 @@ type Bigtable struct {
 	tableMetadataUpdates *gcp_bigtable.Table
 	tableMetadata        *gcp_bigtable.Table
 	tableMachineMetrics  *gcp_bigtable.Table
+	// nil unless the archive table is enabled in the config
+	tableArchive *gcp_bigtable.Table
 
 	chainId string
+
+	// number of chunks WriteBulk writes concurrently and how often a row that failed with a transient error is retried
+	writeConcurrency int
+	writeRetries     int
+
+	// reads failing with a transient error are attempted readAttempts times, readBreaker suspends reads during outages
+	readAttempts int
+	readBreaker  *readBreaker
 }
 
+// InitBigtable creates a bigtable instance and sets it as the process wide BigtableClient
 func InitBigtable(project, instance, chainId string) (*Bigtable, error) {
+	bt, err := NewBigtable(project, instance, chainId)
+	if err != nil {
+		return nil, err
+	}
+
+	bigtableClientMux.Lock()
+	defer bigtableClientMux.Unlock()
+	BigtableClient = bt
+	return bt, nil
+}
+
+// NewBigtable creates a bigtable instance without touching the process wide BigtableClient, this allows to use
+// multiple instances (e.g. for different chains) within one process
+func NewBigtable(project, instance, chainId string) (*Bigtable, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
@@ -71,12 +99,34 @@ func InitBigtable(project, instance, chainId string) (*Bigtable, error) {
 		tableBeaconchain:     btClient.Open("beaconchain"),
 		tableMachineMetrics:  btClient.Open("machine_metrics"),
 		chainId:              chainId,
+		writeConcurrency:     defaultWriteBulkConcurrency,
+		writeRetries:         defaultWriteBulkRetries,
+		readAttempts:         defaultReadAttempts,
+		readBreaker:          &readBreaker{},
+	}
+	if utils.Config != nil && utils.Config.Bigtable.ReadAttempts > 0 {
+		bt.readAttempts = utils.Config.Bigtable.ReadAttempts
+	}
+	if utils.Config != nil && utils.Config.Bigtable.Archive {
+		bt.tableArchive = btClient.Open(ARCHIVE_TABLE)
 	}
 
-	BigtableClient = bt
 	return bt, nil
 }
 
+// ForChain returns an instance reading and writing the rows of another chain stored in the same bigtable instance, the
+// connection and the read breaker are shared with bigtable
+func (bigtable *Bigtable) ForChain(chainId string) *Bigtable {
+	bt := *bigtable
+	bt.chainId = chainId
+	return &bt
+}
+
+// ChainId returns the chain id the rows of the instance are prefixed with
+func (bigtable *Bigtable) ChainId() string {
+	return bigtable.chainId
+}
+
 func (bigtable *Bigtable) Close() {
 	bigtable.client.Close()
 }
@@ -85,8 +135,8 @@ func (bigtable *Bigtable) GetClient() *gcp_bigtable.Client {
 	return bigtable.client
 }
 
-func (bigtable *Bigtable) SaveMachineMetric(process string, userID uint64, machine string, data []byte) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+func (bigtable *Bigtable) SaveMachineMetric(ctx context.Context, process string, userID uint64, machine string, data []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	rowKeyData := fmt.Sprintf("u:%s:p:%s:m:%v", reversePaddedUserID(userID), process, machine)
@@ -103,11 +153,7 @@ func (bigtable *Bigtable) SaveMachineMetric(process string, userID uint64, machi
 	dataMut := gcp_bigtable.NewMutation()
 	dataMut.Set(MACHINE_METRICS_COLUMN_FAMILY, "v1", ts, data)
 
-	err = bigtable.tableMachineMetrics.Apply(
-		ctx,
-		rowKeyData,
-		dataMut,
-	)
+	err = bigtable.apply(ctx, bigtable.tableMachineMetrics, rowKeyData, dataMut)
 	if err != nil {
 		return err
 	}
@@ -121,7 +167,7 @@ func (bigtable *Bigtable) getLastMachineMetricInsertTs(ctx context.Context, rowK
 		gcp_bigtable.LatestNFilter(1),
 	)
 
-	row, err := bigtable.tableMachineMetrics.ReadRow(ctx, rowKey, gcp_bigtable.RowFilter(filter))
+	row, err := bigtable.readRow(ctx, bigtable.tableMachineMetrics, rowKey, gcp_bigtable.RowFilter(filter))
 	if err != nil {
 		return 0, err
 	}
@@ -132,8 +178,8 @@ func (bigtable *Bigtable) getLastMachineMetricInsertTs(ctx context.Context, rowK
 	return 0, nil
 }
 
-func (bigtable Bigtable) getMachineMetricNamesMap(userID uint64, searchDepth int) (map[string]bool, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable Bigtable) getMachineMetricNamesMap(ctx context.Context, userID uint64, searchDepth int) (map[string]bool, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	rangePrefix := fmt.Sprintf("u:%s:p:", reversePaddedUserID(userID))
@@ -146,7 +192,7 @@ func (bigtable Bigtable) getMachineMetricNamesMap(userID uint64, searchDepth int
 
 	machineNames := make(map[string]bool)
 
-	err := bigtable.tableMachineMetrics.ReadRows(ctx, gcp_bigtable.PrefixRange(rangePrefix), func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMachineMetrics, gcp_bigtable.PrefixRange(rangePrefix), func(r gcp_bigtable.Row) bool {
 		success, _, machine, _ := machineMetricRowParts(r.Key())
 		if !success {
 			return false
@@ -162,8 +208,8 @@ func (bigtable Bigtable) getMachineMetricNamesMap(userID uint64, searchDepth int
 	return machineNames, nil
 }
 
-func (bigtable Bigtable) GetMachineMetricsMachineNames(userID uint64) ([]string, error) {
-	names, err := bigtable.getMachineMetricNamesMap(userID, 300)
+func (bigtable Bigtable) GetMachineMetricsMachineNames(ctx context.Context, userID uint64) ([]string, error) {
+	names, err := bigtable.getMachineMetricNamesMap(ctx, userID, 300)
 	if err != nil {
 		return nil, err
 	}
@@ -176,8 +222,8 @@ func (bigtable Bigtable) GetMachineMetricsMachineNames(userID uint64) ([]string,
 	return result, nil
 }
 
-func (bigtable Bigtable) GetMachineMetricsMachineCount(userID uint64) (uint64, error) {
-	names, err := bigtable.getMachineMetricNamesMap(userID, 15)
+func (bigtable Bigtable) GetMachineMetricsMachineCount(ctx context.Context, userID uint64) (uint64, error) {
+	names, err := bigtable.getMachineMetricNamesMap(ctx, userID, 15)
 	if err != nil {
 		return 0, err
 	}
@@ -185,8 +231,8 @@ func (bigtable Bigtable) GetMachineMetricsMachineCount(userID uint64) (uint64, e
 	return uint64(len(names)), nil
 }
 
-func (bigtable Bigtable) GetMachineMetricsNode(userID uint64, limit, offset int) ([]*types.MachineMetricNode, error) {
-	return getMachineMetrics(bigtable, "beaconnode", userID, limit, offset,
+func (bigtable Bigtable) GetMachineMetricsNode(ctx context.Context, userID uint64, limit, offset int) ([]*types.MachineMetricNode, error) {
+	return getMachineMetrics(ctx, bigtable, "beaconnode", userID, limit, offset,
 		func(data []byte, machine string) *types.MachineMetricNode {
 			obj := &types.MachineMetricNode{}
 			err := proto.Unmarshal(data, obj)
@@ -199,8 +245,8 @@ func (bigtable Bigtable) GetMachineMetricsNode(userID uint64, limit, offset int)
 	)
 }
 
-func (bigtable Bigtable) GetMachineMetricsValidator(userID uint64, limit, offset int) ([]*types.MachineMetricValidator, error) {
-	return getMachineMetrics(bigtable, "validator", userID, limit, offset,
+func (bigtable Bigtable) GetMachineMetricsValidator(ctx context.Context, userID uint64, limit, offset int) ([]*types.MachineMetricValidator, error) {
+	return getMachineMetrics(ctx, bigtable, "validator", userID, limit, offset,
 		func(data []byte, machine string) *types.MachineMetricValidator {
 			obj := &types.MachineMetricValidator{}
 			err := proto.Unmarshal(data, obj)
@@ -213,8 +259,8 @@ func (bigtable Bigtable) GetMachineMetricsValidator(userID uint64, limit, offset
 	)
 }
 
-func (bigtable Bigtable) GetMachineMetricsSystem(userID uint64, limit, offset int) ([]*types.MachineMetricSystem, error) {
-	return getMachineMetrics(bigtable, "system", userID, limit, offset,
+func (bigtable Bigtable) GetMachineMetricsSystem(ctx context.Context, userID uint64, limit, offset int) ([]*types.MachineMetricSystem, error) {
+	return getMachineMetrics(ctx, bigtable, "system", userID, limit, offset,
 		func(data []byte, machine string) *types.MachineMetricSystem {
 			obj := &types.MachineMetricSystem{}
 			err := proto.Unmarshal(data, obj)
@@ -227,8 +273,8 @@ func (bigtable Bigtable) GetMachineMetricsSystem(userID uint64, limit, offset in
 	)
 }
 
-func getMachineMetrics[T types.MachineMetricSystem | types.MachineMetricNode | types.MachineMetricValidator](bigtable Bigtable, process string, userID uint64, limit, offset int, marshler func(data []byte, machine string) *T) ([]*T, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func getMachineMetrics[T types.MachineMetricSystem | types.MachineMetricNode | types.MachineMetricValidator](ctx context.Context, bigtable Bigtable, process string, userID uint64, limit, offset int, marshler func(data []byte, machine string) *T) ([]*T, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	rangePrefix := fmt.Sprintf("u:%s:p:%s:m:", reversePaddedUserID(userID), process)
@@ -243,7 +289,7 @@ func getMachineMetrics[T types.MachineMetricSystem | types.MachineMetricNode | t
 		gcp_bigtable.CellsPerRowOffsetFilter(offset),
 	)
 	gapSize := getMachineStatsGap(uint64(limit))
-	err := bigtable.tableMachineMetrics.ReadRows(ctx, gcp_bigtable.PrefixRange(rangePrefix), func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMachineMetrics, gcp_bigtable.PrefixRange(rangePrefix), func(r gcp_bigtable.Row) bool {
 		success, _, machine, _ := machineMetricRowParts(r.Key())
 		if !success {
 			return false
@@ -279,8 +325,8 @@ func GetMachineRowKey(userID uint64, process string, machine string) string {
 // machineData contains the latest machine data in CurrentData
 // and 5 minute old data in fiveMinuteOldData (defined in limit)
 // as well as the insert timestamps of both
-func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys gcp_bigtable.RowList) (map[uint64]map[string]*types.MachineMetricSystemUser, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*200))
+func (bigtable Bigtable) GetMachineMetricsForNotifications(ctx context.Context, rowKeys gcp_bigtable.RowList) (map[uint64]map[string]*types.MachineMetricSystemUser, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*200))
 	defer cancel()
 
 	res := make(map[uint64]map[string]*types.MachineMetricSystemUser) // userID -> machine -> data
@@ -292,7 +338,7 @@ func (bigtable Bigtable) GetMachineMetricsForNotifications(rowKeys gcp_bigtable.
 		gcp_bigtable.LatestNFilter(limit),
 	)
 
-	err := bigtable.tableMachineMetrics.ReadRows(ctx, rowKeys, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMachineMetrics, rowKeys, func(r gcp_bigtable.Row) bool {
 		success, userID, machine, _ := machineMetricRowParts(r.Key())
 		if !success {
 			return false
@@ -364,9 +410,9 @@ func machineMetricRowParts(r string) (bool, uint64, string, string) {
 	return true, userID, machine, process
 }
 
-func (bigtable *Bigtable) SaveValidatorBalances(epoch uint64, validators []*types.Validator) error {
+func (bigtable *Bigtable) SaveValidatorBalances(ctx context.Context, epoch uint64, validators []*types.Validator) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -385,7 +431,7 @@ func (bigtable *Bigtable) SaveValidatorBalances(epoch uint64, validators []*type
 		mut.Set(VALIDATOR_BALANCES_FAMILY, fmt.Sprintf("%d", validator.Index), ts, combined)
 
 		if i%100000 == 0 {
-			err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
+			err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
 
 			if err != nil {
 				return err
@@ -393,7 +439,7 @@ func (bigtable *Bigtable) SaveValidatorBalances(epoch uint64, validators []*type
 			mut = gcp_bigtable.NewMutation()
 		}
 	}
-	err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
+	err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
 
 	if err != nil {
 		return err
@@ -403,9 +449,9 @@ func (bigtable *Bigtable) SaveValidatorBalances(epoch uint64, validators []*type
 	return nil
 }
 
-func (bigtable *Bigtable) SaveAttestationAssignments(epoch uint64, assignments map[string]uint64) error {
+func (bigtable *Bigtable) SaveAttestationAssignments(ctx context.Context, epoch uint64, assignments map[string]uint64) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -431,7 +477,7 @@ func (bigtable *Bigtable) SaveAttestationAssignments(epoch uint64, assignments m
 		for _, validator := range validators {
 			mut.Set(ATTESTATIONS_FAMILY, fmt.Sprintf("%d", validator), ts, []byte{})
 		}
-		err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(epoch), reversedPaddedSlot(slot)), mut)
+		err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(epoch), reversedPaddedSlot(slot)), mut)
 
 		if err != nil {
 			return err
@@ -442,9 +488,9 @@ func (bigtable *Bigtable) SaveAttestationAssignments(epoch uint64, assignments m
 	return nil
 }
 
-func (bigtable *Bigtable) SaveProposalAssignments(epoch uint64, assignments map[uint64]uint64) error {
+func (bigtable *Bigtable) SaveProposalAssignments(ctx context.Context, epoch uint64, assignments map[uint64]uint64) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -453,7 +499,7 @@ func (bigtable *Bigtable) SaveProposalAssignments(epoch uint64, assignments map[
 	for slot, validator := range assignments {
 		mut := gcp_bigtable.NewMutation()
 		mut.Set(PROPOSALS_FAMILY, fmt.Sprintf("%d", validator), ts, []byte{})
-		err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(epoch), reversedPaddedSlot(slot)), mut)
+		err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(epoch), reversedPaddedSlot(slot)), mut)
 
 		if err != nil {
 			return err
@@ -464,9 +510,9 @@ func (bigtable *Bigtable) SaveProposalAssignments(epoch uint64, assignments map[
 	return nil
 }
 
-func (bigtable *Bigtable) SaveSyncCommitteesAssignments(startSlot, endSlot uint64, validators []uint64) error {
+func (bigtable *Bigtable) SaveSyncCommitteesAssignments(ctx context.Context, startSlot, endSlot uint64, validators []uint64) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
 	defer cancel()
 
 	start := time.Now()
@@ -487,7 +533,7 @@ func (bigtable *Bigtable) SaveSyncCommitteesAssignments(startSlot, endSlot uint6
 
 	logger.Infof("saving %v mutations for sync duties", len(muts))
 
-	errs, err := bigtable.tableBeaconchain.ApplyBulk(ctx, keys, muts)
+	errs, err := bigtable.applyBulk(ctx, bigtable.tableBeaconchain, keys, muts)
 
 	if err != nil {
 		return err
@@ -503,9 +549,9 @@ func (bigtable *Bigtable) SaveSyncCommitteesAssignments(startSlot, endSlot uint6
 	return nil
 }
 
-func (bigtable *Bigtable) SaveAttestations(blocks map[uint64]map[string]*types.Block) error {
+func (bigtable *Bigtable) SaveAttestations(ctx context.Context, blocks map[uint64]map[string]*types.Block) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -544,7 +590,7 @@ func (bigtable *Bigtable) SaveAttestations(blocks map[uint64]map[string]*types.B
 		for validator, inclusionSlot := range inclusions {
 			mut.Set(ATTESTATIONS_FAMILY, fmt.Sprintf("%d", validator), gcp_bigtable.Timestamp((max_block_number-inclusionSlot)*1000), []byte{})
 		}
-		err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(attestedSlot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(attestedSlot)), mut)
+		err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(attestedSlot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(attestedSlot)), mut)
 
 		if err != nil {
 			return err
@@ -554,9 +600,9 @@ func (bigtable *Bigtable) SaveAttestations(blocks map[uint64]map[string]*types.B
 	return nil
 }
 
-func (bigtable *Bigtable) SaveProposals(blocks map[uint64]map[string]*types.Block) error {
+func (bigtable *Bigtable) SaveProposals(ctx context.Context, blocks map[uint64]map[string]*types.Block) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -577,7 +623,7 @@ func (bigtable *Bigtable) SaveProposals(blocks map[uint64]map[string]*types.Bloc
 			}
 			mut := gcp_bigtable.NewMutation()
 			mut.Set(PROPOSALS_FAMILY, fmt.Sprintf("%d", b.Proposer), gcp_bigtable.Timestamp((max_block_number-b.Slot)*1000), []byte{})
-			err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(b.Slot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(b.Slot)), mut)
+			err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(b.Slot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(b.Slot)), mut)
 			if err != nil {
 				return err
 			}
@@ -587,9 +633,9 @@ func (bigtable *Bigtable) SaveProposals(blocks map[uint64]map[string]*types.Bloc
 	return nil
 }
 
-func (bigtable *Bigtable) SaveSyncComitteeDuties(blocks map[uint64]map[string]*types.Block) error {
+func (bigtable *Bigtable) SaveSyncComitteeDuties(ctx context.Context, blocks map[uint64]map[string]*types.Block) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -637,7 +683,7 @@ func (bigtable *Bigtable) SaveSyncComitteeDuties(blocks map[uint64]map[string]*t
 				mut.Set(SYNC_COMMITTEES_FAMILY, fmt.Sprintf("%d", validator), gcp_bigtable.Timestamp(0), []byte{})
 			}
 		}
-		err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(slot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(slot)), mut)
+		err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:%s:s:%s", bigtable.chainId, reversedPaddedEpoch(slot/utils.Config.Chain.Config.SlotsPerEpoch), reversedPaddedSlot(slot)), mut)
 
 		if err != nil {
 			return err
@@ -647,7 +693,7 @@ func (bigtable *Bigtable) SaveSyncComitteeDuties(blocks map[uint64]map[string]*t
 	return nil
 }
 
-func (bigtable *Bigtable) GetValidatorBalanceHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
+func (bigtable *Bigtable) GetValidatorBalanceHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorBalance, error) {
 
 	valLen := len(validators)
 	getAllThreshold := 1000
@@ -656,7 +702,7 @@ func (bigtable *Bigtable) GetValidatorBalanceHistory(validators []uint64, startE
 		validatorMap[validatorIndex] = true
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	ranges := bigtable.getEpochRanges(startEpoch, endEpoch)
@@ -730,7 +776,7 @@ func (bigtable *Bigtable) GetValidatorBalanceHistory(validators []uint64, startE
 		return true
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, handleRow, gcp_bigtable.RowFilter(filter))
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, handleRow, gcp_bigtable.RowFilter(filter))
 	if err != nil {
 		return nil, err
 	}
@@ -738,10 +784,10 @@ func (bigtable *Bigtable) GetValidatorBalanceHistory(validators []uint64, startE
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
+func (bigtable *Bigtable) GetValidatorAttestationHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorAttestation, error) {
 	valLen := len(validators)
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*5))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
 	defer cancel()
 
 	ranges := bigtable.getSlotRanges(startEpoch, endEpoch)
@@ -774,7 +820,7 @@ func (bigtable *Bigtable) GetValidatorAttestationHistory(validators []uint64, st
 			gcp_bigtable.LatestNFilter(1),
 		)
 	}
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		keySplit := strings.Split(r.Key(), ":")
 
 		attesterSlot, err := strconv.ParseUint(keySplit[4], 10, 64)
@@ -828,10 +874,10 @@ func (bigtable *Bigtable) GetValidatorAttestationHistory(validators []uint64, st
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorMissedAttestationHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
+func (bigtable *Bigtable) GetValidatorMissedAttestationHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]bool, error) {
 	valLen := len(validators)
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*20))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*20))
 	defer cancel()
 
 	ranges := bigtable.getSlotRanges(startEpoch, endEpoch)
@@ -866,7 +912,7 @@ func (bigtable *Bigtable) GetValidatorMissedAttestationHistory(validators []uint
 		)
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		keySplit := strings.Split(r.Key(), ":")
 
 		attesterSlot, err := strconv.ParseUint(keySplit[4], 10, 64)
@@ -909,8 +955,8 @@ func (bigtable *Bigtable) GetValidatorMissedAttestationHistory(validators []uint
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorSyncDutiesHistoryOrdered(validatorIndex uint64, startEpoch uint64, endEpoch uint64, reverseOrdering bool) ([]*types.ValidatorSyncParticipation, error) {
-	res, err := bigtable.getValidatorSyncDutiesHistory([]uint64{validatorIndex}, startEpoch, endEpoch)
+func (bigtable *Bigtable) GetValidatorSyncDutiesHistoryOrdered(ctx context.Context, validatorIndex uint64, startEpoch uint64, endEpoch uint64, reverseOrdering bool) ([]*types.ValidatorSyncParticipation, error) {
+	res, err := bigtable.getValidatorSyncDutiesHistory(ctx, []uint64{validatorIndex}, startEpoch, endEpoch)
 	if err != nil {
 		return nil, err
 	}
@@ -920,8 +966,8 @@ func (bigtable *Bigtable) GetValidatorSyncDutiesHistoryOrdered(validatorIndex ui
 	return res[validatorIndex], nil
 }
 
-func (bigtable *Bigtable) getValidatorSyncDutiesHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorSyncParticipation, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*5))
+func (bigtable *Bigtable) getValidatorSyncDutiesHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorSyncParticipation, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
 	defer cancel()
 
 	ranges := bigtable.getSlotRanges(startEpoch, endEpoch)
@@ -952,7 +998,7 @@ func (bigtable *Bigtable) getValidatorSyncDutiesHistory(validators []uint64, sta
 		)
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 
 		for _, ri := range r[SYNC_COMMITTEES_FAMILY] {
 			keySplit := strings.Split(r.Key(), ":")
@@ -1000,14 +1046,14 @@ func (bigtable *Bigtable) getValidatorSyncDutiesHistory(validators []uint64, sta
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorMissedAttestationsCount(validators []uint64, firstEpoch uint64, lastEpoch uint64) (map[uint64]*types.ValidatorMissedAttestationsStatistic, error) {
+func (bigtable *Bigtable) GetValidatorMissedAttestationsCount(ctx context.Context, validators []uint64, firstEpoch uint64, lastEpoch uint64) (map[uint64]*types.ValidatorMissedAttestationsStatistic, error) {
 	if firstEpoch > lastEpoch {
 		return nil, fmt.Errorf("GetValidatorMissedAttestationsCount received an invalid firstEpoch (%d) and lastEpoch (%d) combination", firstEpoch, lastEpoch)
 	}
 
 	res := make(map[uint64]*types.ValidatorMissedAttestationsStatistic)
 
-	data, err := bigtable.GetValidatorMissedAttestationHistory(validators, firstEpoch, lastEpoch)
+	data, err := bigtable.GetValidatorMissedAttestationHistory(ctx, validators, firstEpoch, lastEpoch)
 
 	if err != nil {
 		return nil, err
@@ -1028,8 +1074,8 @@ func (bigtable *Bigtable) GetValidatorMissedAttestationsCount(validators []uint6
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorSyncDutiesStatistics(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*types.ValidatorSyncDutiesStatistic, error) {
-	data, err := bigtable.getValidatorSyncDutiesHistory(validators, startEpoch, endEpoch)
+func (bigtable *Bigtable) GetValidatorSyncDutiesStatistics(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*types.ValidatorSyncDutiesStatistic, error) {
+	data, err := bigtable.getValidatorSyncDutiesHistory(ctx, validators, startEpoch, endEpoch)
 
 	if err != nil {
 		return nil, err
@@ -1056,8 +1102,8 @@ func (bigtable *Bigtable) GetValidatorSyncDutiesStatistics(validators []uint64,
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorSyncCommitteesStats(validators []uint64, startEpoch uint64, endEpoch uint64) (types.SyncCommitteesStats, error) {
-	res, err := bigtable.getValidatorSyncDutiesHistory(validators, startEpoch, endEpoch)
+func (bigtable *Bigtable) GetValidatorSyncCommitteesStats(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (types.SyncCommitteesStats, error) {
+	res, err := bigtable.getValidatorSyncDutiesHistory(ctx, validators, startEpoch, endEpoch)
 	if err != nil {
 		return types.SyncCommitteesStats{}, fmt.Errorf("error retrieving validator sync participations data from bigtable: %v", err)
 	}
@@ -1088,8 +1134,8 @@ func (bigtable *Bigtable) GetValidatorSyncCommitteesStats(validators []uint64, s
 }
 
 // returns the validator attestation effectiveness in %
-func (bigtable *Bigtable) GetValidatorEffectiveness(validators []uint64, epoch uint64) ([]*types.ValidatorEffectiveness, error) {
-	data, err := bigtable.GetValidatorAttestationHistory(validators, epoch-100, epoch)
+func (bigtable *Bigtable) GetValidatorEffectiveness(ctx context.Context, validators []uint64, epoch uint64) ([]*types.ValidatorEffectiveness, error) {
+	data, err := bigtable.GetValidatorAttestationHistory(ctx, validators, epoch-100, epoch)
 
 	if err != nil {
 		return nil, err
@@ -1128,14 +1174,14 @@ func (bigtable *Bigtable) GetValidatorEffectiveness(validators []uint64, epoch u
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorBalanceStatistics(startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
+func (bigtable *Bigtable) GetValidatorBalanceStatistics(ctx context.Context, startEpoch, endEpoch uint64) (map[uint64]*types.ValidatorBalanceStatistic, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*10))
 	defer cancel()
 
 	ranges := bigtable.getEpochRanges(startEpoch, endEpoch)
 	res := make(map[uint64]*types.ValidatorBalanceStatistic)
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		keySplit := strings.Split(r.Key(), ":")
 
 		epoch, err := strconv.ParseUint(keySplit[3], 10, 64)
@@ -1209,8 +1255,8 @@ func (bigtable *Bigtable) GetValidatorBalanceStatistics(startEpoch, endEpoch uin
 	return res, nil
 }
 
-func (bigtable *Bigtable) GetValidatorProposalHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorProposal, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetValidatorProposalHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64][]*types.ValidatorProposal, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	ranges := bigtable.getSlotRanges(startEpoch, endEpoch)
@@ -1241,7 +1287,7 @@ func (bigtable *Bigtable) GetValidatorProposalHistory(validators []uint64, start
 		)
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		for _, ri := range r[PROPOSALS_FAMILY] {
 			keySplit := strings.Split(r.Key(), ":")
 
@@ -1290,8 +1336,8 @@ func (bigtable *Bigtable) GetValidatorProposalHistory(validators []uint64, start
 	return res, nil
 }
 
-func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[uint64]*itypes.ValidatorEpochIncome) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+func (bigtable *Bigtable) SaveValidatorIncomeDetails(ctx context.Context, epoch uint64, rewards map[uint64]*itypes.ValidatorEpochIncome) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	start := time.Now()
@@ -1314,7 +1360,7 @@ func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[u
 		mut.Set(INCOME_DETAILS_COLUMN_FAMILY, fmt.Sprintf("%d", i), ts, data)
 
 		if muts%100000 == 0 {
-			err := bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
+			err := bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
 
 			if err != nil {
 				return err
@@ -1345,7 +1391,7 @@ func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[u
 
 	mut.Set(STATS_COLUMN_FAMILY, SUM_COLUMN, ts, sum)
 
-	err = bigtable.tableBeaconchain.Apply(ctx, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
+	err = bigtable.apply(ctx, bigtable.tableBeaconchain, fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch)), mut)
 	if err != nil {
 		return err
 	}
@@ -1354,8 +1400,8 @@ func (bigtable *Bigtable) SaveValidatorIncomeDetails(epoch uint64, rewards map[u
 	return nil
 }
 
-func (bigtable *Bigtable) GetEpochIncomeHistoryDescending(startEpoch uint64, endEpoch uint64) (*itypes.ValidatorEpochIncome, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEpochIncomeHistoryDescending(ctx context.Context, startEpoch uint64, endEpoch uint64) (*itypes.ValidatorEpochIncome, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	ranges := bigtable.getEpochRanges(startEpoch, endEpoch)
@@ -1365,7 +1411,7 @@ func (bigtable *Bigtable) GetEpochIncomeHistoryDescending(startEpoch uint64, end
 
 	res := itypes.ValidatorEpochIncome{}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		if len(r[STATS_COLUMN_FAMILY]) == 0 {
 			return false
 		}
@@ -1384,9 +1430,9 @@ func (bigtable *Bigtable) GetEpochIncomeHistoryDescending(startEpoch uint64, end
 	return &res, nil
 }
 
-func (bigtable *Bigtable) GetEpochIncomeHistory(epoch uint64) (*itypes.ValidatorEpochIncome, error) {
+func (bigtable *Bigtable) GetEpochIncomeHistory(ctx context.Context, epoch uint64) (*itypes.ValidatorEpochIncome, error) {
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	key := fmt.Sprintf("%s:e:b:%s", bigtable.chainId, reversedPaddedEpoch(epoch))
@@ -1395,7 +1441,7 @@ func (bigtable *Bigtable) GetEpochIncomeHistory(epoch uint64) (*itypes.Validator
 	columnFilter := gcp_bigtable.ColumnFilter(SUM_COLUMN)
 	filter := gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(family, columnFilter))
 
-	row, err := bigtable.tableBeaconchain.ReadRow(ctx, key, filter)
+	row, err := bigtable.readRow(ctx, bigtable.tableBeaconchain, key, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error reading income statistics from bigtable for epoch: %v err: %w", epoch, err)
 	}
@@ -1410,7 +1456,7 @@ func (bigtable *Bigtable) GetEpochIncomeHistory(epoch uint64) (*itypes.Validator
 	}
 
 	// if there is no result we have to calculate the sum
-	income, err := bigtable.GetValidatorIncomeDetailsHistory([]uint64{}, epoch, 1)
+	income, err := bigtable.GetValidatorIncomeDetailsHistory(ctx, []uint64{}, epoch, 1)
 	if err != nil {
 		logger.WithError(err).Error("error getting validator income history")
 	}
@@ -1441,12 +1487,12 @@ func (bigtable *Bigtable) GetEpochIncomeHistory(epoch uint64) (*itypes.Validator
 
 // GetValidatorIncomeDetailsHistory returns the validator income details
 // startEpoch & endEpoch are inclusive
-func (bigtable *Bigtable) GetValidatorIncomeDetailsHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]*itypes.ValidatorEpochIncome, error) {
+func (bigtable *Bigtable) GetValidatorIncomeDetailsHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]map[uint64]*itypes.ValidatorEpochIncome, error) {
 	if startEpoch > endEpoch {
 		startEpoch = 0
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*180)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*180)
 	defer cancel()
 
 	ranges := bigtable.getEpochRanges(startEpoch, endEpoch)
@@ -1483,7 +1529,7 @@ func (bigtable *Bigtable) GetValidatorIncomeDetailsHistory(validators []uint64,
 		)
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		keySplit := strings.Split(r.Key(), ":")
 
 		epoch, err := strconv.ParseUint(keySplit[3], 10, 64)
@@ -1524,12 +1570,12 @@ func (bigtable *Bigtable) GetValidatorIncomeDetailsHistory(validators []uint64,
 
 // GetValidatorIncomeDetailsHistory returns the validator income details
 // startEpoch & endEpoch are inclusive
-func (bigtable *Bigtable) GetAggregatedValidatorIncomeDetailsHistory(validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*itypes.ValidatorEpochIncome, error) {
+func (bigtable *Bigtable) GetAggregatedValidatorIncomeDetailsHistory(ctx context.Context, validators []uint64, startEpoch uint64, endEpoch uint64) (map[uint64]*itypes.ValidatorEpochIncome, error) {
 	if startEpoch > endEpoch {
 		startEpoch = 0
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*10))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*10))
 	defer cancel()
 
 	ranges := bigtable.getEpochRanges(startEpoch, endEpoch)
@@ -1568,7 +1614,7 @@ func (bigtable *Bigtable) GetAggregatedValidatorIncomeDetailsHistory(validators
 		)
 	}
 
-	err := bigtable.tableBeaconchain.ReadRows(ctx, ranges, func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableBeaconchain, ranges, func(r gcp_bigtable.Row) bool {
 		keySplit := strings.Split(r.Key(), ":")
 
 		epoch, err := strconv.ParseUint(keySplit[3], 10, 64)
@@ -1624,7 +1670,7 @@ func (bigtable *Bigtable) GetAggregatedValidatorIncomeDetailsHistory(validators
 }
 
 // Deletes all block data from bigtable
-func (bigtable *Bigtable) DeleteEpoch(epoch uint64) error {
+func (bigtable *Bigtable) DeleteEpoch(ctx context.Context, epoch uint64) error {
 
 	// First receive all keys that were written by this block (entities & indices)
 	keys := make([]string, 0, 33)
@@ -1653,7 +1699,7 @@ func (bigtable *Bigtable) DeleteEpoch(epoch uint64) error {
 		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
 	}
 
-	err := bigtable.WriteBulk(mutsDelete, bigtable.tableBeaconchain)
+	err := bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableBeaconchain)
 	if err != nil {
 		return err
 	}
@@ -1723,7 +1769,7 @@ func (bigtable *Bigtable) getEpochRanges(startEpoch uint64, endEpoch uint64) gcp
 	return ranges
 }
 
-func GetCurrentDayClIncome(validator_indices []uint64) (map[uint64]int64, map[uint64]int64, error) {
+func GetCurrentDayClIncome(ctx context.Context, validator_indices []uint64) (map[uint64]int64, map[uint64]int64, error) {
 	dayIncome := make(map[uint64]int64)
 	dayProposerIncome := make(map[uint64]int64)
 	lastDay := int64(0)
@@ -1736,7 +1782,7 @@ func GetCurrentDayClIncome(validator_indices []uint64) (map[uint64]int64, map[ui
 	currentDay := uint64(lastDay + 1)
 	startEpoch := currentDay * utils.EpochsPerDay()
 	endEpoch := startEpoch + utils.EpochsPerDay() - 1
-	income, err := BigtableClient.GetValidatorIncomeDetailsHistory(validator_indices, startEpoch, endEpoch)
+	income, err := BigtableClient.GetValidatorIncomeDetailsHistory(ctx, validator_indices, startEpoch, endEpoch)
 	if err != nil {
 		return dayIncome, dayProposerIncome, err
 	}
@@ -1755,8 +1801,8 @@ func GetCurrentDayClIncome(validator_indices []uint64) (map[uint64]int64, map[ui
 	return dayIncome, dayProposerIncome, nil
 }
 
-func GetCurrentDayProposerIncomeTotal(validator_indices []uint64) (int64, error) {
-	_, proposerIncome, err := GetCurrentDayClIncome(validator_indices)
+func GetCurrentDayProposerIncomeTotal(ctx context.Context, validator_indices []uint64) (int64, error) {
+	_, proposerIncome, err := GetCurrentDayClIncome(ctx, validator_indices)
 
 	if err != nil {
 		return 0, err
@@ -0,0 +1,442 @@
+package db
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"math/big"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/mr-tron/base58/base58"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+)
+
+// The activity of an address interleaves its transactions, internal transactions and token transfers by time. The time indexes
+// of all of them end in <reversePaddedBigtableTimestamp>:<paddedTxIndex>[:<paddedIndex>], so the suffixes of their keys following
+// the index prefix order the entries of all indexes newest first. A page is a k-way merge over a scan of each index, its page token
+// holds the suffix of the last entry taken from each index.
+var activityIndexes = []string{"TX", "ITX", "ERC20", "ERC721", "ERC1155"}
+
+// an index that has been read to its end is marked with this position in the page token
+const activityIndexDone = "-"
+
+const activityPageSize = 25
+
+// activityEntry is an index row of the activity of an address
+type activityEntry struct {
+	index   int
+	suffix  string
+	dataKey string
+}
+
+// activityHeap holds the next unmerged entry of each index
+type activityHeap struct {
+	entries [][]activityEntry
+	heads   []int
+}
+
+func (h *activityHeap) Len() int { return len(h.heads) }
+func (h *activityHeap) Less(i, j int) bool {
+	a, b := h.entries[h.heads[i]][0], h.entries[h.heads[j]][0]
+	if a.suffix != b.suffix {
+		return a.suffix < b.suffix
+	}
+	return a.index < b.index
+}
+func (h *activityHeap) Swap(i, j int)      { h.heads[i], h.heads[j] = h.heads[j], h.heads[i] }
+func (h *activityHeap) Push(x interface{}) { h.heads = append(h.heads, x.(int)) }
+func (h *activityHeap) Pop() interface{} {
+	head := h.heads[len(h.heads)-1]
+	h.heads = h.heads[:len(h.heads)-1]
+	return head
+}
+
+// mergeActivity merges the entries read from each index, which are ordered by their suffix, and returns up to limit entries.
+// If bounded is set, the entries following bound are not merged.
+func mergeActivity(entries [][]activityEntry, bound string, bounded bool, limit int) []activityEntry {
+	h := &activityHeap{entries: make([][]activityEntry, len(entries))}
+	for i, indexEntries := range entries {
+		h.entries[i] = indexEntries
+		if len(indexEntries) > 0 {
+			h.heads = append(h.heads, i)
+		}
+	}
+	heap.Init(h)
+
+	merged := make([]activityEntry, 0, limit)
+	for h.Len() > 0 && len(merged) < limit {
+		i := h.heads[0]
+		entry := h.entries[i][0]
+		if bounded && entry.suffix > bound {
+			break
+		}
+		merged = append(merged, entry)
+		h.entries[i] = h.entries[i][1:]
+		if len(h.entries[i]) > 0 {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return merged
+}
+
+func encodeActivityPageToken(positions []string) string {
+	return base58.FastBase58Encoding([]byte(strings.Join(positions, ",")))
+}
+
+// decodeActivityPageToken returns the position of each activity index, positions are validated like the keys of DecodePageToken
+func decodeActivityPageToken(token string) ([]string, error) {
+	if token == "" {
+		return make([]string, len(activityIndexes)), nil
+	}
+	decoded, err := base58.FastBase58Decoding(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page token: %w", err)
+	}
+	if len(decoded) > maxPageTokenLength*len(activityIndexes) {
+		return nil, fmt.Errorf("invalid page token length %v", len(decoded))
+	}
+	positions := strings.Split(string(decoded), ",")
+	if len(positions) != len(activityIndexes) {
+		return nil, fmt.Errorf("invalid page token with %v positions", len(positions))
+	}
+	for _, position := range positions {
+		if position == activityIndexDone {
+			continue
+		}
+		for _, c := range position {
+			if !(c >= '0' && c <= '9' || c == ':') {
+				return nil, fmt.Errorf("invalid character %q in page token", c)
+			}
+		}
+	}
+	return positions, nil
+}
+
+func (bigtable *Bigtable) activityIndexPrefix(address []byte, index string) string {
+	return fmt.Sprintf("%s:I:%s:%x:%s:", bigtable.chainId, index, address, FILTER_TIME)
+}
+
+// scanActivity merges up to limit entries of the activity indexes of an address that follow their positions and lie within
+// [first, last) of the key suffixes, an empty last does not bound the scan. The positions are advanced to the merged entries and
+// indexes that have been read to their end are marked as done.
+func (bigtable *Bigtable) scanActivity(ctx context.Context, address []byte, positions []string, first, last string, limit int64) ([]activityEntry, error) {
+	entries := make([][]activityEntry, len(activityIndexes))
+	truncated := make([]bool, len(activityIndexes))
+	g, gCtx := errgroup.WithContext(ctx)
+	for i, index := range activityIndexes {
+		if positions[i] == activityIndexDone {
+			continue
+		}
+		i := i
+		prefix := bigtable.activityIndexPrefix(address, index)
+		start := prefix + first
+		if positions[i] != "" {
+			// add \x00 to the row range such that we skip the previous value
+			start = prefix + positions[i] + "\x00"
+		}
+		end := prefixSuccessor(prefix, 5)
+		if last != "" {
+			end = prefix + last
+		}
+		g.Go(func() error {
+			// the merge has to know which of the scans ran out of the latency budget
+			indexCtx := forkLatencyBudget(gCtx)
+			defer func() {
+				truncated[i] = IsTruncated(indexCtx)
+			}()
+			return bigtable.readIndexRows(indexCtx, start, end, limit, func(row gcp_bigtable.Row) bool {
+				entries[i] = append(entries[i], activityEntry{
+					index:   i,
+					suffix:  strings.TrimPrefix(row.Key(), prefix),
+					dataKey: strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, DEFAULT_FAMILY+":"),
+				})
+				return true
+			})
+		})
+	}
+	err := g.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// an index that has not been read to its end may continue with entries preceding the entries read from the other indexes,
+	// the merge must not go beyond the last entry read from such an index
+	complete := make([]bool, len(activityIndexes))
+	bound, bounded := "", false
+	for i := range activityIndexes {
+		complete[i] = positions[i] == activityIndexDone || (int64(len(entries[i])) < limit && !truncated[i])
+		if complete[i] {
+			continue
+		}
+		lastRead := positions[i]
+		if len(entries[i]) > 0 {
+			lastRead = entries[i][len(entries[i])-1].suffix
+		}
+		if !bounded || lastRead < bound {
+			bound, bounded = lastRead, true
+		}
+	}
+	merged := mergeActivity(entries, bound, bounded, int(limit))
+
+	consumed := make([]int, len(activityIndexes))
+	for _, entry := range merged {
+		consumed[entry.index]++
+		positions[entry.index] = entry.suffix
+	}
+	for i := range activityIndexes {
+		if complete[i] && consumed[i] == len(entries[i]) {
+			positions[i] = activityIndexDone
+		}
+	}
+	return merged, nil
+}
+
+// GetAddressActivityTableData returns a page of the transactions, internal transactions and ERC20, ERC721 and ERC1155 transfers of an address ordered by time.
+// If hideAirdrops is set, the airdrops received by the address are left out and the page is filled with the following entries.
+func (bigtable *Bigtable) GetAddressActivityTableData(ctx context.Context, address []byte, pageToken string, hideAirdrops bool) (*types.DataTableResponse, error) {
+	positions, err := decodeActivityPageToken(pageToken)
+	if err != nil {
+		return nil, err
+	}
+	if pageToken == "" {
+		// a truncated first page has to be continued from the start
+		pageToken = encodeActivityPageToken(positions)
+	}
+
+	scanCtx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	merged := []activityEntry{}
+	rows := make(map[string]proto.Message)
+	skipped := newSkippedRows("AddressActivity")
+	scanned := 0
+	for len(merged) < activityPageSize {
+		entries, err := bigtable.scanActivity(scanCtx, address, positions, "", "", int64(activityPageSize-len(merged)))
+		if err != nil {
+			return nil, err
+		}
+		scanned += len(entries)
+
+		read, err := bigtable.readActivityRows(scanCtx, entries, skipped)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if hideAirdrops && inboundAirdropToken(read[entry.dataKey], address) != nil {
+				continue
+			}
+			merged = append(merged, entry)
+			if m, ok := read[entry.dataKey]; ok {
+				rows[entry.dataKey] = m
+			}
+		}
+		if len(entries) == 0 || !hideAirdrops || IsTruncated(scanCtx) {
+			break
+		}
+	}
+	// corrupted or missing rows have been skipped, the remaining ones are still shown
+	partial := skipped.err()
+
+	tableData, err := bigtable.activityTableRows(ctx, address, merged, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.DataTableResponse{
+		Data:     tableData,
+		Warnings: PartialResultWarnings(partial),
+	}
+	// the page after the last entries is empty and ends the activity
+	if scanned > 0 {
+		data.PagingToken = encodeActivityPageToken(positions)
+	}
+
+	truncatePage(ctx, data, pageToken)
+	return data, partial
+}
+
+// readActivityRows reads and decodes the data rows of the entries keyed by their data key, rows that are missing or corrupted are
+// recorded in skipped
+func (bigtable *Bigtable) readActivityRows(ctx context.Context, entries []activityEntry, skipped *skippedRows) (map[string]proto.Message, error) {
+	keys := make([]string, 0, len(entries))
+	indexOfKey := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		keys = append(keys, entry.dataKey)
+		indexOfKey[entry.dataKey] = entry.index
+	}
+
+	rows := make(map[string]proto.Message, len(entries))
+	if len(keys) == 0 {
+		return rows, nil
+	}
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
+		var m proto.Message
+		switch activityIndexes[indexOfKey[row.Key()]] {
+		case "TX":
+			m = &types.Eth1TransactionIndexed{}
+		case "ITX":
+			m = &types.Eth1InternalTransactionIndexed{}
+		case "ERC20":
+			m = &types.Eth1ERC20Indexed{}
+		case "ERC721":
+			m = &types.Eth1ERC721Indexed{}
+		case "ERC1155":
+			m = &types.ETh1ERC1155Indexed{}
+		}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, m)
+		if err != nil {
+			skipped.corrupt(row.Key(), err)
+			return true
+		}
+		if itx, ok := m.(*types.Eth1InternalTransactionIndexed); ok && itx.Index == 0 {
+			// rows written before the trace position was persisted only carry the index in their key
+			index, err := rowIndexFromKey(row.Key(), 3)
+			if err != nil {
+				skipped.corrupt(row.Key(), err)
+				return true
+			}
+			itx.Index = uint64(index)
+		}
+		rows[row.Key()] = m
+		return true
+	}, skipTombstones())
+	if err != nil {
+		return nil, err
+	}
+	skipped.checkMissing(keys)
+	return rows, nil
+}
+
+// activityTableRows formats the data rows of the merged entries in the order of the entries, entries without a row are left out.
+// Consecutive airdrops of the same token received by the address are grouped into a single row.
+func (bigtable *Bigtable) activityTableRows(ctx context.Context, address []byte, entries []activityEntry, rows map[string]proto.Message) ([][]interface{}, error) {
+	tableData := [][]interface{}{}
+	if len(entries) == 0 {
+		return tableData, nil
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	names := make(map[string]string)
+	tokens := make(map[string]*types.ERC20Metadata)
+	for _, m := range rows {
+		switch t := m.(type) {
+		case *types.Eth1TransactionIndexed:
+			names[string(t.From)] = ""
+			names[string(t.To)] = ""
+		case *types.Eth1InternalTransactionIndexed:
+			names[string(t.From)] = ""
+			names[string(t.To)] = ""
+		case *types.Eth1ERC20Indexed:
+			names[string(t.From)] = ""
+			names[string(t.To)] = ""
+			tokens[string(t.TokenAddress)] = nil
+		case *types.Eth1ERC721Indexed:
+			names[string(t.From)] = ""
+			names[string(t.To)] = ""
+		case *types.ETh1ERC1155Indexed:
+			names[string(t.From)] = ""
+			names[string(t.To)] = ""
+		}
+	}
+	names, tokens, err := bigtable.GetAddressesNamesArMetadata(ctx, &names, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	formatAddress := func(a []byte, token []byte) template.HTML {
+		return utils.FormatAddress(a, token, names[string(a)], false, false, !bytes.Equal(a, address))
+	}
+	for i := 0; i < len(entries); i++ {
+		var row []interface{}
+		switch t := rows[entries[i].dataKey].(type) {
+		case *types.Eth1TransactionIndexed:
+			row = []interface{}{
+				template.HTML("Transaction"),
+				utils.FormatTransactionHash(t.Hash),
+				utils.FormatTimeFromNow(t.Time.AsTime()),
+				formatAddress(t.From, nil),
+				utils.FormatInOutSelf(address, t.From, t.To),
+				formatAddress(t.To, nil),
+				utils.FormatAmount(new(big.Int).SetBytes(t.Value), "Ether", 6),
+			}
+		case *types.Eth1InternalTransactionIndexed:
+			// geth traces include zero-value staticalls
+			if len(t.Value) == 0 {
+				continue
+			}
+			row = []interface{}{
+				template.HTML("Internal"),
+				utils.FormatItxHash(t.ParentHash, t.Index),
+				utils.FormatTimeFromNow(t.Time.AsTime()),
+				formatAddress(t.From, nil),
+				utils.FormatInOutSelf(address, t.From, t.To),
+				formatAddress(t.To, nil),
+				utils.FormatAmount(new(big.Int).SetBytes(t.Value), "Ether", 6),
+			}
+		case *types.Eth1ERC20Indexed:
+			tb := &types.Eth1AddressBalance{
+				Address:  address,
+				Balance:  t.Value,
+				Token:    t.TokenAddress,
+				Metadata: tokens[string(t.TokenAddress)],
+			}
+			row = []interface{}{
+				template.HTML("ERC20"),
+				utils.FormatTransactionHash(t.ParentHash),
+				utils.FormatTimeFromNow(t.Time.AsTime()),
+				formatAddress(t.From, t.TokenAddress),
+				utils.FormatInOutSelf(address, t.From, t.To),
+				formatAddress(t.To, t.TokenAddress),
+				utils.FormatTokenValue(tb) + " " + utils.FormatTokenName(tb),
+			}
+		case *types.Eth1ERC721Indexed:
+			row = []interface{}{
+				template.HTML("ERC721"),
+				utils.FormatTransactionHash(t.ParentHash),
+				utils.FormatTimeFromNow(t.Time.AsTime()),
+				formatAddress(t.From, nil),
+				utils.FormatInOutSelf(address, t.From, t.To),
+				formatAddress(t.To, nil),
+				template.HTML(fmt.Sprintf("#%v ", new(big.Int).SetBytes(t.TokenId))) + utils.FormatAddressAsLink(t.TokenAddress, "", false, true),
+			}
+		case *types.ETh1ERC1155Indexed:
+			row = []interface{}{
+				template.HTML("ERC1155"),
+				utils.FormatTransactionHash(t.ParentHash),
+				utils.FormatTimeFromNow(t.Time.AsTime()),
+				formatAddress(t.From, nil),
+				utils.FormatInOutSelf(address, t.From, t.To),
+				formatAddress(t.To, nil),
+				template.HTML(fmt.Sprintf("%v × #%v ", new(big.Int).SetBytes(t.Value), new(big.Int).SetBytes(t.TokenId))) + utils.FormatAddressAsLink(t.TokenAddress, "", false, true),
+			}
+		default:
+			continue
+		}
+
+		if token := inboundAirdropToken(rows[entries[i].dataKey], address); token != nil {
+			more := 0
+			for i+1 < len(entries) && bytes.Equal(inboundAirdropToken(rows[entries[i+1].dataKey], address), token) {
+				more++
+				i++
+			}
+			row[0] = template.HTML(`<span class="badge badge-light" data-toggle="tooltip" title="Received as part of a mass distribution of the token">Airdrop</span>`)
+			if more > 0 {
+				row[6] = row[6].(template.HTML) + template.HTML(fmt.Sprintf(` <span class="text-muted">and %v more</span>`, more))
+			}
+		}
+		tableData = append(tableData, row)
+	}
+	return tableData, nil
+}
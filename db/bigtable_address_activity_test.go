@@ -0,0 +1,66 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeActivity(t *testing.T) {
+	entries := [][]activityEntry{
+		{{index: 0, suffix: "9223372035188572567:9997"}, {index: 0, suffix: "9223372035188572580:9999"}},
+		{{index: 1, suffix: "9223372035188572567:9997:99998"}, {index: 1, suffix: "9223372035188572570:9999:99999"}},
+		{},
+		{{index: 3, suffix: "9223372035188572567:9996:99999"}},
+	}
+	suffixes := func(merged []activityEntry) []string {
+		s := []string{}
+		for _, entry := range merged {
+			s = append(s, entry.suffix)
+		}
+		return s
+	}
+
+	// a transaction precedes its internal transactions and token transfers, older transactions follow
+	merged := mergeActivity(entries, "", false, 25)
+	expected := []string{
+		"9223372035188572567:9996:99999",
+		"9223372035188572567:9997",
+		"9223372035188572567:9997:99998",
+		"9223372035188572570:9999:99999",
+		"9223372035188572580:9999",
+	}
+	if !reflect.DeepEqual(suffixes(merged), expected) {
+		t.Errorf("unexpected merge %v", suffixes(merged))
+	}
+
+	if merged = mergeActivity(entries, "", false, 2); !reflect.DeepEqual(suffixes(merged), expected[:2]) {
+		t.Errorf("expected the merge to stop at the limit, got %v", suffixes(merged))
+	}
+	if merged = mergeActivity(entries, "9223372035188572570:9999:99999", true, 25); !reflect.DeepEqual(suffixes(merged), expected[:4]) {
+		t.Errorf("expected the merge to stop at the bound, got %v", suffixes(merged))
+	}
+	if merged = mergeActivity(entries, "", true, 25); len(merged) != 0 {
+		t.Errorf("expected nothing to be merged before the start of an index, got %v", suffixes(merged))
+	}
+}
+
+func TestActivityPageToken(t *testing.T) {
+	positions := []string{"9223372035188572567:9997", activityIndexDone, "", "9223372035188572567:9996:99999", activityIndexDone}
+	decoded, err := decodeActivityPageToken(encodeActivityPageToken(positions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, positions) {
+		t.Errorf("decoded positions %v do not match %v", decoded, positions)
+	}
+
+	for _, invalid := range [][]string{
+		{"", "", "", ""},
+		{"", "", "", "", "\xff"},
+		{"", "", "", "", "1:I:TX"},
+	} {
+		if _, err := decodeActivityPageToken(encodeActivityPageToken(invalid)); err == nil {
+			t.Errorf("expected the positions %q to be rejected", invalid)
+		}
+	}
+}
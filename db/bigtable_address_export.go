@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// number of entries merged and decoded per chunk of an address export
+const addressExportChunkSize = 500
+
+// StreamAddressTransfers calls f with the transactions and ERC20, ERC721 and ERC1155 transfers of an address within [from, to],
+// newest first. The indexes are read in chunks so that only a single chunk is held in memory, f is called once per chunk and
+// the export stops at the first error returned by f. Rows that are missing or corrupted are skipped.
+func (bigtable *Bigtable) StreamAddressTransfers(ctx context.Context, address []byte, from, to time.Time, f func([]*types.AddressTransferExport) error) error {
+	if to.Before(from) {
+		return fmt.Errorf("invalid export range %v to %v", from, to)
+	}
+	// the time indexes are ordered newest first, the range ends after the last row of the second preceding from
+	first := fmt.Sprintf("%019d", MAX_INT-to.Unix())
+	last := fmt.Sprintf("%019d", MAX_INT-from.Unix()+1)
+
+	positions := make([]string, len(activityIndexes))
+	for i, index := range activityIndexes {
+		if index == "ITX" {
+			positions[i] = activityIndexDone
+		}
+	}
+	// the export is not limited by the latency budget of the request, it is bounded by the deadline of each chunk instead
+	ctx = withoutLatencyBudget(ctx)
+	for {
+		chunk, more, err := bigtable.exportChunk(ctx, address, positions, first, last)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+		// a chunk whose rows have all been skipped is not passed on
+		if len(chunk) == 0 {
+			continue
+		}
+		err = f(chunk)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// exportChunk merges and decodes the next chunk of an address export, more is false once all indexes have been read
+func (bigtable *Bigtable) exportChunk(ctx context.Context, address []byte, positions []string, first, last string) (chunk []*types.AddressTransferExport, more bool, err error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	merged, err := bigtable.scanActivity(ctx, address, positions, first, last, addressExportChunkSize)
+	if err != nil || len(merged) == 0 {
+		return nil, false, err
+	}
+	// skipped rows are logged and left out of the export
+	rows, err := bigtable.readActivityRows(ctx, merged, newSkippedRows("AddressActivity"))
+	if err != nil {
+		return nil, false, err
+	}
+
+	tokens := make(map[string]*types.ERC20Metadata)
+	for _, entry := range merged {
+		if t, ok := rows[entry.dataKey].(*types.Eth1ERC20Indexed); ok {
+			tokens[string(t.TokenAddress)] = nil
+		}
+	}
+	if len(tokens) > 0 {
+		names := make(map[string]string)
+		_, tokens, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, &tokens)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	chunk = make([]*types.AddressTransferExport, 0, len(merged))
+	for _, entry := range merged {
+		switch t := rows[entry.dataKey].(type) {
+		case *types.Eth1TransactionIndexed:
+			chunk = append(chunk, &types.AddressTransferExport{
+				Type:        "transaction",
+				Hash:        t.Hash,
+				BlockNumber: t.BlockNumber,
+				Time:        t.Time.AsTime(),
+				From:        t.From,
+				To:          t.To,
+				Value:       decimal.NewFromBigInt(new(big.Int).SetBytes(t.Value), -18).String(),
+			})
+		case *types.Eth1ERC20Indexed:
+			export := &types.AddressTransferExport{
+				Type:        "erc20",
+				Hash:        t.ParentHash,
+				BlockNumber: t.BlockNumber,
+				Time:        t.Time.AsTime(),
+				From:        t.From,
+				To:          t.To,
+				Token:       t.TokenAddress,
+			}
+			// the raw amount is exported for tokens without decimals
+			decimals := int32(0)
+			if metadata := tokens[string(t.TokenAddress)]; metadata != nil {
+				decimals = int32(new(big.Int).SetBytes(metadata.Decimals).Int64())
+				export.TokenSymbol = metadata.Symbol
+			}
+			export.Value = decimal.NewFromBigInt(new(big.Int).SetBytes(t.Value), -decimals).String()
+			chunk = append(chunk, export)
+		case *types.Eth1ERC721Indexed:
+			chunk = append(chunk, &types.AddressTransferExport{
+				Type:        "erc721",
+				Hash:        t.ParentHash,
+				BlockNumber: t.BlockNumber,
+				Time:        t.Time.AsTime(),
+				From:        t.From,
+				To:          t.To,
+				Token:       t.TokenAddress,
+				TokenId:     t.TokenId,
+				Value:       "1",
+			})
+		case *types.ETh1ERC1155Indexed:
+			chunk = append(chunk, &types.AddressTransferExport{
+				Type:        "erc1155",
+				Hash:        t.ParentHash,
+				BlockNumber: t.BlockNumber,
+				Time:        t.Time.AsTime(),
+				From:        t.From,
+				To:          t.To,
+				Token:       t.TokenAddress,
+				TokenId:     t.TokenId,
+				Value:       new(big.Int).SetBytes(t.Value).String(),
+			})
+		}
+	}
+	return chunk, true, nil
+}
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"google.golang.org/protobuf/proto"
+)
+
+// The funding of an address is the first successful transfer of value to it, either by a transaction or by an internal transaction.
+// TransformFundings writes every transfer that may be the funding of its recipient to:
+// Row:    <chainID>:FUNDED_BY:<ADDRESS>
+// Family: f
+// Column: data
+// Cell:   Proto<Eth1InternalTransactionIndexed>
+//
+// The timestamp of a cell decreases with the position of the transfer in the chain, so that the latest cell of the row is the
+// earliest transfer regardless of the order the blocks are indexed in. Type is tx for transfers by a transaction and the type of
+// the call frame for internal transactions.
+const FUNDED_BY_TX = "tx"
+
+// fundingTimestamp orders the fundings of an address by the position of their transaction in the chain, the earliest one gets the
+// highest timestamp
+func fundingTimestamp(block uint64, txIdx int) gcp_bigtable.Timestamp {
+	return gcp_bigtable.Timestamp((max_block_number*10000 - (block*10000 + uint64(txIdx))) * 1000)
+}
+
+// fundingsOfBlock returns the first transfer of value to every address that received value in the block
+func fundingsOfBlock(blk *types.Eth1Block) []*types.Eth1InternalTransactionIndexed {
+	fundings := []*types.Eth1InternalTransactionIndexed{}
+	funded := map[string]bool{}
+	add := func(funding *types.Eth1InternalTransactionIndexed) {
+		if len(funding.To) == 0 || bytes.Equal(funding.From, funding.To) || new(big.Int).SetBytes(funding.Value).Sign() == 0 {
+			return
+		}
+		if funded[string(funding.To)] {
+			return
+		}
+		funded[string(funding.To)] = true
+		fundings = append(fundings, funding)
+	}
+
+	for _, tx := range blk.GetTransactions() {
+		if tx.GetErrorMsg() != "" {
+			continue
+		}
+		to := tx.GetTo()
+		if len(to) == 0 {
+			to = tx.GetContractAddress()
+		}
+		add(&types.Eth1InternalTransactionIndexed{
+			ParentHash:  tx.GetHash(),
+			BlockNumber: blk.GetNumber(),
+			Time:        blk.GetTime(),
+			Type:        FUNDED_BY_TX,
+			From:        tx.GetFrom(),
+			To:          to,
+			Value:       tx.GetValue(),
+		})
+
+		for j, itx := range tx.GetItx() {
+			// the top level call is the transaction itself
+			if itx.GetPath() == "[]" || itx.GetErrorMsg() != "" {
+				continue
+			}
+			add(newIndexedItx(blk, tx, itx, j))
+		}
+	}
+	return fundings
+}
+
+// TransformFundings records the transfers of a block that may be the first funding of their recipient. Addresses that have been
+// funded in an earlier block since the indexer started are skipped.
+func (bigtable *Bigtable) TransformFundings(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	txIndexes := make(map[string]int, len(blk.GetTransactions()))
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		txIndexes[string(tx.GetHash())] = i
+	}
+
+	for _, funding := range fundingsOfBlock(blk) {
+		cacheKey := []byte(fmt.Sprintf("%s:FUNDED_BY:%x", bigtable.chainId, funding.To))
+		if cached, err := cache.Get(cacheKey); err == nil && binary.BigEndian.Uint64(cached) < blk.GetNumber() {
+			continue
+		}
+
+		b, err := proto.Marshal(funding)
+		if err != nil {
+			return nil, nil, err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, DATA_COLUMN, fundingTimestamp(blk.GetNumber(), txIndexes[string(funding.ParentHash)]), b)
+
+		bulkData.Keys = append(bulkData.Keys, fmt.Sprintf("%s:FUNDED_BY:%x", bigtable.chainId, funding.To))
+		bulkData.Muts = append(bulkData.Muts, mut)
+
+		cached := make([]byte, 8)
+		binary.BigEndian.PutUint64(cached, blk.GetNumber())
+		cache.Set(cacheKey, cached, int((time.Hour * 48).Seconds()))
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// GetAddressFunding returns the first transfer of value to the given address or nil if no transfer to it has been indexed
+func (bigtable *Bigtable) GetAddressFunding(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	row, err := bigtable.readRow(ctx, bigtable.tableData, fmt.Sprintf("%s:FUNDED_BY:%x", bigtable.chainId, address), skipTombstones(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, nil
+	}
+
+	funding := &types.Eth1InternalTransactionIndexed{}
+	err = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, funding)
+	if err != nil {
+		return nil, err
+	}
+	return funding, nil
+}
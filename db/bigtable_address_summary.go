@@ -0,0 +1,565 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// The summary of an address is maintained incrementally. TransformTx and TransformItx write the contribution of a block to
+// the summaries of the addresses it touches to the metadata updates table:
+// Row:    <chainID>:SUMMARY:<ADDRESS>
+// Family: f
+// Column: <reversedPaddedBlockNumber>:TX | <reversedPaddedBlockNumber>:ITX
+// Cell:   Json<addressSummaryDelta>
+//
+// ProcessAddressSummaryUpdates folds the deltas of blocks that can no longer be reorged into the summary in the data table:
+// Row:    <chainID>:SUMMARY:<ADDRESS>
+// Family: f
+// Column: summary
+// Cell:   Json<addressSummaryRow>
+//
+// Deltas of orphaned blocks are deleted before they are folded. A delta of a block at or below the folded block of a summary
+// has been folded before and is dropped, as the data indexing revisits recent blocks the deltas are only folded well below its offset.
+// Blocks indexed after newer blocks have been folded (e.g. when backfilling) are therefore not reflected in the summary, the summary
+// is flagged as having gaps then and its counts are no longer treated as exact.
+//
+// The first block the deltas have been written for is recorded in the data table:
+// Row:    <chainID>:SUMMARY_START
+// Family: f
+// Column: summary
+// Cell:   Json<addressSummaryStart>
+//
+// The counts of a summary are only complete if the address has no transactions before that block.
+const ADDRESS_SUMMARY_COLUMN = "summary"
+
+// addressSummaryDelta is the contribution of a single block to the summary of an address
+type addressSummaryDelta struct {
+	Block       uint64 `json:"block"`
+	Time        int64  `json:"time"`
+	Txs         uint64 `json:"txs,omitempty"`
+	SentTxs     uint64 `json:"sent_txs,omitempty"`
+	ReceivedTxs uint64 `json:"received_txs,omitempty"`
+	InternalTxs uint64 `json:"itxs,omitempty"`
+	Sent        []byte `json:"sent,omitempty"`
+	Received    []byte `json:"received,omitempty"`
+	IsContract  bool   `json:"contract,omitempty"`
+}
+
+// addressSummaryRow is the stored summary together with the highest block folded into it and whether deltas of blocks below it
+// had to be dropped
+type addressSummaryRow struct {
+	Summary *types.AddressSummary `json:"summary"`
+	Folded  uint64                `json:"folded"`
+	Gaps    bool                  `json:"gaps,omitempty"`
+}
+
+// addressSummaryStart is the first block whose transactions have been written as summary deltas
+type addressSummaryStart struct {
+	Block uint64 `json:"block"`
+	Time  int64  `json:"time"`
+}
+
+func addBigBytes(a, b []byte) []byte {
+	if len(b) == 0 {
+		return a
+	}
+	return new(big.Int).Add(new(big.Int).SetBytes(a), new(big.Int).SetBytes(b)).Bytes()
+}
+
+// addressSummaryDeltas collects the deltas of the addresses touched by a block, keyed by the address bytes
+type addressSummaryDeltas map[string]*addressSummaryDelta
+
+func (deltas addressSummaryDeltas) get(blk *types.Eth1Block, address []byte) *addressSummaryDelta {
+	delta := deltas[string(address)]
+	if delta == nil {
+		delta = &addressSummaryDelta{Block: blk.GetNumber(), Time: blk.GetTime().AsTime().Unix()}
+		deltas[string(address)] = delta
+	}
+	return delta
+}
+
+// mutations returns the mutations writing the deltas to the given column of the block, ordered by address
+func (deltas addressSummaryDeltas) mutations(chainId string, column string) (*types.BulkMutations, error) {
+	addresses := make([]string, 0, len(deltas))
+	for address := range deltas {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	muts := &types.BulkMutations{}
+	for _, address := range addresses {
+		b, err := json.Marshal(deltas[address])
+		if err != nil {
+			return nil, err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, column, gcp_bigtable.Timestamp(0), b)
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:SUMMARY:%x", chainId, address))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	return muts, nil
+}
+
+func addressSummaryTxColumn(block uint64) string {
+	return reversedPaddedBlockNumber(block) + ":TX"
+}
+
+func addressSummaryItxColumn(block uint64) string {
+	return reversedPaddedBlockNumber(block) + ":ITX"
+}
+
+// addressSummaryTxDeltas returns the contribution of the transactions of a block to the summaries of their senders and recipients
+func addressSummaryTxDeltas(blk *types.Eth1Block) addressSummaryDeltas {
+	deltas := addressSummaryDeltas{}
+	for _, tx := range blk.GetTransactions() {
+		to := tx.GetTo()
+		isContractCreation := !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS)
+		if isContractCreation {
+			to = tx.GetContractAddress()
+		}
+
+		from := deltas.get(blk, tx.GetFrom())
+		from.Txs++
+		from.SentTxs++
+		recipient := deltas.get(blk, to)
+		if !bytes.Equal(to, tx.GetFrom()) {
+			recipient.Txs++
+		}
+		// a transaction to the sender itself is both sent and received, matching its TO and FROM index rows
+		recipient.ReceivedTxs++
+		recipient.IsContract = recipient.IsContract || isContractCreation
+
+		// failed transactions do not transfer any value
+		if tx.GetErrorMsg() == "" {
+			from.Sent = addBigBytes(from.Sent, tx.GetValue())
+			recipient.Received = addBigBytes(recipient.Received, tx.GetValue())
+		}
+	}
+	return deltas
+}
+
+// addressSummaryItxDeltas returns the contribution of the value transferring internal transactions of a block, contracts deployed
+// by factories are flagged even if the deployment does not transfer any value
+func addressSummaryItxDeltas(blk *types.Eth1Block) addressSummaryDeltas {
+	deltas := addressSummaryDeltas{}
+	for _, tx := range blk.GetTransactions() {
+		for _, itx := range tx.GetItx() {
+			if itx.GetType() == types.ContractCreationCreate || itx.GetType() == types.ContractCreationCreate2 {
+				deltas.get(blk, itx.GetTo()).IsContract = true
+			}
+			// same selection as the internal transactions indexed by TransformItx
+			if itx.Path == "[]" || bytes.Equal(itx.Value, []byte{0x0}) {
+				continue
+			}
+
+			from := deltas.get(blk, itx.GetFrom())
+			from.InternalTxs++
+			from.Sent = addBigBytes(from.Sent, itx.GetValue())
+			to := deltas.get(blk, itx.GetTo())
+			if !bytes.Equal(itx.GetTo(), itx.GetFrom()) {
+				to.InternalTxs++
+			}
+			to.Received = addBigBytes(to.Received, itx.GetValue())
+		}
+	}
+	return deltas
+}
+
+// transformAddressSummaryTx writes the summary deltas of the transactions of a block, it is applied by TransformTx
+func (bigtable *Bigtable) transformAddressSummaryTx(blk *types.Eth1Block, bulkMetadataUpdates *types.BulkMutations) error {
+	muts, err := addressSummaryTxDeltas(blk).mutations(bigtable.chainId, addressSummaryTxColumn(blk.GetNumber()))
+	if err != nil {
+		return err
+	}
+	bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, muts.Keys...)
+	bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, muts.Muts...)
+	return nil
+}
+
+// transformAddressSummaryItx writes the summary deltas of the internal transactions of a block, it is applied by TransformItx
+func (bigtable *Bigtable) transformAddressSummaryItx(blk *types.Eth1Block, bulkMetadataUpdates *types.BulkMutations) error {
+	muts, err := addressSummaryItxDeltas(blk).mutations(bigtable.chainId, addressSummaryItxColumn(blk.GetNumber()))
+	if err != nil {
+		return err
+	}
+	bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, muts.Keys...)
+	bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, muts.Muts...)
+	return nil
+}
+
+// deleteAddressSummaryDeltas deletes the pending summary deltas of an orphaned block
+func (bigtable *Bigtable) deleteAddressSummaryDeltas(ctx context.Context, block *types.Eth1Block) error {
+	addresses := map[string]bool{}
+	for address := range addressSummaryTxDeltas(block) {
+		addresses[address] = true
+	}
+	for address := range addressSummaryItxDeltas(block) {
+		addresses[address] = true
+	}
+
+	muts := &types.BulkMutations{}
+	for address := range addresses {
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteCellsInColumn(DEFAULT_FAMILY, addressSummaryTxColumn(block.GetNumber()))
+		mut.DeleteCellsInColumn(DEFAULT_FAMILY, addressSummaryItxColumn(block.GetNumber()))
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:SUMMARY:%x", bigtable.chainId, address))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	if len(muts.Keys) == 0 {
+		return nil
+	}
+	return bigtable.WriteBulk(ctx, muts, bigtable.tableMetadataUpdates)
+}
+
+// parseAddressSummaryDeltas decodes the delta cells of a row of the metadata updates table, cells of other columns are ignored
+func parseAddressSummaryDeltas(items []gcp_bigtable.ReadItem) ([]*addressSummaryDelta, error) {
+	deltas := make([]*addressSummaryDelta, 0, len(items))
+	for _, item := range items {
+		column := strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		if !strings.HasSuffix(column, ":TX") && !strings.HasSuffix(column, ":ITX") {
+			continue
+		}
+		delta := &addressSummaryDelta{}
+		err := json.Unmarshal(item.Value, delta)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding address summary delta %v: %w", column, err)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas, nil
+}
+
+// foldAddressSummaryDeltas adds the deltas of blocks above the folded block to the summary and returns the new folded block
+func foldAddressSummaryDeltas(summary *types.AddressSummary, folded uint64, deltas []*addressSummaryDelta) uint64 {
+	highest := folded
+	for _, delta := range deltas {
+		if delta.Block <= folded {
+			continue
+		}
+		if delta.Block > highest {
+			highest = delta.Block
+		}
+
+		summary.TxCount += delta.Txs
+		summary.SentTxCount += delta.SentTxs
+		summary.ReceivedTxCount += delta.ReceivedTxs
+		summary.InternalTxCount += delta.InternalTxs
+		summary.ValueSent = addBigBytes(summary.ValueSent, delta.Sent)
+		summary.ValueReceived = addBigBytes(summary.ValueReceived, delta.Received)
+		summary.IsContract = summary.IsContract || delta.IsContract
+
+		if summary.FirstSeenBlock == 0 || delta.Block < summary.FirstSeenBlock {
+			summary.FirstSeenBlock = delta.Block
+			summary.FirstSeen = time.Unix(delta.Time, 0).UTC()
+		}
+		if delta.Block > summary.LastSeenBlock {
+			summary.LastSeenBlock = delta.Block
+			summary.LastSeen = time.Unix(delta.Time, 0).UTC()
+		}
+	}
+	return highest
+}
+
+// addressSummaryGap reports whether any of the deltas belongs to a block at or below the folded block. Such a delta is dropped by
+// foldAddressSummaryDeltas, it either has been folded before or its block was indexed after newer blocks had been folded.
+func addressSummaryGap(folded uint64, deltas []*addressSummaryDelta) bool {
+	if folded == 0 {
+		return false
+	}
+	for _, delta := range deltas {
+		if delta.Block <= folded {
+			return true
+		}
+	}
+	return false
+}
+
+// getAddressSummaryRows reads the stored summaries of the addresses, keyed by the address bytes
+func (bigtable *Bigtable) getAddressSummaryRows(ctx context.Context, addresses [][]byte) (map[string]*addressSummaryRow, error) {
+	keys := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		keys = append(keys, fmt.Sprintf("%s:SUMMARY:%x", bigtable.chainId, address))
+	}
+
+	rows := make(map[string]*addressSummaryRow, len(addresses))
+	keyPrefix := fmt.Sprintf("%s:SUMMARY:", bigtable.chainId)
+	var decodeErr error
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		for _, item := range row[DEFAULT_FAMILY] {
+			if item.Column != DEFAULT_FAMILY+":"+ADDRESS_SUMMARY_COLUMN {
+				continue
+			}
+			summary := &addressSummaryRow{}
+			decodeErr = json.Unmarshal(item.Value, summary)
+			if decodeErr != nil {
+				decodeErr = fmt.Errorf("error decoding address summary %v: %w", row.Key(), decodeErr)
+				return false
+			}
+			var address []byte
+			address, decodeErr = hex.DecodeString(strings.TrimPrefix(row.Key(), keyPrefix))
+			if decodeErr != nil {
+				decodeErr = fmt.Errorf("error decoding address of summary %v: %w", row.Key(), decodeErr)
+				return false
+			}
+			rows[string(address)] = summary
+		}
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	return rows, decodeErr
+}
+
+// ProcessAddressSummaryUpdates folds the pending summary deltas of blocks up to the finalized block into the summaries of up to
+// limit addresses and returns the number of updated summaries. It must only be run by a single indexer.
+func (bigtable *Bigtable) ProcessAddressSummaryUpdates(ctx context.Context, finalized uint64, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	// the deltas are ordered by descending block number, all columns starting at the finalized block belong to finalized blocks
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, reversedPaddedBlockNumber(finalized), ""),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	keyPrefix := fmt.Sprintf("%s:SUMMARY:", bigtable.chainId)
+	addresses := [][]byte{}
+	pending := map[string][]gcp_bigtable.ReadItem{}
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(keyPrefix), func(row gcp_bigtable.Row) bool {
+		address, err := hex.DecodeString(strings.TrimPrefix(row.Key(), keyPrefix))
+		if err != nil {
+			logger.Warnf("skipping address summary update with malformed key %v", row.Key())
+			return true
+		}
+		addresses = append(addresses, address)
+		pending[string(address)] = row[DEFAULT_FAMILY]
+		return true
+	}, gcp_bigtable.RowFilter(filter), gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+	if len(addresses) == 0 {
+		return 0, nil
+	}
+
+	stored, err := bigtable.getAddressSummaryRows(ctx, addresses)
+	if err != nil {
+		return 0, err
+	}
+
+	mutsWrite := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+	for _, address := range addresses {
+		items := pending[string(address)]
+		deltas, err := parseAddressSummaryDeltas(items)
+		if err != nil {
+			return 0, fmt.Errorf("error processing the summary updates of address 0x%x: %w", address, err)
+		}
+
+		row := stored[string(address)]
+		if row == nil {
+			row = &addressSummaryRow{Summary: &types.AddressSummary{Address: address}}
+		}
+		gaps := row.Gaps || addressSummaryGap(row.Folded, deltas)
+		folded := foldAddressSummaryDeltas(row.Summary, row.Folded, deltas)
+		if folded != row.Folded || gaps != row.Gaps {
+			row.Folded = folded
+			row.Gaps = gaps
+			b, err := json.Marshal(row)
+			if err != nil {
+				return 0, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, ADDRESS_SUMMARY_COLUMN, gcp_bigtable.Timestamp(0), b)
+			mutsWrite.Keys = append(mutsWrite.Keys, fmt.Sprintf("%s%x", keyPrefix, address))
+			mutsWrite.Muts = append(mutsWrite.Muts, mut)
+		}
+
+		// only the folded columns are deleted, deltas of newer blocks written in the meantime are kept
+		mutDelete := gcp_bigtable.NewMutation()
+		for _, item := range items {
+			mutDelete.DeleteCellsInColumn(DEFAULT_FAMILY, strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":"))
+		}
+		mutsDelete.Keys = append(mutsDelete.Keys, fmt.Sprintf("%s%x", keyPrefix, address))
+		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+	}
+
+	// the summaries have to be written before the deltas are deleted, deltas that are left behind are skipped by the next run
+	if len(mutsWrite.Keys) > 0 {
+		err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableData)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
+	if err != nil {
+		return 0, err
+	}
+	return len(mutsWrite.Keys), nil
+}
+
+// GetAddressSummary returns the summary of an address including the deltas of blocks that have not been folded into it yet
+func (bigtable *Bigtable) GetAddressSummary(ctx context.Context, address []byte) (*types.AddressSummary, error) {
+	row, err := bigtable.getAddressSummary(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	return row.Summary, nil
+}
+
+// getAddressSummary returns the summary row of an address with the pending deltas folded into it, the row is flagged as having
+// gaps if any of the pending deltas would be dropped
+func (bigtable *Bigtable) getAddressSummary(ctx context.Context, address []byte) (*addressSummaryRow, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*10))
+	defer cancel()
+
+	stored, err := bigtable.getAddressSummaryRows(ctx, [][]byte{address})
+	if err != nil {
+		return nil, err
+	}
+	row := stored[string(address)]
+	if row == nil {
+		row = &addressSummaryRow{Summary: &types.AddressSummary{Address: address}}
+	}
+
+	pending, err := bigtable.readRow(ctx, bigtable.tableMetadataUpdates, fmt.Sprintf("%s:SUMMARY:%x", bigtable.chainId, address), gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		deltas, err := parseAddressSummaryDeltas(pending[DEFAULT_FAMILY])
+		if err != nil {
+			return nil, err
+		}
+		row.Gaps = row.Gaps || addressSummaryGap(row.Folded, deltas)
+		row.Folded = foldAddressSummaryDeltas(row.Summary, row.Folded, deltas)
+	}
+	return row, nil
+}
+
+// MarkAddressSummaryStart records the block from which the data indexing writes summary deltas. A block that has been recorded
+// before is kept, the indexer marks the first block of its data indexing on every start.
+func (bigtable *Bigtable) MarkAddressSummaryStart(ctx context.Context, block uint64) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	blk, err := bigtable.readBlock(ctx, block)
+	if err != nil {
+		return fmt.Errorf("error reading the summary start block %v: %w", block, err)
+	}
+	b, err := json.Marshal(&addressSummaryStart{Block: block, Time: blk.GetTime().AsTime().Unix()})
+	if err != nil {
+		return err
+	}
+
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(DEFAULT_FAMILY, ADDRESS_SUMMARY_COLUMN, gcp_bigtable.Timestamp(0), b)
+	// only applied if the row does not have a start yet
+	cond := gcp_bigtable.NewCondMutation(gcp_bigtable.ColumnFilter(ADDRESS_SUMMARY_COLUMN), nil, mut)
+	return bigtable.apply(ctx, bigtable.tableData, fmt.Sprintf("%s:SUMMARY_START", bigtable.chainId), cond)
+}
+
+// getAddressSummaryStart returns the recorded summary start, nil if none has been recorded yet. A recorded start never changes
+// and is cached.
+func (bigtable *Bigtable) getAddressSummaryStart(ctx context.Context) (*addressSummaryStart, error) {
+	cacheKey := fmt.Sprintf("%s:SUMMARY_START", bigtable.chainId)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, new(addressSummaryStart)); err == nil {
+		return cached.(*addressSummaryStart), nil
+	}
+
+	row, err := bigtable.readRow(ctx, bigtable.tableData, fmt.Sprintf("%s:SUMMARY_START", bigtable.chainId), gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range row[DEFAULT_FAMILY] {
+		if item.Column != DEFAULT_FAMILY+":"+ADDRESS_SUMMARY_COLUMN {
+			continue
+		}
+		start := &addressSummaryStart{}
+		err = json.Unmarshal(item.Value, start)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding the address summary start: %w", err)
+		}
+		err = cache.TieredCache.Set(cacheKey, start, time.Hour*24)
+		if err != nil {
+			logger.Errorf("error caching the address summary start: %v", err)
+		}
+		return start, nil
+	}
+	return nil, nil
+}
+
+// addressSummaryComplete reports whether the summary of an address covers all of its transactions before the summary start, which
+// is the case if the address has no transactions older than the summary start. The result is cached as transactions before the start
+// are only added by backfilling.
+func (bigtable *Bigtable) addressSummaryComplete(ctx context.Context, address []byte) (bool, error) {
+	cacheKey := fmt.Sprintf("%s:SUMMARY_COMPLETE:%x", bigtable.chainId, address)
+	if complete, err := cache.TieredCache.GetBoolWithLocalTimeout(cacheKey, time.Hour); err == nil {
+		return complete, nil
+	}
+
+	start, err := bigtable.getAddressSummaryStart(ctx)
+	if err != nil || start == nil {
+		return false, err
+	}
+	if start.Block == 0 {
+		return true, nil
+	}
+
+	// the TIME index is ordered newest first, the first key after the start time belongs to an older transaction
+	prefix := bigtable.AddressTxPrefix(address, FILTER_TIME)
+	older := false
+	filter := gcp_bigtable.ChainFilters(gcp_bigtable.CellsPerRowLimitFilter(1), gcp_bigtable.StripValueFilter())
+	err = bigtable.readIndexRows(withoutLatencyBudget(ctx), fmt.Sprintf("%s%019d", prefix, MAX_INT-start.Time+1), prefixSuccessor(prefix, 5), 1, func(row gcp_bigtable.Row) bool {
+		older = true
+		return false
+	}, filter)
+	if err != nil {
+		return false, err
+	}
+	err = cache.TieredCache.SetBool(cacheKey, !older, time.Hour)
+	if err != nil {
+		logger.Errorf("error caching the summary completeness of address 0x%x: %v", address, err)
+	}
+	return !older, nil
+}
+
+// addressTxCount returns the number of transactions of an address in the index of the filter as counted by its summary. ok is
+// false if the filter is not counted or the summary does not cover all transactions of the address (transactions before the
+// summary start or gaps), the index has to be scanned to count them then.
+func (bigtable *Bigtable) addressTxCount(ctx context.Context, address []byte, filter IndexFilter) (count uint64, ok bool, err error) {
+	if filter == "" {
+		filter = FILTER_TIME
+	}
+	if filter != FILTER_TIME && filter != FILTER_TO && filter != FILTER_FROM {
+		return 0, false, nil
+	}
+	complete, err := bigtable.addressSummaryComplete(ctx, address)
+	if err != nil || !complete {
+		return 0, false, err
+	}
+	row, err := bigtable.getAddressSummary(ctx, address)
+	if err != nil || row.Gaps {
+		return 0, false, err
+	}
+	summary := row.Summary
+
+	switch filter {
+	case FILTER_TO:
+		return summary.SentTxCount, true, nil
+	case FILTER_FROM:
+		return summary.ReceivedTxCount, true, nil
+	}
+	return summary.TxCount, true, nil
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"math/big"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestAddressSummaryFold(t *testing.T) {
+	alice := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+	bob := []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02}
+	blockTime := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	block := func(number uint64, txs ...*types.Eth1Transaction) *types.Eth1Block {
+		return &types.Eth1Block{Number: number, Time: timestamppb.New(blockTime.Add(time.Duration(number) * time.Second * 12)), Transactions: txs}
+	}
+	transfer := func(from, to []byte, value int64, errorMsg string) *types.Eth1Transaction {
+		return &types.Eth1Transaction{From: from, To: to, Value: big.NewInt(value).Bytes(), ContractAddress: ZERO_ADDRESS, ErrorMsg: errorMsg}
+	}
+
+	first := addressSummaryTxDeltas(block(10, transfer(alice, bob, 5, ""), transfer(alice, bob, 7, "out of gas")))
+	second := addressSummaryTxDeltas(block(20, transfer(bob, alice, 3, "")))
+
+	summary := &types.AddressSummary{Address: alice}
+	folded := foldAddressSummaryDeltas(summary, 0, []*addressSummaryDelta{second[string(alice)], first[string(alice)]})
+	if folded != 20 {
+		t.Errorf("expected block 20 to be folded, got %v", folded)
+	}
+	// deltas of blocks that have been folded before are skipped and flag the summary as having gaps
+	if !addressSummaryGap(folded, []*addressSummaryDelta{first[string(alice)]}) {
+		t.Errorf("expected a delta of block 10 to be a gap of a summary folded up to block 20")
+	}
+	if addressSummaryGap(0, []*addressSummaryDelta{first[string(alice)]}) || addressSummaryGap(10, []*addressSummaryDelta{second[string(alice)]}) {
+		t.Errorf("expected no gap for deltas above the folded block")
+	}
+	folded = foldAddressSummaryDeltas(summary, folded, []*addressSummaryDelta{first[string(alice)]})
+	if folded != 20 {
+		t.Errorf("expected the folded block to stay at 20, got %v", folded)
+	}
+
+	if summary.TxCount != 3 {
+		t.Errorf("expected 3 transactions, got %v", summary.TxCount)
+	}
+	if summary.SentTxCount != 2 || summary.ReceivedTxCount != 1 {
+		t.Errorf("expected 2 sent and 1 received transactions, got %v and %v", summary.SentTxCount, summary.ReceivedTxCount)
+	}
+	// the failed transfer is counted but does not transfer any value
+	if sent := new(big.Int).SetBytes(summary.ValueSent); sent.Int64() != 5 {
+		t.Errorf("expected 5 wei sent, got %v", sent)
+	}
+	if received := new(big.Int).SetBytes(summary.ValueReceived); received.Int64() != 3 {
+		t.Errorf("expected 3 wei received, got %v", received)
+	}
+	if summary.FirstSeenBlock != 10 || summary.LastSeenBlock != 20 {
+		t.Errorf("expected the address to be seen from block 10 to 20, got %v to %v", summary.FirstSeenBlock, summary.LastSeenBlock)
+	}
+	// a transaction to the sender itself is counted once but is both sent and received, as in the TIME, TO and FROM indexes
+	self := &types.AddressSummary{Address: bob}
+	foldAddressSummaryDeltas(self, 0, []*addressSummaryDelta{addressSummaryTxDeltas(block(30, transfer(bob, bob, 1, "")))[string(bob)]})
+	if self.TxCount != 1 || self.SentTxCount != 1 || self.ReceivedTxCount != 1 {
+		t.Errorf("unexpected counts %v, %v and %v of a transaction to the sender itself", self.TxCount, self.SentTxCount, self.ReceivedTxCount)
+	}
+	if !summary.FirstSeen.Equal(blockTime.Add(time.Second*120)) || !summary.LastSeen.Equal(blockTime.Add(time.Second*240)) {
+		t.Errorf("unexpected first and last seen times %v and %v", summary.FirstSeen, summary.LastSeen)
+	}
+}
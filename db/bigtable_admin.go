@@ -42,6 +42,60 @@ var CacheTable CreateTables = CreateTables{
 	},
 }
 
+// SchemaTables are the tables and column families the indexer writes to in addition to the initial ones of bigtable_config.md,
+// they are created by MigrateSchema
+var SchemaTables = []CreateTables{
+	{
+		Name: "data",
+		ColFams: []CreateFamily{
+			{
+				Name:   TOMBSTONE_FAMILY,
+				Policy: gcp_bigtable.MaxVersionsPolicy(1),
+			},
+		},
+	},
+	{
+		Name: "metadata",
+		ColFams: []CreateFamily{
+			{
+				Name:   BALANCE_HISTORY_FAMILY,
+				Policy: gcp_bigtable.MaxVersionsPolicy(1),
+			},
+			{
+				Name:   ENS_FAMILY,
+				Policy: gcp_bigtable.MaxVersionsPolicy(1),
+			},
+			{
+				Name:   SIGNATURE_FAMILY,
+				Policy: gcp_bigtable.MaxVersionsPolicy(1),
+			},
+		},
+	},
+}
+
+// ArchiveTable holds the index rows moved out of the data table, it is only required if the archive is enabled
+var ArchiveTable = CreateTables{
+	ARCHIVE_TABLE,
+	[]CreateFamily{
+		{
+			Name:   DEFAULT_FAMILY,
+			Policy: gcp_bigtable.NoGcPolicy(),
+		},
+		{
+			Name:   TOMBSTONE_FAMILY,
+			Policy: gcp_bigtable.MaxVersionsPolicy(1),
+		},
+	},
+}
+
+// schemaTables returns the tables and column families required by the configuration
+func schemaTables() []CreateTables {
+	if utils.Config != nil && utils.Config.Bigtable.Archive {
+		return append(SchemaTables[:len(SchemaTables):len(SchemaTables)], ArchiveTable)
+	}
+	return SchemaTables
+}
+
 var BigAdminClient *BigtableAdmin
 
 func MustInitBigtableAdmin(ctx context.Context, project, instance string) {
@@ -74,6 +128,55 @@ func (admin *BigtableAdmin) SetupBigtableCache() error {
 	return nil
 }
 
+// MigrateSchema creates the tables and column families of SchemaTables and, if the archive is enabled, of ArchiveTable that do
+// not exist yet and sets their gc policies
+func (admin *BigtableAdmin) MigrateSchema() error {
+	tables := schemaTables()
+	if err := admin.createTables(tables); err != nil {
+		return err
+	}
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	for _, table := range tables {
+		for _, cf := range table.ColFams {
+			if err := admin.client.SetGCPolicy(ctx, table.Name, cf.Name, cf.Policy); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MissingSchema returns the tables and column families migrated by MigrateSchema that do not exist yet as table or table:family
+func (admin *BigtableAdmin) MissingSchema() ([]string, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	tableList, err := admin.client.Tables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	for _, table := range schemaTables() {
+		if !utils.SliceContains(tableList, table.Name) {
+			missing = append(missing, table.Name)
+			continue
+		}
+		tblInfo, err := admin.client.TableInfo(ctx, table.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, colfam := range table.ColFams {
+			if !utils.SliceContains(tblInfo.Families, colfam.Name) {
+				missing = append(missing, table.Name+":"+colfam.Name)
+			}
+		}
+	}
+	return missing, nil
+}
+
 func (admin *BigtableAdmin) TearDownCache() error {
 	if err := admin.deleteTables([]CreateTables{CacheTable}); err != nil {
 		return err
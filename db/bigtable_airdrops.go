@@ -0,0 +1,74 @@
+package db
+
+import (
+	"bytes"
+	"eth2-exporter/erc1155"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// airdropMinRecipients is the number of distinct recipients a token has to be distributed to by the same sender within a single
+// transaction for its transfers to be marked as an airdrop
+const airdropMinRecipients = 20
+
+func airdropKey(token, from []byte) string {
+	return fmt.Sprintf("%x:%x", token, from)
+}
+
+// detectAirdrops returns the airdropKey of each token and sender that distributes the token to at least airdropMinRecipients
+// distinct recipients within the transaction. Transfers by the sender of the transaction itself, like payouts made through a
+// multisend contract, are not considered to be airdrops. The ERC20, ERC721 and ERC1155 transfer events share the sender and
+// recipient topics, so the logs do not have to be decoded.
+func detectAirdrops(tx *types.Eth1Transaction) map[string]bool {
+	recipients := make(map[string]map[common.Address]bool)
+	for _, log := range tx.GetLogs() {
+		topics := log.GetTopics()
+		var from, to common.Address
+		switch {
+		case len(topics) >= 3 && bytes.Equal(topics[0], erc20.TransferTopic):
+			from, to = common.BytesToAddress(topics[1]), common.BytesToAddress(topics[2])
+		case len(topics) == 4 && (bytes.Equal(topics[0], erc1155.TransferSingleTopic) || bytes.Equal(topics[0], erc1155.TransferBulkTopic)):
+			from, to = common.BytesToAddress(topics[2]), common.BytesToAddress(topics[3])
+		default:
+			continue
+		}
+		if bytes.Equal(from.Bytes(), tx.GetFrom()) || from == to {
+			continue
+		}
+		key := airdropKey(log.GetAddress(), from.Bytes())
+		if recipients[key] == nil {
+			recipients[key] = make(map[common.Address]bool)
+		}
+		recipients[key][to] = true
+	}
+
+	airdrops := make(map[string]bool)
+	for key, to := range recipients {
+		if len(to) >= airdropMinRecipients {
+			airdrops[key] = true
+		}
+	}
+	return airdrops
+}
+
+// inboundAirdropToken returns the token of an activity row that is part of an airdrop received by address, nil otherwise
+func inboundAirdropToken(m proto.Message, address []byte) []byte {
+	var airdrop bool
+	var from, to, token []byte
+	switch t := m.(type) {
+	case *types.Eth1ERC20Indexed:
+		airdrop, from, to, token = t.Airdrop, t.From, t.To, t.TokenAddress
+	case *types.Eth1ERC721Indexed:
+		airdrop, from, to, token = t.Airdrop, t.From, t.To, t.TokenAddress
+	case *types.ETh1ERC1155Indexed:
+		airdrop, from, to, token = t.Airdrop, t.From, t.To, t.TokenAddress
+	}
+	if !airdrop || !bytes.Equal(to, address) || bytes.Equal(from, address) {
+		return nil
+	}
+	return token
+}
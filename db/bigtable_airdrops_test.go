@@ -0,0 +1,65 @@
+package db
+
+import (
+	"eth2-exporter/erc1155"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDetectAirdrops(t *testing.T) {
+	sender := common.HexToAddress("0x0101010101010101010101010101010101010101")
+	distributor := common.HexToAddress("0x0202020202020202020202020202020202020202")
+	token := common.HexToAddress("0x0303030303030303030303030303030303030303").Bytes()
+	nft := common.HexToAddress("0x0404040404040404040404040404040404040404").Bytes()
+
+	transfer := func(topic []byte, contract []byte, from common.Address, to int) *types.Eth1Log {
+		recipient := common.BytesToHash([]byte{0x10, byte(to)}).Bytes()
+		if topic == nil {
+			return &types.Eth1Log{Address: contract, Topics: [][]byte{erc20.TransferTopic, from.Hash().Bytes(), recipient}}
+		}
+		return &types.Eth1Log{Address: contract, Topics: [][]byte{topic, sender.Hash().Bytes(), from.Hash().Bytes(), recipient}}
+	}
+
+	tx := &types.Eth1Transaction{From: sender.Bytes()}
+	for i := 0; i < airdropMinRecipients; i++ {
+		tx.Logs = append(tx.Logs,
+			transfer(nil, token, distributor, i),
+			// transfers by the sender of the transaction are payouts
+			transfer(nil, token, sender, i),
+			// the recipients of the nft are not distinct
+			transfer(erc1155.TransferSingleTopic, nft, distributor, 0),
+		)
+	}
+
+	airdrops := detectAirdrops(tx)
+	if len(airdrops) != 1 || !airdrops[airdropKey(token, distributor.Bytes())] {
+		t.Errorf("expected only the distribution of the token by the distributor to be an airdrop, got %v", airdrops)
+	}
+
+	tx.Logs = tx.Logs[3:]
+	if airdrops := detectAirdrops(tx); len(airdrops) != 0 {
+		t.Errorf("expected a distribution to less than %v recipients not to be an airdrop, got %v", airdropMinRecipients, airdrops)
+	}
+}
+
+func TestInboundAirdropToken(t *testing.T) {
+	address := common.HexToAddress("0x0101010101010101010101010101010101010101").Bytes()
+	distributor := common.HexToAddress("0x0202020202020202020202020202020202020202").Bytes()
+	token := common.HexToAddress("0x0303030303030303030303030303030303030303").Bytes()
+
+	if got := inboundAirdropToken(&types.Eth1ERC20Indexed{TokenAddress: token, From: distributor, To: address, Airdrop: true}, address); string(got) != string(token) {
+		t.Errorf("expected the airdropped token, got %x", got)
+	}
+	if got := inboundAirdropToken(&types.Eth1ERC20Indexed{TokenAddress: token, From: address, To: distributor, Airdrop: true}, address); got != nil {
+		t.Errorf("expected an airdrop sent by the address not to be inbound, got %x", got)
+	}
+	if got := inboundAirdropToken(&types.ETh1ERC1155Indexed{TokenAddress: token, From: distributor, To: address}, address); got != nil {
+		t.Errorf("expected a transfer that is no airdrop to be ignored, got %x", got)
+	}
+	if got := inboundAirdropToken(&types.Eth1TransactionIndexed{From: distributor, To: address}, address); got != nil {
+		t.Errorf("expected a transaction to be ignored, got %x", got)
+	}
+}
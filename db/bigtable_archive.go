@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// Index rows of the data table that are older than the hot window are moved to the archive table, which is set up with
+// cheaper storage. Row keys are identical in both tables and a row is stored in only one of them, so a read of an index range
+// merges the rows of both tables by key. Only the time ordered indexes of an address keep all archived rows behind the hot ones,
+// the indexes by counterparty or method are ordered by time per counterparty or method.
+// Indexes ordered by block number do not carry a time and always stay in the data table.
+const ARCHIVE_TABLE = "data_archive"
+
+// readIndexRows reads up to limit index rows of the range [start, end) from the data table and the archive table in the order
+// of their keys
+func (bigtable *Bigtable) readIndexRows(ctx context.Context, start, end string, limit int64, f func(gcp_bigtable.Row) bool, filters ...gcp_bigtable.Filter) error {
+	ctx, cancel, budgetExceeded := budgetedScan(ctx)
+	defer cancel()
+
+	if bigtable.tableArchive == nil {
+		err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.NewRange(start, end), f, gcp_bigtable.LimitRows(limit), skipTombstones(filters...))
+		return budgetExceeded(err)
+	}
+
+	hot := []gcp_bigtable.Row{}
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.NewRange(start, end), func(row gcp_bigtable.Row) bool {
+		hot = append(hot, row)
+		return true
+	}, gcp_bigtable.LimitRows(limit), skipTombstones(filters...))
+	err = budgetExceeded(err)
+	if err != nil {
+		return err
+	}
+
+	// if the hot rows fill the limit, only archived rows sorting before the last of them can be part of the result
+	archiveEnd := end
+	if int64(len(hot)) >= limit {
+		archiveEnd = hot[len(hot)-1].Key()
+	}
+	archived := []gcp_bigtable.Row{}
+	if archiveEnd == "" || archiveEnd > start {
+		err = bigtable.readRows(ctx, bigtable.tableArchive, gcp_bigtable.NewRange(start, archiveEnd), func(row gcp_bigtable.Row) bool {
+			archived = append(archived, row)
+			return true
+		}, gcp_bigtable.LimitRows(limit), skipTombstones(filters...))
+		err = budgetExceeded(err)
+		if err != nil {
+			return err
+		}
+	}
+
+	mergeIndexRows(hot, archived, limit, f)
+	return nil
+}
+
+// mergeIndexRows passes up to limit rows of two lists of rows ordered by key to f in the order of their keys
+func mergeIndexRows(a, b []gcp_bigtable.Row, limit int64, f func(gcp_bigtable.Row) bool) {
+	for n := int64(0); n < limit && (len(a) > 0 || len(b) > 0); n++ {
+		var row gcp_bigtable.Row
+		if len(b) == 0 || len(a) > 0 && a[0].Key() < b[0].Key() {
+			row, a = a[0], a[1:]
+		} else {
+			row, b = b[0], b[1:]
+		}
+		if !f(row) {
+			return
+		}
+	}
+}
+
+// indexRowTime returns the time encoded as reverse padded timestamp in an index row key
+func indexRowTime(key string) (time.Time, bool) {
+	parts := strings.Split(key, ":")
+	if len(parts) < 4 || parts[1] != "I" {
+		return time.Time{}, false
+	}
+	for _, part := range parts[3:] {
+		if len(part) != 19 {
+			continue
+		}
+		reversed, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			continue
+		}
+		return time.Unix(MAX_INT-reversed, 0), true
+	}
+	return time.Time{}, false
+}
+
+// splitIndexRowKey splits an index row key at the reverse padded time or block number the rows of the index are ordered by,
+// the returned prefix ends with the separator preceding it
+func splitIndexRowKey(key string) (prefix string, order string, ok bool) {
+	parts := strings.Split(key, ":")
+	if len(parts) < 4 || parts[1] != "I" {
+		return "", "", false
+	}
+	for i, part := range parts[3:] {
+		if len(part) != 19 && len(part) != 9 {
+			continue
+		}
+		if _, err := strconv.ParseUint(part, 10, 64); err != nil {
+			continue
+		}
+		return strings.Join(parts[:i+3], ":") + ":", part, true
+	}
+	return "", "", false
+}
+
+// ArchiveIndexRows moves the index rows older than cutoff from the data table to the archive table. The index is walked per
+// prefix of the rows ordered by time, e.g. per address and filter or per address and counterparty, and only the rows of a
+// prefix older than cutoff are read. It reads up to limit rows starting at startKey and returns the key to continue from, an
+// empty key is returned once all index rows have been visited.
+func (bigtable *Bigtable) ArchiveIndexRows(ctx context.Context, startKey string, cutoff time.Time, limit int64) (string, int, error) {
+	if bigtable.tableArchive == nil {
+		return "", 0, fmt.Errorf("the archive table is not enabled")
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	indexPrefix := fmt.Sprintf("%s:I:", bigtable.chainId)
+	indexEnd := prefixSuccessor(indexPrefix, 2)
+	cursor := startKey
+	if cursor == "" {
+		cursor = indexPrefix
+	}
+	cutoffKey := fmt.Sprintf("%019d", MAX_INT-cutoff.Unix()+1)
+
+	mutsArchive := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+	read := int64(0)
+	for read < limit {
+		// the first row at the cursor determines the next prefix
+		key := ""
+		err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.NewRange(cursor, indexEnd), func(row gcp_bigtable.Row) bool {
+			key = row.Key()
+			return false
+		}, gcp_bigtable.LimitRows(1), gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(gcp_bigtable.CellsPerRowLimitFilter(1), gcp_bigtable.StripValueFilter())))
+		if err != nil {
+			return "", 0, err
+		}
+		read++
+		if key == "" {
+			cursor = ""
+			break
+		}
+
+		prefix, order, ok := splitIndexRowKey(key)
+		if !ok {
+			cursor = key + "\x00"
+			continue
+		}
+		// the prefix ends with a separator, its successor ends the range of all rows sharing it
+		prefixEnd := prefix[:len(prefix)-1] + ";"
+		if len(order) != 19 {
+			cursor = prefixEnd
+			continue
+		}
+
+		remaining := limit - read
+		if remaining <= 0 {
+			break
+		}
+		start := prefix + cutoffKey
+		if cursor > start {
+			start = cursor
+		}
+		rows := int64(0)
+		// the tombstones are moved along with the rows
+		err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.NewRange(start, prefixEnd), func(row gcp_bigtable.Row) bool {
+			rows++
+			cursor = row.Key() + "\x00"
+
+			mut := gcp_bigtable.NewMutation()
+			for family, items := range row {
+				for _, item := range items {
+					mut.Set(family, strings.TrimPrefix(item.Column, family+":"), item.Timestamp, item.Value)
+				}
+			}
+			mutsArchive.Keys = append(mutsArchive.Keys, row.Key())
+			mutsArchive.Muts = append(mutsArchive.Muts, mut)
+
+			mutDelete := gcp_bigtable.NewMutation()
+			mutDelete.DeleteRow()
+			mutsDelete.Keys = append(mutsDelete.Keys, row.Key())
+			mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+			return true
+		}, gcp_bigtable.LimitRows(remaining))
+		if err != nil {
+			return "", 0, err
+		}
+		read += rows
+		if rows < remaining {
+			// all old rows of the prefix have been read
+			cursor = prefixEnd
+		}
+	}
+
+	if len(mutsArchive.Keys) > 0 {
+		// the rows are only deleted from the data table once they have been written to the archive
+		err := bigtable.WriteBulk(ctx, mutsArchive, bigtable.tableArchive)
+		if err != nil {
+			return "", 0, err
+		}
+		err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableData)
+		if err != nil {
+			return "", 0, err
+		}
+	}
+	return cursor, len(mutsArchive.Keys), nil
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+func TestMergeIndexRows(t *testing.T) {
+	rows := func(keys ...string) []gcp_bigtable.Row {
+		rows := make([]gcp_bigtable.Row, 0, len(keys))
+		for _, key := range keys {
+			rows = append(rows, gcp_bigtable.Row{DEFAULT_FAMILY: {{Row: key, Column: DEFAULT_FAMILY + ":" + DATA_COLUMN}}})
+		}
+		return rows
+	}
+
+	// the archived rows of an earlier counterparty sort before the hot rows of a later one
+	hot := rows("1:I:TX:aa:TO:01:0001", "1:I:TX:aa:TO:02:0001", "1:I:TX:aa:TO:02:0002")
+	archived := rows("1:I:TX:aa:TO:01:0002", "1:I:TX:aa:TO:01:0003", "1:I:TX:aa:TO:03:0001")
+
+	tests := []struct {
+		limit int64
+		stop  int
+		want  []string
+	}{
+		{10, 0, []string{"1:I:TX:aa:TO:01:0001", "1:I:TX:aa:TO:01:0002", "1:I:TX:aa:TO:01:0003", "1:I:TX:aa:TO:02:0001", "1:I:TX:aa:TO:02:0002", "1:I:TX:aa:TO:03:0001"}},
+		{2, 0, []string{"1:I:TX:aa:TO:01:0001", "1:I:TX:aa:TO:01:0002"}},
+		{10, 4, []string{"1:I:TX:aa:TO:01:0001", "1:I:TX:aa:TO:01:0002", "1:I:TX:aa:TO:01:0003", "1:I:TX:aa:TO:02:0001"}},
+	}
+	for _, tt := range tests {
+		got := []string{}
+		mergeIndexRows(hot, archived, tt.limit, func(row gcp_bigtable.Row) bool {
+			got = append(got, row.Key())
+			return tt.stop == 0 || len(got) < tt.stop
+		})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrong rows for limit %v and stop %v: got %v, want %v", tt.limit, tt.stop, got, tt.want)
+		}
+	}
+
+	got := []string{}
+	mergeIndexRows(nil, archived, 10, func(row gcp_bigtable.Row) bool {
+		got = append(got, row.Key())
+		return true
+	})
+	if len(got) != len(archived) {
+		t.Errorf("expected all %v archived rows without hot rows, got %v", len(archived), got)
+	}
+}
+
+func TestSplitIndexRowKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		prefix string
+		order  string
+		ok     bool
+	}{
+		{"1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TIME:9223372035188572567:0001", "1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TIME:", "9223372035188572567", true},
+		{"1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TO:0000000000000000000000000000000000000001:9223372035188572567:0001", "1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TO:0000000000000000000000000000000000000001:", "9223372035188572567", true},
+		{"1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:BLOCK:982999999:0001", "1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:BLOCK:", "982999999", true},
+		{"1:I:ITX:BLOCK:982999999:0001:000001", "1:I:ITX:BLOCK:", "982999999", true},
+		{"1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TIME:", "", "", false},
+		{"1:B:982999999", "", "", false},
+	}
+	for _, tt := range tests {
+		prefix, order, ok := splitIndexRowKey(tt.key)
+		if prefix != tt.prefix || order != tt.order || ok != tt.ok {
+			t.Errorf("wrong split of %q: got %q %q %v, want %q %q %v", tt.key, prefix, order, ok, tt.prefix, tt.order, tt.ok)
+		}
+	}
+}
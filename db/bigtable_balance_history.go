@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// family of the metadata table storing the ether balance snapshots of an address
+const BALANCE_HISTORY_FAMILY = "bh"
+
+// number of blocks covered by a single balance snapshot
+const BalanceSnapshotInterval = 10000
+
+// SaveBalanceSnapshots records the ether balances of addresses retrieved at a block as snapshots of the interval the block
+// belongs to. Balances retrieved later in the same interval overwrite the snapshot, so a snapshot holds the last balance
+// seen in its interval. Token balances are ignored.
+//
+// The snapshots are written to the metadata table:
+// Row:    <chainID>:<address>
+// Family: bh
+// Column: <paddedIntervalStartBlock>
+// Cell:   balance
+func (bigtable *Bigtable) SaveBalanceSnapshots(ctx context.Context, balances []*types.Eth1AddressBalance, block uint64) error {
+	muts := &types.BulkMutations{}
+
+	column := fmt.Sprintf("%09d", block-block%BalanceSnapshotInterval)
+	for _, balance := range balances {
+		if len(balance.Token) >= 20 {
+			continue
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(BALANCE_HISTORY_FAMILY, column, gcp_bigtable.Timestamp(0), balance.Balance)
+
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:%x", bigtable.chainId, balance.Address))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	if len(muts.Keys) == 0 {
+		return nil
+	}
+
+	return bigtable.WriteBulk(ctx, muts, bigtable.tableMetadata)
+}
+
+// GetAddressBalanceHistory returns the ether balance snapshots of an address for the intervals starting between from and to (inclusive),
+// ordered by block. Intervals in which the balance did not change have no snapshot, the balance of the previous snapshot applies to them.
+func (bigtable *Bigtable) GetAddressBalanceHistory(ctx context.Context, address []byte, from, to uint64) ([]*types.Eth1BalanceSnapshot, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	end := "" // unbounded
+	if to < max_block_number {
+		end = fmt.Sprintf("%09d", to+1)
+	}
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(BALANCE_HISTORY_FAMILY),
+		gcp_bigtable.ColumnRangeFilter(BALANCE_HISTORY_FAMILY, fmt.Sprintf("%09d", from-from%BalanceSnapshotInterval), end),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address), gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]*types.Eth1BalanceSnapshot, 0, len(row[BALANCE_HISTORY_FAMILY]))
+	for _, item := range row[BALANCE_HISTORY_FAMILY] {
+		block, err := strconv.ParseUint(strings.TrimPrefix(item.Column, BALANCE_HISTORY_FAMILY+":"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance snapshot column %v of address 0x%x: %w", item.Column, address, err)
+		}
+		snapshots = append(snapshots, &types.Eth1BalanceSnapshot{
+			Block:   block,
+			Balance: item.Value,
+		})
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/fixtures"
+	"eth2-exporter/types"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+)
+
+// The benchmarks of this file track the indexing throughput of the transformers and the latency of the paging read paths.
+// Run them with `make bench`, record a baseline with `make bench-baseline` and compare against it with `make bench-compare`.
+// The read path benchmarks need a bigtable emulator and are skipped unless BIGTABLE_EMULATOR_HOST is set.
+
+var benchBlockSizes = []int{10, 100, 1000}
+
+// benchBlock returns the fixture block the benchmarks index, a block of txCount transactions between 50 addresses
+func benchBlock(number uint64, txCount int) *types.Eth1Block {
+	opts := fixtures.DefaultOptions()
+	opts.Transactions = txCount
+	return fixtures.Block(number, opts)
+}
+
+func benchmarkTransformer(b *testing.B, transform func(*Bigtable) func(*types.Eth1Block, *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) {
+	bt := &Bigtable{chainId: "1"}
+	for _, size := range benchBlockSizes {
+		blk := benchBlock(1, size)
+		b.Run(fmt.Sprintf("txs=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				// a fresh cache per block, otherwise the balance update markers are skipped after the first iteration
+				b.StopTimer()
+				cache := freecache.NewCache(1024 * 1024)
+				b.StartTimer()
+
+				data, _, err := transform(bt)(blk, cache)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(data.Keys) == 0 {
+					b.Fatal("transformer did not produce any mutations")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTransformTx(b *testing.B) {
+	benchmarkTransformer(b, func(bt *Bigtable) func(*types.Eth1Block, *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error) {
+		return bt.TransformTx
+	})
+}
+
+func BenchmarkTransformERC20(b *testing.B) {
+	benchmarkTransformer(b, func(bt *Bigtable) func(*types.Eth1Block, *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error) {
+		return bt.TransformERC20
+	})
+}
+
+// newBenchEmulatorBigtable creates the data table in the emulator and fills it with the transactions and ERC20 transfers of a few fixture blocks
+func newBenchEmulatorBigtable(b *testing.B) *Bigtable {
+	if os.Getenv("BIGTABLE_EMULATOR_HOST") == "" {
+		b.Skip("BIGTABLE_EMULATOR_HOST is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	const project, instance = "bench", "bench"
+	admin, err := gcp_bigtable.NewAdminClient(ctx, project, instance)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer admin.Close()
+
+	tables, err := admin.Tables(ctx)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, table := range tables {
+		if table == "data" {
+			err = admin.DeleteTable(ctx, table)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	err = admin.CreateTable(ctx, "data")
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = admin.CreateColumnFamily(ctx, "data", DEFAULT_FAMILY)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	bt, err := NewBigtable(project, instance, "1")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cache := freecache.NewCache(1024 * 1024)
+	for number := uint64(1); number <= 20; number++ {
+		blk := benchBlock(number, 100)
+		for _, transform := range []func(*types.Eth1Block, *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error){bt.TransformTx, bt.TransformERC20} {
+			data, _, err := transform(blk, cache)
+			if err != nil {
+				b.Fatal(err)
+			}
+			err = bt.WriteBulk(context.Background(), data, bt.tableData)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	return bt
+}
+
+// benchmarkPaging reads all pages of an index, following the returned page token like the address page does
+func benchmarkPaging[T any](b *testing.B, prefix string, read func(ctx context.Context, prefix string, limit int64) ([]T, string, error)) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pageToken := prefix
+		for pages := 0; ; pages++ {
+			data, lastKey, err := read(context.Background(), pageToken, 25)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if len(data) == 0 || lastKey == "" {
+				if pages == 0 {
+					b.Fatal("index is empty")
+				}
+				break
+			}
+			pageToken = lastKey
+		}
+	}
+}
+
+func BenchmarkAddressPaging(b *testing.B) {
+	bt := newBenchEmulatorBigtable(b)
+	defer bt.Close()
+
+	address := fixtures.Address(0)
+	b.Run("transactions", func(b *testing.B) {
+		benchmarkPaging(b, fmt.Sprintf("%s:I:TX:%x:%s:", bt.chainId, address, FILTER_TIME), bt.GetEth1TxForAddress)
+	})
+	b.Run("erc20", func(b *testing.B) {
+		benchmarkPaging(b, fmt.Sprintf("%s:I:ERC20:%x:%s:", bt.chainId, address, FILTER_TIME), bt.GetEth1ERC20ForAddress)
+	})
+}
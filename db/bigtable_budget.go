@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"sync/atomic"
+	"time"
+)
+
+type latencyBudgetKey struct{}
+
+type latencyBudget struct {
+	deadline  time.Time
+	truncated int32
+	// a truncation is reported to the budget the budget has been forked from as well
+	parent *latencyBudget
+}
+
+// WithLatencyBudget limits the index scans made with the returned context to d in total. A scan that exceeds the budget
+// stops early and returns the rows it has read so far instead of failing, IsTruncated reports whether that happened.
+func WithLatencyBudget(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, latencyBudgetKey{}, &latencyBudget{deadline: time.Now().Add(d)})
+}
+
+// IsTruncated returns whether an index scan made with ctx has been cut off by its latency budget
+func IsTruncated(ctx context.Context) bool {
+	budget, _ := ctx.Value(latencyBudgetKey{}).(*latencyBudget)
+	return budget != nil && atomic.LoadInt32(&budget.truncated) == 1
+}
+
+// withoutLatencyBudget returns a context whose scans are not limited by a latency budget, for reads that are useless
+// unless they complete
+func withoutLatencyBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, latencyBudgetKey{}, (*latencyBudget)(nil))
+}
+
+// forkLatencyBudget returns a context sharing the deadline of the latency budget of ctx, IsTruncated of the returned context only
+// reports the scans made with it while the truncation is also reported for ctx
+func forkLatencyBudget(ctx context.Context) context.Context {
+	budget, _ := ctx.Value(latencyBudgetKey{}).(*latencyBudget)
+	if budget == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, latencyBudgetKey{}, &latencyBudget{deadline: budget.deadline, parent: budget})
+}
+
+// budgetedScan returns the context of an index scan limited by the latency budget of ctx and a function that turns the
+// error of a scan that ran out of budget into a truncated result
+func budgetedScan(ctx context.Context) (context.Context, context.CancelFunc, func(error) error) {
+	budget, _ := ctx.Value(latencyBudgetKey{}).(*latencyBudget)
+	if budget == nil {
+		return ctx, func() {}, func(err error) error { return err }
+	}
+
+	scanCtx, cancel := context.WithDeadline(ctx, budget.deadline)
+	return scanCtx, cancel, func(err error) error {
+		// the request itself might have been canceled or reached its own deadline
+		if err == nil || ctx.Err() != nil || scanCtx.Err() != context.DeadlineExceeded {
+			return err
+		}
+		if atomic.CompareAndSwapInt32(&budget.truncated, 0, 1) {
+			metrics.BigtableTruncatedScans.Inc()
+		}
+		for parent := budget.parent; parent != nil; parent = parent.parent {
+			atomic.StoreInt32(&parent.truncated, 1)
+		}
+		return nil
+	}
+}
+
+// truncatePage flags a table page whose index scan ran out of its latency budget. The page continues after its last row,
+// a page without any row is continued where it started.
+func truncatePage(ctx context.Context, data *types.DataTableResponse, pageToken string) {
+	if !IsTruncated(ctx) {
+		return
+	}
+	data.Truncated = true
+	data.Warnings = append(data.Warnings, "Loading this page took too long, it only shows the entries found so far. Load the next page to continue.")
+	if data.PagingToken == "" {
+		data.PagingToken = pageToken
+	}
+}
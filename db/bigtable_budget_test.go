@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"eth2-exporter/types"
+	"testing"
+	"time"
+)
+
+func TestBudgetedScan(t *testing.T) {
+	scanErr := errors.New("deadline exceeded")
+
+	_, cancel, budgetExceeded := budgetedScan(context.Background())
+	cancel()
+	if err := budgetExceeded(scanErr); err != scanErr {
+		t.Errorf("a scan without budget must return its error, got %v", err)
+	}
+
+	ctx := WithLatencyBudget(context.Background(), time.Millisecond)
+	scanCtx, cancel, budgetExceeded := budgetedScan(ctx)
+	defer cancel()
+	<-scanCtx.Done()
+	if err := budgetExceeded(scanErr); err != nil {
+		t.Errorf("a scan that ran out of budget must not fail, got %v", err)
+	}
+	if !IsTruncated(ctx) {
+		t.Errorf("expected the scan to be truncated")
+	}
+
+	data := &types.DataTableResponse{}
+	truncatePage(ctx, data, "1:I:TX:")
+	if !data.Truncated || data.PagingToken != "1:I:TX:" || len(data.Warnings) != 1 {
+		t.Errorf("expected a truncated page that continues at its start, got %+v", data)
+	}
+
+	// the scans of a request that has been canceled fail
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx = WithLatencyBudget(parent, time.Millisecond)
+	cancelParent()
+	_, cancel, budgetExceeded = budgetedScan(ctx)
+	defer cancel()
+	if err := budgetExceeded(scanErr); err != scanErr || IsTruncated(ctx) {
+		t.Errorf("expected a canceled scan to fail, got %v", err)
+	}
+
+	if IsTruncated(withoutLatencyBudget(WithLatencyBudget(context.Background(), 0))) {
+		t.Errorf("a context without budget can not be truncated")
+	}
+}
@@ -0,0 +1,272 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// The fees burned with EIP 1559 are aggregated the same way as the address summaries. TransformBlock writes the burn of a
+// block to the metadata updates table:
+// Row:    <chainID>:BURN
+// Family: f
+// Column: <reversedPaddedBlockNumber>
+// Cell:   Json<burnedFeesDelta>
+//
+// ProcessBurnedFeesUpdates folds the deltas of blocks that can no longer be reorged into the aggregation row in the data table:
+// Row:    <chainID>:BURN
+// Family: f
+// Column: total
+// Cell:   Json<burnedFeesTotal>
+// Column: DAY:<yyyy-mm-dd>
+// Cell:   big endian wei burned by the blocks of the (UTC) day
+//
+// As with the summaries, deltas of blocks at or below the folded block are dropped, blocks indexed after newer blocks have
+// been folded are not reflected in the aggregation.
+const BURNED_FEES_TOTAL_COLUMN = "total"
+
+const burnedFeesDayColumnPrefix = "DAY:"
+
+// burnedFeesDelta is the burn of a single block
+type burnedFeesDelta struct {
+	Block  uint64 `json:"block"`
+	Time   int64  `json:"time"`
+	Burned []byte `json:"burned"`
+}
+
+// burnedFeesTotal is the total burn together with the highest block folded into it
+type burnedFeesTotal struct {
+	Total  []byte `json:"total"`
+	Folded uint64 `json:"folded"`
+}
+
+// burnedFeesOfBlock returns the wei burned by a block, the base fee times the gas used
+func burnedFeesOfBlock(block *types.Eth1Block) types.Amount {
+	return types.NewEtherAmount(new(big.Int).Mul(new(big.Int).SetBytes(block.GetBaseFee()), new(big.Int).SetUint64(block.GetGasUsed())))
+}
+
+func burnedFeesDayColumn(ts time.Time) string {
+	return burnedFeesDayColumnPrefix + ts.UTC().Format("2006-01-02")
+}
+
+// transformBurnedFees writes the burn delta of a block, it is applied by TransformBlock
+func (bigtable *Bigtable) transformBurnedFees(blk *types.Eth1Block, burned []byte, bulkMetadataUpdates *types.BulkMutations) error {
+	b, err := json.Marshal(&burnedFeesDelta{Block: blk.GetNumber(), Time: blk.GetTime().AsTime().Unix(), Burned: burned})
+	if err != nil {
+		return err
+	}
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(DEFAULT_FAMILY, reversedPaddedBlockNumber(blk.GetNumber()), gcp_bigtable.Timestamp(0), b)
+	bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, fmt.Sprintf("%s:BURN", bigtable.chainId))
+	bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, mut)
+	return nil
+}
+
+// deleteBurnedFeesDelta deletes the pending burn delta of an orphaned block
+func (bigtable *Bigtable) deleteBurnedFeesDelta(ctx context.Context, block *types.Eth1Block) error {
+	if len(block.GetBaseFee()) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	mut := gcp_bigtable.NewMutation()
+	mut.DeleteCellsInColumn(DEFAULT_FAMILY, reversedPaddedBlockNumber(block.GetNumber()))
+	return bigtable.apply(ctx, bigtable.tableMetadataUpdates, fmt.Sprintf("%s:BURN", bigtable.chainId), mut)
+}
+
+// burnedFeesRow is the decoded aggregation row, the days are keyed by their column
+type burnedFeesRow struct {
+	total burnedFeesTotal
+	days  map[string]*big.Int
+}
+
+func parseBurnedFeesRow(row gcp_bigtable.Row) (*burnedFeesRow, error) {
+	parsed := &burnedFeesRow{days: map[string]*big.Int{}}
+	for _, item := range row[DEFAULT_FAMILY] {
+		column := strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		if column == BURNED_FEES_TOTAL_COLUMN {
+			err := json.Unmarshal(item.Value, &parsed.total)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the burned fees total: %w", err)
+			}
+		} else if strings.HasPrefix(column, burnedFeesDayColumnPrefix) {
+			parsed.days[column] = new(big.Int).SetBytes(item.Value)
+		}
+	}
+	return parsed, nil
+}
+
+// parseBurnedFeesDeltas decodes the delta cells of the burn row of the metadata updates table
+func parseBurnedFeesDeltas(items []gcp_bigtable.ReadItem) ([]*burnedFeesDelta, error) {
+	deltas := make([]*burnedFeesDelta, 0, len(items))
+	for _, item := range items {
+		delta := &burnedFeesDelta{}
+		err := json.Unmarshal(item.Value, delta)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding burned fees delta %v: %w", item.Column, err)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas, nil
+}
+
+// fold adds the deltas of blocks above the folded block to the total and the days, it returns the number of folded deltas and
+// the columns of the days that changed
+func (row *burnedFeesRow) fold(deltas []*burnedFeesDelta) (int, []string) {
+	total := new(big.Int).SetBytes(row.total.Total)
+	highest := row.total.Folded
+	count := 0
+	changed := map[string]bool{}
+	for _, delta := range deltas {
+		if delta.Block <= row.total.Folded {
+			continue
+		}
+		if delta.Block > highest {
+			highest = delta.Block
+		}
+		burned := new(big.Int).SetBytes(delta.Burned)
+		total.Add(total, burned)
+
+		column := burnedFeesDayColumn(time.Unix(delta.Time, 0))
+		if row.days[column] == nil {
+			row.days[column] = new(big.Int)
+		}
+		row.days[column].Add(row.days[column], burned)
+		changed[column] = true
+		count++
+	}
+	row.total.Total = total.Bytes()
+	row.total.Folded = highest
+
+	columns := make([]string, 0, len(changed))
+	for column := range changed {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return count, columns
+}
+
+func (bigtable *Bigtable) getBurnedFeesRow(ctx context.Context) (*burnedFeesRow, error) {
+	row, err := bigtable.readRow(ctx, bigtable.tableData, fmt.Sprintf("%s:BURN", bigtable.chainId), gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	return parseBurnedFeesRow(row)
+}
+
+// ProcessBurnedFeesUpdates folds the pending burn deltas of blocks up to the finalized block into the aggregation row and
+// returns the number of folded blocks. It must only be run by a single indexer.
+func (bigtable *Bigtable) ProcessBurnedFeesUpdates(ctx context.Context, finalized uint64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	key := fmt.Sprintf("%s:BURN", bigtable.chainId)
+	// the deltas are ordered by descending block number, all columns starting at the finalized block belong to finalized blocks
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, reversedPaddedBlockNumber(finalized), ""),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	pending, err := bigtable.readRow(ctx, bigtable.tableMetadataUpdates, key, gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return 0, err
+	}
+	items := pending[DEFAULT_FAMILY]
+	if len(items) == 0 {
+		return 0, nil
+	}
+	deltas, err := parseBurnedFeesDeltas(items)
+	if err != nil {
+		return 0, err
+	}
+
+	row, err := bigtable.getBurnedFeesRow(ctx)
+	if err != nil {
+		return 0, err
+	}
+	folded, changed := row.fold(deltas)
+
+	// the aggregation has to be written before the deltas are deleted, deltas that are left behind are skipped by the next run
+	if folded > 0 {
+		b, err := json.Marshal(&row.total)
+		if err != nil {
+			return 0, err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, BURNED_FEES_TOTAL_COLUMN, gcp_bigtable.Timestamp(0), b)
+		for _, column := range changed {
+			mut.Set(DEFAULT_FAMILY, column, gcp_bigtable.Timestamp(0), row.days[column].Bytes())
+		}
+		err = bigtable.apply(ctx, bigtable.tableData, key, mut)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	mutDelete := gcp_bigtable.NewMutation()
+	for _, item := range items {
+		mutDelete.DeleteCellsInColumn(DEFAULT_FAMILY, strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":"))
+	}
+	err = bigtable.apply(ctx, bigtable.tableMetadataUpdates, key, mutDelete)
+	if err != nil {
+		return 0, err
+	}
+	return folded, nil
+}
+
+// GetBurnedFees returns the total burn and the burn of the last days, including the deltas of blocks that have not been
+// folded into the aggregation yet
+func (bigtable *Bigtable) GetBurnedFees(ctx context.Context, days uint64) (*types.BurnedFees, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*10))
+	defer cancel()
+
+	row, err := bigtable.getBurnedFeesRow(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := bigtable.readRow(ctx, bigtable.tableMetadataUpdates, fmt.Sprintf("%s:BURN", bigtable.chainId), gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	deltas, err := parseBurnedFeesDeltas(pending[DEFAULT_FAMILY])
+	if err != nil {
+		return nil, err
+	}
+	row.fold(deltas)
+
+	return row.burnedFees(days), nil
+}
+
+// burnedFees converts the aggregation to its api representation, only the last days are included, oldest first
+func (row *burnedFeesRow) burnedFees(days uint64) *types.BurnedFees {
+	columns := make([]string, 0, len(row.days))
+	for column := range row.days {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	if uint64(len(columns)) > days {
+		columns = columns[uint64(len(columns))-days:]
+	}
+
+	burned := &types.BurnedFees{
+		Total: types.EtherAmountFromBytes(row.total.Total),
+		Block: row.total.Folded,
+		Days:  make([]*types.BurnedFeesDay, 0, len(columns)),
+	}
+	for _, column := range columns {
+		day, err := time.Parse("2006-01-02", strings.TrimPrefix(column, burnedFeesDayColumnPrefix))
+		if err != nil {
+			logger.Warnf("skipping burned fees of malformed day column %v", column)
+			continue
+		}
+		burned.Days = append(burned.Days, &types.BurnedFeesDay{Day: day, BurnedFees: types.NewEtherAmount(row.days[column])})
+	}
+	return burned
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"encoding/json"
+	"eth2-exporter/types"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/coocood/freecache"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTransformBlockBurnedFees(t *testing.T) {
+	bt := &Bigtable{chainId: "1"}
+	block := &types.Eth1Block{
+		Number:   100,
+		GasUsed:  21000,
+		BaseFee:  big.NewInt(10e9).Bytes(),
+		Coinbase: []byte{0x01},
+		Time:     timestamppb.New(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)),
+	}
+
+	bulkData, bulkMetadataUpdates, err := bt.TransformBlock(block, freecache.NewCache(1024*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bulkData.Keys) == 0 {
+		t.Fatalf("expected the block row to be written")
+	}
+
+	expected := big.NewInt(21000 * 10e9)
+	found := false
+	for i, key := range bulkMetadataUpdates.Keys {
+		if key == "1:BURN" {
+			found = true
+			if bulkMetadataUpdates.Muts[i] == nil {
+				t.Errorf("expected a mutation for the burn delta")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a burn delta in %v", bulkMetadataUpdates.Keys)
+	}
+	if got := burnedFeesOfBlock(block); got.BigInt().Cmp(expected) != 0 {
+		t.Errorf("expected %v wei to be burned, got %v", expected, got)
+	}
+
+	block.BaseFee = nil
+	_, bulkMetadataUpdates, err = bt.TransformBlock(block, freecache.NewCache(1024*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range bulkMetadataUpdates.Keys {
+		if key == "1:BURN" {
+			t.Errorf("expected no burn delta for a block without base fee")
+		}
+	}
+}
+
+func TestFoldBurnedFees(t *testing.T) {
+	day1 := time.Date(2023, 7, 1, 23, 59, 0, 0, time.UTC)
+	day2 := time.Date(2023, 7, 2, 0, 1, 0, 0, time.UTC)
+
+	row := &burnedFeesRow{
+		total: burnedFeesTotal{Total: big.NewInt(1000).Bytes(), Folded: 99},
+		days:  map[string]*big.Int{burnedFeesDayColumn(day1): big.NewInt(1000)},
+	}
+	deltas := []*burnedFeesDelta{
+		{Block: 102, Time: day2.Unix(), Burned: big.NewInt(30).Bytes()},
+		{Block: 101, Time: day2.Unix(), Burned: big.NewInt(20).Bytes()},
+		{Block: 100, Time: day1.Unix(), Burned: big.NewInt(10).Bytes()},
+		// folded before
+		{Block: 99, Time: day1.Unix(), Burned: big.NewInt(5).Bytes()},
+	}
+
+	folded, changed := row.fold(deltas)
+	if folded != 3 {
+		t.Errorf("expected 3 folded deltas, got %v", folded)
+	}
+	if len(changed) != 2 || changed[0] != "DAY:2023-07-01" || changed[1] != "DAY:2023-07-02" {
+		t.Errorf("unexpected changed days %v", changed)
+	}
+	if row.total.Folded != 102 {
+		t.Errorf("expected block 102 to be folded, got %v", row.total.Folded)
+	}
+
+	burned := row.burnedFees(1)
+	if burned.Total.BigInt().String() != "1060" || burned.Block != 102 {
+		t.Errorf("unexpected total %v at block %v", burned.Total, burned.Block)
+	}
+	if len(burned.Days) != 1 || !burned.Days[0].Day.Equal(time.Date(2023, 7, 2, 0, 0, 0, 0, time.UTC)) || burned.Days[0].BurnedFees.BigInt().String() != "50" {
+		b, _ := json.Marshal(burned.Days)
+		t.Errorf("expected only the last day, got %s", b)
+	}
+	if burned := row.burnedFees(30); len(burned.Days) != 2 || burned.Days[0].BurnedFees.BigInt().String() != "1010" {
+		t.Errorf("expected both days oldest first, got %v days", len(burned.Days))
+	}
+}
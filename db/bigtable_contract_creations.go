@@ -0,0 +1,259 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"google.golang.org/protobuf/proto"
+)
+
+// contractCreationsOfTransaction returns the contracts a successful transaction deployed, either directly or via a factory contract.
+// From is set to the deployer, To to the address of the new contract and Type to one of the types.ContractCreation* kinds.
+func contractCreationsOfTransaction(blk *types.Eth1Block, tx *types.Eth1Transaction) []*types.Eth1InternalTransactionIndexed {
+	if tx.GetErrorMsg() != "" {
+		return nil
+	}
+
+	creations := []*types.Eth1InternalTransactionIndexed{}
+	direct := len(tx.GetTo()) == 0 && len(tx.GetContractAddress()) > 0 && !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS)
+	if direct {
+		creations = append(creations, &types.Eth1InternalTransactionIndexed{
+			ParentHash:  tx.GetHash(),
+			BlockNumber: blk.GetNumber(),
+			Time:        blk.GetTime(),
+			Type:        types.ContractCreationTx,
+			From:        tx.GetFrom(),
+			To:          tx.GetContractAddress(),
+		})
+	}
+
+	for _, itx := range tx.GetItx() {
+		if itx.GetType() != types.ContractCreationCreate && itx.GetType() != types.ContractCreationCreate2 {
+			continue
+		}
+		if itx.GetErrorMsg() != "" || len(itx.GetTo()) == 0 || bytes.Equal(itx.GetTo(), ZERO_ADDRESS) {
+			continue
+		}
+		// the traces also contain the top level creation of a direct deployment
+		if direct && bytes.Equal(itx.GetTo(), tx.GetContractAddress()) {
+			continue
+		}
+		creations = append(creations, &types.Eth1InternalTransactionIndexed{
+			ParentHash:  tx.GetHash(),
+			BlockNumber: blk.GetNumber(),
+			Time:        blk.GetTime(),
+			Type:        itx.GetType(),
+			From:        itx.GetFrom(),
+			To:          itx.GetTo(),
+		})
+	}
+	return creations
+}
+
+// contractDestructionsOfTransaction returns the contracts a successful transaction removed by a self destruct.
+// Since EIP-6780 (cancun, activated together with deneb) a self destruct only removes the code of a contract that was created in the same transaction.
+func contractDestructionsOfTransaction(blk *types.Eth1Block, tx *types.Eth1Transaction, created map[string]bool) []*types.Eth1InternalTransactionIndexed {
+	if tx.GetErrorMsg() != "" {
+		return nil
+	}
+
+	destructions := []*types.Eth1InternalTransactionIndexed{}
+	for j, itx := range tx.GetItx() {
+		if itx.GetType() != "suicide" || itx.GetErrorMsg() != "" {
+			continue
+		}
+		if selfDestructKeepsCode(blk) && !created[string(itx.GetFrom())] {
+			continue
+		}
+		destructions = append(destructions, newIndexedItx(blk, tx, itx, j))
+	}
+	return destructions
+}
+
+// selfDestructKeepsCode returns whether the block is subject to EIP-6780
+func selfDestructKeepsCode(blk *types.Eth1Block) bool {
+	if utils.Config == nil || utils.Config.Chain.Config.DenebForkEpoch == math.MaxUint64 {
+		return false
+	}
+	return !blk.GetTime().AsTime().Before(utils.EpochToTime(utils.Config.Chain.Config.DenebForkEpoch))
+}
+
+// contractLifecycleTimestamp orders the creations and self destructs of a contract by the position of their transaction in the chain,
+// the latest one gets the highest timestamp
+func contractLifecycleTimestamp(block uint64, txIdx int) gcp_bigtable.Timestamp {
+	return gcp_bigtable.Timestamp((block*10000 + uint64(txIdx)) * 1000)
+}
+
+// TransformContractCreations keeps a registry of how every contract was deployed
+// Row:    <chainID>:CONTRACT_CREATION:<CONTRACT_ADDRESS>
+// Family: f
+// Column: data
+// Cell:   Proto<Eth1InternalTransactionIndexed>
+//
+// From holds the deployer of the contract, which is the factory for contracts created by other contracts, and Type the kind of deployment
+// (tx for contracts deployed by a transaction without recipient, create or create2 for contracts deployed by a factory).
+//
+// Contracts deployed by a transaction are queued for source verification, see ProcessContractVerifications.
+//
+// The creations and self destructs of contracts are written to the metadata updates table, the cells are versioned by the position
+// of the transaction in the chain and only the last event of a contract within a transaction is kept, see ProcessContractDestructions.
+// Row:    <chainID>:CONTRACT_DESTRUCTION:<CONTRACT_ADDRESS>
+// Family: f
+// Column: DESTRUCTION
+// Cell:   Proto<Eth1InternalTransactionIndexed> of the self destruct or empty if the contract was created (again)
+func (bigtable *Bigtable) TransformContractCreations(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	for i, tx := range blk.GetTransactions() {
+		lifecycle := make(map[string][]byte)
+		order := []string{}
+		creations := contractCreationsOfTransaction(blk, tx)
+		created := make(map[string]bool, len(creations))
+		for _, creation := range creations {
+			created[string(creation.To)] = true
+			if _, ok := lifecycle[string(creation.To)]; !ok {
+				order = append(order, string(creation.To))
+			}
+			lifecycle[string(creation.To)] = []byte{}
+		}
+		// a destructed contract can only be created again in a later transaction, its destruction is the last event of the transaction
+		for _, destruction := range contractDestructionsOfTransaction(blk, tx, created) {
+			b, err := proto.Marshal(destruction)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := lifecycle[string(destruction.From)]; !ok {
+				order = append(order, string(destruction.From))
+			}
+			lifecycle[string(destruction.From)] = b
+		}
+		for _, address := range order {
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, CONTRACT_DESTRUCTION, contractLifecycleTimestamp(blk.GetNumber(), i), lifecycle[address])
+
+			bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, fmt.Sprintf("%s:CONTRACT_DESTRUCTION:%x", bigtable.chainId, address))
+			bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, mut)
+		}
+
+		for _, creation := range creations {
+			b, err := proto.Marshal(creation)
+			if err != nil {
+				return nil, nil, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
+
+			bulkData.Keys = append(bulkData.Keys, fmt.Sprintf("%s:CONTRACT_CREATION:%x", bigtable.chainId, creation.To))
+			bulkData.Muts = append(bulkData.Muts, mut)
+
+			if creation.Type == types.ContractCreationTx {
+				mut := gcp_bigtable.NewMutation()
+				mut.Set(DEFAULT_FAMILY, CONTRACT_VERIFICATION_DEPLOYED_COLUMN, gcp_bigtable.Time(blk.GetTime().AsTime()), creation.To)
+
+				bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, fmt.Sprintf("%s:CONTRACT_VERIFICATION:%x", bigtable.chainId, creation.To))
+				bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, mut)
+			}
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// GetContractCreation returns how the contract at the given address was deployed or nil if no deployment has been indexed
+func (bigtable *Bigtable) GetContractCreation(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	row, err := bigtable.readRow(ctx, bigtable.tableData, fmt.Sprintf("%s:CONTRACT_CREATION:%x", bigtable.chainId, address), skipTombstones())
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, nil
+	}
+
+	creation := &types.Eth1InternalTransactionIndexed{}
+	err = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, creation)
+	if err != nil {
+		return nil, err
+	}
+	return creation, nil
+}
+
+// ProcessContractDestructions moves the queued contract creations and self destructs to the contract metadata, the latest version
+// of the destruction column tells whether a contract is currently destroyed
+func (bigtable *Bigtable) ProcessContractDestructions(ctx context.Context, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	mutsWrite := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+
+	prefix := fmt.Sprintf("%s:CONTRACT_DESTRUCTION:", bigtable.chainId)
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+		mut := gcp_bigtable.NewMutation()
+		for _, item := range row[DEFAULT_FAMILY] {
+			mut.Set(CONTRACT_METADATA_FAMILY, CONTRACT_DESTRUCTION, item.Timestamp, item.Value)
+		}
+		mutsWrite.Keys = append(mutsWrite.Keys, fmt.Sprintf("%s:%s", bigtable.chainId, strings.TrimPrefix(row.Key(), prefix)))
+		mutsWrite.Muts = append(mutsWrite.Muts, mut)
+
+		mutDelete := gcp_bigtable.NewMutation()
+		mutDelete.DeleteRow()
+		mutsDelete.Keys = append(mutsDelete.Keys, row.Key())
+		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+		return true
+	}, gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+	if len(mutsWrite.Keys) == 0 {
+		return 0, nil
+	}
+
+	err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableMetadata)
+	if err != nil {
+		return 0, err
+	}
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
+	if err != nil {
+		return 0, err
+	}
+	return len(mutsWrite.Keys), nil
+}
+
+// GetContractSelfDestruct returns the internal transaction that destroyed the contract at the given address or nil if the contract
+// was never destroyed or has been created again since
+func (bigtable *Bigtable) GetContractSelfDestruct(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(CONTRACT_METADATA_FAMILY),
+		gcp_bigtable.ColumnFilter(CONTRACT_DESTRUCTION),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address), gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+	if row == nil || len(row[CONTRACT_METADATA_FAMILY][0].Value) == 0 {
+		return nil, nil
+	}
+
+	destruct := &types.Eth1InternalTransactionIndexed{}
+	err = proto.Unmarshal(row[CONTRACT_METADATA_FAMILY][0].Value, destruct)
+	if err != nil {
+		return nil, err
+	}
+	return destruct, nil
+}
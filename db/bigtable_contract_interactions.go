@@ -0,0 +1,509 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The contract calls of every sender are rolled up per (sender, contract) pair and month the same way as the address summaries.
+// TransformContractInteractions writes the calls of a block to the metadata updates table, keyed by the block so that indexing
+// a block again overwrites its contribution:
+// Row:    <chainID>:CI:<FROM_ADDRESS>:<CONTRACT_ADDRESS>
+// Family: f
+// Column: <reversedPaddedBlockNumber>
+// Cell:   Json<contractInteractionDelta>
+//
+// ProcessContractInteractionUpdates folds the deltas of blocks that can no longer be reorged into the counters in the data table:
+// Row:    <chainID>:CI:<FROM_ADDRESS>:<CONTRACT_ADDRESS>
+// Family: f
+// Column: folded
+// Cell:   Json<contractInteractionsFolded>
+// Column: MONTH:<yyyy-mm>
+// Cell:   Json<contractInteractionCounters> of the blocks of the (UTC) month
+//
+// As with the summaries, deltas of blocks at or below the folded block are dropped.
+const CONTRACT_INTERACTIONS_FOLDED_COLUMN = "folded"
+
+const contractInteractionsMonthColumnPrefix = "MONTH:"
+
+// maximum number of distinct contracts that are aggregated for a single address
+const contractInteractionsReadLimit = 5000
+
+// contractInteractionCounters are the calls of a sender to a contract
+type contractInteractionCounters struct {
+	Transactions uint64 `json:"txs"`
+	GasUsed      uint64 `json:"gas_used"`
+	Fees         []byte `json:"fees,omitempty"`
+}
+
+func (c *contractInteractionCounters) add(other *contractInteractionCounters) {
+	c.Transactions += other.Transactions
+	c.GasUsed += other.GasUsed
+	c.Fees = addBigBytes(c.Fees, other.Fees)
+}
+
+// contractInteractionDelta is the contribution of a single block to the calls of a sender to a contract
+type contractInteractionDelta struct {
+	Block uint64 `json:"block"`
+	Time  int64  `json:"time"`
+	contractInteractionCounters
+}
+
+// contractInteractionsFolded is the highest block folded into the counters of a (sender, contract) pair
+type contractInteractionsFolded struct {
+	Folded uint64 `json:"folded"`
+}
+
+func contractInteractionsMonthColumn(ts time.Time) string {
+	return contractInteractionsMonthColumnPrefix + ts.UTC().Format("2006-01")
+}
+
+// contractInteractionDeltas returns the contract calls of a block keyed by <FROM_ADDRESS>:<CONTRACT_ADDRESS>
+func contractInteractionDeltas(blk *types.Eth1Block) map[string]*contractInteractionDelta {
+	deltas := map[string]*contractInteractionDelta{}
+	for _, tx := range blk.GetTransactions() {
+		if len(tx.GetTo()) == 0 || !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS) {
+			continue
+		}
+		// same heuristic as in TransformTx
+		if len(tx.GetItx()) == 0 && tx.GetGasUsed() <= 21000 && tx.GetErrorMsg() == "" {
+			continue
+		}
+
+		key := fmt.Sprintf("%x:%x", tx.GetFrom(), tx.GetTo())
+		delta := deltas[key]
+		if delta == nil {
+			delta = &contractInteractionDelta{Block: blk.GetNumber(), Time: blk.GetTime().AsTime().Unix()}
+			deltas[key] = delta
+		}
+		delta.add(&contractInteractionCounters{
+			Transactions: 1,
+			GasUsed:      tx.GetGasUsed(),
+			Fees:         CalculateTxFeeFromTransaction(tx, new(big.Int).SetBytes(blk.GetBaseFee())).Bytes(),
+		})
+	}
+	return deltas
+}
+
+// TransformContractInteractions writes the contract calls of a block as deltas of the per month counters of every (sender, contract) pair
+func (bigtable *Bigtable) TransformContractInteractions(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	deltas := contractInteractionDeltas(blk)
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		b, err := json.Marshal(deltas[key])
+		if err != nil {
+			return nil, nil, err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, reversedPaddedBlockNumber(blk.GetNumber()), gcp_bigtable.Timestamp(0), b)
+
+		bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, fmt.Sprintf("%s:CI:%s", bigtable.chainId, key))
+		bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, mut)
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// deleteContractInteractionDeltas deletes the pending contract interaction deltas of an orphaned block
+func (bigtable *Bigtable) deleteContractInteractionDeltas(ctx context.Context, block *types.Eth1Block) error {
+	muts := &types.BulkMutations{}
+	for key := range contractInteractionDeltas(block) {
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteCellsInColumn(DEFAULT_FAMILY, reversedPaddedBlockNumber(block.GetNumber()))
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:CI:%s", bigtable.chainId, key))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	if len(muts.Keys) == 0 {
+		return nil
+	}
+	return bigtable.WriteBulk(ctx, muts, bigtable.tableMetadataUpdates)
+}
+
+// parseContractInteractionDeltas decodes the delta cells of a row of the metadata updates table
+func parseContractInteractionDeltas(items []gcp_bigtable.ReadItem) ([]*contractInteractionDelta, error) {
+	deltas := make([]*contractInteractionDelta, 0, len(items))
+	for _, item := range items {
+		delta := &contractInteractionDelta{}
+		err := json.Unmarshal(item.Value, delta)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding contract interaction delta %v: %w", item.Column, err)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas, nil
+}
+
+// contractInteractionRollup is a decoded counter row, the months are keyed by their column
+type contractInteractionRollup struct {
+	folded contractInteractionsFolded
+	months map[string]*contractInteractionCounters
+}
+
+func parseContractInteractionRollup(items []gcp_bigtable.ReadItem) (*contractInteractionRollup, error) {
+	rollup := &contractInteractionRollup{months: map[string]*contractInteractionCounters{}}
+	for _, item := range items {
+		column := strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		if column == CONTRACT_INTERACTIONS_FOLDED_COLUMN {
+			err := json.Unmarshal(item.Value, &rollup.folded)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the folded block of contract interactions: %w", err)
+			}
+		} else if strings.HasPrefix(column, contractInteractionsMonthColumnPrefix) {
+			counters := &contractInteractionCounters{}
+			err := json.Unmarshal(item.Value, counters)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding contract interactions %v: %w", column, err)
+			}
+			rollup.months[column] = counters
+		}
+	}
+	return rollup, nil
+}
+
+// fold adds the deltas of blocks above the folded block to their months, it returns the number of folded deltas and the columns
+// of the months that changed
+func (rollup *contractInteractionRollup) fold(deltas []*contractInteractionDelta) (int, []string) {
+	highest := rollup.folded.Folded
+	count := 0
+	changed := map[string]bool{}
+	for _, delta := range deltas {
+		if delta.Block <= rollup.folded.Folded {
+			continue
+		}
+		if delta.Block > highest {
+			highest = delta.Block
+		}
+
+		column := contractInteractionsMonthColumn(time.Unix(delta.Time, 0))
+		if rollup.months[column] == nil {
+			rollup.months[column] = &contractInteractionCounters{}
+		}
+		rollup.months[column].add(&delta.contractInteractionCounters)
+		changed[column] = true
+		count++
+	}
+	rollup.folded.Folded = highest
+
+	columns := make([]string, 0, len(changed))
+	for column := range changed {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return count, columns
+}
+
+// ProcessContractInteractionUpdates folds the pending contract interaction deltas of blocks up to the finalized block into the
+// counters of up to limit (sender, contract) pairs and returns the number of updated pairs. It must only be run by a single indexer.
+func (bigtable *Bigtable) ProcessContractInteractionUpdates(ctx context.Context, finalized uint64, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	// the deltas are ordered by descending block number, all columns starting at the finalized block belong to finalized blocks
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, reversedPaddedBlockNumber(finalized), ""),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	keys := []string{}
+	pending := map[string][]gcp_bigtable.ReadItem{}
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(fmt.Sprintf("%s:CI:", bigtable.chainId)), func(row gcp_bigtable.Row) bool {
+		keys = append(keys, row.Key())
+		pending[row.Key()] = row[DEFAULT_FAMILY]
+		return true
+	}, gcp_bigtable.RowFilter(filter), gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deltas := make(map[string][]*contractInteractionDelta, len(keys))
+	firstMonth, lastMonth := "", ""
+	for _, key := range keys {
+		deltas[key], err = parseContractInteractionDeltas(pending[key])
+		if err != nil {
+			return 0, fmt.Errorf("error processing the contract interaction updates of %v: %w", key, err)
+		}
+		for _, delta := range deltas[key] {
+			column := contractInteractionsMonthColumn(time.Unix(delta.Time, 0))
+			if firstMonth == "" || column < firstMonth {
+				firstMonth = column
+			}
+			if column > lastMonth {
+				lastMonth = column
+			}
+		}
+	}
+
+	// only the months the deltas fall into are read from the stored counters, the end of a column range is exclusive
+	stored := make(map[string]*contractInteractionRollup, len(keys))
+	var parseErr error
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		stored[row.Key()], parseErr = parseContractInteractionRollup(row[DEFAULT_FAMILY])
+		return parseErr == nil
+	}, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(
+		gcp_bigtable.InterleaveFilters(
+			gcp_bigtable.ColumnFilter(CONTRACT_INTERACTIONS_FOLDED_COLUMN),
+			gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, firstMonth, lastMonth+"\x00"),
+		),
+		gcp_bigtable.LatestNFilter(1),
+	)))
+	if err != nil {
+		return 0, err
+	}
+	if parseErr != nil {
+		return 0, parseErr
+	}
+
+	mutsWrite := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+	for _, key := range keys {
+		rollup := stored[key]
+		if rollup == nil {
+			rollup = &contractInteractionRollup{months: map[string]*contractInteractionCounters{}}
+		}
+		folded, changed := rollup.fold(deltas[key])
+		if folded > 0 {
+			b, err := json.Marshal(&rollup.folded)
+			if err != nil {
+				return 0, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, CONTRACT_INTERACTIONS_FOLDED_COLUMN, gcp_bigtable.Timestamp(0), b)
+			for _, column := range changed {
+				b, err := json.Marshal(rollup.months[column])
+				if err != nil {
+					return 0, err
+				}
+				mut.Set(DEFAULT_FAMILY, column, gcp_bigtable.Timestamp(0), b)
+			}
+			mutsWrite.Keys = append(mutsWrite.Keys, key)
+			mutsWrite.Muts = append(mutsWrite.Muts, mut)
+		}
+
+		// only the folded columns are deleted, deltas of newer blocks written in the meantime are kept
+		mutDelete := gcp_bigtable.NewMutation()
+		for _, item := range pending[key] {
+			mutDelete.DeleteCellsInColumn(DEFAULT_FAMILY, strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":"))
+		}
+		mutsDelete.Keys = append(mutsDelete.Keys, key)
+		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+	}
+
+	// the counters have to be written before the deltas are deleted, deltas that are left behind are skipped by the next run
+	if len(mutsWrite.Keys) > 0 {
+		err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableData)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
+	if err != nil {
+		return 0, err
+	}
+	return len(mutsWrite.Keys), nil
+}
+
+// GetAddressContractInteractions returns the contracts an address has called most often, resolved against the known address names.
+// The deltas of blocks that have not been folded into the counters yet are included.
+func (bigtable *Bigtable) GetAddressContractInteractions(ctx context.Context, address []byte, limit int) ([]*types.AddressContractInteraction, error) {
+	cacheKey := fmt.Sprintf("%s:CI:%x:%d", bigtable.chainId, address, limit)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Minute*10, new([]*types.AddressContractInteraction)); err == nil {
+		return *cached.(*[]*types.AddressContractInteraction), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:CI:%x:", bigtable.chainId, address)
+	rollups := make(map[string]*contractInteractionRollup)
+	var parseErr error
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+		rollups[row.Key()], parseErr = parseContractInteractionRollup(row[DEFAULT_FAMILY])
+		return parseErr == nil
+	}, gcp_bigtable.LimitRows(contractInteractionsReadLimit), gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	err = bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+		var deltas []*contractInteractionDelta
+		deltas, parseErr = parseContractInteractionDeltas(row[DEFAULT_FAMILY])
+		if parseErr != nil {
+			return false
+		}
+		rollup := rollups[row.Key()]
+		if rollup == nil {
+			if len(rollups) >= contractInteractionsReadLimit {
+				return true
+			}
+			rollup = &contractInteractionRollup{months: map[string]*contractInteractionCounters{}}
+			rollups[row.Key()] = rollup
+		}
+		rollup.fold(deltas)
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	interactions := make([]*types.AddressContractInteraction, 0, len(rollups))
+	for key, rollup := range rollups {
+		sum := &contractInteractionCounters{}
+		for _, counters := range rollup.months {
+			sum.add(counters)
+		}
+		if sum.Transactions == 0 {
+			continue
+		}
+		interactions = append(interactions, &types.AddressContractInteraction{
+			Contract:     common.FromHex(strings.TrimPrefix(key, prefix)),
+			Transactions: sum.Transactions,
+			GasUsed:      sum.GasUsed,
+			Fees:         sum.Fees,
+		})
+	}
+
+	sort.Slice(interactions, func(i, j int) bool {
+		if interactions[i].Transactions == interactions[j].Transactions {
+			return interactions[i].GasUsed > interactions[j].GasUsed
+		}
+		return interactions[i].Transactions > interactions[j].Transactions
+	})
+	if len(interactions) > limit {
+		interactions = interactions[:limit]
+	}
+
+	names := make(map[string]string, len(interactions))
+	for _, interaction := range interactions {
+		names[string(interaction.Contract)] = ""
+	}
+	names, _, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, interaction := range interactions {
+		interaction.Name = names[string(interaction.Contract)]
+	}
+
+	err = cache.TieredCache.Set(cacheKey, interactions, time.Minute*10)
+	if err != nil {
+		logger.Errorf("error caching contract interactions of address %x: %v", address, err)
+	}
+
+	return interactions, nil
+}
+
+func (bigtable *Bigtable) GetAddressContractInteractionsTableData(ctx context.Context, address []byte) (*types.DataTableResponse, error) {
+	interactions, err := bigtable.GetAddressContractInteractions(ctx, address, 25)
+	if err != nil {
+		return nil, err
+	}
+
+	tableData := make([][]interface{}, len(interactions))
+	for i, interaction := range interactions {
+		tableData[i] = []interface{}{
+			utils.FormatAddress(interaction.Contract, nil, interaction.Name, false, true, true),
+			utils.FormatAddCommas(interaction.Transactions),
+			utils.FormatAddCommas(interaction.GasUsed),
+			utils.FormatAmount(new(big.Int).SetBytes(interaction.Fees), "Ether", 6),
+		}
+	}
+
+	return &types.DataTableResponse{
+		Data: tableData,
+	}, nil
+}
+
+// GetContractMethodCalls returns up to limit transactions that called the method of the contract after since, newest first.
+// The calls are read from the method index of the transactions.
+func (bigtable *Bigtable) GetContractMethodCalls(ctx context.Context, contract []byte, method []byte, since time.Time, limit int64) ([]*types.Eth1TransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:I:TX:%x:%s:%x:", bigtable.chainId, contract, FILTER_METHOD, method)
+	keys := make([]string, 0, limit)
+	err := bigtable.readIndexRows(ctx, prefix, prefix+reversePaddedBigtableTimestamp(timestamppb.New(since)), limit, func(row gcp_bigtable.Row) bool {
+		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return []*types.Eth1TransactionIndexed{}, nil
+	}
+
+	keysMap := make(map[string]*types.Eth1TransactionIndexed, len(keys))
+	skipped := newSkippedRows("Eth1TransactionIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
+		tx := &types.Eth1TransactionIndexed{}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, tx)
+		if err != nil {
+			skipped.corrupt(row.Key(), err)
+			return true
+		}
+		keysMap[row.Key()] = tx
+		return true
+	}, skipTombstones())
+	if err != nil {
+		return nil, err
+	}
+	skipped.checkMissing(keys)
+
+	calls := make([]*types.Eth1TransactionIndexed, 0, len(keys))
+	for _, key := range keys {
+		// the method index of an address also contains the transactions it sent
+		if tx := keysMap[key]; tx != nil && bytes.Equal(tx.To, contract) {
+			calls = append(calls, tx)
+		}
+	}
+	return calls, skipped.err()
+}
+
+// CountContractReverts returns the number of reverted transactions of the contract after since, at most limit are counted
+func (bigtable *Bigtable) CountContractReverts(ctx context.Context, contract []byte, since time.Time, limit int64) (uint64, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, contract, FILTER_ERROR)
+	count := uint64(0)
+	err := bigtable.readIndexRows(ctx, prefix, prefix+reversePaddedBigtableTimestamp(timestamppb.New(since)), limit, func(row gcp_bigtable.Row) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
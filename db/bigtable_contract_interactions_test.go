@@ -0,0 +1,47 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestContractInteractionFold(t *testing.T) {
+	alice := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+	contract := []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02}
+	blockTime := time.Date(2023, 7, 31, 12, 0, 0, 0, time.UTC)
+
+	block := func(number uint64, txs ...*types.Eth1Transaction) *types.Eth1Block {
+		return &types.Eth1Block{Number: number, Time: timestamppb.New(blockTime.Add(time.Duration(number) * time.Hour)), Transactions: txs}
+	}
+	call := func(gasUsed uint64) *types.Eth1Transaction {
+		return &types.Eth1Transaction{From: alice, To: contract, ContractAddress: ZERO_ADDRESS, GasUsed: gasUsed}
+	}
+	key := fmt.Sprintf("%x:%x", alice, contract)
+
+	// plain transfers are not counted as contract calls
+	first := contractInteractionDeltas(block(1, call(50000), call(21000), call(30000)))
+	second := contractInteractionDeltas(block(20, call(40000)))
+	if first[key].Transactions != 2 || first[key].GasUsed != 80000 {
+		t.Errorf("expected 2 calls using 80000 gas in block 1, got %v using %v", first[key].Transactions, first[key].GasUsed)
+	}
+
+	rollup := &contractInteractionRollup{months: map[string]*contractInteractionCounters{}}
+	folded, changed := rollup.fold([]*contractInteractionDelta{second[key], first[key]})
+	if folded != 2 || rollup.folded.Folded != 20 {
+		t.Errorf("expected 2 deltas to be folded up to block 20, got %v up to %v", folded, rollup.folded.Folded)
+	}
+	if len(changed) != 2 || changed[0] != "MONTH:2023-07" || changed[1] != "MONTH:2023-08" {
+		t.Errorf("unexpected changed months %v", changed)
+	}
+	// folding a block again does not count its calls twice
+	if folded, _ := rollup.fold([]*contractInteractionDelta{first[key]}); folded != 0 {
+		t.Errorf("expected the delta of block 1 to be skipped, got %v folded deltas", folded)
+	}
+	if july := rollup.months["MONTH:2023-07"]; july.Transactions != 2 || july.GasUsed != 80000 {
+		t.Errorf("unexpected counters of july %+v", july)
+	}
+}
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// Contracts deployed by a transaction wait in the metadata updates table until their verified source has been fetched:
+// Row:    <chainID>:CONTRACT_VERIFICATION:<CONTRACT_ADDRESS>
+// Family: f
+// Column: deployed | checked
+// Cell:   contract address, the timestamp of deployed is the block time and the timestamp of checked the time of the last lookup
+//
+// ProcessContractVerifications stores the source of verified contracts in the contract family of the metadata table.
+const (
+	CONTRACT_VERIFICATION_DEPLOYED_COLUMN = "deployed"
+	CONTRACT_VERIFICATION_CHECKED_COLUMN  = "checked"
+)
+
+const (
+	// deployers usually verify their contracts shortly after the deployment
+	contractVerificationDelay = time.Minute * 10
+	// contracts that have not been verified yet are looked up again after this interval
+	contractVerificationRetryInterval = time.Hour
+	// contracts that have not been verified within this window after their deployment are dropped from the queue
+	contractVerificationWindow = time.Hour * 24
+)
+
+// ProcessContractVerifications looks up the verified source of up to limit queued contracts and returns the number of verified contracts
+func (bigtable *Bigtable) ProcessContractVerifications(ctx context.Context, limit int) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	type pendingContract struct {
+		key      string
+		address  []byte
+		deployed time.Time
+	}
+	due := make([]*pendingContract, 0, limit)
+	now := time.Now()
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(fmt.Sprintf("%s:CONTRACT_VERIFICATION:", bigtable.chainId)), func(row gcp_bigtable.Row) bool {
+		pending := &pendingContract{key: row.Key()}
+		var checked time.Time
+		for _, item := range row[DEFAULT_FAMILY] {
+			switch item.Column {
+			case DEFAULT_FAMILY + ":" + CONTRACT_VERIFICATION_DEPLOYED_COLUMN:
+				pending.address = item.Value
+				pending.deployed = item.Timestamp.Time()
+			case DEFAULT_FAMILY + ":" + CONTRACT_VERIFICATION_CHECKED_COLUMN:
+				checked = item.Timestamp.Time()
+			}
+		}
+		if len(pending.address) != 20 || now.Sub(pending.deployed) < contractVerificationDelay || now.Sub(checked) < contractVerificationRetryInterval {
+			return true
+		}
+		due = append(due, pending)
+		return len(due) < limit
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return 0, err
+	}
+
+	updates := &types.BulkMutations{}
+	verified := 0
+	for _, pending := range due {
+		meta, err := utils.TryFetchContractMetadata(pending.address)
+		if err == utils.ErrRateLimit {
+			logger.Warnf("hit rate limit when fetching contract metadata, continuing with the next run")
+			break
+		}
+		if err != nil {
+			logger.Warnf("error fetching contract metadata of 0x%x: %v", pending.address, err)
+		}
+
+		mut := gcp_bigtable.NewMutation()
+		switch {
+		case meta != nil:
+			err = bigtable.SaveContractMetadata(ctx, pending.address, meta)
+			if err != nil {
+				return verified, fmt.Errorf("error saving contract metadata of 0x%x: %w", pending.address, err)
+			}
+			err = cache.TieredCache.Set(bigtable.contractMetadataCacheKey(pending.address), meta, time.Hour*24)
+			if err != nil {
+				logger.Errorf("error caching contract metadata of 0x%x: %v", pending.address, err)
+			}
+			verified++
+			mut.DeleteRow()
+		case now.Sub(pending.deployed) > contractVerificationWindow:
+			mut.DeleteRow()
+		default:
+			mut.Set(DEFAULT_FAMILY, CONTRACT_VERIFICATION_CHECKED_COLUMN, gcp_bigtable.Time(now), pending.address)
+		}
+		updates.Keys = append(updates.Keys, pending.key)
+		updates.Muts = append(updates.Muts, mut)
+	}
+
+	if len(updates.Keys) == 0 {
+		return verified, nil
+	}
+	return verified, bigtable.WriteBulk(ctx, updates, bigtable.tableMetadataUpdates)
+}
@@ -0,0 +1,652 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/ethereum/go-ethereum/common"
+	eth_types "github.com/ethereum/go-ethereum/core/types"
+	"github.com/shopspring/decimal"
+)
+
+// The transfers between an address and its counterparties are rolled up per hour the same way as the address summaries.
+// TransformTx and TransformERC20 write the contribution of a block to the metadata updates table, once from the perspective
+// of each side of a transfer:
+// Row:    <chainID>:COUNTERPARTIES:<ADDRESS>:<COUNTERPARTY>
+// Family: f
+// Column: <reversedPaddedBlockNumber>:TX | <reversedPaddedBlockNumber>:ERC20
+// Cell:   Json<counterpartyDelta>
+//
+// ProcessCounterpartyUpdates folds the deltas of blocks that can no longer be reorged into the rollup in the data table:
+// Row:    <chainID>:COUNTERPARTIES:<ADDRESS>:<COUNTERPARTY>
+// Family: f
+// Column: folded
+// Cell:   Json<counterpartiesFolded>
+// Column: HOUR:<yyyy-mm-ddThh>
+// Cell:   Json<counterpartyTransfers> of the blocks of the (UTC) hour
+//
+// As with the summaries, deltas of blocks at or below the folded block are dropped. Transfers of blocks that have been
+// indexed before the rollups were written are not reflected.
+const COUNTERPARTIES_FOLDED_COLUMN = "folded"
+
+const counterpartiesHourColumnPrefix = "HOUR:"
+
+// CounterpartiesPeriod is the period the transfers between an address and a counterparty are rolled up by, the start of a
+// window is truncated to it
+const CounterpartiesPeriod = time.Hour
+
+// the counterparties of a window are cached for this duration
+const counterpartiesCacheDuration = time.Minute * 10
+
+// ether flows are keyed by this token key
+const counterpartiesEtherKey = "00"
+
+type addressCounterparties struct {
+	Counterparties []*types.AddressCounterparty
+	Truncated      bool
+}
+
+type counterpartyFlow struct {
+	In  []byte `json:"in,omitempty"`
+	Out []byte `json:"out,omitempty"`
+}
+
+// counterpartyTransfers are the number of transfers between an address and a counterparty and their flows, keyed by the hex
+// encoded token address
+type counterpartyTransfers struct {
+	Transfers uint64                       `json:"transfers"`
+	Flows     map[string]*counterpartyFlow `json:"flows"`
+}
+
+func (t *counterpartyTransfers) flow(token string) *counterpartyFlow {
+	if t.Flows == nil {
+		t.Flows = make(map[string]*counterpartyFlow)
+	}
+	f, ok := t.Flows[token]
+	if !ok {
+		f = &counterpartyFlow{}
+		t.Flows[token] = f
+	}
+	return f
+}
+
+func (t *counterpartyTransfers) add(other *counterpartyTransfers) {
+	t.Transfers += other.Transfers
+	for token, f := range other.Flows {
+		sum := t.flow(token)
+		sum.In = addBigBytes(sum.In, f.In)
+		sum.Out = addBigBytes(sum.Out, f.Out)
+	}
+}
+
+// counterpartyDelta is the contribution of a single block to the transfers between an address and a counterparty
+type counterpartyDelta struct {
+	Block uint64 `json:"block"`
+	Time  int64  `json:"time"`
+	counterpartyTransfers
+}
+
+// counterpartiesFolded is the highest block folded into a rollup
+type counterpartiesFolded struct {
+	Folded uint64 `json:"folded"`
+}
+
+// counterpartyDeltas collects the deltas of the address pairs touched by a block, keyed by <ADDRESS>:<COUNTERPARTY>
+type counterpartyDeltas map[string]*counterpartyDelta
+
+func (deltas counterpartyDeltas) get(blk *types.Eth1Block, address, counterparty []byte) *counterpartyDelta {
+	key := fmt.Sprintf("%x:%x", address, counterparty)
+	delta := deltas[key]
+	if delta == nil {
+		delta = &counterpartyDelta{Block: blk.GetNumber(), Time: blk.GetTime().AsTime().Unix()}
+		deltas[key] = delta
+	}
+	return delta
+}
+
+// add records a transfer of value from one address to another, self transfers do not add any information to the flow of funds
+func (deltas counterpartyDeltas) add(blk *types.Eth1Block, from, to []byte, token string, value []byte) {
+	if bytes.Equal(from, to) {
+		return
+	}
+	sent := deltas.get(blk, from, to)
+	sent.Transfers++
+	out := sent.flow(token)
+	out.Out = addBigBytes(out.Out, value)
+
+	received := deltas.get(blk, to, from)
+	received.Transfers++
+	in := received.flow(token)
+	in.In = addBigBytes(in.In, value)
+}
+
+// mutations returns the mutations writing the deltas to the given column of the block, ordered by row key
+func (deltas counterpartyDeltas) mutations(chainId string, column string) (*types.BulkMutations, error) {
+	keys := make([]string, 0, len(deltas))
+	for key := range deltas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	muts := &types.BulkMutations{}
+	for _, key := range keys {
+		b, err := json.Marshal(deltas[key])
+		if err != nil {
+			return nil, err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, column, gcp_bigtable.Timestamp(0), b)
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:COUNTERPARTIES:%s", chainId, key))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	return muts, nil
+}
+
+func counterpartiesTxColumn(block uint64) string {
+	return reversedPaddedBlockNumber(block) + ":TX"
+}
+
+func counterpartiesERC20Column(block uint64) string {
+	return reversedPaddedBlockNumber(block) + ":ERC20"
+}
+
+func counterpartiesHourColumn(ts time.Time) string {
+	return counterpartiesHourColumnPrefix + ts.UTC().Format("2006-01-02T15")
+}
+
+// counterpartyTxDeltas returns the ether transfers of the transactions of a block, contract creations are skipped and failed
+// transactions are counted without transferring any value
+func counterpartyTxDeltas(blk *types.Eth1Block) counterpartyDeltas {
+	deltas := counterpartyDeltas{}
+	for _, tx := range blk.GetTransactions() {
+		if len(tx.GetTo()) == 0 || !bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS) {
+			continue
+		}
+		value := tx.GetValue()
+		if tx.GetErrorMsg() != "" {
+			value = nil
+		}
+		deltas.add(blk, tx.GetFrom(), tx.GetTo(), counterpartiesEtherKey, value)
+	}
+	return deltas
+}
+
+// counterpartyERC20Deltas returns the token transfers of a block, selected the same way as the transfers indexed by TransformERC20
+func counterpartyERC20Deltas(blk *types.Eth1Block) (counterpartyDeltas, error) {
+	filterer, err := erc20.NewErc20Filterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating filterer: %w", err)
+	}
+
+	deltas := counterpartyDeltas{}
+	for _, tx := range blk.GetTransactions() {
+		for _, log := range tx.GetLogs() {
+			if len(log.GetTopics()) != 3 || !bytes.Equal(log.GetTopics()[0], erc20.TransferTopic) {
+				continue
+			}
+			topics := make([]common.Hash, 0, len(log.GetTopics()))
+			for _, lTopic := range log.GetTopics() {
+				topics = append(topics, common.BytesToHash(lTopic))
+			}
+			transfer, _ := filterer.ParseTransfer(eth_types.Log{Address: common.BytesToAddress(log.GetAddress()), Data: log.Data, Topics: topics})
+			if transfer == nil {
+				continue
+			}
+			value := []byte{}
+			if transfer.Value != nil {
+				value = transfer.Value.Bytes()
+			}
+			deltas.add(blk, transfer.From.Bytes(), transfer.To.Bytes(), fmt.Sprintf("%x", log.GetAddress()), value)
+		}
+	}
+	return deltas, nil
+}
+
+// transformCounterpartiesTx writes the counterparty deltas of the transactions of a block, it is applied by TransformTx
+func (bigtable *Bigtable) transformCounterpartiesTx(blk *types.Eth1Block, bulkMetadataUpdates *types.BulkMutations) error {
+	muts, err := counterpartyTxDeltas(blk).mutations(bigtable.chainId, counterpartiesTxColumn(blk.GetNumber()))
+	if err != nil {
+		return err
+	}
+	bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, muts.Keys...)
+	bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, muts.Muts...)
+	return nil
+}
+
+// transformCounterpartiesERC20 writes the counterparty deltas of the token transfers of a block, it is applied by TransformERC20
+func (bigtable *Bigtable) transformCounterpartiesERC20(blk *types.Eth1Block, bulkMetadataUpdates *types.BulkMutations) error {
+	deltas, err := counterpartyERC20Deltas(blk)
+	if err != nil {
+		return err
+	}
+	muts, err := deltas.mutations(bigtable.chainId, counterpartiesERC20Column(blk.GetNumber()))
+	if err != nil {
+		return err
+	}
+	bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, muts.Keys...)
+	bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, muts.Muts...)
+	return nil
+}
+
+// deleteCounterpartyDeltas deletes the pending counterparty deltas of an orphaned block
+func (bigtable *Bigtable) deleteCounterpartyDeltas(ctx context.Context, block *types.Eth1Block) error {
+	erc20Deltas, err := counterpartyERC20Deltas(block)
+	if err != nil {
+		return err
+	}
+	keys := map[string]bool{}
+	for key := range counterpartyTxDeltas(block) {
+		keys[key] = true
+	}
+	for key := range erc20Deltas {
+		keys[key] = true
+	}
+
+	muts := &types.BulkMutations{}
+	for key := range keys {
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteCellsInColumn(DEFAULT_FAMILY, counterpartiesTxColumn(block.GetNumber()))
+		mut.DeleteCellsInColumn(DEFAULT_FAMILY, counterpartiesERC20Column(block.GetNumber()))
+		muts.Keys = append(muts.Keys, fmt.Sprintf("%s:COUNTERPARTIES:%s", bigtable.chainId, key))
+		muts.Muts = append(muts.Muts, mut)
+	}
+	if len(muts.Keys) == 0 {
+		return nil
+	}
+	return bigtable.WriteBulk(ctx, muts, bigtable.tableMetadataUpdates)
+}
+
+// parseCounterpartyDeltas decodes the delta cells of a row of the metadata updates table, cells of other columns are ignored
+func parseCounterpartyDeltas(items []gcp_bigtable.ReadItem) ([]*counterpartyDelta, error) {
+	deltas := make([]*counterpartyDelta, 0, len(items))
+	for _, item := range items {
+		column := strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		if !strings.HasSuffix(column, ":TX") && !strings.HasSuffix(column, ":ERC20") {
+			continue
+		}
+		delta := &counterpartyDelta{}
+		err := json.Unmarshal(item.Value, delta)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding counterparty delta %v: %w", column, err)
+		}
+		deltas = append(deltas, delta)
+	}
+	return deltas, nil
+}
+
+// counterpartyRollup is a decoded rollup row, the hours are keyed by their column
+type counterpartyRollup struct {
+	folded counterpartiesFolded
+	hours  map[string]*counterpartyTransfers
+}
+
+func parseCounterpartyRollup(items []gcp_bigtable.ReadItem) (*counterpartyRollup, error) {
+	rollup := &counterpartyRollup{hours: map[string]*counterpartyTransfers{}}
+	for _, item := range items {
+		column := strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		if column == COUNTERPARTIES_FOLDED_COLUMN {
+			err := json.Unmarshal(item.Value, &rollup.folded)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the folded block of a counterparty rollup: %w", err)
+			}
+		} else if strings.HasPrefix(column, counterpartiesHourColumnPrefix) {
+			transfers := &counterpartyTransfers{}
+			err := json.Unmarshal(item.Value, transfers)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding counterparty rollup %v: %w", column, err)
+			}
+			rollup.hours[column] = transfers
+		}
+	}
+	return rollup, nil
+}
+
+// fold adds the deltas of blocks above the folded block to their hours, it returns the number of folded deltas and the columns
+// of the hours that changed
+func (rollup *counterpartyRollup) fold(deltas []*counterpartyDelta) (int, []string) {
+	highest := rollup.folded.Folded
+	count := 0
+	changed := map[string]bool{}
+	for _, delta := range deltas {
+		if delta.Block <= rollup.folded.Folded {
+			continue
+		}
+		if delta.Block > highest {
+			highest = delta.Block
+		}
+
+		column := counterpartiesHourColumn(time.Unix(delta.Time, 0))
+		if rollup.hours[column] == nil {
+			rollup.hours[column] = &counterpartyTransfers{}
+		}
+		rollup.hours[column].add(&delta.counterpartyTransfers)
+		changed[column] = true
+		count++
+	}
+	rollup.folded.Folded = highest
+
+	columns := make([]string, 0, len(changed))
+	for column := range changed {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return count, columns
+}
+
+// ProcessCounterpartyUpdates folds the pending counterparty deltas of blocks up to the finalized block into the rollups of up
+// to limit address pairs and returns the number of updated rollups. It must only be run by a single indexer.
+func (bigtable *Bigtable) ProcessCounterpartyUpdates(ctx context.Context, finalized uint64, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	// the deltas are ordered by descending block number, all columns starting at the finalized block belong to finalized blocks
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, reversedPaddedBlockNumber(finalized), ""),
+		gcp_bigtable.LatestNFilter(1),
+	)
+	keys := []string{}
+	pending := map[string][]gcp_bigtable.ReadItem{}
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(fmt.Sprintf("%s:COUNTERPARTIES:", bigtable.chainId)), func(row gcp_bigtable.Row) bool {
+		keys = append(keys, row.Key())
+		pending[row.Key()] = row[DEFAULT_FAMILY]
+		return true
+	}, gcp_bigtable.RowFilter(filter), gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	deltas := make(map[string][]*counterpartyDelta, len(keys))
+	firstHour, lastHour := "", ""
+	for _, key := range keys {
+		deltas[key], err = parseCounterpartyDeltas(pending[key])
+		if err != nil {
+			return 0, fmt.Errorf("error processing the counterparty updates of %v: %w", key, err)
+		}
+		for _, delta := range deltas[key] {
+			column := counterpartiesHourColumn(time.Unix(delta.Time, 0))
+			if firstHour == "" || column < firstHour {
+				firstHour = column
+			}
+			if column > lastHour {
+				lastHour = column
+			}
+		}
+	}
+
+	// only the hours the deltas fall into are read from the stored rollups, the end of a column range is exclusive
+	stored := make(map[string]*counterpartyRollup, len(keys))
+	var parseErr error
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		stored[row.Key()], parseErr = parseCounterpartyRollup(row[DEFAULT_FAMILY])
+		return parseErr == nil
+	}, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(
+		gcp_bigtable.InterleaveFilters(
+			gcp_bigtable.ColumnFilter(COUNTERPARTIES_FOLDED_COLUMN),
+			gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, firstHour, lastHour+"\x00"),
+		),
+		gcp_bigtable.LatestNFilter(1),
+	)))
+	if err != nil {
+		return 0, err
+	}
+	if parseErr != nil {
+		return 0, parseErr
+	}
+
+	mutsWrite := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+	for _, key := range keys {
+		rollup := stored[key]
+		if rollup == nil {
+			rollup = &counterpartyRollup{hours: map[string]*counterpartyTransfers{}}
+		}
+		folded, changed := rollup.fold(deltas[key])
+		if folded > 0 {
+			b, err := json.Marshal(&rollup.folded)
+			if err != nil {
+				return 0, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, COUNTERPARTIES_FOLDED_COLUMN, gcp_bigtable.Timestamp(0), b)
+			for _, column := range changed {
+				b, err := json.Marshal(rollup.hours[column])
+				if err != nil {
+					return 0, err
+				}
+				mut.Set(DEFAULT_FAMILY, column, gcp_bigtable.Timestamp(0), b)
+			}
+			mutsWrite.Keys = append(mutsWrite.Keys, key)
+			mutsWrite.Muts = append(mutsWrite.Muts, mut)
+		}
+
+		// only the folded columns are deleted, deltas of newer blocks written in the meantime are kept
+		mutDelete := gcp_bigtable.NewMutation()
+		for _, item := range pending[key] {
+			mutDelete.DeleteCellsInColumn(DEFAULT_FAMILY, strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":"))
+		}
+		mutsDelete.Keys = append(mutsDelete.Keys, key)
+		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+	}
+
+	// the rollups have to be written before the deltas are deleted, deltas that are left behind are skipped by the next run
+	if len(mutsWrite.Keys) > 0 {
+		err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableData)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
+	if err != nil {
+		return 0, err
+	}
+	return len(mutsWrite.Keys), nil
+}
+
+type counterpartyAggregate struct {
+	address []byte
+	counterpartyTransfers
+}
+
+func (a *counterpartyAggregate) etherVolume() *big.Int {
+	f, ok := a.Flows[counterpartiesEtherKey]
+	if !ok {
+		return new(big.Int)
+	}
+	return new(big.Int).Add(new(big.Int).SetBytes(f.In), new(big.Int).SetBytes(f.Out))
+}
+
+// GetAddressCounterparties returns the counterparties of the ether and ERC20 transfers of an address since the given time,
+// ordered by transferred ether volume. The hourly rollups of at most maxCounterparties counterparties are read, truncated is
+// set if the address has more counterparties within the window. Since is truncated to CounterpartiesPeriod.
+func (bigtable *Bigtable) GetAddressCounterparties(ctx context.Context, address []byte, since time.Time, maxCounterparties int64, limit int) ([]*types.AddressCounterparty, bool, error) {
+	since = since.Truncate(CounterpartiesPeriod)
+	cacheKey := fmt.Sprintf("%s:COUNTERPARTIES:%x:%d:%d:%d", bigtable.chainId, address, since.Unix(), maxCounterparties, limit)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, counterpartiesCacheDuration, new(addressCounterparties)); err == nil {
+		result := cached.(*addressCounterparties)
+		return result.Counterparties, result.Truncated, nil
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	keyPrefix := fmt.Sprintf("%s:COUNTERPARTIES:%x:", bigtable.chainId, address)
+	aggregates := make(map[string]*counterpartyAggregate)
+	aggregateFor := func(key string) (*counterpartyAggregate, error) {
+		a, ok := aggregates[key]
+		if !ok {
+			counterparty, err := hex.DecodeString(strings.TrimPrefix(key, keyPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("error decoding the counterparty of %v: %w", key, err)
+			}
+			a = &counterpartyAggregate{address: counterparty}
+			aggregates[key] = a
+		}
+		return a, nil
+	}
+
+	// rows without any transfers within the window are filtered out and do not count against maxCounterparties
+	rows := int64(0)
+	var parseErr error
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(keyPrefix), func(row gcp_bigtable.Row) bool {
+		rows++
+		if rows > maxCounterparties {
+			return false
+		}
+		var rollup *counterpartyRollup
+		rollup, parseErr = parseCounterpartyRollup(row[DEFAULT_FAMILY])
+		if parseErr != nil {
+			return false
+		}
+		var a *counterpartyAggregate
+		a, parseErr = aggregateFor(row.Key())
+		if parseErr != nil {
+			return false
+		}
+		for _, transfers := range rollup.hours {
+			a.add(transfers)
+		}
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(
+		gcp_bigtable.ColumnRangeFilter(DEFAULT_FAMILY, counterpartiesHourColumn(since), strings.TrimSuffix(counterpartiesHourColumnPrefix, ":")+";"),
+		gcp_bigtable.LatestNFilter(1),
+	)), gcp_bigtable.LimitRows(maxCounterparties+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading the counterparty rollups of address %x: %w", address, err)
+	}
+	if parseErr != nil {
+		return nil, false, parseErr
+	}
+	truncated := rows > maxCounterparties
+
+	// the deltas of blocks that have not been folded yet are added unless the rollup has been folded past them already
+	pending := map[string][]*counterpartyDelta{}
+	err = bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(keyPrefix), func(row gcp_bigtable.Row) bool {
+		pending[row.Key()], parseErr = parseCounterpartyDeltas(row[DEFAULT_FAMILY])
+		return parseErr == nil
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, false, fmt.Errorf("error reading the pending counterparty updates of address %x: %w", address, err)
+	}
+	if parseErr != nil {
+		return nil, false, parseErr
+	}
+	if len(pending) > 0 {
+		keys := make([]string, 0, len(pending))
+		for key := range pending {
+			keys = append(keys, key)
+		}
+		folded := make(map[string]uint64, len(keys))
+		err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+			var rollup *counterpartyRollup
+			rollup, parseErr = parseCounterpartyRollup(row[DEFAULT_FAMILY])
+			if parseErr != nil {
+				return false
+			}
+			folded[row.Key()] = rollup.folded.Folded
+			return true
+		}, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(gcp_bigtable.ColumnFilter(COUNTERPARTIES_FOLDED_COLUMN), gcp_bigtable.LatestNFilter(1))))
+		if err != nil {
+			return nil, false, fmt.Errorf("error reading the folded blocks of the counterparty rollups of address %x: %w", address, err)
+		}
+		if parseErr != nil {
+			return nil, false, parseErr
+		}
+
+		for key, deltas := range pending {
+			for _, delta := range deltas {
+				if delta.Block <= folded[key] || delta.Time < since.Unix() {
+					continue
+				}
+				a, err := aggregateFor(key)
+				if err != nil {
+					return nil, false, err
+				}
+				a.add(&delta.counterpartyTransfers)
+			}
+		}
+	}
+
+	sorted := make([]*counterpartyAggregate, 0, len(aggregates))
+	for _, a := range aggregates {
+		sorted = append(sorted, a)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		cmp := sorted[i].etherVolume().Cmp(sorted[j].etherVolume())
+		if cmp == 0 {
+			return sorted[i].Transfers > sorted[j].Transfers
+		}
+		return cmp > 0
+	})
+	if len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+
+	names := make(map[string]string, len(sorted))
+	tokens := make(map[string]*types.ERC20Metadata)
+	for _, a := range sorted {
+		names[string(a.address)] = ""
+		for token := range a.Flows {
+			if token == counterpartiesEtherKey {
+				continue
+			}
+			tokenBytes, err := hex.DecodeString(token)
+			if err != nil {
+				return nil, false, err
+			}
+			tokens[string(tokenBytes)] = nil
+		}
+	}
+	names, tokens, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, &tokens)
+	if err != nil {
+		return nil, false, err
+	}
+
+	counterparties := make([]*types.AddressCounterparty, 0, len(sorted))
+	for _, a := range sorted {
+		counterparty := &types.AddressCounterparty{
+			Address:   fmt.Sprintf("0x%x", a.address),
+			Name:      names[string(a.address)],
+			Transfers: a.Transfers,
+			Flows:     make([]*types.AddressTransferFlow, 0, len(a.Flows)),
+		}
+		for token, f := range a.Flows {
+			flow := &types.AddressTransferFlow{Symbol: "ETH"}
+			decimals := int32(18)
+			if token != counterpartiesEtherKey {
+				flow.Token = "0x" + token
+				flow.Symbol = ""
+				if metadata := tokens[string(common.FromHex(token))]; metadata != nil {
+					flow.Symbol = metadata.Symbol
+					decimals = int32(new(big.Int).SetBytes(metadata.Decimals).Int64())
+				}
+			}
+			flow.In = decimal.NewFromBigInt(new(big.Int).SetBytes(f.In), -decimals).String()
+			flow.Out = decimal.NewFromBigInt(new(big.Int).SetBytes(f.Out), -decimals).String()
+			counterparty.Flows = append(counterparty.Flows, flow)
+		}
+		sort.Slice(counterparty.Flows, func(i, j int) bool {
+			return counterparty.Flows[i].Token < counterparty.Flows[j].Token
+		})
+		counterparties = append(counterparties, counterparty)
+	}
+
+	err = cache.TieredCache.Set(cacheKey, &addressCounterparties{Counterparties: counterparties, Truncated: truncated}, counterpartiesCacheDuration)
+	if err != nil {
+		logger.Errorf("error caching counterparties of address %x: %v", address, err)
+	}
+	return counterparties, truncated, nil
+}
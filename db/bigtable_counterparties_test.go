@@ -0,0 +1,90 @@
+package db
+
+import (
+	"encoding/json"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestCounterpartyRollupFold(t *testing.T) {
+	alice := []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}
+	bob := []byte{0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02, 0x02}
+	token := []byte{0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03, 0x03}
+	blockTime := time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	block := func(number uint64, txs ...*types.Eth1Transaction) *types.Eth1Block {
+		return &types.Eth1Block{Number: number, Time: timestamppb.New(blockTime.Add(time.Duration(number) * time.Minute * 10)), Transactions: txs}
+	}
+	transfer := func(from, to []byte, value int64, errorMsg string) *types.Eth1Transaction {
+		return &types.Eth1Transaction{From: from, To: to, Value: big.NewInt(value).Bytes(), ContractAddress: ZERO_ADDRESS, ErrorMsg: errorMsg}
+	}
+	tokenTransfer := func(from, to []byte, value int64) *types.Eth1Transaction {
+		return &types.Eth1Transaction{From: from, To: token, ContractAddress: ZERO_ADDRESS, Logs: []*types.Eth1Log{{
+			Address: token,
+			Topics:  [][]byte{erc20.TransferTopic, common.BytesToHash(from).Bytes(), common.BytesToHash(to).Bytes()},
+			Data:    common.BigToHash(big.NewInt(value)).Bytes(),
+		}}}
+	}
+	key := fmt.Sprintf("%x:%x", alice, bob)
+
+	first := counterpartyTxDeltas(block(1, transfer(alice, bob, 5, ""), transfer(alice, bob, 7, "out of gas"), transfer(alice, alice, 1, "")))
+	second := counterpartyTxDeltas(block(2, transfer(bob, alice, 3, "")))
+	tokens, err := counterpartyERC20Deltas(block(9, tokenTransfer(alice, bob, 11)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 2 {
+		t.Fatalf("expected the deltas of both sides of the transfers and no self transfer, got %v", len(first))
+	}
+
+	// the deltas are stored as json, the embedded transfers have to survive the round trip
+	b, err := json.Marshal(first[key])
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &counterpartyDelta{}
+	err = json.Unmarshal(b, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Block != 1 || decoded.Transfers != 2 || new(big.Int).SetBytes(decoded.Flows[counterpartiesEtherKey].Out).Int64() != 5 {
+		t.Errorf("unexpected decoded delta %+v", decoded)
+	}
+
+	rollup := &counterpartyRollup{hours: map[string]*counterpartyTransfers{}}
+	folded, changed := rollup.fold([]*counterpartyDelta{tokens[key], second[key], decoded})
+	if folded != 3 || rollup.folded.Folded != 9 {
+		t.Errorf("expected 3 deltas to be folded up to block 9, got %v up to %v", folded, rollup.folded.Folded)
+	}
+	if len(changed) != 2 || changed[0] != "HOUR:2023-07-01T12" || changed[1] != "HOUR:2023-07-01T13" {
+		t.Errorf("unexpected changed hours %v", changed)
+	}
+	// deltas of blocks that have been folded before are skipped
+	if folded, _ := rollup.fold([]*counterpartyDelta{second[key]}); folded != 0 {
+		t.Errorf("expected the delta of block 2 to be skipped, got %v folded deltas", folded)
+	}
+
+	hour := rollup.hours["HOUR:2023-07-01T12"]
+	if hour.Transfers != 3 {
+		t.Errorf("expected 3 transfers, got %v", hour.Transfers)
+	}
+	// the failed transfer is counted but does not transfer any value
+	ether := hour.Flows[counterpartiesEtherKey]
+	if out, in := new(big.Int).SetBytes(ether.Out), new(big.Int).SetBytes(ether.In); out.Int64() != 5 || in.Int64() != 3 {
+		t.Errorf("expected 5 wei sent and 3 wei received, got %v and %v", out, in)
+	}
+	tokenFlow := rollup.hours["HOUR:2023-07-01T13"].Flows[fmt.Sprintf("%x", token)]
+	if tokenFlow == nil || new(big.Int).SetBytes(tokenFlow.Out).Int64() != 11 {
+		t.Errorf("expected 11 tokens sent, got %+v", tokenFlow)
+	}
+	if received := tokens[fmt.Sprintf("%x:%x", bob, alice)]; received == nil || new(big.Int).SetBytes(received.Flows[fmt.Sprintf("%x", token)].In).Int64() != 11 {
+		t.Errorf("expected the recipient to receive 11 tokens, got %+v", received)
+	}
+}
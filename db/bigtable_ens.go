@@ -0,0 +1,262 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENS records are derived from the events of the ENS registry and of the resolvers. A resolver record is only valid while
+// the registry points the node to the resolver that emitted it, the records are therefore stored per resolver.
+//
+// TransformEns writes the records to the metadata updates table, ProcessEnsUpdates moves them to the metadata table:
+// Row:    <chainID>:ENS:<node>
+// Family: ens (f in the metadata updates table)
+// Column: resolver | addr:<resolver> | name:<resolver>
+// Cell:   resolver address | address | name
+//
+// The timestamp of a cell is derived from the block number and transaction index of the event so that the latest record wins.
+const ENS_FAMILY = "ens"
+
+const (
+	ENS_COLUMN_RESOLVER = "resolver"
+	ENS_COLUMN_ADDR     = "addr"
+	ENS_COLUMN_NAME     = "name"
+)
+
+var (
+	// NewResolver(bytes32 indexed node, address resolver)
+	ensNewResolverTopic = crypto.Keccak256([]byte("NewResolver(bytes32,address)"))
+	// AddrChanged(bytes32 indexed node, address a)
+	ensAddrChangedTopic = crypto.Keccak256([]byte("AddrChanged(bytes32,address)"))
+	// NameChanged(bytes32 indexed node, string name)
+	ensNameChangedTopic = crypto.Keccak256([]byte("NameChanged(bytes32,string)"))
+)
+
+var ensNameChangedArgs = abi.Arguments{{Type: mustNewAbiType("string")}}
+
+func mustNewAbiType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		logger.Fatalf("error creating abi type %v: %v", t, err)
+	}
+	return typ
+}
+
+// ensRecordTimestamp orders the records of a node by the position of their event in the chain, bigtable timestamps have a millisecond granularity
+func ensRecordTimestamp(block uint64, txIdx int) gcp_bigtable.Timestamp {
+	return gcp_bigtable.Timestamp((block*10000 + uint64(txIdx)) * 1000)
+}
+
+// TransformEns extracts the resolver assignments of the ENS registry as well as the address and name records of the resolvers
+func (bigtable *Bigtable) TransformEns(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		for _, log := range tx.GetLogs() {
+			topics := log.GetTopics()
+			if log.GetRemoved() || len(topics) != 2 {
+				continue
+			}
+
+			var column string
+			var value []byte
+			switch {
+			case bytes.Equal(topics[0], ensNewResolverTopic) && bytes.Equal(log.GetAddress(), utils.EnsRegistryAddress.Bytes()):
+				if len(log.GetData()) != 32 {
+					continue
+				}
+				column = ENS_COLUMN_RESOLVER
+				value = common.BytesToAddress(log.GetData()).Bytes()
+			case bytes.Equal(topics[0], ensAddrChangedTopic):
+				if len(log.GetData()) != 32 {
+					continue
+				}
+				column = fmt.Sprintf("%s:%x", ENS_COLUMN_ADDR, log.GetAddress())
+				value = common.BytesToAddress(log.GetData()).Bytes()
+			case bytes.Equal(topics[0], ensNameChangedTopic):
+				unpacked, err := ensNameChangedArgs.Unpack(log.GetData())
+				if err != nil || len(unpacked) != 1 {
+					logger.Warnf("skipping malformed ens NameChanged event in tx 0x%x: %v", tx.GetHash(), err)
+					continue
+				}
+				name, ok := unpacked[0].(string)
+				if !ok {
+					continue
+				}
+				column = fmt.Sprintf("%s:%x", ENS_COLUMN_NAME, log.GetAddress())
+				value = []byte(name)
+			default:
+				continue
+			}
+
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, column, ensRecordTimestamp(blk.GetNumber(), i), value)
+
+			bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, fmt.Sprintf("%s:ENS:%x", bigtable.chainId, topics[1]))
+			bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, mut)
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// ProcessEnsUpdates moves up to limit pending ens records from the metadata updates table to the metadata table and returns the number of nodes moved
+func (bigtable *Bigtable) ProcessEnsUpdates(ctx context.Context, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	mutsWrite := &types.BulkMutations{}
+	mutsDelete := &types.BulkMutations{}
+
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(fmt.Sprintf("%s:ENS:", bigtable.chainId)), func(row gcp_bigtable.Row) bool {
+		mut := gcp_bigtable.NewMutation()
+		for _, item := range row[DEFAULT_FAMILY] {
+			mut.Set(ENS_FAMILY, strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":"), item.Timestamp, item.Value)
+		}
+		mutsWrite.Keys = append(mutsWrite.Keys, row.Key())
+		mutsWrite.Muts = append(mutsWrite.Muts, mut)
+
+		mutDelete := gcp_bigtable.NewMutation()
+		mutDelete.DeleteRow()
+		mutsDelete.Keys = append(mutsDelete.Keys, row.Key())
+		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+		return true
+	}, gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+	if len(mutsWrite.Keys) == 0 {
+		return 0, nil
+	}
+
+	err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableMetadata)
+	if err != nil {
+		return 0, err
+	}
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
+	if err != nil {
+		return 0, err
+	}
+	return len(mutsWrite.Keys), nil
+}
+
+// ensRecord is the current record of a node according to the resolver the registry points it to
+type ensRecord struct {
+	resolver []byte
+	addr     []byte
+	name     string
+}
+
+// getEnsRecords reads the current records of a list of ens nodes, nodes without a resolver are missing from the result
+func (bigtable *Bigtable) getEnsRecords(ctx context.Context, nodes []common.Hash) (map[common.Hash]*ensRecord, error) {
+	keys := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		keys = append(keys, fmt.Sprintf("%s:ENS:%x", bigtable.chainId, node))
+	}
+
+	records := make(map[common.Hash]*ensRecord, len(nodes))
+	keyPrefix := fmt.Sprintf("%s:ENS:", bigtable.chainId)
+	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(ENS_FAMILY), gcp_bigtable.LatestNFilter(1))
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		columns := make(map[string][]byte, len(row[ENS_FAMILY]))
+		for _, item := range row[ENS_FAMILY] {
+			columns[strings.TrimPrefix(item.Column, ENS_FAMILY+":")] = item.Value
+		}
+		resolver := columns[ENS_COLUMN_RESOLVER]
+		if len(resolver) != 20 || bytes.Equal(resolver, ZERO_ADDRESS) {
+			return true
+		}
+		records[common.HexToHash(strings.TrimPrefix(row.Key(), keyPrefix))] = &ensRecord{
+			resolver: resolver,
+			addr:     columns[fmt.Sprintf("%s:%x", ENS_COLUMN_ADDR, resolver)],
+			name:     string(columns[fmt.Sprintf("%s:%x", ENS_COLUMN_NAME, resolver)]),
+		}
+		return true
+	}, gcp_bigtable.RowFilter(filter))
+	return records, err
+}
+
+// GetEnsAddress returns the address an ens name resolves to according to the indexed records, nil is returned if the name does not resolve
+func (bigtable *Bigtable) GetEnsAddress(ctx context.Context, name string) ([]byte, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	node := utils.EnsNamehash(name)
+	records, err := bigtable.getEnsRecords(ctx, []common.Hash{node})
+	if err != nil {
+		return nil, err
+	}
+	record := records[node]
+	if record == nil || len(record.addr) != 20 || bytes.Equal(record.addr, ZERO_ADDRESS) {
+		return nil, nil
+	}
+	return record.addr, nil
+}
+
+// GetEnsNames looks up the primary ens names of the addresses (keyed by the address bytes) that do not have a name yet.
+// A primary name is the name of the reverse record of an address if that name resolves back to the address.
+func (bigtable *Bigtable) GetEnsNames(ctx context.Context, addresses map[string]string) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	reverseNodes := make([]common.Hash, 0, len(addresses))
+	addressOfNode := make(map[common.Hash]string, len(addresses))
+	for address, name := range addresses {
+		if name != "" || len(address) != 20 {
+			continue
+		}
+		node := utils.EnsNamehash(utils.EnsReverseName([]byte(address)))
+		reverseNodes = append(reverseNodes, node)
+		addressOfNode[node] = address
+	}
+	if len(reverseNodes) == 0 {
+		return nil
+	}
+
+	reverseRecords, err := bigtable.getEnsRecords(ctx, reverseNodes)
+	if err != nil {
+		return err
+	}
+
+	claims := make(map[common.Hash]string, len(reverseRecords))
+	forwardNodes := make([]common.Hash, 0, len(reverseRecords))
+	for reverseNode, record := range reverseRecords {
+		if !utils.IsEnsName(record.name) {
+			continue
+		}
+		node := utils.EnsNamehash(record.name)
+		claims[reverseNode] = record.name
+		forwardNodes = append(forwardNodes, node)
+	}
+	if len(forwardNodes) == 0 {
+		return nil
+	}
+
+	forwardRecords, err := bigtable.getEnsRecords(ctx, forwardNodes)
+	if err != nil {
+		return err
+	}
+	for reverseNode, name := range claims {
+		address := addressOfNode[reverseNode]
+		record := forwardRecords[utils.EnsNamehash(name)]
+		if record != nil && bytes.Equal(record.addr, []byte(address)) {
+			addresses[address] = strings.ToLower(name)
+		}
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"eth2-exporter/erc1155"
 	"eth2-exporter/erc20"
 	"eth2-exporter/erc721"
+	"eth2-exporter/metrics"
 	"eth2-exporter/rpc"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -39,11 +40,13 @@ import (
 )
 
 var ErrBlockNotFound = errors.New("block not found")
+var ErrBlockKeysNotFound = errors.New("block keys not found")
 
 type IndexFilter string
 
 const (
 	FILTER_TIME           IndexFilter = "TIME"
+	FILTER_BLOCK          IndexFilter = "BLOCK"
 	FILTER_TO             IndexFilter = "TO"
 	FILTER_FROM           IndexFilter = "FROM"
 	FILTER_TOKEN_RECEIVED IndexFilter = "TOKEN_RECEIVED"
@@ -69,11 +72,14 @@ const (
 )
 
 const (
-	ACCOUNT_COLUMN_NAME = "NAME"
-	ACCOUNT_IS_CONTRACT = "ISCONTRACT"
+	ACCOUNT_COLUMN_NAME    = "NAME"
+	ACCOUNT_IS_CONTRACT    = "ISCONTRACT"
+	ACCOUNT_COLUMN_ADDRESS = "ADDRESS"
 
-	CONTRACT_NAME = "CONTRACTNAME"
-	CONTRACT_ABI  = "ABI"
+	CONTRACT_NAME         = "CONTRACTNAME"
+	CONTRACT_ABI          = "ABI"
+	CONTRACT_VERIFICATION = "VERIFICATION"
+	CONTRACT_DESTRUCTION  = "DESTRUCTION"
 
 	ERC20_COLUMN_DECIMALS    = "DECIMALS"
 	ERC20_COLUMN_TOTALSUPPLY = "TOTALSUPPLY"
@@ -110,8 +116,8 @@ func (bigtable *Bigtable) GetMetadatTable() *gcp_bigtable.Table {
 	return bigtable.tableMetadata
 }
 
-func (bigtable *Bigtable) SaveBlock(block *types.Eth1Block) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+func (bigtable *Bigtable) SaveBlock(ctx context.Context, block *types.Eth1Block) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
 	encodedBc, err := proto.Marshal(block)
@@ -124,7 +130,7 @@ func (bigtable *Bigtable) SaveBlock(block *types.Eth1Block) error {
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(DEFAULT_FAMILY_BLOCKS, "data", ts, encodedBc)
 
-	err = bigtable.tableBlocks.Apply(ctx, fmt.Sprintf("%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.Number)), mut)
+	err = bigtable.apply(ctx, bigtable.tableBlocks, fmt.Sprintf("%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.Number)), mut)
 
 	if err != nil {
 		return err
@@ -132,9 +138,9 @@ func (bigtable *Bigtable) SaveBlock(block *types.Eth1Block) error {
 	return nil
 }
 
-func (bigtable *Bigtable) SaveBlocks(block *types.Eth1Block) error {
+func (bigtable *Bigtable) SaveBlocks(ctx context.Context, block *types.Eth1Block) error {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
 	encodedBc, err := proto.Marshal(block)
@@ -147,7 +153,7 @@ func (bigtable *Bigtable) SaveBlocks(block *types.Eth1Block) error {
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(DEFAULT_FAMILY, "data", ts, encodedBc)
 
-	err = bigtable.tableBlocks.Apply(ctx, fmt.Sprintf("%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.Number)), mut)
+	err = bigtable.apply(ctx, bigtable.tableBlocks, fmt.Sprintf("%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.Number)), mut)
 
 	if err != nil {
 		return err
@@ -155,21 +161,28 @@ func (bigtable *Bigtable) SaveBlocks(block *types.Eth1Block) error {
 	return nil
 }
 
-func (bigtable *Bigtable) GetBlockFromBlocksTable(number uint64) (*types.Eth1Block, error) {
+func (bigtable *Bigtable) GetBlockFromBlocksTable(ctx context.Context, number uint64) (*types.Eth1Block, error) {
+	bc, err := bigtable.readBlock(ctx, number)
+	if err == ErrBlockNotFound {
+		logger.Warnf("block %v not found in block table", number)
+	}
+	return bc, err
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+// readBlock reads a block from the blocks table, ErrBlockNotFound is returned if the height has not been saved yet
+func (bigtable *Bigtable) readBlock(ctx context.Context, number uint64) (*types.Eth1Block, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
 	paddedNumber := reversedPaddedBlockNumber(number)
 
-	row, err := bigtable.tableBlocks.ReadRow(ctx, fmt.Sprintf("%s:%s", bigtable.chainId, paddedNumber))
+	row, err := bigtable.readRow(ctx, bigtable.tableBlocks, fmt.Sprintf("%s:%s", bigtable.chainId, paddedNumber))
 
 	if err != nil {
 		return nil, err
 	}
 
 	if len(row[DEFAULT_FAMILY_BLOCKS]) == 0 { // block not found
-		logger.Warnf("block %v not found in block table", number)
 		return nil, ErrBlockNotFound
 	}
 
@@ -183,59 +196,39 @@ func (bigtable *Bigtable) GetBlockFromBlocksTable(number uint64) (*types.Eth1Blo
 	return bc, nil
 }
 
-func (bigtable *Bigtable) CheckForGapsInBlocksTable(lookback int) (gapFound bool, start int, end int, err error) {
+// BlockGap is an inclusive range of missing blocks
+type BlockGap struct {
+	Start uint64
+	End   uint64
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+// CheckForGapsInBlocksTable returns the ranges of blocks missing in the blocks table within the lookback latest rows, ordered descending
+func (bigtable *Bigtable) CheckForGapsInBlocksTable(ctx context.Context, lookback int) ([]BlockGap, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
 	defer cancel()
 
 	prefix := bigtable.chainId + ":"
-	previous := 0
-	i := 0
-	err = bigtable.tableBlocks.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
-		c, err := strconv.Atoi(strings.Replace(r.Key(), prefix, "", 1))
-
-		if err != nil {
-			logger.Errorf("error parsing block number from key %v: %v", r.Key(), err)
-			return false
-		}
-		c = max_block_number - c
-
-		if c%10000 == 0 {
-			logger.Infof("scanning, currently at block %v", c)
-		}
-
-		if previous != 0 && previous != c+1 {
-			gapFound = true
-			start = c
-			end = previous
-			logger.Fatalf("found gap between block %v and block %v in blocks table", previous, c)
-			return false
-		}
-		previous = c
-
-		i++
-
-		return i < lookback
-	}, gcp_bigtable.RowFilter(gcp_bigtable.StripValueFilter()))
-
-	return gapFound, start, end, err
+	gaps, err := findBlockGaps(ctx, bigtable.tableBlocks, prefix, lookback, gcp_bigtable.RowFilter(gcp_bigtable.StripValueFilter()))
+	for _, gap := range gaps {
+		logger.Warnf("found gap of blocks %v to %v in blocks table", gap.Start, gap.End)
+	}
+	return gaps, err
 }
 
-func (bigtable *Bigtable) GetLastBlockInBlocksTable() (int, error) {
+func (bigtable *Bigtable) GetLastBlockInBlocksTable(ctx context.Context) (int, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	prefix := bigtable.chainId + ":"
 	lastBlock := 0
-	err := bigtable.tableBlocks.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
-		c, err := strconv.Atoi(strings.Replace(r.Key(), prefix, "", 1))
-
+	err := bigtable.readRows(ctx, bigtable.tableBlocks, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
+		block, err := blockFromPaddedBlockNumber(strings.TrimPrefix(r.Key(), prefix))
 		if err != nil {
 			logger.Errorf("error parsing block number from key %v: %v", r.Key(), err)
 			return false
 		}
-		c = max_block_number - c
+		c := int(block)
 
 		if c%10000 == 0 {
 			logger.Infof("scanning, currently at block %v", c)
@@ -252,59 +245,67 @@ func (bigtable *Bigtable) GetLastBlockInBlocksTable() (int, error) {
 	return lastBlock, nil
 }
 
-func (bigtable *Bigtable) CheckForGapsInDataTable(lookback int) error {
-
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+// CheckForGapsInDataTable returns the ranges of blocks missing in the data table within the lookback latest block rows, ordered descending
+func (bigtable *Bigtable) CheckForGapsInDataTable(ctx context.Context, lookback int) ([]BlockGap, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute*5)
 	defer cancel()
 
 	prefix := bigtable.chainId + ":B:"
-	previous := 0
-	i := 0
-	err := bigtable.tableData.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
-		c, err := strconv.Atoi(strings.Replace(r.Key(), prefix, "", 1))
+	gaps, err := findBlockGaps(ctx, bigtable.tableData, prefix, lookback, skipTombstones(gcp_bigtable.StripValueFilter()))
+	for _, gap := range gaps {
+		logger.Warnf("found gap of blocks %v to %v in data table", gap.Start, gap.End)
+	}
+	return gaps, err
+}
 
+// findBlockGaps scans up to lookback rows of reverse padded block numbers following prefix and collects the ranges of missing block numbers
+func findBlockGaps(ctx context.Context, table *gcp_bigtable.Table, prefix string, lookback int, opts ...gcp_bigtable.ReadOption) ([]BlockGap, error) {
+	gaps := []BlockGap{}
+	previous := uint64(0)
+	i := 0
+	var parseErr error
+	ctx, call := startBigtableCall(ctx, "ReadRows")
+	err := table.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
+		block, err := blockFromPaddedBlockNumber(strings.TrimPrefix(r.Key(), prefix))
 		if err != nil {
-			logger.Errorf("error parsing block number from key %v: %v", r.Key(), err)
+			parseErr = fmt.Errorf("error parsing block number from key %v: %w", r.Key(), err)
 			return false
 		}
-		c = max_block_number - c
 
-		if c%10000 == 0 {
-			logger.Infof("scanning, currently at block %v", c)
+		if block%10000 == 0 {
+			logger.Infof("scanning, currently at block %v", block)
 		}
 
-		if previous != 0 && previous != c+1 {
-			logger.Fatalf("found gap between block %v and block %v in data table", previous, c)
+		if i > 0 && previous > block+1 {
+			gaps = append(gaps, BlockGap{Start: block + 1, End: previous - 1})
 		}
-		previous = c
+		previous = block
 
 		i++
 
 		return i < lookback
-	}, gcp_bigtable.RowFilter(gcp_bigtable.StripValueFilter()))
-
+	}, opts...)
+	call.end(i, err)
 	if err != nil {
-		return err
+		return gaps, err
 	}
-
-	return nil
+	return gaps, parseErr
 }
 
-func (bigtable *Bigtable) GetLastBlockInDataTable() (int, error) {
+func (bigtable *Bigtable) GetLastBlockInDataTable(ctx context.Context) (int, error) {
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
 	defer cancel()
 
 	prefix := bigtable.chainId + ":B:"
 	lastBlock := 0
-	err := bigtable.tableData.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
-		c, err := strconv.Atoi(strings.Replace(r.Key(), prefix, "", 1))
-
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(prefix), func(r gcp_bigtable.Row) bool {
+		block, err := blockFromPaddedBlockNumber(strings.TrimPrefix(r.Key(), prefix))
 		if err != nil {
 			logger.Errorf("error parsing block number from key %v: %v", r.Key(), err)
 			return false
 		}
-		c = max_block_number - c
+		c := int(block)
 
 		if c%10000 == 0 {
 			logger.Infof("scanning, currently at block %v", c)
@@ -312,7 +313,7 @@ func (bigtable *Bigtable) GetLastBlockInDataTable() (int, error) {
 
 		lastBlock = c
 		return c == 0
-	}, gcp_bigtable.RowFilter(gcp_bigtable.StripValueFilter()))
+	}, skipTombstones(gcp_bigtable.StripValueFilter()))
 
 	if err != nil {
 		return 0, err
@@ -321,8 +322,8 @@ func (bigtable *Bigtable) GetLastBlockInDataTable() (int, error) {
 	return lastBlock, nil
 }
 
-func (bigtable *Bigtable) GetMostRecentBlockFromDataTable() (*types.Eth1BlockIndexed, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetMostRecentBlockFromDataTable(ctx context.Context) (*types.Eth1BlockIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	prefix := fmt.Sprintf("%s:B:", bigtable.chainId)
@@ -333,13 +334,12 @@ func (bigtable *Bigtable) GetMostRecentBlockFromDataTable() (*types.Eth1BlockInd
 	block := types.Eth1BlockIndexed{}
 
 	rowHandler := func(row gcp_bigtable.Row) bool {
-		c, err := strconv.Atoi(strings.Replace(row.Key(), prefix, "", 1))
+		number, err := blockFromPaddedBlockNumber(strings.TrimPrefix(row.Key(), prefix))
 		if err != nil {
 			logger.Errorf("error parsing block number from key %v: %v", row.Key(), err)
 			return false
 		}
-
-		c = max_block_number - c
+		c := int(number)
 
 		err = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, &block)
 		if err != nil {
@@ -349,7 +349,7 @@ func (bigtable *Bigtable) GetMostRecentBlockFromDataTable() (*types.Eth1BlockInd
 		return c == 0
 	}
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, rowHandler, rowFilter)
+	err := bigtable.readRows(ctx, bigtable.tableData, rowRange, rowHandler, rowFilter)
 	if err != nil {
 		return nil, err
 	}
@@ -375,8 +375,8 @@ func getBlockHandler(blocks *[]*types.Eth1BlockIndexed) func(gcp_bigtable.Row) b
 }
 
 // GetFullBlockDescending gets blocks starting at block start
-func (bigtable *Bigtable) GetFullBlockDescending(start, limit uint64) ([]*types.Eth1Block, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*60))
+func (bigtable *Bigtable) GetFullBlockDescending(ctx context.Context, start, limit uint64) ([]*types.Eth1Block, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*60))
 	defer cancel()
 
 	if start < 1 || limit < 1 || limit > start {
@@ -411,7 +411,7 @@ func (bigtable *Bigtable) GetFullBlockDescending(start, limit uint64) ([]*types.
 	}
 
 	// startTime := time.Now()
-	err := bigtable.tableBlocks.ReadRows(ctx, rowRange, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
+	err := bigtable.readRows(ctx, bigtable.tableBlocks, rowRange, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
 	if err != nil {
 		return nil, err
 	}
@@ -421,8 +421,8 @@ func (bigtable *Bigtable) GetFullBlockDescending(start, limit uint64) ([]*types.
 }
 
 // GetFullBlockDescending gets blocks starting at block start
-func (bigtable *Bigtable) GetFullBlocksDescending(stream chan<- *types.Eth1Block, high, low uint64) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*180))
+func (bigtable *Bigtable) GetFullBlocksDescending(ctx context.Context, stream chan<- *types.Eth1Block, high, low uint64) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*180))
 	defer cancel()
 
 	if high < 1 || low < 1 || high < low {
@@ -455,7 +455,7 @@ func (bigtable *Bigtable) GetFullBlocksDescending(stream chan<- *types.Eth1Block
 	}
 
 	// startTime := time.Now()
-	err := bigtable.tableBlocks.ReadRows(ctx, rowRange, rowHandler, rowFilter)
+	err := bigtable.readRows(ctx, bigtable.tableBlocks, rowRange, rowHandler, rowFilter)
 	if err != nil {
 		return err
 	}
@@ -464,13 +464,13 @@ func (bigtable *Bigtable) GetFullBlocksDescending(stream chan<- *types.Eth1Block
 	return nil
 }
 
-func (bigtable *Bigtable) GetBlocksIndexedMultiple(blockNumbers []uint64, limit uint64) ([]*types.Eth1BlockIndexed, error) {
+func (bigtable *Bigtable) GetBlocksIndexedMultiple(ctx context.Context, blockNumbers []uint64, limit uint64) ([]*types.Eth1BlockIndexed, error) {
 	rowList := gcp_bigtable.RowList{}
 	for _, block := range blockNumbers {
 		rowList = append(rowList, fmt.Sprintf("%s:B:%s", bigtable.chainId, reversedPaddedBlockNumber(block)))
 	}
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	rowFilter := gcp_bigtable.RowFilter(gcp_bigtable.ColumnFilter("d"))
@@ -480,7 +480,7 @@ func (bigtable *Bigtable) GetBlocksIndexedMultiple(blockNumbers []uint64, limit
 	rowHandler := getBlockHandler(&blocks)
 
 	// startTime := time.Now()
-	err := bigtable.tableData.ReadRows(ctx, rowList, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
+	err := bigtable.readRows(ctx, bigtable.tableData, rowList, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
 	if err != nil {
 		return nil, err
 	}
@@ -490,7 +490,7 @@ func (bigtable *Bigtable) GetBlocksIndexedMultiple(blockNumbers []uint64, limit
 }
 
 // GetBlocksDescending gets blocks starting at block start
-func (bigtable *Bigtable) GetBlocksDescending(start, limit uint64) ([]*types.Eth1BlockIndexed, error) {
+func (bigtable *Bigtable) GetBlocksDescending(ctx context.Context, start, limit uint64) ([]*types.Eth1BlockIndexed, error) {
 	if start < 1 || limit < 1 || limit > start {
 		return nil, fmt.Errorf("invalid block range provided (start: %v, limit: %v)", start, limit)
 	}
@@ -500,7 +500,7 @@ func (bigtable *Bigtable) GetBlocksDescending(start, limit uint64) ([]*types.Eth
 
 	// logger.Info(start, start-limit)
 	// logger.Info(startPadded, " ", endPadded)
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	startKey := fmt.Sprintf("%s:B:%s", bigtable.chainId, startPadded)
@@ -519,7 +519,7 @@ func (bigtable *Bigtable) GetBlocksDescending(start, limit uint64) ([]*types.Eth
 	rowHandler := getBlockHandler(&blocks)
 
 	// startTime := time.Now()
-	err := bigtable.tableData.ReadRows(ctx, rowRange, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
+	err := bigtable.readRows(ctx, bigtable.tableData, rowRange, rowHandler, rowFilter, gcp_bigtable.LimitRows(int64(limit)))
 	if err != nil {
 		return nil, err
 	}
@@ -532,6 +532,28 @@ func reversedPaddedBlockNumber(blockNumber uint64) string {
 	return fmt.Sprintf("%09d", max_block_number-blockNumber)
 }
 
+// blockFromPaddedBlockNumber is the inverse of reversedPaddedBlockNumber, anything but a 9 digit reversed block number is rejected
+func blockFromPaddedBlockNumber(padded string) (uint64, error) {
+	if len(padded) != 9 {
+		return 0, fmt.Errorf("invalid padded block number %q, expected 9 digits", padded)
+	}
+	for _, c := range padded {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid padded block number %q, expected 9 digits", padded)
+		}
+	}
+	reversed, err := strconv.ParseUint(padded, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid padded block number %q: %w", padded, err)
+	}
+	return max_block_number - reversed, nil
+}
+
+// ReversedPaddedBlockNumber returns the block number in the format used by the BLOCK index rows, so that newer blocks sort first
+func ReversedPaddedBlockNumber(blockNumber uint64) string {
+	return reversedPaddedBlockNumber(blockNumber)
+}
+
 func reversePaddedBigtableTimestamp(timestamp *timestamppb.Timestamp) string {
 	if timestamp == nil {
 		log.Fatalf("unknown timestamp: %v", timestamp)
@@ -552,76 +574,23 @@ func TimestampToBigtableTimeDesc(ts time.Time) string {
 	return fmt.Sprintf("%04d%02d%02d%02d%02d%02d", 9999-ts.Year(), 12-ts.Month(), 31-ts.Day(), 23-ts.Hour(), 59-ts.Minute(), 59-ts.Second())
 }
 
-func (bigtable *Bigtable) WriteBulk(mutations *types.BulkMutations, table *gcp_bigtable.Table) error {
-	ctx, done := context.WithTimeout(context.Background(), time.Minute*5)
-	defer done()
-
-	length := 10000
-	numMutations := len(mutations.Muts)
-	numKeys := len(mutations.Keys)
-	iterations := numKeys / length
-
-	if numKeys != numMutations {
-		return fmt.Errorf("error expected same number of keys as mutations keys: %v mutations: %v", numKeys, numMutations)
-	}
-
-	for offset := 0; offset < iterations; offset++ {
-		start := offset * length
-		end := offset*length + length
-		// logger.Infof("writing from: %v to %v arr len:  %v", start, end, len(mutations.Keys))
-
-		// startTime := time.Now()
-		errs, err := table.ApplyBulk(ctx, mutations.Keys[start:end], mutations.Muts[start:end])
-		for _, e := range errs {
-			if e != nil {
-				return err
-			}
-		}
-		// logrus.Infof("wrote from %v to %v rows to bigtable in %.1f s", start, end, time.Since(startTime).Seconds())
-		if err != nil {
-			return err
-		}
-	}
-
-	if (iterations * length) < numKeys {
-		start := iterations * length
-		// startTime := time.Now()
-		errs, err := table.ApplyBulk(ctx, mutations.Keys[start:], mutations.Muts[start:])
-		if err != nil {
-			return err
-		}
-		for _, e := range errs {
-			if e != nil {
-				return e
-			}
-		}
-		// logrus.Infof("wrote from %v to %v rows to bigtable in %.1fs", start, numKeys, time.Since(startTime).Seconds())
-		if err != nil {
-			return err
-		}
-		return nil
+// DeleteRowsWithPrefix deletes the rows of the data table starting with prefix, the prefix has to start with the chain id of
+// the instance so that the rows of other chains sharing the table are never touched
+func (bigtable *Bigtable) DeleteRowsWithPrefix(ctx context.Context, prefix string) {
+	chainPrefix := bigtable.chainId + ":"
+	if !strings.HasPrefix(prefix, chainPrefix) {
+		logger.WithField("prefix", prefix).Errorf("refusing to delete rows outside of chain %v", bigtable.chainId)
+		return
 	}
 
-	return nil
-
-	// if err := g.Wait(); err == nil {
-	// 	// logrus.Info("Successfully wrote all mutations")
-	// 	return nil
-	// } else {
-	// 	return err
-	// }
-}
-
-func (bigtable *Bigtable) DeleteRowsWithPrefix(prefix string) {
-
 	for {
-		ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+		ctx, done := context.WithTimeout(ctx, time.Second*30)
 		defer done()
 
-		rr := gcp_bigtable.InfiniteRange(prefix)
+		rr := gcp_bigtable.PrefixRange(prefix)
 
 		rowsToDelete := make([]string, 0, 10000)
-		err := bigtable.tableData.ReadRows(ctx, rr, func(r gcp_bigtable.Row) bool {
+		err := bigtable.readRows(ctx, bigtable.tableData, rr, func(r gcp_bigtable.Row) bool {
 			rowsToDelete = append(rowsToDelete, r.Key())
 			return true
 		})
@@ -644,14 +613,14 @@ func (bigtable *Bigtable) DeleteRowsWithPrefix(prefix string) {
 		logger.Infof("deleting %v rows", l)
 
 		for i := 0; i < l; i++ {
-			if !strings.HasPrefix(rowsToDelete[i], "1:t:") {
+			if !strings.HasPrefix(rowsToDelete[i], prefix) {
 				logger.Infof("wrong prefix: %v", rowsToDelete[i])
 			}
-			ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+			ctx, done := context.WithTimeout(ctx, time.Second*30)
 			defer done()
 			if i%10000 == 0 && i != 0 {
 				logger.Infof("deleting rows: %v to %v", i-10000, i)
-				errs, err := bigtable.tableData.ApplyBulk(ctx, rowsToDelete[i-10000:i], muts)
+				errs, err := bigtable.applyBulk(ctx, bigtable.tableData, rowsToDelete[i-10000:i], muts)
 				if err != nil {
 					logger.WithError(err).Errorf("error deleting row: %v", rowsToDelete[i])
 				}
@@ -661,7 +630,7 @@ func (bigtable *Bigtable) DeleteRowsWithPrefix(prefix string) {
 			}
 			if l < 10000 && l > 0 {
 				logger.Infof("deleting remainder")
-				errs, err := bigtable.tableData.ApplyBulk(ctx, rowsToDelete, muts[:len(rowsToDelete)])
+				errs, err := bigtable.applyBulk(ctx, bigtable.tableData, rowsToDelete, muts[:len(rowsToDelete)])
 				if err != nil {
 					logger.WithError(err).Errorf("error deleting row: %v", rowsToDelete[i])
 				}
@@ -792,6 +761,14 @@ func (bigtable *Bigtable) TransformBlock(block *types.Eth1Block, cache *freecach
 
 	idx.Mev = CalculateMevFromBlock(block).Bytes()
 
+	if len(block.GetBaseFee()) > 0 {
+		idx.BurnedFees = burnedFeesOfBlock(block).Bytes()
+		err = bigtable.transformBurnedFees(block, idx.BurnedFees, bulkMetadataUpdates)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Mark Coinbase for balance update
 	bigtable.markBalanceUpdate(idx.Coinbase, []byte{0x0}, bulkMetadataUpdates, cache)
 
@@ -799,7 +776,7 @@ func (bigtable *Bigtable) TransformBlock(block *types.Eth1Block, cache *freecach
 	key := fmt.Sprintf("%s:B:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()))
 	mut := gcp_bigtable.NewMutation()
 
-	b, err := proto.Marshal(&idx)
+	b, err := marshalDataRow(&idx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
 	}
@@ -865,15 +842,34 @@ func CalculateTxFeeFromTransaction(tx *types.Eth1Transaction, blockBaseFee *big.
 }
 
 // TransformTx extracts transactions from bigtable more specifically from the table blocks.
+// The contribution of the transactions to the summaries and counterparty rollups of their senders and recipients is written to the metadata updates table.
 func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
 	bulkData = &types.BulkMutations{}
 	bulkMetadataUpdates = &types.BulkMutations{}
 
+	var baseFee *big.Int
+	if len(blk.GetBaseFee()) > 0 {
+		baseFee = new(big.Int).SetBytes(blk.GetBaseFee())
+	}
+
+	// sorted gas prices of the block, used to rank each tx by the gas price it paid
+	gasPrices := make([]*big.Int, 0, len(blk.Transactions))
+	for _, tx := range blk.Transactions {
+		gasPrices = append(gasPrices, new(big.Int).SetBytes(tx.GetGasPrice()))
+	}
+	sort.Slice(gasPrices, func(i, j int) bool {
+		return gasPrices[i].Cmp(gasPrices[j]) < 0
+	})
+
 	for i, tx := range blk.Transactions {
 		if i > 9999 {
 			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
 		}
 		iReverse := reversePaddedIndex(i, 10000)
+		gasPrice := new(big.Int).SetBytes(tx.GetGasPrice())
+		cheaperTxs := sort.Search(len(gasPrices), func(j int) bool {
+			return gasPrices[j].Cmp(gasPrice) >= 0
+		})
 		// logger.Infof("address to: %x address: contract: %x, len(to): %v, len(contract): %v, contranct zero: %v", tx.GetTo(), tx.GetContractAddress(), len(tx.GetTo()), len(tx.GetContractAddress()), bytes.Equal(tx.GetContractAddress(), ZERO_ADDRESS))
 		to := tx.GetTo()
 		isContract := false
@@ -886,13 +882,16 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 		if len(tx.GetItx()) > 0 || tx.GetGasUsed() > 21000 || tx.GetErrorMsg() != "" {
 			invokesContract = true
 		}
-		method := make([]byte, 0)
-		if len(tx.GetData()) > 3 {
-			method = tx.GetData()[:4]
+		// transactions without calldata (or with calldata shorter than a selector) have no method and are not indexed by method
+		var method []byte
+		if len(tx.GetData()) >= 4 {
+			method = append(make([]byte, 0, 4), tx.GetData()[:4]...)
 		}
 
 		key := fmt.Sprintf("%s:TX:%x", bigtable.chainId, tx.GetHash())
-		fee := new(big.Int).Mul(new(big.Int).SetBytes(tx.GetGasPrice()), big.NewInt(int64(tx.GetGasUsed()))).Bytes()
+		// the gas price of dynamic fee transactions is their fee cap, the fee is paid at the effective gas price
+		effectiveGasPrice := EffectiveGasPrice(tx, baseFee)
+		fee := new(big.Int).Mul(effectiveGasPrice, new(big.Int).SetUint64(tx.GetGasUsed())).Bytes()
 		indexedTx := &types.Eth1TransactionIndexed{
 			Hash:               tx.GetHash(),
 			BlockNumber:        blk.GetNumber(),
@@ -906,6 +905,12 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 			IsContractCreation: isContract,
 			InvokesContract:    invokesContract,
 			ErrorMsg:           tx.GetErrorMsg(),
+			TxIndex:            uint64(i),
+			BlockTxCount:       uint64(len(blk.Transactions)),
+			GasPriceRank:       uint64(len(gasPrices) - cheaperTxs),
+			EffectiveGasPrice:  effectiveGasPrice.Bytes(),
+			BlockBaseFee:       blk.GetBaseFee(),
+			GasUsed:            tx.GetGasUsed(),
 		}
 		// Mark Sender and Recipient for balance update
 		bigtable.markBalanceUpdate(indexedTx.From, []byte{0x0}, bulkMetadataUpdates, cache)
@@ -915,7 +920,7 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 			logger.Fatalf("retrieved hash of length %v for a tx in block %v", len(indexedTx.Hash), blk.GetNumber())
 		}
 
-		b, err := proto.Marshal(indexedTx)
+		b, err := marshalDataRow(indexedTx)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -930,11 +935,14 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 			fmt.Sprintf("%s:I:TX:%x:TO:%x:%s:%s", bigtable.chainId, tx.GetFrom(), to, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
 			fmt.Sprintf("%s:I:TX:%x:TIME:%s:%s", bigtable.chainId, tx.GetFrom(), reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
 			fmt.Sprintf("%s:I:TX:%x:BLOCK:%s:%s", bigtable.chainId, tx.GetFrom(), reversedPaddedBlockNumber(blk.GetNumber()), iReverse),
-			fmt.Sprintf("%s:I:TX:%x:METHOD:%x:%s:%s", bigtable.chainId, tx.GetFrom(), method, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
 			fmt.Sprintf("%s:I:TX:%x:FROM:%x:%s:%s", bigtable.chainId, to, tx.GetFrom(), reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
 			fmt.Sprintf("%s:I:TX:%x:TIME:%s:%s", bigtable.chainId, to, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
 			fmt.Sprintf("%s:I:TX:%x:BLOCK:%s:%s", bigtable.chainId, to, reversedPaddedBlockNumber(blk.GetNumber()), iReverse),
-			fmt.Sprintf("%s:I:TX:%x:METHOD:%x:%s:%s", bigtable.chainId, to, method, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse),
+		}
+
+		if len(method) == 4 {
+			indexes = append(indexes, fmt.Sprintf("%s:I:TX:%x:METHOD:%x:%s:%s", bigtable.chainId, tx.GetFrom(), method, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse))
+			indexes = append(indexes, fmt.Sprintf("%s:I:TX:%x:METHOD:%x:%s:%s", bigtable.chainId, to, method, reversePaddedBigtableTimestamp(blk.GetTime()), iReverse))
 		}
 
 		if indexedTx.ErrorMsg != "" {
@@ -957,9 +965,86 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 
 	}
 
+	err = bigtable.transformAddressSummaryTx(blk, bulkMetadataUpdates)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = bigtable.transformCounterpartiesTx(blk, bulkMetadataUpdates)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return bulkData, bulkMetadataUpdates, nil
 }
 
+// newIndexedItx returns the indexed form of the j-th call frame of a transaction
+func newIndexedItx(blk *types.Eth1Block, tx *types.Eth1Transaction, itx *types.Eth1InternalTransaction, j int) *types.Eth1InternalTransactionIndexed {
+	path, _ := parseTracePath(itx.GetPath())
+	return &types.Eth1InternalTransactionIndexed{
+		ParentHash:  tx.GetHash(),
+		BlockNumber: blk.GetNumber(),
+		Time:        blk.GetTime(),
+		Type:        itx.GetType(),
+		From:        itx.GetFrom(),
+		To:          itx.GetTo(),
+		Value:       itx.GetValue(),
+		Path:        itx.GetPath(),
+		Index:       uint64(j),
+		Depth:       uint32(len(path)),
+	}
+}
+
+// parseTracePath splits a trace address like [0 2] into its positions. Geth style traces do not provide the trace address, their
+// path can not be parsed.
+func parseTracePath(path string) ([]string, bool) {
+	if !strings.HasPrefix(path, "[") || !strings.HasSuffix(path, "]") {
+		return nil, false
+	}
+	return strings.Fields(path[1 : len(path)-1]), true
+}
+
+// BuildItxTree nests the call frames of a transaction by their trace address and returns the top level calls. Frames whose parent
+// is missing are attached to their closest known ancestor, frames without a trace address are returned as top level calls.
+func BuildItxTree(itxs []*types.Eth1InternalTransactionIndexed) []*types.ItxTreeNode {
+	sorted := make([]*types.Eth1InternalTransactionIndexed, len(itxs))
+	copy(sorted, itxs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetIndex() < sorted[j].GetIndex()
+	})
+
+	roots := []*types.ItxTreeNode{}
+	nodes := make(map[string]*types.ItxTreeNode, len(sorted))
+	for _, itx := range sorted {
+		node := &types.ItxTreeNode{
+			Type:  itx.GetType(),
+			From:  itx.GetFrom(),
+			To:    itx.GetTo(),
+			Value: types.NewEtherAmount(new(big.Int).SetBytes(itx.GetValue())),
+			Path:  itx.GetPath(),
+			Index: itx.GetIndex(),
+			Depth: itx.GetDepth(),
+		}
+		path, ok := parseTracePath(itx.GetPath())
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+		key := strings.Join(path, " ")
+		nodes[key] = node
+
+		var parent *types.ItxTreeNode
+		for i := len(path) - 1; i >= 0 && parent == nil; i-- {
+			parent = nodes[strings.Join(path[:i], " ")]
+		}
+		if parent == nil {
+			roots = append(roots, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+	return roots
+}
+
 // TransformItx extracts internal transactions from bigtable more specifically from the table blocks.
 // It transforms the internal transactions contained within a block and strips any information that is not necessary for our frontend views
 // It writes internal transactions to table data:
@@ -984,7 +1069,13 @@ func (bigtable *Bigtable) TransformTx(blk *types.Eth1Block, cache *freecache.Cac
 // Row:    <chainID>:I:ITX:<TO_ADDRESS>:FROM:<FROM_ADDRESS>:<reversePaddedBigtableTimestamp>:<paddedTxIndex>:<paddedITXIndex>
 // Family: f
 // Column: <chainID>:ITX:<HASH>:<paddedITXIndex>
+//
+// Row:    <chainID>:I:ITX:BLOCK:<reversedPaddedBlockNumber>:<paddedTxIndex>:<paddedITXIndex>
+// Family: f
+// Column: <chainID>:ITX:<HASH>:<paddedITXIndex>
 // Cell:   nil
+//
+// The contribution of the internal transactions to the address summaries is written to the metadata updates table.
 func (bigtable *Bigtable) TransformItx(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
 	bulkData = &types.BulkMutations{}
 	bulkMetadataUpdates = &types.BulkMutations{}
@@ -1006,20 +1097,12 @@ func (bigtable *Bigtable) TransformItx(blk *types.Eth1Block, cache *freecache.Ca
 			}
 
 			key := fmt.Sprintf("%s:ITX:%x:%s", bigtable.chainId, tx.GetHash(), jReversed)
-			indexedItx := &types.Eth1InternalTransactionIndexed{
-				ParentHash:  tx.GetHash(),
-				BlockNumber: blk.GetNumber(),
-				Time:        blk.GetTime(),
-				Type:        idx.GetType(),
-				From:        idx.GetFrom(),
-				To:          idx.GetTo(),
-				Value:       idx.GetValue(),
-			}
+			indexedItx := newIndexedItx(blk, tx, idx, j)
 
 			bigtable.markBalanceUpdate(indexedItx.To, []byte{0x0}, bulkMetadataUpdates, cache)
 			bigtable.markBalanceUpdate(indexedItx.From, []byte{0x0}, bulkMetadataUpdates, cache)
 
-			b, err := proto.Marshal(indexedItx)
+			b, err := marshalDataRow(indexedItx)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1036,6 +1119,7 @@ func (bigtable *Bigtable) TransformItx(blk *types.Eth1Block, cache *freecache.Ca
 				fmt.Sprintf("%s:I:ITX:%x:FROM:%x:%s:%s:%s", bigtable.chainId, idx.GetTo(), idx.GetFrom(), reversePaddedBigtableTimestamp(blk.GetTime()), iReversed, jReversed),
 				fmt.Sprintf("%s:I:ITX:%x:TIME:%s:%s:%s", bigtable.chainId, idx.GetFrom(), reversePaddedBigtableTimestamp(blk.GetTime()), iReversed, jReversed),
 				fmt.Sprintf("%s:I:ITX:%x:TIME:%s:%s:%s", bigtable.chainId, idx.GetTo(), reversePaddedBigtableTimestamp(blk.GetTime()), iReversed, jReversed),
+				fmt.Sprintf("%s:I:ITX:BLOCK:%s:%04d:%06d", bigtable.chainId, reversedPaddedBlockNumber(blk.GetNumber()), i, j),
 			}
 
 			for _, idx := range indexes {
@@ -1048,6 +1132,11 @@ func (bigtable *Bigtable) TransformItx(blk *types.Eth1Block, cache *freecache.Ca
 		}
 	}
 
+	err = bigtable.transformAddressSummaryItx(blk, bulkMetadataUpdates)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return bulkData, bulkMetadataUpdates, nil
 }
 
@@ -1110,6 +1199,7 @@ func (bigtable *Bigtable) TransformERC20(blk *types.Eth1Block, cache *freecache.
 			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
 		}
 		iReversed := reversePaddedIndex(i, 10000)
+		airdrops := detectAirdrops(tx)
 		for j, log := range tx.GetLogs() {
 			if j > 99999 {
 				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
@@ -1156,11 +1246,12 @@ func (bigtable *Bigtable) TransformERC20(blk *types.Eth1Block, cache *freecache.
 				From:         transfer.From.Bytes(),
 				To:           transfer.To.Bytes(),
 				Value:        value,
+				Airdrop:      airdrops[airdropKey(log.Address, transfer.From.Bytes())],
 			}
 			bigtable.markBalanceUpdate(indexedLog.From, indexedLog.TokenAddress, bulkMetadataUpdates, cache)
 			bigtable.markBalanceUpdate(indexedLog.To, indexedLog.TokenAddress, bulkMetadataUpdates, cache)
 
-			b, err := proto.Marshal(indexedLog)
+			b, err := marshalDataRow(indexedLog)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1199,6 +1290,11 @@ func (bigtable *Bigtable) TransformERC20(blk *types.Eth1Block, cache *freecache.
 		}
 	}
 
+	err = bigtable.transformCounterpartiesERC20(blk, bulkMetadataUpdates)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return bulkData, bulkMetadataUpdates, nil
 }
 
@@ -1261,6 +1357,7 @@ func (bigtable *Bigtable) TransformERC721(blk *types.Eth1Block, cache *freecache
 			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
 		}
 		iReversed := reversePaddedIndex(i, 10000)
+		airdrops := detectAirdrops(tx)
 		for j, log := range tx.GetLogs() {
 			if j > 99999 {
 				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
@@ -1307,9 +1404,10 @@ func (bigtable *Bigtable) TransformERC721(blk *types.Eth1Block, cache *freecache
 				From:         transfer.From.Bytes(),
 				To:           transfer.To.Bytes(),
 				TokenId:      tokenId.Bytes(),
+				Airdrop:      airdrops[airdropKey(log.Address, transfer.From.Bytes())],
 			}
 
-			b, err := proto.Marshal(indexedLog)
+			b, err := marshalDataRow(indexedLog)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1411,6 +1509,7 @@ func (bigtable *Bigtable) TransformERC1155(blk *types.Eth1Block, cache *freecach
 			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
 		}
 		iReversed := reversePaddedIndex(i, 10000)
+		airdrops := detectAirdrops(tx)
 		for j, log := range tx.GetLogs() {
 			if j > 99999 {
 				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
@@ -1487,8 +1586,9 @@ func (bigtable *Bigtable) TransformERC1155(blk *types.Eth1Block, cache *freecach
 				indexedLog.Value = transferSingle.Value.Bytes()
 				indexedLog.TokenAddress = log.GetAddress()
 			}
+			indexedLog.Airdrop = airdrops[airdropKey(indexedLog.TokenAddress, indexedLog.From)]
 
-			b, err := proto.Marshal(indexedLog)
+			b, err := marshalDataRow(indexedLog)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -1584,7 +1684,7 @@ func (bigtable *Bigtable) TransformUncle(block *types.Eth1Block, cache *freecach
 		key := fmt.Sprintf("%s:U:%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()), iReversed)
 		mut := gcp_bigtable.NewMutation()
 
-		b, err := proto.Marshal(&uncleIndexed)
+		b, err := marshalDataRow(&uncleIndexed)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
 		}
@@ -1653,7 +1753,7 @@ func (bigtable *Bigtable) TransformWithdrawals(block *types.Eth1Block, cache *fr
 		key := fmt.Sprintf("%s:W:%s:%s", bigtable.chainId, reversedPaddedBlockNumber(block.GetNumber()), iReversed)
 		mut := gcp_bigtable.NewMutation()
 
-		b, err := proto.Marshal(&withdrawalIndexed)
+		b, err := marshalDataRow(&withdrawalIndexed)
 		if err != nil {
 			return nil, nil, fmt.Errorf("error marshalling proto object err: %w", err)
 		}
@@ -1680,22 +1780,21 @@ func (bigtable *Bigtable) TransformWithdrawals(block *types.Eth1Block, cache *fr
 	return bulkData, bulkMetadataUpdates, nil
 }
 
-func (bigtable *Bigtable) GetEth1TxForAddress(prefix string, limit int64) ([]*types.Eth1TransactionIndexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1TxForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1TransactionIndexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
 	data := make([]*types.Eth1TransactionIndexed, 0, limit)
 	keys := make([]string, 0, limit)
 	indexes := make([]string, 0, limit)
 	keysMap := make(map[string]*types.Eth1TransactionIndexed, limit)
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	// add \x00 to the row range such that we skip the previous value
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -1704,21 +1803,25 @@ func (bigtable *Bigtable) GetEth1TxForAddress(prefix string, limit int64) ([]*ty
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1TransactionIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1TransactionIndexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1TransactionIndexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1TxForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
@@ -1726,10 +1829,41 @@ func (bigtable *Bigtable) GetEth1TxForAddress(prefix string, limit int64) ([]*ty
 		}
 	}
 
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
+}
+
+// maxIndexPagingRows limits how many rows of an index are counted to page through it by offset
+const maxIndexPagingRows = 10000
+
+// indexPageStarts returns the page tokens of the pages of an index, i.e. the key preceding the first row of each page
+// (the prefix itself for the first page), together with the number of rows read. Only the keys of at most maxRows rows
+// are read, pos is the number of key parts that make up the index as for prefixSuccessor.
+func (bigtable *Bigtable) indexPageStarts(ctx context.Context, prefix string, pos int, length, maxRows int64) ([]string, int64, error) {
+	ctx, cancel := context.WithDeadline(withoutLatencyBudget(ctx), time.Now().Add(time.Second*30))
+	defer cancel()
+
+	starts := []string{prefix}
+	total := int64(0)
+	filter := gcp_bigtable.ChainFilters(gcp_bigtable.CellsPerRowLimitFilter(1), gcp_bigtable.StripValueFilter())
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, pos), maxRows, func(row gcp_bigtable.Row) bool {
+		total++
+		if total%length == 0 {
+			starts = append(starts, row.Key())
+		}
+		return true
+	}, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting rows of index %v: %w", prefix, err)
+	}
+
+	// a full last page is not followed by another one unless the index continues beyond the read rows
+	if total > 0 && total%length == 0 && total < maxRows {
+		starts = starts[:len(starts)-1]
+	}
+	return starts, total, nil
 }
 
-func (bigtable *Bigtable) GetAddressesNamesArMetadata(names *map[string]string, inputMetadata *map[string]*types.ERC20Metadata) (map[string]string, map[string]*types.ERC20Metadata, error) {
+func (bigtable *Bigtable) GetAddressesNamesArMetadata(ctx context.Context, names *map[string]string, inputMetadata *map[string]*types.ERC20Metadata) (map[string]string, map[string]*types.ERC20Metadata, error) {
 	outputMetadata := make(map[string]*types.ERC20Metadata)
 
 	g := new(errgroup.Group)
@@ -1738,7 +1872,7 @@ func (bigtable *Bigtable) GetAddressesNamesArMetadata(names *map[string]string,
 
 	if names != nil {
 		g.Go(func() error {
-			err := bigtable.GetAddressNames(*names)
+			err := bigtable.GetAddressNames(ctx, *names)
 			if err != nil {
 				return err
 			}
@@ -1750,7 +1884,7 @@ func (bigtable *Bigtable) GetAddressesNamesArMetadata(names *map[string]string,
 		for address := range *inputMetadata {
 			address := address
 			g.Go(func() error {
-				metadata, err := bigtable.GetERC20MetadataForAddress([]byte(address))
+				metadata, err := bigtable.GetERC20MetadataForAddress(ctx, []byte(address))
 				if err != nil {
 					return err
 				}
@@ -1770,11 +1904,11 @@ func (bigtable *Bigtable) GetAddressesNamesArMetadata(names *map[string]string,
 	return *names, outputMetadata, nil
 }
 
-func (bigtable *Bigtable) GetIndexedEth1Transaction(txHash []byte) (*types.Eth1TransactionIndexed, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetIndexedEth1Transaction(ctx context.Context, txHash []byte) (*types.Eth1TransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 	key := fmt.Sprintf("%s:TX:%x", bigtable.chainId, txHash)
-	row, err := bigtable.tableData.ReadRow(ctx, key)
+	row, err := bigtable.readRow(ctx, bigtable.tableData, key, skipTombstones())
 
 	if err != nil {
 		return nil, err
@@ -1792,152 +1926,457 @@ func (bigtable *Bigtable) GetIndexedEth1Transaction(txHash []byte) (*types.Eth1T
 	}
 }
 
-func (bigtable *Bigtable) GetAddressTransactionsTableData(address []byte, search string, pageToken string) (*types.DataTableResponse, error) {
-	if pageToken == "" {
-		pageToken = fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, address, FILTER_TIME)
+// GetRawEth1Transaction returns a transaction together with its block as saved in the blocks table. It returns nil if the transaction
+// has not been indexed.
+func (bigtable *Bigtable) GetRawEth1Transaction(ctx context.Context, txHash []byte) (*types.Eth1Transaction, *types.Eth1Block, error) {
+	indexedTx, err := bigtable.GetIndexedEth1Transaction(ctx, txHash)
+	if err != nil || indexedTx == nil {
+		return nil, nil, err
 	}
 
-	transactions, lastKey, err := BigtableClient.GetEth1TxForAddress(pageToken, 25)
+	block, err := bigtable.GetBlockFromBlocksTable(ctx, indexedTx.GetBlockNumber())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if indexedTx.GetTxIndex() >= uint64(len(block.GetTransactions())) || !bytes.Equal(block.GetTransactions()[indexedTx.GetTxIndex()].GetHash(), txHash) {
+		return nil, nil, fmt.Errorf("tx 0x%x not found at index %v of block %v", txHash, indexedTx.GetTxIndex(), block.GetNumber())
 	}
+	return block.GetTransactions()[indexedTx.GetTxIndex()], block, nil
+}
 
-	// retrieve metadata
-	names := make(map[string]string)
-	for _, t := range transactions {
-		names[string(t.From)] = ""
-		names[string(t.To)] = ""
+// GetTxFeeBreakdown returns the fee breakdown of a transaction. Transactions indexed before the effective gas price was stored
+// are looked up in their raw block. It returns nil if the transaction has not been indexed.
+func (bigtable *Bigtable) GetTxFeeBreakdown(ctx context.Context, txHash []byte) (*types.TxFeeBreakdown, error) {
+	indexedTx, err := bigtable.GetIndexedEth1Transaction(ctx, txHash)
+	if err != nil || indexedTx == nil {
+		return nil, err
+	}
+	if breakdown := indexedTx.FeeBreakdown(); breakdown != nil {
+		return breakdown, nil
 	}
-	names, _, err = BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+
+	block, err := bigtable.GetBlockFromBlocksTable(ctx, indexedTx.GetBlockNumber())
 	if err != nil {
 		return nil, err
 	}
+	if indexedTx.GetTxIndex() >= uint64(len(block.GetTransactions())) || !bytes.Equal(block.GetTransactions()[indexedTx.GetTxIndex()].GetHash(), txHash) {
+		return nil, fmt.Errorf("tx 0x%x not found at index %v of block %v", txHash, indexedTx.GetTxIndex(), block.GetNumber())
+	}
+	tx := block.GetTransactions()[indexedTx.GetTxIndex()]
 
-	tableData := make([][]interface{}, len(transactions))
-	for i, t := range transactions {
-		fromName := names[string(t.From)]
-		toName := names[string(t.To)]
-
-		from := utils.FormatAddress(t.From, nil, fromName, false, false, !bytes.Equal(t.From, address))
-		to := utils.FormatAddress(t.To, nil, toName, false, false, !bytes.Equal(t.To, address))
-
-		method := bigtable.GetMethodLabel(t.MethodId, t.InvokesContract)
+	var baseFee *big.Int
+	if len(block.GetBaseFee()) > 0 {
+		baseFee = new(big.Int).SetBytes(block.GetBaseFee())
+	}
+	return types.NewTxFeeBreakdown(tx.GetGasUsed(), EffectiveGasPrice(tx, baseFee), baseFee), nil
+}
 
-		tableData[i] = []interface{}{
-			utils.FormatTransactionHash(t.Hash),
-			utils.FormatMethod(method),
-			utils.FormatBlockNumber(t.BlockNumber),
-			utils.FormatTimeFromNow(t.Time.AsTime()),
-			from,
-			utils.FormatInOutSelf(address, t.From, t.To),
-			to,
-			utils.FormatAmount(new(big.Int).SetBytes(t.Value), "Ether", 6),
-		}
+// GetItxTreeForTransaction returns the call tree of a transaction. The indexed internal transactions only contain the calls that
+// transfer value, so the tree is built from the complete trace stored with the raw block. It returns nil if the transaction has
+// not been indexed or has no trace.
+func (bigtable *Bigtable) GetItxTreeForTransaction(ctx context.Context, txHash []byte) ([]*types.ItxTreeNode, error) {
+	indexedTx, err := bigtable.GetIndexedEth1Transaction(ctx, txHash)
+	if err != nil || indexedTx == nil {
+		return nil, err
 	}
 
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: lastKey,
+	block, err := bigtable.GetBlockFromBlocksTable(ctx, indexedTx.GetBlockNumber())
+	if err != nil {
+		return nil, err
 	}
+	if indexedTx.GetTxIndex() >= uint64(len(block.GetTransactions())) || !bytes.Equal(block.GetTransactions()[indexedTx.GetTxIndex()].GetHash(), txHash) {
+		return nil, fmt.Errorf("tx 0x%x not found at index %v of block %v", txHash, indexedTx.GetTxIndex(), block.GetNumber())
+	}
+	tx := block.GetTransactions()[indexedTx.GetTxIndex()]
 
-	return data, nil
+	itxs := make([]*types.Eth1InternalTransactionIndexed, len(tx.GetItx()))
+	for j, idx := range tx.GetItx() {
+		itxs[j] = newIndexedItx(block, tx, idx, j)
+	}
+	return BuildItxTree(itxs), nil
 }
 
-func (bigtable *Bigtable) GetEth1BlocksForAddress(prefix string, limit int64) ([]*types.Eth1BlockIndexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+// GetIndexedEth1Transactions returns the indexed transactions with the given hashes in a single read, ordered like txHashes.
+// Hashes that have not been indexed are left out of the result.
+func (bigtable *Bigtable) GetIndexedEth1Transactions(ctx context.Context, txHashes [][]byte) ([]*types.Eth1TransactionIndexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 4))
-	data := make([]*types.Eth1BlockIndexed, 0, limit)
-	keys := make([]string, 0, limit)
-	indexes := make([]string, 0, limit)
-	keysMap := make(map[string]*types.Eth1BlockIndexed, limit)
-
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
-		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
-		indexes = append(indexes, row.Key())
-		return true
-	}, gcp_bigtable.LimitRows(limit))
-	if err != nil {
-		return nil, "", err
+	keys := make([]string, 0, len(txHashes))
+	keysMap := make(map[string]*types.Eth1TransactionIndexed, len(txHashes))
+	for _, txHash := range txHashes {
+		key := fmt.Sprintf("%s:TX:%x", bigtable.chainId, txHash)
+		if _, exists := keysMap[key]; exists {
+			continue
+		}
+		keysMap[key] = nil
+		keys = append(keys, key)
 	}
-
 	if len(keys) == 0 {
-		return data, "", nil
+		return []*types.Eth1TransactionIndexed{}, nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
-		b := &types.Eth1BlockIndexed{}
-		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
-
+	skipped := newSkippedRows("Eth1TransactionIndexed")
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		tx := &types.Eth1TransactionIndexed{}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, tx)
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1BlockIndexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
-		keysMap[row.Key()] = b
-
+		keysMap[row.Key()] = tx
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
-		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1BlocksForAddress")
-		return nil, "", err
+		logger.WithError(err).WithField("count", len(keys)).Errorf("error reading rows in bigtable_eth1 / GetIndexedEth1Transactions")
+		return nil, err
 	}
 
+	data := make([]*types.Eth1TransactionIndexed, 0, len(keys))
 	for _, key := range keys {
-		if d := keysMap[key]; d != nil {
-			data = append(data, d)
+		if tx := keysMap[key]; tx != nil {
+			data = append(data, tx)
 		}
 	}
-
-	return data, indexes[len(indexes)-1], nil
+	return data, skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressBlocksMinedTableData(address string, search string, pageToken string) (*types.DataTableResponse, error) {
-	if pageToken == "" {
-		pageToken = fmt.Sprintf("%s:I:B:%s:", bigtable.chainId, address)
+// GetEth1TxByHash returns the indexed transaction with the given hash together with its logs and internal transactions, nil is returned
+// if the transaction has not been indexed. The logs are taken from the block the transaction is included in, the internal transactions
+// are ordered by their position in the trace of the transaction.
+func (bigtable *Bigtable) GetEth1TxByHash(ctx context.Context, txHash []byte) (*types.Eth1TxByHash, error) {
+	tx, err := bigtable.GetIndexedEth1Transaction(ctx, txHash)
+	if err != nil || tx == nil {
+		return nil, err
 	}
 
-	blocks, lastKey, err := BigtableClient.GetEth1BlocksForAddress(pageToken, 25)
+	result := &types.Eth1TxByHash{
+		Transaction: tx,
+	}
+
+	block, err := bigtable.readBlock(ctx, tx.BlockNumber)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error reading block %v of tx 0x%x: %w", tx.BlockNumber, txHash, err)
+	}
+	if tx.TxIndex >= uint64(len(block.Transactions)) || !bytes.Equal(block.Transactions[tx.TxIndex].Hash, txHash) {
+		return nil, fmt.Errorf("tx 0x%x not found at index %v of block %v", txHash, tx.TxIndex, tx.BlockNumber)
 	}
+	result.Logs = block.Transactions[tx.TxIndex].Logs
 
-	tableData := make([][]interface{}, len(blocks))
-	for i, b := range blocks {
-		reward := new(big.Int).Add(utils.Eth1BlockReward(b.Number, b.Difficulty), new(big.Int).SetBytes(b.TxReward))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
 
-		tableData[i] = []interface{}{
-			utils.FormatBlockNumber(b.Number),
-			utils.FormatTimeFromNow(b.Time.AsTime()),
-			utils.FormatBlockUsage(b.GasUsed, b.GasLimit),
-			utils.FormatAmount(reward, "Ether", 6),
+	prefix := fmt.Sprintf("%s:ITX:%x:", bigtable.chainId, txHash)
+	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 3))
+
+	skipped := newSkippedRows("Eth1InternalTransactionIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, rowRange, func(row gcp_bigtable.Row) bool {
+		itx := &types.Eth1InternalTransactionIndexed{}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, itx)
+		if err != nil {
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
+		result.InternalTransactions = append(result.InternalTransactions, itx)
+		return true
+	}, skipTombstones())
+	if err != nil {
+		return nil, err
 	}
 
-	data := &types.DataTableResponse{
-		Data:        tableData,
-		PagingToken: lastKey,
+	sort.Slice(result.InternalTransactions, func(i, j int) bool {
+		return result.InternalTransactions[i].Index < result.InternalTransactions[j].Index
+	})
+
+	return result, skipped.err()
+}
+
+// GetRecentEth1TxForAddress returns the most recent transactions of an address
+func (bigtable *Bigtable) GetRecentEth1TxForAddress(ctx context.Context, address []byte, limit int64) ([]*types.Eth1TransactionIndexed, error) {
+	transactions, _, err := bigtable.GetEth1TxForAddress(ctx, fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, address, FILTER_TIME), limit)
+	if IsPartialResult(err) {
+		// the callers only need a sample of the recent activity
+		err = nil
 	}
+	return transactions, err
+}
 
-	return data, nil
+// TxDirectionFilter returns the index filter of the transactions of an address in the given direction: "sent", "received" or
+// an empty direction for all transactions
+func TxDirectionFilter(direction string) (IndexFilter, error) {
+	switch direction {
+	case "":
+		return FILTER_TIME, nil
+	case "sent":
+		return FILTER_TO, nil
+	case "received":
+		return FILTER_FROM, nil
+	}
+	return "", fmt.Errorf("invalid transaction direction %q", direction)
 }
 
-func (bigtable *Bigtable) GetEth1UnclesForAddress(prefix string, limit int64) ([]*types.Eth1UncleIndexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
-	defer cancel()
+// AddressTxPrefix returns the prefix of the transaction index of an address to be passed to GetEth1TxForAddress. FILTER_TO only
+// contains the transactions the address sent and FILTER_FROM the ones it received, both are ordered by the counterparty first
+// and by time second. An empty filter selects all transactions ordered by time.
+func (bigtable *Bigtable) AddressTxPrefix(address []byte, filter IndexFilter) string {
+	if filter == "" {
+		filter = FILTER_TIME
+	}
+	return fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, address, filter)
+}
+
+func (bigtable *Bigtable) GetAddressTransactionsTableData(ctx context.Context, address []byte, filter IndexFilter, pageToken string) (*types.DataTableResponse, error) {
+	if pageToken == "" {
+		pageToken = bigtable.AddressTxPrefix(address, filter)
+	}
+
+	transactions, lastKey, err := bigtable.GetEth1TxForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	// corrupted or missing rows have been skipped, the remaining ones are still shown
+	partial := err
+
+	tableData, err := bigtable.addressTransactionsTableRows(ctx, address, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
+	}
+
+	truncatePage(ctx, data, pageToken)
+	return data, partial
+}
+
+// GetAddressTransactionsTablePage returns a page of the transactions of an address for a pager. The page is selected by its token or,
+// if no token is given, by the offset of its first row. The response contains the tokens of the previous and the next page and the
+// number of transactions. The number is taken from the summary of the address if it counts all of its transactions, otherwise the
+// index is counted up to maxIndexPagingRows rows. Pages beyond the counted rows can only be reached through their tokens.
+func (bigtable *Bigtable) GetAddressTransactionsTablePage(ctx context.Context, address []byte, filter IndexFilter, pageToken string, start, length int64) (*types.DataTableResponse, error) {
+	prefix := bigtable.AddressTxPrefix(address, filter)
+	if pageToken != "" && !strings.HasPrefix(pageToken, prefix) {
+		return nil, fmt.Errorf("invalid page token %q for address 0x%x", pageToken, address)
+	}
+
+	count, counted, err := bigtable.addressTxCount(ctx, address, filter)
+	if err != nil {
+		return nil, err
+	}
+	// with a counted total only the rows up to the requested page and the first row after it have to be read, the page of a
+	// token is looked up among the pages within the cap
+	maxRows := int64(maxIndexPagingRows)
+	if counted && pageToken == "" && (start/length+1)*length < maxRows {
+		maxRows = (start/length+1)*length + 1
+	}
+	starts, read, err := bigtable.indexPageStarts(ctx, prefix, 5, length, maxRows)
+	if err != nil {
+		return nil, err
+	}
+	total := uint64(read)
+	if counted {
+		total = count
+	}
+	// the index ends within the read rows, there is no row after its last page
+	complete := read < maxRows
+
+	page := int64(-1)
+	if pageToken == "" {
+		page = start / length
+		if page >= int64(len(starts)) {
+			return &types.DataTableResponse{Data: [][]interface{}{}, RecordsTotal: total, RecordsFiltered: total, PageLength: uint64(length), DisplayStart: uint64(start)}, nil
+		}
+		pageToken = starts[page]
+	} else {
+		for i, key := range starts {
+			if key == pageToken {
+				page = int64(i)
+				break
+			}
+		}
+	}
+
+	transactions, lastKey, err := bigtable.GetEth1TxForAddress(ctx, pageToken, length)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	partial := err
+
+	tableData, err := bigtable.addressTransactionsTableRows(ctx, address, transactions)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.DataTableResponse{
+		Data:            tableData,
+		RecordsTotal:    total,
+		RecordsFiltered: total,
+		PageLength:      uint64(length),
+		PagingToken:     lastKey,
+		Warnings:        PartialResultWarnings(partial),
+	}
+	if page >= 0 {
+		data.DisplayStart = uint64(page * length)
+		if page > 0 {
+			data.PreviousPagingToken = starts[page-1]
+		}
+		if page == int64(len(starts))-1 && complete {
+			data.PagingToken = ""
+		}
+	}
+	return data, partial
+}
+
+func (bigtable *Bigtable) addressTransactionsTableRows(ctx context.Context, address []byte, transactions []*types.Eth1TransactionIndexed) ([][]interface{}, error) {
+	// retrieve metadata
+	names := make(map[string]string)
+	for _, t := range transactions {
+		names[string(t.From)] = ""
+		names[string(t.To)] = ""
+	}
+	names, _, err := bigtable.GetAddressesNamesArMetadata(ctx, &names, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	methodIds := make([][]byte, 0, len(transactions))
+	for _, t := range transactions {
+		if t.InvokesContract {
+			methodIds = append(methodIds, t.MethodId)
+		}
+	}
+	signatures := bigtable.ResolveMethodSignatures(ctx, methodIds)
+
+	tableData := make([][]interface{}, len(transactions))
+	for i, t := range transactions {
+		fromName := names[string(t.From)]
+		toName := names[string(t.To)]
+
+		from := utils.FormatAddress(t.From, nil, fromName, false, false, !bytes.Equal(t.From, address))
+		to := utils.FormatAddress(t.To, nil, toName, false, false, !bytes.Equal(t.To, address))
+
+		method, ok := signatures[string(t.MethodId)]
+		if !ok || !t.InvokesContract {
+			method = bigtable.GetMethodLabel(ctx, t.MethodId, t.InvokesContract)
+		}
+
+		tableData[i] = []interface{}{
+			utils.FormatTransactionHash(t.Hash),
+			utils.FormatMethod(method),
+			utils.FormatBlockNumber(t.BlockNumber),
+			utils.FormatTimeFromNow(t.Time.AsTime()),
+			from,
+			utils.FormatInOutSelf(address, t.From, t.To),
+			to,
+			utils.FormatAmount(new(big.Int).SetBytes(t.Value), "Ether", 6),
+		}
+	}
+	return tableData, nil
+}
+
+func (bigtable *Bigtable) GetEth1BlocksForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1BlockIndexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	data := make([]*types.Eth1BlockIndexed, 0, limit)
+	keys := make([]string, 0, limit)
+	indexes := make([]string, 0, limit)
+	keysMap := make(map[string]*types.Eth1BlockIndexed, limit)
 
 	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 4))
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 4), limit, func(row gcp_bigtable.Row) bool {
+		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
+		indexes = append(indexes, row.Key())
+		return true
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(keys) == 0 {
+		return data, "", nil
+	}
+
+	skipped := newSkippedRows("Eth1BlockIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
+		b := &types.Eth1BlockIndexed{}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
+
+		if err != nil {
+			skipped.corrupt(row.Key(), err)
+			return true
+		}
+		keysMap[row.Key()] = b
+
+		return true
+	}, skipTombstones())
+	if err != nil {
+		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1BlocksForAddress")
+		return nil, "", err
+	}
+	skipped.checkMissing(keys)
+
+	for _, key := range keys {
+		if d := keysMap[key]; d != nil {
+			data = append(data, d)
+		}
+	}
+
+	return data, indexes[len(indexes)-1], skipped.err()
+}
+
+func (bigtable *Bigtable) GetAddressBlocksMinedTableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error) {
+	if pageToken == "" {
+		pageToken = fmt.Sprintf("%s:I:B:%s:", bigtable.chainId, address)
+	}
+
+	blocks, lastKey, err := bigtable.GetEth1BlocksForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	partial := err
+
+	tableData := make([][]interface{}, len(blocks))
+	for i, b := range blocks {
+		reward := new(big.Int).Add(utils.Eth1BlockReward(b.Number, b.Difficulty), new(big.Int).SetBytes(b.TxReward))
+
+		tableData[i] = []interface{}{
+			utils.FormatBlockNumber(b.Number),
+			utils.FormatTimeFromNow(b.Time.AsTime()),
+			utils.FormatBlockUsage(b.GasUsed, b.GasLimit),
+			utils.FormatAmount(reward, "Ether", 6),
+		}
+	}
+
+	data := &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
+	}
+
+	truncatePage(ctx, data, pageToken)
+	return data, partial
+}
+
+func (bigtable *Bigtable) GetEth1UnclesForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1UncleIndexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
 	data := make([]*types.Eth1UncleIndexed, 0, limit)
 	keys := make([]string, 0, limit)
 	indexes := make([]string, 0, limit)
 	keysMap := make(map[string]*types.Eth1UncleIndexed, limit)
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	// add \x00 to the row range such that we skip the previous value
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 4), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -1946,21 +2385,25 @@ func (bigtable *Bigtable) GetEth1UnclesForAddress(prefix string, limit int64) ([
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1UncleIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1UncleIndexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1UncleIndexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1UnclesForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
@@ -1968,18 +2411,19 @@ func (bigtable *Bigtable) GetEth1UnclesForAddress(prefix string, limit int64) ([
 		}
 	}
 
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressUnclesMinedTableData(address string, search string, pageToken string) (*types.DataTableResponse, error) {
+func (bigtable *Bigtable) GetAddressUnclesMinedTableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error) {
 	if pageToken == "" {
 		pageToken = fmt.Sprintf("%s:I:U:%s:", bigtable.chainId, address)
 	}
 
-	uncles, lastKey, err := BigtableClient.GetEth1UnclesForAddress(pageToken, 25)
-	if err != nil {
+	uncles, lastKey, err := bigtable.GetEth1UnclesForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	tableData := make([][]interface{}, len(uncles))
 	for i, u := range uncles {
@@ -1994,28 +2438,29 @@ func (bigtable *Bigtable) GetAddressUnclesMinedTableData(address string, search
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	truncatePage(ctx, data, pageToken)
+	return data, partial
 }
 
-func (bigtable *Bigtable) GetEth1ItxForAddress(prefix string, limit int64) ([]*types.Eth1InternalTransactionIndexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1ItxForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1InternalTransactionIndexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
 	data := make([]*types.Eth1InternalTransactionIndexed, 0, limit)
 	keys := make([]string, 0, limit)
 	indexes := make([]string, 0, limit)
 
 	keysMap := make(map[string]*types.Eth1InternalTransactionIndexed, limit)
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	// add \x00 to the row range such that we skip the previous value
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -2023,25 +2468,38 @@ func (bigtable *Bigtable) GetEth1ItxForAddress(prefix string, limit int64) ([]*t
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1InternalTransactionIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1InternalTransactionIndexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1InternalTransactionIndexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 
 		// geth traces include zero-value staticalls
 		if bytes.Equal(b.Value, []byte{}) {
 			return true
 		}
+		if b.Index == 0 {
+			// rows written before the trace position was persisted only carry the index in their key
+			index, err := rowIndexFromKey(row.Key(), 3)
+			if err != nil {
+				skipped.corrupt(row.Key(), err)
+				return true
+			}
+			b.Index = uint64(index)
+		}
 		keysMap[row.Key()] = b
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1ItxForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
@@ -2049,26 +2507,76 @@ func (bigtable *Bigtable) GetEth1ItxForAddress(prefix string, limit int64) ([]*t
 		}
 	}
 
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressInternalTableData(address []byte, search string, pageToken string) (*types.DataTableResponse, error) {
+// GetItxForBlock returns the internal transactions of a block in execution order, the page token is the index key of the last returned itx
+func (bigtable *Bigtable) GetItxForBlock(ctx context.Context, number uint64, pageToken string) ([]*types.Eth1InternalTransactionIndexed, string, error) {
+	prefix := fmt.Sprintf("%s:I:ITX:BLOCK:%s:", bigtable.chainId, reversedPaddedBlockNumber(number))
+	if pageToken == "" {
+		pageToken = prefix
+	} else if !strings.HasPrefix(pageToken, prefix) {
+		return nil, "", fmt.Errorf("invalid page token %v for block %v", pageToken, number)
+	}
+
+	return bigtable.GetEth1ItxForAddress(ctx, pageToken, 25)
+}
+
+func (bigtable *Bigtable) GetBlockInternalTableData(ctx context.Context, number uint64, pageToken string) (*types.DataTableResponse, error) {
+	transactions, lastKey, err := bigtable.GetItxForBlock(ctx, number, pageToken)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	partial := err
+
+	names := make(map[string]string)
+	for _, t := range transactions {
+		names[string(t.From)] = ""
+		names[string(t.To)] = ""
+	}
+	names, _, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	tableData := make([][]interface{}, len(transactions))
+	for i, t := range transactions {
+		tableData[i] = []interface{}{
+			utils.FormatItxHash(t.ParentHash, t.Index),
+			utils.FormatAddress(t.From, nil, names[string(t.From)], false, false, true),
+			utils.FormatAddress(t.To, nil, names[string(t.To)], false, false, true),
+			utils.FormatAmount(new(big.Int).SetBytes(t.Value), "Ether", 6),
+			t.Type,
+		}
+	}
+
+	data := &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
+	}
+
+	return data, partial
+}
+
+func (bigtable *Bigtable) GetAddressInternalTableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error) {
 	// defaults to most recent
 	if pageToken == "" {
 		pageToken = fmt.Sprintf("%s:I:ITX:%x:%s:", bigtable.chainId, address, FILTER_TIME)
 	}
 
-	transactions, lastKey, err := bigtable.GetEth1ItxForAddress(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtable.GetEth1ItxForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	names := make(map[string]string)
 	for _, t := range transactions {
 		names[string(t.From)] = ""
 		names[string(t.To)] = ""
 	}
-	names, _, err = BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+	names, _, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -2083,7 +2591,7 @@ func (bigtable *Bigtable) GetAddressInternalTableData(address []byte, search str
 		to := utils.FormatAddress(t.To, nil, toName, false, false, !bytes.Equal(t.To, address))
 
 		tableData[i] = []interface{}{
-			utils.FormatTransactionHash(t.ParentHash),
+			utils.FormatItxHash(t.ParentHash, t.Index),
 			utils.FormatTimeFromNow(t.Time.AsTime()),
 			from,
 			utils.FormatInOutSelf(address, t.From, t.To),
@@ -2096,13 +2604,15 @@ func (bigtable *Bigtable) GetAddressInternalTableData(address []byte, search str
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	truncatePage(ctx, data, pageToken)
+	return data, partial
 }
 
-func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte, from []byte) ([]types.Transfer, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetInternalTransfersForTransaction(ctx context.Context, transaction []byte, from []byte) ([]types.Transfer, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	transfers := map[int]*types.Eth1InternalTransactionIndexed{}
@@ -2111,29 +2621,29 @@ func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte,
 	prefix := fmt.Sprintf("%s:ITX:%x:", bigtable.chainId, transaction)
 	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 3))
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1InternalTransactionIndexed")
+	err := bigtable.readRows(ctx, bigtable.tableData, rowRange, func(row gcp_bigtable.Row) bool {
 		b := &types.Eth1InternalTransactionIndexed{}
 		row_ := row[DEFAULT_FAMILY][0]
 		err := proto.Unmarshal(row_.Value, b)
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1InternalTransactionIndexed data: %v", err)
-			return false
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		// geth traces include the initial transfer & zero-value staticalls
 		if bytes.Equal(b.From, from) || bytes.Equal(b.Value, []byte{}) {
 			return true
 		}
-		rowN, err := strconv.Atoi(strings.Split(row_.Row, ":")[3])
+		rowN, err := rowIndexFromKey(row_.Row, 3)
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1InternalTransactionIndexed row number: %v", err)
-			return false
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
-		rowN = 100000 - rowN
 		mux.Lock()
 		transfers[rowN] = b
 		mux.Unlock()
 		return true
-	}, gcp_bigtable.LimitRows(256))
+	}, gcp_bigtable.LimitRows(256), skipTombstones())
 
 	if err != nil {
 		return nil, err
@@ -2145,7 +2655,7 @@ func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte,
 		names[string(t.To)] = ""
 	}
 
-	err = bigtable.GetAddressNames(names)
+	err = bigtable.GetAddressNames(ctx, names)
 	if err != nil {
 		return nil, err
 	}
@@ -2168,17 +2678,19 @@ func (bigtable *Bigtable) GetInternalTransfersForTransaction(transaction []byte,
 		to := utils.FormatAddress(t.To, nil, toName, false, false, true)
 
 		data[i] = types.Transfer{
-			From:   from,
-			To:     to,
-			Amount: utils.FormatBytesAmount(t.Value, "Ether", 8),
+			From:       from,
+			To:         to,
+			Amount:     utils.FormatBytesAmount(t.Value, "Ether", 8),
+			TraceIndex: uint64(k),
+			TracePath:  t.Path,
 		}
 	}
-	return data, nil
+	return data, skipped.err()
 }
 
 // currently only erc20
-func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(transaction []byte) ([]*types.Transfer, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(ctx context.Context, transaction []byte) ([]*types.Transfer, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 	// uses a more standard transfer in-between type so multiple token types can be handle before the final table response is generated
 	transfers := map[int]*types.Eth1ERC20Indexed{}
@@ -2187,25 +2699,25 @@ func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(transaction [
 	// get erc20 rows
 	prefix := fmt.Sprintf("%s:ERC20:%x:", bigtable.chainId, transaction)
 	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 3))
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1ERC20Indexed")
+	err := bigtable.readRows(ctx, bigtable.tableData, rowRange, func(row gcp_bigtable.Row) bool {
 		b := &types.Eth1ERC20Indexed{}
 		row_ := row[DEFAULT_FAMILY][0]
 		err := proto.Unmarshal(row_.Value, b)
 		if err != nil {
-			logrus.Fatalf("error unmarshalling data for row %v: %v", row.Key(), err)
-			return false
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
-		rowN, err := strconv.Atoi(strings.Split(row_.Row, ":")[3])
+		rowN, err := rowIndexFromKey(row_.Row, 3)
 		if err != nil {
-			logrus.Fatalf("error parsing data for row %v: %v", row.Key(), err)
-			return false
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
-		rowN = 100000 - rowN
 		mux.Lock()
 		transfers[rowN] = b
 		mux.Unlock()
 		return true
-	}, gcp_bigtable.LimitRows(256))
+	}, gcp_bigtable.LimitRows(256), skipTombstones())
 	if err != nil {
 		return nil, err
 	}
@@ -2222,7 +2734,7 @@ func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(transaction [
 	g := new(errgroup.Group)
 	g.SetLimit(25)
 	g.Go(func() error {
-		err := bigtable.GetAddressNames(names)
+		err := bigtable.GetAddressNames(ctx, names)
 		if err != nil {
 			return err
 		}
@@ -2232,7 +2744,7 @@ func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(transaction [
 	for address := range tokens {
 		address := address
 		g.Go(func() error {
-			metadata, err := bigtable.GetERC20MetadataForAddress([]byte(address))
+			metadata, err := bigtable.GetERC20MetadataForAddress(ctx, []byte(address))
 			if err != nil {
 				return err
 			}
@@ -2283,25 +2795,24 @@ func (bigtable *Bigtable) GetArbitraryTokenTransfersForTransaction(transaction [
 
 	}
 
-	return data, nil
+	return data, skipped.err()
 }
 
-func (bigtable *Bigtable) GetEth1ERC20ForAddress(prefix string, limit int64) ([]*types.Eth1ERC20Indexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1ERC20ForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1ERC20Indexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
 	data := make([]*types.Eth1ERC20Indexed, 0, limit)
 	keys := make([]string, 0, limit)
 	indexes := make([]string, 0, limit)
 
 	keysMap := make(map[string]*types.Eth1ERC20Indexed, limit)
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	// add \x00 to the row range such that we skip the previous value
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -2309,20 +2820,24 @@ func (bigtable *Bigtable) GetEth1ERC20ForAddress(prefix string, limit int64) ([]
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1ERC20Indexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1ERC20Indexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1ERC20Indexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1ERC20ForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
@@ -2330,19 +2845,20 @@ func (bigtable *Bigtable) GetEth1ERC20ForAddress(prefix string, limit int64) ([]
 		}
 	}
 
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressErc20TableData(address []byte, search string, pageToken string) (*types.DataTableResponse, error) {
+func (bigtable *Bigtable) GetAddressErc20TableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error) {
 
 	if pageToken == "" {
 		pageToken = fmt.Sprintf("%s:I:ERC20:%x:%s:", bigtable.chainId, address, FILTER_TIME)
 	}
 
-	transactions, lastKey, err := bigtable.GetEth1ERC20ForAddress(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtable.GetEth1ERC20ForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	names := make(map[string]string)
 	tokens := make(map[string]*types.ERC20Metadata)
@@ -2351,7 +2867,24 @@ func (bigtable *Bigtable) GetAddressErc20TableData(address []byte, search string
 		names[string(t.To)] = ""
 		tokens[string(t.TokenAddress)] = nil
 	}
-	names, tokens, err = BigtableClient.GetAddressesNamesArMetadata(&names, &tokens)
+	names, tokens, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenAddresses := make([][]byte, 0, len(tokens))
+	for token := range tokens {
+		tokenAddresses = append(tokenAddresses, []byte(token))
+	}
+	prices, err := bigtable.GetERC20TokenPrices(ctx, tokenAddresses)
+	if err != nil {
+		return nil, err
+	}
+	closeKeys := make([]string, 0, len(transactions))
+	for _, t := range transactions {
+		closeKeys = append(closeKeys, bigtable.erc20ClosePriceKey(t.TokenAddress, t.Time.AsTime()))
+	}
+	closePrices, err := bigtable.getERC20TokenClosePrices(ctx, closeKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -2371,6 +2904,8 @@ func (bigtable *Bigtable) GetAddressErc20TableData(address []byte, search string
 			Token:    t.TokenAddress,
 			Metadata: tokens[string(t.TokenAddress)],
 		}
+		closePrice := closePrices[bigtable.erc20ClosePriceKey(t.TokenAddress, t.Time.AsTime())]
+		value := utils.FormatTokenValue(tb) + utils.FormatTokenUSDValueAt(tb, prices[string(t.TokenAddress)], closePrice, t.Time.AsTime())
 
 		tableData[i] = []interface{}{
 			utils.FormatTransactionHash(t.ParentHash),
@@ -2378,7 +2913,7 @@ func (bigtable *Bigtable) GetAddressErc20TableData(address []byte, search string
 			from,
 			utils.FormatInOutSelf(address, t.From, t.To),
 			to,
-			utils.FormatTokenValue(tb),
+			value,
 			utils.FormatTokenName(tb),
 		}
 
@@ -2387,18 +2922,19 @@ func (bigtable *Bigtable) GetAddressErc20TableData(address []byte, search string
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	truncatePage(ctx, data, pageToken)
+	return data, partial
 }
 
-func (bigtable *Bigtable) GetEth1ERC721ForAddress(prefix string, limit int64) ([]*types.Eth1ERC721Indexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1ERC721ForAddress(ctx context.Context, prefix string, limit int64) ([]*types.Eth1ERC721Indexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	// add \x00 to the row range such that we don't include the prefix itself in the response. Converts range to open interval (start, end).
 	// "1:I:ERC721:81d98c8fda0410ee3e9d7586cb949cd19fa4cf38:TIME;"
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
 
 	data := make([]*types.Eth1ERC721Indexed, 0, limit)
 
@@ -2407,11 +2943,11 @@ func (bigtable *Bigtable) GetEth1ERC721ForAddress(prefix string, limit int64) ([
 	indexes := make([]string, 0, limit)
 
 	//  1:I:ERC721:81d98c8fda0410ee3e9d7586cb949cd19fa4cf38:TIME:9223372035220135322:0052:00000
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -2420,40 +2956,45 @@ func (bigtable *Bigtable) GetEth1ERC721ForAddress(prefix string, limit int64) ([
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1ERC721Indexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1ERC721Indexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1ERC721Indexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1ERC721ForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
 			data = append(data, d)
 		}
 	}
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressErc721TableData(address string, search string, pageToken string) (*types.DataTableResponse, error) {
+func (bigtable *Bigtable) GetAddressErc721TableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error) {
 
 	if pageToken == "" {
 		pageToken = fmt.Sprintf("%s:I:ERC721:%s:%s:", bigtable.chainId, address, FILTER_TIME)
 		// pageToken = fmt.Sprintf("%s:I:ERC721:%s:%s:9999999999999999999:9999:99999", bigtable.chainId, address, FILTER_TIME)
 	}
 
-	transactions, lastKey, err := bigtable.GetEth1ERC721ForAddress(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtable.GetEth1ERC721ForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	tableData := make([][]interface{}, len(transactions))
 	for i, t := range transactions {
@@ -2471,35 +3012,35 @@ func (bigtable *Bigtable) GetAddressErc721TableData(address string, search strin
 			from,
 			to,
 			utils.FormatAddressAsLink(t.TokenAddress, "", false, true),
-			new(big.Int).SetBytes(t.TokenId).String(),
+			utils.FormatNFTTokenId(t.TokenAddress, new(big.Int).SetBytes(t.TokenId)),
 		}
 	}
 
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	truncatePage(ctx, data, pageToken)
+	return data, partial
 }
 
-func (bigtable *Bigtable) GetEth1ERC1155ForAddress(prefix string, limit int64) ([]*types.ETh1ERC1155Indexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1ERC1155ForAddress(ctx context.Context, prefix string, limit int64) ([]*types.ETh1ERC1155Indexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
-
 	data := make([]*types.ETh1ERC1155Indexed, 0, limit)
 
 	keys := make([]string, 0, limit)
 	keysMap := make(map[string]*types.ETh1ERC1155Indexed, limit)
 	indexes := make([]string, 0, limit)
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -2508,38 +3049,43 @@ func (bigtable *Bigtable) GetEth1ERC1155ForAddress(prefix string, limit int64) (
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1ERC1155Indexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.ETh1ERC1155Indexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing ETh1ERC1155Indexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1ERC1155ForAddress")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
 			data = append(data, d)
 		}
 	}
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetAddressErc1155TableData(address string, search string, pageToken string) (*types.DataTableResponse, error) {
+func (bigtable *Bigtable) GetAddressErc1155TableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error) {
 	if pageToken == "" {
 		pageToken = fmt.Sprintf("%s:I:ERC1155:%s:%s:", bigtable.chainId, address, FILTER_TIME)
 	}
 
-	transactions, lastKey, err := bigtable.GetEth1ERC1155ForAddress(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtable.GetEth1ERC1155ForAddress(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	tableData := make([][]interface{}, len(transactions))
 	for i, t := range transactions {
@@ -2557,7 +3103,7 @@ func (bigtable *Bigtable) GetAddressErc1155TableData(address string, search stri
 			from,
 			to,
 			utils.FormatAddressAsLink(t.TokenAddress, "", false, true),
-			new(big.Int).SetBytes(t.TokenId).String(),
+			utils.FormatNFTTokenId(t.TokenAddress, new(big.Int).SetBytes(t.TokenId)),
 			new(big.Int).SetBytes(t.Value).String(),
 		}
 	}
@@ -2565,19 +3111,21 @@ func (bigtable *Bigtable) GetAddressErc1155TableData(address string, search stri
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	truncatePage(ctx, data, pageToken)
+	return data, partial
 }
 
-func (bigtable *Bigtable) GetMetadataUpdates(prefix string, startToken string, limit int) ([]string, []*types.Eth1AddressBalance, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*120))
+func (bigtable *Bigtable) GetMetadataUpdates(ctx context.Context, prefix string, startToken string, limit int) ([]string, []*types.Eth1AddressBalance, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*120))
 	defer cancel()
 
 	keys := make([]string, 0, limit)
 	pairs := make([]*types.Eth1AddressBalance, 0, limit)
 
-	err := bigtable.tableMetadataUpdates.ReadRows(ctx, gcp_bigtable.NewRange(startToken, ""), func(row gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.NewRange(startToken, ""), func(row gcp_bigtable.Row) bool {
 		if !strings.Contains(row.Key(), prefix) {
 			return false
 		}
@@ -2597,14 +3145,14 @@ func (bigtable *Bigtable) GetMetadataUpdates(prefix string, startToken string, l
 	return keys, pairs, err
 }
 
-func (bigtable *Bigtable) GetMetadata(startToken string, limit int) ([]string, []*types.Eth1AddressBalance, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Minute*120))
+func (bigtable *Bigtable) GetMetadata(ctx context.Context, startToken string, limit int) ([]string, []*types.Eth1AddressBalance, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*120))
 	defer cancel()
 
 	keys := make([]string, 0, limit)
 	pairs := make([]*types.Eth1AddressBalance, 0, limit)
 
-	err := bigtable.tableMetadata.ReadRows(ctx, gcp_bigtable.NewRange(startToken, ""), func(row gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, gcp_bigtable.NewRange(startToken, ""), func(row gcp_bigtable.Row) bool {
 		if !strings.HasPrefix(row.Key(), bigtable.chainId+":") {
 			return false
 		}
@@ -2626,11 +3174,11 @@ func (bigtable *Bigtable) GetMetadata(startToken string, limit int) ([]string, [
 	return keys, pairs, err
 }
 
-func (bigtable *Bigtable) GetMetadataForAddress(address []byte) (*types.Eth1AddressMetadata, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetMetadataForAddress(ctx context.Context, address []byte) (*types.Eth1AddressMetadata, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	row, err := bigtable.tableMetadata.ReadRow(ctx, fmt.Sprintf("%s:%x", bigtable.chainId, address))
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address))
 
 	if err != nil {
 		return nil, err
@@ -2668,7 +3216,7 @@ func (bigtable *Bigtable) GetMetadataForAddress(address []byte) (*types.Eth1Addr
 						Balance: column.Value,
 					}
 
-					metadata, err := bigtable.GetERC20MetadataForAddress(token)
+					metadata, err := bigtable.GetERC20MetadataForAddress(ctx, token)
 					if err != nil {
 						return err
 					}
@@ -2697,6 +3245,23 @@ func (bigtable *Bigtable) GetMetadataForAddress(address []byte) (*types.Eth1Addr
 		return nil, err
 	}
 
+	// the metadata of a token is cached for a day, the price snapshots are more recent
+	tokens := make([][]byte, 0, len(ret.Balances))
+	for _, balance := range ret.Balances {
+		tokens = append(tokens, balance.Token)
+	}
+	prices, err := bigtable.GetERC20TokenPrices(ctx, tokens)
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range ret.Balances {
+		if price, ok := prices[string(balance.Token)]; ok {
+			metadata := *balance.Metadata
+			metadata.Price = []byte(price.String())
+			balance.Metadata = &metadata
+		}
+	}
+
 	sort.Slice(ret.Balances, func(i, j int) bool {
 		priceI := decimal.New(0, 0)
 		priceJ := decimal.New(0, 0)
@@ -2727,12 +3292,12 @@ func (bigtable *Bigtable) GetMetadataForAddress(address []byte) (*types.Eth1Addr
 	return ret, nil
 }
 
-func (bigtable *Bigtable) GetBalanceForAddress(address []byte, token []byte) (*types.Eth1AddressBalance, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetBalanceForAddress(ctx context.Context, address []byte, token []byte) (*types.Eth1AddressBalance, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(ACCOUNT_METADATA_FAMILY), gcp_bigtable.ColumnFilter(fmt.Sprintf("B:%x", token)))
-	row, err := bigtable.tableMetadata.ReadRow(ctx, fmt.Sprintf("%s:%x", bigtable.chainId, address), gcp_bigtable.RowFilter(filter))
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address), gcp_bigtable.RowFilter(filter))
 
 	if err != nil {
 		return nil, err
@@ -2752,7 +3317,7 @@ func (bigtable *Bigtable) GetBalanceForAddress(address []byte, token []byte) (*t
 			Balance: row[ACCOUNT_METADATA_FAMILY][0].Value,
 		}
 
-		metadata, err := bigtable.GetERC20MetadataForAddress(token)
+		metadata, err := bigtable.GetERC20MetadataForAddress(ctx, token)
 		if err != nil {
 			return nil, err
 		}
@@ -2764,7 +3329,41 @@ func (bigtable *Bigtable) GetBalanceForAddress(address []byte, token []byte) (*t
 	return nil, fmt.Errorf("ACCOUNT_METADATA_FAMILY is not a valid index in row map")
 }
 
-func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC20Metadata, error) {
+// GetAddressTokenBalances returns the ether and ERC20 balances of an address together with the number of NFTs it holds per collection
+func (bigtable *Bigtable) GetAddressTokenBalances(ctx context.Context, address []byte) (*types.AddressTokenBalances, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	ret := &types.AddressTokenBalances{}
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		metadata, err := bigtable.GetMetadataForAddress(gCtx, address)
+		if err != nil {
+			return err
+		}
+		ret.Ether = metadata.EthBalance
+		ret.ERC20 = metadata.Balances
+		if len(ret.Ether.Token) == 0 {
+			// the address has no ether balance yet
+			ret.Ether.Address = address
+			ret.Ether.Token = []byte{0x0}
+			ret.Ether.Metadata, err = bigtable.GetERC20MetadataForAddress(gCtx, ret.Ether.Token)
+		}
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		ret.NFTs, ret.NFTsComplete, err = bigtable.addressNFTCollections(gCtx, address)
+		return err
+	})
+	err := g.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (bigtable *Bigtable) GetERC20MetadataForAddress(ctx context.Context, address []byte) (*types.ERC20Metadata, error) {
 
 	if len(address) == 1 {
 		return &types.ERC20Metadata{
@@ -2774,7 +3373,7 @@ func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC
 		}, nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
 	defer cancel()
 
 	cacheKey := fmt.Sprintf("%s:ERC20:%s", bigtable.chainId, string(address))
@@ -2785,7 +3384,7 @@ func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC
 	rowKey := fmt.Sprintf("%s:%x", bigtable.chainId, address)
 	filter := gcp_bigtable.FamilyFilter(ERC20_METADATA_FAMILY)
 
-	row, err := bigtable.tableMetadata.ReadRow(ctx, rowKey, gcp_bigtable.RowFilter(filter))
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, rowKey, gcp_bigtable.RowFilter(filter))
 
 	if err != nil {
 		return nil, err
@@ -2809,7 +3408,7 @@ func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC
 			return metadata, nil
 		}
 
-		err = bigtable.SaveERC20Metadata(address, metadata)
+		err = bigtable.SaveERC20Metadata(ctx, address, metadata)
 		if err != nil {
 			return nil, err
 		}
@@ -2853,10 +3452,10 @@ func (bigtable *Bigtable) GetERC20MetadataForAddress(address []byte) (*types.ERC
 	return ret, nil
 }
 
-func (bigtable *Bigtable) SaveERC20Metadata(address []byte, metadata *types.ERC20Metadata) error {
+func (bigtable *Bigtable) SaveERC20Metadata(ctx context.Context, address []byte, metadata *types.ERC20Metadata) error {
 	rowKey := fmt.Sprintf("%s:%x", bigtable.chainId, address)
 
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	mut := gcp_bigtable.NewMutation()
@@ -2889,11 +3488,11 @@ func (bigtable *Bigtable) SaveERC20Metadata(address []byte, metadata *types.ERC2
 		mut.Set(ERC20_METADATA_FAMILY, ERC20_COLUMN_LOGO_FORMAT, gcp_bigtable.Timestamp(0), []byte(metadata.LogoFormat))
 	}
 
-	return bigtable.tableMetadata.Apply(ctx, rowKey, mut)
+	return bigtable.apply(ctx, bigtable.tableMetadata, rowKey, mut)
 }
 
-func (bigtable *Bigtable) GetAddressName(address []byte) (string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetAddressName(ctx context.Context, address []byte) (string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	rowKey := fmt.Sprintf("%s:%x", bigtable.chainId, address)
@@ -2906,11 +3505,20 @@ func (bigtable *Bigtable) GetAddressName(address []byte) (string, error) {
 
 	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(ACCOUNT_METADATA_FAMILY), gcp_bigtable.ColumnFilter(ACCOUNT_COLUMN_NAME))
 
-	row, err := bigtable.tableMetadata.ReadRow(ctx, rowKey, gcp_bigtable.RowFilter(filter))
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, rowKey, gcp_bigtable.RowFilter(filter))
 
 	if err != nil || row == nil {
-		err = cache.TieredCache.SetString(cacheKey, "", time.Hour)
-		return "", err
+		wanted := ""
+		if err == nil {
+			// addresses without a label are shown with their primary ens name
+			names := map[string]string{string(address): ""}
+			if err := bigtable.GetEnsNames(ctx, names); err != nil {
+				logger.Warnf("error retrieving ens name of address 0x%x: %v", address, err)
+			}
+			wanted = names[string(address)]
+		}
+		err = cache.TieredCache.SetString(cacheKey, wanted, time.Hour)
+		return wanted, err
 	}
 
 	wanted := string(row[ACCOUNT_METADATA_FAMILY][0].Value)
@@ -2918,8 +3526,8 @@ func (bigtable *Bigtable) GetAddressName(address []byte) (string, error) {
 	return wanted, err
 }
 
-func (bigtable *Bigtable) GetAddressNames(addresses map[string]string) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetAddressNames(ctx context.Context, addresses map[string]string) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	keys := make([]string, 0, len(addresses))
@@ -2931,33 +3539,87 @@ func (bigtable *Bigtable) GetAddressNames(addresses map[string]string) error {
 	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(ACCOUNT_METADATA_FAMILY), gcp_bigtable.ColumnFilter(ACCOUNT_COLUMN_NAME))
 
 	keyPrefix := fmt.Sprintf("%s:", bigtable.chainId)
-	err := bigtable.tableMetadata.ReadRows(ctx, gcp_bigtable.RowList(keys), func(r gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, gcp_bigtable.RowList(keys), func(r gcp_bigtable.Row) bool {
 		address := strings.TrimPrefix(r.Key(), keyPrefix)
 		addressBytes, _ := hex.DecodeString(address)
 		addresses[string(addressBytes)] = string(r[ACCOUNT_METADATA_FAMILY][0].Value)
 
 		return true
 	}, gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return err
+	}
 
-	return err
+	// addresses without a label are shown with their primary ens name
+	err = bigtable.GetEnsNames(ctx, addresses)
+	if err != nil {
+		logger.Warnf("error retrieving ens names: %v", err)
+	}
+	return nil
 }
 
-func (bigtable *Bigtable) SaveAddressName(address []byte, name string) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+// SaveAddressName saves the name of an address and indexes the address by the slug of the name so that it can be used in urls
+func (bigtable *Bigtable) SaveAddressName(ctx context.Context, address []byte, name string) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(ACCOUNT_METADATA_FAMILY, ACCOUNT_COLUMN_NAME, gcp_bigtable.Timestamp(0), []byte(name))
 
-	return bigtable.tableMetadata.Apply(ctx, fmt.Sprintf("%s:%x", bigtable.chainId, address), mut)
+	err := bigtable.apply(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address), mut)
+	if err != nil {
+		return err
+	}
+
+	slug := utils.AddressLabelSlug(name)
+	if slug == "" {
+		return nil
+	}
+	mut = gcp_bigtable.NewMutation()
+	mut.Set(ACCOUNT_METADATA_FAMILY, ACCOUNT_COLUMN_ADDRESS, gcp_bigtable.Timestamp(0), address)
+
+	return bigtable.apply(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:LABEL:%s", bigtable.chainId, slug), mut)
 }
 
-func (bigtable *Bigtable) GetContractMetadata(address []byte) (*types.ContractMetadata, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+// GetAddressForLabel returns the address whose name has the given slug, nil is returned if no name has that slug
+func (bigtable *Bigtable) GetAddressForLabel(ctx context.Context, slug string) ([]byte, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	rowKey := fmt.Sprintf("%s:LABEL:%s", bigtable.chainId, slug)
+	cacheKey := bigtable.chainId + ":LABEL_ADDRESS:" + slug
+
+	if wanted, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Hour); err == nil {
+		if wanted == "" {
+			return nil, nil
+		}
+		return hex.DecodeString(wanted)
+	}
+
+	filter := gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(ACCOUNT_METADATA_FAMILY), gcp_bigtable.ColumnFilter(ACCOUNT_COLUMN_ADDRESS))
+
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, rowKey, gcp_bigtable.RowFilter(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	var address []byte
+	if row != nil {
+		address = row[ACCOUNT_METADATA_FAMILY][0].Value
+	}
+	err = cache.TieredCache.SetString(cacheKey, hex.EncodeToString(address), time.Hour)
+	if err != nil {
+		logger.Errorf("error caching address of label %v: %v", slug, err)
+	}
+	return address, nil
+}
+
+func (bigtable *Bigtable) GetContractMetadata(ctx context.Context, address []byte) (*types.ContractMetadata, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	rowKey := fmt.Sprintf("%s:%x", bigtable.chainId, address)
-	cacheKey := bigtable.chainId + ":CONTRACT:" + rowKey
+	cacheKey := bigtable.contractMetadataCacheKey(address)
 	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour*24, new(types.ContractMetadata)); err == nil {
 		ret := cached.(*types.ContractMetadata)
 		val, err := abi.JSON(bytes.NewReader(ret.ABIJson))
@@ -2965,7 +3627,12 @@ func (bigtable *Bigtable) GetContractMetadata(address []byte) (*types.ContractMe
 		return ret, err
 	}
 
-	row, err := bigtable.tableMetadata.ReadRow(ctx, rowKey, gcp_bigtable.RowFilter(gcp_bigtable.FamilyFilter(CONTRACT_METADATA_FAMILY)))
+	// the destruction of the contract is kept in the same family but is no source metadata
+	filter := gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(CONTRACT_METADATA_FAMILY),
+		gcp_bigtable.ColumnFilter(fmt.Sprintf("^(%s|%s|%s)$", CONTRACT_NAME, CONTRACT_ABI, CONTRACT_VERIFICATION)),
+	)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, rowKey, gcp_bigtable.RowFilter(filter))
 
 	ret := &types.ContractMetadata{}
 
@@ -3000,7 +3667,7 @@ func (bigtable *Bigtable) GetContractMetadata(address []byte) (*types.ContractMe
 			utils.LogError(err, "Caching contract metadata", 0, map[string]interface{}{"address": fmt.Sprintf("%x", address)})
 		}
 
-		err = bigtable.SaveContractMetadata(address, ret)
+		err = bigtable.SaveContractMetadata(ctx, address, ret)
 		if err != nil {
 			logger.Errorf("error saving contract metadata to bigtable: %v", err)
 		}
@@ -3016,9 +3683,21 @@ func (bigtable *Bigtable) GetContractMetadata(address []byte) (*types.ContractMe
 				val, err := abi.JSON(bytes.NewReader(ret.ABIJson))
 
 				if err != nil {
-					logrus.Fatalf("error decoding abi for address 0x%x: %v", address, err)
+					metrics.BigtableCorruptedRows.WithLabelValues("ContractMetadata").Inc()
+					logger.WithError(err).Errorf("error decoding abi for address 0x%x, ignoring it", address)
+					ret.ABIJson = nil
+					continue
 				}
 				ret.ABI = &val
+			} else if item.Column == CONTRACT_METADATA_FAMILY+":"+CONTRACT_VERIFICATION {
+				verification := &types.ContractVerification{}
+				err := json.Unmarshal(item.Value, verification)
+				if err != nil {
+					metrics.BigtableCorruptedRows.WithLabelValues("ContractMetadata").Inc()
+					logger.WithError(err).Errorf("error decoding source verification for address 0x%x, ignoring it", address)
+					continue
+				}
+				ret.Verification = verification
 			}
 		}
 	}
@@ -3027,18 +3706,29 @@ func (bigtable *Bigtable) GetContractMetadata(address []byte) (*types.ContractMe
 	return ret, err
 }
 
-func (bigtable *Bigtable) SaveContractMetadata(address []byte, metadata *types.ContractMetadata) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) contractMetadataCacheKey(address []byte) string {
+	return fmt.Sprintf("%s:CONTRACT:%s:%x", bigtable.chainId, bigtable.chainId, address)
+}
+
+func (bigtable *Bigtable) SaveContractMetadata(ctx context.Context, address []byte, metadata *types.ContractMetadata) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(CONTRACT_METADATA_FAMILY, CONTRACT_NAME, gcp_bigtable.Timestamp(0), []byte(metadata.Name))
 	mut.Set(CONTRACT_METADATA_FAMILY, CONTRACT_ABI, gcp_bigtable.Timestamp(0), metadata.ABIJson)
+	if metadata.Verification != nil {
+		verification, err := json.Marshal(metadata.Verification)
+		if err != nil {
+			return err
+		}
+		mut.Set(CONTRACT_METADATA_FAMILY, CONTRACT_VERIFICATION, gcp_bigtable.Timestamp(0), verification)
+	}
 
-	return bigtable.tableMetadata.Apply(ctx, fmt.Sprintf("%s:%x", bigtable.chainId, address), mut)
+	return bigtable.apply(ctx, bigtable.tableMetadata, fmt.Sprintf("%s:%x", bigtable.chainId, address), mut)
 }
 
-func (bigtable *Bigtable) SaveBalances(balances []*types.Eth1AddressBalance, deleteKeys []string) error {
+func (bigtable *Bigtable) SaveBalances(ctx context.Context, balances []*types.Eth1AddressBalance, deleteKeys []string) error {
 	if len(balances) == 0 {
 		return nil
 	}
@@ -3056,7 +3746,7 @@ func (bigtable *Bigtable) SaveBalances(balances []*types.Eth1AddressBalance, del
 		mutsWrite.Muts = append(mutsWrite.Muts, mutWrite)
 	}
 
-	err := bigtable.WriteBulk(mutsWrite, bigtable.tableMetadata)
+	err := bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableMetadata)
 
 	if err != nil {
 		return err
@@ -3076,7 +3766,7 @@ func (bigtable *Bigtable) SaveBalances(balances []*types.Eth1AddressBalance, del
 		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
 	}
 
-	err = bigtable.WriteBulk(mutsDelete, bigtable.tableMetadataUpdates)
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableMetadataUpdates)
 
 	if err != nil {
 		return err
@@ -3085,14 +3775,14 @@ func (bigtable *Bigtable) SaveBalances(balances []*types.Eth1AddressBalance, del
 	return nil
 }
 
-func (bigtable *Bigtable) SaveERC20TokenPrices(prices []*types.ERC20TokenPrice) error {
+func (bigtable *Bigtable) SaveERC20TokenPrices(ctx context.Context, prices []*types.ERC20TokenPrice) error {
 	if len(prices) == 0 {
 		return nil
 	}
 
 	mutsWrite := &types.BulkMutations{
-		Keys: make([]string, 0, len(prices)),
-		Muts: make([]*gcp_bigtable.Mutation, 0, len(prices)),
+		Keys: make([]string, 0, len(prices)*3),
+		Muts: make([]*gcp_bigtable.Mutation, 0, len(prices)*3),
 	}
 
 	for _, price := range prices {
@@ -3102,9 +3792,30 @@ func (bigtable *Bigtable) SaveERC20TokenPrices(prices []*types.ERC20TokenPrice)
 		mut.Set(ERC20_METADATA_FAMILY, ERC20_COLUMN_TOTALSUPPLY, gcp_bigtable.Timestamp(0), price.TotalSupply)
 		mutsWrite.Keys = append(mutsWrite.Keys, rowKey)
 		mutsWrite.Muts = append(mutsWrite.Muts, mut)
+
+		// keep a snapshot of every price update so that the price history of a token can be retrieved
+		// 1:PRICE:<token>:<reversed timestamp>
+		ts := price.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		snapshotKey := fmt.Sprintf("%s:PRICE:%x:%s", bigtable.chainId, price.Token, reversePaddedBigtableTimestamp(timestamppb.New(ts)))
+		snapshotMut := gcp_bigtable.NewMutation()
+		snapshotMut.Set(ERC20_METADATA_FAMILY, ERC20_COLUMN_PRICE, gcp_bigtable.Timestamp(0), price.Price)
+		mutsWrite.Keys = append(mutsWrite.Keys, snapshotKey)
+		mutsWrite.Muts = append(mutsWrite.Muts, snapshotMut)
+
+		// the latest price of a UTC day is its close, older updates of the day are replaced
+		// 1:PRICE_CLOSE:<token>:<reversed day>
+		closeTs := gcp_bigtable.Time(ts).TruncateToMilliseconds()
+		closeMut := gcp_bigtable.NewMutation()
+		closeMut.DeleteTimestampRange(ERC20_METADATA_FAMILY, ERC20_COLUMN_PRICE, 0, closeTs)
+		closeMut.Set(ERC20_METADATA_FAMILY, ERC20_COLUMN_PRICE, closeTs, price.Price)
+		mutsWrite.Keys = append(mutsWrite.Keys, bigtable.erc20ClosePriceKey(price.Token, ts))
+		mutsWrite.Muts = append(mutsWrite.Muts, closeMut)
 	}
 
-	err := bigtable.WriteBulk(mutsWrite, bigtable.tableMetadata)
+	err := bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableMetadata)
 
 	if err != nil {
 		return err
@@ -3113,65 +3824,167 @@ func (bigtable *Bigtable) SaveERC20TokenPrices(prices []*types.ERC20TokenPrice)
 	return nil
 }
 
-func (bigtable *Bigtable) SaveBlockKeys(blockNumber uint64, blockHash []byte, keys string) error {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) erc20ClosePriceKey(token []byte, ts time.Time) string {
+	day := ts.UTC().Truncate(time.Hour * 24)
+	return fmt.Sprintf("%s:PRICE_CLOSE:%x:%s", bigtable.chainId, token, reversePaddedBigtableTimestamp(timestamppb.New(day)))
+}
+
+// getERC20TokenClosePrices returns the USD close prices of the given rows of erc20ClosePriceKey, keyed by the row key. Days
+// without a price update are omitted from the result.
+func (bigtable *Bigtable) getERC20TokenClosePrices(ctx context.Context, keys []string) (map[string]decimal.Decimal, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	prices := make(map[string]decimal.Decimal, len(keys))
+	if len(keys) == 0 {
+		return prices, nil
+	}
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		for _, item := range row[ERC20_METADATA_FAMILY] {
+			if item.Column != ERC20_METADATA_FAMILY+":"+ERC20_COLUMN_PRICE {
+				continue
+			}
+			price, err := decimal.NewFromString(string(item.Value))
+			if err != nil {
+				logger.Errorf("error parsing close price %s of row %v: %v", item.Value, row.Key(), err)
+				continue
+			}
+			prices[row.Key()] = price
+		}
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)))
+	if err != nil {
+		return nil, err
+	}
+	return prices, nil
+}
+
+// GetERC20TokenPrices returns the latest USD price snapshot for each of the given tokens, keyed by the raw token address.
+// Tokens without a known price are omitted from the result.
+func (bigtable *Bigtable) GetERC20TokenPrices(ctx context.Context, tokens [][]byte) (map[string]decimal.Decimal, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	prices := make(map[string]decimal.Decimal, len(tokens))
+	mux := sync.Mutex{}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(10)
+	for _, token := range tokens {
+		if len(token) != 20 {
+			continue
+		}
+		token := token
+		g.Go(func() error {
+			cacheKey := fmt.Sprintf("%s:ERC20PRICE:%x", bigtable.chainId, token)
+			priceS, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Minute*5)
+			if err != nil {
+				priceS = ""
+				prefix := fmt.Sprintf("%s:PRICE:%x:", bigtable.chainId, token)
+				err = bigtable.readRows(gCtx, bigtable.tableMetadata, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+					for _, item := range row[ERC20_METADATA_FAMILY] {
+						if item.Column == ERC20_METADATA_FAMILY+":"+ERC20_COLUMN_PRICE {
+							priceS = string(item.Value)
+						}
+					}
+					return false
+				}, gcp_bigtable.LimitRows(1))
+				if err != nil {
+					return err
+				}
+
+				err = cache.TieredCache.SetString(cacheKey, priceS, time.Minute*10)
+				if err != nil {
+					logger.Errorf("error caching price of token %x: %v", token, err)
+				}
+			}
+
+			if priceS == "" {
+				return nil
+			}
+			price, err := decimal.NewFromString(priceS)
+			if err != nil {
+				logger.Errorf("error parsing price %v of token %x: %v", priceS, token, err)
+				return nil
+			}
+
+			mux.Lock()
+			prices[string(token)] = price
+			mux.Unlock()
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+func (bigtable *Bigtable) SaveBlockKeys(ctx context.Context, blockNumber uint64, blockHash []byte, keys string) error {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(METADATA_UPDATES_FAMILY_BLOCKS, "keys", gcp_bigtable.Timestamp(0), []byte(keys))
 
 	key := fmt.Sprintf("%s:BLOCK:%s:%x", bigtable.chainId, reversedPaddedBlockNumber(blockNumber), blockHash)
-	err := bigtable.tableMetadataUpdates.Apply(ctx, key, mut)
+	err := bigtable.apply(ctx, bigtable.tableMetadataUpdates, key, mut)
 
 	return err
 }
 
-func (bigtable *Bigtable) GetBlockKeys(blockNumber uint64, blockHash []byte) ([]string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetBlockKeys(ctx context.Context, blockNumber uint64, blockHash []byte) ([]string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	key := fmt.Sprintf("%s:BLOCK:%s:%x", bigtable.chainId, reversedPaddedBlockNumber(blockNumber), blockHash)
 
-	row, err := bigtable.tableMetadataUpdates.ReadRow(ctx, key)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadataUpdates, key)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if row == nil {
-		return nil, fmt.Errorf("keys for block %v not found", blockNumber)
+		return nil, fmt.Errorf("%w: block %v (0x%x)", ErrBlockKeysNotFound, blockNumber, blockHash)
 	}
 
 	return strings.Split(string(row[METADATA_UPDATES_FAMILY_BLOCKS][0].Value), ","), nil
 }
 
 // Deletes all block data from bigtable
-func (bigtable *Bigtable) DeleteBlock(blockNumber uint64, blockHash []byte) error {
+func (bigtable *Bigtable) DeleteBlock(ctx context.Context, blockNumber uint64, blockHash []byte) error {
 
 	// First receive all keys that were written by this block (entities & indices)
-	keys, err := bigtable.GetBlockKeys(blockNumber, blockHash)
+	keys, err := bigtable.GetBlockKeys(ctx, blockNumber, blockHash)
 	if err != nil {
 		return err
 	}
 
-	// Delete all of those keys
-	mutsDelete := &types.BulkMutations{
-		Keys: make([]string, 0, len(keys)),
-		Muts: make([]*gcp_bigtable.Mutation, 0, len(keys)),
+	// the cells of the block in aggregate rows are derived from its transactions
+	block, err := bigtable.readBlock(ctx, blockNumber)
+	if err != nil {
+		return err
 	}
-	for _, key := range keys {
-		mutDelete := gcp_bigtable.NewMutation()
-		mutDelete.DeleteRow()
-		mutsDelete.Keys = append(mutsDelete.Keys, key)
-		mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
+	if !bytes.Equal(block.GetHash(), blockHash) {
+		return fmt.Errorf("block %v in the blocks table has hash 0x%x instead of 0x%x", blockNumber, block.GetHash(), blockHash)
 	}
 
-	err = bigtable.WriteBulk(mutsDelete, bigtable.tableData)
+	return bigtable.deleteBlockRows(ctx, block, keys)
+}
+
+// deleteBlockRows tombstones the given data rows of a block and deletes the block itself from the blocks table
+func (bigtable *Bigtable) deleteBlockRows(ctx context.Context, block *types.Eth1Block, keys []string) error {
+	blockNumber := block.GetNumber()
+	err := bigtable.tombstoneBlockRows(ctx, block, keys)
 	if err != nil {
 		return err
 	}
 
-	mutsDelete = &types.BulkMutations{
+	mutsDelete := &types.BulkMutations{
 		Keys: make([]string, 0, len(keys)),
 		Muts: make([]*gcp_bigtable.Mutation, 0, len(keys)),
 	}
@@ -3179,7 +3992,7 @@ func (bigtable *Bigtable) DeleteBlock(blockNumber uint64, blockHash []byte) erro
 	mutDelete.DeleteRow()
 	mutsDelete.Keys = append(mutsDelete.Keys, fmt.Sprintf("%s:%s", bigtable.chainId, reversedPaddedBlockNumber(blockNumber)))
 	mutsDelete.Muts = append(mutsDelete.Muts, mutDelete)
-	err = bigtable.WriteBulk(mutsDelete, bigtable.tableBlocks)
+	err = bigtable.WriteBulk(ctx, mutsDelete, bigtable.tableBlocks)
 	if err != nil {
 		return err
 	}
@@ -3187,22 +4000,21 @@ func (bigtable *Bigtable) DeleteBlock(blockNumber uint64, blockHash []byte) erro
 	return nil
 }
 
-func (bigtable *Bigtable) GetEth1TxForToken(prefix string, limit int64) ([]*types.Eth1ERC20Indexed, string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetEth1TxForToken(ctx context.Context, prefix string, limit int64) ([]*types.Eth1ERC20Indexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
-	// add \x00 to the row range such that we skip the previous value
-	rowRange := gcp_bigtable.NewRange(prefix+"\x00", prefixSuccessor(prefix, 5))
 	data := make([]*types.Eth1ERC20Indexed, 0, limit)
 	keys := make([]string, 0, limit)
 	indexes := make([]string, 0, limit)
 	keysMap := make(map[string]*types.Eth1ERC20Indexed, limit)
 
-	err := bigtable.tableData.ReadRows(ctx, rowRange, func(row gcp_bigtable.Row) bool {
+	// add \x00 to the row range such that we skip the previous value
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 5), limit, func(row gcp_bigtable.Row) bool {
 		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
 		indexes = append(indexes, row.Key())
 		return true
-	}, gcp_bigtable.LimitRows(limit))
+	})
 	if err != nil {
 		return nil, "", err
 	}
@@ -3211,21 +4023,25 @@ func (bigtable *Bigtable) GetEth1TxForToken(prefix string, limit int64) ([]*type
 		return data, "", nil
 	}
 
-	err = bigtable.tableData.ReadRows(ctx, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+	skipped := newSkippedRows("Eth1ERC20Indexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
 		b := &types.Eth1ERC20Indexed{}
 		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
 
 		if err != nil {
-			logrus.Fatalf("error parsing Eth1ERC20Indexed data: %v", err)
+			skipped.corrupt(row.Key(), err)
+			return true
 		}
 		keysMap[row.Key()] = b
 
 		return true
-	})
+	}, skipTombstones())
 	if err != nil {
 		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_eth1 / GetEth1TxForToken")
 		return nil, "", err
 	}
+	skipped.checkMissing(keys)
 
 	for _, key := range keys {
 		if d := keysMap[key]; d != nil {
@@ -3233,10 +4049,10 @@ func (bigtable *Bigtable) GetEth1TxForToken(prefix string, limit int64) ([]*type
 		}
 	}
 
-	return data, indexes[len(indexes)-1], nil
+	return data, indexes[len(indexes)-1], skipped.err()
 }
 
-func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []byte, pageToken string) (*types.DataTableResponse, error) {
+func (bigtable *Bigtable) GetTokenTransactionsTableData(ctx context.Context, token []byte, address []byte, pageToken string) (*types.DataTableResponse, error) {
 	if pageToken == "" {
 		if len(address) == 0 {
 			pageToken = fmt.Sprintf("%s:I:ERC20:%x:ALL:%s", bigtable.chainId, token, FILTER_TIME)
@@ -3245,10 +4061,11 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 		}
 	}
 
-	transactions, lastKey, err := BigtableClient.GetEth1TxForToken(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtable.GetEth1TxForToken(ctx, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
 		return nil, err
 	}
+	partial := err
 
 	names := make(map[string]string)
 	tokens := make(map[string]*types.ERC20Metadata)
@@ -3257,7 +4074,24 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 		names[string(t.To)] = ""
 		tokens[string(t.TokenAddress)] = nil
 	}
-	names, tokens, err = BigtableClient.GetAddressesNamesArMetadata(&names, &tokens)
+	names, tokens, err = bigtable.GetAddressesNamesArMetadata(ctx, &names, &tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenAddresses := make([][]byte, 0, len(tokens))
+	for token := range tokens {
+		tokenAddresses = append(tokenAddresses, []byte(token))
+	}
+	prices, err := bigtable.GetERC20TokenPrices(ctx, tokenAddresses)
+	if err != nil {
+		return nil, err
+	}
+	closeKeys := make([]string, 0, len(transactions))
+	for _, t := range transactions {
+		closeKeys = append(closeKeys, bigtable.erc20ClosePriceKey(t.TokenAddress, t.Time.AsTime()))
+	}
+	closePrices, err := bigtable.getERC20TokenClosePrices(ctx, closeKeys)
 	if err != nil {
 		return nil, err
 	}
@@ -3277,6 +4111,8 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 			Token:    t.TokenAddress,
 			Metadata: tokens[string(t.TokenAddress)],
 		}
+		closePrice := closePrices[bigtable.erc20ClosePriceKey(t.TokenAddress, t.Time.AsTime())]
+		value := utils.FormatTokenValue(tb) + utils.FormatTokenUSDValueAt(tb, prices[string(t.TokenAddress)], closePrice, t.Time.AsTime())
 
 		tableData[i] = []interface{}{
 			utils.FormatTransactionHash(t.ParentHash),
@@ -3284,7 +4120,7 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 			from,
 			utils.FormatInOutSelf(address, t.From, t.To),
 			to,
-			utils.FormatTokenValue(tb),
+			value,
 		}
 
 	}
@@ -3292,20 +4128,21 @@ func (bigtable *Bigtable) GetTokenTransactionsTableData(token []byte, address []
 	data := &types.DataTableResponse{
 		Data:        tableData,
 		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
 	}
 
-	return data, nil
+	return data, partial
 }
 
-func (bigtable *Bigtable) SearchForAddress(addressPrefix []byte, limit int) ([]*types.Eth1AddressSearchItem, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) SearchForAddress(ctx context.Context, addressPrefix []byte, limit int) ([]*types.Eth1AddressSearchItem, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
 
 	data := make([]*types.Eth1AddressSearchItem, 0, limit)
 
 	prefix := fmt.Sprintf("%s:%x", bigtable.chainId, addressPrefix)
 
-	err := bigtable.tableMetadata.ReadRows(ctx, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
 		si := &types.Eth1AddressSearchItem{
 			Address: strings.TrimPrefix(row.Key(), bigtable.chainId+":"),
 			Name:    "",
@@ -3341,11 +4178,11 @@ func getSignaturePrefix(st types.SignatureType) string {
 }
 
 // Get the status of the last signature import run
-func (bigtable *Bigtable) GetSignatureImportStatus(st types.SignatureType) (*types.SignatureImportStatus, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetSignatureImportStatus(ctx context.Context, st types.SignatureType) (*types.SignatureImportStatus, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
-	key := fmt.Sprintf("1:%v_SIGNATURE_IMPORT_STATUS", getSignaturePrefix(st))
-	row, err := bigtable.tableData.ReadRow(ctx, key)
+	key := fmt.Sprintf("%s:%v_SIGNATURE_IMPORT_STATUS", signatureChainId, getSignaturePrefix(st))
+	row, err := bigtable.readRow(ctx, bigtable.tableData, key)
 	if err != nil {
 		logrus.Errorf("error reading signature imoprt status row %v: %v", row.Key(), err)
 		return nil, err
@@ -3365,7 +4202,7 @@ func (bigtable *Bigtable) GetSignatureImportStatus(st types.SignatureType) (*typ
 }
 
 // Save the status of the last signature import run
-func (bigtable *Bigtable) SaveSignatureImportStatus(status types.SignatureImportStatus, st types.SignatureType) error {
+func (bigtable *Bigtable) SaveSignatureImportStatus(ctx context.Context, status types.SignatureImportStatus, st types.SignatureType) error {
 
 	mutsWrite := &types.BulkMutations{
 		Keys: make([]string, 0, 1),
@@ -3380,12 +4217,12 @@ func (bigtable *Bigtable) SaveSignatureImportStatus(status types.SignatureImport
 	mut := gcp_bigtable.NewMutation()
 	mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), s)
 
-	key := fmt.Sprintf("1:%v_SIGNATURE_IMPORT_STATUS", getSignaturePrefix(st))
+	key := fmt.Sprintf("%s:%v_SIGNATURE_IMPORT_STATUS", signatureChainId, getSignaturePrefix(st))
 
 	mutsWrite.Keys = append(mutsWrite.Keys, key)
 	mutsWrite.Muts = append(mutsWrite.Muts, mut)
 
-	err = bigtable.WriteBulk(mutsWrite, bigtable.tableData)
+	err = bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableData)
 
 	if err != nil {
 		return err
@@ -3395,7 +4232,7 @@ func (bigtable *Bigtable) SaveSignatureImportStatus(status types.SignatureImport
 }
 
 // Save a list of signatures
-func (bigtable *Bigtable) SaveSignatures(signatures []types.Signature, st types.SignatureType) error {
+func (bigtable *Bigtable) SaveSignatures(ctx context.Context, signatures []types.Signature, st types.SignatureType) error {
 
 	mutsWrite := &types.BulkMutations{
 		Keys: make([]string, 0, 1),
@@ -3406,13 +4243,13 @@ func (bigtable *Bigtable) SaveSignatures(signatures []types.Signature, st types.
 		mut := gcp_bigtable.NewMutation()
 		mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), []byte(sig.Text))
 
-		key := fmt.Sprintf("1:%v_SIGNATURE:%v", getSignaturePrefix(st), sig.Hex)
+		key := fmt.Sprintf("%s:%v_SIGNATURE:%v", signatureChainId, getSignaturePrefix(st), sig.Hex)
 
 		mutsWrite.Keys = append(mutsWrite.Keys, key)
 		mutsWrite.Muts = append(mutsWrite.Muts, mut)
 	}
 
-	err := bigtable.WriteBulk(mutsWrite, bigtable.tableData)
+	err := bigtable.WriteBulk(ctx, mutsWrite, bigtable.tableData)
 
 	if err != nil {
 		return err
@@ -3422,11 +4259,11 @@ func (bigtable *Bigtable) SaveSignatures(signatures []types.Signature, st types.
 }
 
 // get a signature by it's hex representation
-func (bigtable *Bigtable) GetSignature(hex string, st types.SignatureType) (*string, error) {
-	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(time.Second*30))
+func (bigtable *Bigtable) GetSignature(ctx context.Context, hex string, st types.SignatureType) (*string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
 	defer cancel()
-	key := fmt.Sprintf("1:%v_SIGNATURE:%v", getSignaturePrefix(st), hex)
-	row, err := bigtable.tableData.ReadRow(ctx, key)
+	key := fmt.Sprintf("%s:%v_SIGNATURE:%v", signatureChainId, getSignaturePrefix(st), hex)
+	row, err := bigtable.readRow(ctx, bigtable.tableData, key)
 	if err != nil {
 		logrus.Errorf("error reading signature imoprt status row %v: %v", row.Key(), err)
 		return nil, err
@@ -3440,14 +4277,14 @@ func (bigtable *Bigtable) GetSignature(hex string, st types.SignatureType) (*str
 }
 
 // get a method label for its byte signature with defaults
-func (bigtable *Bigtable) GetMethodLabel(id []byte, invokesContract bool) string {
+func (bigtable *Bigtable) GetMethodLabel(ctx context.Context, id []byte, invokesContract bool) string {
 	method := "Transfer"
 	if len(id) > 0 {
 		if invokesContract {
 			method = fmt.Sprintf("0x%x", id)
 			cacheKey := fmt.Sprintf("M:H2L:%s", method)
 			if _, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, &method); err != nil {
-				sig, err := bigtable.GetSignature(method, types.MethodSignature)
+				sig, err := bigtable.GetSignature(ctx, method, types.MethodSignature)
 				if err == nil {
 					if sig != nil {
 						reg := regexp.MustCompile(`\((?:[^)(]+|\((?:[^)(]+|\([^)(]*\))*\))*\)`)
@@ -3464,13 +4301,13 @@ func (bigtable *Bigtable) GetMethodLabel(id []byte, invokesContract bool) string
 }
 
 // get an event label for its byte signature with defaults
-func (bigtable *Bigtable) GetEventLabel(id []byte) string {
+func (bigtable *Bigtable) GetEventLabel(ctx context.Context, id []byte) string {
 	label := ""
 	if len(id) > 0 {
 		event := fmt.Sprintf("0x%x", id)
 		cacheKey := fmt.Sprintf("E:H2L:%s", event)
 		if _, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Hour, &label); err != nil {
-			sig, err := bigtable.GetSignature(event, types.EventSignature)
+			sig, err := bigtable.GetSignature(ctx, event, types.EventSignature)
 			if err == nil {
 				if sig != nil {
 					label = *sig
@@ -3482,6 +4319,19 @@ func (bigtable *Bigtable) GetEventLabel(id []byte) string {
 	return label
 }
 
+// rowIndexFromKey parses the reverse padded index (see reversePaddedIndex with a max of 100000) at part pos of a row key
+func rowIndexFromKey(key string, pos int) (int, error) {
+	parts := strings.Split(key, ":")
+	if len(parts) <= pos {
+		return 0, fmt.Errorf("row key %v has no part %v", key, pos)
+	}
+	reversed, err := strconv.Atoi(parts[pos])
+	if err != nil {
+		return 0, fmt.Errorf("error parsing index of row key %v: %w", key, err)
+	}
+	return 100000 - reversed, nil
+}
+
 func prefixSuccessor(prefix string, pos int) string {
 	if prefix == "" {
 		return "" // infinite range
@@ -3522,8 +4372,8 @@ var (
 	GASNOW_SLOW_COLUMN     = "SLOW"
 )
 
-func (bigtable *Bigtable) SaveGasNowHistory(slow, standard, rapid, fast *big.Int) error {
-	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+func (bigtable *Bigtable) SaveGasNowHistory(ctx context.Context, slow, standard, rapid, fast *big.Int) error {
+	ctx, done := context.WithTimeout(ctx, time.Second*30)
 	defer done()
 
 	ts := time.Now().Truncate(time.Minute)
@@ -3537,15 +4387,15 @@ func (bigtable *Bigtable) SaveGasNowHistory(slow, standard, rapid, fast *big.Int
 	mut.Set(SERIES_FAMILY, GASNOW_FAST_COLUMN, gcpTs, fast.Bytes())
 	mut.Set(SERIES_FAMILY, GASNOW_RAPID_COLUMN, gcpTs, rapid.Bytes())
 
-	err := bigtable.tableMetadata.Apply(ctx, row, mut)
+	err := bigtable.apply(ctx, bigtable.tableMetadata, row, mut)
 	if err != nil {
 		return fmt.Errorf("error saving gas now history to bigtable. err: %w", err)
 	}
 	return nil
 }
 
-func (bigtable *Bigtable) GetGasNowHistory(ts, pastTs time.Time) ([]types.GasNowHistory, error) {
-	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+func (bigtable *Bigtable) GetGasNowHistory(ctx context.Context, ts, pastTs time.Time) ([]types.GasNowHistory, error) {
+	ctx, done := context.WithTimeout(ctx, time.Second*30)
 	defer done()
 
 	start := fmt.Sprintf("%s:GASNOW:%s", bigtable.chainId, reversePaddedBigtableTimestamp(timestamppb.New(ts)))
@@ -3573,7 +4423,7 @@ func (bigtable *Bigtable) GetGasNowHistory(ts, pastTs time.Time) ([]types.GasNow
 		return true
 	}
 
-	err := bigtable.tableMetadata.ReadRows(ctx, rowRange, scanner, filter)
+	err := bigtable.readRows(ctx, bigtable.tableMetadata, rowRange, scanner, filter)
 	if err != nil {
 		return nil, fmt.Errorf("error getting gas now history to bigtable, err: %w", err)
 	}
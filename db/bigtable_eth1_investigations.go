@@ -0,0 +1,297 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// readIndexedKeys returns the data keys referenced by the index rows within the row range [start, end), archived index rows included.
+// At most limit rows are read, truncated is set if the range contains more rows
+func (bigtable *Bigtable) readIndexedKeys(ctx context.Context, start, end string, limit int64) (keys []string, truncated bool, err error) {
+	keys = make([]string, 0, limit)
+
+	err = bigtable.readIndexRows(ctx, start, end, limit+1, func(row gcp_bigtable.Row) bool {
+		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if int64(len(keys)) > limit {
+		return keys[:limit], true, nil
+	}
+	return keys, false, nil
+}
+
+// HasAddressActivityBefore returns whether any transaction or internal transaction of an address older than before has been indexed
+func (bigtable *Bigtable) HasAddressActivityBefore(ctx context.Context, address []byte, before time.Time) (bool, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	for _, prefix := range []string{
+		fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, address, FILTER_TIME),
+		fmt.Sprintf("%s:I:ITX:%x:%s:", bigtable.chainId, address, FILTER_TIME),
+	} {
+		// the time index is sorted by reversed timestamps, so everything older than before sorts after its reversed timestamp
+		start := prefix + reversePaddedBigtableTimestamp(timestamppb.New(before))
+		keys, _, err := bigtable.readIndexedKeys(ctx, start, prefixSuccessor(prefix, 5), 1)
+		if err != nil {
+			return false, err
+		}
+		if len(keys) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// transferPathEdgeLimit is the maximum number of outgoing transactions and token transfers read per address during a path search,
+// at most transferPathScanLimit index rows of each kind are scanned to find them
+const (
+	transferPathEdgeLimit = 500
+	transferPathScanLimit = 10000
+)
+
+type transferPathNode struct {
+	address []byte
+	parent  *transferPathNode
+	hop     *types.TransferPathHop
+	depth   int
+}
+
+func (n *transferPathNode) path() []*types.TransferPathHop {
+	path := make([]*types.TransferPathHop, n.depth)
+	for cur := n; cur.parent != nil; cur = cur.parent {
+		path[cur.depth-1] = cur.hop
+	}
+	return path
+}
+
+type transferEdge struct {
+	to  []byte
+	hop *types.TransferPathHop
+}
+
+// scanIndexedKeys reads the index rows of the range [start, end) page by page and passes the referenced data keys to read, which returns
+// the number of keys it accepted. The scan stops once limit keys have been accepted, truncated is set if the range may hold more
+// accepted keys than read.
+func (bigtable *Bigtable) scanIndexedKeys(ctx context.Context, start, end string, limit int, read func(keys []string) (int, error)) (truncated bool, err error) {
+	accepted, scanned := 0, 0
+	for accepted < limit {
+		if scanned >= transferPathScanLimit {
+			return true, nil
+		}
+		lastKey := ""
+		keys := make([]string, 0, transferPathEdgeLimit)
+		err := bigtable.readIndexRows(ctx, start, end, transferPathEdgeLimit, func(row gcp_bigtable.Row) bool {
+			lastKey = row.Key()
+			keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
+			return true
+		})
+		if err != nil {
+			return false, err
+		}
+		if len(keys) > 0 {
+			n, err := read(keys)
+			if err != nil {
+				return false, err
+			}
+			accepted += n
+			scanned += len(keys)
+		}
+		if len(keys) < transferPathEdgeLimit {
+			return accepted > limit, nil
+		}
+		start = lastKey + "\x00"
+	}
+	return true, nil
+}
+
+// transferPathTimes returns the times of the first and the last block of a path search, a zero time is returned for blocks that
+// have not been indexed
+func (bigtable *Bigtable) transferPathTimes(ctx context.Context, fromBlock, toBlock uint64) (from, to time.Time, err error) {
+	blocks, err := bigtable.GetBlocksIndexedMultiple(ctx, []uint64{toBlock, fromBlock}, 2)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	for _, block := range blocks {
+		if block.GetNumber() == fromBlock {
+			from = block.GetTime().AsTime()
+		}
+		if block.GetNumber() == toBlock {
+			to = block.GetTime().AsTime()
+		}
+	}
+	return from, to, nil
+}
+
+// getOutgoingTransfers returns up to transferPathEdgeLimit successful ether transactions with a value and ERC20 transfers sent by an
+// address within the given block range, the most recent ones first. The transactions are read from the BLOCK index of the address,
+// the token transfers from its TIME index bounded by the times of the search range.
+func (bigtable *Bigtable) getOutgoingTransfers(ctx context.Context, address []byte, fromBlock, toBlock uint64, fromTime, toTime time.Time) ([]*transferEdge, bool, error) {
+	if toBlock > max_block_number {
+		toBlock = max_block_number
+	}
+	txEdges := make([]*transferEdge, 0)
+	txPrefix := fmt.Sprintf("%s:I:TX:%x:%s:", bigtable.chainId, address, FILTER_BLOCK)
+	txTruncated, err := bigtable.scanIndexedKeys(ctx, txPrefix+reversedPaddedBlockNumber(toBlock), txPrefix+reversedPaddedBlockNumber(fromBlock)+";", transferPathEdgeLimit, func(keys []string) (int, error) {
+		accepted := 0
+		var unmarshalErr error
+		err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+			tx := &types.Eth1TransactionIndexed{}
+			unmarshalErr = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, tx)
+			if unmarshalErr != nil {
+				return false
+			}
+			value := new(big.Int).SetBytes(tx.Value)
+			// the block index also contains the transactions the address received
+			if !bytes.Equal(tx.From, address) || tx.BlockNumber < fromBlock || tx.BlockNumber > toBlock || tx.ErrorMsg != "" || len(tx.To) == 0 || value.Sign() == 0 {
+				return true
+			}
+			accepted++
+			txEdges = append(txEdges, &transferEdge{
+				to: tx.To,
+				hop: &types.TransferPathHop{
+					From:        fmt.Sprintf("0x%x", tx.From),
+					To:          fmt.Sprintf("0x%x", tx.To),
+					TxHash:      fmt.Sprintf("0x%x", tx.Hash),
+					BlockNumber: tx.BlockNumber,
+					Value:       value.String(),
+				},
+			})
+			return true
+		}, skipTombstones())
+		if err != nil {
+			return 0, fmt.Errorf("error reading transactions of address %x: %w", address, err)
+		}
+		if unmarshalErr != nil {
+			return 0, fmt.Errorf("error parsing Eth1TransactionIndexed data: %w", unmarshalErr)
+		}
+		return accepted, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	erc20Edges := make([]*transferEdge, 0)
+	erc20Prefix := fmt.Sprintf("%s:I:ERC20:%x:%s:", bigtable.chainId, address, FILTER_TIME)
+	// the time index is sorted by reversed timestamps, the newest transfers come first
+	start, end := erc20Prefix, prefixSuccessor(erc20Prefix, 5)
+	if !toTime.IsZero() {
+		start = erc20Prefix + reversePaddedBigtableTimestamp(timestamppb.New(toTime))
+	}
+	if !fromTime.IsZero() {
+		end = erc20Prefix + reversePaddedBigtableTimestamp(timestamppb.New(fromTime)) + ";"
+	}
+	erc20Truncated, err := bigtable.scanIndexedKeys(ctx, start, end, transferPathEdgeLimit, func(keys []string) (int, error) {
+		accepted := 0
+		var unmarshalErr error
+		err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+			transfer := &types.Eth1ERC20Indexed{}
+			unmarshalErr = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, transfer)
+			if unmarshalErr != nil {
+				return false
+			}
+			// the time index also contains the transfers the address received
+			if !bytes.Equal(transfer.From, address) || transfer.BlockNumber < fromBlock || transfer.BlockNumber > toBlock {
+				return true
+			}
+			accepted++
+			erc20Edges = append(erc20Edges, &transferEdge{
+				to: transfer.To,
+				hop: &types.TransferPathHop{
+					From:        fmt.Sprintf("0x%x", transfer.From),
+					To:          fmt.Sprintf("0x%x", transfer.To),
+					TxHash:      fmt.Sprintf("0x%x", transfer.ParentHash),
+					BlockNumber: transfer.BlockNumber,
+					Token:       fmt.Sprintf("0x%x", transfer.TokenAddress),
+					Value:       new(big.Int).SetBytes(transfer.Value).String(),
+				},
+			})
+			return true
+		}, skipTombstones())
+		if err != nil {
+			return 0, fmt.Errorf("error reading token transfers of address %x: %w", address, err)
+		}
+		if unmarshalErr != nil {
+			return 0, fmt.Errorf("error parsing Eth1ERC20Indexed data: %w", unmarshalErr)
+		}
+		return accepted, nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(txEdges) > transferPathEdgeLimit {
+		txEdges = txEdges[:transferPathEdgeLimit]
+	}
+	if len(erc20Edges) > transferPathEdgeLimit {
+		erc20Edges = erc20Edges[:transferPathEdgeLimit]
+	}
+	return append(txEdges, erc20Edges...), txTruncated || erc20Truncated, nil
+}
+
+// FindTransferPaths runs a breadth first search over the outgoing ether and token transfers starting at the source address and returns
+// the paths that end at the destination address. Every hop of a path happens in the same or a later block than the previous hop.
+// The search is bounded by the max depth of the search, the number of expanded addresses and the number of found paths,
+// Truncated is set on the result if the search has been cut short by one of these limits.
+func (bigtable *Bigtable) FindTransferPaths(ctx context.Context, search *types.TransferPathSearch, maxAddresses int, maxPaths int) (*types.TransferPathResult, error) {
+	result := &types.TransferPathResult{Paths: make([][]*types.TransferPathHop, 0)}
+	fromTime, toTime, err := bigtable.transferPathTimes(ctx, search.FromBlock, search.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	visited := map[string]bool{string(search.Source): true}
+	frontier := []*transferPathNode{{address: search.Source}}
+
+	for depth := 1; depth <= search.MaxDepth && len(frontier) > 0; depth++ {
+		next := make([]*transferPathNode, 0)
+		for _, node := range frontier {
+			if result.VisitedAddresses >= maxAddresses {
+				result.Truncated = true
+				return result, nil
+			}
+			result.VisitedAddresses++
+
+			fromBlock := search.FromBlock
+			if node.hop != nil && node.hop.BlockNumber > fromBlock {
+				fromBlock = node.hop.BlockNumber
+			}
+			edges, truncated, err := bigtable.getOutgoingTransfers(ctx, node.address, fromBlock, search.ToBlock, fromTime, toTime)
+			if err != nil {
+				return nil, err
+			}
+			result.Truncated = result.Truncated || truncated
+
+			for _, edge := range edges {
+				child := &transferPathNode{address: edge.to, parent: node, hop: edge.hop, depth: depth}
+				if bytes.Equal(edge.to, search.Destination) {
+					result.Paths = append(result.Paths, child.path())
+					if len(result.Paths) >= maxPaths {
+						result.Truncated = true
+						return result, nil
+					}
+					continue
+				}
+				if depth == search.MaxDepth || visited[string(edge.to)] {
+					continue
+				}
+				visited[string(edge.to)] = true
+				next = append(next, child)
+			}
+		}
+		frontier = next
+	}
+
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzBlockFromPaddedBlockNumber(f *testing.F) {
+	for _, seed := range []string{reversedPaddedBlockNumber(0), reversedPaddedBlockNumber(17000000), reversedPaddedBlockNumber(max_block_number), "", "12345678", "1234567890", "-00000001", "+00000001", "00000000a", "０00000000"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, padded string) {
+		block, err := blockFromPaddedBlockNumber(padded)
+		if err != nil {
+			return
+		}
+		if block > max_block_number {
+			t.Fatalf("block %v of %q exceeds the max block number", block, padded)
+		}
+		if reversed := reversedPaddedBlockNumber(block); reversed != padded {
+			t.Fatalf("block %v parsed from %q is padded to %q", block, padded, reversed)
+		}
+	})
+}
+
+func FuzzPrefixSuccessor(f *testing.F) {
+	f.Add("1:I:TX:0xabc:TIME:", 5)
+	f.Add("1:I:TX:0xabc:TIME:0000:1234", 5)
+	f.Add("1:NFT_HOLDER:abc:0001:def", 5)
+	f.Add("", 5)
+	f.Add("\xff\xff", 1)
+	f.Add("a\xff:b", 1)
+	f.Add(":::::::", 3)
+	f.Fuzz(func(t *testing.T, prefix string, pos int) {
+		if pos < 1 || pos > 16 {
+			return
+		}
+		successor := prefixSuccessor(prefix, pos)
+
+		truncated := prefix
+		if split := strings.Split(prefix, ":"); len(split) > pos {
+			truncated = strings.Join(split[:pos], ":")
+		}
+		if successor == "" {
+			if strings.Trim(truncated, "\xff") != "" {
+				t.Fatalf("empty successor of %q (pos %v)", prefix, pos)
+			}
+			return
+		}
+		// every key starting with the truncated prefix must sort before the successor
+		if truncated >= successor || truncated+"\xff\xff" >= successor || strings.HasPrefix(successor, truncated) {
+			t.Fatalf("successor %q of %q (pos %v) does not end the range of %q", successor, prefix, pos, truncated)
+		}
+	})
+}
+
+func FuzzDecodePageToken(f *testing.F) {
+	const prefix = "1:I:TX:00000000219ab540356cbb839cbe05303d7705fa:TIME:"
+	f.Add(EncodePageToken(prefix, prefix+"9223372035188572567:9999"))
+	f.Add(EncodePageToken(prefix, prefix+"x"))
+	f.Add("")
+	f.Add("0OIl")
+	f.Add("1111111")
+	f.Add(strings.Repeat("z", 1024))
+	f.Fuzz(func(t *testing.T, token string) {
+		key, err := DecodePageToken(prefix, token)
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(key, prefix) || len(key) == len(prefix) {
+			t.Fatalf("token %q decoded to key %q outside of the index", token, key)
+		}
+		if encoded := EncodePageToken(prefix, key); encoded != token {
+			t.Fatalf("token %q decoded to key %q which is encoded to %q", token, key, encoded)
+		}
+	})
+}
+
+func FuzzPageTokenRoundTrip(f *testing.F) {
+	const prefix = "1:I:ERC20:00000000219ab540356cbb839cbe05303d7705fa:TIME:"
+	f.Add("9223372035188572567:9999:99999")
+	f.Add(":")
+	f.Fuzz(func(t *testing.T, suffix string) {
+		token := EncodePageToken(prefix, prefix+suffix)
+		key, err := DecodePageToken(prefix, token)
+		if err != nil {
+			return
+		}
+		if key != prefix+suffix {
+			t.Fatalf("suffix %q was decoded to key %q", suffix, key)
+		}
+	})
+}
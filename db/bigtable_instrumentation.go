@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"runtime"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otel_codes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Every call to bigtable is recorded as span of the tracer provider registered with otel and in the bigtable metrics,
+// labeled with the operation and the Bigtable method the call has been made for.
+
+var tracer = otel.Tracer("eth2-exporter/db")
+
+// the helpers that issue bigtable calls on behalf of other methods, calls are attributed to the method calling them
+var bigtableCallWrappers = map[string]bool{
+	"readRows":           true,
+	"readRow":            true,
+	"retryRead":          true,
+	"readIndexRows":      true,
+	"WriteBulk":          true,
+	"applyBulkWithRetry": true,
+	"apply":              true,
+	"applyBulk":          true,
+	"findBlockGaps":      true,
+}
+
+// bigtableCaller returns the name of the first function of the db package on the stack that is not a call wrapper
+func bigtableCaller() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		name := frame.Function
+		if !strings.HasPrefix(name, "eth2-exporter/db.") {
+			return "unknown"
+		}
+		// strip the package and receiver, closures are attributed to their enclosing function
+		name = strings.TrimPrefix(name, "eth2-exporter/db.")
+		if i := strings.LastIndex(name, ")."); i >= 0 {
+			name = name[i+2:]
+		}
+		if i := strings.Index(name, "."); i >= 0 {
+			name = name[:i]
+		}
+		if !bigtableCallWrappers[name] {
+			return name
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+type bigtableCall struct {
+	span      trace.Span
+	operation string
+	method    string
+	start     time.Time
+}
+
+// startBigtableCall starts the span of a call to bigtable, the returned context has to be passed to the call
+func startBigtableCall(ctx context.Context, operation string) (context.Context, *bigtableCall) {
+	call := &bigtableCall{
+		operation: operation,
+		method:    bigtableCaller(),
+		start:     time.Now(),
+	}
+	ctx, call.span = tracer.Start(ctx, "bigtable."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "bigtable"),
+		attribute.String("db.operation", operation),
+		attribute.String("code.function", call.method),
+	))
+	return ctx, call
+}
+
+// end records the duration, the number of rows read or written and the error of the call
+func (call *bigtableCall) end(rows int, err error) {
+	metrics.BigtableCallDuration.WithLabelValues(call.operation, call.method).Observe(time.Since(call.start).Seconds())
+	metrics.BigtableCallRows.WithLabelValues(call.operation, call.method).Observe(float64(rows))
+	call.span.SetAttributes(attribute.Int("db.rows", rows))
+	if err != nil {
+		metrics.BigtableCallErrors.WithLabelValues(call.operation, call.method).Inc()
+		call.span.RecordError(err)
+		call.span.SetStatus(otel_codes.Error, err.Error())
+	}
+	call.span.End()
+}
+
+// apply wraps table.Apply with the instrumentation of the call
+func (bigtable *Bigtable) apply(ctx context.Context, table *gcp_bigtable.Table, key string, mut *gcp_bigtable.Mutation, opts ...gcp_bigtable.ApplyOption) error {
+	ctx, call := startBigtableCall(ctx, "Apply")
+	err := table.Apply(ctx, key, mut, opts...)
+	call.end(1, err)
+	return err
+}
+
+// applyBulk wraps table.ApplyBulk with the instrumentation of the call
+func (bigtable *Bigtable) applyBulk(ctx context.Context, table *gcp_bigtable.Table, keys []string, muts []*gcp_bigtable.Mutation) ([]error, error) {
+	ctx, call := startBigtableCall(ctx, "ApplyBulk")
+	errs, err := table.ApplyBulk(ctx, keys, muts)
+	callErr := err
+	for _, rowErr := range errs {
+		if callErr == nil && rowErr != nil {
+			callErr = rowErr
+		}
+	}
+	call.end(len(keys), callErr)
+	return errs, err
+}
@@ -0,0 +1,18 @@
+package db
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBigtableCaller(t *testing.T) {
+	method := ""
+	func() {
+		_, call := startBigtableCall(context.Background(), "ReadRows")
+		method = call.method
+		call.end(0, nil)
+	}()
+	if method != "TestBigtableCaller" {
+		t.Errorf("expected the call to be attributed to the enclosing function, got %v", method)
+	}
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// maximum size of a row key, bigtable rejects keys larger than 4KiB
+const maxRowKeySize = 4096
+
+// maximum size of the proto stored in a single data row
+const maxDataRowSize = 10 << 20
+
+// maximum number of violations that are included in the error message
+const maxReportedViolations = 10
+
+// indexed row types of the data table, every index row I:<type>:... of a block must point to a <type>:... data row of the same block
+var indexedRowTypes = []string{"B", "TX", "ITX", "ERC20", "ERC721", "ERC1155", "U", "W", "LOG"}
+
+var (
+	txDataKeyRe    = regexp.MustCompile(`^TX:[0-9a-f]{64}$`)
+	blockDataKeyRe = regexp.MustCompile(`^B:[0-9]{9}$`)
+)
+
+// InvariantViolationError is returned if the mutations generated for a block violate the layout of the data table,
+// none of the mutations of the block may be written in that case
+type InvariantViolationError struct {
+	Block      uint64
+	Violations []string
+}
+
+func (e *InvariantViolationError) Error() string {
+	violations := e.Violations
+	if len(violations) > maxReportedViolations {
+		violations = violations[:maxReportedViolations]
+	}
+	return fmt.Sprintf("mutations of block %v violate %v invariants: %v", e.Block, len(e.Violations), strings.Join(violations, "; "))
+}
+
+// blockInvariants collects the invariant violations of the mutations of a single block
+type blockInvariants struct {
+	violations []string
+}
+
+func (v *blockInvariants) violate(invariant string, format string, args ...interface{}) {
+	metrics.BigtableInvariantViolations.WithLabelValues(invariant).Inc()
+	v.violations = append(v.violations, fmt.Sprintf("%v: %v", invariant, fmt.Sprintf(format, args...)))
+}
+
+// ValidateBlockMutations checks the data and metadata update mutations all transforms generated for a block before they are written:
+//   - every key has a mutation and every row key is prefixed with the chain id and fits into a row key
+//   - the data rows of transactions and blocks are keyed by a 32 byte hash and a padded block number
+//   - every index row has a data row of its type in the same block
+func (bigtable *Bigtable) ValidateBlockMutations(block *types.Eth1Block, data, metadataUpdates *types.BulkMutations) error {
+	v := &blockInvariants{}
+
+	bigtable.validateKeys(v, "data", data)
+	bigtable.validateKeys(v, "metadata_updates", metadataUpdates)
+
+	prefix := bigtable.chainId + ":"
+	dataRows := make(map[string]bool)
+	indexRows := make(map[string]string)
+	for _, key := range data.Keys {
+		key := strings.TrimPrefix(key, prefix)
+		if strings.HasPrefix(key, "I:") {
+			parts := strings.SplitN(key, ":", 3)
+			if len(parts) == 3 {
+				if _, ok := indexRows[parts[1]]; !ok {
+					indexRows[parts[1]] = key
+				}
+			}
+			continue
+		}
+
+		rowType := strings.SplitN(key, ":", 2)[0]
+		dataRows[rowType] = true
+
+		switch {
+		case rowType == "TX" && !txDataKeyRe.MatchString(key):
+			v.violate("tx_key", "malformed transaction row %v", key)
+		case rowType == "B" && !blockDataKeyRe.MatchString(key):
+			v.violate("block_key", "malformed block row %v", key)
+		}
+	}
+
+	for _, rowType := range indexedRowTypes {
+		if key, ok := indexRows[rowType]; ok && !dataRows[rowType] {
+			v.violate("index_pairing", "index row %v has no %v data row", key, rowType)
+		}
+	}
+
+	if len(v.violations) > 0 {
+		return &InvariantViolationError{Block: block.GetNumber(), Violations: v.violations}
+	}
+	return nil
+}
+
+func (bigtable *Bigtable) validateKeys(v *blockInvariants, table string, muts *types.BulkMutations) {
+	if len(muts.Keys) != len(muts.Muts) {
+		v.violate("mutation_count", "%v table has %v keys but %v mutations", table, len(muts.Keys), len(muts.Muts))
+		return
+	}
+
+	prefix := bigtable.chainId + ":"
+	for i, key := range muts.Keys {
+		switch {
+		case muts.Muts[i] == nil:
+			v.violate("mutation_count", "%v table row %v has no mutation", table, key)
+		case !strings.HasPrefix(key, prefix):
+			v.violate("key_prefix", "%v table row %q is not prefixed with chain id %v", table, key, bigtable.chainId)
+		case len(key) > maxRowKeySize:
+			v.violate("key_size", "%v table row %.64v... has %v bytes", table, key, len(key))
+		}
+	}
+}
+
+// marshalDataRow marshals the proto stored in a data row, empty and oversized protos are rejected
+func marshalDataRow(m proto.Message) ([]byte, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		metrics.BigtableInvariantViolations.WithLabelValues("empty_proto").Inc()
+		return nil, fmt.Errorf("empty_proto: %T marshals to an empty data row", m)
+	}
+	if len(b) > maxDataRowSize {
+		metrics.BigtableInvariantViolations.WithLabelValues("proto_size").Inc()
+		return nil, fmt.Errorf("proto_size: %T marshals to %v bytes, at most %v bytes are allowed", m, len(b), maxDataRowSize)
+	}
+	return b, nil
+}
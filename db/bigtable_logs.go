@@ -0,0 +1,153 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"google.golang.org/protobuf/proto"
+)
+
+// TransformLogs stores the raw logs emitted by the transactions of a block and indexes them by the emitting contract
+// Row:    <chainID>:LOG:<txHash>:<paddedLogIndex>
+// Family: f
+// Column: data
+// Cell:   Proto<Eth1LogIndexed>
+//
+// Row:    <chainID>:I:LOG:<CONTRACT_ADDRESS>:ALL:TIME:<reversePaddedBigtableTimestamp>:<paddedTxIndex>:<PaddedLogIndex>
+// Family: f
+// Column: <chainID>:LOG:<txHash>:<paddedLogIndex>
+// Cell:   nil
+//
+// Row:    <chainID>:I:LOG:<CONTRACT_ADDRESS>:TOPIC0:<TOPIC>:<reversePaddedBigtableTimestamp>:<paddedTxIndex>:<PaddedLogIndex>
+// Family: f
+// Column: <chainID>:LOG:<txHash>:<paddedLogIndex>
+// Cell:   nil
+func (bigtable *Bigtable) TransformLogs(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	logIndex := uint64(0)
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		iReversed := reversePaddedIndex(i, 10000)
+		for j, log := range tx.GetLogs() {
+			if j > 99999 {
+				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
+			}
+			jReversed := reversePaddedIndex(j, 100000)
+			blockLogIndex := logIndex
+			logIndex++
+			if log.GetRemoved() {
+				continue
+			}
+
+			key := fmt.Sprintf("%s:LOG:%x:%s", bigtable.chainId, tx.GetHash(), jReversed)
+			indexedLog := &types.Eth1LogIndexed{
+				ParentHash:  tx.GetHash(),
+				BlockNumber: blk.GetNumber(),
+				Time:        blk.GetTime(),
+				TxIndex:     uint64(i),
+				LogIndex:    blockLogIndex,
+				Address:     log.GetAddress(),
+				Topics:      log.GetTopics(),
+				Data:        log.GetData(),
+			}
+
+			b, err := marshalDataRow(indexedLog)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
+
+			bulkData.Keys = append(bulkData.Keys, key)
+			bulkData.Muts = append(bulkData.Muts, mut)
+
+			indexes := []string{
+				fmt.Sprintf("%s:I:LOG:%x:ALL:TIME:%s:%s:%s", bigtable.chainId, indexedLog.Address, reversePaddedBigtableTimestamp(blk.GetTime()), iReversed, jReversed),
+			}
+			// anonymous events do not have a signature topic
+			if len(indexedLog.Topics) > 0 && len(indexedLog.Topics[0]) == 32 {
+				indexes = append(indexes, fmt.Sprintf("%s:I:LOG:%x:TOPIC0:%x:%s:%s:%s", bigtable.chainId, indexedLog.Address, indexedLog.Topics[0], reversePaddedBigtableTimestamp(blk.GetTime()), iReversed, jReversed))
+			}
+
+			for _, idx := range indexes {
+				mut := gcp_bigtable.NewMutation()
+				mut.Set(DEFAULT_FAMILY, key, gcp_bigtable.Timestamp(0), nil)
+
+				bulkData.Keys = append(bulkData.Keys, idx)
+				bulkData.Muts = append(bulkData.Muts, mut)
+			}
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// LogIndexPrefix returns the prefix of the log index of a contract, the logs are filtered by their signature topic if topic0 is set
+func (bigtable *Bigtable) LogIndexPrefix(contract []byte, topic0 []byte) string {
+	if len(topic0) > 0 {
+		return fmt.Sprintf("%s:I:LOG:%x:TOPIC0:%x:", bigtable.chainId, contract, topic0)
+	}
+	return fmt.Sprintf("%s:I:LOG:%x:ALL:TIME:", bigtable.chainId, contract)
+}
+
+// GetEth1LogsForContract returns up to limit logs of the log index page that starts after prefix, newest first.
+// The prefix is either one returned by LogIndexPrefix or the last key of a previous page.
+func (bigtable *Bigtable) GetEth1LogsForContract(ctx context.Context, prefix string, limit int64) ([]*types.Eth1LogIndexed, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	data := make([]*types.Eth1LogIndexed, 0, limit)
+	keys := make([]string, 0, limit)
+	indexes := make([]string, 0, limit)
+
+	keysMap := make(map[string]*types.Eth1LogIndexed, limit)
+	// both the ALL:TIME and the TOPIC0:<topic> index consist of 6 fixed components
+	err := bigtable.readIndexRows(ctx, prefix+"\x00", prefixSuccessor(prefix, 6), limit, func(row gcp_bigtable.Row) bool {
+		keys = append(keys, strings.TrimPrefix(row[DEFAULT_FAMILY][0].Column, "f:"))
+		indexes = append(indexes, row.Key())
+		return true
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if len(keys) == 0 {
+		return data, "", nil
+	}
+
+	skipped := newSkippedRows("Eth1LogIndexed")
+	err = bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.RowList(keys), func(row gcp_bigtable.Row) bool {
+		skipped.found(row.Key())
+		b := &types.Eth1LogIndexed{}
+		err := proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, b)
+
+		if err != nil {
+			skipped.corrupt(row.Key(), err)
+			return true
+		}
+		keysMap[row.Key()] = b
+		return true
+	}, skipTombstones())
+	if err != nil {
+		logger.WithError(err).WithField("prefix", prefix).WithField("limit", limit).Errorf("error reading rows in bigtable_logs / GetEth1LogsForContract")
+		return nil, "", err
+	}
+	skipped.checkMissing(keys)
+
+	for _, key := range keys {
+		if d := keysMap[key]; d != nil {
+			data = append(data, d)
+		}
+	}
+
+	return data, indexes[len(indexes)-1], skipped.err()
+}
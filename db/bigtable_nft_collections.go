@@ -0,0 +1,411 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/erc1155"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// maximum number of holder rows that are read when counting the owners of a collection
+const maxNFTHolderRows = 250000
+
+// number of holdings shown per page of the nfts tab of an address
+const addressNFTsPageSize = 24
+
+// TransformNFTHolders records every ERC721 and ERC1155 balance change of a block so that the current holdings
+// of collections and addresses can be derived from them
+//
+// It writes the following rows to the data table, one per holder and transfer:
+//
+//	NFT_HOLDER:<tokenAddress>:<tokenId>:<holder>:<blockNumber>:<txIdx>:<logIdx>:<idIdx>:<IN|OUT>
+//	NFT_OWNED:<holder>:<tokenAddress>:<tokenId>:<blockNumber>:<txIdx>:<logIdx>:<idIdx>:<IN|OUT>
+//
+// The column of a row is the token standard and its value the signed amount of the transfer, the current balance of a holding
+// is the sum of all rows sharing the <tokenAddress>:<tokenId>:<holder> part. As every row belongs to exactly one block,
+// re-indexing a block does not change the balances and the rows of orphaned blocks are removed by DeleteBlock.
+func (bigtable *Bigtable) TransformNFTHolders(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	filterer, err := erc1155.NewErc1155Filterer(common.Address{}, nil)
+	if err != nil {
+		logger.Errorf("error creating filterer: %v", err)
+	}
+
+	addHolding := func(transfer *types.NFTTransfer, holder []byte, suffix string, delta *big.Int) {
+		tokenId := fmt.Sprintf("%064x", new(big.Int).SetBytes(transfer.TokenId))
+		for _, key := range []string{
+			fmt.Sprintf("%s:NFT_HOLDER:%x:%s:%x:%s", bigtable.chainId, transfer.TokenAddress, tokenId, holder, suffix),
+			fmt.Sprintf("%s:NFT_OWNED:%x:%x:%s:%s", bigtable.chainId, holder, transfer.TokenAddress, tokenId, suffix),
+		} {
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, transfer.Standard, gcp_bigtable.Timestamp(0), []byte(delta.String()))
+
+			bulkData.Keys = append(bulkData.Keys, key)
+			bulkData.Muts = append(bulkData.Muts, mut)
+		}
+	}
+
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+
+		idIdx := 0
+		lastLogIdx := -1
+		for _, transfer := range nftTransfersOfTransaction(filterer, blk, i, tx) {
+			if transfer.LogIndex != lastLogIdx {
+				idIdx = 0
+				lastLogIdx = transfer.LogIndex
+			}
+			suffix := fmt.Sprintf("%09d:%04d:%05d:%05d", blk.GetNumber(), i, transfer.LogIndex, idIdx)
+			idIdx++
+
+			value := new(big.Int).SetBytes(transfer.Value)
+			if value.Sign() == 0 {
+				continue
+			}
+			if !bytes.Equal(transfer.From, ZERO_ADDRESS) {
+				addHolding(transfer, transfer.From, suffix+":OUT", new(big.Int).Neg(value))
+			}
+			if !bytes.Equal(transfer.To, ZERO_ADDRESS) {
+				addHolding(transfer, transfer.To, suffix+":IN", value)
+			}
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// nftHolding is the balance of a holding summed up from its transfer rows
+type nftHolding struct {
+	// prefix shared by all transfer rows of the holding, including the trailing separator
+	prefix   string
+	standard string
+	balance  *big.Int
+}
+
+// readNFTHoldings reads the transfer rows of a range and calls fn for every holding once all of its rows have been summed up.
+// A holding is identified by the first five parts of the row key. The holding that is cut off by the row limit is not passed to fn,
+// its prefix is returned instead so that the caller can continue reading from it.
+func (bigtable *Bigtable) readNFTHoldings(ctx context.Context, rowRange gcp_bigtable.RowRange, limit int64, fn func(holding *nftHolding) bool) (string, error) {
+	var current *nftHolding
+	var rowErr error
+	rows := int64(0)
+	stopped := false
+
+	err := bigtable.readRows(ctx, bigtable.tableData, rowRange, func(row gcp_bigtable.Row) bool {
+		rows++
+		keyParts := strings.Split(row.Key(), ":")
+		if len(keyParts) < 6 {
+			rowErr = fmt.Errorf("unexpected nft holding key %v", row.Key())
+			return false
+		}
+		prefix := strings.Join(keyParts[:5], ":") + ":"
+
+		if current != nil && current.prefix != prefix {
+			if !fn(current) {
+				stopped = true
+				current = nil
+				return false
+			}
+			current = nil
+		}
+		if current == nil {
+			current = &nftHolding{prefix: prefix, balance: new(big.Int)}
+		}
+
+		for _, item := range row[DEFAULT_FAMILY] {
+			delta, ok := new(big.Int).SetString(string(item.Value), 10)
+			if !ok {
+				rowErr = fmt.Errorf("invalid amount %q in column %v of row %v", item.Value, item.Column, row.Key())
+				return false
+			}
+			current.balance.Add(current.balance, delta)
+			current.standard = strings.TrimPrefix(item.Column, DEFAULT_FAMILY+":")
+		}
+		return true
+	}, gcp_bigtable.LimitRows(limit), skipTombstones())
+	if err != nil {
+		return "", err
+	}
+	if rowErr != nil {
+		return "", rowErr
+	}
+
+	if stopped || current == nil {
+		return "", nil
+	}
+	if rows >= limit {
+		return current.prefix, nil
+	}
+	fn(current)
+	return "", nil
+}
+
+// GetNFTCollectionOwnerCount returns the number of addresses currently holding at least one token of a collection.
+// For very large collections only a part of the holdings is considered, complete is false in that case.
+func (bigtable *Bigtable) GetNFTCollectionOwnerCount(ctx context.Context, token []byte) (owners uint64, complete bool, err error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:NFT_HOLDER:%x:", bigtable.chainId, token)
+
+	holders := make(map[string]bool)
+	cutOff, err := bigtable.readNFTHoldings(ctx, gcp_bigtable.PrefixRange(prefix), maxNFTHolderRows, func(holding *nftHolding) bool {
+		if holding.balance.Sign() > 0 {
+			keyParts := strings.Split(holding.prefix, ":")
+			holders[keyParts[4]] = true
+		}
+		return true
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return uint64(len(holders)), cutOff == "", nil
+}
+
+// GetAddressNFTs returns a page of the ERC721 and ERC1155 tokens an address currently holds, ordered by collection and token id,
+// together with the token of the next page
+func (bigtable *Bigtable) GetAddressNFTs(ctx context.Context, address []byte, pageToken string) ([]*types.NFTHolding, string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:NFT_OWNED:%x:", bigtable.chainId, address)
+	if pageToken == "" {
+		pageToken = prefix
+	} else if !strings.HasPrefix(pageToken, prefix) {
+		return nil, "", fmt.Errorf("invalid page token %v for address 0x%x", pageToken, address)
+	}
+
+	holdings := make([]*types.NFTHolding, 0, addressNFTsPageSize)
+	nextPageToken := ""
+	cutOff, err := bigtable.readNFTHoldings(ctx, gcp_bigtable.NewRange(pageToken, prefixSuccessor(prefix, 5)), maxNFTHolderRows, func(holding *nftHolding) bool {
+		if holding.balance.Sign() <= 0 {
+			return true
+		}
+		if len(holdings) == addressNFTsPageSize {
+			nextPageToken = holding.prefix
+			return false
+		}
+		keyParts := strings.Split(holding.prefix, ":")
+		tokenId, _ := new(big.Int).SetString(keyParts[4], 16)
+		holdings = append(holdings, &types.NFTHolding{
+			TokenAddress: common.FromHex(keyParts[3]),
+			TokenId:      tokenId.Bytes(),
+			Standard:     holding.standard,
+			Balance:      holding.balance.Bytes(),
+		})
+		return true
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if cutOff == pageToken {
+		// a holding with more transfers than the row limit can not be read at once, skip it instead of returning it as the next page over and over
+		nextPageToken = prefixSuccessor(cutOff, 5)
+	} else if cutOff != "" {
+		nextPageToken = cutOff
+	}
+
+	return holdings, nextPageToken, nil
+}
+
+// addressNFTCollections returns the number of tokens an address currently holds per collection ordered by collection. For
+// addresses with a very large number of transfers only a part of the holdings is considered, complete is false in that case.
+func (bigtable *Bigtable) addressNFTCollections(ctx context.Context, address []byte) (collections []*types.NFTCollectionBalance, complete bool, err error) {
+	prefix := fmt.Sprintf("%s:NFT_OWNED:%x:", bigtable.chainId, address)
+
+	collections = []*types.NFTCollectionBalance{}
+	cutOff, err := bigtable.readNFTHoldings(ctx, gcp_bigtable.PrefixRange(prefix), maxNFTHolderRows, func(holding *nftHolding) bool {
+		if holding.balance.Sign() <= 0 {
+			return true
+		}
+		token := common.FromHex(strings.Split(holding.prefix, ":")[3])
+		var collection *types.NFTCollectionBalance
+		if len(collections) > 0 && bytes.Equal(collections[len(collections)-1].TokenAddress, token) {
+			collection = collections[len(collections)-1]
+		} else {
+			collection = &types.NFTCollectionBalance{TokenAddress: token, Standard: holding.standard, Balance: new(big.Int)}
+			collections = append(collections, collection)
+		}
+		collection.Tokens++
+		collection.Balance.Add(collection.Balance, holding.balance)
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return collections, cutOff == "", nil
+}
+
+// GetAddressNFTsTableData returns a page of the tokens held by an address formatted as the cards of the nfts tab of the address page
+func (bigtable *Bigtable) GetAddressNFTsTableData(ctx context.Context, address []byte, pageToken string) (*types.DataTableResponse, error) {
+	holdings, nextPageToken, err := bigtable.GetAddressNFTs(ctx, address, pageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	for _, h := range holdings {
+		names[string(h.TokenAddress)] = ""
+	}
+	err = bigtable.GetAddressNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tableData := make([][]interface{}, 0, len(holdings))
+	for _, h := range holdings {
+		tableData = append(tableData, []interface{}{
+			utils.FormatNFTHolding(h.TokenAddress, names[string(h.TokenAddress)], new(big.Int).SetBytes(h.TokenId), h.Standard, new(big.Int).SetBytes(h.Balance)),
+		})
+	}
+
+	return &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: nextPageToken,
+	}, nil
+}
+
+// GetNFTCollectionStandard returns whether the transfers of a token contract have been indexed as ERC721 or ERC1155 transfers,
+// an empty string is returned if no transfers of the token exist
+func (bigtable *Bigtable) GetNFTCollectionStandard(ctx context.Context, token []byte) (string, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	for _, candidate := range []struct {
+		standard string
+		prefix   string
+	}{
+		{NFTStandardERC721, fmt.Sprintf("%s:I:ERC721:%x:ALL:%s:", bigtable.chainId, token, FILTER_TIME)},
+		{NFTStandardERC1155, fmt.Sprintf("%s:I:ERC1155:%x:%s:", bigtable.chainId, token, FILTER_TIME)},
+	} {
+		found := false
+		err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(candidate.prefix), func(row gcp_bigtable.Row) bool {
+			found = true
+			return false
+		}, gcp_bigtable.LimitRows(1), gcp_bigtable.RowFilter(gcp_bigtable.StripValueFilter()))
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return candidate.standard, nil
+		}
+	}
+	return "", nil
+}
+
+// NFTCollectionTransfersPrefix returns the index prefix of all transfers of a collection, page tokens of the collection transfers start with it
+func (bigtable *Bigtable) NFTCollectionTransfersPrefix(token []byte, standard string) string {
+	if standard == NFTStandardERC1155 {
+		return fmt.Sprintf("%s:I:ERC1155:%x:%s:", bigtable.chainId, token, FILTER_TIME)
+	}
+	return fmt.Sprintf("%s:I:ERC721:%x:ALL:%s:", bigtable.chainId, token, FILTER_TIME)
+}
+
+// GetNFTCollectionTransfers returns a page of the most recent transfers of a collection together with the token of the next page
+func (bigtable *Bigtable) GetNFTCollectionTransfers(ctx context.Context, token []byte, standard string, pageToken string, limit int64) ([]*types.NFTTransfer, string, error) {
+	prefix := bigtable.NFTCollectionTransfersPrefix(token, standard)
+	if pageToken == "" {
+		pageToken = prefix
+	} else if !strings.HasPrefix(pageToken, prefix) {
+		return nil, "", fmt.Errorf("invalid page token %v for collection 0x%x", pageToken, token)
+	}
+
+	transfers := make([]*types.NFTTransfer, 0, limit)
+	switch standard {
+	case NFTStandardERC721:
+		indexed, lastKey, err := bigtable.GetEth1ERC721ForAddress(ctx, pageToken, limit)
+		if err != nil && !IsPartialResult(err) {
+			return nil, "", err
+		}
+		for _, t := range indexed {
+			transfers = append(transfers, &types.NFTTransfer{
+				Standard:     NFTStandardERC721,
+				TxHash:       t.ParentHash,
+				BlockNumber:  t.BlockNumber,
+				Time:         t.Time.AsTime(),
+				TokenAddress: t.TokenAddress,
+				From:         t.From,
+				To:           t.To,
+				TokenId:      t.TokenId,
+				Value:        []byte{1},
+			})
+		}
+		return transfers, lastKey, err
+	case NFTStandardERC1155:
+		indexed, lastKey, err := bigtable.GetEth1ERC1155ForAddress(ctx, pageToken, limit)
+		if err != nil && !IsPartialResult(err) {
+			return nil, "", err
+		}
+		for _, t := range indexed {
+			// the index of the token address also contains the transfers of other tokens sent or received by the contract itself
+			if !bytes.Equal(t.TokenAddress, token) {
+				continue
+			}
+			transfers = append(transfers, &types.NFTTransfer{
+				Standard:     NFTStandardERC1155,
+				TxHash:       t.ParentHash,
+				BlockNumber:  t.BlockNumber,
+				Time:         t.Time.AsTime(),
+				TokenAddress: t.TokenAddress,
+				From:         t.From,
+				To:           t.To,
+				Operator:     t.Operator,
+				TokenId:      t.TokenId,
+				Value:        t.Value,
+			})
+		}
+		return transfers, lastKey, err
+	}
+	return nil, "", fmt.Errorf("unsupported nft standard %q", standard)
+}
+
+// GetNFTCollectionTransfersTableData returns a page of the transfers of a collection formatted for the collection page
+func (bigtable *Bigtable) GetNFTCollectionTransfersTableData(ctx context.Context, token []byte, standard string, pageToken string) (*types.DataTableResponse, error) {
+	transfers, lastKey, err := bigtable.GetNFTCollectionTransfers(ctx, token, standard, pageToken, 25)
+	if err != nil && !IsPartialResult(err) {
+		return nil, err
+	}
+	partial := err
+
+	names := make(map[string]string)
+	for _, t := range transfers {
+		names[string(t.From)] = ""
+		names[string(t.To)] = ""
+	}
+	err = bigtable.GetAddressNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	tableData := make([][]interface{}, 0, len(transfers))
+	for _, t := range transfers {
+		tableData = append(tableData, []interface{}{
+			utils.FormatTransactionHash(t.TxHash),
+			utils.FormatTimeFromNow(t.Time),
+			utils.FormatAddress(t.From, nil, names[string(t.From)], false, false, !bytes.Equal(t.From, ZERO_ADDRESS)),
+			utils.FormatAddress(t.To, nil, names[string(t.To)], false, false, !bytes.Equal(t.To, ZERO_ADDRESS)),
+			new(big.Int).SetBytes(t.TokenId).String(),
+			new(big.Int).SetBytes(t.Value).String(),
+		})
+	}
+
+	return &types.DataTableResponse{
+		Data:        tableData,
+		PagingToken: lastKey,
+		Warnings:    PartialResultWarnings(partial),
+	}, partial
+}
@@ -0,0 +1,432 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/cache"
+	"eth2-exporter/rpc"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// The metadata of a token of an ERC721 or ERC1155 collection is resolved in the background once it has been requested for the first
+// time. Only tokens with indexed transfers are queued in the metadata updates table:
+// Row:    <chainID>:NFT_METADATA:<tokenAddress>:<tokenId>
+// Family: f
+// Column: queued
+// Cell:   the standard of the collection
+//
+// ProcessNFTMetadataUpdates resolves the queued tokens and keeps their metadata in:
+// Row:    <chainID>:NFT_META:<tokenAddress>:<tokenId>
+// Family: erc721 or erc1155
+// Column: METADATA, the json encoded types.NFTMetadata
+// Column: THUMBNAIL and THUMBNAILFORMAT, the preview of the image of the token and its media type
+//
+// The timestamp of the cells is the time they have been resolved at, metadata and thumbnails that could not be resolved are
+// stored with an error or without data and queued again after nftMetadataRetryAfter.
+const (
+	NFT_COLUMN_METADATA         = "METADATA"
+	NFT_COLUMN_THUMBNAIL        = "THUMBNAIL"
+	NFT_COLUMN_THUMBNAIL_FORMAT = "THUMBNAILFORMAT"
+	NFT_METADATA_QUEUED_COLUMN  = "queued"
+
+	nftMetadataRetryAfter = time.Hour
+	nftThumbnailSize      = 256
+	// images that can not be scaled down are used as their own thumbnail up to this size
+	nftThumbnailMaxBytes = 512 << 10
+	// a token is queued at most once within this interval
+	nftMetadataQueueInterval = time.Minute
+)
+
+// ErrNFTMetadataPending is returned for tokens whose metadata or thumbnail has been queued but not resolved yet
+var ErrNFTMetadataPending = errors.New("the nft metadata has not been resolved yet")
+
+type nftThumbnail struct {
+	Data   []byte
+	Format string
+}
+
+func (bigtable *Bigtable) nftMetadataKey(token []byte, tokenId *big.Int) string {
+	return fmt.Sprintf("%s:NFT_META:%x:%064x", bigtable.chainId, token, tokenId)
+}
+
+func nftMetadataFamily(standard string) string {
+	if standard == NFTStandardERC1155 {
+		return ERC1155_METADATA_FAMILY
+	}
+	return ERC721_METADATA_FAMILY
+}
+
+// nftTokenIndexed reports whether transfers of the token have been indexed
+func (bigtable *Bigtable) nftTokenIndexed(ctx context.Context, token []byte, tokenId *big.Int) (bool, error) {
+	indexed := false
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(fmt.Sprintf("%s:NFT_HOLDER:%x:%064x:", bigtable.chainId, token, tokenId)), func(row gcp_bigtable.Row) bool {
+		indexed = true
+		return false
+	}, gcp_bigtable.LimitRows(1), skipTombstones(gcp_bigtable.StripValueFilter()))
+	return indexed, err
+}
+
+// queueNFTMetadataUpdate queues the resolution of the metadata and the thumbnail of a token
+func (bigtable *Bigtable) queueNFTMetadataUpdate(ctx context.Context, standard string, token []byte, tokenId *big.Int) error {
+	cacheKey := fmt.Sprintf("%s:NFT_METADATA_QUEUED:%x:%064x", bigtable.chainId, token, tokenId)
+	if queued, err := cache.TieredCache.GetBoolWithLocalTimeout(cacheKey, nftMetadataQueueInterval); err == nil && queued {
+		return nil
+	}
+
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(DEFAULT_FAMILY, NFT_METADATA_QUEUED_COLUMN, gcp_bigtable.Timestamp(0), []byte(standard))
+	err := bigtable.apply(ctx, bigtable.tableMetadataUpdates, fmt.Sprintf("%s:NFT_METADATA:%x:%064x", bigtable.chainId, token, tokenId), mut)
+	if err != nil {
+		return err
+	}
+	err = cache.TieredCache.SetBool(cacheKey, true, nftMetadataQueueInterval)
+	if err != nil {
+		logger.Errorf("error caching the queued metadata update of nft %x:%v: %v", token, tokenId, err)
+	}
+	return nil
+}
+
+// readNFTMetadata returns the stored metadata of a token and whether it is resolved, metadata stored with an error counts as
+// resolved until it is due for a retry
+func (bigtable *Bigtable) readNFTMetadata(ctx context.Context, standard string, token []byte, tokenId *big.Int) (*types.NFTMetadata, bool, error) {
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, bigtable.nftMetadataKey(token, tokenId), gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(nftMetadataFamily(standard)),
+		gcp_bigtable.ColumnFilter(NFT_COLUMN_METADATA),
+		gcp_bigtable.LatestNFilter(1),
+	)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, item := range row[nftMetadataFamily(standard)] {
+		metadata := &types.NFTMetadata{}
+		err = json.Unmarshal(item.Value, metadata)
+		if err != nil {
+			logger.Errorf("error decoding metadata of nft %x:%v: %v", token, tokenId, err)
+			return nil, false, nil
+		}
+		return metadata, metadata.Error == "" || time.Since(item.Timestamp.Time()) < nftMetadataRetryAfter, nil
+	}
+	return nil, false, nil
+}
+
+// GetNFTMetadata returns the metadata of a token of an ERC721 or ERC1155 collection, nil is returned if no transfers of the
+// token have been indexed. The metadata of a token that has not been resolved yet is queued and ErrNFTMetadataPending is returned.
+func (bigtable *Bigtable) GetNFTMetadata(ctx context.Context, token []byte, tokenId *big.Int) (*types.NFTMetadata, error) {
+	cacheKey := bigtable.nftMetadataKey(token, tokenId)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Minute*10, new(types.NFTMetadata)); err == nil {
+		return cached.(*types.NFTMetadata), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	standard, err := bigtable.GetNFTCollectionStandard(ctx, token)
+	if err != nil || standard == "" {
+		return nil, err
+	}
+
+	metadata, resolved, err := bigtable.readNFTMetadata(ctx, standard, token, tokenId)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		indexed, err := bigtable.nftTokenIndexed(ctx, token, tokenId)
+		if err != nil || !indexed {
+			return nil, err
+		}
+		err = bigtable.queueNFTMetadataUpdate(ctx, standard, token, tokenId)
+		if err != nil {
+			return nil, err
+		}
+		// metadata that is due for a retry is served until it has been resolved again
+		if metadata == nil {
+			return nil, ErrNFTMetadataPending
+		}
+		return metadata, nil
+	}
+
+	bigtable.cacheNFTMetadata(token, tokenId, metadata)
+	return metadata, nil
+}
+
+func (bigtable *Bigtable) cacheNFTMetadata(token []byte, tokenId *big.Int, metadata *types.NFTMetadata) {
+	expiration := time.Hour * 24
+	if metadata.Error != "" {
+		expiration = nftMetadataRetryAfter
+	}
+	err := cache.TieredCache.Set(bigtable.nftMetadataKey(token, tokenId), metadata, expiration)
+	if err != nil {
+		logger.Errorf("error caching metadata of nft %x:%v: %v", token, tokenId, err)
+	}
+}
+
+// resolveNFTMetadata calls the uri method of the token contract and fetches the metadata it points to, errors are returned as
+// part of the metadata
+func resolveNFTMetadata(ctx context.Context, standard string, token []byte, tokenId *big.Int) *types.NFTMetadata {
+	metadata := &types.NFTMetadata{Standard: standard}
+
+	var err error
+	if standard == NFTStandardERC1155 {
+		metadata.TokenURI, err = rpc.CurrentGethClient.GetERC1155URI(ctx, token, tokenId)
+	} else {
+		metadata.TokenURI, err = rpc.CurrentGethClient.GetERC721TokenURI(ctx, token, tokenId)
+	}
+	if err != nil {
+		metadata.Error = fmt.Sprintf("error retrieving the uri of the token: %v", err)
+		return metadata
+	}
+	if metadata.TokenURI == "" {
+		metadata.Error = "the token has no uri"
+		return metadata
+	}
+
+	data, _, err := utils.FetchNFTURI(ctx, metadata.TokenURI, utils.NFTMetadataMaxBytes)
+	if err != nil {
+		metadata.Error = fmt.Sprintf("error fetching the metadata of the token: %v", err)
+		return metadata
+	}
+
+	// the fields of the metadata are not enforced by the standards, malformed fields are skipped
+	raw := struct {
+		Name         interface{}     `json:"name"`
+		Description  interface{}     `json:"description"`
+		Image        string          `json:"image"`
+		ImageURL     string          `json:"image_url"`
+		ImageData    string          `json:"image_data"`
+		AnimationURL string          `json:"animation_url"`
+		ExternalURL  string          `json:"external_url"`
+		Attributes   json.RawMessage `json:"attributes"`
+	}{}
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		metadata.Error = fmt.Sprintf("error decoding the metadata of the token: %v", err)
+		return metadata
+	}
+	if raw.Name != nil {
+		metadata.Name = fmt.Sprint(raw.Name)
+	}
+	if raw.Description != nil {
+		metadata.Description = fmt.Sprint(raw.Description)
+	}
+	metadata.Image = raw.Image
+	if metadata.Image == "" {
+		metadata.Image = raw.ImageURL
+	}
+	if metadata.Image == "" && strings.HasPrefix(strings.TrimSpace(raw.ImageData), "<svg") {
+		metadata.Image = "data:image/svg+xml," + url.PathEscape(raw.ImageData)
+	}
+	metadata.AnimationURL = raw.AnimationURL
+	metadata.ExternalURL = raw.ExternalURL
+	if len(raw.Attributes) > 0 {
+		attributes := []*types.NFTAttribute{}
+		if json.Unmarshal(raw.Attributes, &attributes) == nil {
+			metadata.Attributes = attributes
+		}
+	}
+	return metadata
+}
+
+// readNFTThumbnail returns the stored thumbnail of a token and whether it is resolved, a missing thumbnail counts as resolved
+// until it is due for a retry
+func (bigtable *Bigtable) readNFTThumbnail(ctx context.Context, standard string, token []byte, tokenId *big.Int) (*nftThumbnail, bool, error) {
+	family := nftMetadataFamily(standard)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, bigtable.nftMetadataKey(token, tokenId), gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(
+		gcp_bigtable.FamilyFilter(family),
+		gcp_bigtable.ColumnFilter(NFT_COLUMN_THUMBNAIL+"|"+NFT_COLUMN_THUMBNAIL_FORMAT),
+		gcp_bigtable.LatestNFilter(1),
+	)))
+	if err != nil {
+		return nil, false, err
+	}
+
+	thumbnail := &nftThumbnail{}
+	resolved := false
+	for _, item := range row[family] {
+		switch item.Column {
+		case family + ":" + NFT_COLUMN_THUMBNAIL:
+			thumbnail.Data = item.Value
+		case family + ":" + NFT_COLUMN_THUMBNAIL_FORMAT:
+			thumbnail.Format = string(item.Value)
+			resolved = thumbnail.Format != "" || time.Since(item.Timestamp.Time()) < nftMetadataRetryAfter
+		}
+	}
+	return thumbnail, resolved, nil
+}
+
+func (bigtable *Bigtable) nftThumbnailCacheKey(token []byte, tokenId *big.Int) string {
+	return fmt.Sprintf("%s:NFT_THUMBNAIL:%x:%064x", bigtable.chainId, token, tokenId)
+}
+
+// GetNFTThumbnail returns a preview of the image of a token of an ERC721 or ERC1155 collection and its media type, nil is
+// returned if the token has no image or it could not be fetched. Png, jpeg and gif images are scaled down, small images of
+// other formats are returned as they are. Thumbnails that have not been resolved yet are queued and ErrNFTMetadataPending is returned.
+func (bigtable *Bigtable) GetNFTThumbnail(ctx context.Context, token []byte, tokenId *big.Int) ([]byte, string, error) {
+	metadata, err := bigtable.GetNFTMetadata(ctx, token, tokenId)
+	if err != nil || metadata == nil || metadata.Image == "" {
+		return nil, "", err
+	}
+
+	cacheKey := bigtable.nftThumbnailCacheKey(token, tokenId)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Minute*10, new(nftThumbnail)); err == nil {
+		thumbnail := cached.(*nftThumbnail)
+		return thumbnail.Data, thumbnail.Format, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*30)
+	defer cancel()
+
+	thumbnail, resolved, err := bigtable.readNFTThumbnail(ctx, metadata.Standard, token, tokenId)
+	if err != nil {
+		return nil, "", err
+	}
+	if !resolved {
+		err = bigtable.queueNFTMetadataUpdate(ctx, metadata.Standard, token, tokenId)
+		if err != nil {
+			return nil, "", err
+		}
+		if thumbnail.Format == "" {
+			return nil, "", ErrNFTMetadataPending
+		}
+		return thumbnail.Data, thumbnail.Format, nil
+	}
+
+	bigtable.cacheNFTThumbnail(token, tokenId, thumbnail)
+	if thumbnail.Format == "" {
+		return nil, "", nil
+	}
+	return thumbnail.Data, thumbnail.Format, nil
+}
+
+func (bigtable *Bigtable) cacheNFTThumbnail(token []byte, tokenId *big.Int, thumbnail *nftThumbnail) {
+	expiration := time.Hour * 24
+	if thumbnail.Format == "" {
+		expiration = nftMetadataRetryAfter
+	}
+	err := cache.TieredCache.Set(bigtable.nftThumbnailCacheKey(token, tokenId), thumbnail, expiration)
+	if err != nil {
+		logger.Errorf("error caching thumbnail of nft %x:%v: %v", token, tokenId, err)
+	}
+}
+
+// ProcessNFTMetadataUpdates resolves the metadata and the thumbnails of up to limit queued tokens and returns the number of
+// processed tokens. Tokens that are left when the deadline is reached stay queued for the next run.
+func (bigtable *Bigtable) ProcessNFTMetadataUpdates(ctx context.Context, limit int64) (int, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Minute*5))
+	defer cancel()
+
+	type queuedToken struct {
+		key      string
+		standard string
+		token    []byte
+		tokenId  *big.Int
+	}
+	keyPrefix := fmt.Sprintf("%s:NFT_METADATA:", bigtable.chainId)
+	queued := []*queuedToken{}
+	err := bigtable.readRows(ctx, bigtable.tableMetadataUpdates, gcp_bigtable.PrefixRange(keyPrefix), func(row gcp_bigtable.Row) bool {
+		parts := strings.Split(strings.TrimPrefix(row.Key(), keyPrefix), ":")
+		tokenId, ok := new(big.Int).SetString(parts[len(parts)-1], 16)
+		if len(parts) != 2 || !ok || len(row[DEFAULT_FAMILY]) == 0 {
+			logger.Warnf("skipping queued nft metadata update with malformed key %v", row.Key())
+			return true
+		}
+		queued = append(queued, &queuedToken{
+			key:      row.Key(),
+			standard: string(row[DEFAULT_FAMILY][0].Value),
+			token:    common.FromHex(parts[0]),
+			tokenId:  tokenId,
+		})
+		return true
+	}, gcp_bigtable.RowFilter(gcp_bigtable.LatestNFilter(1)), gcp_bigtable.LimitRows(limit))
+	if err != nil {
+		return 0, err
+	}
+
+	processed := &types.BulkMutations{}
+	for _, q := range queued {
+		if ctx.Err() != nil {
+			break
+		}
+
+		metadata, resolved, err := bigtable.readNFTMetadata(ctx, q.standard, q.token, q.tokenId)
+		if err != nil {
+			return 0, err
+		}
+		if !resolved {
+			metadata = resolveNFTMetadata(ctx, q.standard, q.token, q.tokenId)
+			b, err := json.Marshal(metadata)
+			if err != nil {
+				return 0, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(nftMetadataFamily(q.standard), NFT_COLUMN_METADATA, gcp_bigtable.Now().TruncateToMilliseconds(), b)
+			err = bigtable.apply(ctx, bigtable.tableMetadata, bigtable.nftMetadataKey(q.token, q.tokenId), mut)
+			if err != nil {
+				return 0, err
+			}
+			bigtable.cacheNFTMetadata(q.token, q.tokenId, metadata)
+		}
+
+		if metadata.Image != "" {
+			_, resolved, err := bigtable.readNFTThumbnail(ctx, q.standard, q.token, q.tokenId)
+			if err != nil {
+				return 0, err
+			}
+			if !resolved {
+				thumbnail := resolveNFTThumbnail(ctx, metadata.Image)
+				ts := gcp_bigtable.Now().TruncateToMilliseconds()
+				mut := gcp_bigtable.NewMutation()
+				mut.Set(nftMetadataFamily(q.standard), NFT_COLUMN_THUMBNAIL, ts, thumbnail.Data)
+				mut.Set(nftMetadataFamily(q.standard), NFT_COLUMN_THUMBNAIL_FORMAT, ts, []byte(thumbnail.Format))
+				err = bigtable.apply(ctx, bigtable.tableMetadata, bigtable.nftMetadataKey(q.token, q.tokenId), mut)
+				if err != nil {
+					return 0, err
+				}
+				bigtable.cacheNFTThumbnail(q.token, q.tokenId, thumbnail)
+			}
+		}
+
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteRow()
+		processed.Keys = append(processed.Keys, q.key)
+		processed.Muts = append(processed.Muts, mut)
+	}
+
+	if len(processed.Keys) == 0 {
+		return 0, nil
+	}
+	return len(processed.Keys), bigtable.WriteBulk(ctx, processed, bigtable.tableMetadataUpdates)
+}
+
+func resolveNFTThumbnail(ctx context.Context, image string) *nftThumbnail {
+	data, mediaType, err := utils.FetchNFTURI(ctx, image, utils.NFTImageMaxBytes)
+	if err != nil {
+		logger.Debugf("error fetching nft image %.100s: %v", image, err)
+		return &nftThumbnail{}
+	}
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+	if !strings.HasPrefix(mediaType, "image/") {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+	}
+
+	thumbnail, err := utils.NFTThumbnail(data, nftThumbnailSize)
+	if err == nil {
+		return &nftThumbnail{Data: thumbnail, Format: "image/png"}
+	}
+	if strings.HasPrefix(mediaType, "image/") && len(data) <= nftThumbnailMaxBytes {
+		return &nftThumbnail{Data: data, Format: mediaType}
+	}
+	return &nftThumbnail{}
+}
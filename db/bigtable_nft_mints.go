@@ -0,0 +1,210 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/erc1155"
+	"eth2-exporter/erc721"
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/common"
+	eth_types "github.com/ethereum/go-ethereum/core/types"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	NFTStandardERC721  = "ERC721"
+	NFTStandardERC1155 = "ERC1155"
+)
+
+// nftTransfersOfTransaction returns the ERC721 and ERC1155 transfers of the logs of a transaction.
+// Batch transfers of ERC1155 tokens result in one transfer per token id.
+func nftTransfersOfTransaction(filterer *erc1155.Erc1155Filterer, blk *types.Eth1Block, txIdx int, tx *types.Eth1Transaction) []*types.NFTTransfer {
+	transfers := []*types.NFTTransfer{}
+	for j, log := range tx.GetLogs() {
+		topics := log.GetTopics()
+		if len(topics) != 4 {
+			continue
+		}
+
+		if bytes.Equal(topics[0], erc721.TransferTopic) {
+			transfers = append(transfers, &types.NFTTransfer{
+				Standard:     NFTStandardERC721,
+				TxHash:       tx.GetHash(),
+				BlockNumber:  blk.GetNumber(),
+				Time:         blk.GetTime().AsTime(),
+				LogIndex:     j,
+				TokenAddress: log.GetAddress(),
+				From:         common.BytesToAddress(topics[1]).Bytes(),
+				To:           common.BytesToAddress(topics[2]).Bytes(),
+				TokenId:      common.BytesToHash(topics[3]).Big().Bytes(),
+				Value:        []byte{1},
+			})
+			continue
+		}
+
+		if (!bytes.Equal(topics[0], erc1155.TransferSingleTopic) && !bytes.Equal(topics[0], erc1155.TransferBulkTopic)) || filterer == nil {
+			continue
+		}
+		ethTopics := make([]common.Hash, 0, len(topics))
+		for _, topic := range topics {
+			ethTopics = append(ethTopics, common.BytesToHash(topic))
+		}
+		ethLog := eth_types.Log{
+			Address:     common.BytesToAddress(log.GetAddress()),
+			Data:        log.GetData(),
+			Topics:      ethTopics,
+			BlockNumber: blk.GetNumber(),
+			TxHash:      common.BytesToHash(tx.GetHash()),
+			TxIndex:     uint(txIdx),
+			BlockHash:   common.BytesToHash(blk.GetHash()),
+			Index:       uint(j),
+			Removed:     log.GetRemoved(),
+		}
+
+		// the operator is the first indexed topic of both ERC1155 transfer events, followed by the from and to address
+		transfer := func(id, value []byte) {
+			transfers = append(transfers, &types.NFTTransfer{
+				Standard:     NFTStandardERC1155,
+				TxHash:       tx.GetHash(),
+				BlockNumber:  blk.GetNumber(),
+				Time:         blk.GetTime().AsTime(),
+				LogIndex:     j,
+				TokenAddress: log.GetAddress(),
+				Operator:     common.BytesToAddress(topics[1]).Bytes(),
+				From:         common.BytesToAddress(topics[2]).Bytes(),
+				To:           common.BytesToAddress(topics[3]).Bytes(),
+				TokenId:      id,
+				Value:        value,
+			})
+		}
+		if transferSingle, _ := filterer.ParseTransferSingle(ethLog); transferSingle != nil {
+			transfer(transferSingle.Id.Bytes(), transferSingle.Value.Bytes())
+		} else if transferBatch, _ := filterer.ParseTransferBatch(ethLog); transferBatch != nil && len(transferBatch.Ids) == len(transferBatch.Values) {
+			for k := range transferBatch.Ids {
+				transfer(transferBatch.Ids[k].Bytes(), transferBatch.Values[k].Bytes())
+			}
+		}
+	}
+	return transfers
+}
+
+// nftMintsOfTransaction returns the ERC721 and ERC1155 tokens minted by a transaction, a mint is a transfer from the zero address
+func nftMintsOfTransaction(filterer *erc1155.Erc1155Filterer, blk *types.Eth1Block, txIdx int, tx *types.Eth1Transaction) []*types.NFTTransfer {
+	mints := []*types.NFTTransfer{}
+	for _, transfer := range nftTransfersOfTransaction(filterer, blk, txIdx, tx) {
+		if bytes.Equal(transfer.From, ZERO_ADDRESS) {
+			mints = append(mints, transfer)
+		}
+	}
+	return mints
+}
+
+// TransformNFTMints extracts all ERC721 and ERC1155 tokens minted in a block
+//
+// It writes the following rows to the data table:
+//
+//	NFT_MINT:<reversePaddedTimestamp>:<txIdx>:<logIdx>:<idIdx>:<standard>
+//
+// The row data is a Eth1ERC1155Indexed message, ERC721 mints always have a value of one and no operator.
+// Rows are sorted by time, with the most recent mint first.
+func (bigtable *Bigtable) TransformNFTMints(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	filterer, err := erc1155.NewErc1155Filterer(common.Address{}, nil)
+	if err != nil {
+		logger.Errorf("error creating filterer: %v", err)
+	}
+
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		if len(tx.GetLogs()) > 100000 {
+			return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", len(tx.GetLogs()), tx.GetHash())
+		}
+
+		idIdx := 0
+		lastLogIdx := -1
+		for _, mint := range nftMintsOfTransaction(filterer, blk, i, tx) {
+			if mint.LogIndex != lastLogIdx {
+				idIdx = 0
+				lastLogIdx = mint.LogIndex
+			}
+			if idIdx > 9999 {
+				// batch mints beyond this size are not tracked individually
+				continue
+			}
+
+			b, err := proto.Marshal(&types.ETh1ERC1155Indexed{
+				ParentHash:   mint.TxHash,
+				BlockNumber:  mint.BlockNumber,
+				TokenAddress: mint.TokenAddress,
+				Time:         blk.GetTime(),
+				From:         mint.From,
+				To:           mint.To,
+				TokenId:      mint.TokenId,
+				Value:        mint.Value,
+				Operator:     mint.Operator,
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			mut := gcp_bigtable.NewMutation()
+			mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
+
+			key := fmt.Sprintf("%s:NFT_MINT:%s:%s:%s:%s:%s", bigtable.chainId, reversePaddedBigtableTimestamp(blk.GetTime()), reversePaddedIndex(i, 10000), reversePaddedIndex(mint.LogIndex, 100000), reversePaddedIndex(idIdx, 10000), mint.Standard)
+			bulkData.Keys = append(bulkData.Keys, key)
+			bulkData.Muts = append(bulkData.Muts, mut)
+			idIdx++
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// GetRecentNFTMints returns the most recent ERC721 and ERC1155 mints
+func (bigtable *Bigtable) GetRecentNFTMints(ctx context.Context, limit int64) ([]*types.NFTTransfer, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:NFT_MINT:", bigtable.chainId)
+
+	mints := make([]*types.NFTTransfer, 0, limit)
+	var rowErr error
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.PrefixRange(prefix), func(row gcp_bigtable.Row) bool {
+		indexed := &types.ETh1ERC1155Indexed{}
+		rowErr = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, indexed)
+		if rowErr != nil {
+			return false
+		}
+		keyParts := strings.Split(row.Key(), ":")
+		mints = append(mints, &types.NFTTransfer{
+			Standard:     keyParts[len(keyParts)-1],
+			TxHash:       indexed.ParentHash,
+			BlockNumber:  indexed.BlockNumber,
+			Time:         indexed.Time.AsTime(),
+			TokenAddress: indexed.TokenAddress,
+			From:         ZERO_ADDRESS,
+			To:           indexed.To,
+			Operator:     indexed.Operator,
+			TokenId:      indexed.TokenId,
+			Value:        indexed.Value,
+		})
+		return true
+	}, gcp_bigtable.LimitRows(limit), skipTombstones())
+	if err != nil {
+		return nil, err
+	}
+	if rowErr != nil {
+		return nil, fmt.Errorf("error parsing Eth1ERC1155Indexed data: %w", rowErr)
+	}
+
+	return mints, nil
+}
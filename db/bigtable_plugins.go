@@ -0,0 +1,174 @@
+package db
+
+import (
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+// TransformFunc is the signature of the transformers the eth1 indexer runs for every block
+type TransformFunc = func(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+
+// TransformerPlugin adds a custom transformer to the eth1 indexer. Plugins register themselves with
+// RegisterTransformerPlugin from the init function of their package, the package is linked into the indexer with a
+// blank import (see cmd/eth1indexer/plugins.go) and the plugin is enabled with the -plugins flag.
+//
+// The transformer of a plugin runs in the same pipeline as the core transformers: its mutations are validated and
+// written together with the mutations of the block and are deleted with the block on reorgs. Plugins only write to the
+// data table and all of their rows have to be keyed below the namespace of the plugin:
+//
+//	<chainId>:P:<name>:...
+type TransformerPlugin interface {
+	// Name is the namespace of the plugin, lower case letters, digits and underscores
+	Name() string
+	// Init is called once before the first block is transformed
+	Init(ctx *TransformerPluginContext) error
+	Transform(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	// Close is called when the indexer shuts down
+	Close() error
+}
+
+// TransformerPluginContext gives a plugin access to the bigtable instance of the indexer and to its keyspace
+type TransformerPluginContext struct {
+	Bigtable *Bigtable
+	name     string
+}
+
+// Prefix returns the prefix all row keys of the plugin have to start with
+func (ctx *TransformerPluginContext) Prefix() string {
+	return transformerPluginPrefix(ctx.Bigtable.chainId, ctx.name)
+}
+
+// RowKey returns the row key of the plugin for the given parts, which are joined by colons
+func (ctx *TransformerPluginContext) RowKey(parts ...string) string {
+	return ctx.Prefix() + strings.Join(parts, ":")
+}
+
+func transformerPluginPrefix(chainId, name string) string {
+	return fmt.Sprintf("%s:P:%s:", chainId, name)
+}
+
+var transformerPluginNameRe = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+var transformerPlugins = make(map[string]TransformerPlugin)
+var transformerPluginsMux = &sync.Mutex{}
+
+// RegisterTransformerPlugin makes a plugin available to the eth1 indexer, it panics on invalid or duplicate names as it
+// is meant to be called from init functions
+func RegisterTransformerPlugin(plugin TransformerPlugin) {
+	transformerPluginsMux.Lock()
+	defer transformerPluginsMux.Unlock()
+
+	name := plugin.Name()
+	if !transformerPluginNameRe.MatchString(name) {
+		panic(fmt.Sprintf("invalid transformer plugin name %q", name))
+	}
+	if transformerPlugins[name] != nil {
+		panic(fmt.Sprintf("transformer plugin %v registered twice", name))
+	}
+	transformerPlugins[name] = plugin
+}
+
+// RegisteredTransformerPlugins returns the names of the registered plugins in alphabetical order
+func RegisteredTransformerPlugins() []string {
+	transformerPluginsMux.Lock()
+	defer transformerPluginsMux.Unlock()
+
+	names := make([]string, 0, len(transformerPlugins))
+	for name := range transformerPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TransformerPlugins holds the initialized plugins of an indexer run
+type TransformerPlugins struct {
+	chainId string
+	plugins []TransformerPlugin
+}
+
+// InitTransformerPlugins initializes the registered plugins with the given names, the plugins initialized so far are
+// closed if one of them fails
+func (bigtable *Bigtable) InitTransformerPlugins(names []string) (*TransformerPlugins, error) {
+	transformerPluginsMux.Lock()
+	defer transformerPluginsMux.Unlock()
+
+	initialized := &TransformerPlugins{chainId: bigtable.chainId}
+	for _, name := range names {
+		plugin := transformerPlugins[name]
+		if plugin == nil {
+			initialized.Close()
+			return nil, fmt.Errorf("unknown transformer plugin %v", name)
+		}
+		err := plugin.Init(&TransformerPluginContext{Bigtable: bigtable, name: name})
+		if err != nil {
+			initialized.Close()
+			return nil, fmt.Errorf("error initializing transformer plugin %v: %w", name, err)
+		}
+		initialized.plugins = append(initialized.plugins, plugin)
+	}
+	return initialized, nil
+}
+
+// Transforms returns the transformers of the plugins, wrapped to check the keyspace of the rows and to record the
+// per plugin metrics
+func (p *TransformerPlugins) Transforms() []TransformFunc {
+	transforms := make([]TransformFunc, 0, len(p.plugins))
+	for _, plugin := range p.plugins {
+		transforms = append(transforms, transformerPluginTransform(plugin, p.chainId))
+	}
+	return transforms
+}
+
+// Close closes all plugins, errors are logged
+func (p *TransformerPlugins) Close() {
+	for _, plugin := range p.plugins {
+		err := plugin.Close()
+		if err != nil {
+			logger.WithError(err).Errorf("error closing transformer plugin %v", plugin.Name())
+		}
+	}
+	p.plugins = nil
+}
+
+func transformerPluginTransform(plugin TransformerPlugin, chainId string) TransformFunc {
+	name := plugin.Name()
+	prefix := transformerPluginPrefix(chainId, name)
+	return func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error) {
+		start := time.Now()
+		bulkData, bulkMetadataUpdates, err := plugin.Transform(blk, cache)
+		metrics.TransformerPluginDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+		if err == nil && bulkMetadataUpdates != nil && len(bulkMetadataUpdates.Keys) > 0 {
+			err = fmt.Errorf("plugins must not write metadata updates")
+		}
+		if err == nil && bulkData != nil {
+			err = checkTransformerPluginKeys(prefix, bulkData)
+		}
+		if err != nil {
+			metrics.TransformerPluginErrors.WithLabelValues(name).Inc()
+			return nil, nil, fmt.Errorf("transformer plugin %v: %w", name, err)
+		}
+		if bulkData == nil {
+			bulkData = &types.BulkMutations{}
+		}
+		metrics.TransformerPluginMutations.WithLabelValues(name).Add(float64(len(bulkData.Muts)))
+		return bulkData, &types.BulkMutations{}, nil
+	}
+}
+
+func checkTransformerPluginKeys(prefix string, bulk *types.BulkMutations) error {
+	for _, key := range bulk.Keys {
+		if !strings.HasPrefix(key, prefix) {
+			return fmt.Errorf("row %v is outside of the keyspace %v of the plugin", key, prefix)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"testing"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+)
+
+type testTransformerPlugin struct {
+	ctx      *TransformerPluginContext
+	keys     func(ctx *TransformerPluginContext, blk *types.Eth1Block) []string
+	metadata bool
+	closed   bool
+}
+
+func (p *testTransformerPlugin) Name() string { return "test_plugin" }
+
+func (p *testTransformerPlugin) Init(ctx *TransformerPluginContext) error {
+	p.ctx = ctx
+	return nil
+}
+
+func (p *testTransformerPlugin) Transform(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error) {
+	bulkData := &types.BulkMutations{}
+	for _, key := range p.keys(p.ctx, blk) {
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), []byte{0x01})
+		bulkData.Keys = append(bulkData.Keys, key)
+		bulkData.Muts = append(bulkData.Muts, mut)
+	}
+	bulkMetadataUpdates := &types.BulkMutations{}
+	if p.metadata {
+		bulkMetadataUpdates.Keys = append(bulkMetadataUpdates.Keys, "1:B:0101")
+		bulkMetadataUpdates.Muts = append(bulkMetadataUpdates.Muts, gcp_bigtable.NewMutation())
+	}
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+func (p *testTransformerPlugin) Close() error {
+	p.closed = true
+	return nil
+}
+
+func TestTransformerPlugins(t *testing.T) {
+	plugin := &testTransformerPlugin{keys: func(ctx *TransformerPluginContext, blk *types.Eth1Block) []string {
+		return []string{ctx.RowKey("POOL", fmt.Sprintf("%d", blk.GetNumber()))}
+	}}
+	RegisterTransformerPlugin(plugin)
+	defer func() {
+		transformerPluginsMux.Lock()
+		delete(transformerPlugins, plugin.Name())
+		transformerPluginsMux.Unlock()
+	}()
+
+	bt := &Bigtable{chainId: "1"}
+	if _, err := bt.InitTransformerPlugins([]string{"unknown"}); err == nil {
+		t.Errorf("expected an error for an unknown plugin")
+	}
+	plugins, err := bt.InitTransformerPlugins([]string{"test_plugin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	transforms := plugins.Transforms()
+	if len(transforms) != 1 {
+		t.Fatalf("expected one transform, got %v", len(transforms))
+	}
+
+	block := &types.Eth1Block{Number: 100}
+	bulkData, _, err := transforms[0](block, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bulkData.Keys) != 1 || bulkData.Keys[0] != "1:P:test_plugin:POOL:100" {
+		t.Errorf("unexpected keys %v", bulkData.Keys)
+	}
+
+	plugin.keys = func(ctx *TransformerPluginContext, blk *types.Eth1Block) []string {
+		return []string{"1:TX:0101"}
+	}
+	if _, _, err := transforms[0](block, nil); err == nil || !strings.Contains(err.Error(), "outside of the keyspace") {
+		t.Errorf("expected rows outside of the namespace to be rejected, got %v", err)
+	}
+
+	plugin.keys = func(ctx *TransformerPluginContext, blk *types.Eth1Block) []string { return nil }
+	plugin.metadata = true
+	if _, _, err := transforms[0](block, nil); err == nil {
+		t.Errorf("expected metadata updates to be rejected")
+	}
+
+	plugins.Close()
+	if !plugin.closed {
+		t.Errorf("expected the plugin to be closed")
+	}
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/coocood/freecache"
+)
+
+// DeleteOrphanedBlock deletes a block that is no longer part of the canonical chain and tombstones all rows derived from it,
+// the address summary, counterparty and contract interaction deltas of the block that have not been folded yet are deleted.
+// The derived rows are taken from the keys saved while indexing the block, if those are not available (e.g. for blocks indexed
+// before the keys were recorded) the keys are derived by running the transforms on the orphaned block again.
+func (bigtable *Bigtable) DeleteOrphanedBlock(ctx context.Context, block *types.Eth1Block, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) error {
+	keys, err := bigtable.GetBlockKeys(ctx, block.Number, block.Hash)
+	if errors.Is(err, ErrBlockKeysNotFound) {
+		logger.Warnf("no keys saved for orphaned block %v (0x%x), deriving them from the transforms", block.Number, block.Hash)
+		keys, err = transformedBlockKeys(block, transforms)
+	}
+	if err != nil {
+		return fmt.Errorf("error getting keys of orphaned block %v (0x%x): %w", block.Number, block.Hash, err)
+	}
+
+	err = bigtable.deleteAddressSummaryDeltas(ctx, block)
+	if err != nil {
+		return fmt.Errorf("error deleting address summary deltas of orphaned block %v (0x%x): %w", block.Number, block.Hash, err)
+	}
+
+	err = bigtable.deleteCounterpartyDeltas(ctx, block)
+	if err != nil {
+		return fmt.Errorf("error deleting counterparty deltas of orphaned block %v (0x%x): %w", block.Number, block.Hash, err)
+	}
+
+	err = bigtable.deleteContractInteractionDeltas(ctx, block)
+	if err != nil {
+		return fmt.Errorf("error deleting contract interaction deltas of orphaned block %v (0x%x): %w", block.Number, block.Hash, err)
+	}
+
+	err = bigtable.deleteBurnedFeesDelta(ctx, block)
+	if err != nil {
+		return fmt.Errorf("error deleting the burned fees delta of orphaned block %v (0x%x): %w", block.Number, block.Hash, err)
+	}
+
+	return bigtable.deleteBlockRows(ctx, block, keys)
+}
+
+// transformedBlockKeys returns the keys of all data table rows the transforms write for a block
+func transformedBlockKeys(block *types.Eth1Block, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) ([]string, error) {
+	// the cache only suppresses repeated balance updates which are written to the metadata updates table
+	cache := freecache.NewCache(1024 * 1024)
+
+	keys := []string{}
+	for _, transform := range transforms {
+		data, _, err := transform(block, cache)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, data.Keys...)
+	}
+	return keys, nil
+}
+
+// ReplaceBlock saves a block to the blocks table. If a block with a different hash has already been saved at the same height,
+// the rows of that orphaned block are tombstoned first so that the data table does not keep serving them, the orphaned block is returned in that case.
+// The canonical block has to be indexed into the data table again afterwards.
+func (bigtable *Bigtable) ReplaceBlock(ctx context.Context, block *types.Eth1Block, transforms []func(blk *types.Eth1Block, cache *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error)) (*types.Eth1Block, error) {
+	existing, err := bigtable.readBlock(ctx, block.Number)
+	if err != nil && err != ErrBlockNotFound {
+		return nil, err
+	}
+
+	var orphaned *types.Eth1Block
+	if existing != nil && !bytes.Equal(existing.Hash, block.Hash) {
+		logger.Warnf("replacing block %v (0x%x) with block 0x%x", existing.Number, existing.Hash, block.Hash)
+		err = bigtable.DeleteOrphanedBlock(ctx, existing, transforms)
+		if err != nil {
+			return nil, err
+		}
+		orphaned = existing
+	}
+
+	err = bigtable.SaveBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"eth2-exporter/metrics"
+	"math/rand"
+	"sync"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+const (
+	defaultReadAttempts = 3
+	readBackoffMin      = time.Millisecond * 100
+	readBackoffMax      = time.Second * 2
+	// number of consecutive reads that failed with a transient error after which reads fail fast for the cooldown
+	readBreakerThreshold = 20
+	readBreakerCooldown  = time.Second * 10
+)
+
+// ErrBigtableUnavailable is returned by reads while the circuit breaker is open
+var ErrBigtableUnavailable = errors.New("bigtable is unavailable, reads are suspended after repeated failures")
+
+// readBreaker stops reads from queueing up behind an unavailable bigtable instance. After readBreakerThreshold consecutive
+// failed reads it opens for readBreakerCooldown, afterwards a single failed read opens it again until a read succeeds.
+type readBreaker struct {
+	mux       sync.Mutex
+	failures  int
+	openUntil time.Time
+	halfOpen  bool
+}
+
+func (b *readBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if time.Now().Before(b.openUntil) {
+		return ErrBigtableUnavailable
+	}
+	return nil
+}
+
+func (b *readBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.failures = 0
+	b.halfOpen = false
+}
+
+func (b *readBreaker) failure() {
+	if b == nil {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.failures++
+	if b.halfOpen || b.failures >= readBreakerThreshold {
+		logger.Warnf("suspending bigtable reads for %v after %v consecutive failures", readBreakerCooldown, b.failures)
+		metrics.BigtableReadBreakerOpened.Inc()
+		b.openUntil = time.Now().Add(readBreakerCooldown)
+		b.failures = 0
+		b.halfOpen = true
+	}
+}
+
+// readBackoff returns the jittered wait before the given retry of a read
+func readBackoff(retry int) time.Duration {
+	backoff := readBackoffMin << retry
+	if backoff > readBackoffMax || backoff <= 0 {
+		backoff = readBackoffMax
+	}
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// retryRead runs read until it succeeds, fails with a permanent error or the attempts are used up. A read is only retried
+// if it has not passed any row to its caller yet, it is up to read to report that.
+func (bigtable *Bigtable) retryRead(ctx context.Context, read func() (delivered bool, err error)) error {
+	err := bigtable.readBreaker.allow()
+	if err != nil {
+		return err
+	}
+
+	attempts := bigtable.readAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		delivered, err := read()
+		if err == nil {
+			bigtable.readBreaker.success()
+			return nil
+		}
+		// a read that has been canceled or ran out of time says nothing about the availability of bigtable
+		if !isTransientBigtableError(err) || ctx.Err() != nil {
+			return err
+		}
+		if delivered || attempt >= attempts {
+			bigtable.readBreaker.failure()
+			return err
+		}
+
+		metrics.BigtableReadRetries.Inc()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(readBackoff(attempt - 1)):
+		}
+	}
+}
+
+// readRows wraps table.ReadRows with retries of transient errors that occur before the first row has been read
+func (bigtable *Bigtable) readRows(ctx context.Context, table *gcp_bigtable.Table, rowSet gcp_bigtable.RowSet, f func(gcp_bigtable.Row) bool, opts ...gcp_bigtable.ReadOption) error {
+	return bigtable.retryRead(ctx, func() (bool, error) {
+		ctx, call := startBigtableCall(ctx, "ReadRows")
+		read := 0
+		err := table.ReadRows(ctx, rowSet, func(row gcp_bigtable.Row) bool {
+			read++
+			return f(row)
+		}, opts...)
+		call.end(read, err)
+		return read > 0, err
+	})
+}
+
+// readRow wraps table.ReadRow with retries of transient errors
+func (bigtable *Bigtable) readRow(ctx context.Context, table *gcp_bigtable.Table, key string, opts ...gcp_bigtable.ReadOption) (gcp_bigtable.Row, error) {
+	var row gcp_bigtable.Row
+	err := bigtable.retryRead(ctx, func() (bool, error) {
+		ctx, call := startBigtableCall(ctx, "ReadRow")
+		var err error
+		row, err = table.ReadRow(ctx, key, opts...)
+		read := 0
+		if row != nil {
+			read = 1
+		}
+		call.end(read, err)
+		return false, err
+	})
+	return row, err
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryRead(t *testing.T) {
+	bt := &Bigtable{readAttempts: 3, readBreaker: &readBreaker{}}
+	unavailable := status.Error(codes.Unavailable, "unavailable")
+
+	calls := 0
+	err := bt.retryRead(context.Background(), func() (bool, error) {
+		calls++
+		if calls < 3 {
+			return false, unavailable
+		}
+		return false, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected the read to succeed on the third attempt, got %v after %v calls", err, calls)
+	}
+
+	calls = 0
+	err = bt.retryRead(context.Background(), func() (bool, error) {
+		calls++
+		return true, unavailable
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("a read that already delivered rows must not be retried, got %v after %v calls", err, calls)
+	}
+
+	calls = 0
+	err = bt.retryRead(context.Background(), func() (bool, error) {
+		calls++
+		return false, status.Error(codes.InvalidArgument, "invalid")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("a permanent error must not be retried, got %v after %v calls", err, calls)
+	}
+}
+
+func TestReadBreaker(t *testing.T) {
+	b := &readBreaker{}
+	for i := 0; i < readBreakerThreshold-1; i++ {
+		b.failure()
+	}
+	if err := b.allow(); err != nil {
+		t.Fatalf("breaker opened before reaching the threshold: %v", err)
+	}
+	b.failure()
+	if err := b.allow(); !errors.Is(err, ErrBigtableUnavailable) {
+		t.Fatalf("expected the breaker to be open, got %v", err)
+	}
+
+	// once the cooldown has passed a single failure opens the breaker again
+	b.openUntil = b.openUntil.Add(-readBreakerCooldown)
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected the breaker to allow a probe after the cooldown, got %v", err)
+	}
+	b.failure()
+	if err := b.allow(); !errors.Is(err, ErrBigtableUnavailable) {
+		t.Fatalf("expected a failed probe to open the breaker again, got %v", err)
+	}
+
+	b.openUntil = b.openUntil.Add(-readBreakerCooldown)
+	b.success()
+	b.failure()
+	if err := b.allow(); err != nil {
+		t.Fatalf("expected a successful read to close the breaker, got %v", err)
+	}
+}
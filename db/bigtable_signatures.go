@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+// Method signatures that are not part of the imported 4byte directory (see cmd/signatures) are resolved on demand and
+// persisted to the metadata table. Signatures are the same on every chain, the rows therefore use the chain id of mainnet:
+// Row:    1:METHOD_SIGNATURE:<0xMethodId>
+// Family: sig
+// Column: text
+// Cell:   text signature, e.g. transfer(address,uint256), or empty if the directory does not know the method id
+const (
+	SIGNATURE_FAMILY      = "sig"
+	SIGNATURE_TEXT_COLUMN = "text"
+)
+
+// signatureChainId is the chain id the signature rows of all chains sharing a bigtable instance are stored under
+const signatureChainId = "1"
+
+const fourByteSignaturesUrl = "https://www.4byte.directory/api/v1/signatures/?hex_signature=%s"
+
+// unknown method ids are looked up in the 4byte directory again once their last lookup is older than this
+const unknownSignatureRetryInterval = time.Hour * 24 * 7
+
+var (
+	signatureLookups singleflight.Group
+	fourByteClient   = &http.Client{Timeout: time.Second * 5}
+)
+
+// ResolveMethodSignature returns the text signature of a 4 byte method id, an empty string is returned if the method id is unknown
+func (bigtable *Bigtable) ResolveMethodSignature(ctx context.Context, id []byte) (string, error) {
+	if len(id) != 4 {
+		return "", nil
+	}
+	hex := fmt.Sprintf("0x%x", id)
+
+	cacheKey := fmt.Sprintf("M:H2S:%s", hex)
+	if wanted, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Hour); err == nil {
+		return wanted, nil
+	}
+
+	sig, err, _ := signatureLookups.Do(hex, func() (interface{}, error) {
+		return bigtable.lookupMethodSignature(ctx, hex)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = cache.TieredCache.SetString(cacheKey, sig.(string), time.Hour)
+	if err != nil {
+		logger.Errorf("error caching signature of method %v: %v", hex, err)
+	}
+	return sig.(string), nil
+}
+
+// ResolveMethodSignatures resolves the text signatures of a list of method ids, the result is keyed by the method id bytes.
+// Method ids that can not be resolved are missing from the result.
+func (bigtable *Bigtable) ResolveMethodSignatures(ctx context.Context, ids [][]byte) map[string]string {
+	signatures := make(map[string]string, len(ids))
+	mux := sync.Mutex{}
+
+	g := new(errgroup.Group)
+	g.SetLimit(8)
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		id := id
+		if len(id) != 4 || seen[string(id)] {
+			continue
+		}
+		seen[string(id)] = true
+		g.Go(func() error {
+			sig, err := bigtable.ResolveMethodSignature(ctx, id)
+			if err != nil {
+				logger.Warnf("error resolving signature of method 0x%x: %v", id, err)
+				return nil
+			}
+			if sig != "" {
+				mux.Lock()
+				signatures[string(id)] = sig
+				mux.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return signatures
+}
+
+func (bigtable *Bigtable) lookupMethodSignature(ctx context.Context, hex string) (string, error) {
+	imported, err := bigtable.GetSignature(ctx, hex, types.MethodSignature)
+	if err != nil {
+		return "", err
+	}
+	if imported != nil {
+		return *imported, nil
+	}
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	rowKey := fmt.Sprintf("%s:METHOD_SIGNATURE:%s", signatureChainId, hex)
+	row, err := bigtable.readRow(ctx, bigtable.tableMetadata, rowKey, gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(gcp_bigtable.FamilyFilter(SIGNATURE_FAMILY), gcp_bigtable.LatestNFilter(1))))
+	if err != nil {
+		return "", err
+	}
+	if len(row[SIGNATURE_FAMILY]) > 0 {
+		item := row[SIGNATURE_FAMILY][0]
+		if len(item.Value) > 0 || time.Since(item.Timestamp.Time()) < unknownSignatureRetryInterval {
+			return string(item.Value), nil
+		}
+	}
+
+	sig, err := fetchFourByteSignature(hex)
+	if err != nil {
+		return "", err
+	}
+
+	// unknown method ids are persisted as well, the timestamp of the cell tells when to look them up again
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(SIGNATURE_FAMILY, SIGNATURE_TEXT_COLUMN, gcp_bigtable.Time(time.Now()), []byte(sig))
+	err = bigtable.apply(ctx, bigtable.tableMetadata, rowKey, mut)
+	if err != nil {
+		return "", err
+	}
+	return sig, nil
+}
+
+// fetchFourByteSignature queries the 4byte directory for a method id. If several signatures share the method id the one
+// that has been submitted first is returned, as later submissions are frequently crafted collisions.
+func fetchFourByteSignature(hex string) (string, error) {
+	resp, err := fourByteClient.Get(fmt.Sprintf(fourByteSignaturesUrl, hex))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error querying signatures api: %v", resp.Status)
+	}
+
+	respParsed := &struct {
+		Results []types.Signature `json:"results"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(respParsed)
+	if err != nil {
+		return "", err
+	}
+	if len(respParsed.Results) == 0 {
+		return "", nil
+	}
+
+	sort.Slice(respParsed.Results, func(i, j int) bool {
+		return respParsed.Results[i].Id < respParsed.Results[j].Id
+	})
+	return respParsed.Results[0].Text, nil
+}
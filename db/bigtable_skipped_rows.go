@@ -0,0 +1,93 @@
+package db
+
+import (
+	"errors"
+	"eth2-exporter/metrics"
+	"fmt"
+	"sync"
+)
+
+// SkippedRowsError is returned together with the readable part of a result if some rows had to be skipped, either because
+// they could not be decoded or because an index row points to a data row that does not exist.
+// The data returned along with the error is usable but incomplete.
+type SkippedRowsError struct {
+	Type      string
+	Corrupted []string
+	Missing   []string
+}
+
+func (e *SkippedRowsError) Error() string {
+	return fmt.Sprintf("skipped %v corrupted and %v missing %v rows", len(e.Corrupted), len(e.Missing), e.Type)
+}
+
+// IsPartialResult returns true if err only reports rows that have been skipped while reading
+func IsPartialResult(err error) bool {
+	var skipped *SkippedRowsError
+	return errors.As(err, &skipped)
+}
+
+// PartialResultWarnings returns the warnings shown to users for a partial result, nil is returned for any other error
+func PartialResultWarnings(err error) []string {
+	var skipped *SkippedRowsError
+	if !errors.As(err, &skipped) {
+		return nil
+	}
+	count := len(skipped.Corrupted) + len(skipped.Missing)
+	if count == 1 {
+		return []string{"1 entry could not be loaded and is missing from this page"}
+	}
+	return []string{fmt.Sprintf("%v entries could not be loaded and are missing from this page", count)}
+}
+
+// skippedRows quarantines the rows of a read that can not be decoded or resolved instead of aborting the read
+type skippedRows struct {
+	mux       sync.Mutex
+	typ       string
+	read      map[string]bool
+	corrupted []string
+	missing   []string
+}
+
+func newSkippedRows(typ string) *skippedRows {
+	return &skippedRows{typ: typ, read: make(map[string]bool)}
+}
+
+func (s *skippedRows) corrupt(key string, err error) {
+	metrics.BigtableCorruptedRows.WithLabelValues(s.typ).Inc()
+	logger.WithError(err).WithField("key", key).Errorf("skipping corrupted %v row", s.typ)
+
+	s.mux.Lock()
+	s.corrupted = append(s.corrupted, key)
+	s.mux.Unlock()
+}
+
+// found marks a data row as read, see checkMissing
+func (s *skippedRows) found(key string) {
+	s.mux.Lock()
+	s.read[key] = true
+	s.mux.Unlock()
+}
+
+// checkMissing records all keys that have not been found, it is called with the data row keys referenced by an index once they have been read
+func (s *skippedRows) checkMissing(keys []string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	for _, key := range keys {
+		if s.read[key] {
+			continue
+		}
+		metrics.BigtableMissingRows.WithLabelValues(s.typ).Inc()
+		logger.WithField("key", key).Warnf("skipping missing %v row", s.typ)
+		s.missing = append(s.missing, key)
+	}
+}
+
+// err returns a *SkippedRowsError if any row has been skipped, nil otherwise
+func (s *skippedRows) err() error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if len(s.corrupted) == 0 && len(s.missing) == 0 {
+		return nil
+	}
+	return &SkippedRowsError{Type: s.typ, Corrupted: s.corrupted, Missing: s.missing}
+}
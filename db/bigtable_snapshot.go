@@ -0,0 +1,391 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/types"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// A snapshot stores the rows of a set of tables as gzipped chunks that are named by the sha256 checksum of their content,
+// so chunks that did not change since a previous snapshot are not uploaded again. The manifest of a snapshot lists the
+// chunks of every table in row key order and is the only object that identifies a snapshot.
+const (
+	snapshotManifestPrefix   = "manifests/"
+	snapshotChunkPrefix      = "chunks/"
+	DefaultSnapshotChunkRows = 5000
+)
+
+// SnapshotTables are the tables a snapshot contains if no tables are specified
+var SnapshotTables = []string{"data", "blocks", "metadata_updates", "metadata", "beaconchain", "machine_metrics"}
+
+// ErrSnapshotChecksumMismatch is returned by a restore if the content of a chunk does not match its checksum
+var ErrSnapshotChecksumMismatch = errors.New("snapshot chunk does not match its checksum")
+
+// SnapshotStore is the object storage the chunks and manifests of snapshots are kept in
+type SnapshotStore interface {
+	Exists(ctx context.Context, name string) (bool, error)
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// SnapshotManifest describes a snapshot, the chunks of each table cover the rows of the exported range in key order
+type SnapshotManifest struct {
+	ID        string           `json:"id"`
+	ChainId   string           `json:"chain_id"`
+	CreatedTs time.Time        `json:"created_ts"`
+	Start     string           `json:"start"`
+	End       string           `json:"end"`
+	Tables    []*SnapshotTable `json:"tables"`
+}
+
+type SnapshotTable struct {
+	Name   string           `json:"name"`
+	Rows   uint64           `json:"rows"`
+	Chunks []*SnapshotChunk `json:"chunks"`
+}
+
+type SnapshotChunk struct {
+	Checksum string `json:"checksum"`
+	FirstKey string `json:"first_key"`
+	LastKey  string `json:"last_key"`
+	Rows     int    `json:"rows"`
+	Size     int    `json:"size"`
+}
+
+type snapshotCell struct {
+	Family    string
+	Column    string
+	Timestamp int64
+	Value     []byte
+}
+
+type snapshotRow struct {
+	Key   string
+	Cells []snapshotCell
+}
+
+// ExportSnapshot writes the rows of the tables within [start, end) to the store and saves the manifest of the snapshot
+// once all chunks have been written. An empty end exports the tables up to their last row.
+func (bigtable *Bigtable) ExportSnapshot(ctx context.Context, store SnapshotStore, tables []string, start, end string, chunkRows int) (*SnapshotManifest, error) {
+	if len(tables) == 0 {
+		tables = SnapshotTables
+	}
+	if chunkRows < 1 {
+		chunkRows = DefaultSnapshotChunkRows
+	}
+
+	now := time.Now().UTC()
+	manifest := &SnapshotManifest{
+		ID:        now.Format("20060102T150405Z"),
+		ChainId:   bigtable.chainId,
+		CreatedTs: now,
+		Start:     start,
+		End:       end,
+	}
+	for _, name := range tables {
+		table, err := bigtable.exportSnapshotTable(ctx, store, name, start, end, chunkRows)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Tables = append(manifest.Tables, table)
+	}
+
+	encoded, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	err = store.Put(ctx, snapshotManifestPrefix+manifest.ID+".json", encoded)
+	if err != nil {
+		return nil, fmt.Errorf("error saving manifest of snapshot %v: %w", manifest.ID, err)
+	}
+	return manifest, nil
+}
+
+func (bigtable *Bigtable) exportSnapshotTable(ctx context.Context, store SnapshotStore, name, start, end string, chunkRows int) (*SnapshotTable, error) {
+	table := bigtable.client.Open(name)
+	snapshot := &SnapshotTable{Name: name}
+
+	cursor := start
+	for {
+		rows := make([]snapshotRow, 0, chunkRows)
+		readCtx, cancel := context.WithTimeout(ctx, time.Minute*5)
+		err := bigtable.readRows(readCtx, table, snapshotRowRange(cursor, end), func(row gcp_bigtable.Row) bool {
+			rows = append(rows, snapshotRowFromBigtable(row))
+			return true
+		}, gcp_bigtable.LimitRows(int64(chunkRows)))
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("error reading rows of table %v starting at %v: %w", name, cursor, err)
+		}
+		if len(rows) == 0 {
+			return snapshot, nil
+		}
+
+		chunk, err := putSnapshotChunk(ctx, store, rows)
+		if err != nil {
+			return nil, fmt.Errorf("error saving chunk of table %v starting at %v: %w", name, cursor, err)
+		}
+		snapshot.Chunks = append(snapshot.Chunks, chunk)
+		snapshot.Rows += uint64(chunk.Rows)
+		logger.Infof("exported %v rows of table %v up to key %v", snapshot.Rows, name, chunk.LastKey)
+
+		if len(rows) < chunkRows {
+			return snapshot, nil
+		}
+		cursor = chunk.LastKey + "\x00"
+	}
+}
+
+func snapshotRowRange(start, end string) gcp_bigtable.RowRange {
+	if end == "" {
+		return gcp_bigtable.InfiniteRange(start)
+	}
+	return gcp_bigtable.NewRange(start, end)
+}
+
+func snapshotRowFromBigtable(row gcp_bigtable.Row) snapshotRow {
+	families := make([]string, 0, len(row))
+	for family := range row {
+		families = append(families, family)
+	}
+	sort.Strings(families)
+
+	r := snapshotRow{Key: row.Key()}
+	for _, family := range families {
+		for _, item := range row[family] {
+			r.Cells = append(r.Cells, snapshotCell{
+				Family:    family,
+				Column:    strings.TrimPrefix(item.Column, family+":"),
+				Timestamp: int64(item.Timestamp),
+				Value:     item.Value,
+			})
+		}
+	}
+	return r
+}
+
+// putSnapshotChunk encodes the rows and uploads them unless a chunk with the same content already exists
+func putSnapshotChunk(ctx context.Context, store SnapshotStore, rows []snapshotRow) (*SnapshotChunk, error) {
+	encoded, err := encodeSnapshotChunk(rows)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(encoded)
+	chunk := &SnapshotChunk{
+		Checksum: hex.EncodeToString(sum[:]),
+		FirstKey: rows[0].Key,
+		LastKey:  rows[len(rows)-1].Key,
+		Rows:     len(rows),
+		Size:     len(encoded),
+	}
+
+	exists, err := store.Exists(ctx, snapshotChunkPrefix+chunk.Checksum)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = store.Put(ctx, snapshotChunkPrefix+chunk.Checksum, encoded)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chunk, nil
+}
+
+func encodeSnapshotChunk(rows []snapshotRow) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	err := gob.NewEncoder(zw).Encode(rows)
+	if err != nil {
+		return nil, err
+	}
+	err = zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSnapshotChunk(chunk *SnapshotChunk, encoded []byte) ([]snapshotRow, error) {
+	sum := sha256.Sum256(encoded)
+	if hex.EncodeToString(sum[:]) != chunk.Checksum {
+		return nil, fmt.Errorf("%w: chunk %v", ErrSnapshotChecksumMismatch, chunk.Checksum)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	rows := []snapshotRow{}
+	err = gob.NewDecoder(zr).Decode(&rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != chunk.Rows {
+		return nil, fmt.Errorf("error chunk %v contains %v rows, expected %v", chunk.Checksum, len(rows), chunk.Rows)
+	}
+	return rows, nil
+}
+
+// GetSnapshotManifest returns the manifest of the snapshot with the given id
+func GetSnapshotManifest(ctx context.Context, store SnapshotStore, id string) (*SnapshotManifest, error) {
+	encoded, err := store.Get(ctx, snapshotManifestPrefix+id+".json")
+	if err != nil {
+		return nil, fmt.Errorf("error getting manifest of snapshot %v: %w", id, err)
+	}
+	manifest := &SnapshotManifest{}
+	err = json.Unmarshal(encoded, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding manifest of snapshot %v: %w", id, err)
+	}
+	return manifest, nil
+}
+
+// ListSnapshots returns the ids of all snapshots in the store, ordered from oldest to newest
+func ListSnapshots(ctx context.Context, store SnapshotStore) ([]string, error) {
+	names, err := store.List(ctx, snapshotManifestPrefix)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(strings.TrimPrefix(name, snapshotManifestPrefix), ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// RestoreSnapshot replays the chunks of a snapshot into the tables of this instance, the checksum of every chunk is
+// verified before its rows are written. Rows that have been written after the snapshot was taken are kept, restore into
+// empty tables to get an exact copy. If tables is empty all tables of the snapshot are restored.
+func (bigtable *Bigtable) RestoreSnapshot(ctx context.Context, store SnapshotStore, id string, tables []string) error {
+	manifest, err := GetSnapshotManifest(ctx, store, id)
+	if err != nil {
+		return err
+	}
+	if manifest.ChainId != bigtable.chainId {
+		return fmt.Errorf("error snapshot %v has been taken of chain %v, cannot restore it to chain %v", id, manifest.ChainId, bigtable.chainId)
+	}
+
+	restore := make(map[string]bool, len(tables))
+	for _, name := range tables {
+		restore[name] = true
+	}
+
+	for _, snapshot := range manifest.Tables {
+		if len(restore) > 0 && !restore[snapshot.Name] {
+			continue
+		}
+		table := bigtable.client.Open(snapshot.Name)
+		restored := 0
+		for _, chunk := range snapshot.Chunks {
+			encoded, err := store.Get(ctx, snapshotChunkPrefix+chunk.Checksum)
+			if err != nil {
+				return fmt.Errorf("error getting chunk %v of table %v: %w", chunk.Checksum, snapshot.Name, err)
+			}
+			rows, err := decodeSnapshotChunk(chunk, encoded)
+			if err != nil {
+				return err
+			}
+
+			err = bigtable.WriteBulk(ctx, snapshotMutations(rows), table)
+			if err != nil {
+				return fmt.Errorf("error restoring chunk %v of table %v: %w", chunk.Checksum, snapshot.Name, err)
+			}
+			restored += len(rows)
+			logger.Infof("restored %v of %v rows of table %v", restored, snapshot.Rows, snapshot.Name)
+		}
+	}
+	return nil
+}
+
+func snapshotMutations(rows []snapshotRow) *types.BulkMutations {
+	muts := &types.BulkMutations{
+		Keys: make([]string, 0, len(rows)),
+		Muts: make([]*gcp_bigtable.Mutation, 0, len(rows)),
+	}
+	for _, row := range rows {
+		mut := gcp_bigtable.NewMutation()
+		for _, cell := range row.Cells {
+			mut.Set(cell.Family, cell.Column, gcp_bigtable.Timestamp(cell.Timestamp), cell.Value)
+		}
+		muts.Keys = append(muts.Keys, row.Key)
+		muts.Muts = append(muts.Muts, mut)
+	}
+	return muts
+}
+
+type gcsSnapshotStore struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// NewGCSSnapshotStore returns a snapshot store that keeps the objects in the bucket below the prefix
+func NewGCSSnapshotStore(bucket *storage.BucketHandle, prefix string) SnapshotStore {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &gcsSnapshotStore{bucket: bucket, prefix: prefix}
+}
+
+func (s *gcsSnapshotStore) Exists(ctx context.Context, name string) (bool, error) {
+	_, err := s.bucket.Object(s.prefix + name).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *gcsSnapshotStore) Put(ctx context.Context, name string, data []byte) error {
+	w := s.bucket.Object(s.prefix + name).NewWriter(ctx)
+	_, err := w.Write(data)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (s *gcsSnapshotStore) Get(ctx context.Context, name string) ([]byte, error) {
+	r, err := s.bucket.Object(s.prefix + name).NewReader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func (s *gcsSnapshotStore) List(ctx context.Context, prefix string) ([]string, error) {
+	names := []string{}
+	it := s.bucket.Objects(ctx, &storage.Query{Prefix: s.prefix + prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return names, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, strings.TrimPrefix(attrs.Name, s.prefix))
+	}
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type memorySnapshotStore struct {
+	objects map[string][]byte
+	puts    int
+}
+
+func (s *memorySnapshotStore) Exists(ctx context.Context, name string) (bool, error) {
+	_, exists := s.objects[name]
+	return exists, nil
+}
+
+func (s *memorySnapshotStore) Put(ctx context.Context, name string, data []byte) error {
+	s.puts++
+	s.objects[name] = data
+	return nil
+}
+
+func (s *memorySnapshotStore) Get(ctx context.Context, name string) ([]byte, error) {
+	data, exists := s.objects[name]
+	if !exists {
+		return nil, errors.New("object not found")
+	}
+	return data, nil
+}
+
+func (s *memorySnapshotStore) List(ctx context.Context, prefix string) ([]string, error) {
+	names := []string{}
+	for name := range s.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func TestSnapshotChunk(t *testing.T) {
+	store := &memorySnapshotStore{objects: map[string][]byte{}}
+	rows := []snapshotRow{
+		{Key: "1:B:a", Cells: []snapshotCell{{Family: "f", Column: "c", Timestamp: 0, Value: []byte{1, 2}}}},
+		{Key: "1:B:b", Cells: []snapshotCell{{Family: "f", Column: "c", Timestamp: 1000, Value: []byte{3}}, {Family: "g", Column: "d"}}},
+	}
+
+	chunk, err := putSnapshotChunk(context.Background(), store, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.FirstKey != "1:B:a" || chunk.LastKey != "1:B:b" || chunk.Rows != 2 {
+		t.Errorf("unexpected chunk %+v", chunk)
+	}
+
+	// a chunk with the same content is not uploaded again
+	again, err := putSnapshotChunk(context.Background(), store, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Checksum != chunk.Checksum || store.puts != 1 {
+		t.Errorf("expected the chunk to be uploaded once, got %v uploads", store.puts)
+	}
+
+	encoded, err := store.Get(context.Background(), snapshotChunkPrefix+chunk.Checksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeSnapshotChunk(chunk, encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, rows) {
+		t.Errorf("decoded rows %+v do not match %+v", decoded, rows)
+	}
+
+	encoded[len(encoded)/2] ^= 0xff
+	_, err = decodeSnapshotChunk(chunk, encoded)
+	if !errors.Is(err, ErrSnapshotChecksumMismatch) {
+		t.Errorf("expected a checksum mismatch for a corrupted chunk, got %v", err)
+	}
+}
+
+func TestListSnapshots(t *testing.T) {
+	store := &memorySnapshotStore{objects: map[string][]byte{
+		snapshotManifestPrefix + "20230702T100000Z.json": nil,
+		snapshotManifestPrefix + "20230701T100000Z.json": nil,
+		snapshotChunkPrefix + "abc":                      nil,
+	}}
+	ids, err := ListSnapshots(context.Background(), store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"20230701T100000Z", "20230702T100000Z"}) {
+		t.Errorf("unexpected snapshots %v", ids)
+	}
+}
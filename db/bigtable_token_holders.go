@@ -0,0 +1,196 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/common"
+	eth_types "github.com/ethereum/go-ethereum/core/types"
+)
+
+// maximum number of holder rows that are read when ranking the holders of a token
+const maxTokenHolderRows = 250000
+
+// number of holders shown per page of the holders tab of a token
+const tokenHoldersPageSize = 25
+
+// TransformTokenBalances records every ERC20 balance change of a block so that the current holders of a token can be derived from them
+//
+// It writes the following rows to the data table, one per holder and transfer:
+//
+//	TOKEN:<tokenAddress>:HOLDER:<holder>:<blockNumber>:<txIdx>:<logIdx>:<IN|OUT>
+//
+// The ERC20 column of a row holds the signed amount of the transfer, the current balance of a holder is the sum of all rows
+// sharing the TOKEN:<tokenAddress>:HOLDER:<holder> prefix. Like the nft holdings, the rows of orphaned blocks are removed
+// together with the block. Balances of tokens that change without a transfer event (e.g. rebasing tokens) are not reflected.
+func (bigtable *Bigtable) TransformTokenBalances(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	filterer, err := erc20.NewErc20Filterer(common.Address{}, nil)
+	if err != nil {
+		logger.Errorf("error creating filterer: %v", err)
+	}
+
+	addBalanceChange := func(token, holder []byte, suffix string, delta *big.Int) {
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, "ERC20", gcp_bigtable.Timestamp(0), []byte(delta.String()))
+
+		bulkData.Keys = append(bulkData.Keys, fmt.Sprintf("%s:TOKEN:%x:HOLDER:%x:%s", bigtable.chainId, token, holder, suffix))
+		bulkData.Muts = append(bulkData.Muts, mut)
+	}
+
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		for j, log := range tx.GetLogs() {
+			if j > 99999 {
+				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
+			}
+			if len(log.GetTopics()) != 3 || !bytes.Equal(log.GetTopics()[0], erc20.TransferTopic) {
+				continue
+			}
+
+			topics := make([]common.Hash, 0, len(log.GetTopics()))
+			for _, lTopic := range log.GetTopics() {
+				topics = append(topics, common.BytesToHash(lTopic))
+			}
+
+			transfer, _ := filterer.ParseTransfer(eth_types.Log{
+				Address: common.BytesToAddress(log.GetAddress()),
+				Data:    log.Data,
+				Topics:  topics,
+			})
+			if transfer == nil || transfer.Value == nil || transfer.Value.Sign() == 0 {
+				continue
+			}
+
+			suffix := fmt.Sprintf("%09d:%04d:%05d", blk.GetNumber(), i, j)
+			if !bytes.Equal(transfer.From.Bytes(), ZERO_ADDRESS) {
+				addBalanceChange(log.GetAddress(), transfer.From.Bytes(), suffix+":OUT", new(big.Int).Neg(transfer.Value))
+			}
+			if !bytes.Equal(transfer.To.Bytes(), ZERO_ADDRESS) {
+				addBalanceChange(log.GetAddress(), transfer.To.Bytes(), suffix+":IN", transfer.Value)
+			}
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// GetTokenHolders returns a page of the current holders of an ERC20 token ordered by balance, the page token is the rank
+// of the first holder of the page. The holders are ranked on every call, total is the number of addresses holding the token.
+// For tokens with a very large number of transfers only a part of the balance changes is considered, complete is false in that case.
+func (bigtable *Bigtable) GetTokenHolders(ctx context.Context, token []byte, limit int64, pageToken string) (holders []*types.TokenHolder, nextPageToken string, total uint64, complete bool, err error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	offset := uint64(0)
+	if pageToken != "" {
+		offset, err = strconv.ParseUint(pageToken, 10, 64)
+		if err != nil {
+			return nil, "", 0, false, fmt.Errorf("invalid page token %v for token 0x%x", pageToken, token)
+		}
+	}
+
+	all := make([]*types.TokenHolder, 0)
+	prefix := fmt.Sprintf("%s:TOKEN:%x:HOLDER:", bigtable.chainId, token)
+	// the holder rows have the same layout as the nft holdings, the holder is the fifth part of the key
+	cutOff, err := bigtable.readNFTHoldings(ctx, gcp_bigtable.PrefixRange(prefix), maxTokenHolderRows, func(holding *nftHolding) bool {
+		if holding.balance.Sign() > 0 {
+			keyParts := strings.Split(holding.prefix, ":")
+			all = append(all, &types.TokenHolder{
+				Address: common.FromHex(keyParts[4]),
+				Balance: holding.balance.Bytes(),
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, "", 0, false, err
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if c := new(big.Int).SetBytes(all[i].Balance).Cmp(new(big.Int).SetBytes(all[j].Balance)); c != 0 {
+			return c > 0
+		}
+		return bytes.Compare(all[i].Address, all[j].Address) < 0
+	})
+
+	total = uint64(len(all))
+	if offset >= total {
+		return []*types.TokenHolder{}, "", total, cutOff == "", nil
+	}
+	end := offset + uint64(limit)
+	if end < total {
+		nextPageToken = strconv.FormatUint(end, 10)
+	} else {
+		end = total
+	}
+
+	return all[offset:end], nextPageToken, total, cutOff == "", nil
+}
+
+// GetTokenHoldersTableData returns a page of the top holders of an ERC20 token formatted for the holders tab of the token page
+func (bigtable *Bigtable) GetTokenHoldersTableData(ctx context.Context, token []byte, pageToken string) (*types.DataTableResponse, error) {
+	holders, nextPageToken, total, complete, err := bigtable.GetTokenHolders(ctx, token, tokenHoldersPageSize, pageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := uint64(0)
+	if pageToken != "" {
+		offset, _ = strconv.ParseUint(pageToken, 10, 64)
+	}
+
+	names := make(map[string]string)
+	for _, h := range holders {
+		names[string(h.Address)] = ""
+	}
+	err = bigtable.GetAddressNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := bigtable.GetERC20MetadataForAddress(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	supply := new(big.Int).SetBytes(metadata.TotalSupply)
+	tableData := make([][]interface{}, 0, len(holders))
+	for i, h := range holders {
+		share := "-"
+		if supply.Sign() > 0 {
+			pct, _ := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Mul(new(big.Int).SetBytes(h.Balance), big.NewInt(100))), new(big.Float).SetInt(supply)).Float64()
+			share = fmt.Sprintf("%.4f%%", pct)
+		}
+		tableData = append(tableData, []interface{}{
+			offset + uint64(i) + 1,
+			utils.FormatAddress(h.Address, token, names[string(h.Address)], false, false, true),
+			utils.FormatTokenValue(&types.Eth1AddressBalance{Address: h.Address, Token: token, Balance: h.Balance, Metadata: metadata}),
+			share,
+		})
+	}
+
+	data := &types.DataTableResponse{
+		RecordsTotal: total,
+		Data:         tableData,
+		PagingToken:  nextPageToken,
+	}
+	if !complete {
+		data.Warnings = append(data.Warnings, "The token has too many transfers to rank all of its holders, only a part of its balance changes has been considered.")
+	}
+	return data, nil
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+)
+
+// The rows an orphaned block wrote to the data table are not deleted but marked with a tombstone. Readers skip rows carrying a tombstone,
+// indexing a block removes the tombstones of all rows it writes so that rows shared by the orphaned and the canonical block are revived.
+// Rows that aggregate the cells of many blocks (fundings) are not tombstoned as that would hide the cells of all
+// other blocks, only the cells the orphaned block wrote to them are deleted, see orphanedAggregateCells.
+//
+// Row:    <any data table row of the orphaned block>
+// Family: t
+// Column: orphaned
+// Cell:   hash of the orphaned block
+const (
+	TOMBSTONE_FAMILY = "t"
+	TOMBSTONE_COLUMN = "orphaned"
+)
+
+// liveRowsFilter drops all rows that carry a tombstone
+var liveRowsFilter = gcp_bigtable.ConditionFilter(gcp_bigtable.FamilyFilter(TOMBSTONE_FAMILY), gcp_bigtable.BlockAllFilter(), gcp_bigtable.PassAllFilter())
+
+// skipTombstones returns the row filter of a read that skips tombstoned rows, filters are applied to the remaining rows
+func skipTombstones(filters ...gcp_bigtable.Filter) gcp_bigtable.ReadOption {
+	return gcp_bigtable.RowFilter(gcp_bigtable.ChainFilters(append([]gcp_bigtable.Filter{liveRowsFilter}, filters...)...))
+}
+
+// ReviveRows removes the tombstones of all rows written by the mutations, it has to be applied to the data table mutations of a block before they are written
+func ReviveRows(muts *types.BulkMutations) {
+	for _, mut := range muts.Muts {
+		mut.DeleteCellsInFamily(TOMBSTONE_FAMILY)
+	}
+}
+
+// orphanedAggregateCells returns the mutation deleting the cells an orphaned block wrote to a data row that is shared with other blocks,
+// nil is returned for rows that belong to the block alone
+func (bigtable *Bigtable) orphanedAggregateCells(block *types.Eth1Block, key string) *gcp_bigtable.Mutation {
+	switch strings.SplitN(strings.TrimPrefix(key, bigtable.chainId+":"), ":", 2)[0] {
+	case "FUNDED_BY":
+		// the timestamps of the fundings of a block range from its last to its first transaction
+		mut := gcp_bigtable.NewMutation()
+		mut.DeleteTimestampRange(DEFAULT_FAMILY, DATA_COLUMN, fundingTimestamp(block.GetNumber(), 9999), fundingTimestamp(block.GetNumber(), 0)+1000)
+		return mut
+	}
+	return nil
+}
+
+// tombstoneBlockRows marks the given data rows of an orphaned block with a tombstone and records their number for the consistency report,
+// the cells of the block are deleted from aggregate rows instead
+func (bigtable *Bigtable) tombstoneBlockRows(ctx context.Context, block *types.Eth1Block, keys []string) error {
+	blockNumber, blockHash := block.GetNumber(), block.GetHash()
+	muts := &types.BulkMutations{
+		Keys: make([]string, 0, len(keys)),
+		Muts: make([]*gcp_bigtable.Mutation, 0, len(keys)),
+	}
+	mutsAggregates := &types.BulkMutations{}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if mut := bigtable.orphanedAggregateCells(block, key); mut != nil {
+			mutsAggregates.Keys = append(mutsAggregates.Keys, key)
+			mutsAggregates.Muts = append(mutsAggregates.Muts, mut)
+			continue
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(TOMBSTONE_FAMILY, TOMBSTONE_COLUMN, gcp_bigtable.Timestamp(0), blockHash)
+		muts.Keys = append(muts.Keys, key)
+		muts.Muts = append(muts.Muts, mut)
+	}
+
+	err := bigtable.WriteBulk(ctx, mutsAggregates, bigtable.tableData)
+	if err != nil {
+		return err
+	}
+	err = bigtable.WriteBulk(ctx, muts, bigtable.tableData)
+	if err != nil {
+		return err
+	}
+	metrics.BigtableTombstonedRows.Add(float64(len(muts.Keys)))
+
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	mut := gcp_bigtable.NewMutation()
+	mut.Set(METADATA_UPDATES_FAMILY_BLOCKS, "tombstones", gcp_bigtable.Timestamp(0), []byte(strconv.Itoa(len(muts.Keys))))
+	return bigtable.apply(ctx, bigtable.tableMetadataUpdates, fmt.Sprintf("%s:TOMBSTONES:%s:%x", bigtable.chainId, reversedPaddedBlockNumber(blockNumber), blockHash), mut)
+}
+
+// GetTombstoneCounts returns the number of orphaned blocks and the number of data rows they tombstoned since a block
+func (bigtable *Bigtable) GetTombstoneCounts(ctx context.Context, sinceBlock uint64) (orphanedBlocks uint64, rows uint64, err error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	// the block numbers are reversed, the most recent orphaned blocks come first
+	prefix := fmt.Sprintf("%s:TOMBSTONES:", bigtable.chainId)
+	rowRange := gcp_bigtable.NewRange(prefix, fmt.Sprintf("%s%s;", prefix, reversedPaddedBlockNumber(sinceBlock)))
+
+	var rowErr error
+	err = bigtable.readRows(ctx, bigtable.tableMetadataUpdates, rowRange, func(row gcp_bigtable.Row) bool {
+		for _, item := range row[METADATA_UPDATES_FAMILY_BLOCKS] {
+			if !strings.HasSuffix(item.Column, ":tombstones") {
+				continue
+			}
+			count, err := strconv.ParseUint(string(item.Value), 10, 64)
+			if err != nil {
+				rowErr = fmt.Errorf("invalid tombstone count %q in row %v", item.Value, row.Key())
+				return false
+			}
+			orphanedBlocks++
+			rows += count
+		}
+		return true
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return orphanedBlocks, rows, rowErr
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"testing"
+)
+
+// TestOrphanedAggregateCells checks that only the rows shared by several blocks are cleaned up cell by cell instead of being tombstoned
+func TestOrphanedAggregateCells(t *testing.T) {
+	bt := &Bigtable{chainId: "1"}
+	blk := &types.Eth1Block{Number: 10, Transactions: []*types.Eth1Transaction{{Hash: []byte{1}, From: []byte{0xa}, To: []byte{0xb}}}}
+
+	tests := []struct {
+		key       string
+		aggregate bool
+	}{
+		{"1:FUNDED_BY:0b", true},
+		{"1:TX:01", false},
+		{"1:I:TX:0a:TIME:9223372036854775807:9999", false},
+		{"1:CONTRACT_CREATION:0b", false},
+	}
+	for _, tt := range tests {
+		if got := bt.orphanedAggregateCells(blk, tt.key) != nil; got != tt.aggregate {
+			t.Errorf("row %v: expected aggregate %v, got %v", tt.key, tt.aggregate, got)
+		}
+	}
+}
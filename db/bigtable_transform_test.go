@@ -0,0 +1,180 @@
+package db
+
+import (
+	"bytes"
+	"eth2-exporter/fixtures"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"strings"
+	"testing"
+
+	"github.com/coocood/freecache"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestTransformFixtures runs the transformers of the indexer over a few fixture blocks and checks the invariants of the resulting mutations
+func TestTransformFixtures(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.MaxWithdrawalsPerPayload = 16
+
+	bt := &Bigtable{chainId: "1"}
+	transforms := map[string]func(*types.Eth1Block, *freecache.Cache) (*types.BulkMutations, *types.BulkMutations, error){
+		"block":              bt.TransformBlock,
+		"tx":                 bt.TransformTx,
+		"itx":                bt.TransformItx,
+		"erc20":              bt.TransformERC20,
+		"erc721":             bt.TransformERC721,
+		"erc1155":            bt.TransformERC1155,
+		"uncle":              bt.TransformUncle,
+		"withdrawals":        bt.TransformWithdrawals,
+		"contract_creations": bt.TransformContractCreations,
+		"fundings":           bt.TransformFundings,
+		"ens":                bt.TransformEns,
+		"logs":               bt.TransformLogs,
+	}
+
+	for _, blk := range fixtures.Chain(1, 5, fixtures.DefaultOptions()) {
+		cache := freecache.NewCache(1024 * 1024)
+		data := &types.BulkMutations{}
+		metadataUpdates := &types.BulkMutations{}
+		for name, transform := range transforms {
+			d, u, err := transform(blk, cache)
+			if err != nil {
+				t.Fatalf("transformer %v failed on block %v: %v", name, blk.GetNumber(), err)
+			}
+			data.Keys = append(data.Keys, d.Keys...)
+			data.Muts = append(data.Muts, d.Muts...)
+			metadataUpdates.Keys = append(metadataUpdates.Keys, u.Keys...)
+			metadataUpdates.Muts = append(metadataUpdates.Muts, u.Muts...)
+		}
+		if len(data.Keys) == 0 {
+			t.Fatalf("no mutations for block %v", blk.GetNumber())
+		}
+
+		err := bt.ValidateBlockMutations(blk, data, metadataUpdates)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestIndexRowTime checks that the time of the index rows written by the transaction transformer can be recovered from their keys
+func TestIndexRowTime(t *testing.T) {
+	bt := &Bigtable{chainId: "1"}
+	blk := fixtures.Block(1, fixtures.DefaultOptions())
+	data, _, err := bt.TransformTx(blk, freecache.NewCache(1024*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range data.Keys {
+		ts, ok := indexRowTime(key)
+		switch {
+		case !strings.HasPrefix(key, "1:I:"):
+			if ok {
+				t.Errorf("data row %v must not have an index time", key)
+			}
+		case strings.Contains(key, ":BLOCK:"):
+			if ok {
+				t.Errorf("block ordered index row %v must not have an index time", key)
+			}
+		case !ok || !ts.Equal(blk.GetTime().AsTime()):
+			t.Errorf("index row %v has time %v, want %v", key, ts, blk.GetTime().AsTime())
+		}
+	}
+}
+
+// TestBuildItxTree checks that call frames are nested by their trace address
+func TestBuildItxTree(t *testing.T) {
+	itxs := []*types.Eth1InternalTransactionIndexed{
+		{Path: "[1]", Index: 4, Depth: 1},
+		{Path: "[]", Index: 0, Depth: 0},
+		{Path: "[0]", Index: 1, Depth: 1},
+		{Path: "[0 0]", Index: 2, Depth: 2},
+		// the parent [0 1] is missing, the frame is attached to [0]
+		{Path: "[0 1 0]", Index: 3, Depth: 3},
+	}
+
+	roots := BuildItxTree(itxs)
+	if len(roots) != 1 || roots[0].Index != 0 {
+		t.Fatalf("expected the top level call as the only root, got %v roots", len(roots))
+	}
+	calls := roots[0].Children
+	if len(calls) != 2 || calls[0].Index != 1 || calls[1].Index != 4 {
+		t.Fatalf("unexpected calls of the top level call: %v", calls)
+	}
+	if len(calls[0].Children) != 2 || calls[0].Children[0].Index != 2 || calls[0].Children[1].Index != 3 {
+		t.Fatalf("unexpected calls of frame [0]: %v", calls[0].Children)
+	}
+
+	// geth style traces have no trace address and stay flat
+	roots = BuildItxTree([]*types.Eth1InternalTransactionIndexed{{Path: "0", Index: 0}, {Path: "0", Index: 1}})
+	if len(roots) != 2 {
+		t.Fatalf("expected 2 top level calls for frames without trace address, got %v", len(roots))
+	}
+}
+
+// TestFundingsOfBlock checks that only the first transfer of value to an address in a block is recorded as its funding
+func TestFundingsOfBlock(t *testing.T) {
+	a, b, c := []byte{0xa}, []byte{0xb}, []byte{0xc}
+	blk := &types.Eth1Block{
+		Number: 10,
+		Transactions: []*types.Eth1Transaction{
+			{Hash: []byte{1}, From: a, To: b, Value: []byte{0x0}, Itx: []*types.Eth1InternalTransaction{
+				{Type: "call", Path: "[]", From: a, To: b, Value: []byte{0x0}},
+				{Type: "call", Path: "[0]", From: b, To: c, Value: []byte{0x5}},
+			}},
+			{Hash: []byte{2}, From: a, To: b, Value: []byte{0x1}},
+			{Hash: []byte{3}, From: a, To: c, Value: []byte{0x2}},
+			{Hash: []byte{4}, From: c, To: a, Value: []byte{0x3}, ErrorMsg: "reverted"},
+		},
+	}
+
+	fundings := fundingsOfBlock(blk)
+	if len(fundings) != 2 {
+		t.Fatalf("expected 2 fundings, got %v", len(fundings))
+	}
+	if !bytes.Equal(fundings[0].To, c) || !bytes.Equal(fundings[0].ParentHash, []byte{1}) || fundings[0].Type != "call" {
+		t.Errorf("expected c to be funded by the internal transaction of tx 1, got %v", fundings[0])
+	}
+	if !bytes.Equal(fundings[1].To, b) || !bytes.Equal(fundings[1].ParentHash, []byte{2}) || fundings[1].Type != FUNDED_BY_TX {
+		t.Errorf("expected b to be funded by tx 2, got %v", fundings[1])
+	}
+
+	if fundingTimestamp(10, 1) <= fundingTimestamp(10, 2) || fundingTimestamp(10, 9999) <= fundingTimestamp(11, 0) {
+		t.Errorf("expected the timestamps of fundings to decrease with their position in the chain")
+	}
+}
+
+// TestContractDestructionsOfTransaction checks that self destructs only remove contracts created in the same transaction since cancun
+func TestContractDestructionsOfTransaction(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.SecondsPerSlot = 12
+	utils.Config.Chain.Config.SlotsPerEpoch = 32
+	utils.Config.Chain.Config.DenebForkEpoch = 10
+
+	a, b := []byte{0xa}, []byte{0xb}
+	tx := &types.Eth1Transaction{Hash: []byte{1}, Itx: []*types.Eth1InternalTransaction{
+		{Type: "suicide", Path: "[0]", From: a, To: b},
+		{Type: "suicide", Path: "[1]", From: b, To: a},
+		{Type: "suicide", Path: "[2]", From: b, To: a, ErrorMsg: "reverted"},
+	}}
+	before := &types.Eth1Block{Number: 1, Time: timestamppb.New(utils.EpochToTime(9))}
+	after := &types.Eth1Block{Number: 2, Time: timestamppb.New(utils.EpochToTime(10))}
+
+	if destructions := contractDestructionsOfTransaction(before, tx, nil); len(destructions) != 2 {
+		t.Errorf("expected both self destructs to remove their contract before cancun, got %v", len(destructions))
+	}
+	destructions := contractDestructionsOfTransaction(after, tx, map[string]bool{string(b): true})
+	if len(destructions) != 1 || !bytes.Equal(destructions[0].From, b) {
+		t.Errorf("expected only the contract created in the transaction to be removed after cancun, got %v", destructions)
+	}
+	tx.ErrorMsg = "reverted"
+	if destructions := contractDestructionsOfTransaction(before, tx, nil); len(destructions) != 0 {
+		t.Errorf("expected no self destructs of a failed transaction, got %v", len(destructions))
+	}
+
+	if contractLifecycleTimestamp(10, 2) <= contractLifecycleTimestamp(10, 1) || contractLifecycleTimestamp(11, 0) <= contractLifecycleTimestamp(10, 9999) {
+		t.Errorf("expected the timestamps of contract lifecycle events to increase with their position in the chain")
+	}
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/coocood/freecache"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// whaleTokenThresholds caches the configured token thresholds converted to the smallest unit of each token
+var whaleTokenThresholds = make(map[string]*big.Int)
+var whaleTokenThresholdsMux = &sync.Mutex{}
+
+// getWhaleTokenThreshold returns the whale threshold of a token in its smallest unit, nil is returned for tokens without a configured threshold
+func (bigtable *Bigtable) getWhaleTokenThreshold(ctx context.Context, token []byte) (*big.Int, error) {
+	key := fmt.Sprintf("%x", token)
+
+	whaleTokenThresholdsMux.Lock()
+	defer whaleTokenThresholdsMux.Unlock()
+	if threshold, ok := whaleTokenThresholds[key]; ok {
+		return threshold, nil
+	}
+
+	var threshold *big.Int
+	for address, amount := range utils.Config.WhaleAlerts.TokenThresholds {
+		if !strings.EqualFold(strings.TrimPrefix(address, "0x"), key) || amount <= 0 {
+			continue
+		}
+		metadata, err := bigtable.GetERC20MetadataForAddress(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		threshold = decimal.NewFromFloat(amount).Shift(int32(new(big.Int).SetBytes(metadata.Decimals).Int64())).BigInt()
+		break
+	}
+	whaleTokenThresholds[key] = threshold
+	return threshold, nil
+}
+
+// TransformWhaleTransfers extracts all ether transactions and ERC20 transfers of a block whose value reaches the configured whale alert thresholds
+//
+// It writes the following rows to the data table:
+//
+//	WHALE:<reversePaddedTimestamp>:<txIdx>:<logIdx>
+//
+// Ether transactions use TX as log index, the row data is a Eth1ERC20Indexed message without token address for ether transactions.
+// Rows are sorted by time, with the most recent transfer first.
+func (bigtable *Bigtable) TransformWhaleTransfers(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error) {
+	bulkData = &types.BulkMutations{}
+	bulkMetadataUpdates = &types.BulkMutations{}
+
+	etherThreshold := decimal.NewFromFloat(utils.Config.WhaleAlerts.EtherThreshold).Shift(18).BigInt()
+
+	addTransfer := func(key string, transfer *types.Eth1ERC20Indexed) error {
+		b, err := proto.Marshal(transfer)
+		if err != nil {
+			return err
+		}
+		mut := gcp_bigtable.NewMutation()
+		mut.Set(DEFAULT_FAMILY, DATA_COLUMN, gcp_bigtable.Timestamp(0), b)
+
+		bulkData.Keys = append(bulkData.Keys, key)
+		bulkData.Muts = append(bulkData.Muts, mut)
+		return nil
+	}
+
+	for i, tx := range blk.GetTransactions() {
+		if i > 9999 {
+			return nil, nil, fmt.Errorf("unexpected number of transactions in block expected at most 9999 but got: %v, tx: %x", i, tx.GetHash())
+		}
+		iReversed := reversePaddedIndex(i, 10000)
+		prefix := fmt.Sprintf("%s:WHALE:%s:%s", bigtable.chainId, reversePaddedBigtableTimestamp(blk.GetTime()), iReversed)
+
+		if etherThreshold.Sign() > 0 && tx.GetErrorMsg() == "" && new(big.Int).SetBytes(tx.GetValue()).Cmp(etherThreshold) >= 0 {
+			err = addTransfer(prefix+":TX", &types.Eth1ERC20Indexed{
+				ParentHash:  tx.GetHash(),
+				BlockNumber: blk.GetNumber(),
+				Time:        blk.GetTime(),
+				From:        tx.GetFrom(),
+				To:          tx.GetTo(),
+				Value:       tx.GetValue(),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		if len(utils.Config.WhaleAlerts.TokenThresholds) == 0 {
+			continue
+		}
+		for j, log := range tx.GetLogs() {
+			if j > 99999 {
+				return nil, nil, fmt.Errorf("unexpected number of logs in block expected at most 99999 but got: %v tx: %x", j, tx.GetHash())
+			}
+			// ERC20 transfers have the amount in the data, ERC721 transfers index the token id as third topic
+			if len(log.GetTopics()) != 3 || !bytes.Equal(log.GetTopics()[0], erc20.TransferTopic) || len(log.GetData()) != 32 {
+				continue
+			}
+			threshold, err := bigtable.getWhaleTokenThreshold(context.Background(), log.GetAddress())
+			if err != nil {
+				return nil, nil, err
+			}
+			value := new(big.Int).SetBytes(log.GetData())
+			if threshold == nil || value.Cmp(threshold) < 0 {
+				continue
+			}
+			err = addTransfer(fmt.Sprintf("%s:%s", prefix, reversePaddedIndex(j, 100000)), &types.Eth1ERC20Indexed{
+				ParentHash:   tx.GetHash(),
+				BlockNumber:  blk.GetNumber(),
+				Time:         blk.GetTime(),
+				TokenAddress: log.GetAddress(),
+				From:         common.BytesToAddress(log.GetTopics()[1]).Bytes(),
+				To:           common.BytesToAddress(log.GetTopics()[2]).Bytes(),
+				Value:        value.Bytes(),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return bulkData, bulkMetadataUpdates, nil
+}
+
+// GetWhaleTransfers returns the most recent whale transfers that happened after since
+func (bigtable *Bigtable) GetWhaleTransfers(ctx context.Context, since time.Time, limit int64) ([]*types.Eth1ERC20Indexed, error) {
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30))
+	defer cancel()
+
+	prefix := fmt.Sprintf("%s:WHALE:", bigtable.chainId)
+	end := prefix + reversePaddedBigtableTimestamp(timestamppb.New(since)) + ";"
+
+	transfers := make([]*types.Eth1ERC20Indexed, 0, limit)
+	var unmarshalErr error
+	err := bigtable.readRows(ctx, bigtable.tableData, gcp_bigtable.NewRange(prefix, end), func(row gcp_bigtable.Row) bool {
+		transfer := &types.Eth1ERC20Indexed{}
+		unmarshalErr = proto.Unmarshal(row[DEFAULT_FAMILY][0].Value, transfer)
+		if unmarshalErr != nil {
+			return false
+		}
+		transfers = append(transfers, transfer)
+		return true
+	}, gcp_bigtable.LimitRows(limit), skipTombstones())
+	if err != nil {
+		return nil, err
+	}
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("error parsing Eth1ERC20Indexed data: %w", unmarshalErr)
+	}
+
+	return transfers, nil
+}
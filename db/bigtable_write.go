@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	gcp_bigtable "cloud.google.com/go/bigtable"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	writeBulkChunkSize          = 10000
+	defaultWriteBulkConcurrency = 4
+	defaultWriteBulkRetries     = 5
+	writeBulkBackoffMin         = time.Millisecond * 250
+	writeBulkBackoffMax         = time.Second * 10
+	// number of row errors kept in a WriteBulkError
+	writeBulkErrorSample = 10
+)
+
+// WriteBulkError is returned by WriteBulk if some of the mutations could not be written
+type WriteBulkError struct {
+	Failed int
+	Total  int
+	// a sample of the errors of the failed rows
+	Errs []error
+}
+
+func (e *WriteBulkError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("error writing %v of %v mutations: %v", e.Failed, e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *WriteBulkError) add(failed int, errs []error) {
+	e.Failed += failed
+	for _, err := range errs {
+		if len(e.Errs) >= writeBulkErrorSample {
+			return
+		}
+		e.Errs = append(e.Errs, err)
+	}
+}
+
+// SetWriteBulkOptions sets the number of chunks WriteBulk writes concurrently and how often a mutation that failed with a
+// transient error is retried
+func (bigtable *Bigtable) SetWriteBulkOptions(concurrency, retries int) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	bigtable.writeConcurrency = concurrency
+	bigtable.writeRetries = retries
+}
+
+// WriteBulk writes the mutations in chunks of 10000 rows, the chunks are applied concurrently. Rows that fail with a transient
+// error are retried with exponential backoff, all chunks are written even if some of them fail and the failures are
+// reported as *WriteBulkError.
+func (bigtable *Bigtable) WriteBulk(ctx context.Context, mutations *types.BulkMutations, table *gcp_bigtable.Table) error {
+	ctx, done := context.WithTimeout(ctx, time.Minute*5)
+	defer done()
+
+	numMutations := len(mutations.Muts)
+	numKeys := len(mutations.Keys)
+	if numKeys != numMutations {
+		return fmt.Errorf("error expected same number of keys as mutations keys: %v mutations: %v", numKeys, numMutations)
+	}
+
+	concurrency := bigtable.writeConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	writeErr := &WriteBulkError{Total: numKeys}
+	mux := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	sem := make(chan struct{}, concurrency)
+	for start := 0; start < numKeys; start += writeBulkChunkSize {
+		end := start + writeBulkChunkSize
+		if end > numKeys {
+			end = numKeys
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(keys []string, muts []*gcp_bigtable.Mutation) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			failed, errs := bigtable.applyBulkWithRetry(ctx, table, keys, muts)
+			if failed == 0 {
+				return
+			}
+			metrics.BigtableWriteFailures.Add(float64(failed))
+			mux.Lock()
+			writeErr.add(failed, errs)
+			mux.Unlock()
+		}(mutations.Keys[start:end], mutations.Muts[start:end])
+	}
+	wg.Wait()
+
+	if writeErr.Failed > 0 {
+		return writeErr
+	}
+	return nil
+}
+
+// applyBulkWithRetry applies the mutations and retries the rows that failed with a transient error. It returns the number
+// of rows that could not be written along with their errors.
+func (bigtable *Bigtable) applyBulkWithRetry(ctx context.Context, table *gcp_bigtable.Table, keys []string, muts []*gcp_bigtable.Mutation) (int, []error) {
+	failed := 0
+	var errs []error
+	backoff := writeBulkBackoffMin
+	for attempt := 0; ; attempt++ {
+		retry := attempt < bigtable.writeRetries
+		rowErrs, err := bigtable.applyBulk(ctx, table, keys, muts)
+		if err != nil {
+			// the whole request failed, none of the rows have been written
+			if !retry || !isTransientBigtableError(err) {
+				return failed + len(keys), append(errs, err)
+			}
+		} else {
+			retryKeys := make([]string, 0)
+			retryMuts := make([]*gcp_bigtable.Mutation, 0)
+			for i, rowErr := range rowErrs {
+				if rowErr == nil {
+					continue
+				}
+				if retry && isTransientBigtableError(rowErr) {
+					retryKeys = append(retryKeys, keys[i])
+					retryMuts = append(retryMuts, muts[i])
+					continue
+				}
+				failed++
+				errs = append(errs, fmt.Errorf("error writing row %v: %w", keys[i], rowErr))
+			}
+			if len(retryKeys) == 0 {
+				return failed, errs
+			}
+			keys = retryKeys
+			muts = retryMuts
+		}
+
+		metrics.BigtableWriteRetries.Add(float64(len(keys)))
+		select {
+		case <-ctx.Done():
+			return failed + len(keys), append(errs, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > writeBulkBackoffMax {
+			backoff = writeBulkBackoffMax
+		}
+	}
+}
+
+// isTransientBigtableError reports whether a failed mutation may succeed when it is applied again
+func isTransientBigtableError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.ResourceExhausted, codes.Internal:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransientBigtableError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{status.Error(codes.Unavailable, "unavailable"), true},
+		{status.Error(codes.ResourceExhausted, "throttled"), true},
+		{status.Error(codes.InvalidArgument, "too many mutations"), false},
+		{status.Error(codes.NotFound, "table not found"), false},
+		{fmt.Errorf("error writing row: %w", context.DeadlineExceeded), false},
+	}
+	for _, tt := range tests {
+		if got := isTransientBigtableError(tt.err); got != tt.want {
+			t.Errorf("isTransientBigtableError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestWriteBulkErrorSample(t *testing.T) {
+	writeErr := &WriteBulkError{Total: 100}
+	for i := 0; i < 3; i++ {
+		errs := make([]error, 0, 5)
+		for j := 0; j < 5; j++ {
+			errs = append(errs, fmt.Errorf("error %v", i*5+j))
+		}
+		writeErr.add(len(errs), errs)
+	}
+	if writeErr.Failed != 15 {
+		t.Errorf("got %v failed mutations, want 15", writeErr.Failed)
+	}
+	if len(writeErr.Errs) != writeBulkErrorSample {
+		t.Errorf("got %v sampled errors, want %v", len(writeErr.Errs), writeBulkErrorSample)
+	}
+}
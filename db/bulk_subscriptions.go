@@ -0,0 +1,76 @@
+package db
+
+import (
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// BulkSubscription is a subscription of a bulk request, EventName includes the network prefix of the stored subscription
+type BulkSubscription struct {
+	EventName      string
+	EventFilter    string
+	EventThreshold float64
+}
+
+// SaveUserSubscriptions creates the given subscriptions of a user in a single transaction, the thresholds of existing
+// subscriptions are updated. It returns the number of created and updated subscriptions.
+func SaveUserSubscriptions(userID uint64, subscriptions []*BulkSubscription) (created, updated int64, err error) {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return 0, 0, fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	names := make(pq.StringArray, 0, len(subscriptions))
+	filters := make(pq.StringArray, 0, len(subscriptions))
+	thresholds := make(pq.Float64Array, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		names = append(names, s.EventName)
+		filters = append(filters, s.EventFilter)
+		thresholds = append(thresholds, s.EventThreshold)
+	}
+
+	now := time.Now()
+	inserted := []bool{}
+	err = tx.Select(&inserted, `
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, event_threshold, created_ts, created_epoch)
+		SELECT $1, UNNEST($2::text[]), UNNEST($3::text[]), UNNEST($4::float8[]), TO_TIMESTAMP($5), $6
+		ON CONFLICT (user_id, event_name, event_filter) DO UPDATE SET event_threshold = EXCLUDED.event_threshold
+		RETURNING xmax = 0`,
+		userID, names, filters, thresholds, now.Unix(), utils.TimeToEpoch(now))
+	if err != nil {
+		return 0, 0, fmt.Errorf("error saving subscriptions of user %v: %w", userID, err)
+	}
+	for _, i := range inserted {
+		if i {
+			created++
+		} else {
+			updated++
+		}
+	}
+
+	return created, updated, tx.Commit()
+}
+
+// DeleteUserSubscriptions removes the given subscriptions of a user and returns the number of deleted subscriptions,
+// the thresholds of the subscriptions are ignored
+func DeleteUserSubscriptions(userID uint64, subscriptions []*BulkSubscription) (int64, error) {
+	names := make(pq.StringArray, 0, len(subscriptions))
+	filters := make(pq.StringArray, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		names = append(names, s.EventName)
+		filters = append(filters, s.EventFilter)
+	}
+
+	res, err := FrontendWriterDB.Exec(`
+		DELETE FROM users_subscriptions
+		WHERE user_id = $1 AND (event_name, event_filter) IN (SELECT UNNEST($2::text[]), UNNEST($3::text[]))`,
+		userID, names, filters)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting subscriptions of user %v: %w", userID, err)
+	}
+	return res.RowsAffected()
+}
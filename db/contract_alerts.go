@@ -0,0 +1,139 @@
+package db
+
+import (
+	"database/sql"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// contractAlertEventName returns the network specific subscription event name of the contract alerts
+func contractAlertEventName(network string) string {
+	return strings.ToLower(network) + ":" + string(types.ContractInteractionEventName)
+}
+
+// SaveVerifiedContract records that the user has proven to control the deployer of the contract
+func SaveVerifiedContract(userID uint64, network string, contract, deployer []byte) error {
+	_, err := FrontendWriterDB.Exec(`
+		INSERT INTO users_verified_contracts (user_id, network, contract_address, deployer_address, verified_ts)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, network, contract_address) DO UPDATE SET deployer_address = excluded.deployer_address, verified_ts = excluded.verified_ts`,
+		userID, strings.ToLower(network), contract, deployer, time.Now())
+	if err != nil {
+		return fmt.Errorf("error saving verified contract %x of user %v: %w", contract, userID, err)
+	}
+	return nil
+}
+
+// IsVerifiedContractOwner returns whether the user has verified the ownership of the contract
+func IsVerifiedContractOwner(userID uint64, network string, contract []byte) (bool, error) {
+	var verified bool
+	err := FrontendWriterDB.Get(&verified, `
+		SELECT EXISTS (SELECT 1 FROM users_verified_contracts WHERE user_id = $1 AND network = $2 AND contract_address = $3)`,
+		userID, strings.ToLower(network), contract)
+	if err != nil {
+		return false, fmt.Errorf("error checking verified contract %x of user %v: %w", contract, userID, err)
+	}
+	return verified, nil
+}
+
+// CreateContractAlert stores a new contract alert of a user together with the subscription its notifications are delivered through
+func CreateContractAlert(alert *types.ContractAlert, network string) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	alert.CreatedTs = now
+	err = tx.Get(&alert.ID, `
+		INSERT INTO users_contract_alerts (user_id, network, contract_address, kind, method_id, revert_threshold, created_ts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`,
+		alert.UserID, strings.ToLower(network), alert.Contract, alert.Kind, alert.MethodID, alert.RevertThreshold, now)
+	if err != nil {
+		return fmt.Errorf("error saving contract alert of user %v: %w", alert.UserID, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users_subscriptions (user_id, event_name, event_filter, created_ts, created_epoch, event_threshold)
+		VALUES ($1, $2, $3, $4, $5, 0)
+		ON CONFLICT (user_id, event_name, event_filter) DO NOTHING`,
+		alert.UserID, contractAlertEventName(network), fmt.Sprintf("%v", alert.ID), now, utils.TimeToEpoch(now))
+	if err != nil {
+		return fmt.Errorf("error saving subscription of contract alert %v: %w", alert.ID, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetUserContractAlerts returns the contract alerts a user has defined on a network
+func GetUserContractAlerts(userID uint64, network string) ([]*types.ContractAlert, error) {
+	alerts := []*types.ContractAlert{}
+	err := FrontendWriterDB.Select(&alerts, `
+		SELECT id, user_id, contract_address, kind, method_id, revert_threshold, created_ts
+		FROM users_contract_alerts
+		WHERE user_id = $1 AND network = $2
+		ORDER BY id`, userID, strings.ToLower(network))
+	if err != nil {
+		return nil, fmt.Errorf("error getting contract alerts of user %v: %w", userID, err)
+	}
+	return alerts, nil
+}
+
+// DeleteContractAlert removes a contract alert of a user and its subscription, returns false if the user has no such alert
+func DeleteContractAlert(userID, alertID uint64, network string) (bool, error) {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM users_contract_alerts WHERE id = $1 AND user_id = $2 AND network = $3`, alertID, userID, strings.ToLower(network))
+	if err != nil {
+		return false, fmt.Errorf("error deleting contract alert %v: %w", alertID, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if deleted == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`DELETE FROM users_subscriptions WHERE user_id = $1 AND event_name = $2 AND event_filter = $3`, userID, contractAlertEventName(network), fmt.Sprintf("%v", alertID))
+	if err != nil {
+		return false, fmt.Errorf("error deleting subscription of contract alert %v: %w", alertID, err)
+	}
+
+	return true, tx.Commit()
+}
+
+// SubscribedContractAlert is a contract alert together with the subscription state of its notifications
+type SubscribedContractAlert struct {
+	types.ContractAlert
+	SubscriptionID  uint64         `db:"subscription_id"`
+	UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
+	LastSent        sql.NullTime   `db:"last_sent_ts"`
+}
+
+// GetDueContractAlerts returns the contract alerts of a network whose owners are still verified. Revert rate alerts are
+// only returned if they have not been sent within the last hour.
+func GetDueContractAlerts(network string) ([]*SubscribedContractAlert, error) {
+	alerts := []*SubscribedContractAlert{}
+	err := FrontendWriterDB.Select(&alerts, `
+		SELECT a.id, a.user_id, a.contract_address, a.kind, a.method_id, a.revert_threshold, a.created_ts,
+			us.id AS subscription_id, ENCODE(us.unsubscribe_hash, 'hex') AS unsubscribe_hash, us.last_sent_ts
+		FROM users_contract_alerts a
+		INNER JOIN users_verified_contracts vc ON vc.user_id = a.user_id AND vc.network = a.network AND vc.contract_address = a.contract_address
+		INNER JOIN users_subscriptions us ON us.user_id = a.user_id AND us.event_name = $1 AND us.event_filter = a.id::TEXT
+		WHERE a.network = $2 AND (a.kind != $3 OR us.last_sent_ts IS NULL OR us.last_sent_ts <= NOW() - INTERVAL '1 hour')`,
+		contractAlertEventName(network), strings.ToLower(network), types.ContractAlertRevertRate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting due contract alerts: %w", err)
+	}
+	return alerts, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha1"
 	"database/sql"
 	"embed"
@@ -601,7 +602,16 @@ func UpdateCanonicalBlocks(startEpoch, endEpoch uint64, blocks []*types.MinimalB
 			}
 		}
 	}
-	return tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	err = SaveOrphanedBlockReorgs(startEpoch, endEpoch)
+	if err != nil {
+		logger.Errorf("error saving orphaned blocks of epochs %v-%v: %v", startEpoch, endEpoch, err)
+	}
+	return nil
 }
 
 func SetBlockStatus(blocks []*types.CanonBlock) error {
@@ -638,7 +648,26 @@ func SetBlockStatus(blocks []*types.CanonBlock) error {
 		return err
 	}
 
-	return tx.Commit()
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	startEpoch, endEpoch := utils.EpochOfSlot(blocks[0].Slot), utils.EpochOfSlot(blocks[0].Slot)
+	for _, block := range blocks {
+		epoch := utils.EpochOfSlot(block.Slot)
+		if epoch < startEpoch {
+			startEpoch = epoch
+		}
+		if epoch > endEpoch {
+			endEpoch = epoch
+		}
+	}
+	err = SaveOrphanedBlockReorgs(startEpoch, endEpoch)
+	if err != nil {
+		logger.Errorf("error saving orphaned blocks of epochs %v-%v: %v", startEpoch, endEpoch, err)
+	}
+	return nil
 }
 
 // SaveValidatorQueue will save the validator queue into the database
@@ -967,7 +996,7 @@ func saveValidators(data *types.EpochData, tx *sqlx.Tx, client rpc.Client) error
 
 	if data.Epoch == 0 {
 		var err error
-		genesisBalances, err = BigtableClient.GetValidatorBalanceHistory([]uint64{}, 0, 0)
+		genesisBalances, err = BigtableClient.GetValidatorBalanceHistory(context.Background(), []uint64{}, 0, 0)
 		if err != nil {
 			return err
 		}
@@ -1276,7 +1305,7 @@ func saveValidators(data *types.EpochData, tx *sqlx.Tx, client rpc.Client) error
 		if newValidator.ActivationEpoch == 0 {
 			balance = genesisBalances
 		} else {
-			balance, err = BigtableClient.GetValidatorBalanceHistory([]uint64{newValidator.Validatorindex}, newValidator.ActivationEpoch, newValidator.ActivationEpoch)
+			balance, err = BigtableClient.GetValidatorBalanceHistory(context.Background(), []uint64{newValidator.Validatorindex}, newValidator.ActivationEpoch, newValidator.ActivationEpoch)
 			if err != nil {
 				return err
 			}
@@ -1458,6 +1487,15 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sqlx.Tx) error {
 	}
 	defer stmtBLSChange.Close()
 
+	stmtCredentialRotation, err := tx.Prepare(`
+	INSERT INTO validator_credential_rotations (validatorindex, block_slot, block_root, from_credentials, to_credentials)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (validatorindex, block_root) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer stmtCredentialRotation.Close()
+
 	stmtProposerSlashing, err := tx.Prepare(`
 		INSERT INTO blocks_proposerslashings (block_slot, block_index, block_root, proposerindex, header1_slot, header1_parentroot, header1_stateroot, header1_bodyroot, header1_signature, header2_slot, header2_parentroot, header2_stateroot, header2_bodyroot, header2_signature)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
@@ -1674,6 +1712,14 @@ func saveBlocks(blocks map[uint64]map[string]*types.Block, tx *sqlx.Tx) error {
 				if err != nil {
 					return fmt.Errorf("error executing stmtBLSChange for block %v: %w", b.Slot, err)
 				}
+
+				// the address is not validated here as the zero address is a valid (if unfortunate) target of a bls change
+				toCredentials := append(make([]byte, 12, 32), bls.Message.Address...)
+				toCredentials[0] = 0x01
+				_, err = stmtCredentialRotation.Exec(bls.Message.Validatorindex, b.Slot, b.BlockRoot, utils.BLSPubkeyToWithdrawalCredentials(bls.Message.BlsPubkey), toCredentials)
+				if err != nil {
+					return fmt.Errorf("error executing stmtCredentialRotation for block %v: %w", b.Slot, err)
+				}
 			}
 			blockLog.WithField("duration", time.Since(n)).Tracef("stmtBLSChange")
 			t = time.Now()
@@ -2312,12 +2358,16 @@ func GetEpochWithdrawalsTotal(epoch uint64) (total uint64, err error) {
 
 // GetAddressWithdrawals returns the withdrawals for an address
 func GetAddressWithdrawals(address []byte, limit uint64, offset uint64) ([]*types.Withdrawals, error) {
+	return getAddressWithdrawals(ReaderDb, address, limit, offset)
+}
+
+func getAddressWithdrawals(reader *sqlx.DB, address []byte, limit uint64, offset uint64) ([]*types.Withdrawals, error) {
 	var withdrawals []*types.Withdrawals
 	if limit == 0 {
 		limit = 100
 	}
 
-	err := ReaderDb.Select(&withdrawals, `
+	err := reader.Select(&withdrawals, `
 	SELECT 
 		w.block_slot as slot, 
 		w.withdrawalindex as index, 
@@ -2444,9 +2494,13 @@ func GetValidatorsWithdrawalsByEpoch(validator []uint64, startEpoch uint64, endE
 
 // GetAddressWithdrawalsTotal returns the total withdrawals for an address
 func GetAddressWithdrawalsTotal(address []byte) (uint64, error) {
+	return getAddressWithdrawalsTotal(ReaderDb, address)
+}
+
+func getAddressWithdrawalsTotal(reader *sqlx.DB, address []byte) (uint64, error) {
 	var total uint64
 
-	err := ReaderDb.Get(&total, `
+	err := reader.Get(&total, `
 	SELECT 
 		COALESCE(sum(w.amount), 0) as total
 	FROM blocks_withdrawals w
@@ -2462,6 +2516,26 @@ func GetAddressWithdrawalsTotal(address []byte) (uint64, error) {
 	return total, nil
 }
 
+// GetValidatorsSummary aggregates the state and balances of a set of validators
+func GetValidatorsSummary(validators []uint64) (*types.UserDashboardValidatorsSummary, error) {
+	summary := &types.UserDashboardValidatorsSummary{}
+	err := ReaderDb.Get(summary, `
+	SELECT
+		COUNT(*) AS validators,
+		COUNT(*) FILTER (WHERE status LIKE 'active%') AS active,
+		COUNT(*) FILTER (WHERE status IN ('pending', 'deposited')) AS pending,
+		COUNT(*) FILTER (WHERE status = 'exited') AS exited,
+		COUNT(*) FILTER (WHERE status = 'slashed' OR status LIKE 'slashing%') AS slashed,
+		COALESCE(SUM(balance), 0) AS balance,
+		COALESCE(SUM(effectivebalance), 0) AS effective_balance
+	FROM validators
+	WHERE validatorindex = ANY($1)`, pq.Array(validators))
+	if err != nil {
+		return nil, fmt.Errorf("error getting summary of validators %v: %w", validators, err)
+	}
+	return summary, nil
+}
+
 func GetDashboardWithdrawalsCount(validators []uint64) (uint64, error) {
 	var count uint64
 	validatorFilter := pq.Array(validators)
@@ -2932,6 +3006,52 @@ func GetValidatorsBLSChange(validators []uint64) ([]*types.ValidatorsBLSChange,
 	return change, nil
 }
 
+// GetValidatorCredentialRotations returns the withdrawal credential rotations of a validator that were included in canonical blocks
+func GetValidatorCredentialRotations(validatorindex uint64) ([]*types.ValidatorCredentialRotation, error) {
+	rotations := []*types.ValidatorCredentialRotation{}
+
+	err := ReaderDb.Select(&rotations, `
+	SELECT
+		r.validatorindex,
+		v.pubkey,
+		r.block_slot,
+		r.from_credentials,
+		r.to_credentials
+	FROM validator_credential_rotations r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	INNER JOIN validators v ON v.validatorindex = r.validatorindex
+	WHERE r.validatorindex = $1
+	ORDER BY r.block_slot`, validatorindex)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credential rotations of validator %v: %w", validatorindex, err)
+	}
+
+	return rotations, nil
+}
+
+// GetEpochCredentialRotations returns all withdrawal credential rotations that were included in canonical blocks of an epoch
+func GetEpochCredentialRotations(epoch uint64) ([]*types.ValidatorCredentialRotation, error) {
+	rotations := []*types.ValidatorCredentialRotation{}
+
+	err := ReaderDb.Select(&rotations, `
+	SELECT
+		r.validatorindex,
+		v.pubkey,
+		r.block_slot,
+		r.from_credentials,
+		r.to_credentials
+	FROM validator_credential_rotations r
+	INNER JOIN blocks b ON b.blockroot = r.block_root AND b.status = '1'
+	INNER JOIN validators v ON v.validatorindex = r.validatorindex
+	WHERE r.block_slot >= $1 AND r.block_slot < $2
+	ORDER BY r.block_slot, r.validatorindex`, epoch*utils.Config.Chain.Config.SlotsPerEpoch, (epoch+1)*utils.Config.Chain.Config.SlotsPerEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting credential rotations of epoch %v: %w", epoch, err)
+	}
+
+	return rotations, nil
+}
+
 func GetValidatorsInitialWithdrawalCredentials(validators []uint64) ([][]byte, error) {
 	var withdrawalCredentials [][]byte
 
@@ -2953,6 +3073,40 @@ func GetValidatorsInitialWithdrawalCredentials(validators []uint64) ([][]byte, e
 	return withdrawalCredentials, nil
 }
 
+// GetValidatorPubkeysByWithdrawalCredentials returns the hex encoded public keys of the validators currently using the given withdrawal credentials
+func GetValidatorPubkeysByWithdrawalCredentials(credentials []byte, limit int) ([]string, error) {
+	pubkeys := []string{}
+	err := ReaderDb.Select(&pubkeys, `
+	SELECT
+		pubkeyhex
+	FROM validators
+	WHERE withdrawalcredentials = $1
+	ORDER BY validatorindex
+	LIMIT $2`, credentials, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validators of withdrawal credentials 0x%x: %w", credentials, err)
+	}
+	return pubkeys, nil
+}
+
+// GetValidatorPubkeysByFeeRecipient returns the hex encoded public keys of the validators that proposed at least one canonical block paying the given fee recipient
+func GetValidatorPubkeysByFeeRecipient(address []byte, limit int) ([]string, error) {
+	pubkeys := []string{}
+	err := ReaderDb.Select(&pubkeys, `
+	SELECT
+		v.pubkeyhex
+	FROM validators v
+	WHERE v.validatorindex IN (
+		SELECT DISTINCT proposer FROM blocks WHERE exec_fee_recipient = $1 AND status = '1'
+	)
+	ORDER BY v.validatorindex
+	LIMIT $2`, address, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting validators of fee recipient 0x%x: %w", address, err)
+	}
+	return pubkeys, nil
+}
+
 func GetWithdrawableValidatorCount(epoch uint64) (uint64, error) {
 	var count uint64
 	err := ReaderDb.Get(&count, `
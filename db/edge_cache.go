@@ -0,0 +1,34 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EdgeCacheBlockKeys returns the surrogate keys of the cached responses a block invalidates: the block itself and the
+// addresses whose balances or activity it changes, including the miner and the token contracts of its transfers
+func EdgeCacheBlockKeys(block *types.Eth1Block) ([]string, error) {
+	streamBlock, err := Eth1StreamBlockFromBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{utils.EdgeCacheBlockKey(block.GetNumber())}
+	seen := make(map[string]bool)
+	add := func(address string) {
+		if address == "" || address == "0x" || seen[address] {
+			return
+		}
+		seen[address] = true
+		keys = append(keys, utils.EdgeCacheAddressKey(common.FromHex(address)))
+	}
+	add(streamBlock.Miner)
+	for _, activity := range streamBlock.Activity {
+		add(activity.From)
+		add(activity.To)
+		add(activity.Token)
+	}
+	return keys, nil
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestEdgeCacheBlockKeys(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.DepositChainID = 1
+
+	miner := common.HexToAddress("0x0101010101010101010101010101010101010101")
+	sender := common.HexToAddress("0x0202020202020202020202020202020202020202")
+	token := common.HexToAddress("0x0303030303030303030303030303030303030303")
+
+	block := &types.Eth1Block{
+		Number:   100,
+		Coinbase: miner.Bytes(),
+		Time:     timestamppb.New(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)),
+		Transactions: []*types.Eth1Transaction{
+			{Hash: []byte{0x01}, From: sender.Bytes(), To: miner.Bytes(), Value: big.NewInt(5).Bytes()},
+			{Hash: []byte{0x02}, From: sender.Bytes(), To: token.Bytes(), Logs: []*types.Eth1Log{
+				{Address: token.Bytes(), Topics: [][]byte{erc20.TransferTopic, sender.Hash().Bytes(), miner.Hash().Bytes()}, Data: common.BigToHash(big.NewInt(7)).Bytes()},
+			}},
+		},
+	}
+
+	keys, err := EdgeCacheBlockKeys(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"1-block-100",
+		"1-address-0101010101010101010101010101010101010101",
+		"1-address-0202020202020202020202020202020202020202",
+		"1-address-0303030303030303030303030303030303030303",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys %v, got %v", expected, keys)
+	}
+}
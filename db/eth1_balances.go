@@ -0,0 +1,40 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+)
+
+// GetEth1BalanceAt returns the stored balance of an address at a block, nil is returned if the balance has not been stored yet
+func GetEth1BalanceAt(address, token []byte, block uint64) (*big.Int, error) {
+	var balance string
+	err := ReaderDb.Get(&balance, `
+		SELECT balance::TEXT
+		FROM eth1_balance_history
+		WHERE address = $1 AND token = $2 AND block_number = $3`, address, token, block)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting balance of address 0x%x for token 0x%x at block %v: %w", address, token, block, err)
+	}
+
+	ret, ok := new(big.Int).SetString(balance, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance %v stored for address 0x%x and token 0x%x at block %v", balance, address, token, block)
+	}
+	return ret, nil
+}
+
+// SaveEth1BalanceAt stores the balance of an address at a block, only balances of final blocks should be stored
+func SaveEth1BalanceAt(address, token []byte, block uint64, balance *big.Int) error {
+	_, err := WriterDb.Exec(`
+		INSERT INTO eth1_balance_history (address, token, block_number, balance)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (address, token, block_number) DO NOTHING`, address, token, block, balance.String())
+	if err != nil {
+		return fmt.Errorf("error saving balance of address 0x%x for token 0x%x at block %v: %w", address, token, block, err)
+	}
+	return nil
+}
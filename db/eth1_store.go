@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"eth2-exporter/types"
+	"sync"
+	"time"
+
+	"github.com/coocood/freecache"
+)
+
+// Eth1BlockStore stores the raw execution blocks as received from the node
+type Eth1BlockStore interface {
+	SaveBlock(ctx context.Context, block *types.Eth1Block) error
+	GetBlockFromBlocksTable(ctx context.Context, number uint64) (*types.Eth1Block, error)
+	GetFullBlocksDescending(ctx context.Context, stream chan<- *types.Eth1Block, high, low uint64) error
+	GetLastBlockInBlocksTable(ctx context.Context) (int, error)
+}
+
+// Eth1IndexReader provides the indexed execution data that is displayed on the eth1 pages and api endpoints
+type Eth1IndexReader interface {
+	GetLastBlockInDataTable(ctx context.Context) (int, error)
+	GetTombstoneCounts(ctx context.Context, sinceBlock uint64) (orphanedBlocks uint64, rows uint64, err error)
+	GetBlocksDescending(ctx context.Context, start, limit uint64) ([]*types.Eth1BlockIndexed, error)
+	GetBlocksIndexedMultiple(ctx context.Context, blockNumbers []uint64, limit uint64) ([]*types.Eth1BlockIndexed, error)
+	GetBlockInternalTableData(ctx context.Context, number uint64, pageToken string) (*types.DataTableResponse, error)
+	GetIndexedEth1Transaction(ctx context.Context, txHash []byte) (*types.Eth1TransactionIndexed, error)
+	GetIndexedEth1Transactions(ctx context.Context, txHashes [][]byte) ([]*types.Eth1TransactionIndexed, error)
+	GetTxFeeBreakdown(ctx context.Context, txHash []byte) (*types.TxFeeBreakdown, error)
+	GetEth1TxByHash(ctx context.Context, txHash []byte) (*types.Eth1TxByHash, error)
+	GetArbitraryTokenTransfersForTransaction(ctx context.Context, transaction []byte) ([]*types.Transfer, error)
+	GetInternalTransfersForTransaction(ctx context.Context, transaction []byte, from []byte) ([]types.Transfer, error)
+	GetItxTreeForTransaction(ctx context.Context, txHash []byte) ([]*types.ItxTreeNode, error)
+
+	GetAddressTransactionsTableData(ctx context.Context, address []byte, filter IndexFilter, pageToken string) (*types.DataTableResponse, error)
+	GetAddressTransactionsTablePage(ctx context.Context, address []byte, filter IndexFilter, pageToken string, start, length int64) (*types.DataTableResponse, error)
+	GetAddressInternalTableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc20TableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc721TableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc1155TableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressBlocksMinedTableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressUnclesMinedTableData(ctx context.Context, address string, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressContractInteractionsTableData(ctx context.Context, address []byte) (*types.DataTableResponse, error)
+	GetAddressNFTsTableData(ctx context.Context, address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetAddressActivityTableData(ctx context.Context, address []byte, pageToken string, hideAirdrops bool) (*types.DataTableResponse, error)
+	StreamAddressTransfers(ctx context.Context, address []byte, from, to time.Time, f func([]*types.AddressTransferExport) error) error
+	GetTokenTransactionsTableData(ctx context.Context, token []byte, address []byte, pageToken string) (*types.DataTableResponse, error)
+	GetTokenHoldersTableData(ctx context.Context, token []byte, pageToken string) (*types.DataTableResponse, error)
+	GetContractSelfDestruct(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error)
+	GetContractCreation(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error)
+	GetAddressFunding(ctx context.Context, address []byte) (*types.Eth1InternalTransactionIndexed, error)
+
+	GetMetadataForAddress(ctx context.Context, address []byte) (*types.Eth1AddressMetadata, error)
+	GetAddressTokenBalances(ctx context.Context, address []byte) (*types.AddressTokenBalances, error)
+	GetBalanceForAddress(ctx context.Context, address []byte, token []byte) (*types.Eth1AddressBalance, error)
+	GetAddressBalanceHistory(ctx context.Context, address []byte, from, to uint64) ([]*types.Eth1BalanceSnapshot, error)
+	GetAddressSummary(ctx context.Context, address []byte) (*types.AddressSummary, error)
+	GetERC20MetadataForAddress(ctx context.Context, address []byte) (*types.ERC20Metadata, error)
+	GetContractMetadata(ctx context.Context, address []byte) (*types.ContractMetadata, error)
+	GetAddressName(ctx context.Context, address []byte) (string, error)
+	GetAddressNames(ctx context.Context, addresses map[string]string) error
+	GetEnsAddress(ctx context.Context, name string) ([]byte, error)
+	GetEnsNames(ctx context.Context, addresses map[string]string) error
+	GetAddressForLabel(ctx context.Context, slug string) ([]byte, error)
+	GetAddressesNamesArMetadata(ctx context.Context, names *map[string]string, inputMetadata *map[string]*types.ERC20Metadata) (map[string]string, map[string]*types.ERC20Metadata, error)
+	GetMethodLabel(ctx context.Context, id []byte, invokesContract bool) string
+	GetEventLabel(ctx context.Context, id []byte) string
+}
+
+// Eth1Transformer converts a raw execution block into the mutations of the data and metadata updates tables
+type Eth1Transformer interface {
+	TransformBlock(block *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformTx(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformItx(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformERC20(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformERC721(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformERC1155(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformLogs(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformUncle(block *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformWithdrawals(block *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformContractInteractions(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformContractCreations(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+	TransformFundings(blk *types.Eth1Block, cache *freecache.Cache) (bulkData *types.BulkMutations, bulkMetadataUpdates *types.BulkMutations, err error)
+}
+
+// Eth1Store is the storage backend of the execution layer index, it is implemented by Bigtable
+// and can be replaced by an in-memory fake in tests or by alternative backends
+type Eth1Store interface {
+	Eth1BlockStore
+	Eth1IndexReader
+	Eth1Transformer
+}
+
+var _ Eth1Store = (*Bigtable)(nil)
+
+var eth1Store Eth1Store
+var eth1StoreMux = &sync.RWMutex{}
+
+// SetEth1Store replaces the execution index backend returned by GetEth1Store, passing nil restores the bigtable client
+func SetEth1Store(store Eth1Store) {
+	eth1StoreMux.Lock()
+	defer eth1StoreMux.Unlock()
+	eth1Store = store
+}
+
+// GetEth1Store returns the execution index backend, defaults to the process wide bigtable client
+func GetEth1Store() Eth1Store {
+	eth1StoreMux.RLock()
+	defer eth1StoreMux.RUnlock()
+	if eth1Store != nil {
+		return eth1Store
+	}
+	return BigtableClient
+}
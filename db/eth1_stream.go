@@ -0,0 +1,102 @@
+package db
+
+import (
+	"bytes"
+	"eth2-exporter/erc1155"
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	eth_types "github.com/ethereum/go-ethereum/core/types"
+)
+
+// Eth1StreamBlockFromBlock extracts the transactions and ERC20, ERC721 and ERC1155 transfers of a block that are published to
+// the subscribers of the websocket stream
+func Eth1StreamBlockFromBlock(block *types.Eth1Block) (*types.Eth1StreamBlock, error) {
+	erc1155Filterer, err := erc1155.NewErc1155Filterer(common.Address{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating erc1155 filterer: %w", err)
+	}
+
+	streamBlock := &types.Eth1StreamBlock{
+		Number:  block.GetNumber(),
+		Hash:    fmt.Sprintf("0x%x", block.GetHash()),
+		Time:    block.GetTime().AsTime(),
+		Miner:   fmt.Sprintf("0x%x", block.GetCoinbase()),
+		TxCount: len(block.GetTransactions()),
+		GasUsed: block.GetGasUsed(),
+		BaseFee: new(big.Int).SetBytes(block.GetBaseFee()).String(),
+	}
+	for _, tx := range block.GetTransactions() {
+		method := ""
+		if len(tx.GetData()) >= 4 {
+			method = fmt.Sprintf("0x%x", tx.GetData()[:4])
+		}
+		to := tx.GetTo()
+		if len(to) == 0 {
+			to = tx.GetContractAddress()
+		}
+		streamBlock.Activity = append(streamBlock.Activity, &types.Eth1StreamActivity{
+			Type:        "transaction",
+			TxHash:      fmt.Sprintf("0x%x", tx.GetHash()),
+			BlockNumber: block.GetNumber(),
+			From:        fmt.Sprintf("0x%x", tx.GetFrom()),
+			To:          fmt.Sprintf("0x%x", to),
+			Method:      method,
+			Value:       new(big.Int).SetBytes(tx.GetValue()).String(),
+			Failed:      tx.GetErrorMsg() != "",
+		})
+
+		airdrops := detectAirdrops(tx)
+		transfer := func(typ string, log *types.Eth1Log, from, to common.Address, tokenId, value *big.Int) {
+			activity := &types.Eth1StreamActivity{
+				Type:        typ,
+				TxHash:      fmt.Sprintf("0x%x", tx.GetHash()),
+				BlockNumber: block.GetNumber(),
+				From:        fmt.Sprintf("0x%x", from.Bytes()),
+				To:          fmt.Sprintf("0x%x", to.Bytes()),
+				Method:      method,
+				Token:       fmt.Sprintf("0x%x", log.GetAddress()),
+				Value:       value.String(),
+				Airdrop:     airdrops[airdropKey(log.GetAddress(), from.Bytes())],
+			}
+			if tokenId != nil {
+				activity.TokenId = tokenId.String()
+			}
+			streamBlock.Activity = append(streamBlock.Activity, activity)
+		}
+		for _, log := range tx.GetLogs() {
+			topics := log.GetTopics()
+			switch {
+			case len(topics) == 3 && bytes.Equal(topics[0], erc20.TransferTopic):
+				transfer("erc20", log, common.BytesToAddress(topics[1]), common.BytesToAddress(topics[2]), nil, new(big.Int).SetBytes(log.GetData()))
+			case len(topics) == 4 && bytes.Equal(topics[0], erc20.TransferTopic):
+				// ERC721 transfers share the event signature of ERC20 transfers but index the token id
+				transfer("erc721", log, common.BytesToAddress(topics[1]), common.BytesToAddress(topics[2]), new(big.Int).SetBytes(topics[3]), big.NewInt(1))
+			case len(topics) == 4 && (bytes.Equal(topics[0], erc1155.TransferSingleTopic) || bytes.Equal(topics[0], erc1155.TransferBulkTopic)):
+				ethLog := eth_types.Log{Address: common.BytesToAddress(log.GetAddress()), Data: log.GetData()}
+				for _, topic := range topics {
+					ethLog.Topics = append(ethLog.Topics, common.BytesToHash(topic))
+				}
+				if bytes.Equal(topics[0], erc1155.TransferSingleTopic) {
+					single, err := erc1155Filterer.ParseTransferSingle(ethLog)
+					if err != nil {
+						continue
+					}
+					transfer("erc1155", log, single.From, single.To, single.Id, single.Value)
+					continue
+				}
+				batch, err := erc1155Filterer.ParseTransferBatch(ethLog)
+				if err != nil || len(batch.Ids) != len(batch.Values) {
+					continue
+				}
+				for i := range batch.Ids {
+					transfer("erc1155", log, batch.From, batch.To, batch.Ids[i], batch.Values[i])
+				}
+			}
+		}
+	}
+	return streamBlock, nil
+}
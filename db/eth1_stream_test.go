@@ -0,0 +1,66 @@
+package db
+
+import (
+	"eth2-exporter/erc20"
+	"eth2-exporter/types"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestEth1StreamBlockFromBlock(t *testing.T) {
+	sender := common.HexToAddress("0x0101010101010101010101010101010101010101")
+	recipient := common.HexToAddress("0x0202020202020202020202020202020202020202")
+	token := common.HexToAddress("0x0303030303030303030303030303030303030303")
+
+	transferData := append([]byte{0xa9, 0x05, 0x9c, 0xbb}, make([]byte, 64)...)
+	block := &types.Eth1Block{
+		Number: 100,
+		Time:   timestamppb.New(time.Date(2023, 7, 1, 12, 0, 0, 0, time.UTC)),
+		Transactions: []*types.Eth1Transaction{
+			{Hash: []byte{0x01}, From: sender.Bytes(), To: recipient.Bytes(), Value: big.NewInt(5).Bytes()},
+			{Hash: []byte{0x02}, From: sender.Bytes(), To: token.Bytes(), Data: transferData, Logs: []*types.Eth1Log{
+				{Address: token.Bytes(), Topics: [][]byte{erc20.TransferTopic, sender.Hash().Bytes(), recipient.Hash().Bytes()}, Data: common.BigToHash(big.NewInt(7)).Bytes()},
+				{Address: token.Bytes(), Topics: [][]byte{erc20.TransferTopic, sender.Hash().Bytes(), recipient.Hash().Bytes(), common.BigToHash(big.NewInt(9)).Bytes()}},
+			}},
+		},
+	}
+
+	streamBlock, err := Eth1StreamBlockFromBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if streamBlock.Number != 100 || streamBlock.TxCount != 2 || len(streamBlock.Activity) != 4 {
+		t.Fatalf("unexpected block %+v", streamBlock)
+	}
+
+	expected := []types.Eth1StreamActivity{
+		{Type: "transaction", Method: "", Value: "5"},
+		{Type: "transaction", Method: "0xa9059cbb", Value: "0"},
+		{Type: "erc20", Method: "0xa9059cbb", Token: "0x0303030303030303030303030303030303030303", Value: "7"},
+		{Type: "erc721", Method: "0xa9059cbb", Token: "0x0303030303030303030303030303030303030303", TokenId: "9", Value: "1"},
+	}
+	for i, activity := range streamBlock.Activity {
+		e := expected[i]
+		if activity.Type != e.Type || activity.Method != e.Method || activity.Token != e.Token || activity.TokenId != e.TokenId || activity.Value != e.Value {
+			t.Errorf("unexpected activity %v: %+v", i, activity)
+		}
+	}
+
+	filter := &types.Eth1StreamFilter{Address: "0x0202020202020202020202020202020202020202", Token: "0x0303030303030303030303030303030303030303"}
+	matched := 0
+	for _, activity := range streamBlock.Activity {
+		if filter.Matches(activity) {
+			matched++
+		}
+	}
+	if matched != 2 {
+		t.Errorf("expected the filter to match both token transfers, matched %v", matched)
+	}
+	if filter.Method = "0x095ea7b3"; filter.Matches(streamBlock.Activity[2]) {
+		t.Errorf("expected the filter not to match the transfer of another method")
+	}
+}
@@ -0,0 +1,93 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GetUserFeeRecipients returns the fee recipients a user expects the proposals of the watched validators to pay
+func GetUserFeeRecipients(userID uint64, network string) ([][]byte, error) {
+	recipients := [][]byte{}
+	err := FrontendWriterDB.Select(&recipients, `
+		SELECT fee_recipient
+		FROM users_fee_recipients
+		WHERE user_id = $1 AND network = $2
+		ORDER BY created_ts, fee_recipient`, userID, strings.ToLower(network))
+	if err != nil {
+		return nil, fmt.Errorf("error getting fee recipients of user %v: %w", userID, err)
+	}
+	return recipients, nil
+}
+
+// SetUserFeeRecipients replaces the expected fee recipients of a user, an empty list disables the fee recipient checks
+func SetUserFeeRecipients(userID uint64, network string, recipients [][]byte) error {
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM users_fee_recipients WHERE user_id = $1 AND network = $2`, userID, strings.ToLower(network))
+	if err != nil {
+		return fmt.Errorf("error deleting fee recipients of user %v: %w", userID, err)
+	}
+
+	now := time.Now()
+	for _, recipient := range recipients {
+		_, err = tx.Exec(`
+			INSERT INTO users_fee_recipients (user_id, network, fee_recipient, created_ts)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, network, fee_recipient) DO NOTHING`,
+			userID, strings.ToLower(network), recipient, now)
+		if err != nil {
+			return fmt.Errorf("error saving fee recipient %#x of user %v: %w", recipient, userID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFeeRecipientsByUser returns the expected fee recipients of all users of a network that have configured any, keyed by user id
+func GetFeeRecipientsByUser(network string) (map[uint64][][]byte, error) {
+	rows := []struct {
+		UserID       uint64 `db:"user_id"`
+		FeeRecipient []byte `db:"fee_recipient"`
+	}{}
+	err := FrontendWriterDB.Select(&rows, `SELECT user_id, fee_recipient FROM users_fee_recipients WHERE network = $1`, strings.ToLower(network))
+	if err != nil {
+		return nil, fmt.Errorf("error getting fee recipients of users: %w", err)
+	}
+
+	recipients := make(map[uint64][][]byte)
+	for _, row := range rows {
+		recipients[row.UserID] = append(recipients[row.UserID], row.FeeRecipient)
+	}
+	return recipients, nil
+}
+
+// GetEpochProposalFeeRecipients returns the fee recipients paid by the canonical blocks of an epoch that carry an execution payload
+func GetEpochProposalFeeRecipients(epoch uint64) ([]*types.ProposalFeeRecipient, error) {
+	proposals := []*types.ProposalFeeRecipient{}
+
+	// a block delivered by several relays has a row per relay, the fee recipient reported by the relays is the same
+	err := ReaderDb.Select(&proposals, `
+	SELECT DISTINCT ON (b.slot)
+		b.proposer,
+		v.pubkey,
+		b.slot,
+		COALESCE(b.exec_block_number, 0) AS exec_block_number,
+		b.exec_fee_recipient,
+		rb.proposer_fee_recipient
+	FROM blocks b
+	INNER JOIN validators v ON v.validatorindex = b.proposer
+	LEFT JOIN relays_blocks rb ON rb.exec_block_hash = b.exec_block_hash
+	WHERE b.epoch = $1 AND b.status = '1' AND b.exec_fee_recipient IS NOT NULL
+	ORDER BY b.slot`, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the proposal fee recipients of epoch %v: %w", epoch, err)
+	}
+
+	return proposals, nil
+}
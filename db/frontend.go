@@ -663,3 +663,171 @@ func GetDataTablesState(user uint64, key string) (*types.DataTableSaveState, err
 
 	return &state, err
 }
+
+// MaxUserDashboards is the maximum number of custom dashboards a user can create per network
+const MaxUserDashboards = 10
+
+// ErrUserDashboardLimit is returned when a user tries to create more than MaxUserDashboards dashboards
+var ErrUserDashboardLimit = errors.New("dashboard limit reached")
+
+// GetUserDashboards returns all custom dashboards of a user on the current network
+func GetUserDashboards(user uint64) ([]*types.UserDashboard, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	dashboards := []*types.UserDashboard{}
+	err := FrontendReaderDB.SelectContext(ctx, &dashboards, `
+		SELECT id, name, layout, updated_at
+		FROM users_dashboards
+		WHERE user_id = $1 AND network = $2
+		ORDER BY id
+	`, user, utils.GetNetwork())
+
+	return dashboards, err
+}
+
+// GetUserDashboard returns a single custom dashboard of a user
+func GetUserDashboard(user, id uint64) (*types.UserDashboard, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	dashboard := &types.UserDashboard{}
+	err := FrontendReaderDB.GetContext(ctx, dashboard, `
+		SELECT id, name, layout, updated_at
+		FROM users_dashboards
+		WHERE id = $1 AND user_id = $2 AND network = $3
+	`, id, user, utils.GetNetwork())
+
+	return dashboard, err
+}
+
+// SaveUserDashboard creates a new dashboard if its id is 0 and updates the existing dashboard of the user otherwise, it returns the id of the dashboard
+func SaveUserDashboard(user uint64, dashboard *types.UserDashboard) (uint64, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	if dashboard.ID != 0 {
+		res, err := FrontendWriterDB.ExecContext(ctx, `
+			UPDATE users_dashboards
+			SET name = $1, layout = $2, updated_at = NOW()
+			WHERE id = $3 AND user_id = $4 AND network = $5
+		`, dashboard.Name, dashboard.Layout, dashboard.ID, user, utils.GetNetwork())
+		if err != nil {
+			return 0, err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		if rows == 0 {
+			return 0, sql.ErrNoRows
+		}
+		return dashboard.ID, nil
+	}
+
+	tx, err := FrontendWriterDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count := 0
+	err = tx.GetContext(ctx, &count, `SELECT COUNT(*) FROM users_dashboards WHERE user_id = $1 AND network = $2`, user, utils.GetNetwork())
+	if err != nil {
+		return 0, err
+	}
+	if count >= MaxUserDashboards {
+		return 0, ErrUserDashboardLimit
+	}
+
+	var id uint64
+	err = tx.GetContext(ctx, &id, `
+		INSERT INTO users_dashboards (user_id, network, name, layout)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, user, utils.GetNetwork(), dashboard.Name, dashboard.Layout)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// DeleteUserDashboard removes a custom dashboard of a user
+func DeleteUserDashboard(user, id uint64) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	_, err := FrontendWriterDB.ExecContext(ctx, `DELETE FROM users_dashboards WHERE id = $1 AND user_id = $2 AND network = $3`, id, user, utils.GetNetwork())
+	return err
+}
+
+// MaxUserSavedFilters is the maximum number of filters a user can save per table and network
+const MaxUserSavedFilters = 20
+
+// ErrUserSavedFilterLimit is returned when a user tries to save more than MaxUserSavedFilters filters for a table
+var ErrUserSavedFilterLimit = errors.New("saved filter limit reached")
+
+// GetUserSavedFilters returns the saved filters of a user for a table on the current network
+func GetUserSavedFilters(user uint64, table types.SavedFilterTable) ([]*types.UserSavedFilter, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	filters := []*types.UserSavedFilter{}
+	err := FrontendReaderDB.SelectContext(ctx, &filters, `
+		SELECT id, table_name, name, params, updated_at
+		FROM users_saved_filters
+		WHERE user_id = $1 AND network = $2 AND table_name = $3
+		ORDER BY name
+	`, user, utils.GetNetwork(), table)
+
+	return filters, err
+}
+
+// SaveUserSavedFilter saves a filter of a user, an existing filter of the same table and name is replaced. It returns the id of the filter.
+func SaveUserSavedFilter(user uint64, filter *types.UserSavedFilter) (uint64, error) {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	tx, err := FrontendWriterDB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// replacing an existing filter does not count against the limit
+	count := 0
+	err = tx.GetContext(ctx, &count, `
+		SELECT COUNT(*)
+		FROM users_saved_filters
+		WHERE user_id = $1 AND network = $2 AND table_name = $3 AND name <> $4
+	`, user, utils.GetNetwork(), filter.Table, filter.Name)
+	if err != nil {
+		return 0, err
+	}
+	if count >= MaxUserSavedFilters {
+		return 0, ErrUserSavedFilterLimit
+	}
+
+	var id uint64
+	err = tx.GetContext(ctx, &id, `
+		INSERT INTO users_saved_filters (user_id, network, table_name, name, params)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, network, table_name, name) DO UPDATE SET params = excluded.params, updated_at = NOW()
+		RETURNING id
+	`, user, utils.GetNetwork(), filter.Table, filter.Name, filter.Params)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+// DeleteUserSavedFilter removes a saved filter of a user
+func DeleteUserSavedFilter(user, id uint64) error {
+	ctx, done := context.WithTimeout(context.Background(), time.Second*30)
+	defer done()
+
+	_, err := FrontendWriterDB.ExecContext(ctx, `DELETE FROM users_saved_filters WHERE id = $1 AND user_id = $2 AND network = $3`, id, user, utils.GetNetwork())
+	return err
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// gasPriceOfTransaction returns the gas price a transaction paid in wei, prices beyond the range of the BIGINT columns are capped
+func gasPriceOfTransaction(tx *types.Eth1Transaction) uint64 {
+	price := new(big.Int).SetBytes(tx.GetGasPrice())
+	if !price.IsInt64() {
+		return math.MaxInt64
+	}
+	return price.Uint64()
+}
+
+// gasPricePercentiles sorts the gas prices of an hour and returns their 10th, 50th and 90th percentile using the nearest rank
+func gasPricePercentiles(hour time.Time, prices []uint64) *types.GasPricePercentiles {
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i] < prices[j]
+	})
+	percentile := func(p int) uint64 {
+		rank := (p*len(prices) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return prices[rank-1]
+	}
+	return &types.GasPricePercentiles{
+		Hour:         hour,
+		Transactions: uint64(len(prices)),
+		P10:          percentile(10),
+		P50:          percentile(50),
+		P90:          percentile(90),
+	}
+}
+
+// SaveGasPriceHourly replaces the gas price percentiles of the hours of a day, hours without transactions are not stored
+func SaveGasPriceHourly(day time.Time, hours []*types.GasPricePercentiles) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM gas_price_hourly WHERE ts >= $1 AND ts < $2`, day, day.Add(time.Hour*24))
+	if err != nil {
+		return fmt.Errorf("error deleting gas price percentiles of day %v: %w", day, err)
+	}
+
+	// the hours are passed as offsets to the start of the day
+	offsets := make(pq.Int64Array, 0, len(hours))
+	txCounts := make(pq.Int64Array, 0, len(hours))
+	p10 := make(pq.Int64Array, 0, len(hours))
+	p50 := make(pq.Int64Array, 0, len(hours))
+	p90 := make(pq.Int64Array, 0, len(hours))
+	for _, h := range hours {
+		offsets = append(offsets, int64(h.Hour.Sub(day)/time.Hour))
+		txCounts = append(txCounts, int64(h.Transactions))
+		p10 = append(p10, int64(h.P10))
+		p50 = append(p50, int64(h.P50))
+		p90 = append(p90, int64(h.P90))
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO gas_price_hourly (ts, tx_count, p10, p50, p90)
+		SELECT $1::timestamp + UNNEST($2::int[]) * INTERVAL '1 hour', UNNEST($3::int[]), UNNEST($4::bigint[]), UNNEST($5::bigint[]), UNNEST($6::bigint[])`,
+		day, offsets, txCounts, p10, p50, p90)
+	if err != nil {
+		return fmt.Errorf("error saving gas price percentiles of day %v: %w", day, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetGasPriceHistory returns the hourly gas price percentiles of the last hours, oldest first
+func GetGasPriceHistory(hours uint64) ([]*types.GasPricePercentiles, error) {
+	history := []*types.GasPricePercentiles{}
+	err := ReaderDb.Select(&history, `
+		SELECT ts, tx_count, p10, p50, p90
+		FROM gas_price_hourly
+		WHERE ts > NOW() - $1::INT * INTERVAL '1 hour'
+		ORDER BY ts`, hours)
+	if err != nil {
+		return nil, fmt.Errorf("error getting gas price history: %w", err)
+	}
+	return history, nil
+}
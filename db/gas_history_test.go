@@ -0,0 +1,28 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGasPricePercentiles(t *testing.T) {
+	hour := time.Date(2023, 6, 1, 13, 0, 0, 0, time.UTC)
+
+	prices := []uint64{}
+	for price := uint64(100); price > 0; price-- {
+		prices = append(prices, price)
+	}
+	percentiles := gasPricePercentiles(hour, prices)
+	if percentiles.Transactions != 100 || percentiles.P10 != 10 || percentiles.P50 != 50 || percentiles.P90 != 90 {
+		t.Errorf("unexpected percentiles %+v", percentiles)
+	}
+	if !percentiles.Hour.Equal(hour) {
+		t.Errorf("unexpected hour %v", percentiles.Hour)
+	}
+
+	// the nearest rank of a single price is the price itself for all percentiles
+	percentiles = gasPricePercentiles(hour, []uint64{7})
+	if percentiles.P10 != 7 || percentiles.P50 != 7 || percentiles.P90 != 7 {
+		t.Errorf("unexpected percentiles of a single price %+v", percentiles)
+	}
+}
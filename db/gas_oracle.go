@@ -0,0 +1,143 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+const (
+	// EIP 1559 parameters of the base fee adjustment
+	baseFeeElasticityMultiplier = 2
+	baseFeeChangeDenominator    = 8
+
+	// base fee changes of the oracle window below this percentage are reported as stable
+	gasOracleStableThreshold = 5
+)
+
+// NextBaseFee returns the base fee of the block following the given block as defined by EIP 1559, nil for pre London blocks
+func NextBaseFee(block *types.Eth1Block) *big.Int {
+	if len(block.GetBaseFee()) == 0 {
+		return nil
+	}
+	baseFee := new(big.Int).SetBytes(block.GetBaseFee())
+	target := block.GetGasLimit() / baseFeeElasticityMultiplier
+	if target == 0 || block.GetGasUsed() == target {
+		return baseFee
+	}
+
+	var delta uint64
+	if block.GetGasUsed() > target {
+		delta = block.GetGasUsed() - target
+	} else {
+		delta = target - block.GetGasUsed()
+	}
+	change := new(big.Int).Mul(baseFee, new(big.Int).SetUint64(delta))
+	change.Div(change, new(big.Int).SetUint64(target))
+	change.Div(change, big.NewInt(baseFeeChangeDenominator))
+
+	if block.GetGasUsed() > target {
+		// the base fee increases by at least 1 wei
+		if change.Sign() == 0 {
+			change.SetInt64(1)
+		}
+		return baseFee.Add(baseFee, change)
+	}
+	baseFee.Sub(baseFee, change)
+	if baseFee.Sign() < 0 {
+		baseFee.SetInt64(0)
+	}
+	return baseFee
+}
+
+// effectivePriorityFee returns the tip per gas a transaction paid to the fee recipient of a block with the given base fee
+func effectivePriorityFee(tx *types.Eth1Transaction, baseFee *big.Int) *big.Int {
+	var tip *big.Int
+	if len(tx.GetMaxFeePerGas()) > 0 {
+		tip = new(big.Int).Sub(new(big.Int).SetBytes(tx.GetMaxFeePerGas()), baseFee)
+		if maxTip := new(big.Int).SetBytes(tx.GetMaxPriorityFeePerGas()); maxTip.Cmp(tip) < 0 {
+			tip = maxTip
+		}
+	} else {
+		tip = new(big.Int).Sub(new(big.Int).SetBytes(tx.GetGasPrice()), baseFee)
+	}
+	if tip.Sign() < 0 {
+		return new(big.Int)
+	}
+	return tip
+}
+
+// EffectiveGasPrice returns the price per gas a transaction paid in a block with the given base fee, for blocks before London
+// (baseFee is nil) this is the gas price of the transaction
+func EffectiveGasPrice(tx *types.Eth1Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).SetBytes(tx.GetGasPrice())
+	}
+	return new(big.Int).Add(baseFee, effectivePriorityFee(tx, baseFee))
+}
+
+// GasOracleFromBlocks estimates the gas prices for the next block from the priority fees paid by the transactions of the most
+// recent blocks, which have to be passed newest first. Blocks without transactions count as paying no tip, so that an idle
+// network lowers the estimates to the base fee.
+func GasOracleFromBlocks(blocks []*types.Eth1Block) (*types.GasOracle, error) {
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("no blocks to estimate the gas price from")
+	}
+	latest := blocks[0]
+	nextBaseFee := NextBaseFee(latest)
+	if nextBaseFee == nil {
+		return nil, fmt.Errorf("block %v has no base fee", latest.GetNumber())
+	}
+
+	tips := []*big.Int{}
+	baseFeeSum := new(big.Int)
+	for _, block := range blocks {
+		baseFee := new(big.Int).SetBytes(block.GetBaseFee())
+		baseFeeSum.Add(baseFeeSum, baseFee)
+		if len(block.GetTransactions()) == 0 {
+			tips = append(tips, new(big.Int))
+			continue
+		}
+		for _, tx := range block.GetTransactions() {
+			tips = append(tips, effectivePriorityFee(tx, baseFee))
+		}
+	}
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) < 0
+	})
+	// nearest rank, like the hourly gas price percentiles
+	percentile := func(p int) *big.Int {
+		rank := (p*len(tips) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return new(big.Int).Add(nextBaseFee, tips[rank-1])
+	}
+
+	oracle := &types.GasOracle{
+		Block:        latest.GetNumber(),
+		Blocks:       len(blocks),
+		Slow:         percentile(10),
+		Standard:     percentile(50),
+		Fast:         percentile(75),
+		Rapid:        percentile(95),
+		BaseFee:      new(big.Int).SetBytes(latest.GetBaseFee()),
+		NextBaseFee:  nextBaseFee,
+		BaseFeeTrend: types.GasOracleTrendStable,
+	}
+
+	// the trend compares the next base fee with the average base fee of the window
+	average := new(big.Int).Div(baseFeeSum, big.NewInt(int64(len(blocks))))
+	if average.Sign() > 0 {
+		change := new(big.Int).Sub(nextBaseFee, average)
+		change.Mul(change, big.NewInt(100))
+		change.Quo(change, average)
+		if change.Cmp(big.NewInt(gasOracleStableThreshold)) >= 0 {
+			oracle.BaseFeeTrend = types.GasOracleTrendRising
+		} else if change.Cmp(big.NewInt(-gasOracleStableThreshold)) <= 0 {
+			oracle.BaseFeeTrend = types.GasOracleTrendFalling
+		}
+	}
+	return oracle, nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"math/big"
+	"testing"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	tests := []struct {
+		gasUsed uint64
+		want    int64
+	}{
+		{15_000_000, 1000},
+		{30_000_000, 1125},
+		{0, 875},
+		// the increase is rounded up to 1 wei
+		{15_000_001, 1001},
+	}
+	for _, tt := range tests {
+		block := &types.Eth1Block{GasLimit: 30_000_000, GasUsed: tt.gasUsed, BaseFee: big.NewInt(1000).Bytes()}
+		if got := NextBaseFee(block); got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("wrong next base fee for %v gas used: got %v, want %v", tt.gasUsed, got, tt.want)
+		}
+	}
+	if NextBaseFee(&types.Eth1Block{GasLimit: 30_000_000}) != nil {
+		t.Errorf("expected no base fee for pre london blocks")
+	}
+}
+
+func TestGasOracleFromBlocks(t *testing.T) {
+	gwei := func(n int64) []byte {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(1e9)).Bytes()
+	}
+	txs := []*types.Eth1Transaction{}
+	for i := int64(1); i <= 10; i++ {
+		// dynamic fee transactions paying a tip of i gwei
+		txs = append(txs, &types.Eth1Transaction{MaxFeePerGas: gwei(100), MaxPriorityFeePerGas: gwei(i)})
+	}
+	// a legacy transaction paying 5 gwei above the base fee and one whose fee cap limits the tip to 1 gwei
+	txs = append(txs, &types.Eth1Transaction{GasPrice: gwei(15)}, &types.Eth1Transaction{MaxFeePerGas: gwei(11), MaxPriorityFeePerGas: gwei(50)})
+
+	blocks := []*types.Eth1Block{
+		{Number: 101, GasLimit: 30_000_000, GasUsed: 30_000_000, BaseFee: gwei(10), Transactions: txs},
+		{Number: 100, GasLimit: 30_000_000, GasUsed: 15_000_000, BaseFee: gwei(10)},
+	}
+	oracle, err := GasOracleFromBlocks(blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nextBaseFee := new(big.Int).SetBytes(gwei(10))
+	nextBaseFee.Add(nextBaseFee, new(big.Int).Div(nextBaseFee, big.NewInt(8)))
+	if oracle.NextBaseFee.Cmp(nextBaseFee) != 0 || oracle.Block != 101 || oracle.Blocks != 2 {
+		t.Errorf("unexpected oracle %+v", oracle)
+	}
+	// tips in gwei: 0 (empty block), 1, 1, 2, 3, 4, 5, 5, 6, 7, 8, 9, 10
+	for name, tt := range map[string]struct {
+		got  *big.Int
+		want int64
+	}{
+		"slow":     {oracle.Slow, 1},
+		"standard": {oracle.Standard, 5},
+		"fast":     {oracle.Fast, 7},
+		"rapid":    {oracle.Rapid, 10},
+	} {
+		want := new(big.Int).Add(nextBaseFee, new(big.Int).SetBytes(gwei(tt.want)))
+		if tt.got.Cmp(want) != 0 {
+			t.Errorf("wrong %v price: got %v, want %v", name, tt.got, want)
+		}
+	}
+	if oracle.BaseFeeTrend != types.GasOracleTrendRising {
+		t.Errorf("expected a rising base fee, got %v", oracle.BaseFeeTrend)
+	}
+
+	if _, err := GasOracleFromBlocks(nil); err == nil {
+		t.Errorf("expected an error without blocks")
+	}
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	baseFee := big.NewInt(100)
+	tests := []struct {
+		name string
+		tx   *types.Eth1Transaction
+		want int64
+	}{
+		{"dynamic fee", &types.Eth1Transaction{GasPrice: big.NewInt(200).Bytes(), MaxFeePerGas: big.NewInt(200).Bytes(), MaxPriorityFeePerGas: big.NewInt(10).Bytes()}, 110},
+		{"capped by the max fee", &types.Eth1Transaction{GasPrice: big.NewInt(105).Bytes(), MaxFeePerGas: big.NewInt(105).Bytes(), MaxPriorityFeePerGas: big.NewInt(10).Bytes()}, 105},
+		{"legacy", &types.Eth1Transaction{GasPrice: big.NewInt(130).Bytes()}, 130},
+	}
+	for _, tt := range tests {
+		got := EffectiveGasPrice(tt.tx, baseFee)
+		if got.Cmp(big.NewInt(tt.want)) != 0 {
+			t.Errorf("%v: got effective gas price %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := EffectiveGasPrice(&types.Eth1Transaction{GasPrice: big.NewInt(130).Bytes()}, nil); got.Int64() != 130 {
+		t.Errorf("expected the gas price before london, got %v", got)
+	}
+
+	breakdown := types.NewTxFeeBreakdown(21000, big.NewInt(110), baseFee)
+	if breakdown.Burned.BigInt().Int64() != 2100000 || breakdown.Tip.BigInt().Int64() != 210000 || breakdown.Total.BigInt().Int64() != 2310000 {
+		t.Errorf("unexpected fee breakdown %+v", breakdown)
+	}
+	if breakdown := types.NewTxFeeBreakdown(21000, big.NewInt(110), nil); !breakdown.Burned.IsZero() || breakdown.Tip.BigInt().Int64() != 2310000 {
+		t.Errorf("expected the whole fee to be tip before london, got %+v", breakdown)
+	}
+}
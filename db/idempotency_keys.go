@@ -0,0 +1,73 @@
+package db
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IdempotencyKeyTTL is the time a response is stored for its idempotency key
+const IdempotencyKeyTTL = time.Hour * 24
+
+// ErrIdempotencyKeyReused is returned if an idempotency key is used again for a request that differs from the first one
+var ErrIdempotencyKeyReused = errors.New("idempotency key has been used for a different request")
+
+// GetUserIdempotentResponse returns the response stored for an idempotency key of a user or nil if the key has not been used
+// within IdempotencyKeyTTL. ErrIdempotencyKeyReused is returned if the key has been used for a request with a different hash.
+func GetUserIdempotentResponse(userID uint64, key string, requestHash []byte) (json.RawMessage, error) {
+	var stored struct {
+		RequestHash []byte `db:"request_hash"`
+		Response    []byte `db:"response"`
+	}
+	err := FrontendWriterDB.Get(&stored, `
+		SELECT request_hash, response
+		FROM users_idempotency_keys
+		WHERE user_id = $1 AND idempotency_key = $2 AND created_ts > NOW() - $3 * INTERVAL '1 second'`,
+		userID, key, IdempotencyKeyTTL.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting idempotency key of user %v: %w", userID, err)
+	}
+	if !bytes.Equal(stored.RequestHash, requestHash) {
+		return nil, ErrIdempotencyKeyReused
+	}
+	return stored.Response, nil
+}
+
+// SaveUserIdempotentResponse stores the response of a request for its idempotency key, the expired keys of the user are removed.
+// If the key is stored concurrently the first response is kept.
+func SaveUserIdempotentResponse(userID uint64, key string, requestHash []byte, response interface{}) error {
+	b, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	tx, err := FrontendWriterDB.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM users_idempotency_keys
+		WHERE user_id = $1 AND created_ts <= NOW() - $2 * INTERVAL '1 second'`,
+		userID, IdempotencyKeyTTL.Seconds())
+	if err != nil {
+		return fmt.Errorf("error deleting expired idempotency keys of user %v: %w", userID, err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO users_idempotency_keys (user_id, idempotency_key, request_hash, response, created_ts)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id, idempotency_key) DO NOTHING`,
+		userID, key, requestHash, b)
+	if err != nil {
+		return fmt.Errorf("error saving idempotency key of user %v: %w", userID, err)
+	}
+	return tx.Commit()
+}
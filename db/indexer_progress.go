@@ -0,0 +1,40 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+)
+
+// SaveIndexerProgress stores the latest progress of an indexing pipeline
+func SaveIndexerProgress(progress *types.IndexerProgress) error {
+	_, err := WriterDb.Exec(`
+		INSERT INTO indexer_progress (name, start_block, end_block, current_block, processed_blocks, blocks_per_second, pending_mutations, started_time, updated_time)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (name) DO UPDATE SET
+			start_block = excluded.start_block,
+			end_block = excluded.end_block,
+			current_block = excluded.current_block,
+			processed_blocks = excluded.processed_blocks,
+			blocks_per_second = excluded.blocks_per_second,
+			pending_mutations = excluded.pending_mutations,
+			started_time = excluded.started_time,
+			updated_time = excluded.updated_time`,
+		progress.Name, progress.StartBlock, progress.EndBlock, progress.CurrentBlock, progress.ProcessedBlocks, progress.BlocksPerSecond, progress.PendingMutations, progress.StartedTime, progress.UpdatedTime)
+	if err != nil {
+		return fmt.Errorf("error saving progress of indexer %v: %w", progress.Name, err)
+	}
+	return nil
+}
+
+// GetIndexerProgress returns the latest progress of all indexing pipelines
+func GetIndexerProgress() ([]*types.IndexerProgress, error) {
+	progress := []*types.IndexerProgress{}
+	err := ReaderDb.Select(&progress, `
+		SELECT name, start_block, end_block, current_block, processed_blocks, blocks_per_second, pending_mutations, started_time, updated_time
+		FROM indexer_progress
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexer progress: %w", err)
+	}
+	return progress, nil
+}
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    transfer_path_jobs (
+        id VARCHAR(40),
+        status VARCHAR(40) NOT NULL,
+        -- can be one of: PENDING, RUNNING, COMPLETED, FAILED
+        created_time TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        completed_time TIMESTAMP WITHOUT TIME ZONE,
+        source bytea NOT NULL,
+        destination bytea NOT NULL,
+        from_block BIGINT NOT NULL,
+        to_block BIGINT NOT NULL,
+        max_depth INT NOT NULL,
+        result jsonb,
+        error TEXT,
+        PRIMARY KEY (id)
+    );
+CREATE INDEX IF NOT EXISTS idx_transfer_path_jobs_status ON transfer_path_jobs (status, created_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS transfer_path_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_dashboards (
+        id SERIAL NOT NULL,
+        user_id INT NOT NULL,
+        network VARCHAR(20) NOT NULL,
+        name VARCHAR(100) NOT NULL,
+        layout jsonb NOT NULL,
+        created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (id)
+    );
+CREATE INDEX IF NOT EXISTS idx_users_dashboards_user_id ON users_dashboards (user_id, network);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_dashboards;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    sla_report_jobs (
+        id VARCHAR(40),
+        status VARCHAR(40) NOT NULL,
+        -- can be one of: PENDING, RUNNING, COMPLETED, FAILED
+        created_time TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        completed_time TIMESTAMP WITHOUT TIME ZONE,
+        validators INT[] NOT NULL,
+        month VARCHAR(7) NOT NULL,
+        -- can be one of: pdf, csv
+        format VARCHAR(10) NOT NULL,
+        result bytea,
+        error TEXT,
+        PRIMARY KEY (id)
+    );
+CREATE INDEX IF NOT EXISTS idx_sla_report_jobs_status ON sla_report_jobs (status, created_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS sla_report_jobs;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    validator_stats_distributions (
+        DAY INT NOT NULL,
+        metric VARCHAR(40) NOT NULL,
+        -- percentiles 0 to 100 of the metric over all validators that were active during the whole day
+        percentiles FLOAT[] NOT NULL,
+        PRIMARY KEY (DAY, metric)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS validator_stats_distributions;
+-- +goose StatementEnd
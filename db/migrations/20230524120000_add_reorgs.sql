@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    reorgs (
+        -- can be one of: el (replaced execution block), cl (orphaned consensus block)
+        layer VARCHAR(2) NOT NULL,
+        -- block number for el reorgs, slot for cl reorgs
+        height BIGINT NOT NULL,
+        -- hash of the replaced execution block or root of the orphaned consensus block
+        hash bytea NOT NULL,
+        canonical_hash bytea,
+        -- number of consecutive blocks that have been replaced
+        depth INT NOT NULL,
+        block_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        detected_time TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (layer, hash)
+    );
+CREATE INDEX IF NOT EXISTS idx_reorgs_block_time ON reorgs (block_time);
+CREATE INDEX IF NOT EXISTS idx_reorgs_detected_time ON reorgs (detected_time);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reorgs;
+-- +goose StatementEnd
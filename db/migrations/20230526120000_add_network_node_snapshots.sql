@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    network_node_snapshots (
+        DAY DATE NOT NULL,
+        -- time of the latest crawl of the day, each crawl replaces the snapshot of its day
+        crawled_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        node_count INT NOT NULL,
+        avg_peer_count FLOAT NOT NULL,
+        median_peer_count FLOAT NOT NULL,
+        clients jsonb NOT NULL,
+        versions jsonb NOT NULL,
+        countries jsonb NOT NULL,
+        PRIMARY KEY (DAY)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS network_node_snapshots;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    eth1_balance_history (
+        address bytea NOT NULL,
+        -- 0x00 denotes the native ether balance
+        token bytea NOT NULL,
+        block_number BIGINT NOT NULL,
+        balance NUMERIC NOT NULL,
+        PRIMARY KEY (address, token, block_number)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS eth1_balance_history;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    validator_credential_rotations (
+        validatorindex INT NOT NULL,
+        block_slot INT NOT NULL,
+        block_root bytea NOT NULL,
+        from_credentials bytea NOT NULL,
+        to_credentials bytea NOT NULL,
+        PRIMARY KEY (validatorindex, block_root)
+    );
+-- +goose StatementEnd
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_validator_credential_rotations_block_slot ON validator_credential_rotations (block_slot);
+-- +goose StatementEnd
+-- +goose StatementBegin
+INSERT INTO
+    validator_credential_rotations (validatorindex, block_slot, block_root, from_credentials, to_credentials)
+SELECT
+    validatorindex,
+    block_slot,
+    block_root,
+    '\x00'::bytea || SUBSTRING(SHA256(pubkey) FROM 2),
+    '\x010000000000000000000000'::bytea || address
+FROM
+    blocks_bls_change
+ON CONFLICT (validatorindex, block_root) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS validator_credential_rotations;
+-- +goose StatementEnd
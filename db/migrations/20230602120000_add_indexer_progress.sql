@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    indexer_progress (
+        -- name of the indexing pipeline, e.g. blocks or data
+        NAME TEXT NOT NULL,
+        start_block BIGINT NOT NULL,
+        end_block BIGINT NOT NULL,
+        current_block BIGINT NOT NULL,
+        processed_blocks BIGINT NOT NULL,
+        blocks_per_second FLOAT NOT NULL,
+        pending_mutations BIGINT NOT NULL,
+        started_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        updated_time TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (NAME)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS indexer_progress;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_address_labels (
+        user_id INT NOT NULL,
+        address bytea NOT NULL,
+        label CHARACTER VARYING(100) NOT NULL,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (user_id, address)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_address_labels;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_alert_rules (
+        id serial NOT NULL,
+        user_id INT NOT NULL,
+        network CHARACTER VARYING(20) NOT NULL,
+        NAME CHARACTER VARYING(100) NOT NULL,
+        -- tree of boolean conditions over metrics, see types.AlertCondition
+        condition jsonb NOT NULL,
+        window_epochs INT NOT NULL,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (id)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_users_alert_rules_user_id ON users_alert_rules (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_alert_rules;
+-- +goose StatementEnd
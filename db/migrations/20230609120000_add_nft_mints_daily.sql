@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    nft_mints_daily (
+        day DATE NOT NULL,
+        token_address bytea NOT NULL,
+        standard VARCHAR(10) NOT NULL,
+        mints BIGINT NOT NULL,
+        minters BIGINT NOT NULL,
+        PRIMARY KEY (day, token_address)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_nft_mints_daily_token_address ON nft_mints_daily (token_address, day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS nft_mints_daily;
+-- +goose StatementEnd
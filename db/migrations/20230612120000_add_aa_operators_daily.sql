@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    aa_operators_daily (
+        day DATE NOT NULL,
+        role VARCHAR(10) NOT NULL,
+        address bytea NOT NULL,
+        operations BIGINT NOT NULL,
+        successful BIGINT NOT NULL,
+        gas_used NUMERIC NOT NULL,
+        gas_cost NUMERIC NOT NULL,
+        PRIMARY KEY (day, role, address)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS aa_operators_daily;
+-- +goose StatementEnd
@@ -0,0 +1,47 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    api_webhooks (
+        id serial NOT NULL,
+        user_id INT NOT NULL,
+        url CHARACTER VARYING(1024) NOT NULL,
+        -- operational events delivered to the webhook, see types.ApiWebhookEvent
+        events TEXT[] NOT NULL,
+        -- lag in blocks of the execution data index from which on index_lag events are sent
+        lag_threshold INT NOT NULL,
+        last_lag_sent TIMESTAMP WITHOUT TIME ZONE,
+        -- incidents up to this id have been delivered
+        last_incident_id INT NOT NULL DEFAULT 0,
+        retries INT NOT NULL DEFAULT 0,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (id)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_api_webhooks_user_id ON api_webhooks (user_id);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    api_incidents (
+        id serial NOT NULL,
+        -- maintenance or deprecation
+        kind CHARACTER VARYING(20) NOT NULL,
+        title CHARACTER VARYING(200) NOT NULL,
+        description TEXT NOT NULL DEFAULT '',
+        start_ts TIMESTAMP WITHOUT TIME ZONE,
+        end_ts TIMESTAMP WITHOUT TIME ZONE,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (id)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_incidents;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_webhooks;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    support_audit_log (
+        id serial NOT NULL,
+        -- the support staff member that accessed the data
+        staff_user_id INT NOT NULL,
+        -- the user whose data was accessed
+        target_user_id INT NOT NULL,
+        -- subscriptions, api_usage or watchlist
+        action CHARACTER VARYING(50) NOT NULL,
+        route TEXT NOT NULL,
+        remote_addr CHARACTER VARYING(100) NOT NULL DEFAULT '',
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (id)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_support_audit_log_target_user_id ON support_audit_log (target_user_id, created_ts);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS support_audit_log;
+-- +goose StatementEnd
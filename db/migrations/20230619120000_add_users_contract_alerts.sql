@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_verified_contracts (
+        user_id INT NOT NULL,
+        network CHARACTER VARYING(20) NOT NULL,
+        contract_address bytea NOT NULL,
+        -- address that deployed the contract and signed the ownership challenge
+        deployer_address bytea NOT NULL,
+        verified_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (user_id, network, contract_address)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_contract_alerts (
+        id serial NOT NULL,
+        user_id INT NOT NULL,
+        network CHARACTER VARYING(20) NOT NULL,
+        contract_address bytea NOT NULL,
+        kind CHARACTER VARYING(20) NOT NULL,
+        method_id bytea,
+        revert_threshold INT NOT NULL DEFAULT 0,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (id)
+    );
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_users_contract_alerts_user_id ON users_contract_alerts (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_contract_alerts;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_verified_contracts;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_fee_recipients (
+        user_id INT NOT NULL,
+        network CHARACTER VARYING(20) NOT NULL,
+        -- address the validators of the user are expected to pay the fees of their proposals to
+        fee_recipient bytea NOT NULL,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        PRIMARY KEY (user_id, network, fee_recipient)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_fee_recipients;
+-- +goose StatementEnd
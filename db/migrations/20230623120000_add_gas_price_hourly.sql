@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    gas_price_hourly (
+        ts TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+        tx_count INT NOT NULL,
+        -- percentiles of the gas prices paid by the transactions of the hour in wei
+        p10 BIGINT NOT NULL,
+        p50 BIGINT NOT NULL,
+        p90 BIGINT NOT NULL,
+        PRIMARY KEY (ts)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gas_price_hourly;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_saved_filters (
+        id SERIAL NOT NULL,
+        user_id INT NOT NULL,
+        network VARCHAR(20) NOT NULL,
+        -- table the filter applies to, e.g. address_transactions or validators
+        table_name VARCHAR(40) NOT NULL,
+        name VARCHAR(100) NOT NULL,
+        -- query parameters of the table data request
+        params jsonb NOT NULL,
+        created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        updated_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (id),
+        UNIQUE (user_id, network, table_name, name)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_saved_filters;
+-- +goose StatementEnd
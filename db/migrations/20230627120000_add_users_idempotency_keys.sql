@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS
+    users_idempotency_keys (
+        user_id INT NOT NULL,
+        idempotency_key VARCHAR(100) NOT NULL,
+        -- hash of the method, path and body of the request the key was first used for
+        request_hash BYTEA NOT NULL,
+        -- data of the response that is returned again when the request is repeated
+        response jsonb NOT NULL,
+        created_ts TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (user_id, idempotency_key)
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users_idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- the raw X-Forwarded-For chain of the request, its entries except for the one appended by the trusted proxy are sent by the client
+ALTER TABLE support_audit_log ADD COLUMN IF NOT EXISTS forwarded_for TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+ALTER TABLE support_audit_log DROP COLUMN IF EXISTS forwarded_for;
+-- +goose StatementEnd
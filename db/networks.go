@@ -0,0 +1,161 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Network is a network the frontend serves execution layer pages for. The default network reads from the process wide
+// data sources, the additional networks of the frontend config have their own postgres connection and either their own
+// bigtable connection or share the one of the frontend.
+type Network struct {
+	Profile  types.NetworkProfile
+	bigtable *Bigtable
+	readerDb *sqlx.DB
+}
+
+var networks = make(map[string]*Network)
+var networksMux = &sync.RWMutex{}
+
+// DefaultNetwork is the network the frontend is configured for
+var DefaultNetwork = &Network{}
+
+// MustInitNetworks connects to the data sources of the additional networks of the frontend
+func MustInitNetworks(profiles []types.NetworkProfile) {
+	networksMux.Lock()
+	defer networksMux.Unlock()
+
+	for _, profile := range profiles {
+		if profile.Name == "" || networks[profile.Name] != nil {
+			logger.Fatalf("invalid or duplicate network name %q in the frontend networks", profile.Name)
+		}
+
+		if profile.ChainId == "" || profile.ChainId == DefaultNetwork.ChainId() {
+			logger.Fatalf("invalid chain id %q of network %v", profile.ChainId, profile.Name)
+		}
+		for _, n := range networks {
+			if n.Profile.ChainId == profile.ChainId {
+				logger.Fatalf("networks %v and %v share the chain id %v", n.Profile.Name, profile.Name, profile.ChainId)
+			}
+		}
+
+		var bt *Bigtable
+		if profile.Bigtable.Project == "" {
+			if BigtableClient == nil {
+				logger.Fatalf("network %v has no bigtable configured and the frontend bigtable is not initialized", profile.Name)
+			}
+			bt = BigtableClient.ForChain(profile.ChainId)
+		} else {
+			var err error
+			bt, err = NewBigtable(profile.Bigtable.Project, profile.Bigtable.Instance, profile.ChainId)
+			if err != nil {
+				logger.Fatalf("error connecting to the bigtable of network %v: %v", profile.Name, err)
+			}
+		}
+		reader, _ := mustInitDB(&types.DatabaseConfig{
+			Username: profile.ReaderDatabase.Username,
+			Password: profile.ReaderDatabase.Password,
+			Name:     profile.ReaderDatabase.Name,
+			Host:     profile.ReaderDatabase.Host,
+			Port:     profile.ReaderDatabase.Port,
+		}, nil)
+
+		networks[profile.Name] = &Network{Profile: profile, bigtable: bt, readerDb: reader}
+		logger.Infof("initialized data sources of network %v (chain id %v)", profile.Name, profile.ChainId)
+	}
+}
+
+// GetNetwork returns the additional network with the given name, nil if there is none
+func GetNetwork(name string) *Network {
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+	return networks[name]
+}
+
+// GetNetworkByChainId returns the network with the given execution layer chain id, nil if there is none. The default
+// network is returned for its own chain id.
+func GetNetworkByChainId(chainId string) *Network {
+	if chainId == DefaultNetwork.ChainId() {
+		return DefaultNetwork
+	}
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+	for _, n := range networks {
+		if n.Profile.ChainId == chainId {
+			return n
+		}
+	}
+	return nil
+}
+
+// GetNetworks returns the additional networks ordered by name
+func GetNetworks() []*Network {
+	networksMux.RLock()
+	defer networksMux.RUnlock()
+
+	list := make([]*Network, 0, len(networks))
+	for _, n := range networks {
+		list = append(list, n)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Profile.Name < list[j].Profile.Name })
+	return list
+}
+
+// IsDefault returns true for the network the frontend is configured for
+func (n *Network) IsDefault() bool {
+	return n.bigtable == nil
+}
+
+// ChainId returns the execution layer chain id of the network
+func (n *Network) ChainId() string {
+	if n.IsDefault() {
+		return fmt.Sprintf("%d", utils.Config.Chain.Config.DepositChainID)
+	}
+	return n.Profile.ChainId
+}
+
+// Bigtable returns the bigtable instance keyed by the chain id of the network
+func (n *Network) Bigtable() *Bigtable {
+	if n.IsDefault() {
+		return BigtableClient
+	}
+	return n.bigtable
+}
+
+// Eth1Store returns the execution index of the network
+func (n *Network) Eth1Store() Eth1Store {
+	if n.IsDefault() {
+		return GetEth1Store()
+	}
+	return n.bigtable
+}
+
+func (n *Network) reader() *sqlx.DB {
+	if n.IsDefault() {
+		return ReaderDb
+	}
+	return n.readerDb
+}
+
+// GetAddressWithdrawals returns the withdrawals of an address on the network
+func (n *Network) GetAddressWithdrawals(address []byte, limit uint64, offset uint64) ([]*types.Withdrawals, error) {
+	withdrawals, err := getAddressWithdrawals(n.reader(), address, limit, offset)
+	if err != nil && !n.IsDefault() {
+		return nil, fmt.Errorf("network %v: %w", n.Profile.Name, err)
+	}
+	return withdrawals, err
+}
+
+// GetAddressWithdrawalsTotal returns the total withdrawals of an address on the network
+func (n *Network) GetAddressWithdrawalsTotal(address []byte) (uint64, error) {
+	total, err := getAddressWithdrawalsTotal(n.reader(), address)
+	if err != nil && !n.IsDefault() {
+		return 0, fmt.Errorf("network %v: %w", n.Profile.Name, err)
+	}
+	return total, err
+}
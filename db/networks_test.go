@@ -0,0 +1,36 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"testing"
+)
+
+func TestGetNetworkByChainId(t *testing.T) {
+	utils.Config = &types.Config{}
+	utils.Config.Chain.Config.DepositChainID = 1
+
+	shared := (&Bigtable{chainId: "1"}).ForChain("100")
+	networksMux.Lock()
+	networks = map[string]*Network{"gnosis": {Profile: types.NetworkProfile{Name: "gnosis", ChainId: "100"}, bigtable: shared}}
+	networksMux.Unlock()
+	defer func() {
+		networksMux.Lock()
+		networks = make(map[string]*Network)
+		networksMux.Unlock()
+	}()
+
+	if n := GetNetworkByChainId("1"); n != DefaultNetwork {
+		t.Errorf("expected the default network for its own chain id, got %+v", n)
+	}
+	n := GetNetworkByChainId("100")
+	if n == nil || n.Profile.Name != "gnosis" {
+		t.Fatalf("expected the gnosis network, got %+v", n)
+	}
+	if n.ChainId() != "100" || n.Bigtable().ChainId() != "100" {
+		t.Errorf("expected the network to read the rows of chain 100, got %v and %v", n.ChainId(), n.Bigtable().ChainId())
+	}
+	if GetNetworkByChainId("5") != nil {
+		t.Errorf("expected no network for an unknown chain id")
+	}
+}
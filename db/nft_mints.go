@@ -0,0 +1,73 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// SaveNFTCollectionMints replaces the mint statistics of all collections for a day
+func SaveNFTCollectionMints(day time.Time, collections []*types.NFTCollectionMints) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM nft_mints_daily WHERE day = $1`, day)
+	if err != nil {
+		return fmt.Errorf("error deleting nft mints of day %v: %w", day, err)
+	}
+
+	addresses := make(pq.ByteaArray, 0, len(collections))
+	standards := make(pq.StringArray, 0, len(collections))
+	mints := make(pq.Int64Array, 0, len(collections))
+	minters := make(pq.Int64Array, 0, len(collections))
+	for _, c := range collections {
+		addresses = append(addresses, c.TokenAddress)
+		standards = append(standards, c.Standard)
+		mints = append(mints, int64(c.Mints))
+		minters = append(minters, int64(c.Minters))
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO nft_mints_daily (day, token_address, standard, mints, minters)
+		SELECT $1, UNNEST($2::bytea[]), UNNEST($3::text[]), UNNEST($4::bigint[]), UNNEST($5::bigint[])`,
+		day, addresses, standards, mints, minters)
+	if err != nil {
+		return fmt.Errorf("error saving nft mints of day %v: %w", day, err)
+	}
+
+	return tx.Commit()
+}
+
+// GetTopNFTCollectionMints returns the collections with the most mints on the latest exported day
+func GetTopNFTCollectionMints(limit uint64) ([]*types.NFTCollectionMints, error) {
+	collections := []*types.NFTCollectionMints{}
+	err := ReaderDb.Select(&collections, `
+		SELECT day, token_address, standard, mints, minters
+		FROM nft_mints_daily
+		WHERE day = (SELECT MAX(day) FROM nft_mints_daily)
+		ORDER BY mints DESC, minters DESC
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting top nft collection mints: %w", err)
+	}
+	return collections, nil
+}
+
+// GetNFTCollectionMintHistory returns the daily mint statistics of a collection for the last days
+func GetNFTCollectionMintHistory(token []byte, days uint64) ([]*types.NFTCollectionMints, error) {
+	history := []*types.NFTCollectionMints{}
+	err := ReaderDb.Select(&history, `
+		SELECT day, token_address, standard, mints, minters
+		FROM nft_mints_daily
+		WHERE token_address = $1 AND day > NOW() - $2::INT * INTERVAL '1 day'
+		ORDER BY day`, token, days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting mint history of collection 0x%x: %w", token, err)
+	}
+	return history, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+)
+
+// SaveNetworkNodeSnapshot stores the snapshot of a crawl, it replaces an earlier snapshot of the same day
+func SaveNetworkNodeSnapshot(snapshot *types.NetworkNodeSnapshot) error {
+	_, err := WriterDb.Exec(`
+		INSERT INTO network_node_snapshots (day, crawled_time, node_count, avg_peer_count, median_peer_count, clients, versions, countries)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (day) DO UPDATE SET
+			crawled_time = excluded.crawled_time,
+			node_count = excluded.node_count,
+			avg_peer_count = excluded.avg_peer_count,
+			median_peer_count = excluded.median_peer_count,
+			clients = excluded.clients,
+			versions = excluded.versions,
+			countries = excluded.countries
+		WHERE network_node_snapshots.crawled_time <= excluded.crawled_time`,
+		snapshot.Day, snapshot.CrawledTime, snapshot.NodeCount, snapshot.AvgPeerCount, snapshot.MedianPeerCount, snapshot.Clients, snapshot.Versions, snapshot.Countries)
+	if err != nil {
+		return fmt.Errorf("error saving network node snapshot of day %v: %w", snapshot.Day.Format("2006-01-02"), err)
+	}
+	return nil
+}
+
+// GetNetworkNodeSnapshots returns the daily network node snapshots of the last days in ascending order
+func GetNetworkNodeSnapshots(days uint64) ([]*types.NetworkNodeSnapshot, error) {
+	snapshots := []*types.NetworkNodeSnapshot{}
+	err := ReaderDb.Select(&snapshots, `
+		SELECT day, crawled_time, node_count, avg_peer_count, median_peer_count, clients, versions, countries
+		FROM network_node_snapshots
+		WHERE day > CURRENT_DATE - $1::INT
+		ORDER BY day`, days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting network node snapshots: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mr-tron/base58/base58"
+)
+
+// decoded page tokens are suffixes of index row keys, the longest of them are well below this length
+const maxPageTokenLength = 256
+
+// EncodePageToken returns the public page token for the last key of a page. Only the part of the key following the
+// prefix of the index is encoded, an empty token is returned if there is no next page.
+func EncodePageToken(prefix, lastKey string) string {
+	if lastKey == "" || !strings.HasPrefix(lastKey, prefix) {
+		return ""
+	}
+	return base58.FastBase58Encoding([]byte(strings.TrimPrefix(lastKey, prefix)))
+}
+
+// DecodePageToken returns the row key a page token continues the index of prefix from.
+// Index keys only consist of alphanumerics and separators, anything else is rejected so that a crafted token
+// can not move the row range outside of the index.
+func DecodePageToken(prefix, token string) (string, error) {
+	decoded, err := base58.FastBase58Decoding(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid page token: %w", err)
+	}
+	if len(decoded) == 0 || len(decoded) > maxPageTokenLength {
+		return "", fmt.Errorf("invalid page token length %v", len(decoded))
+	}
+	for _, c := range decoded {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == ':') {
+			return "", fmt.Errorf("invalid character %q in page token", c)
+		}
+	}
+	return prefix + string(decoded), nil
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+)
+
+// maximum number of ancestors that are followed when calculating the depth of an orphaned branch
+const maxOrphanedBranchDepth = 64
+
+// SaveReorg persists a replaced block, a reorg is only stored once per layer and hash
+func SaveReorg(reorg *types.Reorg) error {
+	_, err := WriterDb.Exec(`
+		INSERT INTO reorgs (layer, height, hash, canonical_hash, depth, block_time, detected_time)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (layer, hash) DO NOTHING`,
+		reorg.Layer, reorg.Height, reorg.Hash, reorg.CanonicalHash, reorg.Depth, reorg.BlockTime)
+	if err != nil {
+		return fmt.Errorf("error saving %v reorg at height %v: %w", reorg.Layer, reorg.Height, err)
+	}
+	return nil
+}
+
+// SaveOrphanedBlockReorgs records all orphaned consensus blocks of the epochs [startEpoch, endEpoch] and removes
+// previously recorded blocks of these epochs that have become canonical again
+func SaveOrphanedBlockReorgs(startEpoch, endEpoch uint64) error {
+	tx, err := WriterDb.Beginx()
+	if err != nil {
+		return fmt.Errorf("error starting db transactions: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM reorgs r
+		USING blocks b
+		WHERE r.layer = $1 AND r.hash = b.blockroot AND b.status != '3' AND b.epoch >= $2 AND b.epoch <= $3`,
+		types.ConsensusLayerReorg, startEpoch, endEpoch)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		WITH RECURSIVE branch AS (
+			SELECT slot, blockroot, parentroot, blockroot AS orphaned, 1 AS depth
+			FROM blocks
+			WHERE status = '3' AND epoch >= $2 AND epoch <= $3
+			UNION ALL
+			SELECT p.slot, p.blockroot, p.parentroot, branch.orphaned, branch.depth + 1
+			FROM blocks p
+			INNER JOIN branch ON p.blockroot = branch.parentroot
+			WHERE p.status = '3' AND branch.depth < $4
+		)
+		INSERT INTO reorgs (layer, height, hash, depth, block_time, detected_time)
+		SELECT $1, b.slot, b.blockroot, MAX(branch.depth), TO_TIMESTAMP($5 + b.slot * $6) AT TIME ZONE 'UTC', NOW()
+		FROM branch
+		INNER JOIN blocks b ON b.blockroot = branch.orphaned
+		GROUP BY b.slot, b.blockroot
+		ON CONFLICT (layer, hash) DO NOTHING`,
+		types.ConsensusLayerReorg, startEpoch, endEpoch, maxOrphanedBranchDepth, utils.Config.Chain.GenesisTimestamp, utils.Config.Chain.Config.SecondsPerSlot)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetReorgs returns the most recent reorgs of a layer, all layers are returned if layer is empty
+func GetReorgs(layer types.ReorgLayer, limit uint64) ([]*types.Reorg, error) {
+	reorgs := []*types.Reorg{}
+	err := ReaderDb.Select(&reorgs, `
+		SELECT layer, height, hash, canonical_hash, depth, block_time, detected_time
+		FROM reorgs
+		WHERE $1 = '' OR layer = $1
+		ORDER BY block_time DESC
+		LIMIT $2`, layer, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reorgs: %w", err)
+	}
+	return reorgs, nil
+}
+
+// GetReorgsDetectedSince returns all reorgs with at least the given depth that have been detected after since
+func GetReorgsDetectedSince(since time.Time, minDepth uint64) ([]*types.Reorg, error) {
+	reorgs := []*types.Reorg{}
+	err := ReaderDb.Select(&reorgs, `
+		SELECT layer, height, hash, canonical_hash, depth, block_time, detected_time
+		FROM reorgs
+		WHERE detected_time > $1 AND depth >= $2
+		ORDER BY detected_time`, since, minDepth)
+	if err != nil {
+		return nil, fmt.Errorf("error getting reorgs detected since %v: %w", since, err)
+	}
+	return reorgs, nil
+}
+
+// GetDailyReorgCounts returns the number of reorgs per day of a layer for the last days
+func GetDailyReorgCounts(layer types.ReorgLayer, days uint64) ([]*types.ChartDataPoint, error) {
+	rows := []struct {
+		Day   time.Time `db:"day"`
+		Count uint64    `db:"count"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT DATE_TRUNC('day', block_time) AS day, COUNT(*) AS count
+		FROM reorgs
+		WHERE layer = $1 AND block_time > NOW() - $2::INT * INTERVAL '1 day'
+		GROUP BY day
+		ORDER BY day`, layer, days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily %v reorg counts: %w", layer, err)
+	}
+
+	series := make([]*types.ChartDataPoint, 0, len(rows))
+	for _, row := range rows {
+		series = append(series, &types.ChartDataPoint{X: float64(row.Day.Unix() * 1000), Y: float64(row.Count)})
+	}
+	return series, nil
+}
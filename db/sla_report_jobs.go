@@ -0,0 +1,132 @@
+package db
+
+import (
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+)
+
+func CreateSlaReportJob(validators []uint64, month string, format types.SlaReportFormat) (*types.SlaReportJob, error) {
+	job := &types.SlaReportJob{
+		ID:         uuid.New().String(),
+		Status:     types.PendingSlaReportJobStatus,
+		Validators: make(pq.Int64Array, 0, len(validators)),
+		Month:      month,
+		Format:     format,
+	}
+	for _, validator := range validators {
+		job.Validators = append(job.Validators, int64(validator))
+	}
+	err := WriterDb.Get(&job.CreatedTime, `
+		insert into sla_report_jobs (id, status, validators, month, format, created_time)
+		values ($1, $2, $3, $4, $5, now())
+		returning created_time`,
+		job.ID, job.Status, job.Validators, job.Month, job.Format)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting into sla_report_jobs: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{"id": job.ID, "validators": len(job.Validators), "month": job.Month, "format": job.Format}).Infof("created sla_report_job")
+	return job, nil
+}
+
+func GetSlaReportJob(id string) (*types.SlaReportJob, error) {
+	if len(id) > 40 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	job := types.SlaReportJob{}
+	err := WriterDb.Get(&job, `select id, status, created_time, completed_time, validators, month, format, result, error from sla_report_jobs where id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimPendingSlaReportJob marks the oldest pending job as running and returns it, it returns nil if there is no pending job
+func ClaimPendingSlaReportJob() (*types.SlaReportJob, error) {
+	jobs := []*types.SlaReportJob{}
+	err := WriterDb.Select(&jobs, `
+		update sla_report_jobs set status = $1
+		where id = (select id from sla_report_jobs where status = $2 order by created_time limit 1 for update skip locked)
+		returning id, status, created_time, completed_time, validators, month, format, result, error`,
+		types.RunningSlaReportJobStatus, types.PendingSlaReportJobStatus)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+func CompleteSlaReportJob(id string, result []byte) error {
+	_, err := WriterDb.Exec(`update sla_report_jobs set status = $1, result = $2, completed_time = now() where id = $3`, types.CompletedSlaReportJobStatus, result, id)
+	return err
+}
+
+func FailSlaReportJob(id string, jobErr error) error {
+	_, err := WriterDb.Exec(`update sla_report_jobs set status = $1, error = $2, completed_time = now() where id = $3`, types.FailedSlaReportJobStatus, jobErr.Error(), id)
+	return err
+}
+
+// GetSlaReportValidators aggregates the daily validator statistics of the days [startDay, endDay] for the given validators
+func GetSlaReportValidators(validators []uint64, startDay, endDay uint64) ([]*types.SlaReportValidator, error) {
+	data := []*types.SlaReportValidator{}
+	err := ReaderDb.Select(&data, `
+		SELECT
+			v.validatorindex,
+			v.pubkey,
+			v.activationepoch,
+			v.exitepoch,
+			COALESCE(SUM(s.missed_attestations), 0) AS missed_attestations,
+			COALESCE(SUM(s.proposed_blocks), 0) AS proposed_blocks,
+			COALESCE(SUM(s.missed_blocks), 0) AS missed_blocks,
+			COALESCE(SUM(s.orphaned_blocks), 0) AS orphaned_blocks,
+			COALESCE(SUM(s.participated_sync), 0) AS participated_sync,
+			COALESCE(SUM(s.missed_sync), 0) AS missed_sync
+		FROM validators v
+		LEFT JOIN validator_stats s ON s.validatorindex = v.validatorindex AND s.day >= $2 AND s.day <= $3
+		WHERE v.validatorindex = ANY($1)
+		GROUP BY v.validatorindex, v.pubkey, v.activationepoch, v.exitepoch
+		ORDER BY v.validatorindex`, pq.Array(validators), startDay, endDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sla report statistics of validators: %w", err)
+	}
+	return data, nil
+}
+
+// GetSlashingsOfValidators returns the proposer and attester slashings of the given validators that were included in canonical blocks of the epochs [startEpoch, endEpoch)
+func GetSlashingsOfValidators(validators []uint64, startEpoch, endEpoch uint64) ([]*types.SlaReportSlashing, error) {
+	slashings := []*types.SlaReportSlashing{}
+	err := ReaderDb.Select(&slashings, `
+		SELECT validatorindex, slot, reason FROM (
+			SELECT
+				blocks.slot,
+				UNNEST(ARRAY(
+					SELECT UNNEST(attestation1_indices)
+						INTERSECT
+					SELECT UNNEST(attestation2_indices)
+				)) AS validatorindex,
+				'Attestation Violation' AS reason
+			FROM blocks_attesterslashings
+			INNER JOIN blocks ON blocks_attesterslashings.block_slot = blocks.slot
+			WHERE blocks.status = '1' AND blocks.epoch >= $2 AND blocks.epoch < $3
+			UNION ALL
+			SELECT
+				blocks.slot,
+				blocks_proposerslashings.proposerindex AS validatorindex,
+				'Proposer Violation' AS reason
+			FROM blocks_proposerslashings
+			INNER JOIN blocks ON blocks_proposerslashings.block_slot = blocks.slot
+			WHERE blocks.status = '1' AND blocks.epoch >= $2 AND blocks.epoch < $3
+		) a
+		WHERE validatorindex = ANY($1)
+		ORDER BY slot`, pq.Array(validators), startEpoch, endEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error getting slashings of validators: %w", err)
+	}
+	return slashings, nil
+}
@@ -1,6 +1,9 @@
 package db
 
 import (
+	"bytes"
+	"context"
+	"eth2-exporter/erc1155"
 	"eth2-exporter/metrics"
 	"eth2-exporter/price"
 	"eth2-exporter/types"
@@ -45,7 +48,7 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 	}
 	defer tx.Rollback()
 	logger.Infof("exporting missed_attestations statistics lastEpoch: %v firstEpoch: %v", lastEpoch, firstEpoch)
-	ma, err := BigtableClient.GetValidatorMissedAttestationsCount([]uint64{}, firstEpoch, lastEpoch)
+	ma, err := BigtableClient.GetValidatorMissedAttestationsCount(context.Background(), []uint64{}, firstEpoch, lastEpoch)
 	if err != nil {
 		return err
 	}
@@ -153,7 +156,7 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 
 	start = time.Now()
 	logger.Infof("exporting el_rewards_wei statistics")
-	incomeStats, err := BigtableClient.GetAggregatedValidatorIncomeDetailsHistory([]uint64{}, firstEpoch, lastEpoch)
+	incomeStats, err := BigtableClient.GetAggregatedValidatorIncomeDetailsHistory(context.Background(), []uint64{}, firstEpoch, lastEpoch)
 	if err != nil {
 		return err
 	}
@@ -257,7 +260,7 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 		blocksMap[b.ExecBlockNumber] = b
 	}
 
-	blocksData, err := BigtableClient.GetBlocksIndexedMultiple(numbers, uint64(len(numbers)))
+	blocksData, err := BigtableClient.GetBlocksIndexedMultiple(context.Background(), numbers, uint64(len(numbers)))
 	if err != nil {
 		return fmt.Errorf("error in GetBlocksIndexedMultiple: %v", err)
 	}
@@ -339,7 +342,7 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 	}
 
 	logger.Infof("exporting min_balance, max_balance, min_effective_balance, max_effective_balance, start_balance, start_effective_balance, end_balance and end_effective_balance statistics")
-	balanceStatistics, err := BigtableClient.GetValidatorBalanceStatistics(firstEpoch, lastEpoch)
+	balanceStatistics, err := BigtableClient.GetValidatorBalanceStatistics(context.Background(), firstEpoch, lastEpoch)
 	if err != nil {
 		return err
 	}
@@ -506,7 +509,7 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 	start = time.Now()
 
 	logger.Infof("exporting sync statistics")
-	syncStats, err := BigtableClient.GetValidatorSyncDutiesStatistics([]uint64{}, firstEpoch, lastEpoch) //+1 is needed because the function uses limit instead of end epoch
+	syncStats, err := BigtableClient.GetValidatorSyncDutiesStatistics(context.Background(), []uint64{}, firstEpoch, lastEpoch) //+1 is needed because the function uses limit instead of end epoch
 	if err != nil {
 		return err
 	}
@@ -582,6 +585,26 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 	}
 	logger.Infof("export completed, took %v", time.Since(start))
 
+	start = time.Now()
+	logger.Infof("exporting network distributions of the validator statistics")
+	for _, metric := range []string{types.ValidatorStatsMetricClRewards, types.ValidatorStatsMetricAttestationParticipation} {
+		_, err = tx.Exec(fmt.Sprintf(`
+			INSERT INTO validator_stats_distributions (day, metric, percentiles)
+			(
+				SELECT $1, $4, percentile_cont(ARRAY(SELECT generate_series(0, 100) / 100.0)::FLOAT[]) WITHIN GROUP (ORDER BY %s)
+				FROM validator_stats vs
+				INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+				WHERE vs.day = $1 AND v.activationepoch <= $2 AND v.exitepoch > $3
+				HAVING COUNT(*) > 0
+			)
+			ON CONFLICT (day, metric) DO UPDATE SET percentiles = excluded.percentiles;`, validatorStatsMetricExpression(metric, epochsPerDay)),
+			day, firstEpoch, lastEpoch, metric)
+		if err != nil {
+			return err
+		}
+	}
+	logger.Infof("export completed, took %v", time.Since(start))
+
 	start = time.Now()
 	logger.Infof("marking day export as completed in the status table")
 	_, err = tx.Exec("insert into validator_stats_status (day, status, income_exported) values ($1, true, true) ON CONFLICT (day) DO UPDATE SET status=EXCLUDED.status, income_exported=EXCLUDED.income_exported;", day)
@@ -599,8 +622,97 @@ func WriteValidatorStatisticsForDay(day uint64) error {
 	return nil
 }
 
-func GetValidatorIncomeHistoryChart(validator_indices []uint64, currency string) ([]*types.ChartDataPoint, int64, error) {
-	incomeHistory, currentDayIncome, err := GetValidatorIncomeHistory(validator_indices, 0, 0)
+// validatorStatsMetricExpression returns the sql expression calculating a benchmark metric from a validator_stats row aliased as vs
+func validatorStatsMetricExpression(metric string, epochsPerDay uint64) string {
+	switch metric {
+	case types.ValidatorStatsMetricAttestationParticipation:
+		return fmt.Sprintf("GREATEST(1 - COALESCE(vs.missed_attestations, 0)::FLOAT / %d, 0)", epochsPerDay)
+	default:
+		return "COALESCE(vs.cl_rewards_gwei, 0)::FLOAT"
+	}
+}
+
+// GetValidatorStatsDistributions returns the network wide percentiles of a metric for the days [fromDay, toDay]
+func GetValidatorStatsDistributions(metric string, fromDay, toDay uint64) (map[uint64][]float64, error) {
+	rows := []struct {
+		Day         uint64          `db:"day"`
+		Percentiles pq.Float64Array `db:"percentiles"`
+	}{}
+	err := ReaderDb.Select(&rows, `SELECT day, percentiles FROM validator_stats_distributions WHERE metric = $1 AND day BETWEEN $2 AND $3`, metric, fromDay, toDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %v distributions: %w", metric, err)
+	}
+
+	distributions := make(map[uint64][]float64, len(rows))
+	for _, row := range rows {
+		distributions[row.Day] = row.Percentiles
+	}
+	return distributions, nil
+}
+
+// GetValidatorSetDailyAverage returns the average of a metric over all validators of the set that were active during the whole day for the days [fromDay, toDay]
+func GetValidatorSetDailyAverage(validators []uint64, metric string, fromDay, toDay uint64) (map[uint64]float64, error) {
+	epochsPerDay := utils.EpochsPerDay()
+	rows := []struct {
+		Day   uint64  `db:"day"`
+		Value float64 `db:"value"`
+	}{}
+	err := ReaderDb.Select(&rows, fmt.Sprintf(`
+		SELECT vs.day, AVG(%s) AS value
+		FROM validator_stats vs
+		INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+		WHERE vs.validatorindex = ANY($1) AND vs.day BETWEEN $2 AND $3 AND v.activationepoch <= vs.day * $4 AND v.exitepoch > (vs.day + 1) * $4 - 1
+		GROUP BY vs.day`, validatorStatsMetricExpression(metric, epochsPerDay)),
+		pq.Array(validators), fromDay, toDay, epochsPerDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily %v of validators: %w", metric, err)
+	}
+
+	averages := make(map[uint64]float64, len(rows))
+	for _, row := range rows {
+		averages[row.Day] = row.Value
+	}
+	return averages, nil
+}
+
+// GetValidatorsDailyAttestations returns the attestation results of the validators for the exported days [fromDay, toDay], ordered
+// by day descending and validator index. The assigned attestations are the epochs of the day the validator was active in.
+func GetValidatorsDailyAttestations(validators []uint64, fromDay, toDay uint64) ([]*types.ApiValidatorDailyAttestationsResponse, error) {
+	epochsPerDay := utils.EpochsPerDay()
+	rows := []*types.ApiValidatorDailyAttestationsResponse{}
+	err := ReaderDb.Select(&rows, `
+		SELECT
+			vs.validatorindex,
+			vs.day,
+			GREATEST(LEAST(v.exitepoch, (vs.day + 1) * $4) - GREATEST(v.activationepoch, vs.day * $4), 0) AS assigned_attestations,
+			COALESCE(vs.missed_attestations, 0) AS missed_attestations,
+			COALESCE(vs.orphaned_attestations, 0) AS orphaned_attestations
+		FROM validator_stats vs
+		INNER JOIN validators v ON v.validatorindex = vs.validatorindex
+		INNER JOIN validator_stats_status s ON s.day = vs.day AND s.status
+		WHERE vs.validatorindex = ANY($1) AND vs.day BETWEEN $2 AND $3
+		ORDER BY vs.day DESC, vs.validatorindex`,
+		pq.Array(validators), fromDay, toDay, epochsPerDay)
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily attestations of validators: %w", err)
+	}
+
+	for _, row := range rows {
+		row.DayStart = utils.DayToTime(int64(row.Day))
+		row.DayEnd = utils.DayToTime(int64(row.Day) + 1)
+		executed := int64(row.AssignedAttestations) - int64(row.MissedAttestations) - int64(row.OrphanedAttestations)
+		if executed > 0 {
+			row.ExecutedAttestations = uint64(executed)
+		}
+		if row.AssignedAttestations > 0 {
+			row.ParticipationRate = float64(row.ExecutedAttestations) / float64(row.AssignedAttestations)
+		}
+	}
+	return rows, nil
+}
+
+func GetValidatorIncomeHistoryChart(ctx context.Context, validator_indices []uint64, currency string) ([]*types.ChartDataPoint, int64, error) {
+	incomeHistory, currentDayIncome, err := GetValidatorIncomeHistory(ctx, validator_indices, 0, 0)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -617,7 +729,7 @@ func GetValidatorIncomeHistoryChart(validator_indices []uint64, currency string)
 	return clRewardsSeries, currentDayIncome, err
 }
 
-func GetValidatorIncomeHistory(validator_indices []uint64, lowerBoundDay uint64, upperBoundDay uint64) ([]types.ValidatorIncomeHistory, int64, error) {
+func GetValidatorIncomeHistory(ctx context.Context, validator_indices []uint64, lowerBoundDay uint64, upperBoundDay uint64) ([]types.ValidatorIncomeHistory, int64, error) {
 	if upperBoundDay == 0 {
 		upperBoundDay = 65536
 	}
@@ -654,7 +766,7 @@ func GetValidatorIncomeHistory(validator_indices []uint64, lowerBoundDay uint64,
 		currentDay := uint64(lastDay + 1)
 		startEpoch := currentDay * utils.EpochsPerDay()
 		endEpoch := startEpoch + utils.EpochsPerDay() - 1
-		income, err := BigtableClient.GetValidatorIncomeDetailsHistory(validator_indices, startEpoch, endEpoch)
+		income, err := BigtableClient.GetValidatorIncomeDetailsHistory(ctx, validator_indices, startEpoch, endEpoch)
 
 		if err != nil {
 			return nil, 0, err
@@ -734,7 +846,7 @@ func WriteChartSeriesForDay(day int64) error {
 				low = int64(firstBlock - 1)
 			}
 
-			err := BigtableClient.GetFullBlocksDescending(stream, uint64(high), uint64(low))
+			err := GetEth1Store().GetFullBlocksDescending(context.Background(), stream, uint64(high), uint64(low))
 			if err != nil {
 				logger.Errorf("error getting blocks descending high: %v low: %v err: %v", high, low, err)
 			}
@@ -758,6 +870,7 @@ func WriteChartSeriesForDay(day int64) error {
 	legacyTxCount := int64(0)
 	accessListTxCount := int64(0)
 	eip1559TxCount := int64(0)
+	blobTxCount := int64(0)
 	failedTxCount := int64(0)
 	successTxCount := int64(0)
 
@@ -769,12 +882,59 @@ func WriteChartSeriesForDay(day int64) error {
 	totalGasLimit := decimal.NewFromInt(0)
 	totalTips := decimal.NewFromInt(0)
 
+	// block space composition, the calldata gas only covers the intrinsic data costs of the transactions
+	totalCalldataBytes := int64(0)
+	totalCalldataGas := decimal.NewFromInt(0)
+
 	// totalSize := decimal.NewFromInt(0)
 
 	// blockCount := len(blocks)
 
 	// missedBlockCount := (firstSlot - uint64(lastSlot)) - uint64(blockCount)
 
+	// minted NFTs per standard and collection, the recipients are tracked to count the distinct minters of a collection
+	nftFilterer, err := erc1155.NewErc1155Filterer(common.Address{}, nil)
+	if err != nil {
+		return fmt.Errorf("error creating erc1155 filterer: %w", err)
+	}
+	nftMintCounts := map[string]int64{
+		types.NFTMintsERC721Indicator:  0,
+		types.NFTMintsERC1155Indicator: 0,
+	}
+	nftCollectionMints := map[string]*types.NFTCollectionMints{}
+	nftCollectionMinters := map[string]map[string]bool{}
+
+	// deployed contracts per kind of deployment, the deployers of factory deployments are tracked to count the distinct factories
+	contractDeploymentCounts := map[string]int64{
+		types.ContractsDeployedTxIndicator:      0,
+		types.ContractsDeployedCreateIndicator:  0,
+		types.ContractsDeployedCreate2Indicator: 0,
+	}
+	contractFactories := map[string]bool{}
+
+	// ERC-4337 user operations per bundler and paymaster, operations without paymaster are paid by the account itself
+	aaUserOpCounts := map[string]int64{
+		types.AAUserOpsSponsoredIndicator: 0,
+		types.AAUserOpsSelfPaidIndicator:  0,
+	}
+	aaOperatorStats := map[string]*types.AAOperatorStats{}
+	addAAOperation := func(role string, address []byte, op *types.UserOperation) {
+		key := role + string(address)
+		if aaOperatorStats[key] == nil {
+			aaOperatorStats[key] = &types.AAOperatorStats{Day: dateTrunc, Role: role, Address: address}
+		}
+		stats := aaOperatorStats[key]
+		stats.Operations++
+		if op.Success {
+			stats.Successful++
+		}
+		stats.GasUsed = stats.GasUsed.Add(decimal.NewFromBigInt(op.ActualGasUsed, 0))
+		stats.GasCost = stats.GasCost.Add(decimal.NewFromBigInt(op.ActualGasCost, 0))
+	}
+
+	// gas prices paid per hour of the day, rolled up into the hourly percentiles
+	gasPricesByHour := map[int][]uint64{}
+
 	var prevBlock *types.Eth1Block
 
 	accumulatedBlockTime := decimal.NewFromInt(0)
@@ -792,7 +952,48 @@ func WriteChartSeriesForDay(day int64) error {
 
 		totalBaseBlockReward = totalBaseBlockReward.Add(decimal.NewFromBigInt(utils.Eth1BlockReward(blk.Number, blk.Difficulty), 0))
 
-		for _, tx := range blk.Transactions {
+		blockHour := blk.Time.AsTime().UTC().Hour()
+		for i, tx := range blk.Transactions {
+			gasPricesByHour[blockHour] = append(gasPricesByHour[blockHour], gasPriceOfTransaction(tx))
+
+			for _, mint := range nftMintsOfTransaction(nftFilterer, blk, i, tx) {
+				if mint.Standard == NFTStandardERC721 {
+					nftMintCounts[types.NFTMintsERC721Indicator]++
+				} else {
+					nftMintCounts[types.NFTMintsERC1155Indicator]++
+				}
+				collection := string(mint.TokenAddress)
+				if nftCollectionMints[collection] == nil {
+					nftCollectionMints[collection] = &types.NFTCollectionMints{Day: dateTrunc, TokenAddress: mint.TokenAddress, Standard: mint.Standard}
+					nftCollectionMinters[collection] = map[string]bool{}
+				}
+				nftCollectionMints[collection].Mints++
+				nftCollectionMinters[collection][string(mint.To)] = true
+			}
+
+			for _, creation := range contractCreationsOfTransaction(blk, tx) {
+				switch creation.Type {
+				case types.ContractCreationTx:
+					contractDeploymentCounts[types.ContractsDeployedTxIndicator]++
+				case types.ContractCreationCreate:
+					contractDeploymentCounts[types.ContractsDeployedCreateIndicator]++
+					contractFactories[string(creation.From)] = true
+				case types.ContractCreationCreate2:
+					contractDeploymentCounts[types.ContractsDeployedCreate2Indicator]++
+					contractFactories[string(creation.From)] = true
+				}
+			}
+
+			for _, op := range userOperationsOfTransaction(tx) {
+				addAAOperation(types.AAOperatorBundler, op.Bundler, op)
+				if bytes.Equal(op.Paymaster, ZERO_ADDRESS) {
+					aaUserOpCounts[types.AAUserOpsSelfPaidIndicator]++
+				} else {
+					aaUserOpCounts[types.AAUserOpsSponsoredIndicator]++
+					addAAOperation(types.AAOperatorPaymaster, op.Paymaster, op)
+				}
+			}
+
 			// for _, itx := range tx.Itx {
 			// }
 			// blk.Time
@@ -817,10 +1018,14 @@ func WriteChartSeriesForDay(day int64) error {
 				txFees = gasUsed.Mul(gasPrice)
 				tipFee = gasPrice.Sub(baseFee)
 
-			case 2:
+			case 2, 3:
 				// priority fee is capped because the base fee is filled first
 				tipFee = decimal.Min(prioFee, maxFee.Sub(baseFee))
-				eip1559TxCount += 1
+				if tx.Type == 3 {
+					blobTxCount += 1
+				} else {
+					eip1559TxCount += 1
+				}
 				// totalMinerTips = totalMinerTips.Add(tipFee.Mul(gasUsed))
 				txFees = baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))
 				totalTxSavings = totalTxSavings.Add(maxFee.Mul(gasUsed).Sub(baseFee.Mul(gasUsed).Add(tipFee.Mul(gasUsed))))
@@ -842,6 +1047,8 @@ func WriteChartSeriesForDay(day int64) error {
 			}
 			totalGasUsed = totalGasUsed.Add(gasUsed)
 			totalBurned = totalBurned.Add(baseFee.Mul(gasUsed))
+			totalCalldataBytes += int64(len(tx.Data))
+			totalCalldataGas = totalCalldataGas.Add(decimal.NewFromBigInt(new(big.Int).SetUint64(utils.CalldataGas(tx.Data)), 0))
 			if blk.Number < 12244000 {
 				totalTips = totalTips.Add(gasUsed.Mul(gasPrice))
 			} else {
@@ -953,6 +1160,97 @@ func WriteChartSeriesForDay(day int64) error {
 		return fmt.Errorf("error calculating TX_COUNT chart_series: %w", err)
 	}
 
+	if blockCount > 0 {
+		logger.Infof("Exporting AVG_PAYLOAD_SIZE %v", totalCalldataBytes/blockCount)
+		err = SaveChartSeriesPoint(dateTrunc, "AVG_PAYLOAD_SIZE", totalCalldataBytes/blockCount)
+		if err != nil {
+			return fmt.Errorf("error calculating AVG_PAYLOAD_SIZE chart_series: %w", err)
+		}
+	}
+
+	logger.Infof("Exporting CALLDATA_GAS %v", totalCalldataGas.String())
+	err = SaveChartSeriesPoint(dateTrunc, "CALLDATA_GAS", totalCalldataGas.String())
+	if err != nil {
+		return fmt.Errorf("error calculating CALLDATA_GAS chart_series: %w", err)
+	}
+
+	logger.Infof("Exporting EXECUTION_GAS %v", totalGasUsed.Sub(totalCalldataGas).String())
+	err = SaveChartSeriesPoint(dateTrunc, "EXECUTION_GAS", totalGasUsed.Sub(totalCalldataGas).String())
+	if err != nil {
+		return fmt.Errorf("error calculating EXECUTION_GAS chart_series: %w", err)
+	}
+
+	for indicator, count := range map[string]int64{
+		types.TxTypeLegacyIndicator:     legacyTxCount,
+		types.TxTypeAccessListIndicator: accessListTxCount,
+		types.TxTypeEIP1559Indicator:    eip1559TxCount,
+		types.TxTypeBlobIndicator:       blobTxCount,
+	} {
+		logger.Infof("Exporting %v %v", indicator, count)
+		err = SaveChartSeriesPoint(dateTrunc, indicator, count)
+		if err != nil {
+			return fmt.Errorf("error calculating %v chart_series: %w", indicator, err)
+		}
+	}
+
+	contractDeploymentCounts[types.ContractFactoriesIndicator] = int64(len(contractFactories))
+	for indicator, count := range contractDeploymentCounts {
+		logger.Infof("Exporting %v %v", indicator, count)
+		err = SaveChartSeriesPoint(dateTrunc, indicator, count)
+		if err != nil {
+			return fmt.Errorf("error calculating %v chart_series: %w", indicator, err)
+		}
+	}
+
+	for indicator, count := range nftMintCounts {
+		logger.Infof("Exporting %v %v", indicator, count)
+		err = SaveChartSeriesPoint(dateTrunc, indicator, count)
+		if err != nil {
+			return fmt.Errorf("error calculating %v chart_series: %w", indicator, err)
+		}
+	}
+
+	collections := make([]*types.NFTCollectionMints, 0, len(nftCollectionMints))
+	for address, c := range nftCollectionMints {
+		c.Minters = uint64(len(nftCollectionMinters[address]))
+		collections = append(collections, c)
+	}
+	logger.Infof("Exporting mints of %v nft collections", len(collections))
+	err = SaveNFTCollectionMints(dateTrunc, collections)
+	if err != nil {
+		return err
+	}
+
+	for indicator, count := range aaUserOpCounts {
+		logger.Infof("Exporting %v %v", indicator, count)
+		err = SaveChartSeriesPoint(dateTrunc, indicator, count)
+		if err != nil {
+			return fmt.Errorf("error calculating %v chart_series: %w", indicator, err)
+		}
+	}
+
+	operators := make([]*types.AAOperatorStats, 0, len(aaOperatorStats))
+	for _, stats := range aaOperatorStats {
+		operators = append(operators, stats)
+	}
+	logger.Infof("Exporting stats of %v account abstraction bundlers and paymasters", len(operators))
+	err = SaveAAOperatorStats(dateTrunc, operators)
+	if err != nil {
+		return err
+	}
+
+	gasPriceHours := make([]*types.GasPricePercentiles, 0, len(gasPricesByHour))
+	for hour := 0; hour < 24; hour++ {
+		if len(gasPricesByHour[hour]) > 0 {
+			gasPriceHours = append(gasPriceHours, gasPricePercentiles(dateTrunc.Add(time.Duration(hour)*time.Hour), gasPricesByHour[hour]))
+		}
+	}
+	logger.Infof("Exporting gas price percentiles of %v hours", len(gasPriceHours))
+	err = SaveGasPriceHourly(dateTrunc, gasPriceHours)
+	if err != nil {
+		return err
+	}
+
 	// Not sure how this is currently possible (where do we store the size, i think this is missing)
 	// logger.Infof("Exporting AVG_SIZE %v", totalSize.div)
 	// err = SaveChartSeriesPoint(dateTrunc, "AVG_SIZE", totalSize.div)
@@ -982,3 +1280,75 @@ func WriteChartSeriesForDay(day int64) error {
 
 	return nil
 }
+
+// GetDailyTxTypeCounts returns the number of transactions per transaction type and day for the last days
+func GetDailyTxTypeCounts(days uint64) ([]*types.TxTypeDayCounts, error) {
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     uint64    `db:"value"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT time, indicator, value
+		FROM chart_series
+		WHERE indicator = ANY($1) AND time > NOW() - $2::INT * INTERVAL '1 day'
+		ORDER BY time`, pq.Array(types.TxTypeIndicators), days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily tx type counts: %w", err)
+	}
+
+	counts := []*types.TxTypeDayCounts{}
+	for _, row := range rows {
+		if len(counts) == 0 || !counts[len(counts)-1].Day.Equal(row.Day) {
+			counts = append(counts, &types.TxTypeDayCounts{Day: row.Day})
+		}
+		day := counts[len(counts)-1]
+		switch row.Indicator {
+		case types.TxTypeLegacyIndicator:
+			day.Legacy = row.Value
+		case types.TxTypeAccessListIndicator:
+			day.AccessList = row.Value
+		case types.TxTypeEIP1559Indicator:
+			day.EIP1559 = row.Value
+		case types.TxTypeBlobIndicator:
+			day.Blob = row.Value
+		}
+	}
+	return counts, nil
+}
+
+// GetDailyContractDeploymentCounts returns the number of deployed contracts per kind of deployment and the number of active factories per day for the last days
+func GetDailyContractDeploymentCounts(days uint64) ([]*types.ContractDeploymentDayCounts, error) {
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     uint64    `db:"value"`
+	}{}
+	err := ReaderDb.Select(&rows, `
+		SELECT time, indicator, value
+		FROM chart_series
+		WHERE indicator = ANY($1) AND time > NOW() - $2::INT * INTERVAL '1 day'
+		ORDER BY time`, pq.Array(types.ContractDeploymentIndicators), days)
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily contract deployment counts: %w", err)
+	}
+
+	counts := []*types.ContractDeploymentDayCounts{}
+	for _, row := range rows {
+		if len(counts) == 0 || !counts[len(counts)-1].Day.Equal(row.Day) {
+			counts = append(counts, &types.ContractDeploymentDayCounts{Day: row.Day})
+		}
+		day := counts[len(counts)-1]
+		switch row.Indicator {
+		case types.ContractsDeployedTxIndicator:
+			day.Direct = row.Value
+		case types.ContractsDeployedCreateIndicator:
+			day.Create = row.Value
+		case types.ContractsDeployedCreate2Indicator:
+			day.Create2 = row.Value
+		case types.ContractFactoriesIndicator:
+			day.Factories = row.Value
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,18 @@
+package db
+
+// GetUserIdByEmail returns the id of the user registered with an email address
+func GetUserIdByEmail(email string) (uint64, error) {
+	var id uint64
+	err := FrontendWriterDB.Get(&id, "SELECT id FROM users WHERE email = $1", email)
+	return id, err
+}
+
+// InsertSupportAuditLog records that a support staff member accessed the data of a user. remoteAddr is the ip of the client as seen by
+// the explorer or its trusted proxy, forwardedFor the unverified X-Forwarded-For chain of the request.
+func InsertSupportAuditLog(staffUserID, targetUserID uint64, action, route, remoteAddr, forwardedFor string) error {
+	_, err := FrontendWriterDB.Exec(`
+		INSERT INTO support_audit_log (staff_user_id, target_user_id, action, route, remote_addr, forwarded_for)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		staffUserID, targetUserID, action, route, remoteAddr, forwardedFor)
+	return err
+}
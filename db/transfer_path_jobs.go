@@ -0,0 +1,76 @@
+package db
+
+import (
+	"encoding/json"
+	"eth2-exporter/types"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+func CreateTransferPathJob(search *types.TransferPathSearch) (*types.TransferPathJob, error) {
+	job := &types.TransferPathJob{
+		ID:          uuid.New().String(),
+		Status:      types.PendingTransferPathJobStatus,
+		Source:      search.Source,
+		Destination: search.Destination,
+		FromBlock:   search.FromBlock,
+		ToBlock:     search.ToBlock,
+		MaxDepth:    search.MaxDepth,
+	}
+	err := WriterDb.Get(&job.CreatedTime, `
+		insert into transfer_path_jobs (id, status, source, destination, from_block, to_block, max_depth, created_time)
+		values ($1, $2, $3, $4, $5, $6, $7, now())
+		returning created_time`,
+		job.ID, job.Status, job.Source, job.Destination, job.FromBlock, job.ToBlock, job.MaxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting into transfer_path_jobs: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{"id": job.ID, "source": fmt.Sprintf("%x", job.Source), "destination": fmt.Sprintf("%x", job.Destination), "depth": job.MaxDepth}).Infof("created transfer_path_job")
+	return job, nil
+}
+
+func GetTransferPathJob(id string) (*types.TransferPathJob, error) {
+	if len(id) > 40 {
+		return nil, fmt.Errorf("invalid id")
+	}
+	job := types.TransferPathJob{}
+	err := WriterDb.Get(&job, `select id, status, created_time, completed_time, source, destination, from_block, to_block, max_depth, result, error from transfer_path_jobs where id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ClaimPendingTransferPathJob marks the oldest pending job as running and returns it, it returns nil if there is no pending job
+func ClaimPendingTransferPathJob() (*types.TransferPathJob, error) {
+	jobs := []*types.TransferPathJob{}
+	err := WriterDb.Select(&jobs, `
+		update transfer_path_jobs set status = $1
+		where id = (select id from transfer_path_jobs where status = $2 order by created_time limit 1 for update skip locked)
+		returning id, status, created_time, completed_time, source, destination, from_block, to_block, max_depth, result, error`,
+		types.RunningTransferPathJobStatus, types.PendingTransferPathJobStatus)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	return jobs[0], nil
+}
+
+func CompleteTransferPathJob(id string, result *types.TransferPathResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = WriterDb.Exec(`update transfer_path_jobs set status = $1, result = $2, completed_time = now() where id = $3`, types.CompletedTransferPathJobStatus, data, id)
+	return err
+}
+
+func FailTransferPathJob(id string, jobErr error) error {
+	_, err := WriterDb.Exec(`update transfer_path_jobs set status = $1, error = $2, completed_time = now() where id = $3`, types.FailedTransferPathJobStatus, jobErr.Error(), id)
+	return err
+}
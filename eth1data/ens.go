@@ -0,0 +1,74 @@
+package eth1data
+
+import (
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	ensResolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	ensAddrSelector     = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+)
+
+// ResolveEnsName returns the address an ens name points to, the indexed ens records are preferred over querying the node
+func ResolveEnsName(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(name)
+	cacheKey := fmt.Sprintf("%d:ens:%s", utils.Config.Chain.Config.DepositChainID, name)
+	if wanted, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Hour); err == nil {
+		return common.HexToAddress(wanted), nil
+	}
+
+	indexed, err := db.GetEth1Store().GetEnsAddress(ctx, name)
+	if err != nil {
+		logger.Warnf("error retrieving indexed ens record of %v: %v", name, err)
+	}
+	if len(indexed) == 20 {
+		address := common.BytesToAddress(indexed)
+		err = cache.TieredCache.SetString(cacheKey, address.Hex(), time.Hour)
+		if err != nil {
+			logger.Errorf("error writing ens resolution for %v to cache: %v", name, err)
+		}
+		return address, nil
+	}
+
+	if rpc.CurrentErigonClient == nil {
+		return common.Address{}, fmt.Errorf("ens name %v is not indexed and no node is available to resolve it", name)
+	}
+	node := utils.EnsNamehash(name)
+	client := rpc.CurrentErigonClient.GetNativeClient()
+
+	resolver, err := client.CallContract(ctx, ethereum.CallMsg{To: &utils.EnsRegistryAddress, Data: append(append([]byte{}, ensResolverSelector...), node.Bytes()...)}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error retrieving ens resolver for %v: %w", name, err)
+	}
+	resolverAddress := common.BytesToAddress(resolver)
+	if len(resolver) != 32 || resolverAddress == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("no ens resolver set for %v", name)
+	}
+
+	addr, err := client.CallContract(ctx, ethereum.CallMsg{To: &resolverAddress, Data: append(append([]byte{}, ensAddrSelector...), node.Bytes()...)}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("error resolving ens name %v: %w", name, err)
+	}
+	address := common.BytesToAddress(addr)
+	if len(addr) != 32 || address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("ens name %v does not resolve to an address", name)
+	}
+
+	err = cache.TieredCache.SetString(cacheKey, address.Hex(), time.Hour*24)
+	if err != nil {
+		logger.Errorf("error writing ens resolution for %v to cache: %v", name, err)
+	}
+
+	return address, nil
+}
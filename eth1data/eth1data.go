@@ -14,6 +14,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -99,6 +100,15 @@ func GetEth1Transaction(hash common.Hash) (*types.Eth1TxData, error) {
 	txPageData.TypeFormatted = utils.FormatTransactionType(receipt.Type)
 	txPageData.TxnPosition = receipt.TransactionIndex
 
+	// the position and gas price rank within the block are computed by the indexer, txs that are not indexed yet have none
+	indexedTx, err := db.GetEth1Store().GetIndexedEth1Transaction(context.Background(), hash.Bytes())
+	if err != nil {
+		logger.Warnf("error retrieving indexed data for tx %v: %v", hash, err)
+	} else if indexedTx != nil && indexedTx.GetBlockTxCount() > 0 {
+		txPageData.BlockTxCount = indexedTx.GetBlockTxCount()
+		txPageData.GasPricePercentile = (indexedTx.GetBlockTxCount() - indexedTx.GetGasPriceRank()) * 100 / indexedTx.GetBlockTxCount()
+	}
+
 	txPageData.Gas.MaxPriorityFee = msg.GasTipCap().Bytes()
 	txPageData.Gas.MaxFee = msg.GasFeeCap().Bytes()
 	if header.BaseFee != nil {
@@ -121,6 +131,7 @@ func GetEth1Transaction(hash common.Hash) (*types.Eth1TxData, error) {
 		txPageData.Gas.EffectiveFee = msg.GasFeeCap().Bytes()
 		txPageData.Gas.TxFee = msg.GasFeeCap().Mul(msg.GasFeeCap(), big.NewInt(int64(receipt.GasUsed))).Bytes()
 	}
+	txPageData.FeeBreakdown = types.NewTxFeeBreakdown(receipt.GasUsed, new(big.Int).SetBytes(txPageData.Gas.EffectiveFee), header.BaseFee)
 
 	if receipt.Status != 1 {
 		data, err := rpc.CurrentErigonClient.TraceParityTx(tx.Hash().Hex())
@@ -133,21 +144,30 @@ func GetEth1Transaction(hash common.Hash) (*types.Eth1TxData, error) {
 		}
 	}
 	if receipt.Status == 1 {
-		txPageData.Transfers, err = db.BigtableClient.GetArbitraryTokenTransfersForTransaction(tx.Hash().Bytes())
-		if err != nil {
+		txPageData.Transfers, err = db.GetEth1Store().GetArbitraryTokenTransfersForTransaction(context.Background(), tx.Hash().Bytes())
+		if err != nil && !db.IsPartialResult(err) {
 			return nil, fmt.Errorf("error loading token transfers from tx %v: %v", hash, err)
 		}
-		txPageData.InternalTxns, err = db.BigtableClient.GetInternalTransfersForTransaction(tx.Hash().Bytes(), msg.From().Bytes())
-		if err != nil {
+		txPageData.InternalTxns, err = db.GetEth1Store().GetInternalTransfersForTransaction(context.Background(), tx.Hash().Bytes(), msg.From().Bytes())
+		if err != nil && !db.IsPartialResult(err) {
 			return nil, fmt.Errorf("error loading internal transfers from tx %v: %v", hash, err)
 		}
 	}
-	txPageData.FromName, err = db.BigtableClient.GetAddressName(msg.From().Bytes())
+	txPageData.ItxTree, err = db.GetEth1Store().GetItxTreeForTransaction(context.Background(), tx.Hash().Bytes())
+	if err != nil {
+		// the call tree is not essential for the page, it is left out if the raw block can not be read
+		logger.Warnf("error loading call tree of tx %v: %v", hash, err)
+	}
+	if len(txPageData.ItxTree) == 1 && len(txPageData.ItxTree[0].Children) == 0 {
+		// plain transfers and calls that do not call other contracts have no tree to show
+		txPageData.ItxTree = nil
+	}
+	txPageData.FromName, err = db.GetEth1Store().GetAddressName(context.Background(), msg.From().Bytes())
 	if err != nil {
 		return nil, fmt.Errorf("error retrieveing from name for tx %v: %v", hash, err)
 	}
 	if msg.To() != nil {
-		txPageData.ToName, err = db.BigtableClient.GetAddressName(msg.To().Bytes())
+		txPageData.ToName, err = db.GetEth1Store().GetAddressName(context.Background(), msg.To().Bytes())
 		if err != nil {
 			return nil, fmt.Errorf("error retrieveing to name for tx %v: %v", hash, err)
 		}
@@ -164,13 +184,13 @@ func GetEth1Transaction(hash common.Hash) (*types.Eth1TxData, error) {
 
 		for _, log := range receipt.Logs {
 			if cmEntry, wasContractMetadataCached = contractMetadataCache[log.Address]; !wasContractMetadataCached {
-				cmEntry.meta, cmEntry.err = db.BigtableClient.GetContractMetadata(log.Address.Bytes())
+				cmEntry.meta, cmEntry.err = db.GetEth1Store().GetContractMetadata(context.Background(), log.Address.Bytes())
 				contractMetadataCache[log.Address] = cmEntry
 			}
 			if cmEntry.err != nil || cmEntry.meta == nil || cmEntry.meta.ABI == nil {
 				name := ""
 				if len(log.Topics) > 0 {
-					name = db.BigtableClient.GetEventLabel(log.Topics[0][:])
+					name = db.GetEth1Store().GetEventLabel(context.Background(), log.Topics[0][:])
 				}
 				eth1Event := &types.Eth1EventData{
 					Address: log.Address,
@@ -307,6 +327,35 @@ func IsContract(ctx context.Context, address common.Address) (bool, error) {
 	return isContract, nil
 }
 
+// GetContractCode returns the runtime code of a contract at the latest block, the code is cached as it only changes if the contract is destroyed
+func GetContractCode(ctx context.Context, address common.Address) ([]byte, error) {
+	cacheKey := fmt.Sprintf("%d:code:%s", utils.Config.Chain.Config.DepositChainID, address.String())
+	if wanted, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Hour); err == nil {
+		return []byte(wanted), nil
+	}
+
+	code, err := rpc.CurrentErigonClient.GetNativeClient().CodeAt(ctx, address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving code of address %v: %v", address, err)
+	}
+
+	err = cache.TieredCache.SetString(cacheKey, string(code), time.Hour*24)
+	if err != nil {
+		return nil, fmt.Errorf("error writing code of address %v to cache: %v", address, err)
+	}
+
+	return code, nil
+}
+
+// GetStorageAt returns the value of a storage slot of a contract at the latest block
+func GetStorageAt(ctx context.Context, address common.Address, slot common.Hash) ([]byte, error) {
+	value, err := rpc.CurrentErigonClient.GetNativeClient().StorageAt(ctx, address, slot, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving storage slot %v of address %v: %v", slot, address, err)
+	}
+	return value, nil
+}
+
 func GetBlockHeaderByHash(ctx context.Context, hash common.Hash) (*geth_types.Header, error) {
 	// cacheKey := fmt.Sprintf("%d:h:%s", utils.Config.Chain.Config.DepositChainID, hash.String())
 
@@ -348,3 +397,61 @@ func GetTransactionReceipt(ctx context.Context, hash common.Hash) (*geth_types.R
 
 	return receipt, nil
 }
+
+// GetBalanceAt returns the balance of an address at a block, native ether is denoted by the token 0x00.
+// Balances of final blocks are answered from the stored balance history and only queried from the archive node once.
+// The returned source is either "history" or "node".
+func GetBalanceAt(ctx context.Context, address, token []byte, block uint64, final bool) (*big.Int, string, error) {
+	// balances of blocks that might still be reorged are only cached shortly
+	cacheKey := fmt.Sprintf("%d:balanceAt:%x:%x:%d", utils.Config.Chain.Config.DepositChainID, address, token, block)
+
+	if final {
+		balance, err := db.GetEth1BalanceAt(address, token, block)
+		if err != nil {
+			return nil, "", err
+		}
+		if balance != nil {
+			return balance, "history", nil
+		}
+	} else if cached, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Minute); err == nil {
+		if balance, ok := new(big.Int).SetString(cached, 10); ok {
+			return balance, "node", nil
+		}
+	}
+
+	blockNumber := new(big.Int).SetUint64(block)
+	balance := new(big.Int)
+	if bytes.Equal(token, []byte{0x00}) {
+		var err error
+		balance, err = rpc.CurrentErigonClient.GetNativeClient().BalanceAt(ctx, common.BytesToAddress(address), blockNumber)
+		if err != nil {
+			return nil, "", fmt.Errorf("error retrieving balance of address 0x%x at block %v: %v", address, block, err)
+		}
+	} else {
+		to := common.BytesToAddress(token)
+		result, err := rpc.CurrentErigonClient.GetNativeClient().CallContract(ctx, ethereum.CallMsg{
+			To:   &to,
+			Gas:  1000000,
+			Data: append(common.Hex2Bytes("70a08231000000000000000000000000"), common.BytesToAddress(address).Bytes()...),
+		}, blockNumber)
+		// tokens that have not been deployed yet at the requested block revert, the balance is zero in that case
+		if err != nil && !strings.HasPrefix(err.Error(), "execution reverted") {
+			return nil, "", fmt.Errorf("error retrieving token 0x%x balance of address 0x%x at block %v: %v", token, address, block, err)
+		}
+		balance.SetBytes(result)
+	}
+
+	if final {
+		err := db.SaveEth1BalanceAt(address, token, block, balance)
+		if err != nil {
+			logger.Errorf("error storing balance history: %v", err)
+		}
+	} else {
+		err := cache.TieredCache.SetString(cacheKey, balance.String(), time.Minute)
+		if err != nil {
+			logger.Errorf("error caching balance of address 0x%x at block %v: %v", address, block, err)
+		}
+	}
+
+	return balance, "node", nil
+}
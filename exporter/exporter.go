@@ -2,6 +2,7 @@ package exporter
 
 import (
 	"bytes"
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/metrics"
 	"eth2-exporter/rpc"
@@ -258,11 +259,11 @@ func Start(client rpc.Client) error {
 		}
 		blocksMap[block.Slot][fmt.Sprintf("%x", block.BlockRoot)] = block
 
-		err := db.BigtableClient.SaveAttestations(blocksMap)
+		err := db.BigtableClient.SaveAttestations(context.Background(), blocksMap)
 		if err != nil {
 			logrus.Errorf("error exporting attestations to bigtable for block %v: %v", block.Slot, err)
 		}
-		err = db.BigtableClient.SaveSyncComitteeDuties(blocksMap)
+		err = db.BigtableClient.SaveSyncComitteeDuties(context.Background(), blocksMap)
 		if err != nil {
 			logrus.Errorf("error exporting sync committee duties to bigtable for block %v: %v", block.Slot, err)
 		}
@@ -537,42 +538,42 @@ func ExportEpoch(epoch uint64, client rpc.Client) error {
 	g := new(errgroup.Group)
 	g.SetLimit(7)
 	g.Go(func() error {
-		err = db.BigtableClient.SaveValidatorBalances(epoch, data.Validators)
+		err = db.BigtableClient.SaveValidatorBalances(context.Background(), epoch, data.Validators)
 		if err != nil {
 			return fmt.Errorf("error exporting validator balances to bigtable: %v", err)
 		}
 		return nil
 	})
 	g.Go(func() error {
-		err = db.BigtableClient.SaveAttestationAssignments(epoch, data.ValidatorAssignmentes.AttestorAssignments)
+		err = db.BigtableClient.SaveAttestationAssignments(context.Background(), epoch, data.ValidatorAssignmentes.AttestorAssignments)
 		if err != nil {
 			return fmt.Errorf("error exporting attestation assignments to bigtable: %v", err)
 		}
 		return nil
 	})
 	g.Go(func() error {
-		err = db.BigtableClient.SaveProposalAssignments(epoch, data.ValidatorAssignmentes.ProposerAssignments)
+		err = db.BigtableClient.SaveProposalAssignments(context.Background(), epoch, data.ValidatorAssignmentes.ProposerAssignments)
 		if err != nil {
 			return fmt.Errorf("error exporting proposal assignments to bigtable: %v", err)
 		}
 		return nil
 	})
 	g.Go(func() error {
-		err = db.BigtableClient.SaveAttestations(data.Blocks)
+		err = db.BigtableClient.SaveAttestations(context.Background(), data.Blocks)
 		if err != nil {
 			return fmt.Errorf("error exporting attestations to bigtable: %v", err)
 		}
 		return nil
 	})
 	g.Go(func() error {
-		err = db.BigtableClient.SaveProposals(data.Blocks)
+		err = db.BigtableClient.SaveProposals(context.Background(), data.Blocks)
 		if err != nil {
 			return fmt.Errorf("error exporting proposals to bigtable: %v", err)
 		}
 		return nil
 	})
 	g.Go(func() error {
-		err = db.BigtableClient.SaveSyncComitteeDuties(data.Blocks)
+		err = db.BigtableClient.SaveSyncComitteeDuties(context.Background(), data.Blocks)
 		if err != nil {
 			return fmt.Errorf("error exporting sync committee duties to bigtable: %v", err)
 		}
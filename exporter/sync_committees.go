@@ -1,6 +1,7 @@
 package exporter
 
 import (
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/rpc"
 	"eth2-exporter/services"
@@ -91,7 +92,7 @@ func exportSyncCommitteeAtPeriod(rpcClient rpc.Client, p uint64) error {
 	lastSlot := lastEpoch*utils.Config.Chain.Config.SlotsPerEpoch + utils.Config.Chain.Config.SlotsPerEpoch - 1
 	logger.Infof("exporting sync committee assignments for period %v (epoch %v to %v, slot %v to %v) to bigtable", p, firstEpoch, lastEpoch, firstSlot, lastSlot)
 
-	err = db.BigtableClient.SaveSyncCommitteesAssignments(firstSlot, lastSlot, validatorsU64)
+	err = db.BigtableClient.SaveSyncCommitteesAssignments(context.Background(), firstSlot, lastSlot, validatorsU64)
 	if err != nil {
 		return fmt.Errorf("error saving sync committee assignments: %v", err)
 	}
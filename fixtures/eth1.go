@@ -0,0 +1,324 @@
+// Package fixtures generates deterministic synthetic execution blocks for the transformer tests, the db benchmarks and the
+// bigtable emulator suite, so that none of them depends on live chain data.
+package fixtures
+
+import (
+	"encoding/binary"
+	"eth2-exporter/erc1155"
+	"eth2-exporter/erc20"
+	"eth2-exporter/erc721"
+	"eth2-exporter/types"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GenesisTime is the time of block 0, blocks follow each other every 12 seconds
+var GenesisTime = time.Unix(1600000000, 0)
+
+var (
+	transferSelector     = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	safeTransferSelector = crypto.Keccak256([]byte("safeTransferFrom(address,address,uint256)"))[:4]
+	multicallSelector    = crypto.Keccak256([]byte("multicall(bytes[])"))[:4]
+	// PUSH1 0x80 PUSH1 0x40 MSTORE CALLVALUE DUP1 ISZERO
+	initCode = common.FromHex("0x608060405234801561001057600080fd5b50")
+)
+
+// Options configures the generated blocks. The shares set how many transactions of a block are of each kind, the
+// remaining transactions are plain ether transfers between externally owned accounts. Token transactions cycle through
+// the ERC20, ERC721 and ERC1155 standards.
+type Options struct {
+	// Seed selects the generated contents, the same seed and options always produce the same blocks.
+	// Block and transaction hashes only depend on the block number and the position of the transaction.
+	Seed int64
+	// Transactions is the number of transactions per block
+	Transactions int
+	// Addresses is the number of externally owned accounts sending and receiving transactions
+	Addresses int
+	// Tokens is the number of token contracts of each standard
+	Tokens int
+	// ERC20PerTx, ERC721PerTx and ERC1155PerTx are the number of token transfers a token transaction emits
+	ERC20PerTx   int
+	ERC721PerTx  int
+	ERC1155PerTx int
+	// ItxPerTx is the number of internal calls of a contract call
+	ItxPerTx int
+	// Withdrawals is the number of withdrawals per block
+	Withdrawals int
+	// shares of the transaction kinds, between 0 and 1
+	TokenShare    float64
+	CallShare     float64
+	CreationShare float64
+	FailureShare  float64
+}
+
+// DefaultOptions returns options that roughly resemble a mainnet block
+func DefaultOptions() Options {
+	return Options{
+		Seed:          1,
+		Transactions:  100,
+		Addresses:     50,
+		Tokens:        5,
+		ERC20PerTx:    2,
+		ERC721PerTx:   1,
+		ERC1155PerTx:  1,
+		ItxPerTx:      2,
+		Withdrawals:   16,
+		TokenShare:    0.4,
+		CallShare:     0.2,
+		CreationShare: 0.02,
+		FailureShare:  0.03,
+	}
+}
+
+// Address returns the i-th externally owned account of the fixtures
+func Address(i int) []byte {
+	address := make([]byte, 20)
+	address[0] = 0xbe
+	binary.BigEndian.PutUint64(address[12:], uint64(i))
+	return address
+}
+
+// ContractAddress returns the i-th contract of a kind, the kinds used by the fixtures are erc20, erc721, erc1155 and call
+func ContractAddress(kind string, i int) []byte {
+	address := make([]byte, 20)
+	address[0] = 0xc0
+	copy(address[1:12], kind)
+	binary.BigEndian.PutUint64(address[12:], uint64(i))
+	return address
+}
+
+// Hash returns the hash of the i-th transaction of a block, the block itself has index -1
+func Hash(block uint64, i int) []byte {
+	hash := make([]byte, 32)
+	binary.BigEndian.PutUint64(hash[16:], block)
+	binary.BigEndian.PutUint64(hash[24:], uint64(i))
+	return hash
+}
+
+// Chain returns count consecutive blocks starting at block from, every block references its predecessor as parent
+func Chain(from uint64, count int, opts Options) []*types.Eth1Block {
+	blocks := make([]*types.Eth1Block, 0, count)
+	for i := 0; i < count; i++ {
+		blocks = append(blocks, Block(from+uint64(i), opts))
+	}
+	return blocks
+}
+
+// Block returns the synthetic block with the given number
+func Block(number uint64, opts Options) *types.Eth1Block {
+	g := &generator{
+		rng:    rand.New(rand.NewSource(opts.Seed*1_000_003 + int64(number))),
+		opts:   opts,
+		number: number,
+	}
+	return g.block()
+}
+
+// kinds of the generated transactions
+const (
+	kindTransfer = iota
+	kindCreation
+	kindToken
+	kindCall
+)
+
+type generator struct {
+	rng      *rand.Rand
+	opts     Options
+	number   uint64
+	baseFee  *big.Int
+	tokenTxs int
+}
+
+func (g *generator) block() *types.Eth1Block {
+	// the base fee moves between 10 and 50 GWei
+	g.baseFee = new(big.Int).Mul(big.NewInt(10+g.rng.Int63n(40)), big.NewInt(1e9))
+
+	blk := &types.Eth1Block{
+		Hash:         Hash(g.number, -1),
+		ParentHash:   make([]byte, 32),
+		UncleHash:    crypto.Keccak256(common.FromHex("0xc0")),
+		Coinbase:     g.address(),
+		Number:       g.number,
+		GasLimit:     30_000_000,
+		Time:         timestamppb.New(GenesisTime.Add(time.Duration(g.number) * 12 * time.Second)),
+		BaseFee:      g.baseFee.Bytes(),
+		Transactions: make([]*types.Eth1Transaction, 0, g.opts.Transactions),
+		Withdrawals:  make([]*types.Eth1Withdrawal, 0, g.opts.Withdrawals),
+	}
+
+	if g.number > 0 {
+		blk.ParentHash = Hash(g.number-1, -1)
+	}
+
+	// the number of transactions of every kind is fixed by the shares, only their order is random
+	kinds := make([]int, g.opts.Transactions)
+	offset := 0
+	for _, share := range []struct {
+		kind  int
+		share float64
+	}{{kindCreation, g.opts.CreationShare}, {kindToken, g.opts.TokenShare}, {kindCall, g.opts.CallShare}} {
+		count := int(math.Round(share.share * float64(g.opts.Transactions)))
+		for i := offset; i < offset+count && i < len(kinds); i++ {
+			kinds[i] = share.kind
+		}
+		offset += count
+	}
+	g.rng.Shuffle(len(kinds), func(i, j int) {
+		kinds[i], kinds[j] = kinds[j], kinds[i]
+	})
+
+	cumulativeGas := uint64(0)
+	for i := 0; i < g.opts.Transactions; i++ {
+		tx := g.transaction(i, kinds[i])
+		cumulativeGas += tx.GasUsed
+		tx.CommulativeGasUsed = cumulativeGas
+		blk.Transactions = append(blk.Transactions, tx)
+	}
+	blk.GasUsed = cumulativeGas
+
+	for i := 0; i < g.opts.Withdrawals; i++ {
+		blk.Withdrawals = append(blk.Withdrawals, &types.Eth1Withdrawal{
+			Index:          g.number*uint64(g.opts.Withdrawals) + uint64(i),
+			ValidatorIndex: uint64(g.rng.Intn(500000)),
+			Address:        g.address(),
+			Amount:         big.NewInt(g.rng.Int63n(1e8)).Bytes(),
+		})
+	}
+	return blk
+}
+
+func (g *generator) address() []byte {
+	return Address(g.rng.Intn(g.opts.Addresses))
+}
+
+func (g *generator) transaction(i int, kind int) *types.Eth1Transaction {
+	priorityFee := new(big.Int).Mul(big.NewInt(1+g.rng.Int63n(3)), big.NewInt(1e9))
+	from := g.address()
+	tx := &types.Eth1Transaction{
+		Type:                 2,
+		Nonce:                g.number*uint64(g.opts.Transactions) + uint64(i),
+		GasPrice:             new(big.Int).Add(g.baseFee, priorityFee).Bytes(),
+		MaxPriorityFeePerGas: priorityFee.Bytes(),
+		MaxFeePerGas:         new(big.Int).Mul(g.baseFee, big.NewInt(2)).Bytes(),
+		From:                 from,
+		ChainId:              big.NewInt(1).Bytes(),
+		Hash:                 Hash(g.number, i),
+		Status:               1,
+	}
+
+	switch kind {
+	case kindCreation:
+		g.creation(tx)
+	case kindToken:
+		g.tokenTransfers(tx)
+	case kindCall:
+		g.call(tx)
+	default:
+		to := g.address()
+		tx.To = to
+		tx.Value = g.value(1e9)
+		tx.Gas = 21000
+		tx.GasUsed = 21000
+		tx.Itx = []*types.Eth1InternalTransaction{{Type: "call", From: from, To: to, Value: tx.Value, Path: "[]"}}
+	}
+
+	// only calls can fail, a failed call does not emit logs and its traces carry the error
+	if len(tx.Data) > 0 && g.rng.Float64() < g.opts.FailureShare {
+		tx.Status = 0
+		tx.ErrorMsg = "execution reverted"
+		tx.Logs = nil
+		tx.ContractAddress = nil
+		for _, itx := range tx.Itx {
+			itx.ErrorMsg = "Reverted"
+		}
+	}
+	return tx
+}
+
+func (g *generator) creation(tx *types.Eth1Transaction) {
+	contract := crypto.CreateAddress(common.BytesToAddress(tx.From), tx.Nonce).Bytes()
+	tx.Data = initCode
+	tx.Gas = 1_500_000
+	tx.GasUsed = 500_000 + uint64(g.rng.Intn(1_000_000))
+	tx.ContractAddress = contract
+	tx.Itx = []*types.Eth1InternalTransaction{{Type: "create", From: tx.From, To: contract, Path: "[]"}}
+}
+
+func (g *generator) call(tx *types.Eth1Transaction) {
+	contract := ContractAddress("call", g.rng.Intn(g.opts.Tokens))
+	tx.To = contract
+	tx.Data = append(append([]byte{}, multicallSelector...), make([]byte, 64)...)
+	tx.Value = g.value(1e8)
+	tx.Gas = 300_000
+	tx.GasUsed = 50_000 + uint64(g.rng.Intn(200_000))
+	tx.Itx = []*types.Eth1InternalTransaction{{Type: "call", From: tx.From, To: contract, Value: tx.Value, Path: "[]"}}
+	for j := 0; j < g.opts.ItxPerTx; j++ {
+		itx := &types.Eth1InternalTransaction{Type: "call", From: contract, To: g.address(), Path: fmt.Sprintf("[%d]", j)}
+		// every other internal call forwards ether
+		if j%2 == 0 {
+			itx.Value = g.value(1e7)
+		}
+		tx.Itx = append(tx.Itx, itx)
+	}
+}
+
+func (g *generator) tokenTransfers(tx *types.Eth1Transaction) {
+	to := g.address()
+	standard := g.tokenTxs % 3
+	g.tokenTxs++
+	switch standard {
+	case 0:
+		tx.To = ContractAddress("erc20", g.rng.Intn(g.opts.Tokens))
+		tx.Data = append(append(append([]byte{}, transferSelector...), common.LeftPadBytes(to, 32)...), make([]byte, 32)...)
+	case 1:
+		tx.To = ContractAddress("erc721", g.rng.Intn(g.opts.Tokens))
+		tx.Data = append(append([]byte{}, safeTransferSelector...), make([]byte, 96)...)
+	default:
+		tx.To = ContractAddress("erc1155", g.rng.Intn(g.opts.Tokens))
+		tx.Data = append(append([]byte{}, safeTransferSelector...), make([]byte, 96)...)
+	}
+	tx.Gas = 200_000
+	tx.GasUsed = 40_000 + uint64(g.rng.Intn(100_000))
+	tx.Itx = []*types.Eth1InternalTransaction{{Type: "call", From: tx.From, To: tx.To, Path: "[]"}}
+
+	from := common.LeftPadBytes(tx.From, 32)
+	switch standard {
+	case 0:
+		for j := 0; j < g.opts.ERC20PerTx; j++ {
+			tx.Logs = append(tx.Logs, &types.Eth1Log{
+				Address: tx.To,
+				Data:    common.LeftPadBytes(g.value(1e12), 32),
+				Topics:  [][]byte{erc20.TransferTopic, from, common.LeftPadBytes(g.address(), 32)},
+			})
+		}
+	case 1:
+		for j := 0; j < g.opts.ERC721PerTx; j++ {
+			tx.Logs = append(tx.Logs, &types.Eth1Log{
+				Address: tx.To,
+				Topics:  [][]byte{erc721.TransferTopic, from, common.LeftPadBytes(g.address(), 32), common.LeftPadBytes(big.NewInt(g.rng.Int63n(10000)).Bytes(), 32)},
+			})
+		}
+	default:
+		for j := 0; j < g.opts.ERC1155PerTx; j++ {
+			data := append(common.LeftPadBytes(big.NewInt(g.rng.Int63n(100)).Bytes(), 32), common.LeftPadBytes(big.NewInt(1+g.rng.Int63n(10)).Bytes(), 32)...)
+			tx.Logs = append(tx.Logs, &types.Eth1Log{
+				Address: tx.To,
+				Data:    data,
+				Topics:  [][]byte{erc1155.TransferSingleTopic, from, from, common.LeftPadBytes(g.address(), 32)},
+			})
+		}
+	}
+}
+
+// value returns a random amount of wei below maxGwei GWei
+func (g *generator) value(maxGwei int64) []byte {
+	return new(big.Int).Mul(big.NewInt(g.rng.Int63n(maxGwei)), big.NewInt(1e9)).Bytes()
+}
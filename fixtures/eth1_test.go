@@ -0,0 +1,50 @@
+package fixtures
+
+import (
+	"bytes"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBlockIsDeterministic(t *testing.T) {
+	opts := DefaultOptions()
+	if !proto.Equal(Block(42, opts), Block(42, opts)) {
+		t.Fatal("the same options produced different blocks")
+	}
+
+	opts.Seed = 2
+	if proto.Equal(Block(42, DefaultOptions()), Block(42, opts)) {
+		t.Fatal("different seeds produced the same block")
+	}
+}
+
+func TestChain(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Transactions = 10
+	blocks := Chain(100, 3, opts)
+	for i := 1; i < len(blocks); i++ {
+		if !bytes.Equal(blocks[i].GetParentHash(), blocks[i-1].GetHash()) {
+			t.Errorf("block %v does not reference block %v as parent", blocks[i].GetNumber(), blocks[i-1].GetNumber())
+		}
+	}
+
+	// 40% token transactions cycling through the standards, 20% calls and no creation in a block of 10 transactions
+	kinds := map[string]int{}
+	for _, tx := range blocks[0].GetTransactions() {
+		switch {
+		case len(tx.GetTo()) == 0:
+			kinds["creation"]++
+		case tx.GetTo()[0] == 0xc0:
+			kinds[string(bytes.TrimRight(tx.GetTo()[1:12], "\x00"))]++
+		default:
+			kinds["transfer"]++
+		}
+	}
+	want := map[string]int{"erc20": 2, "erc721": 1, "erc1155": 1, "call": 2, "transfer": 4}
+	for kind, count := range want {
+		if kinds[kind] != count {
+			t.Errorf("got %v %v transactions, want %v", kinds[kind], kind, count)
+		}
+	}
+}
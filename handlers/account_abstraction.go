@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/sync/errgroup"
+)
+
+// number of days the account abstraction leaderboards of the page are aggregated over
+const aaLeaderboardDays = 30
+
+// aaSuccessRate returns the share of successful operations in percent
+func aaSuccessRate(stats *types.AAOperatorStats) float64 {
+	if stats.Operations == 0 {
+		return 0
+	}
+	return float64(stats.Successful) * 100 / float64(stats.Operations)
+}
+
+// AAOperators will return the page with the leaderboards of the most active ERC-4337 bundlers and paymasters
+func AAOperators(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "aa_operators.html")
+	var aaOperatorsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "blockchain", "/aa", "Account Abstraction", templateFiles)
+
+	var bundlers, paymasters []*types.AAOperatorStats
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		var err error
+		bundlers, err = db.GetAAOperatorLeaderboard(types.AAOperatorBundler, aaLeaderboardDays, 25)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		paymasters, err = db.GetAAOperatorLeaderboard(types.AAOperatorPaymaster, aaLeaderboardDays, 25)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		logger.Errorf("error retrieving account abstraction leaderboards: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	names := make(map[string]string)
+	for _, s := range append(append([]*types.AAOperatorStats{}, bundlers...), paymasters...) {
+		names[string(s.Address)] = ""
+	}
+	err := db.GetEth1Store().GetAddressNames(r.Context(), names)
+	if err != nil {
+		logger.Errorf("error retrieving names of account abstraction operators: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	rows := func(stats []*types.AAOperatorStats) []*types.AAOperatorRow {
+		rows := make([]*types.AAOperatorRow, 0, len(stats))
+		for _, s := range stats {
+			rows = append(rows, &types.AAOperatorRow{
+				Address:     utils.FormatAddressWithLimits(s.Address, names[string(s.Address)], false, "address", 17, 20, false),
+				Operations:  s.Operations,
+				SuccessRate: aaSuccessRate(s),
+				GasUsed:     s.GasUsed.BigInt().Uint64(),
+				GasCost:     utils.FormatAmount(s.GasCost.BigInt(), "Ether", 5),
+			})
+		}
+		return rows
+	}
+	data.Data = &types.AAOperatorsPageData{
+		Days:       aaLeaderboardDays,
+		Bundlers:   rows(bundlers),
+		Paymasters: rows(paymasters),
+	}
+
+	if handleTemplateError(w, r, "account_abstraction.go", "AAOperators", "", aaOperatorsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// ApiAAOperators godoc
+// @Summary Get the ERC-4337 bundlers or paymasters with the most user operations, gas cost is denominated in wei
+// @Tags Execution
+// @Produce json
+// @Param role path string true "Either bundlers or paymasters"
+// @Param days query int false "Number of past days, defaults to 30, maximum 365"
+// @Success 200 {object} types.ApiResponse{data=[]types.APIAAOperator}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/aa/{role} [get]
+func ApiAAOperators(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	role := ""
+	switch mux.Vars(r)["role"] {
+	case "bundlers":
+		role = types.AAOperatorBundler
+	case "paymasters":
+		role = types.AAOperatorPaymaster
+	default:
+		sendErrorResponse(w, r.URL.String(), "invalid role provided, role must be bundlers or paymasters")
+		return
+	}
+
+	days := uint64(aaLeaderboardDays)
+	if q := r.URL.Query().Get("days"); q != "" {
+		var err error
+		days, err = strconv.ParseUint(q, 10, 64)
+		if err != nil || days < 1 || days > dailyStatsMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", dailyStatsMaxDays))
+			return
+		}
+	}
+
+	stats, err := db.GetAAOperatorLeaderboard(role, days, 100)
+	if err != nil {
+		logger.Errorf("error getting aa operators route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting account abstraction operators")
+		return
+	}
+
+	operators := make([]*types.APIAAOperator, 0, len(stats))
+	for _, s := range stats {
+		operators = append(operators, &types.APIAAOperator{
+			Address:     utils.FixAddressCasing(fmt.Sprintf("%x", s.Address)),
+			Operations:  s.Operations,
+			Successful:  s.Successful,
+			SuccessRate: aaSuccessRate(s),
+			GasUsed:     s.GasUsed.String(),
+			GasCost:     s.GasCost.String(),
+		})
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{operators})
+}
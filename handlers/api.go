@@ -114,13 +114,13 @@ func ApiHealthz(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// check latest eth1 indexed block
-	numberBlocksTable, err := db.BigtableClient.GetLastBlockInBlocksTable()
+	numberBlocksTable, err := db.GetEth1Store().GetLastBlockInBlocksTable(r.Context())
 	if err != nil {
 		logger.Errorf("could not retrieve latest block number from the blocks table: %v", err)
 		http.Error(w, "Internal server error: could not retrieve latest block number from the blocks table", http.StatusServiceUnavailable)
 		return
 	}
-	blockBlocksTable, err := db.BigtableClient.GetBlockFromBlocksTable(uint64(numberBlocksTable))
+	blockBlocksTable, err := db.GetEth1Store().GetBlockFromBlocksTable(r.Context(), uint64(numberBlocksTable))
 	if err != nil {
 		logger.Errorf("could not retrieve latest block from the blocks table: %v", err)
 		http.Error(w, "Internal server error: could not retrieve latest block from the blocks table", http.StatusServiceUnavailable)
@@ -132,7 +132,7 @@ func ApiHealthz(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// check if eth1 indices are up to date
-	numberDataTable, err := db.BigtableClient.GetLastBlockInDataTable()
+	numberDataTable, err := db.GetEth1Store().GetLastBlockInDataTable(r.Context())
 	if err != nil {
 		logger.Errorf("could not retrieve latest block number from the data table: %v", err)
 		http.Error(w, "Internal server error: could not retrieve latest block number from the data table", http.StatusServiceUnavailable)
@@ -563,7 +563,8 @@ func ApiSlotAttesterSlashings(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param  slot path string true "Block slot"
 // @Param  limit query string false "Limit the number of results"
-// @Param offset query string false "Offset the number of results"
+// @Param cursor query string false "Cursor returned by the previous request"
+// @Param offset query string false "Offset the number of results, deprecated in favor of the cursor"
 // @Success 200 {object} types.ApiResponse{[]APIAttestationResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/slot/{slot}/deposits [get]
@@ -573,25 +574,18 @@ func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	q := r.URL.Query()
 
-	limitQuery := q.Get("limit")
-	offsetQuery := q.Get("offset")
-
-	offset, err := strconv.ParseInt(offsetQuery, 10, 64)
-	if err != nil {
-		offset = 0
-	}
-
-	limit, err := strconv.ParseInt(limitQuery, 10, 64)
+	cursor, err := parseApiCursor(q, 100, 100, apiOrderDesc)
 	if err != nil {
-		limit = 100 + offset
-	}
-
-	if offset < 0 {
-		offset = 0
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
 	}
-
-	if limit > (100+offset) || limit <= 0 || limit <= offset {
-		limit = 100 + offset
+	// block indices start at 0, so the first page continues before any real index
+	before := int64(math.MaxInt64)
+	if key, ok, err := cursor.UintKey(); err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	} else if ok {
+		before = int64(key)
 	}
 
 	slot, err := strconv.ParseInt(vars["slot"], 10, 64)
@@ -600,7 +594,7 @@ func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT amount, block_index, block_root, block_slot, proof, publickey, signature, withdrawalcredentials FROM blocks_deposits WHERE block_slot = $1 ORDER BY block_index DESC limit $2 offset $3", slot, limit, offset)
+	rows, err := db.ReaderDb.Query("SELECT amount, block_index, block_root, block_slot, proof, publickey, signature, withdrawalcredentials FROM blocks_deposits WHERE block_slot = $1 AND block_index < $4 ORDER BY block_index DESC limit $2 offset $3", slot, cursor.Limit, cursor.Offset, before)
 	if err != nil {
 		logger.WithError(err).Error("could not retrieve db results")
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
@@ -608,7 +602,7 @@ func ApiSlotDeposits(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	returnQueryResultsAsCursorPage(rows, w, r, cursor, "block_index")
 }
 
 // ApiSlotProposerSlashings godoc
@@ -848,7 +842,7 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 	epoch := services.LatestEpoch()
 
-	g, _ := errgroup.WithContext(context.Background())
+	g, _ := errgroup.WithContext(r.Context())
 	var validatorsData []interface{}
 	var validatorEffectivenessData []*types.ValidatorEffectiveness
 	var rocketpoolData []interface{}
@@ -869,12 +863,12 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 
 		if len(queryIndices) > 0 {
 			g.Go(func() error {
-				validatorsData, err = validators(queryIndices)
+				validatorsData, err = validators(r.Context(), queryIndices)
 				return err
 			})
 
 			g.Go(func() error {
-				validatorEffectivenessData, err = validatorEffectiveness(epoch-1, queryIndices)
+				validatorEffectivenessData, err = validatorEffectiveness(r.Context(), epoch-1, queryIndices)
 				return err
 			})
 
@@ -884,7 +878,7 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 			})
 
 			g.Go(func() error {
-				executionPerformance, err = getValidatorExecutionPerformance(queryIndices)
+				executionPerformance, err = getValidatorExecutionPerformance(r.Context(), queryIndices)
 				return err
 			})
 
@@ -901,7 +895,7 @@ func ApiDashboard(w http.ResponseWriter, r *http.Request) {
 			})
 
 			g.Go(func() error {
-				syncCommitteeStats, err = getSyncCommitteeStatistics(queryIndices, epoch)
+				syncCommitteeStats, err = getSyncCommitteeStatistics(r.Context(), queryIndices, epoch)
 				return err
 			})
 		}
@@ -966,7 +960,7 @@ func getSyncCommitteeFor(validators []uint64, period uint64) ([]interface{}, err
 	return utils.SqlRowsToJSON(rows)
 }
 
-func getSyncCommitteeStatistics(validators []uint64, epoch uint64) (*SyncCommitteesInfo, error) {
+func getSyncCommitteeStatistics(ctx context.Context, validators []uint64, epoch uint64) (*SyncCommitteesInfo, error) {
 	if epoch < utils.Config.Chain.Config.AltairForkEpoch {
 		// no sync committee duties before altair fork
 		return &SyncCommitteesInfo{}, nil
@@ -982,7 +976,7 @@ func getSyncCommitteeStatistics(validators []uint64, epoch uint64) (*SyncCommitt
 		return nil, err
 	}
 
-	stats, err := getSyncCommitteeSlotsStatistics(validators, epoch)
+	stats, err := getSyncCommitteeSlotsStatistics(ctx, validators, epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -1092,7 +1086,7 @@ func getExpectedSyncCommitteeSlots(validators []uint64, epoch uint64) (expectedS
 	return expectedSlots, nil
 }
 
-func getSyncCommitteeSlotsStatistics(validators []uint64, epoch uint64) (types.SyncCommitteesStats, error) {
+func getSyncCommitteeSlotsStatistics(ctx context.Context, validators []uint64, epoch uint64) (types.SyncCommitteesStats, error) {
 	// collect aggregated sync committee stats from validator_stats table for all validators
 	var syncStats struct {
 		Participated int64 `db:"participated"`
@@ -1148,7 +1142,7 @@ func getSyncCommitteeSlotsStatistics(validators []uint64, epoch uint64) (types.S
 				vs = append(vs, uint64(v))
 			}
 
-			syncStats, err := db.BigtableClient.GetValidatorSyncCommitteesStats(vs, lastExportedEpoch, epoch)
+			syncStats, err := db.BigtableClient.GetValidatorSyncCommitteesStats(ctx, vs, lastExportedEpoch, epoch)
 			if err != nil {
 				return retv, fmt.Errorf("error retrieving validator sync participations data from bigtable: %v", err)
 			}
@@ -1242,7 +1236,7 @@ func getRocketpoolValidators(queryIndices []uint64) ([]interface{}, error) {
 	return utils.SqlRowsToJSON(rows)
 }
 
-func validators(queryIndices []uint64) ([]interface{}, error) {
+func validators(ctx context.Context, queryIndices []uint64) ([]interface{}, error) {
 	rows, err := db.ReaderDb.Query(`
 	SELECT 
 		validators.validatorindex,
@@ -1285,12 +1279,12 @@ func validators(queryIndices []uint64) ([]interface{}, error) {
 		return nil, fmt.Errorf("error converting validators to json: %w", err)
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, services.LatestEpoch(), services.LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(ctx, queryIndices, services.LatestEpoch(), services.LatestEpoch())
 	if err != nil {
 		return nil, fmt.Errorf("error getting validator balances from bigtable: %w", err)
 	}
 
-	currentDayIncome, _, err := db.GetCurrentDayClIncome(queryIndices)
+	currentDayIncome, _, err := db.GetCurrentDayClIncome(ctx, queryIndices)
 	if err != nil {
 		return nil, err
 	}
@@ -1324,8 +1318,8 @@ func validators(queryIndices []uint64) ([]interface{}, error) {
 	return data, nil
 }
 
-func validatorEffectiveness(epoch uint64, indices []uint64) ([]*types.ValidatorEffectiveness, error) {
-	data, err := db.BigtableClient.GetValidatorEffectiveness(indices, epoch)
+func validatorEffectiveness(ctx context.Context, epoch uint64, indices []uint64) ([]*types.ValidatorEffectiveness, error) {
+	data, err := db.BigtableClient.GetValidatorEffectiveness(ctx, indices, epoch)
 	if err != nil {
 		return nil, fmt.Errorf("error getting validator effectiveness from bigtable: %w", err)
 	}
@@ -1458,7 +1452,7 @@ func apiValidator(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, services.LatestEpoch(), services.LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), queryIndices, services.LatestEpoch(), services.LatestEpoch())
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve validator balance data")
 		return
@@ -1616,7 +1610,8 @@ func ApiValidatorDailyStats(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param  eth1address path string true "Eth1 address from which the validator deposits were sent"
 // @Param limit query string false "Limit the number of results (default: 2000)"
-// @Param offset query string false "Offset the results (default: 0)"
+// @Param cursor query string false "Cursor returned by the previous request"
+// @Param offset query string false "Offset the results, deprecated in favor of the cursor (default: 0)"
 // @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorEth1Response}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/eth1/{eth1address} [get]
@@ -1624,26 +1619,19 @@ func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	q := r.URL.Query()
-	limitQuery := q.Get("limit")
-	offsetQuery := q.Get("offset")
 
-	limit, err := strconv.ParseInt(limitQuery, 10, 64)
+	cursor, err := parseApiCursor(q, 2000, 2000, apiOrderAsc)
 	if err != nil {
-		limit = 2000
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
 	}
-
-	offset, err := strconv.ParseInt(offsetQuery, 10, 64)
+	// deposits of unknown validators have no index, so the rows lack a unique sort key and the cursor stores the position in the list
+	offset, _, err := cursor.UintKey()
 	if err != nil {
-		offset = 0
-	}
-
-	if offset < 0 {
-		offset = 0
-	}
-
-	if limit > (2000+offset) || limit <= 0 || limit <= offset {
-		limit = 2000 + offset
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
 	}
+	offset += cursor.Offset
 
 	vars := mux.Vars(r)
 
@@ -1653,14 +1641,14 @@ func ApiValidatorByEth1Address(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.ReaderDb.Query("SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex OFFSET $2 LIMIT $3;", eth1Address, offset, limit)
+	rows, err := db.ReaderDb.Query("SELECT publickey, validatorindex, valid_signature FROM eth1_deposits LEFT JOIN validators ON eth1_deposits.publickey = validators.pubkey WHERE from_address = $1 GROUP BY publickey, validatorindex, valid_signature ORDER BY validatorindex, publickey OFFSET $2 LIMIT $3;", eth1Address, offset, cursor.Limit)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
 	defer rows.Close()
 
-	returnQueryResultsAsArray(rows, w, r)
+	returnQueryResultsAsCursorPage(rows, w, r, &apiCursor{Key: strconv.FormatUint(offset, 10), Limit: cursor.Limit, Order: cursor.Order}, "")
 }
 
 // ApiValidator godoc
@@ -1690,7 +1678,7 @@ func ApiValidatorIncomeDetailsHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(queryIndices, services.LatestEpoch()-101, services.LatestEpoch())
+	history, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(r.Context(), queryIndices, services.LatestEpoch()-101, services.LatestEpoch())
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
@@ -1876,7 +1864,8 @@ func ApiValidatorBlsChange(w http.ResponseWriter, r *http.Request) {
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
 // @Param  latest_epoch query int false "The latest epoch to consider in the query"
-// @Param  offset query int false "Number of items to skip"
+// @Param  cursor query string false "Cursor returned by the previous request"
+// @Param  offset query int false "Number of epochs to skip"
 // @Param  limit query int false "Maximum number of items to return, up to 100"
 // @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorBalanceHistoryResponse}
 // @Failure 400 {object} types.ApiResponse
@@ -1895,6 +1884,23 @@ func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the key of the cursor is the latest epoch of the next page
+	cursor, err := parseApiCursor(r.URL.Query(), limit, limit, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	if key, ok, err := cursor.UintKey(); err != nil || (ok && key > latestEpoch) {
+		sendErrorResponse(w, r.URL.String(), "invalid cursor parameter")
+		return
+	} else if ok {
+		latestEpoch = key
+	}
+	startEpoch := uint64(0)
+	if latestEpoch >= limit {
+		startEpoch = latestEpoch - (limit - 1)
+	}
+
 	queryIndices, err := parseApiValidatorParamToIndices(vars["indexOrPubkey"], maxValidators)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), err.Error())
@@ -1905,7 +1911,7 @@ func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
 		sendErrorResponse(w, r.URL.String(), "no or invalid validator indicies provided")
 	}
 
-	history, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, latestEpoch-(limit-1), latestEpoch)
+	history, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), queryIndices, startEpoch, latestEpoch)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
@@ -1936,10 +1942,16 @@ func ApiValidatorBalanceHistory(w http.ResponseWriter, r *http.Request) {
 		return responseData[i].Validatorindex < responseData[j].Validatorindex
 	})
 
+	nextEpoch := ""
+	if startEpoch > 0 {
+		nextEpoch = strconv.FormatUint(startEpoch-1, 10)
+	}
+
 	response := &types.ApiResponse{}
 	response.Status = "OK"
 
 	response.Data = responseData
+	response.Cursor = cursor.Next(nextEpoch, int(limit))
 
 	err = j.Encode(response)
 
@@ -2029,14 +2041,14 @@ func ApiValidatorPerformance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	currentDayIncome, _, err := db.GetCurrentDayClIncome(queryIndices)
+	currentDayIncome, _, err := db.GetCurrentDayClIncome(r.Context(), queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "error retrieving current day income")
 		return
 	}
 
 	latestEpoch := int64(services.LatestFinalizedEpoch())
-	latestBalances, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, uint64(latestEpoch), uint64(latestEpoch))
+	latestBalances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), queryIndices, uint64(latestEpoch), uint64(latestEpoch))
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "error retrieving balances")
 		return
@@ -2115,7 +2127,7 @@ func ApiValidatorExecutionPerformance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := getValidatorExecutionPerformance(queryIndices)
+	result, err := getValidatorExecutionPerformance(r.Context(), queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), err.Error())
 		logger.WithError(err).Error("can not getValidatorExecutionPerformance")
@@ -2148,7 +2160,7 @@ func ApiValidatorAttestationEffectiveness(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	data, err := validatorEffectiveness(services.LatestEpoch()-1, queryIndices)
+	data, err := validatorEffectiveness(r.Context(), services.LatestEpoch()-1, queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
@@ -2190,7 +2202,7 @@ func ApiValidatorAttestationEfficiency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := validatorEffectiveness(services.LatestEpoch()-1, queryIndices)
+	data, err := validatorEffectiveness(r.Context(), services.LatestEpoch()-1, queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
@@ -2293,12 +2305,20 @@ func ApiValidatorDeposits(w http.ResponseWriter, r *http.Request) {
 	returnQueryResultsAsArray(rows, w, r)
 }
 
+// apiAttestationsMaxDays limits the range of the day resolution of ApiValidatorAttestations
+const apiAttestationsMaxDays = 365
+
 // ApiValidatorAttestations godoc
-// @Summary Get all attestations during the last 10 epochs for up to 100 validators
+// @Summary Get the attestations of up to 100 validators for a range of epochs, per epoch or downsampled to days
 // @Tags Validator
+// @Description Returns the attestations of the last 100 epochs by default. With the epoch resolution at most one day of epochs can be requested, longer ranges have to use the day resolution which returns the attestation results per validator and finalized day.
 // @Produce  json
 // @Param  indexOrPubkey path string true "Up to 100 validator indicesOrPubkeys, comma separated"
-// @Success 200 {object} types.ApiResponse{[]types.ApiValidatorAttestationsResponse}
+// @Param  from_epoch query int false "First epoch of the range (default: 100 epochs before to_epoch)"
+// @Param  to_epoch query int false "Last epoch of the range (default: latest epoch)"
+// @Param  resolution query string false "epoch (default) or day"
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorAttestationsResponse}
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiValidatorDailyAttestationsResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/{indexOrPubkey}/attestations [get]
 func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
@@ -2307,6 +2327,7 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 
 	j := json.NewEncoder(w)
 	vars := mux.Vars(r)
+	q := r.URL.Query()
 	maxValidators := getUserPremium(r).MaxValidators
 
 	queryIndices, err := parseApiValidatorParamToIndices(vars["indexOrPubkey"], maxValidators)
@@ -2315,13 +2336,68 @@ func ApiValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	history, err := db.BigtableClient.GetValidatorAttestationHistory(queryIndices, services.LatestEpoch()-101, services.LatestEpoch())
+	latestEpoch := services.LatestEpoch()
+	toEpoch := latestEpoch
+	if q.Get("to_epoch") != "" {
+		toEpoch, err = strconv.ParseUint(q.Get("to_epoch"), 10, 64)
+		if err != nil {
+			sendErrorResponse(w, r.URL.String(), "invalid to_epoch parameter")
+			return
+		}
+		if toEpoch > latestEpoch {
+			toEpoch = latestEpoch
+		}
+	}
+	fromEpoch := uint64(0)
+	if toEpoch > 100 {
+		fromEpoch = toEpoch - 100
+	}
+	if q.Get("from_epoch") != "" {
+		fromEpoch, err = strconv.ParseUint(q.Get("from_epoch"), 10, 64)
+		if err != nil {
+			sendErrorResponse(w, r.URL.String(), "invalid from_epoch parameter")
+			return
+		}
+		if fromEpoch > toEpoch {
+			sendErrorResponse(w, r.URL.String(), "from_epoch must not be greater than to_epoch")
+			return
+		}
+	}
+
+	epochsPerDay := utils.EpochsPerDay()
+	switch q.Get("resolution") {
+	case "", "epoch":
+	case "day":
+		fromDay, toDay := fromEpoch/epochsPerDay, toEpoch/epochsPerDay
+		if toDay-fromDay >= apiAttestationsMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("at most %v days can be requested", apiAttestationsMaxDays))
+			return
+		}
+		data, err := db.GetValidatorsDailyAttestations(queryIndices, fromDay, toDay)
+		if err != nil {
+			logger.WithError(err).Errorf("error getting daily attestations route: %v", r.URL.String())
+			sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
+			return
+		}
+		sendOKResponse(j, r.URL.String(), []interface{}{data})
+		return
+	default:
+		sendErrorResponse(w, r.URL.String(), "invalid resolution parameter, must be epoch or day")
+		return
+	}
+
+	if toEpoch-fromEpoch >= epochsPerDay {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("at most %v epochs can be requested with the epoch resolution, use resolution=day for longer ranges", epochsPerDay))
+		return
+	}
+
+	history, err := db.BigtableClient.GetValidatorAttestationHistory(r.Context(), queryIndices, fromEpoch, toEpoch)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not retrieve db results")
 		return
 	}
 
-	responseData := make([]*types.ApiValidatorAttestationsResponse, 0, len(history)*101)
+	responseData := make([]*types.ApiValidatorAttestationsResponse, 0, len(history)*int(toEpoch-fromEpoch+1))
 
 	epochsPerWeek := utils.EpochsPerDay() * 7
 	for validatorIndex, balances := range history {
@@ -2889,6 +2965,8 @@ type PremiumUser struct {
 	MaxValidators          int
 	MaxStats               uint64
 	MaxNodes               uint64
+	MaxAddressLabels       uint64
+	MaxAlertRules          int
 	WidgetSupport          bool
 	NotificationThresholds bool
 	NoAds                  bool
@@ -2918,6 +2996,8 @@ func GetUserPremiumByPackage(pkg string) PremiumUser {
 		MaxValidators:          100,
 		MaxStats:               180,
 		MaxNodes:               1,
+		MaxAddressLabels:       100,
+		MaxAlertRules:          5,
 		WidgetSupport:          false,
 		NotificationThresholds: false,
 		NoAds:                  false,
@@ -2929,6 +3009,8 @@ func GetUserPremiumByPackage(pkg string) PremiumUser {
 
 	result.Package = pkg
 	result.MaxStats = 43200
+	result.MaxAddressLabels = 10000
+	result.MaxAlertRules = 50
 	result.NotificationThresholds = true
 	result.NoAds = true
 
@@ -2942,6 +3024,7 @@ func GetUserPremiumByPackage(pkg string) PremiumUser {
 	if result.Package == "whale" {
 		result.MaxValidators = 300
 		result.MaxNodes = 10
+		result.MaxAddressLabels = 100000
 	}
 
 	return result
@@ -2984,7 +3067,7 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 		return
 	}
 
-	g, _ := errgroup.WithContext(context.Background())
+	g, _ := errgroup.WithContext(r.Context())
 	var rocketpoolStats []any
 	var efficiencyRows *sql.Rows
 	var validatorRows *sql.Rows
@@ -3041,13 +3124,13 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 		return
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(queryIndices, uint64(epoch), uint64(epoch))
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), queryIndices, uint64(epoch), uint64(epoch))
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "error retrieving validator balance data")
 		return
 	}
 
-	currentDayIncome, _, err := db.GetCurrentDayClIncome(queryIndices)
+	currentDayIncome, _, err := db.GetCurrentDayClIncome(r.Context(), queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "error retrieving current day income")
 		return
@@ -3078,7 +3161,7 @@ func GetMobileWidgetStats(w http.ResponseWriter, r *http.Request, indexOrPubkey
 		}
 	}
 
-	efficiencyData, err := validatorEffectiveness(services.LatestEpoch()-1, queryIndices)
+	efficiencyData, err := validatorEffectiveness(r.Context(), services.LatestEpoch()-1, queryIndices)
 	if err != nil {
 		sendErrorResponse(w, r.URL.String(), "could not parse db results")
 		return
@@ -3250,21 +3333,21 @@ func ClientStats(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	system, err := db.BigtableClient.GetMachineMetricsSystem(claims.UserID, int(limit), int(offset))
+	system, err := db.BigtableClient.GetMachineMetricsSystem(r.Context(), claims.UserID, int(limit), int(offset))
 	if err != nil {
 		logger.Errorf("sytem stat error : %v", err)
 		sendErrorResponse(w, r.URL.String(), "could not retrieve system stats from db")
 		return
 	}
 
-	validator, err := db.BigtableClient.GetMachineMetricsValidator(claims.UserID, int(limit), int(offset))
+	validator, err := db.BigtableClient.GetMachineMetricsValidator(r.Context(), claims.UserID, int(limit), int(offset))
 	if err != nil {
 		logger.Errorf("validator stat error : %v", err)
 		sendErrorResponse(w, r.URL.String(), "could not retrieve validator stats from db")
 		return
 	}
 
-	node, err := db.BigtableClient.GetMachineMetricsNode(claims.UserID, int(limit), int(offset))
+	node, err := db.BigtableClient.GetMachineMetricsNode(r.Context(), claims.UserID, int(limit), int(offset))
 	if err != nil {
 		logger.Errorf("node stat error : %v", err)
 		sendErrorResponse(w, r.URL.String(), "could not retrieve beaconnode stats from db")
@@ -3399,7 +3482,7 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 
 	maxNodes := GetUserPremiumByPackage(userData.Product.String).MaxNodes
 
-	count, err := db.BigtableClient.GetMachineMetricsMachineCount(userData.ID)
+	count, err := db.BigtableClient.GetMachineMetricsMachineCount(r.Context(), userData.ID)
 	if err != nil {
 		logger.Errorf("Could not get max machine count| %v", err)
 		sendErrorResponse(w, r.URL.String(), "could not get machine count")
@@ -3456,7 +3539,7 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 		}
 	}
 
-	err = db.BigtableClient.SaveMachineMetric(parsedMeta.Process, userData.ID, machine, data)
+	err = db.BigtableClient.SaveMachineMetric(r.Context(), parsedMeta.Process, userData.ID, machine, data)
 	if err != nil {
 		if strings.HasPrefix(err.Error(), "rate limit") {
 			return err
@@ -3475,7 +3558,8 @@ func insertStats(userData *types.UserWithPremium, machine string, body *map[stri
 // @Produce json
 // @Param withdrawalCredentialsOrEth1address path string true "Provide a withdrawal credential or an eth1 address with an optional 0x prefix"
 // @Param  limit query int false "Limit the number of results, maximum: 200" default(10)
-// @Param offset query int false "Offset the number of results" default(0)
+// @Param cursor query string false "Cursor returned by the previous request"
+// @Param offset query int false "Offset the number of results, deprecated in favor of the cursor" default(0)
 // @Success 200 {object} types.ApiResponse{data=[]types.ApiWithdrawalCredentialsResponse}
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/validator/withdrawalCredentials/{withdrawalCredentialsOrEth1address} [get]
@@ -3502,15 +3586,20 @@ func ApiWithdrawalCredentialsValidators(w http.ResponseWriter, r *http.Request)
 		credentials = credentialsOrAddress
 	}
 
-	limitQuery := q.Get("limit")
-	offsetQuery := q.Get("offset")
-
-	offset := parseUintWithDefault(offsetQuery, 0)
-	limit := parseUintWithDefault(limitQuery, 10)
-
 	// We set a max limit to limit the request call time.
-	const maxLimit uint64 = 200
-	limit = utilMath.MinU64(limit, maxLimit)
+	cursor, err := parseApiCursor(q, 10, 200, apiOrderAsc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	// validator indices start at 0, so the first page continues after -1
+	after := int64(-1)
+	if key, ok, err := cursor.UintKey(); err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	} else if ok {
+		after = int64(key)
+	}
 
 	result := []struct {
 		Index  uint64 `db:"validatorindex"`
@@ -3522,10 +3611,11 @@ func ApiWithdrawalCredentialsValidators(w http.ResponseWriter, r *http.Request)
 		validatorindex,
 		pubkey
 	FROM validators
-	WHERE withdrawalcredentials = $1
+	WHERE withdrawalcredentials = $1 AND validatorindex > $4
+	ORDER BY validatorindex
 	LIMIT $2
 	OFFSET $3
-	`, credentials, limit, offset)
+	`, credentials, cursor.Limit, cursor.Offset, after)
 
 	if err != nil {
 		logger.Warnf("error retrieving validator data from db: %v", err)
@@ -3534,14 +3624,16 @@ func ApiWithdrawalCredentialsValidators(w http.ResponseWriter, r *http.Request)
 	}
 
 	response := make([]*types.ApiWithdrawalCredentialsResponse, 0, len(result))
+	lastIndex := ""
 	for _, validator := range result {
 		response = append(response, &types.ApiWithdrawalCredentialsResponse{
 			Publickey:      fmt.Sprintf("%#x", validator.Pubkey),
 			ValidatorIndex: validator.Index,
 		})
+		lastIndex = strconv.FormatUint(validator.Index, 10)
 	}
 
-	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.Next(lastIndex, len(response)))
 }
 
 func DecodeMapStructure(input interface{}, output interface{}) error {
@@ -3592,7 +3684,7 @@ func APIDashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 		sendErrorResponse(w, r.URL.String(), "no or invalid validator indicies provided")
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(queryValidators, latestEpoch-queryOffsetEpoch, latestEpoch)
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), queryValidators, latestEpoch-queryOffsetEpoch, latestEpoch)
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance history")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -3698,6 +3790,35 @@ func returnQueryResultsAsArray(rows *sql.Rows, w http.ResponseWriter, r *http.Re
 	}
 }
 
+// returnQueryResultsAsCursorPage returns a page of a paginated list like returnQueryResultsAsArray together with the cursor of the next page.
+// The cursor continues after the keyColumn value of the last row, if keyColumn is empty the key of the cursor is the position in the list.
+func returnQueryResultsAsCursorPage(rows *sql.Rows, w http.ResponseWriter, r *http.Request, cursor *apiCursor, keyColumn string, adjustQueryEntriesFuncs ...func(map[string]interface{}) error) {
+	data, err := utils.SqlRowsToJSON(rows)
+
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "could not parse db results")
+		return
+	}
+
+	lastKey := ""
+	if len(data) > 0 {
+		if keyColumn == "" {
+			position, _, _ := cursor.UintKey()
+			lastKey = strconv.FormatUint(position+uint64(len(data)), 10)
+		} else if row, ok := data[len(data)-1].(map[string]interface{}); ok {
+			lastKey = fmt.Sprintf("%v", row[keyColumn])
+		}
+	}
+
+	err = adjustQueryResults(data, adjustQueryEntriesFuncs...)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "could not adjust query results")
+		return
+	}
+
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), data, cursor.Next(lastKey, len(data)))
+}
+
 func adjustQueryResults(data []interface{}, adjustQueryEntriesFuncs ...func(map[string]interface{}) error) error {
 	for _, dataEntry := range data {
 		dataEntryMap, ok := dataEntry.(map[string]interface{})
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const maxAddressLabelLength = 100
+
+// an import of the largest label allowance is well below this size
+const maxAddressLabelImportBytes = 16 * 1024 * 1024
+
+// ApiUserAddressLabelsExport godoc
+// @Summary Export the private address labels of the authenticated user
+// @Tags User
+// @Produce json,text/csv
+// @Param format query string false "Export format, either json (default) or csv"
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiAddressLabel}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/labels [get]
+func ApiUserAddressLabelsExport(w http.ResponseWriter, r *http.Request) {
+	claims := getAuthClaims(r)
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorResponse(w, r.URL.String(), "error invalid format, supported formats are json and csv")
+		return
+	}
+
+	labels, err := db.GetUserAddressLabels(claims.UserID)
+	if err != nil {
+		logger.Errorf("error getting address labels route: %v err: %v", r.URL.String(), err)
+		w.Header().Set("Content-Type", "application/json")
+		sendServerErrorResponse(w, r.URL.String(), "error getting address labels")
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=address_labels.csv")
+		writer := csv.NewWriter(w)
+		err = writer.Write([]string{"address", "label"})
+		for _, l := range labels {
+			if err != nil {
+				break
+			}
+			err = writer.Write([]string{fmt.Sprintf("0x%x", l.Address), l.Label})
+		}
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		if err != nil {
+			logger.WithError(err).WithField("route", r.URL.String()).Error("error writing response")
+		}
+		return
+	}
+
+	data := make([]types.ApiAddressLabel, 0, len(labels))
+	for _, l := range labels {
+		data = append(data, types.ApiAddressLabel{Address: fmt.Sprintf("0x%x", l.Address), Label: l.Label})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
+
+// ApiUserAddressLabelsImport godoc
+// @Summary Import private address labels for the authenticated user
+// @Tags User
+// @Description Accepts a json array of labels or, if the content type is text/csv, a csv file with the columns address and label. Labels of already labeled addresses are overwritten, if an address occurs multiple times the last label wins.
+// @Accept json,text/csv
+// @Produce json
+// @Param labels body []types.ApiAddressLabel true "Address labels"
+// @Param replace query bool false "Remove all existing labels that are not part of the import"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/labels [post]
+func ApiUserAddressLabelsImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+	replace := r.URL.Query().Get("replace") == "true"
+
+	var entries []types.ApiAddressLabel
+	var err error
+	body := http.MaxBytesReader(w, r.Body, maxAddressLabelImportBytes)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv") {
+		entries, err = readAddressLabelsCsv(body)
+	} else {
+		err = json.NewDecoder(body).Decode(&entries)
+	}
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error reading labels: %v", err))
+		return
+	}
+
+	labels, err := parseAddressLabels(entries)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	maxLabels := getUserPremium(r).MaxAddressLabels
+	total := uint64(len(labels))
+	if !replace {
+		existing, err := db.GetUserAddressLabelCount(claims.UserID)
+		if err != nil {
+			logger.Errorf("error getting address label count route: %v err: %v", r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error importing address labels")
+			return
+		}
+		// overwritten labels are counted twice, the estimate is only off for users close to their limit
+		total += existing
+	}
+	if total > maxLabels {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error too many labels, your plan allows up to %v labels", maxLabels))
+		return
+	}
+
+	err = db.SaveUserAddressLabels(claims.UserID, labels, replace)
+	if err != nil {
+		logger.Errorf("error saving address labels route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error importing address labels")
+		return
+	}
+
+	OKResponse(w, r)
+}
+
+// readAddressLabelsCsv reads address,label rows, a leading header row is skipped
+func readAddressLabelsCsv(r io.Reader) ([]types.ApiAddressLabel, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > 0 && strings.EqualFold(records[0][0], "address") {
+		records = records[1:]
+	}
+
+	entries := make([]types.ApiAddressLabel, 0, len(records))
+	for _, record := range records {
+		entries = append(entries, types.ApiAddressLabel{Address: record[0], Label: record[1]})
+	}
+	return entries, nil
+}
+
+// parseAddressLabels validates the imported labels and removes duplicate addresses, keeping the last label of an address
+func parseAddressLabels(entries []types.ApiAddressLabel) ([]*types.AddressLabel, error) {
+	labels := make([]*types.AddressLabel, 0, len(entries))
+	indexByAddress := make(map[string]int, len(entries))
+	for i, e := range entries {
+		address := strings.ToLower(strings.Replace(strings.TrimSpace(e.Address), "0x", "", -1))
+		if !utils.IsEth1Address(address) {
+			return nil, fmt.Errorf("error invalid address %q in entry %v", e.Address, i+1)
+		}
+		label := strings.TrimSpace(e.Label)
+		if label == "" || utf8.RuneCountInString(label) > maxAddressLabelLength {
+			return nil, fmt.Errorf("error invalid label in entry %v, labels must have between 1 and %v characters", i+1, maxAddressLabelLength)
+		}
+
+		if idx, exists := indexByAddress[address]; exists {
+			labels[idx].Label = label
+			continue
+		}
+		indexByAddress[address] = len(labels)
+		labels = append(labels, &types.AddressLabel{Address: common.FromHex(address), Label: label})
+	}
+	return labels, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+// ApiUserAlertRuleMetrics godoc
+// @Summary Lists the metrics, comparators and operators that can be used to build alert rules
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=types.ApiAlertRuleBuilderResponse}
+// @Security ApiKeyAuth
+// @Router /api/v1/user/alertrules/metrics [get]
+func ApiUserAlertRuleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data := types.ApiAlertRuleBuilderResponse{
+		Metrics: []types.ApiAlertRuleMetric{
+			{Metric: types.AlertMetricValidatorBalanceDecrease, Target: "validator index", Unit: "ETH", Description: "Decrease of the validator balance within the window"},
+			{Metric: types.AlertMetricValidatorMissedAttestations, Target: "validator index", Description: "Number of missed attestations within the window"},
+			{Metric: types.AlertMetricValidatorMissedProposals, Target: "validator index", Description: "Number of missed proposals within the window"},
+			{Metric: types.AlertMetricAddressReceivedFromNewCounterparty, Target: "address", Unit: "ETH", Description: "Largest amount received within the window from a counterparty without transfers in the previous 30 days"},
+		},
+		Comparators: []string{"gt", "gte", "lt", "lte", "eq"},
+		Operators:   []string{types.AlertOperatorAnd, types.AlertOperatorOr, types.AlertOperatorNot},
+		MaxWindow:   types.MaxAlertRuleWindow,
+		MaxDepth:    types.MaxAlertConditionDepth,
+		MaxLeaves:   types.MaxAlertConditionLeaves,
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
+
+// ApiUserAlertRules godoc
+// @Summary Lists the alert rules of the authenticated user
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=[]types.AlertRule}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/alertrules [get]
+func ApiUserAlertRules(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	rules, err := db.GetUserAlertRules(claims.UserID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error getting alert rules route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting alert rules")
+		return
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{rules})
+}
+
+// ApiUserAlertRuleCreate godoc
+// @Summary Creates an alert rule for the authenticated user
+// @Tags User
+// @Description The condition is a tree of and, or and not operators whose leaves compare a metric of a validator or address against a threshold. The metrics are aggregated over the window of the rule, a triggered rule is not triggered again within its window.
+// @Accept json
+// @Produce json
+// @Param rule body types.AlertRule true "Name, condition and window in epochs of the rule"
+// @Success 200 {object} types.ApiResponse{data=types.AlertRule}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/alertrules [post]
+func ApiUserAlertRuleCreate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	rule := &types.AlertRule{}
+	err := json.NewDecoder(r.Body).Decode(rule)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid rule, could not parse body")
+		return
+	}
+	rule.UserID = claims.UserID
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Name == "" || utf8.RuneCountInString(rule.Name) > 100 {
+		sendErrorResponse(w, r.URL.String(), "error invalid name, names must have between 1 and 100 characters")
+		return
+	}
+	if rule.Window == 0 || rule.Window > types.MaxAlertRuleWindow {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid window, the window must be between 1 and %v epochs", types.MaxAlertRuleWindow))
+		return
+	}
+	err = rule.Condition.Validate()
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid condition: %v", err))
+		return
+	}
+
+	rules, err := db.GetUserAlertRules(claims.UserID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error getting alert rules route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating alert rule")
+		return
+	}
+	maxRules := getUserPremium(r).MaxAlertRules
+	if len(rules) >= maxRules {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error too many rules, your plan allows up to %v alert rules", maxRules))
+		return
+	}
+
+	err = db.CreateAlertRule(rule, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error creating alert rule route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating alert rule")
+		return
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{rule})
+}
+
+// ApiUserAlertRuleDelete godoc
+// @Summary Deletes an alert rule of the authenticated user
+// @Tags User
+// @Produce json
+// @Param id path int true "Rule id"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/alertrules/{id} [delete]
+func ApiUserAlertRuleDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	ruleID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid rule id")
+		return
+	}
+
+	deleted, err := db.DeleteAlertRule(claims.UserID, ruleID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error deleting alert rule route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error deleting alert rule")
+		return
+	}
+	if !deleted {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error rule not found", http.StatusNotFound)
+		return
+	}
+	OKResponse(w, r)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/services"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const validatorBenchmarkMaxDays = 31
+
+// ApiValidatorBenchmark godoc
+// @Summary Compare the daily consensus layer income and attestation participation of a set of validators against the network wide distribution. Only validators that were active during the whole day are considered. The percentile is the average rank of the validator set within the network (0-100, higher is better).
+// @Tags Validator
+// @Produce  json
+// @Param  validators query string true "Up to 100 validator indicesOrPubkeys, comma separated"
+// @Param  days query int false "Number of past days to compare, defaults to 7, maximum 31"
+// @Success 200 {object} types.ApiResponse{data=types.APIValidatorBenchmark}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/benchmark [get]
+func ApiValidatorBenchmark(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	q := r.URL.Query()
+
+	validators, err := parseApiValidatorParamToIndices(q.Get("validators"), 100)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "invalid validators provided: "+err.Error())
+		return
+	}
+
+	days := uint64(7)
+	if q.Get("days") != "" {
+		days, err = strconv.ParseUint(q.Get("days"), 10, 64)
+		if err != nil || days < 1 || days > validatorBenchmarkMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", validatorBenchmarkMaxDays))
+			return
+		}
+	}
+
+	benchmark, err := services.GetValidatorBenchmark(validators, days)
+	if err != nil {
+		logger.Errorf("error getting validator benchmark route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting validator benchmark")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{benchmark})
+}
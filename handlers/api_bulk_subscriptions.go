@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxBulkSubscriptions      = 1000
+	maxBulkSubscriptionsBytes = 1 << 20
+	maxIdempotencyKeyLength   = 100
+)
+
+// ApiUserSubscriptionsBulkSave godoc
+// @Summary Create or update notification subscriptions of the authenticated user in bulk
+// @Tags User
+// @Description Subscribes up to 1000 validators or rocket pool nodes to notification events in a single transaction, the thresholds of existing subscriptions are updated. Either all or none of the subscriptions are saved. Requests with an Idempotency-Key header are only applied once within 24 hours, repeating them returns the response of the first request.
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key of up to 100 characters that identifies the request"
+// @Param request body types.ApiBulkSubscriptionsRequest true "Subscriptions"
+// @Success 200 {object} types.ApiResponse{data=types.ApiBulkSubscriptionsResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 422 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/bulk [post]
+func ApiUserSubscriptionsBulkSave(w http.ResponseWriter, r *http.Request) {
+	handleBulkSubscriptions(w, r, true)
+}
+
+// ApiUserSubscriptionsBulkDelete godoc
+// @Summary Delete notification subscriptions of the authenticated user in bulk
+// @Tags User
+// @Description Removes up to 1000 subscriptions in a single request, the thresholds of the subscriptions are ignored. Requests with an Idempotency-Key header are only applied once within 24 hours, repeating them returns the response of the first request.
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key of up to 100 characters that identifies the request"
+// @Param request body types.ApiBulkSubscriptionsRequest true "Subscriptions"
+// @Success 200 {object} types.ApiResponse{data=types.ApiBulkSubscriptionsResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 422 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/notifications/bulk/delete [post]
+func ApiUserSubscriptionsBulkDelete(w http.ResponseWriter, r *http.Request) {
+	handleBulkSubscriptions(w, r, false)
+}
+
+func handleBulkSubscriptions(w http.ResponseWriter, r *http.Request, save bool) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBulkSubscriptionsBytes))
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error reading request")
+		return
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	var requestHash []byte
+	if key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error the idempotency key must not be longer than %v characters", maxIdempotencyKeyLength))
+			return
+		}
+		hash := sha256.Sum256(append([]byte(r.Method+" "+r.URL.Path+"\n"), body...))
+		requestHash = hash[:]
+
+		stored, err := db.GetUserIdempotentResponse(claims.UserID, key, requestHash)
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			sendErrorWithCodeResponse(w, r.URL.String(), "error the idempotency key has already been used for a different request", http.StatusUnprocessableEntity)
+			return
+		}
+		if err != nil {
+			logger.Errorf("error getting idempotency key route: %v err: %v", r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error saving subscriptions")
+			return
+		}
+		if stored != nil {
+			w.Header().Set("Idempotent-Replayed", "true")
+			sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{stored})
+			return
+		}
+	}
+
+	req := types.ApiBulkSubscriptionsRequest{}
+	err = json.Unmarshal(body, &req)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error could not parse request")
+		return
+	}
+	subscriptions, err := parseBulkSubscriptions(req.Subscriptions, getUserPremium(r), save)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	response := types.ApiBulkSubscriptionsResponse{}
+	if save {
+		response.Created, response.Updated, err = db.SaveUserSubscriptions(claims.UserID, subscriptions)
+	} else {
+		response.Deleted, err = db.DeleteUserSubscriptions(claims.UserID, subscriptions)
+	}
+	if err != nil {
+		logger.Errorf("error saving subscriptions in bulk route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error saving subscriptions")
+		return
+	}
+
+	if key != "" {
+		// the subscriptions have been saved, repeating the request without the stored response does not change them again
+		err = db.SaveUserIdempotentResponse(claims.UserID, key, requestHash, response)
+		if err != nil {
+			logger.Errorf("error saving idempotency key route: %v err: %v", r.URL.String(), err)
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
+// parseBulkSubscriptions validates the subscriptions of a bulk request and converts them to the stored format. Only events with
+// a validator or address filter can be subscribed in bulk, duplicates are merged and the last threshold wins.
+func parseBulkSubscriptions(entries []types.ApiBulkSubscription, premium PremiumUser, save bool) ([]*db.BulkSubscription, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("error no subscriptions provided")
+	}
+	if len(entries) > maxBulkSubscriptions {
+		return nil, fmt.Errorf("error too many subscriptions, at most %v subscriptions can be changed per request", maxBulkSubscriptions)
+	}
+
+	network := utils.GetNetwork()
+	subscriptions := make([]*db.BulkSubscription, 0, len(entries))
+	seen := make(map[string]*db.BulkSubscription, len(entries))
+	validators := make(map[string]bool)
+	for i, entry := range entries {
+		eventName, err := types.EventNameFromString(strings.TrimPrefix(entry.EventName, network+":"))
+		if err != nil {
+			return nil, fmt.Errorf("error subscription %v: invalid event name %v", i, entry.EventName)
+		}
+
+		filter := strings.ToLower(strings.TrimPrefix(entry.EventFilter, "0x"))
+		switch {
+		case strings.HasPrefix(string(eventName), "validator_"):
+			if b, err := hex.DecodeString(filter); err != nil || len(b) != 48 {
+				return nil, fmt.Errorf("error subscription %v: invalid validator public key %v", i, entry.EventFilter)
+			}
+			validators[filter] = true
+		case eventName == types.RocketpoolCollateralMinReached || eventName == types.RocketpoolCollateralMaxReached:
+			if !utils.IsEth1Address(filter) {
+				return nil, fmt.Errorf("error subscription %v: invalid node address %v", i, entry.EventFilter)
+			}
+		default:
+			return nil, fmt.Errorf("error subscription %v: event %v can not be subscribed in bulk", i, eventName)
+		}
+
+		threshold := entry.EventThreshold
+		if threshold < 0 {
+			return nil, fmt.Errorf("error subscription %v: invalid threshold %v", i, threshold)
+		}
+		// the same default as for single subscriptions, rocket pool thresholds are free
+		if !premium.NotificationThresholds && eventName == types.ValidatorIsOfflineEventName {
+			threshold = 3
+		}
+
+		name := network + ":" + string(eventName)
+		if existing, ok := seen[name+":"+filter]; ok {
+			existing.EventThreshold = threshold
+			continue
+		}
+		subscription := &db.BulkSubscription{EventName: name, EventFilter: filter, EventThreshold: threshold}
+		seen[name+":"+filter] = subscription
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if save && len(validators) > premium.MaxValidators {
+		return nil, fmt.Errorf("error too many validators, your plan allows notifications for up to %v validators", premium.MaxValidators)
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gorilla/mux"
+)
+
+// contractOwnershipMessage returns the message the deployer of a contract signs to authorize the user to receive its alerts
+func contractOwnershipMessage(userID uint64, contract []byte) string {
+	return fmt.Sprintf("beaconcha.in: I deployed the contract %v and authorize user %v to receive alerts on its interactions", common.BytesToAddress(contract).Hex(), userID)
+}
+
+// parseContractAddress returns the contract address of the request and its deployer, it writes an error response and returns
+// false if the address is invalid or its creation has not been indexed
+func parseContractAddress(w http.ResponseWriter, r *http.Request, address string) ([]byte, []byte, bool) {
+	if !common.IsHexAddress(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid contract address")
+		return nil, nil, false
+	}
+	contract := common.HexToAddress(address).Bytes()
+
+	creation, err := db.GetEth1Store().GetContractCreation(r.Context(), contract)
+	if err != nil {
+		logger.Errorf("error getting creation of contract %x route: %v err: %v", contract, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting contract creation")
+		return nil, nil, false
+	}
+	if creation == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error no contract creation found for this address", http.StatusNotFound)
+		return nil, nil, false
+	}
+	return contract, creation.From, true
+}
+
+// ApiUserContractOwnershipChallenge godoc
+// @Summary Returns the message the deployer of a contract has to sign to verify the ownership of the contract
+// @Tags User
+// @Produce json
+// @Param address path string true "Contract address"
+// @Success 200 {object} types.ApiResponse{data=types.ApiContractOwnershipChallenge}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/contracts/{address}/verify [get]
+func ApiUserContractOwnershipChallenge(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	contract, deployer, ok := parseContractAddress(w, r, mux.Vars(r)["address"])
+	if !ok {
+		return
+	}
+
+	data := types.ApiContractOwnershipChallenge{
+		Contract: common.BytesToAddress(contract).Hex(),
+		Deployer: common.BytesToAddress(deployer).Hex(),
+		Message:  contractOwnershipMessage(claims.UserID, contract),
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
+
+// ApiUserContractOwnershipVerify godoc
+// @Summary Verifies the ownership of a contract by a personal_sign signature of the ownership challenge by the deployer of the contract
+// @Tags User
+// @Accept json
+// @Produce json
+// @Param address path string true "Contract address"
+// @Param proof body types.ApiContractOwnershipProof true "Signature of the message returned by the challenge endpoint"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/contracts/{address}/verify [post]
+func ApiUserContractOwnershipVerify(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	contract, deployer, ok := parseContractAddress(w, r, mux.Vars(r)["address"])
+	if !ok {
+		return
+	}
+
+	proof := &types.ApiContractOwnershipProof{}
+	err := json.NewDecoder(r.Body).Decode(proof)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid proof, could not parse body")
+		return
+	}
+	sig, err := sanitizeSignature(proof.Signature)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid signature")
+		return
+	}
+	recoveredPubkey, err := crypto.SigToPub(accounts.TextHash([]byte(contractOwnershipMessage(claims.UserID, contract))), sig)
+	if err != nil || !bytes.Equal(crypto.PubkeyToAddress(*recoveredPubkey).Bytes(), deployer) {
+		sendErrorResponse(w, r.URL.String(), "error the signature has not been created by the deployer of the contract")
+		return
+	}
+
+	err = db.SaveVerifiedContract(claims.UserID, utils.GetNetwork(), contract, deployer)
+	if err != nil {
+		logger.Errorf("error verifying contract ownership route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error verifying contract ownership")
+		return
+	}
+	OKResponse(w, r)
+}
+
+func formatApiContractAlert(alert *types.ContractAlert) types.ApiContractAlert {
+	data := types.ApiContractAlert{
+		ID:              alert.ID,
+		Contract:        common.BytesToAddress(alert.Contract).Hex(),
+		Kind:            alert.Kind,
+		RevertThreshold: alert.RevertThreshold,
+		CreatedTs:       alert.CreatedTs,
+	}
+	if len(alert.MethodID) > 0 {
+		data.Method = fmt.Sprintf("0x%x", alert.MethodID)
+	}
+	return data
+}
+
+// ApiUserContractAlerts godoc
+// @Summary Lists the contract alerts of the authenticated user
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=[]types.ApiContractAlert}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/contractalerts [get]
+func ApiUserContractAlerts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	alerts, err := db.GetUserContractAlerts(claims.UserID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error getting contract alerts route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting contract alerts")
+		return
+	}
+
+	data := make([]types.ApiContractAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		data = append(data, formatApiContractAlert(alert))
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
+
+// ApiUserContractAlertCreate godoc
+// @Summary Creates an alert on the interactions with a contract whose ownership the authenticated user has verified
+// @Tags User
+// @Description A method_called alert notifies about calls of the method given as 4 byte selector. A revert_rate alert notifies once the number of reverted transactions of the contract within an hour reaches the revert threshold, it is sent at most once per hour.
+// @Accept json
+// @Produce json
+// @Param alert body types.ApiContractAlert true "Contract, kind and method or revert threshold of the alert"
+// @Success 200 {object} types.ApiResponse{data=types.ApiContractAlert}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 403 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/contractalerts [post]
+func ApiUserContractAlertCreate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	req := &types.ApiContractAlert{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid alert, could not parse body")
+		return
+	}
+	if !common.IsHexAddress(req.Contract) {
+		sendErrorResponse(w, r.URL.String(), "error invalid contract address")
+		return
+	}
+
+	alert := &types.ContractAlert{
+		UserID:   claims.UserID,
+		Contract: common.HexToAddress(req.Contract).Bytes(),
+		Kind:     req.Kind,
+	}
+	switch req.Kind {
+	case types.ContractAlertMethodCalled:
+		alert.MethodID, err = hex.DecodeString(strings.TrimPrefix(req.Method, "0x"))
+		if err != nil || len(alert.MethodID) != 4 {
+			sendErrorResponse(w, r.URL.String(), "error invalid method, the method has to be a 4 byte selector")
+			return
+		}
+	case types.ContractAlertRevertRate:
+		if req.RevertThreshold == 0 {
+			sendErrorResponse(w, r.URL.String(), "error invalid revert threshold, the threshold must be at least 1")
+			return
+		}
+		alert.RevertThreshold = req.RevertThreshold
+	default:
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid kind, the kind must be either %v or %v", types.ContractAlertMethodCalled, types.ContractAlertRevertRate))
+		return
+	}
+
+	verified, err := db.IsVerifiedContractOwner(claims.UserID, utils.GetNetwork(), alert.Contract)
+	if err != nil {
+		logger.Errorf("error checking contract ownership route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating contract alert")
+		return
+	}
+	if !verified {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error the ownership of the contract has not been verified", http.StatusForbidden)
+		return
+	}
+
+	alerts, err := db.GetUserContractAlerts(claims.UserID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error getting contract alerts route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating contract alert")
+		return
+	}
+	count := 0
+	for _, a := range alerts {
+		if bytes.Equal(a.Contract, alert.Contract) {
+			count++
+		}
+	}
+	if count >= types.MaxContractAlertsPerContract {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error too many alerts, at most %v alerts are allowed per contract", types.MaxContractAlertsPerContract))
+		return
+	}
+
+	err = db.CreateContractAlert(alert, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error creating contract alert route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating contract alert")
+		return
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{formatApiContractAlert(alert)})
+}
+
+// ApiUserContractAlertDelete godoc
+// @Summary Deletes a contract alert of the authenticated user
+// @Tags User
+// @Produce json
+// @Param id path int true "Alert id"
+// @Success 200 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/contractalerts/{id} [delete]
+func ApiUserContractAlertDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	alertID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid alert id")
+		return
+	}
+
+	deleted, err := db.DeleteContractAlert(claims.UserID, alertID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error deleting contract alert route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error deleting contract alert")
+		return
+	}
+	if !deleted {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error alert not found", http.StatusNotFound)
+		return
+	}
+	OKResponse(w, r)
+}
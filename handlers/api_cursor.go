@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/mr-tron/base58/base58"
+)
+
+const (
+	apiOrderAsc  = "asc"
+	apiOrderDesc = "desc"
+)
+
+// apiCursor is the position of a request in a paginated api list. The cursor token is opaque to the api consumers, it contains
+// the order of the list and the key of the last returned entry: the row key suffix within the index for bigtable backed lists
+// and the sort key of the row for postgres backed lists. The legacy page and offset parameters are mapped onto the cursor.
+type apiCursor struct {
+	// key of the last entry of the previous page, empty for the first page
+	Key string
+	// only set if the legacy offset parameter was used instead of a cursor
+	Offset uint64
+	Limit  uint64
+	Order  string
+}
+
+// parseApiCursor reads the cursor, limit and order parameters of a list request, orders lists the orders the endpoint supports
+// with the default one first. The legacy page parameter is accepted in place of the cursor, the legacy offset parameter is only
+// used if no cursor is given.
+func parseApiCursor(q url.Values, defaultLimit, maxLimit uint64, orders ...string) (*apiCursor, error) {
+	cursor := &apiCursor{Limit: defaultLimit, Order: orders[0]}
+
+	if q.Get("limit") != "" {
+		limit, err := strconv.ParseUint(q.Get("limit"), 10, 64)
+		if err != nil || limit == 0 {
+			return nil, fmt.Errorf("invalid limit parameter")
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+		cursor.Limit = limit
+	}
+
+	supported := func(order string) bool {
+		for _, o := range orders {
+			if o == order {
+				return true
+			}
+		}
+		return false
+	}
+
+	order := q.Get("order")
+	if order == "" {
+		// older endpoints called the order parameter sort
+		order = q.Get("sort")
+	}
+	if order != "" {
+		order = strings.ToLower(order)
+		if !supported(order) {
+			return nil, fmt.Errorf("invalid order parameter, supported orders: %v", strings.Join(orders, ", "))
+		}
+		cursor.Order = order
+	}
+
+	if token := q.Get("cursor"); token != "" {
+		decoded, err := base58.FastBase58Decoding(token)
+		if err != nil || len(decoded) == 0 || len(decoded) > 256 {
+			return nil, fmt.Errorf("invalid cursor parameter")
+		}
+		split := strings.SplitN(string(decoded), ":", 2)
+		if len(split) != 2 || !supported(split[0]) || !isApiCursorKey(split[1]) {
+			return nil, fmt.Errorf("invalid cursor parameter")
+		}
+		// the cursor continues the list in the order it was created for
+		cursor.Order = split[0]
+		cursor.Key = split[1]
+		return cursor, nil
+	}
+
+	if page := q.Get("page"); page != "" {
+		key, err := db.DecodePageToken("", page)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page parameter")
+		}
+		cursor.Key = key
+		return cursor, nil
+	}
+
+	if q.Get("offset") != "" {
+		offset, err := strconv.ParseUint(q.Get("offset"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset parameter")
+		}
+		cursor.Offset = offset
+	}
+	return cursor, nil
+}
+
+// keys consist of the same characters as the row keys of the bigtable indices
+func isApiCursorKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// RowKey returns the row key of the index with the given prefix the page starts after
+func (c *apiCursor) RowKey(prefix string) string {
+	return prefix + c.Key
+}
+
+// UintKey returns the key of a postgres backed list with a numeric sort key, ok is false for the first page
+func (c *apiCursor) UintKey() (key uint64, ok bool, err error) {
+	if c.Key == "" {
+		return 0, false, nil
+	}
+	key, err = strconv.ParseUint(c.Key, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid cursor parameter")
+	}
+	return key, true, nil
+}
+
+// Next returns the cursor of the response. The next token is only set if the page is full, lastKey is the key of the last
+// returned entry in the format of Key.
+func (c *apiCursor) Next(lastKey string, entries int) *types.ApiCursor {
+	next := &types.ApiCursor{Limit: c.Limit, Order: c.Order}
+	if lastKey != "" && uint64(entries) >= c.Limit {
+		next.Next = base58.FastBase58Encoding([]byte(c.Order + ":" + lastKey))
+	}
+	return next
+}
+
+// NextRowKey returns the cursor of a response of a bigtable backed list, lastKey is the last index row key that was read.
+// Skipped rows make pages shorter than the limit, so the next token is set whenever index rows were read.
+func (c *apiCursor) NextRowKey(prefix, lastKey string) *types.ApiCursor {
+	if !strings.HasPrefix(lastKey, prefix) {
+		return c.Next("", 0)
+	}
+	return c.Next(strings.TrimPrefix(lastKey, prefix), int(c.Limit))
+}
+
+func sendOKResponseWithCursor(j *json.Encoder, route string, data interface{}, cursor *types.ApiCursor) {
+	response := &types.ApiResponse{
+		Status: "OK",
+		Data:   data,
+		Cursor: cursor,
+	}
+	err := j.Encode(response)
+
+	if err != nil {
+		logger.Errorf("error serializing json data for API %v route: %v", route, err)
+	}
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"eth2-exporter/db"
+	"eth2-exporter/eth1data"
 	"eth2-exporter/price"
 	"eth2-exporter/services"
 	"eth2-exporter/types"
@@ -19,7 +22,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
-	"github.com/mr-tron/base58/base58"
 	"github.com/shopspring/decimal"
 	"golang.org/x/exp/maps"
 )
@@ -79,7 +81,7 @@ func ApiETH1ExecBlocks(w http.ResponseWriter, r *http.Request) {
 		blockList = append(blockList, temp)
 	}
 
-	blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(blockList, uint64(100))
+	blocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(r.Context(), blockList, uint64(100))
 	if err != nil {
 		logger.Errorf("Can not retrieve blocks from bigtable %v", err)
 		sendErrorResponse(w, r.URL.String(), "can not retrieve blocks from bigtable")
@@ -105,16 +107,233 @@ func ApiETH1ExecBlocks(w http.ResponseWriter, r *http.Request) {
 	sendOKResponse(j, r.URL.String(), []interface{}{results})
 }
 
+// ApiEth1TxByHash godoc
+// @Summary Get an execution transaction by its hash
+// @Tags Execution
+// @Description Returns an indexed execution transaction together with the logs it emitted and the internal transactions of its trace. Values are denominated in ether, gas prices in gwei.
+// @Produce json
+// @Param hash path string true "Transaction hash, an optional 0x prefix followed by 64 hexadecimal characters"
+// @Success 200 {object} types.ApiResponse{data=types.APIEth1TxResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Router /api/v1/execution/tx/{hash} [get]
+func ApiEth1TxByHash(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+
+	txHash, err := hex.DecodeString(strings.Replace(vars["hash"], "0x", "", -1))
+	if err != nil || len(txHash) != 32 {
+		sendErrorResponse(w, r.URL.String(), "error invalid tx hash. A transaction hash consists of an optional 0x prefix followed by 64 hexadecimal characters.")
+		return
+	}
+
+	data, err := db.GetEth1Store().GetEth1TxByHash(r.Context(), txHash)
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error getting tx 0x%x route: %v err: %v", txHash, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not get transaction")
+		return
+	}
+	if data == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error transaction not found", http.StatusNotFound)
+		return
+	}
+
+	tx := data.Transaction
+	response := types.APIEth1TxResponse{
+		Transaction: types.Eth1TransactionParsed{
+			Hash:               fmt.Sprintf("0x%x", tx.Hash),
+			BlockNumber:        tx.BlockNumber,
+			Time:               tx.Time.AsTime(),
+			From:               utils.FixAddressCasing(fmt.Sprintf("%x", tx.From)),
+			To:                 utils.FixAddressCasing(fmt.Sprintf("%x", tx.To)),
+			MethodId:           fmt.Sprintf("0x%x", tx.MethodId),
+			Value:              tx.ValueAmount().In(types.Ether).String(),
+			TxFee:              tx.TxFeeAmount().In(types.Ether).String(),
+			GasPrice:           tx.GasPriceAmount().In(types.GWei).String(),
+			IsContractCreation: tx.IsContractCreation,
+			InvokesContract:    tx.InvokesContract,
+		},
+		TxIndex:              tx.TxIndex,
+		Status:               "success",
+		Error:                tx.ErrorMsg,
+		Logs:                 make([]types.Eth1LogParsed, 0, len(data.Logs)),
+		InternalTransactions: make([]types.Eth1InternalTransactionParsed, 0, len(data.InternalTransactions)),
+		Warnings:             db.PartialResultWarnings(err),
+	}
+	if tx.ErrorMsg != "" {
+		response.Status = "failed"
+	}
+
+	for i, log := range data.Logs {
+		topics := make([]string, 0, len(log.Topics))
+		for _, topic := range log.Topics {
+			topics = append(topics, fmt.Sprintf("0x%x", topic))
+		}
+		response.Logs = append(response.Logs, types.Eth1LogParsed{
+			Index:   uint64(i),
+			Address: utils.FixAddressCasing(fmt.Sprintf("%x", log.Address)),
+			Topics:  topics,
+			Data:    fmt.Sprintf("0x%x", log.Data),
+			Removed: log.Removed,
+		})
+	}
+
+	for _, itx := range data.InternalTransactions {
+		response.InternalTransactions = append(response.InternalTransactions, types.Eth1InternalTransactionParsed{
+			ParentHash:  fmt.Sprintf("0x%x", itx.ParentHash),
+			BlockNumber: itx.BlockNumber,
+			Time:        itx.Time.AsTime(),
+			Type:        itx.Type,
+			From:        utils.FixAddressCasing(fmt.Sprintf("%x", itx.From)),
+			To:          utils.FixAddressCasing(fmt.Sprintf("%x", itx.To)),
+			Value:       itx.ValueAmount().In(types.Ether).String(),
+			Path:        itx.Path,
+			Index:       itx.Index,
+		})
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
+// ApiEth1TxFees godoc
+// @Summary Get the fee breakdown of an execution transaction
+// @Tags Execution
+// @Description Splits the fee of an indexed transaction into the part burned by the base fee of its block and the priority fee paid to the fee recipient. Amounts are denominated in wei.
+// @Produce json
+// @Param hash path string true "Transaction hash, an optional 0x prefix followed by 64 hexadecimal characters"
+// @Success 200 {object} types.ApiResponse{data=types.TxFeeBreakdown}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Router /api/v1/execution/tx/{hash}/fees [get]
+func ApiEth1TxFees(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+
+	txHash, err := hex.DecodeString(strings.Replace(vars["hash"], "0x", "", -1))
+	if err != nil || len(txHash) != 32 {
+		sendErrorResponse(w, r.URL.String(), "error invalid tx hash. A transaction hash consists of an optional 0x prefix followed by 64 hexadecimal characters.")
+		return
+	}
+
+	breakdown, err := db.GetEth1Store().GetTxFeeBreakdown(r.Context(), txHash)
+	if err != nil {
+		logger.Errorf("error getting fee breakdown of tx 0x%x route: %v err: %v", txHash, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not get transaction fees")
+		return
+	}
+	if breakdown == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error transaction not found", http.StatusNotFound)
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{breakdown})
+}
+
+// ApiEth1Transactions godoc
+// @Summary Get up to 100 execution transactions by their hashes
+// @Tags Execution
+// @Description Returns the indexed execution transactions with the given hashes in the order of the request, hashes that have not been indexed are listed in not_found. Values are denominated in ether, gas prices in gwei.
+// @Accept json
+// @Produce json
+// @Param request body types.APIEth1TransactionsRequest true "Up to 100 transaction hashes"
+// @Success 200 {object} types.ApiResponse{data=types.APIEth1TransactionsResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/transactions [post]
+func ApiEth1Transactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	req := &types.APIEth1TransactionsRequest{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024*1024)).Decode(req)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error decoding request body")
+		return
+	}
+	if len(req.Hashes) == 0 {
+		sendErrorResponse(w, r.URL.String(), "error no transaction hashes provided")
+		return
+	}
+	if len(req.Hashes) > 100 {
+		sendErrorResponse(w, r.URL.String(), "error too many transaction hashes, at most 100 are allowed")
+		return
+	}
+
+	txHashes := make([][]byte, 0, len(req.Hashes))
+	for _, hash := range req.Hashes {
+		txHash, err := hex.DecodeString(strings.Replace(hash, "0x", "", -1))
+		if err != nil || len(txHash) != 32 {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid tx hash %v. A transaction hash consists of an optional 0x prefix followed by 64 hexadecimal characters.", hash))
+			return
+		}
+		txHashes = append(txHashes, txHash)
+	}
+
+	transactions, err := db.GetEth1Store().GetIndexedEth1Transactions(r.Context(), txHashes)
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error getting %v txs route: %v err: %v", len(txHashes), r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not get transactions")
+		return
+	}
+
+	response := types.APIEth1TransactionsResponse{
+		Transactions: make([]types.APIEth1TransactionsEntry, 0, len(transactions)),
+		NotFound:     make([]string, 0),
+		Warnings:     db.PartialResultWarnings(err),
+	}
+
+	found := make(map[string]bool, len(transactions))
+	for _, tx := range transactions {
+		found[string(tx.Hash)] = true
+		entry := types.APIEth1TransactionsEntry{
+			Transaction: types.Eth1TransactionParsed{
+				Hash:               fmt.Sprintf("0x%x", tx.Hash),
+				BlockNumber:        tx.BlockNumber,
+				Time:               tx.Time.AsTime(),
+				From:               utils.FixAddressCasing(fmt.Sprintf("%x", tx.From)),
+				To:                 utils.FixAddressCasing(fmt.Sprintf("%x", tx.To)),
+				MethodId:           fmt.Sprintf("0x%x", tx.MethodId),
+				Value:              tx.ValueAmount().In(types.Ether).String(),
+				TxFee:              tx.TxFeeAmount().In(types.Ether).String(),
+				GasPrice:           tx.GasPriceAmount().In(types.GWei).String(),
+				IsContractCreation: tx.IsContractCreation,
+				InvokesContract:    tx.InvokesContract,
+			},
+			TxIndex: tx.TxIndex,
+			Status:  "success",
+			Error:   tx.ErrorMsg,
+		}
+		if tx.ErrorMsg != "" {
+			entry.Status = "failed"
+		}
+		response.Transactions = append(response.Transactions, entry)
+	}
+
+	for _, txHash := range txHashes {
+		if !found[string(txHash)] {
+			found[string(txHash)] = true
+			response.NotFound = append(response.NotFound, fmt.Sprintf("0x%x", txHash))
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
 // ApiETH1AccountProposedBlocks godoc
 // @Summary Get proposed or mined blocks
 // @Tags Execution
 // @Description Get a list of proposed or mined blocks from a given fee recipient address, proposer index or proposer pubkey.
-// @Description Mixed use of recipient addresses and proposer indexes or proposer pubkeys with an offset is discouraged as it can lead to skipped entries.
+// @Description Mixed use of recipient addresses and proposer indexes or proposer pubkeys with an offset is discouraged as it can lead to skipped entries, use the cursor instead.
 // @Produce json
 // @Param addressIndexOrPubkey path string true "Either the fee recipient address, the proposer index or proposer pubkey. You can provide multiple by separating them with ','. Max allowed index or pubkeys are 100, max allowed user addresses are 20."
-// @Param offset query int false "Offset" default(0)
+// @Param cursor query string false "Cursor returned by the previous request"
+// @Param offset query int false "Offset, deprecated in favor of the cursor" default(0)
 // @Param limit query int false "Limit, amount of entries you wish to receive" default(10)
-// @Param sort query string false "Sort via the block number either by 'asc' or 'desc'" default(desc)
+// @Param order query string false "Order via the block number either by 'asc' or 'desc', also accepted as sort" default(desc)
 // @Success 200 {object} types.ApiResponse
 // @Failure 400 {object} types.ApiResponse
 // @Router /api/v1/execution/{addressIndexOrPubkey}/produced [get]
@@ -143,34 +362,24 @@ func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var offset uint64 = 0
-	var limit uint64 = 10
-	var isSortAsc bool = false
-
-	offsetString := r.URL.Query().Get("offset")
-	offset, err = strconv.ParseUint(offsetString, 10, 64)
+	cursor, err := parseApiCursor(r.URL.Query(), 10, 100, apiOrderDesc, apiOrderAsc)
 	if err != nil {
-		offset = 0
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
 	}
-
-	limitString := r.URL.Query().Get("limit")
-	limit, err = strconv.ParseUint(limitString, 10, 64)
+	// the cursor continues after the last returned block number, which does not skip entries when addresses and indices are mixed
+	after, _, err := cursor.UintKey()
 	if err != nil {
-		limit = 10
-	}
-	if limit > 100 {
-		limit = 100
-	}
-
-	sortString := r.URL.Query().Get("sort")
-	if sortString == "asc" {
-		isSortAsc = true
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
 	}
+	offset, limit := cursor.Offset, cursor.Limit
+	isSortAsc := cursor.Order == apiOrderAsc
 
 	var blockList []uint64
 	var beaconDataMap = map[uint64]types.ExecBlockProposer{}
 	if len(addresses) > 0 {
-		blockListSub, beaconDataMapSub, err := findExecBlockNumbersByFeeRecipient(addresses, offset, limit, isSortAsc)
+		blockListSub, beaconDataMapSub, err := findExecBlockNumbersByFeeRecipient(addresses, offset, limit, isSortAsc, after)
 		if err != nil {
 			sendErrorResponse(w, r.URL.String(), "can not retrieve blocks from database")
 			return
@@ -182,7 +391,7 @@ func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if len(indices) > 0 {
-		blockListSub, beaconDataMapSub, err := findExecBlockNumbersByProposerIndex(indices, offset, limit, isSortAsc)
+		blockListSub, beaconDataMapSub, err := findExecBlockNumbersByProposerIndex(indices, offset, limit, isSortAsc, after)
 		if err != nil {
 			sendErrorResponse(w, r.URL.String(), "can not retrieve blocks from database")
 			return
@@ -214,7 +423,7 @@ func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
 		blockList = blockList[:limit]
 	}
 
-	blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(blockList, uint64(limit))
+	blocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(r.Context(), blockList, uint64(limit))
 	if err != nil {
 		logger.Errorf("Can not retrieve blocks from bigtable %v", err)
 		sendErrorResponse(w, r.URL.String(), "can not retrieve blocks from bigtable")
@@ -235,14 +444,168 @@ func ApiETH1AccountProducedBlocks(w http.ResponseWriter, r *http.Request) {
 
 	results := formatBlocksForApiResponse(blocks, relaysData, beaconDataMap, sortFunc)
 
-	j := json.NewEncoder(w)
-	sendOKResponse(j, r.URL.String(), []interface{}{results})
+	lastBlock := ""
+	if len(blockList) > 0 {
+		lastBlock = strconv.FormatUint(blockList[len(blockList)-1], 10)
+	}
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), results, cursor.Next(lastBlock, len(blockList)))
+}
+
+const dailyStatsMaxDays = 365
+
+// ApiEth1TxTypeStats godoc
+// @Summary Get the daily number of legacy, access list (EIP-2930), dynamic fee (EIP-1559) and blob (EIP-4844) transactions
+// @Tags Execution
+// @Produce  json
+// @Param  days query int false "Number of past days, defaults to 30, maximum 365"
+// @Success 200 {object} types.ApiResponse{data=[]types.TxTypeDayCounts}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/stats/txtypes [get]
+func ApiEth1TxTypeStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	days := uint64(30)
+	if q := r.URL.Query().Get("days"); q != "" {
+		var err error
+		days, err = strconv.ParseUint(q, 10, 64)
+		if err != nil || days < 1 || days > dailyStatsMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", dailyStatsMaxDays))
+			return
+		}
+	}
+
+	counts, err := db.GetDailyTxTypeCounts(days)
+	if err != nil {
+		logger.Errorf("error getting tx type stats route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting tx type stats")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{counts})
+}
+
+// ApiEth1ContractDeploymentStats godoc
+// @Summary Get the daily number of contracts deployed by transactions and by factory contracts via CREATE and CREATE2 as well as the number of active factories
+// @Tags Execution
+// @Produce  json
+// @Param  days query int false "Number of past days, defaults to 30, maximum 365"
+// @Success 200 {object} types.ApiResponse{data=[]types.ContractDeploymentDayCounts}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/stats/contracts [get]
+func ApiEth1ContractDeploymentStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	days := uint64(30)
+	if q := r.URL.Query().Get("days"); q != "" {
+		var err error
+		days, err = strconv.ParseUint(q, 10, 64)
+		if err != nil || days < 1 || days > dailyStatsMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", dailyStatsMaxDays))
+			return
+		}
+	}
+
+	counts, err := db.GetDailyContractDeploymentCounts(days)
+	if err != nil {
+		logger.Errorf("error getting contract deployment stats route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting contract deployment stats")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{counts})
+}
+
+// gasHistoryMaxHours limits the hourly gas price history to the last 90 days
+const gasHistoryMaxHours = 90 * 24
+
+// ApiEth1GasHistory godoc
+// @Summary Get the 10th, 50th and 90th percentile of the gas prices in wei paid by the transactions of each hour
+// @Tags Execution
+// @Description The percentiles of an hour are available once its day has been aggregated.
+// @Produce  json
+// @Param  resolution query string false "Resolution of the history, only hour is supported"
+// @Param  hours query int false "Number of past hours, defaults to 168, maximum 2160"
+// @Success 200 {object} types.ApiResponse{data=[]types.GasPricePercentiles}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/gas/history [get]
+func ApiEth1GasHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	q := r.URL.Query()
+	if resolution := q.Get("resolution"); resolution != "" && resolution != "hour" {
+		sendErrorResponse(w, r.URL.String(), "invalid resolution provided, only hour is supported")
+		return
+	}
+
+	hours := uint64(7 * 24)
+	if q.Get("hours") != "" {
+		var err error
+		hours, err = strconv.ParseUint(q.Get("hours"), 10, 64)
+		if err != nil || hours < 1 || hours > gasHistoryMaxHours {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid hours provided, hours must be between 1 and %d", gasHistoryMaxHours))
+			return
+		}
+	}
+
+	history, err := db.GetGasPriceHistory(hours)
+	if err != nil {
+		logger.Errorf("error getting gas price history route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting gas price history")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{history})
+}
+
+// burnMaxDays limits the daily burn returned by the burn api to about two years
+const burnMaxDays = 730
+
+// ApiEth1Burn godoc
+// @Summary Get the total amount of wei burned with EIP 1559 and the amount burned per day
+// @Tags Execution
+// @Description The burn is aggregated by the eth1 indexer, block is the highest block included in it.
+// @Produce  json
+// @Param  days query int false "Number of past days, defaults to 30, maximum 730"
+// @Success 200 {object} types.ApiResponse{data=types.BurnedFees}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/burn [get]
+func ApiEth1Burn(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	q := r.URL.Query()
+	days := uint64(30)
+	if q.Get("days") != "" {
+		var err error
+		days, err = strconv.ParseUint(q.Get("days"), 10, 64)
+		if err != nil || days < 1 || days > burnMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", burnMaxDays))
+			return
+		}
+	}
+
+	burned, err := db.BigtableClient.GetBurnedFees(r.Context(), days)
+	if err != nil {
+		logger.Errorf("error getting burned fees route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting burned fees")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{burned})
 }
 
 // ApiETH1GasNowData godoc
 // @Summary Gets the current estimation for gas prices in GWei.
 // @Tags Execution
 // @Description The response is split into four estimated inclusion speeds rapid (15 seconds), fast (1 minute), standard (3 minutes) and slow (> 10 minutes).
+// @Description If the gas oracle is enabled the prices in wei are the next base fee plus the 95th, 75th, 50th and 10th percentile of the priority fees paid in the most recent blocks, block, baseFee, nextBaseFee and baseFeeTrend describe the estimation.
 // @Produce json
 // @Success 200 {object} types.ApiResponse
 // @Failure 400 {object} types.ApiResponse
@@ -271,10 +634,155 @@ func ApiEth1GasNowData(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ApiEth1TopMovements godoc
+// @Summary Gets a summary of the most recent execution blocks
+// @Tags Execution
+// @Description Returns the largest ether transfers, the most called contracts and gas statistics of the last 100 execution blocks. Values are denominated in ether, gas prices and base fees in GWei.
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=types.TopMovements}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/movements [get]
+func ApiEth1TopMovements(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	movements := services.LatestTopMovements()
+	if movements == nil {
+		sendErrorResponse(w, r.URL.String(), "error top movements are currently not available.")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{movements})
+}
+
+// ApiEnsLookup godoc
+// @Summary Resolves an ENS name or looks up the primary ENS name of an address
+// @Tags Execution
+// @Description Returns the address an ENS name resolves to or, if an address is passed, its primary ENS name. A primary name is only returned if it resolves back to the address. The lookup is based on the indexed ENS registry and resolver events.
+// @Produce json
+// @Param name path string true "ENS name, e.g. vitalik.eth, or an address"
+// @Success 200 {object} types.ApiResponse{data=types.ApiEnsResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Router /api/v1/ens/{name} [get]
+func ApiEnsLookup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+	search := vars["name"]
+
+	if utils.IsEth1Address(search) {
+		address := common.HexToAddress(search)
+		names := map[string]string{string(address.Bytes()): ""}
+		err := db.GetEth1Store().GetEnsNames(r.Context(), names)
+		if err != nil {
+			logger.Errorf("error getting ens name of address %v route: %v err: %v", address.Hex(), r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error could not look up ens name")
+			return
+		}
+		if names[string(address.Bytes())] == "" {
+			sendErrorWithCodeResponse(w, r.URL.String(), "error address has no primary ens name", http.StatusNotFound)
+			return
+		}
+		sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{types.ApiEnsResponse{Name: names[string(address.Bytes())], Address: address.Hex()}})
+		return
+	}
+
+	if !utils.IsEnsName(search) {
+		sendErrorResponse(w, r.URL.String(), "error invalid ens name or address")
+		return
+	}
+	name := strings.ToLower(search)
+	address, err := db.GetEth1Store().GetEnsAddress(r.Context(), name)
+	if err != nil {
+		logger.Errorf("error resolving ens name %v route: %v err: %v", name, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not resolve ens name")
+		return
+	}
+	if len(address) != 20 {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error ens name does not resolve to an address", http.StatusNotFound)
+		return
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{types.ApiEnsResponse{Name: name, Address: common.BytesToAddress(address).Hex()}})
+}
+
+const balanceAtRequestsPerMinute = 30
+
+var balanceAtLimiter = newIpRateLimiter()
+
+// ApiEth1AddressBalanceAt godoc
+// @Summary Gets the ether or token balance of an address at a historical block
+// @Tags Execution
+// @Description Balances of final blocks are answered from the stored balance history, all other balances are queried from an archive node. Requests are rate limited per ip.
+// @Produce json
+// @Param address path string true "provide an ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters"
+// @Param block query int true "Block number"
+// @Param token query string false "Token contract address, the ether balance is returned if omitted"
+// @Success 200 {object} types.ApiResponse{data=types.ApiEth1AddressBalanceAtResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/address/{address}/balance [get]
+func ApiEth1AddressBalanceAt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+	q := r.URL.Query()
+
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	token := []byte{0x00}
+	if q.Get("token") != "" {
+		tokenStr := strings.ToLower(strings.Replace(q.Get("token"), "0x", "", -1))
+		if !utils.IsEth1Address(tokenStr) {
+			sendErrorResponse(w, r.URL.String(), "error invalid token query param. A token address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+			return
+		}
+		token = common.FromHex(tokenStr)
+	}
+
+	latest := services.LatestEth1BlockNumber()
+	block, err := strconv.ParseUint(q.Get("block"), 10, 64)
+	if err != nil || block > latest {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid block query param. The block must be a number between 0 and %d", latest))
+		return
+	}
+
+	if !balanceAtLimiter.Allow(utils.ClientIp(r), 1, balanceAtRequestsPerMinute) {
+		sendErrorWithCodeResponse(w, r.URL.String(), "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
+	defer cancel()
+
+	final := latest > rpcProxyImmutableDepth && block < latest-rpcProxyImmutableDepth
+	balance, source, err := eth1data.GetBalanceAt(ctx, common.FromHex(address), token, block, final)
+	if err != nil {
+		logger.Errorf("error retrieving balance at block %v for address: %v route: %v err: %v", block, address, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not get balance of address")
+		return
+	}
+
+	response := types.ApiEth1AddressBalanceAtResponse{
+		Address: "0x" + address,
+		Token:   fmt.Sprintf("0x%x", token),
+		Block:   block,
+		Balance: balance.String(),
+		Source:  source,
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
 // ApiEth1Address godoc
 // @Summary Gets information about an ethereum address.
 // @Tags Execution
-// @Description Returns the ether balance and any token balances for a given ethereum address.
+// @Description Returns the ether balance and any token balances for a given ethereum address. If enabled for the deployment, heuristic risk indicators of the address are included.
 // @Produce json
 // @Param address path string true "provide an ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters"
 // @Param token query string false "filter for a specific token by providing a ethereum token contract address"
@@ -309,7 +817,7 @@ func ApiEth1Address(w http.ResponseWriter, r *http.Request) {
 
 	response := types.ApiEth1AddressResponse{}
 
-	metadata, err := db.BigtableClient.GetMetadataForAddress(common.FromHex(address))
+	metadata, err := eth1StoreForRequest(r).GetMetadataForAddress(r.Context(), common.FromHex(address))
 	if err != nil {
 		logger.Errorf("error retrieving metadata for address: %v route: %v err: %v", address, r.URL.String(), err)
 		sendErrorResponse(w, r.URL.String(), "error could not get metadata for address")
@@ -359,6 +867,16 @@ func ApiEth1Address(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	// the risk indicators are only tracked for the chain of the frontend
+	if requestNetwork(r).IsDefault() {
+		response.Risk, err = services.GetAddressRisk(common.FromHex(address))
+		if err != nil {
+			logger.Errorf("error retrieving risk indicators for address: %v route: %v err: %v", address, r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error could not get risk indicators for address")
+			return
+		}
+	}
+
 	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
 }
 
@@ -386,38 +904,49 @@ func ApiEth1AddressTx(w http.ResponseWriter, r *http.Request) {
 		"time":     string(db.FILTER_TIME),
 		"received": string(db.FILTER_FROM),
 		"sent":     string(db.FILTER_TO),
+		"block":    string(db.FILTER_BLOCK),
 		// "method":   string(db.FILTER_METHOD),
 		// "contract": string(db.FILTER_CONTRACT),
 	}
 
 	filter, ok := filters[filter]
 	if !ok {
-		sendErrorResponse(w, r.URL.String(), "error invalid filter provided. Please provide a valid filter: time (default), received, sent")
+		sendErrorResponse(w, r.URL.String(), "error invalid filter provided. Please provide a valid filter: time (default), block, received, sent")
 		return
 	}
 
-	pageToken := q.Get("page")
-	if len(pageToken) > 0 {
-		token, err := base58.FastBase58Decoding(pageToken)
-		if err != nil {
-			logger.Errorf("error invalid page token provided: %v err: %v", q.Get("page"), err)
-			sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+	prefix := fmt.Sprintf("%s:I:TX:%s:%s:", bigtableForRequest(r).ChainId(), address, filter)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
+	}
+
+	// in block mode the transactions are ordered by block number and index, optionally starting at a given block (inclusive)
+	if len(pageToken) == 0 && filter == string(db.FILTER_BLOCK) && q.Get("block") != "" {
+		startBlock, err := strconv.ParseUint(q.Get("block"), 10, 64)
+		if err != nil || startBlock > 999999999 {
+			sendErrorResponse(w, r.URL.String(), "error invalid block provided. Please provide a valid block number")
 			return
 		}
-		pageToken = fmt.Sprintf("%d:I:TX:%s:%s:%s", utils.Config.Chain.Config.DepositChainID, address, filter, token)
+		pageToken = prefix + db.ReversedPaddedBlockNumber(startBlock)
 	}
 
 	if len(pageToken) == 0 {
-		pageToken = fmt.Sprintf("%d:I:TX:%s:%s:", utils.Config.Chain.Config.DepositChainID, address, filter)
+		pageToken = prefix
 	}
 
-	transactions, lastKey, err := db.BigtableClient.GetEth1TxForAddress(pageToken, 25)
-	if err != nil {
+	transactions, lastKey, err := bigtableForRequest(r).GetEth1TxForAddress(r.Context(), pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
 		logger.Errorf("error getting transactions for address: %v route: %v err: %v", address, r.URL.String(), err)
 		sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 		return
 	}
-	response.Page = base58.FastBase58Encoding([]byte(strings.TrimPrefix(lastKey, fmt.Sprintf("%d:I:TX:%s:%s:", utils.Config.Chain.Config.DepositChainID, address, filter))))
+	response.Page = db.EncodePageToken(prefix, lastKey)
 
 	txsParsed := make([]types.Eth1TransactionParsed, 0, len(transactions))
 
@@ -429,8 +958,8 @@ func ApiEth1AddressTx(w http.ResponseWriter, r *http.Request) {
 			From:               utils.FixAddressCasing(fmt.Sprintf("%x", tx.From)),
 			To:                 utils.FixAddressCasing(fmt.Sprintf("%x", tx.To)),
 			MethodId:           fmt.Sprintf("0x%x", tx.MethodId),
-			Value:              new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(tx.Value)), big.NewFloat(1e18)).String(),   //new(big.Int).Div(new(big.Int).SetBytes(tx.Value), big.NewInt(1e18)).String(),
-			GasPrice:           new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(tx.GasPrice)), big.NewFloat(1e9)).String(), //new(big.Int).Div(new(big.Int).SetBytes(tx.GasPrice), new(big.Int).SetInt64(1e18)).String(),
+			Value:              tx.ValueAmount().In(types.Ether).String(),
+			GasPrice:           tx.GasPriceAmount().In(types.GWei).String(),
 			IsContractCreation: tx.IsContractCreation,
 			InvokesContract:    tx.InvokesContract,
 		})
@@ -438,7 +967,7 @@ func ApiEth1AddressTx(w http.ResponseWriter, r *http.Request) {
 
 	response.Transactions = txsParsed
 
-	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
 }
 
 func ApiEth1AddressItx(w http.ResponseWriter, r *http.Request) {
@@ -475,30 +1004,30 @@ func ApiEth1AddressItx(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	prefixFormat := "%d:I:ITX:%s:%s:"
+	prefixFormat := "%s:I:ITX:%s:%s:"
 
-	pageToken := q.Get("page")
-	if len(pageToken) > 0 {
-		token, err := base58.FastBase58Decoding(pageToken)
-		if err != nil {
-			logger.Errorf("error invalid page token provided: %v err: %v", q.Get("page"), err)
-			sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
-			return
-		}
-		pageToken = fmt.Sprintf(prefixFormat+"%s", utils.Config.Chain.Config.DepositChainID, address, filter, token)
+	prefix := fmt.Sprintf(prefixFormat, bigtableForRequest(r).ChainId(), address, filter)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
 	}
 
 	if len(pageToken) == 0 {
-		pageToken = fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address, filter)
+		pageToken = prefix
 	}
 
-	internalTransactions, lastKey, err := db.BigtableClient.GetEth1ItxForAddress(pageToken, 25)
-	if err != nil {
+	internalTransactions, lastKey, err := bigtableForRequest(r).GetEth1ItxForAddress(r.Context(), pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
 		logger.Errorf("error getting transactions for address: %v route: %v err: %v", address, r.URL.String(), err)
 		sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 		return
 	}
-	response.Page = base58.FastBase58Encoding([]byte(strings.TrimPrefix(lastKey, fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address, filter))))
+	response.Page = db.EncodePageToken(prefix, lastKey)
 
 	itxParsed := make([]types.Eth1InternalTransactionParsed, 0, len(internalTransactions))
 
@@ -510,12 +1039,14 @@ func ApiEth1AddressItx(w http.ResponseWriter, r *http.Request) {
 			Type:        itx.Type,
 			From:        utils.FixAddressCasing(fmt.Sprintf("%x", itx.From)),
 			To:          utils.FixAddressCasing(fmt.Sprintf("%x", itx.To)),
-			Value:       new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(itx.Value)), big.NewFloat(1e18)).String(),
+			Value:       itx.ValueAmount().In(types.Ether).String(),
+			Path:        itx.Path,
+			Index:       itx.Index,
 		})
 	}
 
 	response.InternalTransactions = itxParsed
-	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
 }
 
 func ApiEth1AddressBlocks(w http.ResponseWriter, r *http.Request) {
@@ -536,41 +1067,41 @@ func ApiEth1AddressBlocks(w http.ResponseWriter, r *http.Request) {
 
 	response := types.APIEth1AddressBlockResponse{}
 
-	prefixFormat := "%d:I:B:%s:"
+	prefixFormat := "%s:I:B:%s:"
 
-	pageToken := q.Get("page")
-	if len(pageToken) > 0 {
-		token, err := base58.FastBase58Decoding(pageToken)
-		if err != nil {
-			logger.Errorf("error invalid page token provided: %v err: %v", q.Get("page"), err)
-			sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
-			return
-		}
-		pageToken = fmt.Sprintf(prefixFormat+"%s", utils.Config.Chain.Config.DepositChainID, address, token)
+	prefix := fmt.Sprintf(prefixFormat, bigtableForRequest(r).ChainId(), address)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
 	}
 
 	if len(pageToken) == 0 {
-		pageToken = fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address)
+		pageToken = prefix
 	}
 
-	producedBlocks, lastKey, err := db.BigtableClient.GetEth1BlocksForAddress(pageToken, 25)
-	if err != nil {
+	producedBlocks, lastKey, err := bigtableForRequest(r).GetEth1BlocksForAddress(r.Context(), pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
 		logger.Errorf("error getting transactions for address: %v route: %v err: %v", address, r.URL.String(), err)
 		sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 		return
 	}
-	response.Page = base58.FastBase58Encoding([]byte(strings.TrimPrefix(lastKey, fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address))))
+	response.Page = db.EncodePageToken(prefix, lastKey)
 
 	blocksParsed := make([]types.Eth1BlockParsed, 0, len(producedBlocks))
 
 	for _, blk := range producedBlocks {
-		txReward := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(blk.TxReward)), big.NewFloat(1e18)).String()
+		txReward := blk.TxRewardAmount().In(types.Ether).String()
 		if txReward == "0" {
 			txReward = ""
 		}
 
 		uncleHash := fmt.Sprintf("0x%x", blk.UncleHash)
-		uncleReward := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(blk.UncleReward)), big.NewFloat(1e18)).String()
+		uncleReward := blk.UncleRewardAmount().In(types.Ether).String()
 		if uncleReward == "0" {
 			uncleReward = ""
 			uncleHash = ""
@@ -590,13 +1121,13 @@ func ApiEth1AddressBlocks(w http.ResponseWriter, r *http.Request) {
 			Hash:                     fmt.Sprintf("0x%x", blk.Hash),
 			ParentHash:               fmt.Sprintf("0x%x", blk.ParentHash),
 			UncleHash:                uncleHash,
-			Coinbase:                 fmt.Sprintf("0x%x", blk.Coinbase), //new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(blk.Coinbase)), big.NewFloat(1e18)).String(),
+			Coinbase:                 fmt.Sprintf("0x%x", blk.Coinbase),
 			Difficulty:               difficulty,
 			Number:                   blk.Number,
 			GasLimit:                 blk.GasLimit,
 			GasUsed:                  blk.GasUsed,
 			Time:                     blk.Time.AsTime(),
-			BaseFee:                  new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(blk.BaseFee)), big.NewFloat(1e9)).String(),
+			BaseFee:                  blk.BaseFeeAmount().In(types.GWei).String(),
 			UncleCount:               blk.UncleCount,
 			TransactionCount:         blk.TransactionCount,
 			InternalTransactionCount: blk.InternalTransactionCount,
@@ -609,7 +1140,7 @@ func ApiEth1AddressBlocks(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response.ProducedBlocks = blocksParsed
-	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
 }
 
 func ApiEth1AddressUncles(w http.ResponseWriter, r *http.Request) {
@@ -630,30 +1161,30 @@ func ApiEth1AddressUncles(w http.ResponseWriter, r *http.Request) {
 
 	response := types.APIEth1AddressUncleResponse{}
 
-	prefixFormat := "%d:I:B:%s:"
+	prefixFormat := "%s:I:B:%s:"
 
-	pageToken := q.Get("page")
-	if len(pageToken) > 0 {
-		token, err := base58.FastBase58Decoding(pageToken)
-		if err != nil {
-			logger.Errorf("error invalid page token provided: %v err: %v", q.Get("page"), err)
-			sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
-			return
-		}
-		pageToken = fmt.Sprintf(prefixFormat+"%s", utils.Config.Chain.Config.DepositChainID, address, token)
+	prefix := fmt.Sprintf(prefixFormat, bigtableForRequest(r).ChainId(), address)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
 	}
 
 	if len(pageToken) == 0 {
-		pageToken = fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address)
+		pageToken = prefix
 	}
 
-	producedUncle, lastKey, err := db.BigtableClient.GetEth1UnclesForAddress(pageToken, 25)
-	if err != nil {
+	producedUncle, lastKey, err := bigtableForRequest(r).GetEth1UnclesForAddress(r.Context(), pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
 		logger.Errorf("error getting transactions for address: %v route: %v err: %v", address, r.URL.String(), err)
 		sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 		return
 	}
-	response.Page = base58.FastBase58Encoding([]byte(strings.TrimPrefix(lastKey, fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address))))
+	response.Page = db.EncodePageToken(prefix, lastKey)
 
 	unclesParsed := make([]types.Eth1UncleParsed, 0, len(producedUncle))
 
@@ -664,15 +1195,15 @@ func ApiEth1AddressUncles(w http.ResponseWriter, r *http.Request) {
 			Number:      uncl.Number,
 			GasLimit:    uncl.GasLimit,
 			GasUsed:     uncl.GasUsed,
-			BaseFee:     new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(uncl.BaseFee)), big.NewFloat(1e9)).String(),
+			BaseFee:     types.EtherAmountFromBytes(uncl.BaseFee).In(types.GWei).String(),
 			Difficulty:  new(big.Int).SetBytes(uncl.Difficulty).String(),
 			Time:        uncl.Time.AsTime(),
-			Reward:      new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).SetBytes(uncl.Reward)), big.NewFloat(1e18)).String(),
+			Reward:      types.EtherAmountFromBytes(uncl.Reward).In(types.Ether).String(),
 		})
 	}
 
 	response.ProducedUncles = unclesParsed
-	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
 }
 
 func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
@@ -720,29 +1251,29 @@ func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
 
 	response := types.APIEth1TokenResponse{}
 
-	prefixFormat := fmt.Sprintf("%%d:I:%s:%%s:%%s:", selectedToken)
+	prefixFormat := fmt.Sprintf("%%s:I:%s:%%s:%%s:", selectedToken)
 
-	pageToken := q.Get("page")
-	if len(pageToken) > 0 {
-		token, err := base58.FastBase58Decoding(pageToken)
-		if err != nil {
-			logger.Errorf("error invalid page token provided: %v err: %v", q.Get("page"), err)
-			sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
-			return
-		}
-		pageToken = fmt.Sprintf(prefixFormat+"%s", utils.Config.Chain.Config.DepositChainID, address, token)
+	prefix := fmt.Sprintf(prefixFormat, bigtableForRequest(r).ChainId(), address, db.FILTER_TIME)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
 	}
 
 	if len(pageToken) == 0 {
-		pageToken = fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address)
+		pageToken = prefix
 	}
-	pageSize := 25
+	pageSize := cursor.Limit
 	transactions := make([]*types.Eth1TokenTxParsed, 0, pageSize)
 	pageKey := ""
 	switch selectedToken {
 	case "erc721":
-		txs, lastKey, err := db.BigtableClient.GetEth1ERC721ForAddress(pageToken, 25)
-		if err != nil {
+		txs, lastKey, err := bigtableForRequest(r).GetEth1ERC721ForAddress(r.Context(), pageToken, int64(cursor.Limit))
+		if err != nil && !db.IsPartialResult(err) {
 			logger.Errorf("error getting token: %v transactions for address: %v route: %v err: %v", selectedToken, address, r.URL.String(), err)
 			sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 			return
@@ -762,8 +1293,8 @@ func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
 		}
 
 	case "erc1155":
-		txs, lastKey, err := db.BigtableClient.GetEth1ERC1155ForAddress(pageToken, 25)
-		if err != nil {
+		txs, lastKey, err := bigtableForRequest(r).GetEth1ERC1155ForAddress(r.Context(), pageToken, int64(cursor.Limit))
+		if err != nil && !db.IsPartialResult(err) {
 			logger.Errorf("error getting token: %v transactions for address: %v route: %v err: %v", selectedToken, address, r.URL.String(), err)
 			sendErrorResponse(w, r.URL.String(), "error getting transactions for address")
 			return
@@ -785,8 +1316,8 @@ func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
 		}
 
 	default:
-		txs, lastKey, err := db.BigtableClient.GetEth1ERC20ForAddress(pageToken, 25)
-		if err != nil {
+		txs, lastKey, err := bigtableForRequest(r).GetEth1ERC20ForAddress(r.Context(), pageToken, int64(cursor.Limit))
+		if err != nil && !db.IsPartialResult(err) {
 			logger.Errorf("error getting token: %v transactions for address: %v route: %v err: %v", selectedToken, address, r.URL.String(), err)
 			sendErrorResponse(w, r.URL.String(), "error getting transactions for token")
 			return
@@ -796,7 +1327,7 @@ func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
 		for _, tx := range txs {
 			_, ok := tokenMeta[string(tx.TokenAddress)]
 			if !ok {
-				metadata, err := db.BigtableClient.GetERC20MetadataForAddress([]byte(address))
+				metadata, err := eth1StoreForRequest(r).GetERC20MetadataForAddress(r.Context(), []byte(address))
 				if err != nil {
 					logger.Errorf("error getting token: %v metadata for address: %v route: %v err: %v", selectedToken, address, r.URL.String(), err)
 					sendErrorResponse(w, r.URL.String(), "error getting transactions for token")
@@ -822,12 +1353,180 @@ func ApiEth1AddressTokens(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	response.Page = base58.FastBase58Encoding([]byte(strings.TrimPrefix(pageKey, fmt.Sprintf(prefixFormat, utils.Config.Chain.Config.DepositChainID, address))))
+	response.Page = db.EncodePageToken(prefix, pageKey)
 
 	response.TokenTxs = transactions
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, pageKey))
+}
+
+// ApiEth1AddressTokenBalances godoc
+// @Summary Get the ether, ERC20 and NFT balances of an execution address
+// @Tags Execution
+// @Description Returns the ether and ERC20 balances of an address adjusted by the decimals of their token together with the number of tokens it holds per ERC721 and ERC1155 collection. USD values are included for the chain of the deployment where a price of the token is known.
+// @Produce json
+// @Param address path string true "provide an ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters"
+// @Success 200 {object} types.ApiResponse{data=types.ApiEth1AddressTokenBalancesResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/address/{address}/tokens/balances [get]
+func ApiEth1AddressTokenBalances(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	balances, err := eth1StoreForRequest(r).GetAddressTokenBalances(r.Context(), common.FromHex(address))
+	if err != nil {
+		logger.Errorf("error retrieving token balances for address: %v route: %v err: %v", address, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error could not get token balances for address")
+		return
+	}
+
+	// prices are only tracked for the chain of the frontend
+	var ethPrice float64
+	tokenPrices := map[string]decimal.Decimal{}
+	if requestNetwork(r).IsDefault() {
+		ethPrice = price.GetEthPrice("USD")
+		tokens := make([][]byte, 0, len(balances.ERC20))
+		for _, balance := range balances.ERC20 {
+			tokens = append(tokens, balance.Token)
+		}
+		tokenPrices, err = bigtableForRequest(r).GetERC20TokenPrices(r.Context(), tokens)
+		if err != nil {
+			logger.Errorf("error retrieving token prices for address: %v route: %v err: %v", address, r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error could not get token prices")
+			return
+		}
+	}
+
+	response := types.ApiEth1AddressTokenBalancesResponse{
+		Address:      fmt.Sprintf("0x%x", common.FromHex(address)),
+		ERC20:        make([]*types.ApiEth1AddressTokenBalance, 0, len(balances.ERC20)),
+		NFTs:         make([]*types.ApiEth1AddressNFTCollection, 0, len(balances.NFTs)),
+		NFTsComplete: balances.NFTsComplete,
+	}
+
+	response.Ether = apiTokenBalance(balances.Ether, decimal.NewFromFloat(ethPrice))
+	response.Ether.Token = ""
+	response.ValueUSD += response.Ether.ValueUSD
+	for _, balance := range balances.ERC20 {
+		b := apiTokenBalance(balance, tokenPrices[string(balance.Token)])
+		response.ValueUSD += b.ValueUSD
+		response.ERC20 = append(response.ERC20, b)
+	}
+	for _, collection := range balances.NFTs {
+		response.NFTs = append(response.NFTs, &types.ApiEth1AddressNFTCollection{
+			Token:    fmt.Sprintf("0x%x", collection.TokenAddress),
+			Standard: collection.Standard,
+			Tokens:   collection.Tokens,
+			Balance:  collection.Balance.String(),
+		})
+	}
+
 	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
 }
 
+// apiTokenBalance converts a balance to its api representation, a zero price leaves the USD value unset
+func apiTokenBalance(balance *types.Eth1AddressBalance, price decimal.Decimal) *types.ApiEth1AddressTokenBalance {
+	ret := &types.ApiEth1AddressTokenBalance{
+		Token:   fmt.Sprintf("0x%x", balance.Token),
+		Balance: "0",
+	}
+	if balance.Metadata != nil {
+		ret.Symbol = balance.Metadata.Symbol
+		ret.Decimals = new(big.Int).SetBytes(balance.Metadata.Decimals).Uint64()
+	}
+	amount := decimal.NewFromBigInt(new(big.Int).SetBytes(balance.Balance), -int32(ret.Decimals))
+	ret.Balance = amount.String()
+	if price.IsPositive() {
+		ret.PriceUSD, _ = price.Float64()
+		ret.ValueUSD, _ = amount.Mul(price).Round(2).Float64()
+	}
+	return ret
+}
+
+// ApiEth1AddressLogs returns the events emitted by a contract, newest first, optionally filtered by their signature topic
+func ApiEth1AddressLogs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+	address := vars["address"]
+	q := r.URL.Query()
+
+	address = strings.Replace(address, "0x", "", -1)
+	address = strings.ToLower(address)
+
+	if !utils.IsEth1Address(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	var topic0 []byte
+	var err error
+	if q.Get("topic0") != "" {
+		topic0, err = hex.DecodeString(strings.Replace(q.Get("topic0"), "0x", "", -1))
+		if err != nil || len(topic0) != 32 {
+			sendErrorResponse(w, r.URL.String(), "error invalid topic0 provided. A topic consists of an optional 0x prefix followed by 64 hexadecimal characters.")
+			return
+		}
+	}
+
+	response := types.APIEth1AddressLogResponse{}
+
+	prefix := bigtableForRequest(r).LogIndexPrefix(common.FromHex(address), topic0)
+	cursor, err := parseApiCursor(q, 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
+	}
+
+	if len(pageToken) == 0 {
+		pageToken = prefix
+	}
+
+	logs, lastKey, err := bigtableForRequest(r).GetEth1LogsForContract(r.Context(), pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error getting logs for address: %v route: %v err: %v", address, r.URL.String(), err)
+		sendErrorResponse(w, r.URL.String(), "error getting logs for address")
+		return
+	}
+	response.Page = db.EncodePageToken(prefix, lastKey)
+
+	logsParsed := make([]*types.Eth1ContractLogParsed, 0, len(logs))
+	for _, log := range logs {
+		parsed := &types.Eth1ContractLogParsed{
+			TxHash:      fmt.Sprintf("0x%x", log.ParentHash),
+			BlockNumber: log.BlockNumber,
+			Time:        log.Time.AsTime(),
+			TxIndex:     log.TxIndex,
+			LogIndex:    log.LogIndex,
+			Address:     utils.FixAddressCasing(fmt.Sprintf("%x", log.Address)),
+			Topics:      make([]string, 0, len(log.Topics)),
+			Data:        fmt.Sprintf("0x%x", log.Data),
+		}
+		for _, topic := range log.Topics {
+			parsed.Topics = append(parsed.Topics, fmt.Sprintf("0x%x", topic))
+		}
+		if len(log.Topics) > 0 {
+			parsed.Event = eth1StoreForRequest(r).GetEventLabel(r.Context(), log.Topics[0])
+		}
+		logsParsed = append(logsParsed, parsed)
+	}
+
+	response.Logs = logsParsed
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
+}
+
 func formatBlocksForApiResponse(blocks []*types.Eth1BlockIndexed, relaysData map[common.Hash]types.RelaysData, beaconDataMap map[uint64]types.ExecBlockProposer, sortFunc func(i, j types.ExecutionBlockApiResponse) bool) []types.ExecutionBlockApiResponse {
 	results := []types.ExecutionBlockApiResponse{}
 
@@ -901,7 +1600,7 @@ func formatBlocksForApiResponse(blocks []*types.Eth1BlockIndexed, relaysData map
 	return results
 }
 
-func getValidatorExecutionPerformance(queryIndices []uint64) ([]types.ExecutionPerformanceResponse, error) {
+func getValidatorExecutionPerformance(ctx context.Context, queryIndices []uint64) ([]types.ExecutionPerformanceResponse, error) {
 	latestEpoch := services.LatestEpoch()
 	last30dTimestamp := time.Now().Add(-31 * 24 * time.Hour)
 	last7dTimestamp := time.Now().Add(-7 * 24 * time.Hour)
@@ -931,7 +1630,7 @@ func getValidatorExecutionPerformance(queryIndices []uint64) ([]types.ExecutionP
 
 	blockList, blockToProposerMap := getBlockNumbersAndMapProposer(execBlocks)
 
-	blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(blockList, 10000)
+	blocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(ctx, blockList, 10000)
 	if err != nil {
 		return nil, fmt.Errorf("error cannot get blocks from bigtable using GetBlocksIndexedMultiple: %w", err)
 	}
@@ -989,12 +1688,12 @@ func getValidatorExecutionPerformance(queryIndices []uint64) ([]types.ExecutionP
 	return maps.Values(resultPerProposer), nil
 }
 
-func findExecBlockNumbersByProposerIndex(indices []uint64, offset, limit uint64, isSortAsc bool) ([]uint64, map[uint64]types.ExecBlockProposer, error) {
+func findExecBlockNumbersByProposerIndex(indices []uint64, offset, limit uint64, isSortAsc bool, after uint64) ([]uint64, map[uint64]types.ExecBlockProposer, error) {
 	var blockListSub []types.ExecBlockProposer
 
-	order := "DESC"
+	order, cmp := "DESC", "<"
 	if isSortAsc {
-		order = "ASC"
+		order, cmp = "ASC", ">"
 	}
 
 	query := fmt.Sprintf(`SELECT 
@@ -1005,14 +1704,16 @@ func findExecBlockNumbersByProposerIndex(indices []uint64, offset, limit uint64,
 		FROM blocks 
 		WHERE proposer = ANY($1)
 		AND exec_block_number IS NOT NULL AND exec_block_number > 0 
+		AND ($4 = 0 OR exec_block_number %s $4)
 		ORDER BY exec_block_number %s
-		OFFSET $2 LIMIT $3`, order)
+		OFFSET $2 LIMIT $3`, cmp, order)
 
 	err := db.ReaderDb.Select(&blockListSub,
 		query,
 		pq.Array(indices),
 		offset,
 		limit,
+		after,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -1021,12 +1722,12 @@ func findExecBlockNumbersByProposerIndex(indices []uint64, offset, limit uint64,
 	return blockList, blockProposerMap, nil
 }
 
-func findExecBlockNumbersByFeeRecipient(addresses [][]byte, offset, limit uint64, isSortAsc bool) ([]uint64, map[uint64]types.ExecBlockProposer, error) {
+func findExecBlockNumbersByFeeRecipient(addresses [][]byte, offset, limit uint64, isSortAsc bool, after uint64) ([]uint64, map[uint64]types.ExecBlockProposer, error) {
 	var blockListSub []types.ExecBlockProposer
 
-	order := "DESC"
+	order, cmp := "DESC", "<"
 	if isSortAsc {
-		order = "ASC"
+		order, cmp = "ASC", ">"
 	}
 
 	query := fmt.Sprintf(`
@@ -1038,14 +1739,16 @@ func findExecBlockNumbersByFeeRecipient(addresses [][]byte, offset, limit uint64
 		FROM blocks 
 		WHERE exec_fee_recipient = ANY($1)
 		AND exec_block_number IS NOT NULL AND exec_block_number > 0 
+		AND ($4 = 0 OR exec_block_number %s $4)
 		ORDER BY exec_block_number %s
-		OFFSET $2 LIMIT $3`, order)
+		OFFSET $2 LIMIT $3`, cmp, order)
 
 	err := db.ReaderDb.Select(&blockListSub,
 		query,
 		pq.ByteaArray(addresses),
 		offset,
 		limit,
+		after,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -1170,3 +1873,199 @@ func parseFromAddressIndexOrPubkey(search string) (types.AddressIndexOrPubkey, e
 		}, nil
 	}
 }
+
+func ApiEth1AddressCounterparties(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+	address := vars["address"]
+	q := r.URL.Query()
+
+	address = strings.Replace(address, "0x", "", -1)
+	address = strings.ToLower(address)
+
+	if !utils.IsEth1Address(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	days := uint64(30)
+	if q.Get("days") != "" {
+		var err error
+		days, err = strconv.ParseUint(q.Get("days"), 10, 64)
+		if err != nil || days == 0 {
+			sendErrorResponse(w, r.URL.String(), "error invalid days provided")
+			return
+		}
+	}
+	if days > 365 {
+		days = 365
+	}
+
+	addressBytes := common.FromHex(address)
+	since := time.Now().Add(-time.Hour * 24 * time.Duration(days)).Truncate(db.CounterpartiesPeriod)
+	counterparties, truncated, err := bigtableForRequest(r).GetAddressCounterparties(r.Context(), addressBytes, since, 10000, 25)
+	if err != nil {
+		logger.Errorf("error getting counterparties for address: %v route: %v err: %v", address, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting counterparties for address")
+		return
+	}
+
+	response := types.APIEth1AddressCounterpartiesResponse{
+		Address:        utils.FixAddressCasing(address),
+		Since:          since,
+		Truncated:      truncated,
+		Counterparties: counterparties,
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
+func ApiEth1AddressContracts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+	address := vars["address"]
+
+	address = strings.Replace(address, "0x", "", -1)
+	address = strings.ToLower(address)
+
+	if !utils.IsEth1Address(address) {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	interactions, err := bigtableForRequest(r).GetAddressContractInteractions(r.Context(), common.FromHex(address), 100)
+	if err != nil {
+		logger.Errorf("error getting contract interactions for address: %v route: %v err: %v", address, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting contract interactions for address")
+		return
+	}
+
+	response := make([]types.APIEth1AddressContractInteraction, 0, len(interactions))
+	for _, interaction := range interactions {
+		response = append(response, types.APIEth1AddressContractInteraction{
+			Contract:     utils.FixAddressCasing(fmt.Sprintf("%x", interaction.Contract)),
+			Name:         interaction.Name,
+			Transactions: interaction.Transactions,
+			GasUsed:      interaction.GasUsed,
+			Fees:         decimal.NewFromBigInt(new(big.Int).SetBytes(interaction.Fees), -18).String(),
+		})
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
+// searches up to this depth are executed right away, deeper searches are queued as a job
+const transferPathSyncMaxDepth = 2
+const transferPathMaxDepth = 6
+
+func ApiEth1TransferPath(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	q := r.URL.Query()
+
+	source := strings.ToLower(strings.Replace(q.Get("source"), "0x", "", -1))
+	destination := strings.ToLower(strings.Replace(q.Get("destination"), "0x", "", -1))
+	if !utils.IsEth1Address(source) || !utils.IsEth1Address(destination) {
+		sendErrorResponse(w, r.URL.String(), "error invalid source or destination address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+	if source == destination {
+		sendErrorResponse(w, r.URL.String(), "error source and destination address must differ")
+		return
+	}
+
+	search := &types.TransferPathSearch{
+		Source:      common.FromHex(source),
+		Destination: common.FromHex(destination),
+		ToBlock:     services.LatestEth1BlockNumber(),
+		MaxDepth:    3,
+	}
+	var err error
+	if q.Get("from_block") != "" {
+		search.FromBlock, err = strconv.ParseUint(q.Get("from_block"), 10, 64)
+		if err != nil {
+			sendErrorResponse(w, r.URL.String(), "error invalid from_block provided")
+			return
+		}
+	}
+	if q.Get("to_block") != "" {
+		search.ToBlock, err = strconv.ParseUint(q.Get("to_block"), 10, 64)
+		if err != nil {
+			sendErrorResponse(w, r.URL.String(), "error invalid to_block provided")
+			return
+		}
+	}
+	if search.FromBlock > search.ToBlock {
+		sendErrorResponse(w, r.URL.String(), "error from_block must not be greater than to_block")
+		return
+	}
+	if q.Get("depth") != "" {
+		search.MaxDepth, err = strconv.Atoi(q.Get("depth"))
+		if err != nil || search.MaxDepth < 1 || search.MaxDepth > transferPathMaxDepth {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid depth provided, depth must be between 1 and %d", transferPathMaxDepth))
+			return
+		}
+	}
+
+	if search.MaxDepth > transferPathSyncMaxDepth {
+		job, err := db.CreateTransferPathJob(search)
+		if err != nil {
+			logger.Errorf("error creating transfer path job route: %v err: %v", r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error creating transfer path search")
+			return
+		}
+		sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{types.APIEth1TransferPathResponse{JobID: job.ID, Status: string(job.Status)}})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second*30)
+	defer cancel()
+	result, err := db.BigtableClient.FindTransferPaths(ctx, search, 500, 25)
+	if err != nil {
+		logger.Errorf("error searching transfer paths route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error searching transfer paths")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{types.APIEth1TransferPathResponse{Status: string(types.CompletedTransferPathJobStatus), Result: result}})
+}
+
+func ApiEth1TransferPathJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	vars := mux.Vars(r)
+
+	job, err := db.GetTransferPathJob(vars["id"])
+	if err == sql.ErrNoRows {
+		sendErrorResponse(w, r.URL.String(), "error job not found")
+		return
+	}
+	if err != nil {
+		logger.Errorf("error getting transfer path job route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting transfer path job")
+		return
+	}
+
+	response := types.APIEth1TransferPathResponse{
+		JobID:  job.ID,
+		Status: string(job.Status),
+		Error:  job.Error.String,
+	}
+	if job.Status == types.CompletedTransferPathJobStatus {
+		response.Result = &types.TransferPathResult{}
+		err = json.Unmarshal(job.RawResult, response.Result)
+		if err != nil {
+			logger.Errorf("error parsing transfer path job result route: %v err: %v", r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "error getting transfer path job")
+			return
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ApiUserFeeRecipients godoc
+// @Summary Lists the fee recipients the authenticated user expects the proposals of the watched validators to pay
+// @Tags User
+// @Produce json
+// @Success 200 {object} types.ApiResponse{data=types.ApiUserFeeRecipients}
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/feerecipients [get]
+func ApiUserFeeRecipients(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	recipients, err := db.GetUserFeeRecipients(claims.UserID, utils.GetNetwork())
+	if err != nil {
+		logger.Errorf("error getting fee recipients route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting fee recipients")
+		return
+	}
+
+	data := types.ApiUserFeeRecipients{FeeRecipients: make([]string, 0, len(recipients))}
+	for _, recipient := range recipients {
+		data.FeeRecipients = append(data.FeeRecipients, common.BytesToAddress(recipient).Hex())
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
+
+// ApiUserFeeRecipientsUpdate godoc
+// @Summary Replaces the fee recipients the authenticated user expects the proposals of the watched validators to pay
+// @Tags User
+// @Description Validators subscribed to the validator_fee_recipient_mismatch event trigger a notification when they propose a block paying any other address. For blocks built through a relay the fee recipient reported by the relay is checked. An empty list disables the checks.
+// @Accept json
+// @Produce json
+// @Param recipients body types.ApiUserFeeRecipients true "The expected fee recipients"
+// @Success 200 {object} types.ApiResponse{data=types.ApiUserFeeRecipients}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/feerecipients [put]
+func ApiUserFeeRecipientsUpdate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	claims := getAuthClaims(r)
+
+	data := types.ApiUserFeeRecipients{}
+	err := json.NewDecoder(r.Body).Decode(&data)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid fee recipients, could not parse body")
+		return
+	}
+	if len(data.FeeRecipients) > types.MaxUserFeeRecipients {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error too many fee recipients, at most %v fee recipients can be configured", types.MaxUserFeeRecipients))
+		return
+	}
+
+	recipients := make([][]byte, 0, len(data.FeeRecipients))
+	for i, recipient := range data.FeeRecipients {
+		if !utils.IsValidEth1Address(recipient) {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error invalid fee recipient %q", recipient))
+			return
+		}
+		recipients = append(recipients, common.HexToAddress(recipient).Bytes())
+		data.FeeRecipients[i] = common.HexToAddress(recipient).Hex()
+	}
+
+	err = db.SetUserFeeRecipients(claims.UserID, utils.GetNetwork(), recipients)
+	if err != nil {
+		logger.Errorf("error updating fee recipients route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error updating fee recipients")
+		return
+	}
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{data})
+}
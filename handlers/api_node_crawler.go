@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+const (
+	nodeCrawlMaxBodySize = 32 * 1024 * 1024
+	networkNodesMaxDays  = 365
+)
+
+// ApiNodeCrawlerReport godoc
+// @Summary Submit the result of a p2p network crawl. The nodes are aggregated into a daily snapshot, a later crawl of the same day replaces the snapshot. Requires the crawler secret in the X-Crawler-Secret header.
+// @Tags Misc
+// @Accept  json
+// @Produce  json
+// @Param  report body types.NodeCrawlReport true "Crawled nodes"
+// @Success 200 {object} types.ApiResponse{data=types.NetworkNodeSnapshot}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/crawler [post]
+func ApiNodeCrawlerReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	secret := r.Header.Get("X-Crawler-Secret")
+	if utils.Config.Frontend.NodeCrawler.Secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(utils.Config.Frontend.NodeCrawler.Secret)) != 1 {
+		sendErrorWithCodeResponse(w, r.URL.String(), "invalid crawler secret", http.StatusUnauthorized)
+		return
+	}
+
+	report := &types.NodeCrawlReport{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, nodeCrawlMaxBodySize)).Decode(report)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "could not parse crawl report")
+		return
+	}
+	if len(report.Nodes) == 0 {
+		sendErrorResponse(w, r.URL.String(), "crawl report does not contain any nodes")
+		return
+	}
+
+	snapshot := services.AggregateNodeCrawl(report)
+	err = db.SaveNetworkNodeSnapshot(snapshot)
+	if err != nil {
+		logger.Errorf("error saving network node snapshot route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error saving crawl report")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{snapshot})
+}
+
+// ApiNetworkNodes godoc
+// @Summary Get the daily snapshots of the p2p network crawler containing the node count, peer counts and the distribution of clients, client versions and countries
+// @Tags Misc
+// @Produce  json
+// @Param  days query int false "Number of past days, defaults to 30, maximum 365"
+// @Success 200 {object} types.ApiResponse{data=[]types.NetworkNodeSnapshot}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/network/nodes [get]
+func ApiNetworkNodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	days := uint64(30)
+	if q := r.URL.Query().Get("days"); q != "" {
+		var err error
+		days, err = strconv.ParseUint(q, 10, 64)
+		if err != nil || days < 1 || days > networkNodesMaxDays {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid days provided, days must be between 1 and %d", networkNodesMaxDays))
+			return
+		}
+	}
+
+	snapshots, err := db.GetNetworkNodeSnapshots(days)
+	if err != nil {
+		logger.Errorf("error getting network node snapshots route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting network node snapshots")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{snapshots})
+}
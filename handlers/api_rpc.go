@@ -0,0 +1,407 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/rpc"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	rpcProxyDefaultRequestsPerMinute = 60
+	rpcProxyDefaultMaxBatchSize      = 10
+	rpcProxyMaxBodySize              = 64 * 1024
+
+	// blocks deeper than this are considered final enough to cache their state forever
+	rpcProxyImmutableDepth = 64
+
+	rpcErrorParse          = -32700
+	rpcErrorInvalidRequest = -32600
+	rpcErrorMethodNotFound = -32601
+	rpcErrorInvalidParams  = -32602
+	rpcErrorInternal       = -32603
+	rpcErrorLimitExceeded  = -32005
+)
+
+type rpcProxyRequest struct {
+	JsonRpc string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+type rpcProxyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcProxyResponse struct {
+	JsonRpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcProxyError  `json:"error,omitempty"`
+}
+
+// ipRateLimiter counts the calls of every client ip within the current minute
+type ipRateLimiter struct {
+	sync.Mutex
+	window int64
+	counts map[string]int
+}
+
+func newIpRateLimiter() *ipRateLimiter {
+	return &ipRateLimiter{counts: make(map[string]int)}
+}
+
+// Allow adds calls to the count of the client ip and reports whether the ip is still within the limit of the current minute
+func (l *ipRateLimiter) Allow(ip string, calls, limit int) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	window := time.Now().Unix() / 60
+	if window != l.window {
+		l.window = window
+		l.counts = make(map[string]int)
+	}
+	l.counts[ip] += calls
+	return l.counts[ip] <= limit
+}
+
+var rpcProxyLimiter = newIpRateLimiter()
+
+func rpcProxyAllow(ip string, calls int) bool {
+	limit := utils.Config.Frontend.RpcProxy.RequestsPerMinute
+	if limit <= 0 {
+		limit = rpcProxyDefaultRequestsPerMinute
+	}
+	return rpcProxyLimiter.Allow(ip, calls, limit)
+}
+
+// ApiEth1RpcProxy godoc
+// @Summary Execute a restricted set of read-only JSON-RPC methods (eth_blockNumber, eth_getTransactionByHash, eth_getBalance). Single and batch requests are supported, indexed transactions are served by the explorer itself, responses are cached and every call counts towards a per ip rate limit.
+// @Tags Execution
+// @Accept  json
+// @Produce  json
+// @Param  request body string true "JSON-RPC 2.0 request or batch of requests"
+// @Success 200 {object} string
+// @Router /api/v1/execution/rpc [post]
+func ApiEth1RpcProxy(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	body, err := readRpcProxyBody(w, r)
+	if err != nil {
+		writeRpcProxyResponse(w, http.StatusOK, rpcProxyErrorResponse(nil, rpcErrorParse, "parse error"))
+		return
+	}
+
+	maxBatchSize := utils.Config.Frontend.RpcProxy.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = rpcProxyDefaultMaxBatchSize
+	}
+
+	batch := len(body) > 0 && body[0] == '['
+	requests := []*rpcProxyRequest{}
+	if batch {
+		err = json.Unmarshal(body, &requests)
+	} else {
+		req := &rpcProxyRequest{}
+		err = json.Unmarshal(body, req)
+		requests = append(requests, req)
+	}
+	if err != nil {
+		writeRpcProxyResponse(w, http.StatusOK, rpcProxyErrorResponse(nil, rpcErrorParse, "parse error"))
+		return
+	}
+	if len(requests) == 0 || len(requests) > maxBatchSize {
+		writeRpcProxyResponse(w, http.StatusOK, rpcProxyErrorResponse(nil, rpcErrorInvalidRequest, fmt.Sprintf("batch must contain between 1 and %d requests", maxBatchSize)))
+		return
+	}
+
+	if !rpcProxyAllow(utils.ClientIp(r), len(requests)) {
+		writeRpcProxyResponse(w, http.StatusTooManyRequests, rpcProxyErrorResponse(nil, rpcErrorLimitExceeded, "rate limit exceeded"))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
+	defer cancel()
+
+	responses := make([]*rpcProxyResponse, len(requests))
+	for i, req := range requests {
+		responses[i] = handleRpcProxyRequest(ctx, req)
+	}
+
+	if batch {
+		writeRpcProxyResponse(w, http.StatusOK, responses)
+	} else {
+		writeRpcProxyResponse(w, http.StatusOK, responses[0])
+	}
+}
+
+func readRpcProxyBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	body := json.RawMessage{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, rpcProxyMaxBodySize)).Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+func writeRpcProxyResponse(w http.ResponseWriter, status int, response interface{}) {
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logger.Errorf("error serializing json-rpc proxy response: %v", err)
+	}
+}
+
+func rpcProxyErrorResponse(id json.RawMessage, code int, message string) *rpcProxyResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return &rpcProxyResponse{
+		JsonRpc: "2.0",
+		ID:      id,
+		Error:   &rpcProxyError{Code: code, Message: message},
+	}
+}
+
+func handleRpcProxyRequest(ctx context.Context, req *rpcProxyRequest) *rpcProxyResponse {
+	if req == nil || req.JsonRpc != "2.0" || req.Method == "" {
+		return rpcProxyErrorResponse(nil, rpcErrorInvalidRequest, "invalid request")
+	}
+
+	var result json.RawMessage
+	var rpcErr *rpcProxyError
+	switch req.Method {
+	case "eth_blockNumber":
+		result, rpcErr = rpcProxyBlockNumber()
+	case "eth_getTransactionByHash":
+		result, rpcErr = rpcProxyTransactionByHash(ctx, req.Params)
+	case "eth_getBalance":
+		result, rpcErr = rpcProxyBalance(ctx, req.Params)
+	default:
+		return rpcProxyErrorResponse(req.ID, rpcErrorMethodNotFound, fmt.Sprintf("the method %s is not supported", req.Method))
+	}
+	if rpcErr != nil {
+		return rpcProxyErrorResponse(req.ID, rpcErr.Code, rpcErr.Message)
+	}
+
+	return &rpcProxyResponse{
+		JsonRpc: "2.0",
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+func rpcProxyBlockNumber() (json.RawMessage, *rpcProxyError) {
+	number := services.LatestEth1BlockNumber()
+	if number == 0 {
+		var err error
+		number, err = rpc.CurrentErigonClient.GetLatestEth1BlockNumber()
+		if err != nil {
+			logger.Errorf("error retrieving latest block number for json-rpc proxy: %v", err)
+			return nil, &rpcProxyError{Code: rpcErrorInternal, Message: "internal error"}
+		}
+	}
+	return json.RawMessage(fmt.Sprintf("%q", hexutil.EncodeUint64(number))), nil
+}
+
+type rpcProxyAccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// rpcProxyTransaction is a mined transaction in the format of eth_getTransactionByHash, the gas price of a dynamic fee transaction
+// is the price it effectively paid. The signature of a transaction is not saved when it is indexed, so v, r and s are left out.
+type rpcProxyTransaction struct {
+	BlockHash            common.Hash           `json:"blockHash"`
+	BlockNumber          hexutil.Uint64        `json:"blockNumber"`
+	From                 common.Address        `json:"from"`
+	Gas                  hexutil.Uint64        `json:"gas"`
+	GasPrice             *hexutil.Big          `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big          `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big          `json:"maxPriorityFeePerGas,omitempty"`
+	Hash                 common.Hash           `json:"hash"`
+	Input                hexutil.Bytes         `json:"input"`
+	Nonce                hexutil.Uint64        `json:"nonce"`
+	To                   *common.Address       `json:"to"`
+	TransactionIndex     hexutil.Uint64        `json:"transactionIndex"`
+	Value                *hexutil.Big          `json:"value"`
+	Type                 hexutil.Uint64        `json:"type"`
+	AccessList           []rpcProxyAccessTuple `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big          `json:"chainId,omitempty"`
+}
+
+func newRpcProxyTransaction(tx *types.Eth1Transaction, block *types.Eth1Block) *rpcProxyTransaction {
+	var baseFee *big.Int
+	if len(block.GetBaseFee()) > 0 {
+		baseFee = new(big.Int).SetBytes(block.GetBaseFee())
+	}
+
+	result := &rpcProxyTransaction{
+		BlockHash:   common.BytesToHash(block.GetHash()),
+		BlockNumber: hexutil.Uint64(block.GetNumber()),
+		From:        common.BytesToAddress(tx.GetFrom()),
+		Gas:         hexutil.Uint64(tx.GetGas()),
+		GasPrice:    (*hexutil.Big)(db.EffectiveGasPrice(tx, baseFee)),
+		Hash:        common.BytesToHash(tx.GetHash()),
+		Input:       tx.GetData(),
+		Nonce:       hexutil.Uint64(tx.GetNonce()),
+		Value:       (*hexutil.Big)(new(big.Int).SetBytes(tx.GetValue())),
+		Type:        hexutil.Uint64(tx.GetType()),
+	}
+	// the recipient of a contract creation is null
+	if len(tx.GetTo()) > 0 {
+		to := common.BytesToAddress(tx.GetTo())
+		result.To = &to
+	}
+	if tx.GetType() == ethtypes.DynamicFeeTxType {
+		result.MaxFeePerGas = (*hexutil.Big)(new(big.Int).SetBytes(tx.GetMaxFeePerGas()))
+		result.MaxPriorityFeePerGas = (*hexutil.Big)(new(big.Int).SetBytes(tx.GetMaxPriorityFeePerGas()))
+	}
+	if tx.GetType() != ethtypes.LegacyTxType {
+		result.ChainID = (*hexutil.Big)(new(big.Int).SetBytes(tx.GetChainId()))
+		result.AccessList = make([]rpcProxyAccessTuple, 0, len(tx.GetAccessList()))
+		for _, tuple := range tx.GetAccessList() {
+			keys := make([]common.Hash, 0, len(tuple.GetStorageKeys()))
+			for _, key := range tuple.GetStorageKeys() {
+				keys = append(keys, common.BytesToHash(key))
+			}
+			result.AccessList = append(result.AccessList, rpcProxyAccessTuple{Address: common.BytesToAddress(tuple.GetAddress()), StorageKeys: keys})
+		}
+	}
+
+	// the transaction index is not part of the raw transaction
+	for i, blockTx := range block.GetTransactions() {
+		if blockTx == tx {
+			result.TransactionIndex = hexutil.Uint64(i)
+		}
+	}
+	return result
+}
+
+func rpcProxyTransactionByHash(ctx context.Context, params []json.RawMessage) (json.RawMessage, *rpcProxyError) {
+	var hash string
+	if len(params) != 1 || json.Unmarshal(params[0], &hash) != nil || !utils.IsValidEth1Tx(strings.TrimPrefix(hash, "0x")) {
+		return nil, &rpcProxyError{Code: rpcErrorInvalidParams, Message: "invalid params, expected a transaction hash"}
+	}
+	hash = "0x" + strings.ToLower(strings.TrimPrefix(hash, "0x"))
+
+	cacheKey := fmt.Sprintf("%d:rpcproxy:tx:%s", utils.Config.Chain.Config.DepositChainID, hash)
+	if cached, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, time.Minute*10); err == nil {
+		return json.RawMessage(cached), nil
+	}
+
+	// indexed transactions are served from bigtable, only unknown transactions are looked up by the node
+	rawTx, block, err := db.BigtableClient.GetRawEth1Transaction(ctx, common.FromHex(hash))
+	if err != nil {
+		logger.Warnf("error retrieving tx %v from bigtable for json-rpc proxy, falling back to the node: %v", hash, err)
+	}
+	if rawTx != nil {
+		result, err := json.Marshal(newRpcProxyTransaction(rawTx, block))
+		if err != nil {
+			logger.Errorf("error serializing tx %v for json-rpc proxy: %v", hash, err)
+			return nil, &rpcProxyError{Code: rpcErrorInternal, Message: "internal error"}
+		}
+		err = cache.TieredCache.SetString(cacheKey, string(result), time.Minute*30)
+		if err != nil {
+			logger.Errorf("error caching json-rpc proxy result for tx %v: %v", hash, err)
+		}
+		return result, nil
+	}
+
+	result := json.RawMessage{}
+	err = rpc.CurrentErigonClient.GetRPCClient().CallContext(ctx, &result, "eth_getTransactionByHash", hash)
+	if err != nil {
+		logger.Errorf("error proxying eth_getTransactionByHash for tx %v: %v", hash, err)
+		return nil, &rpcProxyError{Code: rpcErrorInternal, Message: "internal error"}
+	}
+
+	// only mined transactions are cached, pending or unknown transactions might change at any time
+	tx := struct {
+		BlockNumber *string `json:"blockNumber"`
+	}{}
+	if json.Unmarshal(result, &tx) == nil && tx.BlockNumber != nil {
+		err = cache.TieredCache.SetString(cacheKey, string(result), time.Minute*30)
+		if err != nil {
+			logger.Errorf("error caching json-rpc proxy result for tx %v: %v", hash, err)
+		}
+	}
+	return result, nil
+}
+
+func rpcProxyBalance(ctx context.Context, params []json.RawMessage) (json.RawMessage, *rpcProxyError) {
+	var address, block string
+	if len(params) < 1 || len(params) > 2 || json.Unmarshal(params[0], &address) != nil || !utils.IsEth1Address(strings.TrimPrefix(address, "0x")) {
+		return nil, &rpcProxyError{Code: rpcErrorInvalidParams, Message: "invalid params, expected an address and an optional block number or tag"}
+	}
+	if len(params) == 2 && json.Unmarshal(params[1], &block) != nil {
+		return nil, &rpcProxyError{Code: rpcErrorInvalidParams, Message: "invalid params, expected an address and an optional block number or tag"}
+	}
+	address = "0x" + strings.ToLower(strings.TrimPrefix(address, "0x"))
+	if block == "" {
+		block = "latest"
+	}
+
+	// balances at a fixed height never change once the block is deep enough, latest balances are cached per head block
+	latest := services.LatestEth1BlockNumber()
+	cacheKey := ""
+	cacheDuration := time.Duration(0)
+	switch block {
+	case "latest":
+		if latest > 0 {
+			cacheKey = fmt.Sprintf("%d:rpcproxy:balance:%s:%d", utils.Config.Chain.Config.DepositChainID, address, latest)
+			cacheDuration = time.Minute
+		}
+	case "earliest", "pending", "safe", "finalized":
+	default:
+		number, err := hexutil.DecodeUint64(block)
+		if err != nil {
+			return nil, &rpcProxyError{Code: rpcErrorInvalidParams, Message: "invalid params, invalid block number"}
+		}
+		if latest > rpcProxyImmutableDepth && number < latest-rpcProxyImmutableDepth {
+			cacheKey = fmt.Sprintf("%d:rpcproxy:balance:%s:%d", utils.Config.Chain.Config.DepositChainID, address, number)
+			cacheDuration = time.Hour * 24
+		}
+	}
+
+	if cacheKey != "" {
+		if cached, err := cache.TieredCache.GetStringWithLocalTimeout(cacheKey, cacheDuration); err == nil {
+			return json.RawMessage(cached), nil
+		}
+	}
+
+	result := json.RawMessage{}
+	err := rpc.CurrentErigonClient.GetRPCClient().CallContext(ctx, &result, "eth_getBalance", address, block)
+	if err != nil {
+		logger.Errorf("error proxying eth_getBalance for address %v at %v: %v", address, block, err)
+		return nil, &rpcProxyError{Code: rpcErrorInternal, Message: "internal error"}
+	}
+
+	if cacheKey != "" {
+		err = cache.TieredCache.SetString(cacheKey, string(result), cacheDuration)
+		if err != nil {
+			logger.Errorf("error caching json-rpc proxy balance of address %v: %v", address, err)
+		}
+	}
+	return result, nil
+}
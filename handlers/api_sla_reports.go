@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// reports are generated in the background, this limit only protects the job queue from oversized requests
+const slaReportMaxValidators = 1000
+
+// ApiValidatorSlaReport godoc
+// @Summary Queue the generation of a monthly uptime and performance report (attestation participation, proposals and slashings) of a set of validators. Use the returned job id to poll the status of the report and download it once it has been completed.
+// @Tags Validator
+// @Produce  json
+// @Param  validators query string true "Up to 1000 validator indicesOrPubkeys, comma separated"
+// @Param  month query string true "Month of the report formatted as YYYY-MM"
+// @Param  format query string false "Format of the report, pdf (default) or csv"
+// @Success 200 {object} types.ApiResponse{data=types.APISlaReportResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/slareport [get]
+func ApiValidatorSlaReport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+	q := r.URL.Query()
+
+	validators, err := parseApiValidatorParamToIndices(q.Get("validators"), slaReportMaxValidators)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "invalid validators provided: "+err.Error())
+		return
+	}
+
+	month := q.Get("month")
+	_, err = services.ParseSlaReportMonth(month)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	format := types.SlaReportFormat(q.Get("format"))
+	if format == "" {
+		format = types.SlaReportFormatPdf
+	}
+	if format != types.SlaReportFormatPdf && format != types.SlaReportFormatCsv {
+		sendErrorResponse(w, r.URL.String(), "invalid format provided, the format must be pdf or csv")
+		return
+	}
+
+	job, err := db.CreateSlaReportJob(validators, month, format)
+	if err != nil {
+		logger.Errorf("error creating sla report job route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error creating sla report")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{slaReportResponse(job)})
+}
+
+// ApiValidatorSlaReportJob godoc
+// @Summary Get the status of a queued validator sla report
+// @Tags Validator
+// @Produce  json
+// @Param  id path string true "Job id returned when the report was requested"
+// @Success 200 {object} types.ApiResponse{data=types.APISlaReportResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/slareport/job/{id} [get]
+func ApiValidatorSlaReportJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	job, err := db.GetSlaReportJob(mux.Vars(r)["id"])
+	if err == sql.ErrNoRows {
+		sendErrorResponse(w, r.URL.String(), "error job not found")
+		return
+	}
+	if err != nil {
+		logger.Errorf("error getting sla report job route: %v err: %v", r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error getting sla report job")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{slaReportResponse(job)})
+}
+
+// ApiValidatorSlaReportDownload godoc
+// @Summary Download a completed validator sla report as pdf or csv file
+// @Tags Validator
+// @Produce  application/pdf,text/csv
+// @Param  id path string true "Job id returned when the report was requested"
+// @Success 200 {file} file
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/validators/slareport/job/{id}/download [get]
+func ApiValidatorSlaReportDownload(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	job, err := db.GetSlaReportJob(mux.Vars(r)["id"])
+	if err == sql.ErrNoRows {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorResponse(w, r.URL.String(), "error job not found")
+		return
+	}
+	if err != nil {
+		logger.Errorf("error getting sla report job route: %v err: %v", r.URL.String(), err)
+		w.Header().Set("Content-Type", "application/json")
+		sendServerErrorResponse(w, r.URL.String(), "error getting sla report job")
+		return
+	}
+	if job.Status != types.CompletedSlaReportJobStatus {
+		w.Header().Set("Content-Type", "application/json")
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error report is not available, job status is %v", job.Status))
+		return
+	}
+
+	contentType := "application/pdf"
+	if job.Format == types.SlaReportFormatCsv {
+		contentType = "text/csv"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=sla_report_%v.%v", job.Month, job.Format))
+
+	_, err = w.Write(job.Result)
+	if err != nil {
+		logger.WithError(err).WithField("route", r.URL.String()).Error("error writing response")
+	}
+}
+
+func slaReportResponse(job *types.SlaReportJob) types.APISlaReportResponse {
+	return types.APISlaReportResponse{
+		JobID:      job.ID,
+		Status:     string(job.Status),
+		Month:      job.Month,
+		Format:     string(job.Format),
+		Validators: job.Validators,
+		Error:      job.Error.String,
+	}
+}
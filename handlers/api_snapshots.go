@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"eth2-exporter/services"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/exp/slices"
+)
+
+// ApiSnapshot godoc
+// @Summary Get a periodically refreshed static snapshot of frequently requested data. Available snapshots are latestBlocks, gasOracle and networkStats. If a CDN is configured the request is redirected to the CDN copy of the snapshot.
+// @Tags Misc
+// @Produce  json
+// @Param  name path string true "Name of the snapshot"
+// @Success 200 {object} types.APISnapshot
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/snapshots/{name} [get]
+func ApiSnapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+
+	name := mux.Vars(r)["name"]
+	if !slices.Contains(services.SnapshotNames, name) {
+		sendErrorResponse(w, r.URL.String(), fmt.Sprintf("invalid snapshot provided, available snapshots are %v", strings.Join(services.SnapshotNames, ", ")))
+		return
+	}
+
+	maxAge := int(services.SnapshotInterval().Seconds())
+	if publicURL := utils.Config.Frontend.Snapshots.PublicURL; publicURL != "" {
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+		http.Redirect(w, r, fmt.Sprintf("%s/%s", strings.TrimSuffix(publicURL, "/"), services.SnapshotObjectName(name)), http.StatusTemporaryRedirect)
+		return
+	}
+
+	snapshot, err := services.LatestSnapshot(name)
+	if err != nil {
+		logger.Errorf("error retrieving %v snapshot: %v", name, err)
+		sendServerErrorResponse(w, r.URL.String(), "snapshot is currently not available")
+		return
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", maxAge))
+	_, err = w.Write(snapshot)
+	if err != nil {
+		logger.Errorf("error writing %v snapshot: %v", name, err)
+	}
+}
@@ -53,6 +53,7 @@ func Charts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	data.Data = &types.ChartsPageData{ChartsPageDataCharts: cpd, Disclaimer: disclaimer}
+	setEdgeCacheHeaders(w, r, utils.EdgeCacheChartKey("all"))
 
 	if handleTemplateError(w, r, "charts.go", "Charts", "Done", chartsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
 		return // an error has occurred and was processed
@@ -107,6 +108,7 @@ func GenericChart(w http.ResponseWriter, r *http.Request) {
 	SetPageDataTitle(data, fmt.Sprintf("%v Chart", chartData.Title))
 	data.Meta.Path = "/charts/" + chartVar
 	data.Data = chartData
+	setEdgeCacheHeaders(w, r, utils.EdgeCacheChartKey(chartVar))
 
 	if handleTemplateError(w, r, "charts.go", "GenericChart", "Done", genericChartTemplate.ExecuteTemplate(w, "layout", data)) != nil {
 		return // an error has occurred and was processed
@@ -127,9 +129,11 @@ func GenericChartData(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var chartData *types.GenericChartData
+	chartPath := ""
 	for _, d := range chartsPageData {
 		if fmt.Sprintf("chart-holder-%d", d.Order) == chartVar {
 			chartData = d.Data
+			chartPath = d.Path
 			break
 		}
 	}
@@ -139,6 +143,7 @@ func GenericChartData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setEdgeCacheHeaders(w, r, utils.EdgeCacheChartKey(chartPath))
 	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{chartData.Series})
 }
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
@@ -40,13 +41,13 @@ func GetValidatorOnlineThresholdSlot() uint64 {
 }
 
 // GetValidatorEarnings will return the earnings (last day, week, month and total) of selected validators
-func GetValidatorEarnings(validators []uint64, currency string) (*types.ValidatorEarnings, map[uint64]*types.Validator, error) {
+func GetValidatorEarnings(ctx context.Context, validators []uint64, currency string) (*types.ValidatorEarnings, map[uint64]*types.Validator, error) {
 	validatorsPQArray := pq.Array(validators)
 	latestFinalizedEpoch := services.LatestFinalizedEpoch()
 
 	balancesMap := make(map[uint64]*types.Validator, 0)
 
-	latestBalances, err := db.BigtableClient.GetValidatorBalanceHistory(validators, latestFinalizedEpoch, latestFinalizedEpoch)
+	latestBalances, err := db.BigtableClient.GetValidatorBalanceHistory(ctx, validators, latestFinalizedEpoch, latestFinalizedEpoch)
 	if err != nil {
 		logger.Errorf("error getting validator balance data in GetValidatorEarnings: %v", err)
 		return nil, nil, err
@@ -204,7 +205,7 @@ func GetValidatorEarnings(validators []uint64, currency string) (*types.Validato
 	}
 
 	// retrieve cl income not yet in stats
-	currentDayProposerIncome, err := db.GetCurrentDayProposerIncomeTotal(validators)
+	currentDayProposerIncome, err := db.GetCurrentDayProposerIncomeTotal(ctx, validators)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -428,6 +429,37 @@ func GetCurrency(r *http.Request) string {
 	return "ETH"
 }
 
+// GetTimezone returns the IANA timezone the user selected for rendering timestamps.
+// An empty string means that timestamps are rendered in the local timezone of the browser.
+func GetTimezone(r *http.Request) string {
+	cookie, err := r.Cookie("timezone")
+	if err != nil || cookie.Value == "" {
+		return ""
+	}
+
+	if _, err := time.LoadLocation(cookie.Value); err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// GetNumberLocale returns the code of the number format the user selected for rendering numbers and amounts
+func GetNumberLocale(r *http.Request) string {
+	if cookie, err := r.Cookie("number_locale"); err == nil && utils.IsNumberLocale(cookie.Value) {
+		return cookie.Value
+	}
+	return utils.DefaultNumberLocale
+}
+
+// GetTimestampMode returns whether timestamps should be rendered relative to now ("5 mins ago") or absolute
+func GetTimestampMode(r *http.Request) string {
+	if cookie, err := r.Cookie("timestamp_mode"); err == nil && cookie.Value == utils.TimestampModeAbsolute {
+		return utils.TimestampModeAbsolute
+	}
+
+	return utils.TimestampModeRelative
+}
+
 func GetCurrencySymbol(r *http.Request) string {
 
 	cookie, err := r.Cookie("currency")
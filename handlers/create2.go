@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/sync/errgroup"
+)
+
+// computeCreate2Address returns the address a contract deployed by deployer via CREATE2 with the given salt and init code hash will have.
+// The salt may be shorter than 32 bytes and is left padded in that case.
+func computeCreate2Address(deployer, salt, initCodeHash string) (common.Address, error) {
+	deployer = strings.ToLower(strings.TrimPrefix(deployer, "0x"))
+	if !utils.IsEth1Address(deployer) {
+		return common.Address{}, fmt.Errorf("invalid deployer address")
+	}
+
+	saltBytes, err := decodeHexInput(salt)
+	if err != nil || len(saltBytes) > 32 {
+		return common.Address{}, fmt.Errorf("invalid salt, the salt must be a hex encoded value of at most 32 bytes")
+	}
+	var salt32 [32]byte
+	copy(salt32[32-len(saltBytes):], saltBytes)
+
+	hash, err := decodeHexInput(initCodeHash)
+	if err != nil || len(hash) != 32 {
+		return common.Address{}, fmt.Errorf("invalid init code hash, the init code hash must be a hex encoded 32 byte keccak256 hash")
+	}
+
+	return crypto.CreateAddress2(common.HexToAddress(deployer), salt32, hash), nil
+}
+
+// decodeHexInput decodes a hex value entered by a user, the 0x prefix and a leading zero nibble are optional
+func decodeHexInput(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if len(s)%2 == 1 {
+		s = "0" + s
+	}
+	return hex.DecodeString(s)
+}
+
+// getAddressDeployment returns how the contract at an address was deployed and whether any transactions of the address have been indexed
+func getAddressDeployment(ctx context.Context, address common.Address) (*types.Eth1InternalTransactionIndexed, bool, error) {
+	var creation *types.Eth1InternalTransactionIndexed
+	hasTransactions := false
+
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		var err error
+		creation, err = db.GetEth1Store().GetContractCreation(ctx, address.Bytes())
+		return err
+	})
+	g.Go(func() error {
+		txs, err := db.BigtableClient.GetRecentEth1TxForAddress(ctx, address.Bytes(), 1)
+		hasTransactions = len(txs) > 0
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, false, err
+	}
+	return creation, creation != nil || hasTransactions, nil
+}
+
+// Create2 will return the page to pre-compute the address of a CREATE2 deployment
+func Create2(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "create2.html")
+	var create2Template = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "create2", "/tools/create2", "CREATE2 Address Calculator", templateFiles)
+
+	q := r.URL.Query()
+	pageData := &types.Create2PageData{
+		Deployer:     q.Get("deployer"),
+		Salt:         q.Get("salt"),
+		InitCodeHash: q.Get("initCodeHash"),
+	}
+	if pageData.Deployer != "" || pageData.Salt != "" || pageData.InitCodeHash != "" {
+		address, err := computeCreate2Address(pageData.Deployer, pageData.Salt, pageData.InitCodeHash)
+		if err != nil {
+			pageData.Error = err.Error()
+		} else {
+			pageData.Address = address.Hex()
+			pageData.Creation, pageData.HasActivity, err = getAddressDeployment(r.Context(), address)
+			if err != nil {
+				logger.Errorf("error retrieving deployment of create2 address %v: %v", address, err)
+				http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+				return
+			}
+		}
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "create2.go", "Create2", "", create2Template.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// ApiCreate2 godoc
+// @Summary Compute the address of a CREATE2 deployment and check whether the address already has indexed activity
+// @Tags Execution
+// @Produce json
+// @Param deployer query string true "Address of the deploying contract"
+// @Param salt query string true "Hex encoded salt of at most 32 bytes, shorter salts are left padded"
+// @Param init_code_hash query string true "Hex encoded keccak256 hash of the init code"
+// @Success 200 {object} types.ApiResponse{data=types.APICreate2Response}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/create2 [get]
+func ApiCreate2(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	address, err := computeCreate2Address(q.Get("deployer"), q.Get("salt"), q.Get("init_code_hash"))
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "error "+err.Error())
+		return
+	}
+
+	creation, hasActivity, err := getAddressDeployment(r.Context(), address)
+	if err != nil {
+		logger.Errorf("error retrieving deployment of create2 address %v route: %v err: %v", address, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error retrieving address activity")
+		return
+	}
+
+	response := &types.APICreate2Response{
+		Address:     address.Hex(),
+		HasActivity: hasActivity,
+	}
+	if creation != nil {
+		response.Creation = &types.APIContractCreation{
+			TxHash:      fmt.Sprintf("0x%x", creation.ParentHash),
+			BlockNumber: creation.BlockNumber,
+			Time:        creation.Time.AsTime(),
+			Deployer:    utils.FixAddressCasing(fmt.Sprintf("%x", creation.From)),
+			Type:        creation.Type,
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
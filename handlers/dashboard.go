@@ -111,7 +111,7 @@ func Heatmap(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
 		return
 	}
-	incomeData, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(validators, endEpoch-100, endEpoch)
+	incomeData, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(r.Context(), validators, endEpoch-100, endEpoch)
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Error("error loading validator income history data")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -169,7 +169,7 @@ func Dashboard(w http.ResponseWriter, r *http.Request) {
 	epoch := services.LatestEpoch()
 	dashboardData.CappellaHasHappened = epoch >= (utils.Config.Chain.Config.CappellaForkEpoch)
 
-	dashboardData.NextWithdrawalRow, err = getNextWithdrawalRow(queryValidators)
+	dashboardData.NextWithdrawalRow, err = getNextWithdrawalRow(r.Context(), queryValidators)
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Error("error calculating next withdrawal row")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -184,7 +184,7 @@ func Dashboard(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getNextWithdrawalRow(queryValidators []uint64) ([][]interface{}, error) {
+func getNextWithdrawalRow(ctx context.Context, queryValidators []uint64) ([][]interface{}, error) {
 	if len(queryValidators) == 0 {
 		return nil, nil
 	}
@@ -226,7 +226,7 @@ func getNextWithdrawalRow(queryValidators []uint64) ([][]interface{}, error) {
 	}
 
 	// retrieve up2date balances for all valid validators from bigtable
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(validatorIds, epoch, epoch)
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(ctx, validatorIds, epoch, epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -336,16 +336,16 @@ func DashboardDataBalanceCombined(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	g, _ := errgroup.WithContext(context.Background())
+	g, _ := errgroup.WithContext(r.Context())
 	var incomeHistoryChartData []*types.ChartDataPoint
 	var executionChartData []*types.ChartDataPoint
 	g.Go(func() error {
-		incomeHistoryChartData, _, err = db.GetValidatorIncomeHistoryChart(queryValidators, currency)
+		incomeHistoryChartData, _, err = db.GetValidatorIncomeHistoryChart(r.Context(), queryValidators, currency)
 		return err
 	})
 
 	g.Go(func() error {
-		executionChartData, err = getExecutionChartData(queryValidators, currency)
+		executionChartData, err = getExecutionChartData(r.Context(), queryValidators, currency)
 		return err
 	})
 
@@ -371,14 +371,14 @@ func DashboardDataBalanceCombined(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func getExecutionChartData(indices []uint64, currency string) ([]*types.ChartDataPoint, error) {
+func getExecutionChartData(ctx context.Context, indices []uint64, currency string) ([]*types.ChartDataPoint, error) {
 	var limit uint64 = 300
-	blockList, consMap, err := findExecBlockNumbersByProposerIndex(indices, 0, limit, false)
+	blockList, consMap, err := findExecBlockNumbersByProposerIndex(indices, 0, limit, false, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(blockList, limit)
+	blocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(ctx, blockList, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -440,7 +440,7 @@ func DashboardDataBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	incomeHistoryChartData, _, err := db.GetValidatorIncomeHistoryChart(queryValidators, currency)
+	incomeHistoryChartData, _, err := db.GetValidatorIncomeHistoryChart(r.Context(), queryValidators, currency)
 	if err != nil {
 		logger.Errorf("failed to genereate income history chart data for dashboard view: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -630,7 +630,7 @@ func DashboardDataValidators(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(filterArr, services.LatestEpoch(), services.LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), filterArr, services.LatestEpoch(), services.LatestEpoch())
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance data")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -740,7 +740,7 @@ func DashboardDataEarnings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	earnings, _, err := GetValidatorEarnings(queryValidators, GetCurrency(r))
+	earnings, _, err := GetValidatorEarnings(r.Context(), queryValidators, GetCurrency(r))
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator earnings")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -786,7 +786,7 @@ func DashboardDataEffectiveness(w http.ResponseWriter, r *http.Request) {
 
 	var avgIncDistance []float64
 
-	effectiveness, err := db.BigtableClient.GetValidatorEffectiveness(activeValidators, services.LatestEpoch()-1)
+	effectiveness, err := db.BigtableClient.GetValidatorEffectiveness(r.Context(), activeValidators, services.LatestEpoch()-1)
 	for _, e := range effectiveness {
 		avgIncDistance = append(avgIncDistance, e.AttestationEfficiency)
 	}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func edgeCacheMaxAge() time.Duration {
+	if utils.Config.Frontend.EdgeCache.MaxAge > 0 {
+		return utils.Config.Frontend.EdgeCache.MaxAge
+	}
+	return time.Hour * 24
+}
+
+func edgeCacheKeyHeader() string {
+	if utils.Config.Frontend.EdgeCache.KeyHeader != "" {
+		return utils.Config.Frontend.EdgeCache.KeyHeader
+	}
+	return "Surrogate-Key"
+}
+
+// edgeCacheStorable returns false for responses rendered for a signed in user or with the currency or timezone chosen by
+// the user and for the additional networks, whose indexers do not purge the cdn
+func edgeCacheStorable(r *http.Request) bool {
+	if _, err := r.Cookie("currency"); err == nil {
+		return false
+	}
+	if _, err := r.Cookie("timezone"); err == nil {
+		return false
+	}
+	return requestNetwork(r).IsDefault() && !getUser(r).Authenticated
+}
+
+// setEdgeCacheHeaders tags a successful response with the surrogate keys it is purged by and allows the cdn to cache it
+// until then
+func setEdgeCacheHeaders(w http.ResponseWriter, r *http.Request, keys ...string) {
+	if !utils.Config.Frontend.EdgeCache.Enabled {
+		return
+	}
+	w.Header().Set(edgeCacheKeyHeader(), strings.Join(keys, " "))
+	if !edgeCacheStorable(r) {
+		w.Header().Set("Surrogate-Control", "no-store")
+		return
+	}
+	w.Header().Set("Surrogate-Control", fmt.Sprintf("max-age=%d", int64(edgeCacheMaxAge().Seconds())))
+}
@@ -2,25 +2,83 @@ package handlers
 
 import (
 	"context"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/eth1data"
+	"eth2-exporter/rpc"
+	"eth2-exporter/services"
 	"eth2-exporter/templates"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
 	"html/template"
+	"math"
 	"math/big"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/gorilla/mux"
 	"golang.org/x/sync/errgroup"
 )
 
+// ignorePartialResult drops errors that only report skipped rows and records them in partial, the page is rendered with the remaining rows
+func ignorePartialResult(err error, partial *int32) error {
+	if db.IsPartialResult(err) {
+		atomic.StoreInt32(partial, 1)
+		return nil
+	}
+	return err
+}
+
+// resolveAddressAlias resolves an ens name or the slug of an address label to the canonical address, nil is returned if the alias is unknown
+func resolveAddressAlias(r *http.Request, alias string) *common.Address {
+	if utils.IsEnsName(alias) {
+		// ens names are only indexed for the configured chain
+		if !requestNetwork(r).IsDefault() {
+			return nil
+		}
+		address, err := eth1data.ResolveEnsName(r.Context(), alias)
+		if err != nil {
+			logger.Warnf("error resolving ens name %v: %v", alias, err)
+			return nil
+		}
+		return &address
+	}
+
+	slug := utils.AddressLabelSlug(alias)
+	if slug == "" {
+		return nil
+	}
+	address, err := eth1StoreForRequest(r).GetAddressForLabel(r.Context(), slug)
+	if err != nil {
+		logger.Errorf("error getting address of label %v: %v", slug, err)
+		return nil
+	}
+	if len(address) != 20 {
+		return nil
+	}
+	resolved := common.BytesToAddress(address)
+	return &resolved
+}
+
+// addressQueryContext limits the index scans of an address table query to the latency budget, a query that exceeds it
+// returns the rows found so far as truncated page
+func addressQueryContext(r *http.Request) context.Context {
+	budget := utils.Config.Frontend.AddressQueryBudget
+	if budget <= 0 {
+		budget = time.Second * 2
+	}
+	return db.WithLatencyBudget(r.Context(), budget)
+}
+
 func Eth1Address(w http.ResponseWriter, r *http.Request) {
 	templateFiles := append(layoutTemplateFiles, "sprites.html", "execution/address.html")
 	var eth1AddressTemplate = templates.GetTemplate(templateFiles...)
@@ -30,6 +88,11 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 	address := template.HTMLEscapeString(vars["address"])
 	isValid := utils.IsEth1Address(address)
 	if !isValid {
+		if resolved := resolveAddressAlias(r, vars["address"]); resolved != nil {
+			// aliases can move to another address, the redirect must not be cached permanently
+			http.Redirect(w, r, networkPath(r, "/address/"+resolved.Hex()), http.StatusFound)
+			return
+		}
 		templateFiles = append(layoutTemplateFiles, "sprites.html", "execution/addressNotFound.html")
 		data := InitPageData(w, r, "blockchain", "/address", "not found", templateFiles)
 
@@ -49,16 +112,27 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 	addressBytes := common.FromHex(address)
 	data := InitPageData(w, r, "blockchain", "/address", fmt.Sprintf("Address 0x%x", addressBytes), templateFiles)
 
-	metadata, err := db.BigtableClient.GetMetadataForAddress(addressBytes)
+	metadata, err := eth1StoreForRequest(r).GetMetadataForAddress(r.Context(), addressBytes)
 	if err != nil {
 		logger.Errorf("error retieving balances for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
 		return
 	}
+	network := requestNetwork(r)
+	var activity *types.AddressActivity
+	if network.IsDefault() {
+		services.RecordAddressVisit(addressBytes)
+		activity = services.GetAddressActivity(addressBytes)
+	}
+
 	g := new(errgroup.Group)
 	g.SetLimit(9)
 
 	isContract := false
+	var selfDestruct *types.Eth1InternalTransactionIndexed
+	var contractCreation *types.Eth1InternalTransactionIndexed
+	var fundedBy *types.Eth1InternalTransactionIndexed
+	var risk *types.AddressRisk
 	txns := &types.DataTableResponse{}
 	internal := &types.DataTableResponse{}
 	erc20 := &types.DataTableResponse{}
@@ -67,75 +141,121 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 	blocksMined := &types.DataTableResponse{}
 	unclesMined := &types.DataTableResponse{}
 	withdrawals := &types.DataTableResponse{}
+	contractInteractions := &types.DataTableResponse{}
+	nfts := &types.DataTableResponse{}
+	var summary *types.AddressSummary
 	withdrawalSummary := template.HTML("0")
+	privateLabel := ""
+	// set if any of the tables skipped corrupted or missing rows
+	partialResult := int32(0)
 
-	g.Go(func() error {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
-		defer cancel()
+	if network.IsDefault() {
+		g.Go(func() error {
+			ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
+			defer cancel()
 
-		isContract, err = eth1data.IsContract(ctx, common.BytesToAddress(addressBytes))
+			isContract, err = eth1data.IsContract(ctx, common.BytesToAddress(addressBytes))
+			return err
+		})
+	}
+	if data.User.Authenticated {
+		g.Go(func() error {
+			labels, err := db.GetUserAddressLabelsByAddress(data.User.UserID, [][]byte{addressBytes})
+			if err != nil {
+				return err
+			}
+			privateLabel = labels[string(addressBytes)]
+			return nil
+		})
+	}
+	g.Go(func() error {
+		var err error
+		summary, err = eth1StoreForRequest(r).GetAddressSummary(r.Context(), addressBytes)
 		return err
 	})
 	g.Go(func() error {
 		var err error
-		txns, err = db.BigtableClient.GetAddressTransactionsTableData(addressBytes, "", "")
-		if err != nil {
+		selfDestruct, err = eth1StoreForRequest(r).GetContractSelfDestruct(r.Context(), addressBytes)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		contractCreation, err = eth1StoreForRequest(r).GetContractCreation(r.Context(), addressBytes)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		fundedBy, err = eth1StoreForRequest(r).GetAddressFunding(r.Context(), addressBytes)
+		return err
+	})
+	if network.IsDefault() {
+		g.Go(func() error {
+			var err error
+			risk, err = services.GetAddressRisk(addressBytes)
 			return err
+		})
+	}
+	g.Go(func() error {
+		if activity != nil {
+			txns = activity.Transactions
+			return nil
 		}
-		return nil
+		var err error
+		txns, err = eth1StoreForRequest(r).GetAddressTransactionsTableData(addressQueryContext(r), addressBytes, db.FILTER_TIME, "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	// if !utils.Config.Frontend.Debug {
 	g.Go(func() error {
+		if activity != nil {
+			internal = activity.InternalTransactions
+			return nil
+		}
 		var err error
-		internal, err = db.BigtableClient.GetAddressInternalTableData(addressBytes, "", "")
-		if err != nil {
-			return err
+		internal, err = eth1StoreForRequest(r).GetAddressInternalTableData(addressQueryContext(r), addressBytes, "", "")
+		return ignorePartialResult(err, &partialResult)
+	})
+	g.Go(func() error {
+		if activity != nil {
+			erc20 = activity.Erc20Transfers
+			return nil
 		}
-		return nil
+		var err error
+		erc20, err = eth1StoreForRequest(r).GetAddressErc20TableData(addressQueryContext(r), addressBytes, "", "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		erc20, err = db.BigtableClient.GetAddressErc20TableData(addressBytes, "", "")
-		if err != nil {
-			return err
-		}
-		return nil
+		erc721, err = eth1StoreForRequest(r).GetAddressErc721TableData(addressQueryContext(r), address, "", "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		erc721, err = db.BigtableClient.GetAddressErc721TableData(address, "", "")
-		if err != nil {
-			return err
-		}
-		return nil
+		erc1155, err = eth1StoreForRequest(r).GetAddressErc1155TableData(addressQueryContext(r), address, "", "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		erc1155, err = db.BigtableClient.GetAddressErc1155TableData(address, "", "")
-		if err != nil {
-			return err
-		}
-		return nil
+		blocksMined, err = eth1StoreForRequest(r).GetAddressBlocksMinedTableData(addressQueryContext(r), address, "", "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		blocksMined, err = db.BigtableClient.GetAddressBlocksMinedTableData(address, "", "")
-		if err != nil {
-			return err
-		}
-		return nil
+		unclesMined, err = eth1StoreForRequest(r).GetAddressUnclesMinedTableData(addressQueryContext(r), address, "", "")
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		unclesMined, err = db.BigtableClient.GetAddressUnclesMinedTableData(address, "", "")
-		if err != nil {
-			return err
-		}
-		return nil
+		contractInteractions, err = eth1StoreForRequest(r).GetAddressContractInteractionsTableData(r.Context(), addressBytes)
+		return ignorePartialResult(err, &partialResult)
 	})
 	g.Go(func() error {
 		var err error
-		addressWithdrawals, err := db.GetAddressWithdrawals(addressBytes, 25, 0)
+		nfts, err = eth1StoreForRequest(r).GetAddressNFTsTableData(r.Context(), addressBytes, "")
+		return ignorePartialResult(err, &partialResult)
+	})
+	g.Go(func() error {
+		var err error
+		addressWithdrawals, err := requestNetwork(r).GetAddressWithdrawals(addressBytes, 25, 0)
 		if err != nil {
 			return err
 		}
@@ -162,7 +282,7 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 	g.Go(func() error {
-		sumWithdrawals, err := db.GetAddressWithdrawalsTotal(addressBytes)
+		sumWithdrawals, err := requestNetwork(r).GetAddressWithdrawalsTotal(addressBytes)
 		if err != nil {
 			return err
 		}
@@ -188,6 +308,18 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 	ethPrice := new(big.Float).Mul(etherBalance, big.NewFloat(float64(price)))
 	tabs := []types.Eth1AddressPageTabs{}
 
+	// the activity is loaded when the tab is opened
+	for _, table := range []*types.DataTableResponse{txns, internal, erc20, erc721, erc1155} {
+		if table != nil && len(table.Data) != 0 {
+			tabs = append(tabs, types.Eth1AddressPageTabs{
+				Id:   "activity",
+				Href: "#activity",
+				Text: "All Activity",
+			})
+			break
+		}
+	}
+
 	// if txns != nil && len(txns.Data) != 0 {
 	// 	tabs = append(tabs, types.Eth1AddressPageTabs{
 	// 		Id:   "transactions",
@@ -243,6 +375,14 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 			Data: erc1155,
 		})
 	}
+	if nfts != nil && len(nfts.Data) != 0 {
+		tabs = append(tabs, types.Eth1AddressPageTabs{
+			Id:   "nfts",
+			Href: "#nfts",
+			Text: "NFTs",
+			Data: nfts,
+		})
+	}
 
 	if withdrawals != nil && len(withdrawals.Data) != 0 {
 		tabs = append(tabs, types.Eth1AddressPageTabs{
@@ -253,23 +393,60 @@ func Eth1Address(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	if contractInteractions != nil && len(contractInteractions.Data) != 0 {
+		tabs = append(tabs, types.Eth1AddressPageTabs{
+			Id:   "contractInteractions",
+			Href: "#contractInteractions",
+			Text: "Contracts",
+			Data: contractInteractions,
+		})
+	}
+
+	// a contract with code has been created again since its last indexed self destruct
+	if isContract {
+		selfDestruct = nil
+	}
+
+	// the code is loaded when the tab is opened
+	if isContract {
+		tabs = append(tabs, types.Eth1AddressPageTabs{
+			Id:   "code",
+			Href: "#code",
+			Text: "Code",
+		})
+	}
+
 	data.Data = types.Eth1AddressPageData{
-		Address:            address,
-		IsContract:         isContract,
-		QRCode:             pngStr,
-		QRCodeInverse:      pngStrInverse,
-		Metadata:           metadata,
-		WithdrawalsSummary: withdrawalSummary,
-		TransactionsTable:  txns,
-		InternalTxnsTable:  internal,
-		Erc20Table:         erc20,
-		Erc721Table:        erc721,
-		Erc1155Table:       erc1155,
-		WithdrawalsTable:   withdrawals,
-		BlocksMinedTable:   blocksMined,
-		UnclesMinedTable:   unclesMined,
-		EtherValue:         utils.FormatEtherValue(symbol, ethPrice, GetCurrentPriceFormatted(r)),
-		Tabs:               tabs,
+		Address:                   address,
+		IsContract:                isContract || contractCreation != nil || (!network.IsDefault() && summary.IsContract),
+		SelfDestruct:              selfDestruct,
+		ContractCreation:          contractCreation,
+		FundedBy:                  fundedBy,
+		Risk:                      risk,
+		QRCode:                    pngStr,
+		QRCodeInverse:             pngStrInverse,
+		Metadata:                  metadata,
+		PrivateLabel:              privateLabel,
+		WithdrawalsSummary:        withdrawalSummary,
+		Summary:                   summary,
+		TransactionsTable:         txns,
+		InternalTxnsTable:         internal,
+		Erc20Table:                erc20,
+		Erc721Table:               erc721,
+		Erc1155Table:              erc1155,
+		WithdrawalsTable:          withdrawals,
+		ContractInteractionsTable: contractInteractions,
+		NFTsTable:                 nfts,
+		BlocksMinedTable:          blocksMined,
+		UnclesMinedTable:          unclesMined,
+		EtherValue:                utils.FormatEtherValue(symbol, ethPrice, GetCurrentPriceFormatted(r)),
+		Tabs:                      tabs,
+		PartialResult:             atomic.LoadInt32(&partialResult) == 1,
+	}
+
+	// incomplete pages must not be cached until the next activity of the address
+	if atomic.LoadInt32(&partialResult) == 0 {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
 	}
 
 	if handleTemplateError(w, r, "eth1Account.go", "Eth1Address", "Done", eth1AddressTemplate.ExecuteTemplate(w, "layout", data)) != nil {
@@ -288,11 +465,59 @@ func Eth1AddressTransactions(w http.ResponseWriter, r *http.Request) {
 
 	pageToken := q.Get("pageToken")
 
-	search := ""
-	// logger.Infof("GETTING TRANSACTION table data for address: %v search: %v draw: %v start: %v length: %v", address, search, draw, start, length)
-	data, err := db.BigtableClient.GetAddressTransactionsTableData(addressBytes, search, pageToken)
+	filter, err := db.TxDirectionFilter(q.Get("direction"))
+	if err != nil {
+		http.Error(w, "Invalid direction, use sent or received", http.StatusBadRequest)
+		return
+	}
+
+	// requests of the datatables pager select the page by its offset (or by the token of the previous and next buttons),
+	// requests without a draw counter come from the infinite scroll and only page forward
+	if q.Get("draw") != "" {
+		draw, err := strconv.ParseUint(q.Get("draw"), 10, 64)
+		if err != nil {
+			logger.Errorf("error converting datatables data parameter from string to int for route %v: %v", r.URL.String(), err)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+		start, err := strconv.ParseUint(q.Get("start"), 10, 64)
+		if err != nil && pageToken == "" {
+			logger.Errorf("error converting datatables start parameter from string to int for route %v: %v", r.URL.String(), err)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+		length, err := strconv.ParseUint(q.Get("length"), 10, 64)
+		if err != nil || length == 0 {
+			length = 25
+		}
+		if length > 100 {
+			length = 100
+		}
+
+		data, err := eth1StoreForRequest(r).GetAddressTransactionsTablePage(r.Context(), addressBytes, filter, pageToken, int64(start), int64(length))
+		if err != nil && !db.IsPartialResult(err) {
+			logger.WithError(err).Errorf("error getting eth1 address transactions page")
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+		data.Draw = draw
+		if err == nil {
+			setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
+		}
+
+		err = json.NewEncoder(w).Encode(data)
+		if err != nil {
+			logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		}
+		return
+	}
+
+	data, err := eth1StoreForRequest(r).GetAddressTransactionsTableData(addressQueryContext(r), addressBytes, filter, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
+	} else {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
 	}
 
 	// logger.Infof("GOT TX: %+v", data)
@@ -305,6 +530,32 @@ func Eth1AddressTransactions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Eth1AddressPendingTransactions returns the pending transactions sent or received by an address. The response is not edge cached
+// as the transactions leave the mempool independently of the indexed data of the address.
+func Eth1AddressPendingTransactions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	address := strings.ToLower(strings.Replace(mux.Vars(r)["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	pending := services.PendingTransactionsOfAddress(common.HexToAddress(address))
+	data := &types.DataTableResponse{Data: make([][]interface{}, 0, len(pending))}
+	for _, tx := range pending {
+		data.Data = append(data.Data, toTableDataRow(tx))
+	}
+	data.RecordsTotal = uint64(len(data.Data))
+	data.RecordsFiltered = data.RecordsTotal
+
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+	}
+}
+
 func Eth1AddressBlocksMined(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -316,7 +567,7 @@ func Eth1AddressBlocksMined(w http.ResponseWriter, r *http.Request) {
 	pageToken := q.Get("pageToken")
 
 	search := ""
-	data, err := db.BigtableClient.GetAddressBlocksMinedTableData(address, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressBlocksMinedTableData(addressQueryContext(r), address, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
 	}
@@ -340,7 +591,7 @@ func Eth1AddressUnclesMined(w http.ResponseWriter, r *http.Request) {
 	pageToken := q.Get("pageToken")
 
 	search := ""
-	data, err := db.BigtableClient.GetAddressUnclesMinedTableData(address, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressUnclesMinedTableData(addressQueryContext(r), address, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
 	}
@@ -368,7 +619,7 @@ func Eth1AddressWithdrawals(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	withdrawals, err := db.GetAddressWithdrawals(common.HexToAddress(address).Bytes(), 25, uint64(pageToken))
+	withdrawals, err := requestNetwork(r).GetAddressWithdrawals(common.HexToAddress(address).Bytes(), 25, uint64(pageToken))
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
 	}
@@ -423,9 +674,11 @@ func Eth1AddressInternalTransactions(w http.ResponseWriter, r *http.Request) {
 
 	search := ""
 
-	data, err := db.BigtableClient.GetAddressInternalTableData(addressBytes, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressInternalTableData(addressQueryContext(r), addressBytes, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
+	} else {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
 	}
 
 	// logger.Infof("GOT TX: %+v", data)
@@ -438,6 +691,35 @@ func Eth1AddressInternalTransactions(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Eth1AddressActivity returns a page of the transactions, internal transactions and token transfers of an address merged by time,
+// received airdrops are left out with hideAirdrops=true
+func Eth1AddressActivity(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	vars := mux.Vars(r)
+	address := strings.Replace(vars["address"], "0x", "", -1)
+	address = strings.ToLower(address)
+	addressBytes := common.FromHex(address)
+
+	data, err := eth1StoreForRequest(r).GetAddressActivityTableData(addressQueryContext(r), addressBytes, q.Get("pageToken"), q.Get("hideAirdrops") == "true")
+	if err != nil && !db.IsPartialResult(err) {
+		logger.WithError(err).Errorf("error getting activity of address %v", address)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	if err == nil {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
 func Eth1AddressErc20Transactions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -451,9 +733,11 @@ func Eth1AddressErc20Transactions(w http.ResponseWriter, r *http.Request) {
 
 	search := ""
 	// logger.Infof("GETTING TRANSACTION table data for address: %v search: %v draw: %v start: %v length: %v", address, search, draw, start, length)
-	data, err := db.BigtableClient.GetAddressErc20TableData(addressBytes, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressErc20TableData(addressQueryContext(r), addressBytes, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 internal transactions table data")
+	} else {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(addressBytes))
 	}
 
 	// logger.Infof("GOT TX: %+v", data)
@@ -477,9 +761,11 @@ func Eth1AddressErc721Transactions(w http.ResponseWriter, r *http.Request) {
 	pageToken := q.Get("pageToken")
 	search := ""
 	// logger.Infof("GETTING TRANSACTION table data for address: %v search: %v draw: %v start: %v length: %v", address, search, draw, start, length)
-	data, err := db.BigtableClient.GetAddressErc721TableData(address, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressErc721TableData(addressQueryContext(r), address, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
+	} else {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(common.FromHex(address)))
 	}
 
 	// logger.Infof("GOT TX: %+v", data)
@@ -503,9 +789,11 @@ func Eth1AddressErc1155Transactions(w http.ResponseWriter, r *http.Request) {
 
 	search := ""
 	// logger.Infof("GETTING TRANSACTION table data for address: %v search: %v draw: %v start: %v length: %v", address, search, draw, start, length)
-	data, err := db.BigtableClient.GetAddressErc1155TableData(address, search, pageToken)
+	data, err := eth1StoreForRequest(r).GetAddressErc1155TableData(addressQueryContext(r), address, search, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 internal transactions table data")
+	} else {
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheAddressKey(common.FromHex(address)))
 	}
 
 	// logger.Infof("GOT TX: %+v", data)
@@ -517,3 +805,347 @@ func Eth1AddressErc1155Transactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// Eth1AddressNFTs returns the next page of the nfts tab of an address
+func Eth1AddressNFTs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	data, err := eth1StoreForRequest(r).GetAddressNFTsTableData(r.Context(), common.FromHex(address), r.URL.Query().Get("pageToken"))
+	if err != nil {
+		logger.WithError(err).Errorf("error getting nfts of address 0x%v", address)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+func Eth1AddressGraph(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "execution/addressGraph.html")
+	var eth1AddressGraphTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	vars := mux.Vars(r)
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		templateFiles = append(layoutTemplateFiles, "sprites.html", "execution/addressNotFound.html")
+		data := InitPageData(w, r, "blockchain", "/address", "not found", templateFiles)
+
+		if handleTemplateError(w, r, "eth1Account.go", "Eth1AddressGraph", "not valid", templates.GetTemplate(templateFiles...).ExecuteTemplate(w, "layout", data)) != nil {
+			return // an error has occurred and was processed
+		}
+		return
+	}
+
+	days, err := strconv.ParseUint(r.URL.Query().Get("days"), 10, 64)
+	if err != nil || days == 0 || days > 365 {
+		days = 30
+	}
+
+	addressBytes := common.FromHex(address)
+	data := InitPageData(w, r, "blockchain", "/address", fmt.Sprintf("Fund Flows of 0x%x", addressBytes), templateFiles)
+
+	names := map[string]string{string(addressBytes): ""}
+	err = eth1StoreForRequest(r).GetAddressNames(r.Context(), names)
+	if err != nil {
+		logger.Errorf("error retrieving name of address %x route: %v err: %v", addressBytes, r.URL.String(), err)
+	}
+
+	data.Data = types.Eth1AddressGraphPageData{
+		Address: utils.FixAddressCasing(address),
+		Name:    names[string(addressBytes)],
+		Days:    days,
+	}
+
+	if handleTemplateError(w, r, "eth1Account.go", "Eth1AddressGraph", "Done", eth1AddressGraphTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// Eth1AddressTransactionsExport streams the transactions and ERC20, ERC721 and ERC1155 transfers of an address as csv, newest first.
+// The range is given by the optional from and to dates (YYYY-MM-DD, both inclusive) and defaults to the whole history.
+func Eth1AddressTransactionsExport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	vars := mux.Vars(r)
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	from := time.Unix(0, 0).UTC()
+	to := time.Now().UTC()
+	if q.Get("from") != "" {
+		day, err := time.Parse("2006-01-02", q.Get("from"))
+		if err != nil {
+			http.Error(w, "Invalid from date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from = day
+	}
+	if q.Get("to") != "" {
+		day, err := time.Parse("2006-01-02", q.Get("to"))
+		if err != nil {
+			http.Error(w, "Invalid to date, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = day.Add(time.Hour*24 - time.Second)
+	}
+	if to.Before(from) {
+		http.Error(w, "Invalid date range", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=transactions_0x%v_%v_%v.csv", address, from.Format("20060102"), to.Format("20060102")))
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"type", "hash", "block", "time", "from", "to", "token", "token_symbol", "token_id", "value"})
+	if err == nil {
+		err = eth1StoreForRequest(r).StreamAddressTransfers(r.Context(), common.FromHex(address), from, to, func(transfers []*types.AddressTransferExport) error {
+			for _, t := range transfers {
+				record := []string{t.Type, fmt.Sprintf("%#x", t.Hash), fmt.Sprintf("%v", t.BlockNumber), t.Time.UTC().Format(time.RFC3339), fmt.Sprintf("%#x", t.From), fmt.Sprintf("%#x", t.To), "", t.TokenSymbol, "", t.Value}
+				if len(t.Token) > 0 {
+					record[6] = fmt.Sprintf("%#x", t.Token)
+				}
+				if t.Type == "erc721" || t.Type == "erc1155" {
+					record[8] = new(big.Int).SetBytes(t.TokenId).String()
+				}
+				// the token symbol is chosen by the token contract
+				for i := range record {
+					record[i] = utils.EscapeCSVCell(record[i])
+				}
+				err := writer.Write(record)
+				if err != nil {
+					return err
+				}
+			}
+			// each chunk is sent right away instead of being buffered until the export is complete
+			writer.Flush()
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			return writer.Error()
+		})
+	}
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	if err != nil {
+		// the status has already been sent, the truncated file is all the client gets
+		logger.WithError(err).WithField("route", r.URL.String()).Error("error exporting the transactions of an address")
+	}
+}
+
+// Eth1AddressBalanceHistory returns the ether balance snapshots of an address as [block, ether] pairs for the balance chart of the address page
+func Eth1AddressBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	vars := mux.Vars(r)
+	address := strings.ToLower(strings.Replace(vars["address"], "0x", "", -1))
+	if !utils.IsEth1Address(address) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+
+	from, err := strconv.ParseUint(q.Get("from"), 10, 64)
+	if err != nil {
+		from = 0
+	}
+	to, err := strconv.ParseUint(q.Get("to"), 10, 64)
+	if err != nil || to == 0 {
+		to = math.MaxUint64
+	}
+
+	snapshots, err := eth1StoreForRequest(r).GetAddressBalanceHistory(r.Context(), common.FromHex(address), from, to)
+	if err != nil {
+		logger.WithError(err).Errorf("error getting balance history of address 0x%v", address)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	series := make([][]float64, 0, len(snapshots))
+	for _, s := range snapshots {
+		ether, _ := utils.WeiToEther(new(big.Int).SetBytes(s.Balance)).Float64()
+		series = append(series, []float64{float64(s.Block), ether})
+	}
+
+	err = json.NewEncoder(w).Encode(series)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// Eth1AddressCode returns the disassembled runtime code of a contract
+func Eth1AddressCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	if !utils.IsEth1Address(vars["address"]) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+	// the code is read from the node of the configured chain
+	if rpc.CurrentErigonClient == nil || !requestNetwork(r).IsDefault() {
+		http.Error(w, "Contract code is not available for this network", http.StatusNotFound)
+		return
+	}
+	address := common.HexToAddress(vars["address"])
+
+	code, err := eth1data.GetContractCode(r.Context(), address)
+	if err != nil {
+		logger.WithError(err).Errorf("error getting code of address %v", address)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	instructions, metadata := utils.DisassembleBytecode(code)
+	response := types.Eth1AddressCode{
+		Size:         len(code),
+		CodeHash:     crypto.Keccak256Hash(code).Hex(),
+		Instructions: instructions,
+	}
+	if len(metadata) > 0 {
+		response.Metadata = fmt.Sprintf("0x%x", metadata)
+	}
+	if len(code) > 0 {
+		contractMetadata, err := db.GetEth1Store().GetContractMetadata(r.Context(), address.Bytes())
+		if err != nil {
+			logger.Warnf("error getting contract metadata of address %v: %v", address, err)
+		} else if contractMetadata != nil {
+			response.ContractName = contractMetadata.Name
+			response.Verified = verifiedContractSource(contractMetadata)
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// verifiedContractSource prepares the verified source of a contract for the code tab, nil is returned for unverified contracts
+func verifiedContractSource(meta *types.ContractMetadata) *types.Eth1AddressVerified {
+	if meta.Verification == nil || meta.ABI == nil {
+		return nil
+	}
+	verified := &types.Eth1AddressVerified{
+		Source:          meta.Verification.Source,
+		Match:           meta.Verification.Match,
+		CompilerVersion: meta.Verification.CompilerVersion,
+		Files:           meta.Verification.Files,
+		Abi:             meta.ABIJson,
+		Events:          make([]types.Eth1AbiEvent, 0, len(meta.ABI.Events)),
+	}
+
+	for _, event := range meta.ABI.Events {
+		verified.Events = append(verified.Events, types.Eth1AbiEvent{
+			Signature: event.Sig,
+			Topic:     event.ID.Hex(),
+		})
+	}
+	sort.Slice(verified.Events, func(i, j int) bool {
+		return verified.Events[i].Signature < verified.Events[j].Signature
+	})
+
+	if len(meta.Verification.ConstructorArgs) > 0 {
+		values, err := meta.ABI.Constructor.Inputs.Unpack(meta.Verification.ConstructorArgs)
+		if err != nil {
+			logger.Warnf("error decoding constructor arguments: %v", err)
+			return verified
+		}
+		for i, input := range meta.ABI.Constructor.Inputs {
+			arg := types.Eth1DecodedArgument{
+				Name:  input.Name,
+				Type:  input.Type.String(),
+				Value: fmt.Sprintf("%v", values[i]),
+			}
+			if address, ok := values[i].(common.Address); ok {
+				arg.Value = address.Hex()
+			} else if strings.HasPrefix(arg.Type, "byte") {
+				arg.Value = fmt.Sprintf("0x%x", values[i])
+			}
+			verified.ConstructorArgs = append(verified.ConstructorArgs, arg)
+		}
+	}
+	return verified
+}
+
+// Eth1AddressStorage reads a storage slot of a contract. The slot is passed as a decimal number or 32 byte hex string,
+// if a key is passed the slot of that key in the mapping stored at the slot is read instead.
+func Eth1AddressStorage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	q := r.URL.Query()
+	vars := mux.Vars(r)
+	if !utils.IsEth1Address(vars["address"]) {
+		http.Error(w, "Invalid address", http.StatusBadRequest)
+		return
+	}
+	if rpc.CurrentErigonClient == nil || !requestNetwork(r).IsDefault() {
+		http.Error(w, "Contract storage is not available for this network", http.StatusNotFound)
+		return
+	}
+	address := common.HexToAddress(vars["address"])
+
+	slot, err := parseStorageWord(q.Get("slot"))
+	if err != nil {
+		http.Error(w, "Invalid slot, use a decimal number or a 0x prefixed hex string of at most 32 bytes", http.StatusBadRequest)
+		return
+	}
+	if q.Get("key") != "" {
+		key, err := parseStorageWord(q.Get("key"))
+		if err != nil {
+			http.Error(w, "Invalid mapping key, use a decimal number, an address or a 0x prefixed hex string of at most 32 bytes", http.StatusBadRequest)
+			return
+		}
+		slot = utils.StorageMappingSlot(key.Bytes(), slot)
+	}
+
+	value, err := eth1data.GetStorageAt(r.Context(), address, slot)
+	if err != nil {
+		logger.WithError(err).Errorf("error getting storage of address %v", address)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(utils.DecodeStorageWord(slot, value))
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// parseStorageWord parses a 32 byte word given as a decimal number or as a 0x prefixed hex string
+func parseStorageWord(s string) (common.Hash, error) {
+	if strings.HasPrefix(s, "0x") {
+		b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+		if err != nil || len(b) > 32 {
+			return common.Hash{}, fmt.Errorf("invalid hex word %v", s)
+		}
+		return common.BytesToHash(b), nil
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("invalid word %v", s)
+	}
+	return common.BigToHash(n), nil
+}
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/rpc"
 	"eth2-exporter/services"
@@ -30,11 +32,12 @@ func Eth1Block(w http.ResponseWriter, r *http.Request) {
 		"slot/exits.html",
 		"slot/overview.html",
 		"slot/execTransactions.html",
+		"slot/execInternalTransactions.html",
 		"slot/withdrawals.html")
 	var blockTemplate = templates.GetTemplate(
 		blockTemplateFiles...,
 	)
-	preMergeTemplateFiles := append(layoutTemplateFiles, "execution/block.html", "slot/execTransactions.html")
+	preMergeTemplateFiles := append(layoutTemplateFiles, "execution/block.html", "slot/execTransactions.html", "slot/execInternalTransactions.html")
 	notFountTemplateFiles := append(layoutTemplateFiles, "slotnotfound.html")
 	var blockNotFoundTemplate = templates.GetTemplate(notFountTemplateFiles...)
 	var preMergeBlockTemplate = templates.GetTemplate(preMergeTemplateFiles...)
@@ -62,7 +65,7 @@ func Eth1Block(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	eth1BlockPageData, err := GetExecutionBlockPageData(number, 10)
+	eth1BlockPageData, err := GetExecutionBlockPageData(r.Context(), number, 10)
 	if err != nil {
 		data := InitPageData(w, r, "blockchain", "/block", fmt.Sprintf("Block %d", 0), notFountTemplateFiles)
 		data.Data = "block"
@@ -98,6 +101,11 @@ func Eth1Block(w http.ResponseWriter, r *http.Request) {
 
 		data.Data = blockPageData
 
+		// the page shows the finalization of the slot, which no block is indexed for
+		if blockPageData.EpochFinalized {
+			setEdgeCacheHeaders(w, r, utils.EdgeCacheBlockKey(number))
+		}
+
 		if handleTemplateError(w, r, "eth1Block.go", "Eth1Block", "Done (Post Merge)", blockTemplate.ExecuteTemplate(w, "layout", data)) != nil {
 			return // an error has occurred and was processed
 		}
@@ -106,14 +114,16 @@ func Eth1Block(w http.ResponseWriter, r *http.Request) {
 		data := InitPageData(w, r, "block", "/block", fmt.Sprintf("Block %d", eth1BlockPageData.Number), preMergeTemplateFiles)
 		data.Data = eth1BlockPageData
 
+		setEdgeCacheHeaders(w, r, utils.EdgeCacheBlockKey(number))
+
 		if handleTemplateError(w, r, "eth1Block.go", "Eth1Block", "Done (Pre Merge)", preMergeBlockTemplate.ExecuteTemplate(w, "layout", data)) != nil {
 			return // an error has occurred and was processed
 		}
 	}
 }
 
-func GetExecutionBlockPageData(number uint64, limit int) (*types.Eth1BlockPageData, error) {
-	block, err := db.BigtableClient.GetBlockFromBlocksTable(number)
+func GetExecutionBlockPageData(ctx context.Context, number uint64, limit int) (*types.Eth1BlockPageData, error) {
+	block, err := db.GetEth1Store().GetBlockFromBlocksTable(ctx, number)
 	if diffToHead := int64(services.LatestEth1BlockNumber()) - int64(number); err != nil && diffToHead < 0 && diffToHead >= -5 {
 		block, _, err = rpc.CurrentErigonClient.GetBlock(int64(number))
 	}
@@ -131,7 +141,7 @@ func GetExecutionBlockPageData(number uint64, limit int) (*types.Eth1BlockPageDa
 	for _, uncle := range block.Uncles {
 		names[string(uncle.Coinbase)] = ""
 	}
-	names, _, err = db.BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+	names, _, err = db.GetEth1Store().GetAddressesNamesArMetadata(ctx, &names, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -166,7 +176,7 @@ func GetExecutionBlockPageData(number uint64, limit int) (*types.Eth1BlockPageDa
 			if len(d) > 3 {
 				m := d[:4]
 				invokesContract := len(tx.GetItx()) > 0 || tx.GetGasUsed() > 21000 || tx.GetErrorMsg() != ""
-				method = db.BigtableClient.GetMethodLabel(m, invokesContract)
+				method = db.GetEth1Store().GetMethodLabel(ctx, m, invokesContract)
 			}
 		}
 
@@ -240,6 +250,7 @@ func GetExecutionBlockPageData(number uint64, limit int) (*types.Eth1BlockPageDa
 		Extra:          fmt.Sprintf("%#x", block.Extra),
 		Txs:            txs,
 		Uncles:         uncles,
+		Finality:       utils.FormatEth1BlockFinality(services.Eth1BlockFinality(number)),
 	}
 
 	var relaysData struct {
@@ -254,3 +265,29 @@ func GetExecutionBlockPageData(number uint64, limit int) (*types.Eth1BlockPageDa
 	}
 	return &eth1BlockPageData, nil
 }
+
+func BlockInternalTransactionsData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	number, err := strconv.ParseUint(vars["block"], 10, 64)
+	if err != nil {
+		logger.Errorf("error parsing block url parameter %v, err: %v", vars["block"], err)
+		http.Error(w, "Invalid block number", http.StatusBadRequest)
+		return
+	}
+
+	data, err := db.GetEth1Store().GetBlockInternalTableData(r.Context(), number, r.URL.Query().Get("pageToken"))
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error retrieving internal transactions of block %v, err: %v", number, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error encoding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
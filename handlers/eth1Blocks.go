@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/services"
@@ -60,7 +61,7 @@ func Eth1BlocksData(w http.ResponseWriter, r *http.Request) {
 		length = 100
 	}
 
-	data, err := getEth1BlocksTableData(draw, start, length, recordsTotal)
+	data, err := getEth1BlocksTableData(r.Context(), draw, start, length, recordsTotal)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
 	}
@@ -121,7 +122,7 @@ func Eth1BlocksHighest(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(fmt.Sprintf("%d", services.LatestEth1BlockNumber())))
 }
 
-func getEth1BlocksTableData(draw, start, length, recordsTotal uint64) (*types.DataTableResponse, error) {
+func getEth1BlocksTableData(ctx context.Context, draw, start, length, recordsTotal uint64) (*types.DataTableResponse, error) {
 	if recordsTotal == 0 {
 		recordsTotal = services.LatestEth1BlockNumber()
 	}
@@ -136,7 +137,7 @@ func getEth1BlocksTableData(draw, start, length, recordsTotal uint64) (*types.Da
 		length = start
 	}
 
-	blocks, err := db.BigtableClient.GetBlocksDescending(start, length)
+	blocks, err := db.GetEth1Store().GetBlocksDescending(ctx, start, length)
 	if err != nil {
 		return nil, err
 	}
@@ -167,6 +168,8 @@ func getEth1BlocksTableData(draw, start, length, recordsTotal uint64) (*types.Da
 		}
 	}
 
+	checkpoints := services.LatestEth1FinalityCheckpoints()
+
 	tableData := make([][]interface{}, len(blocks))
 	for i, b := range blocks {
 		var sData *additionalSlotData
@@ -206,6 +209,13 @@ func getEth1BlocksTableData(draw, start, length, recordsTotal uint64) (*types.Da
 		}
 
 		blockNumber := b.GetNumber()
+		finality := template.HTML("")
+		switch checkpoints.Finality(blockNumber) {
+		case types.Eth1BlockFinalized:
+			finality = `<BR /><i class="fas fa-lock text-success" style="font-size: .63rem;" data-toggle="tooltip" title="Finalized"></i>`
+		case types.Eth1BlockSafe:
+			finality = `<BR /><i class="fas fa-shield-alt text-info" style="font-size: .63rem;" data-toggle="tooltip" title="Safe"></i>`
+		}
 		baseFee := new(big.Int).SetBytes(b.GetBaseFee())
 		gasHalf := float64(b.GetGasLimit()) / 2.0
 		txReward := new(big.Int).SetBytes(b.GetTxReward())
@@ -221,7 +231,7 @@ func getEth1BlocksTableData(draw, start, length, recordsTotal uint64) (*types.Da
 		tableData[i] = []interface{}{
 			epochText, // Epoch
 			fmt.Sprintf(`%s<BR /><span style="font-size: .63rem; color: grey;">%v</span>`, slotText, utils.FormatTimestamp(b.GetTime().AsTime().Unix())), // Slot
-			fmt.Sprintf(`<A href="block/%d">%v</A>`, blockNumber, utils.FormatAddCommas(blockNumber)),                                                    // Block
+			fmt.Sprintf(`<A href="block/%d">%v</A>%s`, blockNumber, utils.FormatAddCommas(blockNumber), finality),                                        // Block
 			status,                             // Status
 			fmt.Sprintf("%x", b.GetCoinbase()), // Recipient
 			proposer,                           // Proposer
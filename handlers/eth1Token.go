@@ -33,29 +33,38 @@ func Eth1Token(w http.ResponseWriter, r *http.Request) {
 	// symbol := GetCurrencySymbol(r)
 
 	g := new(errgroup.Group)
-	g.SetLimit(3)
+	g.SetLimit(4)
 
 	var txns *types.DataTableResponse
 	var metadata *types.ERC20Metadata
 	var balance *types.Eth1AddressBalance
-	// var holders *types.DataTableResponse
+	var holders *types.DataTableResponse
 
 	g.Go(func() error {
 		var err error
-		txns, err = db.BigtableClient.GetTokenTransactionsTableData(token, address, "")
+		txns, err = db.GetEth1Store().GetTokenTransactionsTableData(r.Context(), token, address, "")
+		if db.IsPartialResult(err) {
+			return nil
+		}
 		return err
 	})
 
 	g.Go(func() error {
 		var err error
-		metadata, err = db.BigtableClient.GetERC20MetadataForAddress(token)
+		metadata, err = db.GetEth1Store().GetERC20MetadataForAddress(r.Context(), token)
+		return err
+	})
+
+	g.Go(func() error {
+		var err error
+		holders, err = db.GetEth1Store().GetTokenHoldersTableData(r.Context(), token, "")
 		return err
 	})
 
 	if address != nil {
 		g.Go(func() error {
 			var err error
-			balance, err = db.BigtableClient.GetBalanceForAddress(address, token)
+			balance, err = db.GetEth1Store().GetBalanceForAddress(r.Context(), address, token)
 			return err
 		})
 	}
@@ -72,8 +81,11 @@ func Eth1Token(w http.ResponseWriter, r *http.Request) {
 		logger.WithError(err).Errorf("error generating qr code for address %v", token)
 	}
 
-	if len(metadata.Price) == 0 {
-		metadata.Price = []byte("32.523423")
+	prices, err := db.BigtableClient.GetERC20TokenPrices(r.Context(), [][]byte{token})
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving price of token %x", token)
+	} else if tokenPrice, ok := prices[string(token)]; ok {
+		metadata.Price = []byte(tokenPrice.String())
 	}
 
 	marketCap := float64(0)
@@ -107,13 +119,14 @@ func Eth1Token(w http.ResponseWriter, r *http.Request) {
 		Token:            fmt.Sprintf("%x", token),
 		Address:          fmt.Sprintf("%x", address),
 		TransfersTable:   txns,
+		HoldersTable:     holders,
 		Metadata:         metadata,
 		Balance:          balance,
 		QRCode:           pngStr,
 		QRCodeInverse:    pngStrInverse,
 		MarketCap:        template.HTML("$" + utils.FormatThousandsEnglish(fmt.Sprintf("%.2f", marketCap))),
 		SocialProfiles:   template.HTML(``),
-		Holders:          template.HTML(`<span>500</span>`),
+		Holders:          template.HTML(fmt.Sprintf("<span>%v</span>", utils.FormatThousandsEnglish(fmt.Sprintf("%d", holders.RecordsTotal)))),
 		Transfers:        template.HTML(`<span>10,000</span>`),
 		DilutedMarketCap: template.HTML("$" + utils.FormatThousandsEnglish(fmt.Sprintf("%.2f", marketCap))),
 		Price:            template.HTML(fmt.Sprintf("<span>$%s</span><span>@ %.6f</span>", string(metadata.Price), ethExchangeRate)),
@@ -135,7 +148,7 @@ func Eth1TokenTransfers(w http.ResponseWriter, r *http.Request) {
 	pageToken := q.Get("pageToken")
 
 	// logger.Infof("GETTING TRANSACTION table data for address: %v search: %v draw: %v start: %v length: %v", address, search, draw, start, length)
-	data, err := db.BigtableClient.GetTokenTransactionsTableData(token, address, pageToken)
+	data, err := db.GetEth1Store().GetTokenTransactionsTableData(r.Context(), token, address, pageToken)
 	if err != nil {
 		logger.WithError(err).Errorf("error getting eth1 block table data")
 	}
@@ -149,3 +162,24 @@ func Eth1TokenTransfers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func Eth1TokenHolders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	vars := mux.Vars(r)
+	token := common.FromHex(strings.TrimPrefix(vars["token"], "0x"))
+
+	data, err := db.GetEth1Store().GetTokenHoldersTableData(r.Context(), token, r.URL.Query().Get("pageToken"))
+	if err != nil {
+		logger.WithError(err).Errorf("error getting holders of token 0x%x", token)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/services"
@@ -28,7 +29,7 @@ func Eth1Transactions(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
 
 	data := InitPageData(w, r, "blockchain", "/eth1transactions", "Transactions", templateFiles)
-	data.Data = getTransactionDataStartingWithPageToken("")
+	data.Data = getTransactionDataStartingWithPageToken(r.Context(), "")
 
 	if handleTemplateError(w, r, "eth1Transactions.go", "Eth1Transactions", "", eth1TransactionsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
 		return // an error has occurred and was processed
@@ -38,14 +39,14 @@ func Eth1Transactions(w http.ResponseWriter, r *http.Request) {
 func Eth1TransactionsData(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	err := json.NewEncoder(w).Encode(getTransactionDataStartingWithPageToken(r.URL.Query().Get("pageToken")))
+	err := json.NewEncoder(w).Encode(getTransactionDataStartingWithPageToken(r.Context(), r.URL.Query().Get("pageToken")))
 	if err != nil {
 		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
 	}
 }
 
-func getTransactionDataStartingWithPageToken(pageToken string) *types.DataTableResponse {
+func getTransactionDataStartingWithPageToken(ctx context.Context, pageToken string) *types.DataTableResponse {
 	pageTokenId := uint64(0)
 	{
 		if len(pageToken) > 0 {
@@ -61,7 +62,7 @@ func getTransactionDataStartingWithPageToken(pageToken string) *types.DataTableR
 
 	tableData := make([][]interface{}, 0, minimumTransactionsPerUpdate)
 	for len(tableData) < minimumTransactionsPerUpdate && pageTokenId != 0 {
-		b, n, err := getEth1BlockAndNext(pageTokenId)
+		b, n, err := getEth1BlockAndNext(ctx, pageTokenId)
 		if err != nil {
 			logger.Errorf("error getting transaction from block %v", err)
 			return nil
@@ -75,7 +76,7 @@ func getTransactionDataStartingWithPageToken(pageToken string) *types.DataTableR
 				names[string(v.GetFrom())] = ""
 				names[string(v.GetTo())] = ""
 			}
-			names, _, err = db.BigtableClient.GetAddressesNamesArMetadata(&names, nil)
+			names, _, err = db.GetEth1Store().GetAddressesNamesArMetadata(ctx, &names, nil)
 			if err != nil {
 				logger.Errorf("error getting name for addresses: %v", err)
 				return nil
@@ -91,7 +92,7 @@ func getTransactionDataStartingWithPageToken(pageToken string) *types.DataTableR
 					if len(d) > 3 {
 						m := d[:4]
 						invokesContract := len(v.GetItx()) > 0 || v.GetGasUsed() > 21000 || v.GetErrorMsg() != ""
-						method = db.BigtableClient.GetMethodLabel(m, invokesContract)
+						method = db.GetEth1Store().GetMethodLabel(ctx, m, invokesContract)
 					}
 				}
 
@@ -137,8 +138,8 @@ func getTransactionDataStartingWithPageToken(pageToken string) *types.DataTableR
 
 // Return given block, next block number and error
 // If block doesn't exists nil, 0, nil is returned
-func getEth1BlockAndNext(number uint64) (*types.Eth1Block, uint64, error) {
-	block, err := db.BigtableClient.GetBlockFromBlocksTable(number)
+func getEth1BlockAndNext(ctx context.Context, number uint64) (*types.Eth1Block, uint64, error) {
+	block, err := db.GetEth1Store().GetBlockFromBlocksTable(ctx, number)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -148,7 +149,7 @@ func getEth1BlockAndNext(number uint64) (*types.Eth1Block, uint64, error) {
 
 	nextBlock := uint64(0)
 	{
-		blocks, err := db.BigtableClient.GetBlocksDescending(number, 2)
+		blocks, err := db.GetEth1Store().GetBlocksDescending(ctx, number, 2)
 		if err != nil {
 			return nil, 0, err
 		}
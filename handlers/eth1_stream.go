@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/metrics"
+	"eth2-exporter/services"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	eth1StreamWriteTimeout = time.Second * 10
+	eth1StreamPingInterval = time.Second * 30
+	// a connection is closed if the client does not answer a ping within this time
+	eth1StreamPongTimeout = eth1StreamPingInterval * 2
+	eth1StreamMaxRequest  = 4096
+)
+
+var eth1StreamMethodRE = regexp.MustCompile(`^0x[0-9a-f]{8}$`)
+
+var eth1StreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// the stream is public like the api
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+var eth1StreamConnections int64
+
+func eth1StreamMaxConnections() int64 {
+	if utils.Config.Frontend.Eth1Stream.MaxConnections > 0 {
+		return int64(utils.Config.Frontend.Eth1Stream.MaxConnections)
+	}
+	return 1000
+}
+
+func eth1StreamMaxSubscriptions() int {
+	if utils.Config.Frontend.Eth1Stream.MaxSubscriptions > 0 {
+		return utils.Config.Frontend.Eth1Stream.MaxSubscriptions
+	}
+	return 10
+}
+
+// Eth1Stream upgrades the request to a websocket connection that pushes the blocks and the address activity indexed from then
+// on to the client. Clients subscribe by sending a types.Eth1StreamRequest, e.g.
+// {"op":"subscribe","id":"usdc","topic":"activity","filter":{"token":"0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"}}
+func Eth1Stream(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt64(&eth1StreamConnections, 1) > eth1StreamMaxConnections() {
+		atomic.AddInt64(&eth1StreamConnections, -1)
+		http.Error(w, "Too many connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer atomic.AddInt64(&eth1StreamConnections, -1)
+
+	conn, err := eth1StreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has already replied with an error
+		logger.Warnf("error upgrading eth1 stream connection: %v", err)
+		return
+	}
+	defer conn.Close()
+	metrics.Eth1StreamConnections.Inc()
+	defer metrics.Eth1StreamConnections.Dec()
+
+	blocks := services.SubscribeEth1Stream()
+	defer services.UnsubscribeEth1Stream(blocks)
+
+	// the requests are read in their own goroutine, all writes are made by this one
+	requests := make(chan *types.Eth1StreamRequest)
+	closed := make(chan struct{})
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		defer close(closed)
+		conn.SetReadLimit(eth1StreamMaxRequest)
+		conn.SetReadDeadline(time.Now().Add(eth1StreamPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(eth1StreamPongTimeout))
+		})
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			req := &types.Eth1StreamRequest{}
+			err = json.Unmarshal(message, req)
+			if err != nil {
+				req = &types.Eth1StreamRequest{Op: "invalid"}
+			}
+			select {
+			case requests <- req:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	write := func(message *types.Eth1StreamMessage) error {
+		conn.SetWriteDeadline(time.Now().Add(eth1StreamWriteTimeout))
+		return conn.WriteJSON(message)
+	}
+
+	subscriptions := make(map[string]*types.Eth1StreamRequest)
+	ping := time.NewTicker(eth1StreamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case req := <-requests:
+			err = write(handleEth1StreamRequest(subscriptions, req))
+		case block, ok := <-blocks:
+			if !ok {
+				write(&types.Eth1StreamMessage{Op: "error", Error: "connection too slow to keep up with the stream"})
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too slow"), time.Now().Add(eth1StreamWriteTimeout))
+				return
+			}
+			err = writeEth1StreamBlock(write, subscriptions, block)
+		case <-ping.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eth1StreamWriteTimeout))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleEth1StreamRequest adds or removes a subscription of a connection and returns the reply to the request
+func handleEth1StreamRequest(subscriptions map[string]*types.Eth1StreamRequest, req *types.Eth1StreamRequest) *types.Eth1StreamMessage {
+	reject := func(format string, args ...interface{}) *types.Eth1StreamMessage {
+		return &types.Eth1StreamMessage{Op: "error", Id: req.Id, Error: fmt.Sprintf(format, args...)}
+	}
+	if req.Op != "subscribe" && req.Op != "unsubscribe" {
+		return reject("invalid request, op must be subscribe or unsubscribe")
+	}
+	if req.Id == "" || len(req.Id) > 64 {
+		return reject("invalid request, a subscription id of at most 64 characters is required")
+	}
+	if req.Op == "unsubscribe" {
+		if subscriptions[req.Id] == nil {
+			return reject("unknown subscription")
+		}
+		delete(subscriptions, req.Id)
+		return &types.Eth1StreamMessage{Op: "unsubscribed", Id: req.Id}
+	}
+
+	if subscriptions[req.Id] != nil {
+		return reject("subscription id already in use")
+	}
+	if len(subscriptions) >= eth1StreamMaxSubscriptions() {
+		return reject("at most %v subscriptions per connection are allowed", eth1StreamMaxSubscriptions())
+	}
+	filter := &req.Filter
+	filter.Address = strings.ToLower(filter.Address)
+	filter.Token = strings.ToLower(filter.Token)
+	filter.Method = strings.ToLower(filter.Method)
+	switch req.Topic {
+	case "blocks":
+		if *filter != (types.Eth1StreamFilter{}) {
+			return reject("the blocks topic can not be filtered")
+		}
+	case "activity":
+		if filter.Address == "" && filter.Token == "" {
+			return reject("the activity topic requires an address or token filter")
+		}
+		if filter.Address != "" && !utils.IsEth1Address(filter.Address) {
+			return reject("invalid address %v", filter.Address)
+		}
+		if filter.Token != "" && !utils.IsEth1Address(filter.Token) {
+			return reject("invalid token %v", filter.Token)
+		}
+		if filter.Method != "" && !eth1StreamMethodRE.MatchString(filter.Method) {
+			return reject("invalid method %v, expected the 4 byte selector like 0xa9059cbb", filter.Method)
+		}
+		// the addresses of the activity are 0x prefixed
+		if filter.Address != "" {
+			filter.Address = "0x" + strings.TrimPrefix(filter.Address, "0x")
+		}
+		if filter.Token != "" {
+			filter.Token = "0x" + strings.TrimPrefix(filter.Token, "0x")
+		}
+	default:
+		return reject("invalid topic, topic must be blocks or activity")
+	}
+	subscriptions[req.Id] = req
+	return &types.Eth1StreamMessage{Op: "subscribed", Id: req.Id}
+}
+
+// writeEth1StreamBlock writes the messages of a block to the subscriptions of a connection
+func writeEth1StreamBlock(write func(*types.Eth1StreamMessage) error, subscriptions map[string]*types.Eth1StreamRequest, block *types.Eth1StreamBlock) error {
+	header := *block
+	header.Activity = nil
+	for id, subscription := range subscriptions {
+		if subscription.Topic == "blocks" {
+			err := write(&types.Eth1StreamMessage{Op: "block", Id: id, Data: &header})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		for _, activity := range block.Activity {
+			if !subscription.Filter.Matches(activity) {
+				continue
+			}
+			err := write(&types.Eth1StreamMessage{Op: "activity", Id: id, Data: activity})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -25,7 +25,7 @@ func GasNow(w http.ResponseWriter, r *http.Request) {
 	now := time.Now().Truncate(time.Minute)
 	lastWeek := time.Now().Truncate(time.Minute).Add(-time.Hour * 24 * 7)
 
-	history, err := db.BigtableClient.GetGasNowHistory(now, lastWeek)
+	history, err := db.BigtableClient.GetGasNowHistory(r.Context(), now, lastWeek)
 	if err != nil {
 		logger.Errorf("error retrieving gas price histors: %v", err)
 		return
@@ -22,6 +22,7 @@ var indexTemplateFiles = append(layoutTemplateFiles,
 	"index/preGenesis.html",
 	"index/recentBlocks.html",
 	"index/recentEpochs.html",
+	"index/topMovements.html",
 	"index/genesisCountdown.html",
 	"index/depositDistribution.html",
 	"svg/bricks.html",
@@ -46,6 +47,7 @@ func Index(w http.ResponseWriter, r *http.Request) {
 	pageData.Countdown = utils.Config.Frontend.Countdown
 
 	pageData.SlotVizData = getSlotVizData(data.CurrentEpoch)
+	pageData.TopMovements = services.LatestTopMovements()
 
 	data.Data = pageData
 
@@ -59,7 +61,10 @@ func IndexPageData(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", utils.Config.Chain.Config.SecondsPerSlot)) // set local cache to the seconds per slot interval
 
-	err := json.NewEncoder(w).Encode(services.LatestIndexPageData())
+	pageData := services.LatestIndexPageData()
+	pageData.TopMovements = services.LatestTopMovements()
+
+	err := json.NewEncoder(w).Encode(pageData)
 
 	if err != nil {
 		logger.Errorf("error sending latest index page data: %v", err)
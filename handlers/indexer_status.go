@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"net/http"
+)
+
+// IndexerStatus renders the operator page showing the progress of the eth1 indexing pipelines
+func IndexerStatus(w http.ResponseWriter, r *http.Request) {
+	isAdmin, user := handleAdminPermissions(w, r)
+	if !isAdmin {
+		return
+	}
+
+	templateFiles := append(layoutTemplateFiles, "user/indexer_status.html")
+	var indexerStatusTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+
+	progress, err := getIndexerStatus()
+	if err != nil {
+		logger.Errorf("error retrieving indexer progress: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data := InitPageData(w, r, "user", "/user/indexer_status", "Indexer Status", templateFiles)
+	data.Data = progress
+	data.User = user
+
+	if handleTemplateError(w, r, "indexer_status.go", "IndexerStatus", "", indexerStatusTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// IndexerStatusData returns the progress of the eth1 indexing pipelines as json
+func IndexerStatusData(w http.ResponseWriter, r *http.Request) {
+	isAdmin, _ := handleAdminPermissions(w, r)
+	if !isAdmin {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	progress, err := getIndexerStatus()
+	if err != nil {
+		logger.Errorf("error retrieving indexer progress: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(progress)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+func getIndexerStatus() ([]*types.IndexerStatus, error) {
+	progress, err := db.GetIndexerProgress()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]*types.IndexerStatus, 0, len(progress))
+	for _, p := range progress {
+		s := &types.IndexerStatus{
+			IndexerProgress: p,
+			Completion:      p.Completion(),
+		}
+		if eta := p.EstimatedCompletionTime(); !eta.IsZero() {
+			s.EstimatedCompletionTime = &eta
+		}
+		status = append(status, s)
+	}
+	return status, nil
+}
@@ -32,7 +32,34 @@ func UsersModalAddValidator(w http.ResponseWriter, r *http.Request) {
 		validators = strings.Split(validatorForm, ",")
 	}
 
+	pubkeys := make([][]byte, 0, len(validators))
 	for _, val := range validators {
+		val = strings.TrimSpace(val)
+		if utils.IsValidEth1Address(val) || utils.IsValidWithdrawalCredentials(val) {
+			// addresses are expanded to all validators withdrawing to (or proposing blocks for) them
+			source := r.FormValue("import_source")
+			if source == "" {
+				source = watchlistImportWithdrawal
+			}
+			imported, ok, err := getWatchlistImportPubkeys(source, val, getUserPremium(r).MaxValidators)
+			if err != nil || !ok {
+				if err != nil {
+					logger.WithError(err).Errorf("error retrieving validators of %v to import for user: %v", val, user.UserID)
+				}
+				utils.SetFlash(w, r, authSessionName, "Error: We could not find the validators of this address.")
+				http.Redirect(w, r, "/user/notifications", http.StatusSeeOther)
+				return
+			}
+			for _, pubkey := range imported {
+				key, err := hex.DecodeString(pubkey)
+				if err != nil {
+					continue
+				}
+				pubkeys = append(pubkeys, key)
+			}
+			continue
+		}
+
 		pubkey, _, err := GetValidatorIndexFrom(val)
 		if err != nil {
 			utils.LogError(err, "error parsing form", 0)
@@ -40,6 +67,10 @@ func UsersModalAddValidator(w http.ResponseWriter, r *http.Request) {
 			http.Redirect(w, r, "/user/notifications", http.StatusSeeOther)
 			return
 		}
+		pubkeys = append(pubkeys, pubkey)
+	}
+
+	for _, pubkey := range pubkeys {
 
 		// events[types.ValidatorMissedAttestationEventName] = "on" == r.FormValue(string(types.ValidatorMissedAttestationEventName))
 		// events[types.ValidatorMissedProposalEventName] = "on" == r.FormValue(string(types.ValidatorMissedProposalEventName))
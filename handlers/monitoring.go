@@ -31,6 +31,8 @@ func Monitoring(w http.ResponseWriter, r *http.Request) {
 		module = "monitoring_api"
 	case "redis":
 		module = "monitoring_redis"
+	case "anomalies":
+		module = "monitoring_anomalies"
 	default:
 		http.Error(w, "Invalid monitoring module provided", http.StatusNotFound)
 		return
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"net/http"
+)
+
+const networkNodesPageDays = 180
+
+// NetworkNodes will return the page showing the client, version and geographic distribution of the nodes found by the p2p network crawler
+func NetworkNodes(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "network_nodes.html")
+	var networkNodesTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "stats", "/nodes", "Network Nodes", templateFiles)
+
+	snapshots, err := db.GetNetworkNodeSnapshots(networkNodesPageDays)
+	if err != nil {
+		logger.Errorf("error retrieving network node snapshots: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	pageData := &types.NetworkNodesPageData{
+		Snapshots: snapshots,
+	}
+	if len(snapshots) > 0 {
+		pageData.Latest = snapshots[len(snapshots)-1]
+		pageData.Clients = pageData.Latest.Clients.Sorted(8)
+		pageData.Versions = pageData.Latest.Versions.Sorted(20)
+		pageData.Countries = pageData.Latest.Countries.Sorted(20)
+		for _, client := range pageData.Clients {
+			if client.Name != "other" {
+				pageData.ClientNames = append(pageData.ClientNames, client.Name)
+			}
+		}
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "network_nodes.go", "NetworkNodes", "", networkNodesTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type networkContextKey struct{}
+
+// paths that can be served for the additional networks of the frontend
+var networkScopedPaths = []string{"/address/"}
+
+// NetworkPrefixMiddleware serves requests of the form /{network}/address/... from the data sources of the network.
+// The network prefix is removed from the path before routing, other pages are not available for the additional networks.
+func NetworkPrefixMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		split := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+		if len(split) != 2 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		network := db.GetNetwork(split[0])
+		if network == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := "/" + split[1]
+		scoped := false
+		for _, prefix := range networkScopedPaths {
+			if strings.HasPrefix(path, prefix) {
+				scoped = true
+				break
+			}
+		}
+		if !scoped {
+			http.NotFound(w, r)
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), networkContextKey{}, network))
+		r.URL.Path = path
+		r.URL.RawPath = ""
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestNetwork returns the network a request is served for
+func requestNetwork(r *http.Request) *db.Network {
+	if network, ok := r.Context().Value(networkContextKey{}).(*db.Network); ok {
+		return network
+	}
+	return db.DefaultNetwork
+}
+
+// eth1StoreForRequest returns the execution index of the network a request is served for
+func eth1StoreForRequest(r *http.Request) db.Eth1Store {
+	return requestNetwork(r).Eth1Store()
+}
+
+// networkPath prefixes path with the network of the request
+func networkPath(r *http.Request, path string) string {
+	network := requestNetwork(r)
+	if network.IsDefault() {
+		return path
+	}
+	return "/" + network.Profile.Name + path
+}
+
+// bigtableForRequest returns the bigtable instance of the network a request is served for
+func bigtableForRequest(r *http.Request) *db.Bigtable {
+	return requestNetwork(r).Bigtable()
+}
+
+// ApiChainSelector serves execution api requests for the network selected by the chainId query parameter, requests without
+// the parameter are served for the chain the frontend is configured for
+func ApiChainSelector(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		chainId := r.URL.Query().Get("chainId")
+		if chainId == "" {
+			next(w, r)
+			return
+		}
+		network := db.GetNetworkByChainId(chainId)
+		if network == nil {
+			w.Header().Set("Content-Type", "application/json")
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("error unknown chainId %v. Please provide the chain id of one of the networks served by this explorer", chainId))
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), networkContextKey{}, network)))
+	}
+}
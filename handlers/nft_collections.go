@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+	"golang.org/x/sync/errgroup"
+)
+
+// number of days of mint statistics shown for a collection
+const nftCollectionMintHistoryDays = 30
+
+// nftCollectionAddress parses the collection address of the route, nil is returned for invalid addresses
+func nftCollectionAddress(r *http.Request) []byte {
+	address := strings.ToLower(strings.TrimPrefix(mux.Vars(r)["address"], "0x"))
+	if !utils.IsEth1Address(address) {
+		return nil
+	}
+	return common.FromHex(address)
+}
+
+// getNFTCollection returns the summary of a collection, nil is returned if no ERC721 or ERC1155 transfers of the token have been indexed
+func getNFTCollection(ctx context.Context, token []byte) (*types.APINFTCollectionResponse, []*types.NFTCollectionMints, error) {
+	standard, err := db.BigtableClient.GetNFTCollectionStandard(ctx, token)
+	if err != nil || standard == "" {
+		return nil, nil, err
+	}
+
+	collection := &types.APINFTCollectionResponse{
+		Address:  utils.FixAddressCasing(fmt.Sprintf("%x", token)),
+		Standard: standard,
+	}
+	var history []*types.NFTCollectionMints
+
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		metadata, err := db.GetEth1Store().GetERC20MetadataForAddress(ctx, token)
+		if err != nil {
+			return err
+		}
+		collection.Name = metadata.Name
+		collection.Symbol = metadata.Symbol
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		collection.Owners, collection.OwnersComplete, err = db.BigtableClient.GetNFTCollectionOwnerCount(ctx, token)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		history, err = db.GetNFTCollectionMintHistory(token, nftCollectionMintHistoryDays)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+
+	collection.DailyMints = make([]*types.APINFTCollectionMints, 0, len(history))
+	for _, day := range history {
+		collection.DailyMints = append(collection.DailyMints, &types.APINFTCollectionMints{Day: day.Day, Mints: day.Mints, Minters: day.Minters})
+	}
+	return collection, history, nil
+}
+
+// NFTCollection will return the page of an ERC721 or ERC1155 collection with its owners, mint activity and transfers
+func NFTCollection(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "execution/collection.html")
+	var collectionTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+
+	token := nftCollectionAddress(r)
+	if token == nil {
+		NotFound(w, r)
+		return
+	}
+
+	collection, history, err := getNFTCollection(r.Context(), token)
+	if err != nil {
+		logger.Errorf("error retrieving nft collection 0x%x: %v", token, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	if collection == nil {
+		NotFound(w, r)
+		return
+	}
+
+	transfers, err := db.BigtableClient.GetNFTCollectionTransfersTableData(r.Context(), token, collection.Standard, "")
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error retrieving transfers of nft collection 0x%x: %v", token, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	pageData := &types.NFTCollectionPageData{
+		Address:        collection.Address,
+		Name:           collection.Name,
+		Symbol:         collection.Symbol,
+		Standard:       collection.Standard,
+		Owners:         collection.Owners,
+		OwnersComplete: collection.OwnersComplete,
+		DailyMints:     history,
+		TransfersTable: transfers,
+	}
+	for _, day := range history {
+		pageData.Mints += day.Mints
+	}
+
+	title := fmt.Sprintf("Collection 0x%x", token)
+	if collection.Name != "" {
+		title = fmt.Sprintf("%v Collection", collection.Name)
+	}
+	data := InitPageData(w, r, "blockchain", "/collection", title, templateFiles)
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "nft_collections.go", "NFTCollection", "", collectionTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// NFTCollectionTransfers returns the next page of the transfers table of a collection
+func NFTCollectionTransfers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token := nftCollectionAddress(r)
+	if token == nil {
+		http.Error(w, "Invalid collection address", http.StatusBadRequest)
+		return
+	}
+
+	standard, err := db.BigtableClient.GetNFTCollectionStandard(r.Context(), token)
+	if err != nil {
+		logger.Errorf("error retrieving standard of nft collection 0x%x: %v", token, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	data := &types.DataTableResponse{}
+	if standard != "" {
+		data, err = db.BigtableClient.GetNFTCollectionTransfersTableData(r.Context(), token, standard, r.URL.Query().Get("pageToken"))
+		if err != nil && !db.IsPartialResult(err) {
+			logger.WithError(err).Errorf("error getting transfers of nft collection 0x%x", token)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	err = json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// ApiNFTCollection godoc
+// @Summary Get the owner count and the daily mints of the last 30 days of an ERC721 or ERC1155 collection
+// @Tags Execution
+// @Produce json
+// @Param address path string true "Address of the collection contract"
+// @Success 200 {object} types.ApiResponse{data=types.APINFTCollectionResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Router /api/v1/execution/collection/{address} [get]
+func ApiNFTCollection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token := nftCollectionAddress(r)
+	if token == nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	collection, _, err := getNFTCollection(r.Context(), token)
+	if err != nil {
+		logger.Errorf("error retrieving nft collection 0x%x route: %v err: %v", token, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error retrieving collection")
+		return
+	}
+	if collection == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error no nft transfers found for address", http.StatusNotFound)
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{collection})
+}
+
+// ApiNFTCollectionTransfers godoc
+// @Summary Get the transfers of an ERC721 or ERC1155 collection, most recent first
+// @Tags Execution
+// @Produce json
+// @Param address path string true "Address of the collection contract"
+// @Param cursor query string false "Cursor returned by the previous request, the page token of older responses is accepted as page parameter"
+// @Param limit query int false "Number of transfers to return, up to 100" default(25)
+// @Success 200 {object} types.ApiResponse{data=types.APINFTCollectionTransfersResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Router /api/v1/execution/collection/{address}/transfers [get]
+func ApiNFTCollectionTransfers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token := nftCollectionAddress(r)
+	if token == nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid address. A ethereum address consists of an optional 0x prefix followed by 40 hexadecimal characters.")
+		return
+	}
+
+	standard, err := db.BigtableClient.GetNFTCollectionStandard(r.Context(), token)
+	if err != nil {
+		logger.Errorf("error retrieving standard of nft collection 0x%x route: %v err: %v", token, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error retrieving collection transfers")
+		return
+	}
+	response := &types.APINFTCollectionTransfersResponse{Transfers: []*types.APINFTTransfer{}}
+	if standard == "" {
+		sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+		return
+	}
+
+	prefix := db.BigtableClient.NFTCollectionTransfersPrefix(token, standard)
+	cursor, err := parseApiCursor(r.URL.Query(), 25, 100, apiOrderDesc)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+	pageToken := ""
+	if cursor.Key != "" {
+		pageToken = cursor.RowKey(prefix)
+	}
+
+	transfers, lastKey, err := db.BigtableClient.GetNFTCollectionTransfers(r.Context(), token, standard, pageToken, int64(cursor.Limit))
+	if err != nil && !db.IsPartialResult(err) {
+		logger.Errorf("error retrieving transfers of nft collection 0x%x route: %v err: %v", token, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error retrieving collection transfers")
+		return
+	}
+	response.Page = db.EncodePageToken(prefix, lastKey)
+	for _, t := range transfers {
+		response.Transfers = append(response.Transfers, &types.APINFTTransfer{
+			TxHash:      fmt.Sprintf("0x%x", t.TxHash),
+			BlockNumber: t.BlockNumber,
+			Time:        t.Time,
+			From:        utils.FixAddressCasing(fmt.Sprintf("%x", t.From)),
+			To:          utils.FixAddressCasing(fmt.Sprintf("%x", t.To)),
+			TokenId:     new(big.Int).SetBytes(t.TokenId).String(),
+			Quantity:    new(big.Int).SetBytes(t.Value).String(),
+		})
+	}
+
+	sendOKResponseWithCursor(json.NewEncoder(w), r.URL.String(), response, cursor.NextRowKey(prefix, lastKey))
+}
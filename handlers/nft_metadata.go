@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+)
+
+// nftToken parses the contract address and the token id of the route, the token id can be given as decimal or as 0x prefixed
+// hexadecimal number. Nil is returned for invalid parameters.
+func nftToken(r *http.Request) ([]byte, *big.Int) {
+	vars := mux.Vars(r)
+	address := strings.ToLower(strings.TrimPrefix(vars["contract"], "0x"))
+	if !utils.IsEth1Address(address) {
+		return nil, nil
+	}
+
+	tokenId, ok := new(big.Int).SetString(vars["tokenId"], 10)
+	if strings.HasPrefix(vars["tokenId"], "0x") {
+		tokenId, ok = new(big.Int).SetString(vars["tokenId"][2:], 16)
+	}
+	if !ok || tokenId.Sign() < 0 || tokenId.BitLen() > 256 {
+		return nil, nil
+	}
+	return common.FromHex(address), tokenId
+}
+
+// ApiNFTMetadata godoc
+// @Summary Get the metadata of a token of an ERC721 or ERC1155 collection
+// @Tags Execution
+// @Description The metadata is resolved in the background via the tokenURI or uri method of the contract, ipfs and arweave uris are fetched from public gateways. Only tokens with indexed transfers are resolved, 202 is returned until the metadata of a token has been resolved. Metadata that could not be resolved is returned with an error and retried after an hour.
+// @Produce json
+// @Param contract path string true "Address of the collection contract"
+// @Param tokenId path string true "Id of the token, decimal or 0x prefixed hexadecimal"
+// @Success 200 {object} types.ApiResponse{data=types.APINFTMetadataResponse}
+// @Success 202 {object} types.ApiResponse
+// @Failure 400 {object} types.ApiResponse
+// @Failure 404 {object} types.ApiResponse
+// @Router /api/v1/nft/{contract}/{tokenId}/metadata [get]
+func ApiNFTMetadata(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	token, tokenId := nftToken(r)
+	if token == nil {
+		sendErrorResponse(w, r.URL.String(), "error invalid contract address or token id")
+		return
+	}
+
+	metadata, err := db.BigtableClient.GetNFTMetadata(r.Context(), token, tokenId)
+	if errors.Is(err, db.ErrNFTMetadataPending) {
+		w.WriteHeader(http.StatusAccepted)
+		sendOKResponse(json.NewEncoder(w), r.URL.String(), nil)
+		return
+	}
+	if err != nil {
+		logger.Errorf("error retrieving metadata of nft 0x%x:%v route: %v err: %v", token, tokenId, r.URL.String(), err)
+		sendServerErrorResponse(w, r.URL.String(), "error retrieving nft metadata")
+		return
+	}
+	if metadata == nil {
+		sendErrorWithCodeResponse(w, r.URL.String(), "error no nft transfers found for token", http.StatusNotFound)
+		return
+	}
+
+	response := &types.APINFTMetadataResponse{
+		Address:      fmt.Sprintf("0x%x", token),
+		TokenId:      tokenId.String(),
+		Standard:     metadata.Standard,
+		TokenURI:     metadata.TokenURI,
+		Name:         metadata.Name,
+		Description:  metadata.Description,
+		Image:        metadata.Image,
+		AnimationURL: metadata.AnimationURL,
+		ExternalURL:  metadata.ExternalURL,
+		Attributes:   metadata.Attributes,
+		Error:        metadata.Error,
+	}
+	if response.Attributes == nil {
+		response.Attributes = []*types.NFTAttribute{}
+	}
+	if metadata.Image != "" {
+		response.Preview = utils.NFTPreviewURL(token, tokenId)
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
+// NFTImage serves the thumbnail of the image of a token of an ERC721 or ERC1155 collection
+func NFTImage(w http.ResponseWriter, r *http.Request) {
+	token, tokenId := nftToken(r)
+	if token == nil {
+		http.Error(w, "Invalid contract address or token id", http.StatusBadRequest)
+		return
+	}
+
+	thumbnail, format, err := db.BigtableClient.GetNFTThumbnail(r.Context(), token, tokenId)
+	if errors.Is(err, db.ErrNFTMetadataPending) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		logger.Errorf("error retrieving thumbnail of nft 0x%x:%v route: %v err: %v", token, tokenId, r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	if format == "" {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	// the images are chosen by the token contract, svg images must not run scripts
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, err = w.Write(thumbnail)
+	if err != nil {
+		logger.Debugf("error writing thumbnail of nft 0x%x:%v: %v", token, tokenId, err)
+	}
+}
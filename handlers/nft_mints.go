@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"math/big"
+	"net/http"
+)
+
+// NFTMints will return the page listing the most recent ERC721 and ERC1155 mints and the top minted collections of the last exported day
+func NFTMints(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "nft_mints.html")
+	var nftMintsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "blockchain", "/nfts/mints", "NFT Mints", templateFiles)
+
+	mints, err := db.BigtableClient.GetRecentNFTMints(r.Context(), 100)
+	if err != nil {
+		logger.Errorf("error retrieving recent nft mints: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	collections, err := db.GetTopNFTCollectionMints(20)
+	if err != nil {
+		logger.Errorf("error retrieving top nft collections: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	names := make(map[string]string)
+	for _, m := range mints {
+		names[string(m.TokenAddress)] = ""
+		names[string(m.To)] = ""
+	}
+	for _, c := range collections {
+		names[string(c.TokenAddress)] = ""
+	}
+	err = db.GetEth1Store().GetAddressNames(r.Context(), names)
+	if err != nil {
+		logger.Errorf("error retrieving names of nft mints: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	pageData := &types.NFTMintsPageData{
+		Collections: make([]*types.NFTMintsCollection, 0, len(collections)),
+		Mints:       make([]*types.NFTMintsRow, 0, len(mints)),
+	}
+	for _, c := range collections {
+		pageData.Collections = append(pageData.Collections, &types.NFTMintsCollection{
+			Day:        c.Day,
+			Collection: utils.FormatAddressWithLimits(c.TokenAddress, names[string(c.TokenAddress)], true, "collection", 17, 20, false),
+			Standard:   c.Standard,
+			Mints:      c.Mints,
+			Minters:    c.Minters,
+		})
+	}
+	for _, m := range mints {
+		pageData.Mints = append(pageData.Mints, &types.NFTMintsRow{
+			TxHash:      utils.FormatTransactionHash(m.TxHash),
+			BlockNumber: m.BlockNumber,
+			Time:        m.Time,
+			Collection:  utils.FormatAddressWithLimits(m.TokenAddress, names[string(m.TokenAddress)], true, "collection", 17, 20, false),
+			Standard:    m.Standard,
+			TokenId:     new(big.Int).SetBytes(m.TokenId).String(),
+			Quantity:    new(big.Int).SetBytes(m.Value).String(),
+			To:          utils.FormatAddress(m.To, nil, names[string(m.To)], false, false, true),
+		})
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "nft_mints.go", "NFTMints", "", nftMintsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
@@ -77,18 +77,30 @@ func InitPageData(w http.ResponseWriter, r *http.Request, active, path, title st
 			CurrentPriceKFormatted: GetCurrentPriceKFormatted(r),
 			CurrentSymbol:          GetCurrencySymbol(r),
 		},
-		Mainnet:             utils.Config.Chain.Config.ConfigName == "mainnet",
-		DepositContract:     utils.Config.Chain.Config.DepositContractAddress,
-		ClientsUpdated:      ethclients.ClientsUpdated(),
-		ChainConfig:         utils.Config.Chain.Config,
-		Lang:                "en-US",
-		NoAds:               user.Authenticated && user.Subscription != "",
-		Debug:               utils.Config.Frontend.Debug,
-		GasNow:              services.LatestGasNowData(),
-		ShowSyncingMessage:  services.IsSyncing(),
-		GlobalNotification:  services.GlobalNotificationMessage(),
-		AvailableCurrencies: price.GetAvailableCurrencies(),
-		MainMenuItems:       createMenuItems(active, isMainnet),
+		Mainnet:                utils.Config.Chain.Config.ConfigName == "mainnet",
+		DepositContract:        utils.Config.Chain.Config.DepositContractAddress,
+		ClientsUpdated:         ethclients.ClientsUpdated(),
+		ChainConfig:            utils.Config.Chain.Config,
+		Lang:                   "en-US",
+		NoAds:                  user.Authenticated && user.Subscription != "",
+		Debug:                  utils.Config.Frontend.Debug,
+		GasNow:                 services.LatestGasNowData(),
+		ShowSyncingMessage:     services.IsSyncing(),
+		GlobalNotification:     services.GlobalNotificationMessage(),
+		AvailableCurrencies:    price.GetAvailableCurrencies(),
+		MainMenuItems:          createMenuItems(active, isMainnet),
+		Timezone:               GetTimezone(r),
+		TimestampMode:          GetTimestampMode(r),
+		AvailableTimezones:     utils.AvailableTimezones,
+		NumberLocale:           GetNumberLocale(r),
+		AvailableNumberLocales: utils.AvailableNumberLocales,
+	}
+
+	for _, network := range db.GetNetworks() {
+		data.Networks = append(data.Networks, types.PageNetwork{Name: network.Profile.Name, Label: network.Profile.Label, Sandbox: network.Profile.Sandbox})
+	}
+	if network := requestNetwork(r); !network.IsDefault() {
+		data.Network = &types.PageNetwork{Name: network.Profile.Name, Label: network.Profile.Label, Sandbox: network.Profile.Sandbox}
 	}
 
 	adConfigurations, err := db.GetAdConfigurationsForTemplate(mainTemplates, data.NoAds)
@@ -261,6 +273,21 @@ func createMenuItems(active string, isMain bool) []types.MainMenuItem {
 							Path:  "/mempool",
 							Icon:  "fa-upload",
 						},
+						{
+							Label: "Whale Watch",
+							Path:  "/whales",
+							Icon:  "fa-fish",
+						},
+						{
+							Label: "NFT Mints",
+							Path:  "/nfts/mints",
+							Icon:  "fa-palette",
+						},
+						{
+							Label: "Account Abstraction",
+							Path:  "/aa",
+							Icon:  "fa-user-shield",
+						},
 					},
 				},
 			},
@@ -429,6 +456,11 @@ func createMenuItems(active string, isMain bool) []types.MainMenuItem {
 							Path:  "/tools/unitConverter",
 							Icon:  "fa-sync",
 						},
+						{
+							Label: "CREATE2 Calculator",
+							Path:  "/tools/create2",
+							Icon:  "fa-calculator",
+						},
 						{
 							Label: "GasNow",
 							Path:  "/gasnow",
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"net/http"
+
+	"github.com/gorilla/csrf"
+)
+
+const reorgsFrequencyDays = 90
+
+// Reorgs will return the page listing the recently replaced execution blocks and orphaned consensus blocks
+func Reorgs(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "reorgs.html")
+	var reorgsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "blockchain", "/reorgs", "Reorgs", templateFiles)
+
+	pageData := &types.ReorgsPageData{
+		Layer: types.ReorgLayer(r.URL.Query().Get("layer")),
+	}
+	if pageData.Layer != types.ExecutionLayerReorg && pageData.Layer != types.ConsensusLayerReorg {
+		pageData.Layer = ""
+	}
+
+	var err error
+	pageData.Reorgs, err = db.GetReorgs(pageData.Layer, 100)
+	if err != nil {
+		logger.Errorf("error retrieving reorgs: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	pageData.FrequencyConsensus, err = db.GetDailyReorgCounts(types.ConsensusLayerReorg, reorgsFrequencyDays)
+	if err != nil {
+		logger.Errorf("error retrieving consensus layer reorg frequency: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	pageData.FrequencyExecution, err = db.GetDailyReorgCounts(types.ExecutionLayerReorg, reorgsFrequencyDays)
+	if err != nil {
+		logger.Errorf("error retrieving execution layer reorg frequency: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	if data.User.Authenticated {
+		pageData.CsrfField = csrf.TemplateField(r)
+		var thresholds []float64
+		err = db.FrontendWriterDB.Select(&thresholds, `
+			select coalesce(event_threshold, 0)
+			from users_subscriptions
+			where user_id = $1 and event_name = $2`, data.User.UserID, utils.GetNetwork()+":"+string(types.NetworkDeepReorgEventName))
+		if err != nil {
+			logger.Errorf("error getting user subscriptions: %v route: %v", r.URL.String(), err)
+		}
+		if len(thresholds) > 0 {
+			pageData.Subscribed = true
+			pageData.SubscriptionThreshold = thresholds[0]
+		}
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "reorgs.go", "Reorgs", "", reorgsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
@@ -1,17 +1,23 @@
 package handlers
 
 import (
+	"context"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"eth2-exporter/db"
+	"eth2-exporter/eth1data"
 	"eth2-exporter/templates"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
+	"math/big"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/lib/pq"
@@ -103,7 +109,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 	case "blocks":
 		number, err := strconv.ParseUint(search, 10, 64)
 		if err == nil {
-			block, err := db.BigtableClient.GetBlockFromBlocksTable(number)
+			block, err := db.GetEth1Store().GetBlockFromBlocksTable(r.Context(), number)
 			if err == nil {
 				result = &types.SearchAheadBlocksResult{{
 					Block: block.Number,
@@ -136,7 +142,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			var tx *types.Eth1TransactionIndexed
-			tx, err = db.BigtableClient.GetIndexedEth1Transaction(txHash)
+			tx, err = db.GetEth1Store().GetIndexedEth1Transaction(r.Context(), txHash)
 			if err == nil && tx != nil {
 				result = &types.SearchAheadTransactionsResult{{TxHash: fmt.Sprintf("%x", tx.Hash)}}
 			}
@@ -190,7 +196,7 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, "Internal server error", http.StatusServiceUnavailable)
 				return
 			}
-			result, err = db.BigtableClient.SearchForAddress(eth1AddressHash, 10)
+			result, err = db.BigtableClient.SearchForAddress(r.Context(), eth1AddressHash, 10)
 			if err != nil {
 				logger.Errorf("error searching for eth1AddressHash: %v", err)
 				http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -414,3 +420,295 @@ func SearchAhead(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
 	}
 }
+
+// searchSuggestionsBudget is the maximum time the keyboard search waits for its lookups, results of slower lookups are dropped
+const searchSuggestionsBudget = time.Millisecond * 400
+const searchSuggestionsLimit = 5
+
+type searchSuggestionsLookup func(ctx context.Context, search string) ([]*types.SearchSuggestion, error)
+
+// SearchSuggestions returns typed suggestions (blocks, transactions, addresses, tokens, validators, ens names and graffiti) for the keyboard search
+func SearchSuggestions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	search := strings.TrimSpace(mux.Vars(r)["search"])
+	response := &types.SearchSuggestionsResponse{Suggestions: []*types.SearchSuggestion{}}
+
+	if len(search) > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), searchSuggestionsBudget)
+		defer cancel()
+
+		lookups := []searchSuggestionsLookup{
+			searchSuggestionsBlocks,
+			searchSuggestionsTransactions,
+			searchSuggestionsAddresses,
+			searchSuggestionsValidators,
+			searchSuggestionsEns,
+			searchSuggestionsGraffiti,
+		}
+
+		type lookupResult struct {
+			index       int
+			suggestions []*types.SearchSuggestion
+		}
+		results := make(chan lookupResult, len(lookups))
+		for i, lookup := range lookups {
+			go func(i int, lookup searchSuggestionsLookup) {
+				suggestions, err := lookup(ctx, search)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.WithError(err).WithField("search", search).Warn("error retrieving search suggestions")
+					}
+					suggestions = nil
+				}
+				results <- lookupResult{index: i, suggestions: suggestions}
+			}(i, lookup)
+		}
+
+		// keep the order of the lookups stable so the most specific suggestions come first
+		ordered := make([][]*types.SearchSuggestion, len(lookups))
+	collect:
+		for received := 0; received < len(lookups); received++ {
+			select {
+			case res := <-results:
+				ordered[res.index] = res.suggestions
+			case <-ctx.Done():
+				response.Incomplete = true
+				break collect
+			}
+		}
+		for _, suggestions := range ordered {
+			response.Suggestions = append(response.Suggestions, suggestions...)
+		}
+	}
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		logger.WithError(err).Error("error encoding search suggestions")
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+	}
+}
+
+func searchSuggestionsBlocks(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	number, err := strconv.ParseUint(search, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	block, err := db.GetEth1Store().GetBlockFromBlocksTable(ctx, number)
+	if err != nil {
+		// the block does not exist (yet)
+		return nil, nil
+	}
+	return []*types.SearchSuggestion{{
+		Type:  types.SearchSuggestionBlock,
+		Icon:  "fa-cube",
+		Label: fmt.Sprintf("Block %v", block.Number),
+		Value: fmt.Sprintf("%v", block.Number),
+		Path:  fmt.Sprintf("/block/%v", block.Number),
+		Meta: map[string]interface{}{
+			"hash":         fmt.Sprintf("%#x", block.Hash),
+			"ts":           block.Time.AsTime().Unix(),
+			"transactions": len(block.Transactions),
+		},
+	}}, nil
+}
+
+func searchSuggestionsTransactions(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	search = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(search, "0x"), "0X"))
+	if !transactionLikeRE.MatchString(search) {
+		return nil, nil
+	}
+	txHash, err := hex.DecodeString(search)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.GetEth1Store().GetIndexedEth1Transaction(ctx, txHash)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	return []*types.SearchSuggestion{{
+		Type:  types.SearchSuggestionTransaction,
+		Icon:  "fa-credit-card",
+		Label: fmt.Sprintf("%#x", tx.Hash),
+		Value: fmt.Sprintf("%#x", tx.Hash),
+		Path:  fmt.Sprintf("/tx/%#x", tx.Hash),
+		Meta: map[string]interface{}{
+			"block": tx.BlockNumber,
+			"ts":    tx.Time.AsTime().Unix(),
+			"from":  fmt.Sprintf("%#x", tx.From),
+			"to":    fmt.Sprintf("%#x", tx.To),
+			"value": utils.WeiToEther(new(big.Int).SetBytes(tx.Value)).String(),
+		},
+	}}, nil
+}
+
+func searchSuggestionsAddresses(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	search = strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(search, "0x"), "0X"))
+	if len(search) <= 1 || len(search) > 40 || !searchLikeRE.MatchString(search) {
+		return nil, nil
+	}
+	if len(search)%2 != 0 {
+		search = search[:len(search)-1]
+	}
+	prefix, err := hex.DecodeString(search)
+	if err != nil {
+		return nil, err
+	}
+	items, err := db.BigtableClient.SearchForAddress(ctx, prefix, searchSuggestionsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*types.SearchSuggestion, 0, len(items))
+	for _, item := range items {
+		suggestion := &types.SearchSuggestion{
+			Type:  types.SearchSuggestionAddress,
+			Icon:  "fa-wallet",
+			Label: "0x" + item.Address,
+			Value: "0x" + item.Address,
+			Path:  "/address/0x" + item.Address,
+			Meta:  map[string]interface{}{},
+		}
+		if item.Name != "" {
+			suggestion.Label = item.Name
+			suggestion.Meta["name"] = item.Name
+		}
+		if item.Token != "" {
+			suggestion.Type = types.SearchSuggestionToken
+			suggestion.Icon = "fa-coins"
+			suggestion.Path = "/token/0x" + item.Address
+			suggestion.Meta["standard"] = item.Token
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// only load the balance for exact matches, prefix matches would need one lookup per suggestion
+	if len(prefix) == 20 && len(suggestions) == 1 && suggestions[0].Type == types.SearchSuggestionAddress {
+		balance, err := db.GetEth1Store().GetBalanceForAddress(ctx, prefix, []byte{0x00})
+		if err == nil && balance != nil {
+			suggestions[0].Meta["balance"] = utils.WeiToEther(new(big.Int).SetBytes(balance.Balance)).String()
+		}
+	}
+	return suggestions, nil
+}
+
+func searchSuggestionsValidators(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	validators := []struct {
+		Index   uint64         `db:"validatorindex"`
+		Pubkey  string         `db:"pubkeyhex"`
+		Status  string         `db:"status"`
+		Balance uint64         `db:"balance"`
+		Name    sql.NullString `db:"name"`
+	}{}
+
+	var err error
+	trimmed := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(search, "0x"), "0X"))
+	if index, errParse := strconv.ParseUint(search, 10, 64); errParse == nil {
+		err = db.ReaderDb.SelectContext(ctx, &validators, `
+			SELECT validatorindex, pubkeyhex, status, balance, validator_names.name
+			FROM validators
+			LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
+			WHERE validatorindex = $1`, index)
+	} else if thresholdHexLikeRE.MatchString(trimmed) {
+		err = db.ReaderDb.SelectContext(ctx, &validators, `
+			SELECT validatorindex, pubkeyhex, status, balance, validator_names.name
+			FROM validators
+			LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
+			WHERE pubkeyhex LIKE ($1 || '%')
+			ORDER BY validatorindex LIMIT $2`, trimmed, searchSuggestionsLimit)
+	} else if len(search) > 1 {
+		err = db.ReaderDb.SelectContext(ctx, &validators, `
+			SELECT validatorindex, pubkeyhex, status, balance, validator_names.name
+			FROM validators
+			LEFT JOIN validator_names ON validators.pubkey = validator_names.publickey
+			WHERE LOWER(validator_names.name) LIKE LOWER($1)
+			ORDER BY validatorindex LIMIT $2`, search+"%", searchSuggestionsLimit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*types.SearchSuggestion, 0, len(validators))
+	for _, v := range validators {
+		label := fmt.Sprintf("Validator %v", v.Index)
+		if v.Name.Valid && v.Name.String != "" {
+			label = fmt.Sprintf("%v (%v)", v.Name.String, v.Index)
+		}
+		suggestions = append(suggestions, &types.SearchSuggestion{
+			Type:  types.SearchSuggestionValidator,
+			Icon:  "fa-male",
+			Label: label,
+			Value: fmt.Sprintf("%v", v.Index),
+			Path:  fmt.Sprintf("/validator/%v", v.Index),
+			Meta: map[string]interface{}{
+				"pubkey":  "0x" + v.Pubkey,
+				"status":  v.Status,
+				"balance": float64(v.Balance) / 1e9,
+			},
+		})
+	}
+	return suggestions, nil
+}
+
+func searchSuggestionsEns(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	if !utils.IsEnsName(search) {
+		return nil, nil
+	}
+	address, err := eth1data.ResolveEnsName(ctx, search)
+	if err != nil {
+		// unregistered names are expected while the user is typing
+		return nil, nil
+	}
+	return []*types.SearchSuggestion{{
+		Type:  types.SearchSuggestionEns,
+		Icon:  "fa-address-card",
+		Label: strings.ToLower(search),
+		Value: address.Hex(),
+		Path:  "/address/" + address.Hex(),
+		Meta: map[string]interface{}{
+			"address": address.Hex(),
+		},
+	}}, nil
+}
+
+// escapeLikePattern escapes the wildcards of a LIKE pattern so that search matches literally
+func escapeLikePattern(search string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search)
+}
+
+func searchSuggestionsGraffiti(ctx context.Context, search string) ([]*types.SearchSuggestion, error) {
+	// shorter patterns contain no trigram and can not use the idx_blocks_graffiti_text index
+	if len(search) < 3 {
+		return nil, nil
+	}
+	graffiti := []struct {
+		Graffiti string `db:"graffiti"`
+		Count    uint64 `db:"count"`
+	}{}
+	err := db.ReaderDb.SelectContext(ctx, &graffiti, `
+		SELECT graffiti, count(*)
+		FROM blocks
+		WHERE graffiti_text ILIKE $1
+		GROUP BY graffiti
+		ORDER BY count desc
+		LIMIT $2`, "%"+escapeLikePattern(search)+"%", searchSuggestionsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*types.SearchSuggestion, 0, len(graffiti))
+	for _, g := range graffiti {
+		text := utils.FormatGraffitiString(g.Graffiti)
+		suggestions = append(suggestions, &types.SearchSuggestion{
+			Type:  types.SearchSuggestionGraffiti,
+			Icon:  "fa-paint-brush",
+			Label: text,
+			Value: text,
+			Path:  "/slots?q=" + url.QueryEscape(text),
+			Meta: map[string]interface{}{
+				"blocks": g.Count,
+			},
+		})
+	}
+	return suggestions, nil
+}
@@ -40,7 +40,8 @@ func Slot(w http.ResponseWriter, r *http.Request) {
 		"slot/proposerSlashing.html",
 		"slot/exits.html",
 		"slot/overview.html",
-		"slot/execTransactions.html")
+		"slot/execTransactions.html",
+		"slot/execInternalTransactions.html")
 	slotFutureTemplateFiles := append(layoutTemplateFiles, "slot/slotFuture.html")
 	blockNotFoundTemplateFiles := append(layoutTemplateFiles, "slotnotfound.html")
 	var slotTemplate = templates.GetTemplate(slotTemplateFiles...)
@@ -122,7 +123,7 @@ func Slot(w http.ResponseWriter, r *http.Request) {
 
 	if blockPageData.ExecBlockNumber.Int64 != 0 && blockPageData.Status == 1 {
 		// slot has corresponding execution block, fetch execution data
-		eth1BlockPageData, err := GetExecutionBlockPageData(uint64(blockPageData.ExecBlockNumber.Int64), 10)
+		eth1BlockPageData, err := GetExecutionBlockPageData(r.Context(), uint64(blockPageData.ExecBlockNumber.Int64), 10)
 		// if err != nil, simply show slot view without block
 		if err == nil {
 			blockPageData.ExecutionData = eth1BlockPageData
@@ -704,7 +705,7 @@ func BlockTransactionsData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transactions, err := GetExecutionBlockPageData(slot, 0)
+	transactions, err := GetExecutionBlockPageData(r.Context(), slot, 0)
 	if err != nil || transactions == nil {
 		logger.Errorf("error retrieving transactions data for slot %v, err: %v", slot, err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"net/http"
+)
+
+// Status will return the status page showing the state of the monitoring modules and recently detected chain anomalies
+func Status(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "status.html")
+	var statusTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "stats", "/status", "Status", templateFiles)
+
+	pageData := &types.StatusPageData{
+		Anomalies: services.LatestAnomalies(),
+	}
+	err := db.WriterDb.Select(&pageData.Modules, `
+		SELECT DISTINCT ON (name) name, status, last_update
+		FROM service_status
+		WHERE name LIKE 'monitoring_%' AND last_update > NOW() - INTERVAL '15 MINUTES'
+		ORDER BY name, last_update DESC`)
+	if err != nil {
+		logger.Errorf("error retrieving monitoring status: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "status.go", "Status", "", statusTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
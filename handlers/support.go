@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// support staff can look up the notification subscriptions, api usage and watchlists of users but can not modify them,
+// admins have the same read access
+const supportUserGroup = "SUPPORT"
+
+// returns true if support permissions are available, otherwise http.Error is called and false is returned
+func handleSupportPermissions(w http.ResponseWriter, r *http.Request) (bool, *types.User) {
+	user, _, err := getUserSession(r)
+	if err != nil {
+		utils.LogError(err, "error retrieving session", 0)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return false, user
+	}
+
+	if user.UserGroup != supportUserGroup && user.UserGroup != "ADMIN" {
+		http.Error(w, "Insufficient privileges", http.StatusUnauthorized)
+		return false, user
+	}
+
+	return true, user
+}
+
+// supportLookupUser resolves the user of the request, either by id or by email, and records the access in the audit log.
+// If the data of the user may not be returned http.Error is called and nil is returned.
+func supportLookupUser(w http.ResponseWriter, r *http.Request, action string) *types.SupportUserData {
+	ok, staff := handleSupportPermissions(w, r)
+	if !ok {
+		return nil
+	}
+
+	target := &types.SupportUserData{}
+	query := strings.TrimSpace(mux.Vars(r)["user"])
+	var err error
+	if id, parseErr := strconv.ParseUint(query, 10, 64); parseErr == nil {
+		target.UserID = id
+		target.Email, err = db.GetUserEmailById(id)
+	} else {
+		target.Email = strings.ToLower(query)
+		target.UserID, err = db.GetUserIdByEmail(target.Email)
+	}
+	if err == sql.ErrNoRows {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return nil
+	}
+	if err != nil {
+		logger.Errorf("error looking up user %v for support: %v", query, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil
+	}
+
+	// the forwarded chain is stored as is, only the client ip passed by the trusted proxy is recorded as the address of the access
+	forwardedFor := strings.Join(r.Header.Values("X-Forwarded-For"), ", ")
+	// the data is not returned if the access can not be audited
+	err = db.InsertSupportAuditLog(staff.UserID, target.UserID, action, r.URL.String(), utils.ClientIp(r), forwardedFor)
+	if err != nil {
+		logger.Errorf("error writing support audit log of user %v accessing %v of user %v: %v", staff.UserID, action, target.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil
+	}
+	logger.Infof("support user %v accessed %v of user %v", staff.UserID, action, target.UserID)
+
+	return target
+}
+
+func sendSupportUserData(w http.ResponseWriter, r *http.Request, data *types.SupportUserData) {
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		logger.Errorf("error enconding json response for %v route: %v", r.URL.String(), err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+}
+
+// SupportUserSubscriptions returns the notification subscriptions of a user
+func SupportUserSubscriptions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data := supportLookupUser(w, r, "subscriptions")
+	if data == nil {
+		return
+	}
+
+	subs := []types.Subscription{}
+	err := db.FrontendReaderDB.Select(&subs, `
+		SELECT event_name, event_filter, last_sent_ts, created_ts, event_threshold
+		FROM users_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_ts DESC`, data.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving subscriptions of user %v for support: %v", data.UserID, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	data.Subscriptions = make([]*types.SupportUserSubscription, 0, len(subs))
+	for _, sub := range subs {
+		data.Subscriptions = append(data.Subscriptions, &types.SupportUserSubscription{
+			EventName:      sub.EventName,
+			EventFilter:    sub.EventFilter,
+			EventThreshold: sub.EventThreshold,
+			CreatedTime:    sub.CreatedTime,
+			LastSent:       sub.LastSent,
+		})
+	}
+
+	sendSupportUserData(w, r, data)
+}
+
+// SupportUserApiUsage returns the api plan and the api usage of a user
+func SupportUserApiUsage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data := supportLookupUser(w, r, "api_usage")
+	if data == nil {
+		return
+	}
+
+	subscription, err := db.StripeGetUserSubscription(data.UserID, utils.GROUP_API)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Errorf("error retrieving api subscription of user %v for support: %v", data.UserID, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	usage := &types.SupportUserApiUsage{
+		HasApiKey: subscription.ApiKey != nil && len(*subscription.ApiKey) > 0,
+		PriceID:   subscription.PriceID,
+		Active:    subscription.Active,
+	}
+	usage.MaxDaily, usage.MaxMonthly = apiRateLimits(subscription.PriceID)
+
+	if usage.HasApiKey {
+		stats, err := db.GetUserAPIKeyStatistics(subscription.ApiKey)
+		if err != nil {
+			logger.Errorf("error retrieving api key usage of user %v for support: %v", data.UserID, err)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+		if stats.Daily != nil {
+			usage.Daily = *stats.Daily
+		}
+		if stats.Monthly != nil {
+			usage.Monthly = *stats.Monthly
+		}
+
+		webhooks, err := db.GetUserApiWebhooks(data.UserID)
+		if err != nil {
+			logger.Errorf("error retrieving api webhooks of user %v for support: %v", data.UserID, err)
+			http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+			return
+		}
+		usage.WebhookURLs = len(webhooks)
+	}
+	data.ApiUsage = usage
+
+	sendSupportUserData(w, r, data)
+}
+
+// SupportUserWatchlist returns the validators on the watchlist of a user
+func SupportUserWatchlist(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	data := supportLookupUser(w, r, "watchlist")
+	if data == nil {
+		return
+	}
+
+	watchlist, err := db.GetTaggedValidators(db.WatchlistFilter{
+		UserId:         data.UserID,
+		Tag:            types.ValidatorTagsWatchlist,
+		JoinValidators: true,
+		Network:        utils.GetNetwork(),
+	})
+	if err != nil {
+		logger.Errorf("error retrieving watchlist of user %v for support: %v", data.UserID, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	data.Watchlist = make([]*types.SupportUserWatchlistItem, 0, len(watchlist))
+	for _, entry := range watchlist {
+		item := &types.SupportUserWatchlistItem{Pubkey: fmt.Sprintf("0x%x", entry.ValidatorPublickey)}
+		// validators that are not yet known to the explorer are joined with an empty validator
+		if entry.Validator != nil && bytes.Equal(entry.Validator.PublicKey, entry.ValidatorPublickey) {
+			index := entry.Validator.Index
+			item.ValidatorIndex = &index
+		}
+		data.Watchlist = append(data.Watchlist, item)
+	}
+
+	sendSupportUserData(w, r, data)
+}
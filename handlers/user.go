@@ -23,6 +23,7 @@ import (
 
 	ctxt "context"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/gorilla/context"
 	"github.com/gorilla/csrf"
 	"github.com/gorilla/mux"
@@ -88,20 +89,7 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 		statsSharing = false
 	}
 
-	maxDaily := 10000
-	maxMonthly := 30000
-	if subscription.PriceID != nil {
-		if *subscription.PriceID == utils.Config.Frontend.Stripe.Sapphire {
-			maxDaily = 100000
-			maxMonthly = 500000
-		} else if *subscription.PriceID == utils.Config.Frontend.Stripe.Emerald {
-			maxDaily = 200000
-			maxMonthly = 1000000
-		} else if *subscription.PriceID == utils.Config.Frontend.Stripe.Diamond {
-			maxDaily = -1
-			maxMonthly = 4000000
-		}
-	}
+	maxDaily, maxMonthly := apiRateLimits(subscription.PriceID)
 
 	userSettingsData.ApiStatistics = &types.ApiStatistics{}
 
@@ -113,7 +101,14 @@ func UserSettings(w http.ResponseWriter, r *http.Request) {
 		if apiStats != nil {
 			userSettingsData.ApiStatistics = apiStats
 		}
+
+		apiWebhooks, err := db.GetUserApiWebhooks(user.UserID)
+		if err != nil {
+			logger.Errorf("Error retrieving user api webhooks: %v %v", user.UserID, err)
+		}
+		userSettingsData.ApiWebhooks = apiWebhooks
 	}
+	userSettingsData.ApiWebhookEvents = types.ApiWebhookEvents
 
 	userSettingsData.ApiStatistics.MaxDaily = &maxDaily
 	userSettingsData.ApiStatistics.MaxMonthly = &maxMonthly
@@ -770,7 +765,7 @@ func UserNotificationsCenter(w http.ResponseWriter, r *http.Request) {
 	// 	return
 	// }
 
-	machines, err := db.BigtableClient.GetMachineMetricsMachineNames(user.UserID)
+	machines, err := db.BigtableClient.GetMachineMetricsMachineNames(r.Context(), user.UserID)
 	if err != nil {
 		logger.Errorf("error retrieving user machines for user %v: %v ", user.UserID, err)
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -964,7 +959,7 @@ func UserNotificationsData(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(indices, services.LatestEpoch(), services.LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), indices, services.LatestEpoch(), services.LatestEpoch())
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance data")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
@@ -1670,6 +1665,82 @@ func UserDashboardWatchlistAdd(w http.ResponseWriter, r *http.Request) {
 	OKResponse(w, r)
 }
 
+// sources of a bulk watchlist import
+const (
+	watchlistImportWithdrawal   = "withdrawal"
+	watchlistImportFeeRecipient = "fee_recipient"
+)
+
+// getWatchlistImportPubkeys returns the public keys of the validators withdrawing to or proposing blocks for the given address.
+// ok is false if the source or address of the import are invalid.
+func getWatchlistImportPubkeys(source, address string, limit int) (pubkeys []string, ok bool, err error) {
+	address = strings.ToLower(address)
+	switch source {
+	case watchlistImportWithdrawal:
+		if !utils.IsValidEth1Address(address) && !utils.IsValidWithdrawalCredentials(address) {
+			return nil, false, nil
+		}
+		credentials, err := utils.AddressToWithdrawalCredentials(common.FromHex(address))
+		if err != nil {
+			// Input is not an address so it must already be withdrawal credentials
+			credentials = common.FromHex(address)
+		}
+		pubkeys, err = db.GetValidatorPubkeysByWithdrawalCredentials(credentials, limit)
+		return pubkeys, true, err
+	case watchlistImportFeeRecipient:
+		if !utils.IsValidEth1Address(address) {
+			return nil, false, nil
+		}
+		pubkeys, err = db.GetValidatorPubkeysByFeeRecipient(common.FromHex(address), limit)
+		return pubkeys, true, err
+	}
+	return nil, false, nil
+}
+
+// UserWatchlistImport godoc
+// @Summary  adds all validators with the given withdrawal address or fee recipient to the watchlist of the user
+// @Tags User
+// @Produce  json
+// @Param source body string true "Either \"withdrawal\" to import the validators withdrawing to the address or \"fee_recipient\" to import the validators that proposed blocks for it"
+// @Param address body string true "Eth1 address, or withdrawal credentials if the source is \"withdrawal\""
+// @Success 200 {object} types.ApiResponse{data=types.ApiWatchlistImportResponse}
+// @Failure 400 {object} types.ApiResponse
+// @Failure 500 {object} types.ApiResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/user/watchlist/import [post]
+func UserWatchlistImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	pubkeys, ok, err := getWatchlistImportPubkeys(FormValueOrJSON(r, "source"), FormValueOrJSON(r, "address"), getUserPremium(r).MaxValidators)
+	if err != nil {
+		logger.Errorf("error retrieving validators to import for user %v: %v", user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve db results")
+		return
+	}
+	if !ok {
+		sendErrorResponse(w, r.URL.String(), "invalid source or address provided")
+		return
+	}
+
+	response := &types.ApiWatchlistImportResponse{Validators: make([]string, 0, len(pubkeys))}
+	if len(pubkeys) > 0 {
+		entries := make([]db.WatchlistEntry, 0, len(pubkeys))
+		for _, pubkey := range pubkeys {
+			entries = append(entries, db.WatchlistEntry{UserId: user.UserID, Validator_publickey: pubkey})
+			response.Validators = append(response.Validators, "0x"+pubkey)
+		}
+		err = db.AddToWatchlist(entries, utils.GetNetwork())
+		if err != nil {
+			logger.Errorf("error could not add validators to watchlist: %v, %v", r.URL.String(), err)
+			sendServerErrorResponse(w, r.URL.String(), "could not add validators to watchlist")
+			return
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{response})
+}
+
 // UserValidatorWatchlistRemove godoc
 // @Summary  unsubscribes a user from a specific validator
 // @Tags User
@@ -1877,7 +1948,7 @@ func internUserNotificationsSubscribe(event, filter string, threshold float64, w
 		// rocketpool thresholds are free
 	}
 
-	if filterLen == 0 && !strings.HasPrefix(string(eventName), "monitoring_") && !strings.HasPrefix(string(eventName), "rocketpool_") { // no filter = add all my watched validators
+	if filterLen == 0 && !strings.HasPrefix(string(eventName), "monitoring_") && !strings.HasPrefix(string(eventName), "rocketpool_") && eventName != types.WhaleTransferEventName && eventName != types.NetworkDeepReorgEventName { // no filter = add all my watched validators
 		myValidators, err2 := db.GetTaggedValidators(filterWatchlist)
 		if err2 != nil {
 			ErrorOrJSONResponse(w, r, "could not retrieve db results", http.StatusInternalServerError)
@@ -2038,7 +2109,7 @@ func internUserNotificationsUnsubscribe(event, filter string, w http.ResponseWri
 		Network:        utils.GetNetwork(),
 	}
 
-	if filterLen == 0 && !strings.HasPrefix(string(eventName), "monitoring_") && !strings.HasPrefix(string(eventName), "rocketpool_") { // no filter = add all my watched validators
+	if filterLen == 0 && !strings.HasPrefix(string(eventName), "monitoring_") && !strings.HasPrefix(string(eventName), "rocketpool_") && eventName != types.WhaleTransferEventName && eventName != types.NetworkDeepReorgEventName { // no filter = add all my watched validators
 
 		myValidators, err2 := db.GetTaggedValidators(filterWatchlist)
 		if err2 != nil {
@@ -2115,7 +2186,7 @@ func UserNotificationsUnsubscribe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if filterLen == 0 && !types.IsUserIndexed(eventName) { // no filter = add all my watched validators
+	if filterLen == 0 && !types.IsUserIndexed(eventName) && eventName != types.WhaleTransferEventName && eventName != types.NetworkDeepReorgEventName { // no filter = add all my watched validators
 
 		filter := db.WatchlistFilter{
 			UserId:         user.UserID,
@@ -2892,6 +2963,116 @@ func UsersDeleteWebhook(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/user/webhooks", http.StatusSeeOther)
 }
 
+// UsersAddApiWebhook adds an operational webhook (index lag, maintenance, deprecations) to the api key of a user
+func UsersAddApiWebhook(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+	redirect := "/user/settings#api"
+
+	err := r.ParseForm()
+	if err != nil {
+		utils.LogError(err, "error parsing form", 0)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	subscription, err := db.StripeGetUserSubscription(user.UserID, utils.GROUP_API)
+	if err != nil {
+		logger.WithError(err).Errorf("error retrieving api subscription of user %v", user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+	if subscription.ApiKey == nil || len(*subscription.ApiKey) == 0 {
+		utils.SetFlash(w, r, authSessionName, "Error: Please generate an API key before adding webhooks.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	urlForm := r.FormValue("url")
+	if !utils.IsValidUrl(urlForm) {
+		utils.SetFlash(w, r, authSessionName, "Error: The URL provided is invalid.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	events := pq.StringArray{}
+	for _, event := range types.ApiWebhookEvents {
+		if r.FormValue(string(event)) == "on" {
+			events = append(events, string(event))
+		}
+	}
+	if len(events) == 0 {
+		utils.SetFlash(w, r, authSessionName, "Error: Please select at least one event.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	threshold := uint64(types.DefaultApiWebhookLagThreshold)
+	if thresholdForm := r.FormValue("lag_threshold"); thresholdForm != "" {
+		threshold, err = strconv.ParseUint(thresholdForm, 10, 64)
+		if err != nil || threshold == 0 {
+			utils.SetFlash(w, r, authSessionName, "Error: The lag threshold has to be a positive number of blocks.")
+			http.Redirect(w, r, redirect, http.StatusSeeOther)
+			return
+		}
+	}
+
+	webhooks, err := db.GetUserApiWebhooks(user.UserID)
+	if err != nil {
+		logger.WithError(err).Errorf("error getting api webhooks of user %v", user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+	if len(webhooks) >= types.MaxApiWebhooks {
+		utils.SetFlash(w, r, authSessionName, fmt.Sprintf("Error: You can not add more than %v webhooks to your API key.", types.MaxApiWebhooks))
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	err = db.CreateApiWebhook(&types.ApiWebhook{
+		UserID:       user.UserID,
+		Url:          urlForm,
+		Events:       events,
+		LagThreshold: threshold,
+	})
+	if err != nil {
+		logger.WithError(err).Errorf("error adding api webhook for user %v", user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong adding your webhook, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+// UsersDeleteApiWebhook removes an operational webhook from the api key of a user
+func UsersDeleteApiWebhook(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+	redirect := "/user/settings#api"
+
+	webhookID, err := strconv.ParseUint(mux.Vars(r)["webhookID"], 10, 64)
+	if err != nil {
+		utils.SetFlash(w, r, authSessionName, "Error: Invalid webhook.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+
+	deleted, err := db.DeleteApiWebhook(user.UserID, webhookID)
+	if err != nil {
+		logger.WithError(err).Errorf("error deleting api webhook %v of user %v", webhookID, user.UserID)
+		utils.SetFlash(w, r, authSessionName, "Error: Something went wrong deleting your webhook, please try again in a bit.")
+		http.Redirect(w, r, redirect, http.StatusSeeOther)
+		return
+	}
+	if !deleted {
+		utils.SetFlash(w, r, authSessionName, "Error: Webhook not found.")
+	}
+
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
 // UsersNotificationChannel
 // Accepts form encoded values channel and active to set the global notification settings for a user
 func UsersNotificationChannels(w http.ResponseWriter, r *http.Request) {
@@ -3045,6 +3226,25 @@ func UserGlobalNotificationPost(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/user/global_notification", http.StatusSeeOther)
 }
 
+// apiRateLimits returns the daily and monthly api request limits of a stripe price, a daily limit of -1 means unlimited
+func apiRateLimits(priceID *string) (int, int) {
+	maxDaily := 10000
+	maxMonthly := 30000
+	if priceID != nil {
+		if *priceID == utils.Config.Frontend.Stripe.Sapphire {
+			maxDaily = 100000
+			maxMonthly = 500000
+		} else if *priceID == utils.Config.Frontend.Stripe.Emerald {
+			maxDaily = 200000
+			maxMonthly = 1000000
+		} else if *priceID == utils.Config.Frontend.Stripe.Diamond {
+			maxDaily = -1
+			maxMonthly = 4000000
+		}
+	}
+	return maxDaily, maxMonthly
+}
+
 // returns true if admin permissions are available, otherwise http.Error is called and false is returned
 func handleAdminPermissions(w http.ResponseWriter, r *http.Request) (bool, *types.User) {
 	user, _, err := getUserSession(r)
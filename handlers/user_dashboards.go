@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/services"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	userDashboardMaxWidgets          = 12
+	userDashboardMaxWidgetValidators = 100
+	userDashboardMaxWidgetAddresses  = 5
+	userDashboardAddressTxs          = 5
+	userDashboardBenchmarkDays       = 7
+	// widgets are placed on a 12 column grid, the height is given in rows of 100px
+	userDashboardGridColumns = 12
+	userDashboardMaxHeight   = 8
+)
+
+// UserDashboards will return the page listing the custom dashboards of the user and rendering the selected one
+func UserDashboards(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "user/dashboards.html")
+	var dashboardsTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	user := getUser(r)
+	data := InitPageData(w, r, "user", "/user/dashboards", "Custom Dashboards", templateFiles)
+
+	dashboards, err := db.GetUserDashboards(user.UserID)
+	if err != nil {
+		logger.Errorf("error retrieving dashboards of user %v: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	pageData := &types.UserDashboardsPageData{
+		Dashboards: dashboards,
+		CsrfField:  csrf.TemplateField(r),
+		Charts:     make([]string, 0, len(services.ChartHandlers)),
+	}
+	for chart := range services.ChartHandlers {
+		pageData.Charts = append(pageData.Charts, chart)
+	}
+	sort.Strings(pageData.Charts)
+
+	selected, _ := strconv.ParseUint(r.URL.Query().Get("id"), 10, 64)
+	for _, dashboard := range dashboards {
+		if dashboard.ID == selected || (selected == 0 && pageData.Selected == nil) {
+			pageData.Selected = dashboard
+		}
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "user_dashboards.go", "UserDashboards", "", dashboardsTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
+
+// UserDashboardSave creates or updates a custom dashboard of the user
+func UserDashboardSave(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	dashboard := &types.UserDashboard{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(dashboard)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "could not parse request")
+		return
+	}
+
+	err = validateUserDashboard(dashboard)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	id, err := db.SaveUserDashboard(user.UserID, dashboard)
+	if err != nil {
+		if errors.Is(err, db.ErrUserDashboardLimit) {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("you can not create more than %v dashboards", db.MaxUserDashboards))
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			sendErrorResponse(w, r.URL.String(), "dashboard not found")
+			return
+		}
+		logger.Errorf("error saving dashboard of user %v: %v", user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not save dashboard")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{map[string]uint64{"id": id}})
+}
+
+// validateUserDashboard checks the name and widgets of a dashboard and normalizes the widget positions
+func validateUserDashboard(dashboard *types.UserDashboard) error {
+	dashboard.Name = strings.TrimSpace(dashboard.Name)
+	if dashboard.Name == "" || len(dashboard.Name) > 100 {
+		return fmt.Errorf("the dashboard name must contain between 1 and 100 characters")
+	}
+	if len(dashboard.Layout) > userDashboardMaxWidgets {
+		return fmt.Errorf("a dashboard can contain at most %v widgets", userDashboardMaxWidgets)
+	}
+
+	for _, widget := range dashboard.Layout {
+		if widget == nil {
+			return fmt.Errorf("invalid widget")
+		}
+		if len(widget.Title) > 100 {
+			return fmt.Errorf("widget titles can contain at most 100 characters")
+		}
+
+		switch widget.Type {
+		case types.DashboardWidgetValidators, types.DashboardWidgetBenchmark:
+			if len(widget.Validators) == 0 || len(widget.Validators) > userDashboardMaxWidgetValidators {
+				return fmt.Errorf("a validators widget must contain between 1 and %v validators", userDashboardMaxWidgetValidators)
+			}
+		case types.DashboardWidgetAddresses:
+			if len(widget.Addresses) == 0 || len(widget.Addresses) > userDashboardMaxWidgetAddresses {
+				return fmt.Errorf("an address widget must contain between 1 and %v addresses", userDashboardMaxWidgetAddresses)
+			}
+			for i, address := range widget.Addresses {
+				address = strings.ToLower(strings.TrimPrefix(address, "0x"))
+				if !utils.IsEth1Address(address) {
+					return fmt.Errorf("invalid address %v", widget.Addresses[i])
+				}
+				widget.Addresses[i] = address
+			}
+		case types.DashboardWidgetGasOracle:
+		case types.DashboardWidgetChart:
+			if _, ok := services.ChartHandlers[widget.Chart]; !ok {
+				return fmt.Errorf("invalid chart %v", widget.Chart)
+			}
+		default:
+			return fmt.Errorf("invalid widget type %v", widget.Type)
+		}
+
+		widget.Width = clampInt(widget.Width, 1, userDashboardGridColumns)
+		widget.Height = clampInt(widget.Height, 1, userDashboardMaxHeight)
+		widget.X = clampInt(widget.X, 0, userDashboardGridColumns-widget.Width)
+		if widget.Y < 0 {
+			widget.Y = 0
+		}
+	}
+
+	sort.SliceStable(dashboard.Layout, func(i, j int) bool {
+		if dashboard.Layout[i].Y == dashboard.Layout[j].Y {
+			return dashboard.Layout[i].X < dashboard.Layout[j].X
+		}
+		return dashboard.Layout[i].Y < dashboard.Layout[j].Y
+	})
+	return nil
+}
+
+// UserDashboardDelete removes a custom dashboard of the user
+func UserDashboardDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "invalid dashboard id")
+		return
+	}
+
+	err = db.DeleteUserDashboard(user.UserID, id)
+	if err != nil {
+		logger.Errorf("error deleting dashboard %v of user %v: %v", id, user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not delete dashboard")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), nil)
+}
+
+// UserDashboardData returns the hydrated data of all widgets of a custom dashboard
+func UserDashboardData(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "invalid dashboard id")
+		return
+	}
+
+	dashboard, err := db.GetUserDashboard(user.UserID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			sendErrorResponse(w, r.URL.String(), "dashboard not found")
+			return
+		}
+		logger.Errorf("error retrieving dashboard %v of user %v: %v", id, user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve dashboard")
+		return
+	}
+
+	widgets := make([]*types.UserDashboardWidgetData, len(dashboard.Layout))
+	for i, widget := range dashboard.Layout {
+		widgets[i] = &types.UserDashboardWidgetData{
+			Type:  widget.Type,
+			Title: widget.Title,
+		}
+		widgets[i].Data, err = getUserDashboardWidgetData(r.Context(), widget)
+		if err != nil {
+			logger.Errorf("error hydrating %v widget of dashboard %v: %v", widget.Type, id, err)
+			widgets[i].Error = "widget data is currently not available"
+		}
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{widgets})
+}
+
+func getUserDashboardWidgetData(ctx context.Context, widget *types.UserDashboardWidget) (interface{}, error) {
+	switch widget.Type {
+	case types.DashboardWidgetValidators:
+		return db.GetValidatorsSummary(widget.Validators)
+	case types.DashboardWidgetAddresses:
+		return getUserDashboardAddressActivity(ctx, widget.Addresses)
+	case types.DashboardWidgetBenchmark:
+		return services.GetValidatorBenchmark(widget.Validators, userDashboardBenchmarkDays)
+	case types.DashboardWidgetGasOracle:
+		gasNow := services.LatestGasNowData()
+		if gasNow == nil {
+			return nil, fmt.Errorf("gas now data is not available")
+		}
+		return gasNow.Data, nil
+	case types.DashboardWidgetChart:
+		for _, chart := range services.LatestChartsPageData() {
+			if chart.Path == widget.Chart {
+				return chart.Data, nil
+			}
+		}
+		return nil, fmt.Errorf("chart %v is not available", widget.Chart)
+	}
+	return nil, fmt.Errorf("unknown widget type %v", widget.Type)
+}
+
+func getUserDashboardAddressActivity(ctx context.Context, addresses []string) ([]*types.UserDashboardAddressActivity, error) {
+	activities := make([]*types.UserDashboardAddressActivity, 0, len(addresses))
+	for _, address := range addresses {
+		addressBytes := common.FromHex(address)
+		metadata, err := db.GetEth1Store().GetMetadataForAddress(ctx, addressBytes)
+		if err != nil {
+			return nil, err
+		}
+		transactions, err := db.BigtableClient.GetRecentEth1TxForAddress(ctx, addressBytes, userDashboardAddressTxs)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := &types.UserDashboardAddressActivity{
+			Address:      utils.FixAddressCasing(address),
+			Name:         metadata.Name,
+			Balance:      "0",
+			Transactions: make([]*types.UserDashboardAddressActivityTx, 0, len(transactions)),
+		}
+		if metadata.EthBalance != nil {
+			activity.Balance = decimal.NewFromBigInt(new(big.Int).SetBytes(metadata.EthBalance.Balance), -18).String()
+		}
+		for _, tx := range transactions {
+			activity.Transactions = append(activity.Transactions, &types.UserDashboardAddressActivityTx{
+				Hash:        fmt.Sprintf("0x%x", tx.Hash),
+				BlockNumber: tx.BlockNumber,
+				Time:        tx.Time.AsTime(),
+				From:        utils.FixAddressCasing(fmt.Sprintf("%x", tx.From)),
+				To:          utils.FixAddressCasing(fmt.Sprintf("%x", tx.To)),
+				Value:       decimal.NewFromBigInt(new(big.Int).SetBytes(tx.Value), -18).String(),
+				Method:      db.GetEth1Store().GetMethodLabel(ctx, tx.MethodId, tx.InvokesContract),
+			})
+		}
+		activities = append(activities, activity)
+	}
+	return activities, nil
+}
+
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
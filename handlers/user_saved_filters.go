@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/csrf"
+	"github.com/gorilla/mux"
+)
+
+// savedFilterParams lists the query parameters a saved filter of each table may contain together with their validation
+var savedFilterParams = map[types.SavedFilterTable]map[string]func(string) bool{
+	types.SavedFilterAddressTransactions: {
+		"direction": func(v string) bool {
+			_, err := db.TxDirectionFilter(v)
+			return err == nil
+		},
+	},
+	types.SavedFilterValidators: {
+		"search[value]": func(v string) bool {
+			return len(v) <= 128
+		},
+		"filterByState": func(v string) bool {
+			_, ok := validatorsStateFilters[v]
+			return ok
+		},
+		"order[0][column]": func(v string) bool {
+			_, ok := validatorsOrderColumns[v]
+			return ok
+		},
+		"order[0][dir]": func(v string) bool {
+			return v == "asc" || v == "desc"
+		},
+	},
+}
+
+// UserSavedFilters returns the saved filters of the user for the table given by the table parameter. The response carries the csrf
+// token for saving and deleting filters, as the pages of the tables are not rendered for the user.
+func UserSavedFilters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	table := types.SavedFilterTable(r.URL.Query().Get("table"))
+	if _, ok := savedFilterParams[table]; !ok {
+		sendErrorResponse(w, r.URL.String(), "invalid table")
+		return
+	}
+
+	filters, err := db.GetUserSavedFilters(user.UserID, table)
+	if err != nil {
+		logger.Errorf("error retrieving saved filters of user %v: %v", user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not retrieve saved filters")
+		return
+	}
+
+	w.Header().Set("X-CSRF-Token", csrf.Token(r))
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{filters})
+}
+
+// UserSavedFilterSave saves a filter of the user, a filter with the same name for the same table is replaced
+func UserSavedFilterSave(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	filter := &types.UserSavedFilter{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 16*1024)).Decode(filter)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "could not parse request")
+		return
+	}
+
+	err = validateUserSavedFilter(filter)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), err.Error())
+		return
+	}
+
+	id, err := db.SaveUserSavedFilter(user.UserID, filter)
+	if err != nil {
+		if errors.Is(err, db.ErrUserSavedFilterLimit) {
+			sendErrorResponse(w, r.URL.String(), fmt.Sprintf("you can not save more than %v filters per table", db.MaxUserSavedFilters))
+			return
+		}
+		logger.Errorf("error saving filter of user %v: %v", user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not save filter")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), []interface{}{map[string]uint64{"id": id}})
+}
+
+// validateUserSavedFilter checks the name of a filter and that it only contains valid parameters of its table, empty parameters are dropped
+func validateUserSavedFilter(filter *types.UserSavedFilter) error {
+	params, ok := savedFilterParams[filter.Table]
+	if !ok {
+		return fmt.Errorf("invalid table %v", filter.Table)
+	}
+	filter.Name = strings.TrimSpace(filter.Name)
+	if filter.Name == "" || len(filter.Name) > 100 {
+		return fmt.Errorf("the filter name must contain between 1 and 100 characters")
+	}
+
+	for param, value := range filter.Params {
+		valid, ok := params[param]
+		if !ok {
+			return fmt.Errorf("invalid filter parameter %v", param)
+		}
+		if value == "" {
+			delete(filter.Params, param)
+			continue
+		}
+		if !valid(value) {
+			return fmt.Errorf("invalid value %q of filter parameter %v", value, param)
+		}
+	}
+	if len(filter.Params) == 0 {
+		return fmt.Errorf("the filter does not contain any parameters")
+	}
+	return nil
+}
+
+// UserSavedFilterDelete removes a saved filter of the user
+func UserSavedFilterDelete(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	user := getUser(r)
+
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		sendErrorResponse(w, r.URL.String(), "invalid filter id")
+		return
+	}
+
+	err = db.DeleteUserSavedFilter(user.UserID, id)
+	if err != nil {
+		logger.Errorf("error deleting saved filter %v of user %v: %v", id, user.UserID, err)
+		sendServerErrorResponse(w, r.URL.String(), "could not delete filter")
+		return
+	}
+
+	sendOKResponse(json.NewEncoder(w), r.URL.String(), nil)
+}
@@ -374,7 +374,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			timings.Charts = time.Since(start)
 		}()
 
-		validatorPageData.IncomeHistoryChartData, validatorPageData.IncomeToday.Cl, err = db.GetValidatorIncomeHistoryChart([]uint64{index}, currency)
+		validatorPageData.IncomeHistoryChartData, validatorPageData.IncomeToday.Cl, err = db.GetValidatorIncomeHistoryChart(r.Context(), []uint64{index}, currency)
 
 		if err != nil {
 			return fmt.Errorf("error calling db.GetValidatorIncomeHistoryChart: %v", err)
@@ -387,7 +387,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			timings.Charts = time.Since(start)
 		}()
-		validatorPageData.ExecutionIncomeHistoryData, err = getExecutionChartData([]uint64{index}, currency)
+		validatorPageData.ExecutionIncomeHistoryData, err = getExecutionChartData(r.Context(), []uint64{index}, currency)
 
 		if err != nil {
 			return fmt.Errorf("error calling getExecutionChartData: %v", err)
@@ -401,7 +401,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			timings.Earnings = time.Since(start)
 		}()
-		earnings, balances, err := GetValidatorEarnings([]uint64{index}, GetCurrency(r))
+		earnings, balances, err := GetValidatorEarnings(r.Context(), []uint64{index}, GetCurrency(r))
 		if err != nil {
 			return fmt.Errorf("error retrieving validator earnings: %v", err)
 		}
@@ -445,6 +445,11 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			}
 			validatorPageData.BLSChange = blsChange
 
+			validatorPageData.CredentialRotations, err = db.GetValidatorCredentialRotations(validatorPageData.Index)
+			if err != nil {
+				return fmt.Errorf("error getting validator credential rotations from db: %v", err)
+			}
+
 			if bytes.Equal(validatorPageData.WithdrawCredentials[:1], []byte{0x00}) && blsChange != nil {
 				// blsChanges are only possible afters cappeala
 				validatorPageData.IsWithdrawableAddress = true
@@ -637,7 +642,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 
 		if len(proposedToday) > 0 {
 			// get el data
-			execBlocks, err := db.BigtableClient.GetBlocksIndexedMultiple(proposedToday, 10000)
+			execBlocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(r.Context(), proposedToday, 10000)
 			if err != nil {
 				return fmt.Errorf("error retrieving execution blocks data from bigtable: %v", err)
 			}
@@ -686,7 +691,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			lookback := int64(lastFinalizedEpoch - (lastStatsDay+1)*utils.EpochsPerDay())
 			if lookback > 0 {
 				// logger.Infof("retrieving attestations not yet in stats, lookback is %v", lookback)
-				missedAttestations, err := db.BigtableClient.GetValidatorMissedAttestationHistory([]uint64{index}, lastFinalizedEpoch-uint64(lookback), lastFinalizedEpoch)
+				missedAttestations, err := db.BigtableClient.GetValidatorMissedAttestationHistory(r.Context(), []uint64{index}, lastFinalizedEpoch-uint64(lookback), lastFinalizedEpoch)
 				if err != nil {
 					return fmt.Errorf("error retrieving validator attestations not in stats from bigtable: %v", err)
 				}
@@ -735,7 +740,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 	})
 
 	g.Go(func() error {
-		eff, err := db.BigtableClient.GetValidatorEffectiveness([]uint64{index}, validatorPageData.Epoch-1)
+		eff, err := db.BigtableClient.GetValidatorEffectiveness(r.Context(), []uint64{index}, validatorPageData.Epoch-1)
 		if err != nil {
 			return fmt.Errorf("error retrieving validator effectiveness: %v", err)
 		}
@@ -797,7 +802,7 @@ func Validator(w http.ResponseWriter, r *http.Request) {
 			lastExportedEpoch := (lastStatsDay+1)*utils.EpochsPerDay() - 1
 			if actualSyncPeriods[0].LastEpoch > lastExportedEpoch {
 				lookback := int64(latestEpoch - lastExportedEpoch)
-				syncStatsBt, err := db.BigtableClient.GetValidatorSyncCommitteesStats([]uint64{index}, latestEpoch-uint64(lookback), latestEpoch)
+				syncStatsBt, err := db.BigtableClient.GetValidatorSyncCommitteesStats(r.Context(), []uint64{index}, latestEpoch-uint64(lookback), latestEpoch)
 				if err != nil {
 					return fmt.Errorf("error retrieving validator sync participations data from bigtable: %v", err)
 				}
@@ -972,7 +977,7 @@ func ValidatorAttestationInclusionEffectiveness(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	eff, err := db.BigtableClient.GetValidatorEffectiveness([]uint64{index}, services.LatestEpoch()-1)
+	eff, err := db.BigtableClient.GetValidatorEffectiveness(r.Context(), []uint64{index}, services.LatestEpoch()-1)
 	if err != nil {
 		logger.Errorf("error retrieving validator effectiveness: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -1182,7 +1187,7 @@ func ValidatorAttestations(w http.ResponseWriter, r *http.Request) {
 	tableData := [][]interface{}{}
 
 	if totalCount > 0 {
-		attestationData, err := db.BigtableClient.GetValidatorAttestationHistory([]uint64{index}, uint64(int64(lastAttestationEpoch)-start)-uint64(length), uint64(int64(lastAttestationEpoch)-start))
+		attestationData, err := db.BigtableClient.GetValidatorAttestationHistory(r.Context(), []uint64{index}, uint64(int64(lastAttestationEpoch)-start)-uint64(length), uint64(int64(lastAttestationEpoch)-start))
 		if err != nil {
 			logger.Errorf("error retrieving validator attestations data: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -1669,7 +1674,7 @@ func ValidatorHistory(w http.ResponseWriter, r *http.Request) {
 	var incomeDetails map[uint64]map[uint64]*itypes.ValidatorEpochIncome
 	g.Go(func() error {
 		var err error
-		incomeDetails, err = db.BigtableClient.GetValidatorIncomeDetailsHistory([]uint64{index}, startEpoch, endEpoch)
+		incomeDetails, err = db.BigtableClient.GetValidatorIncomeDetailsHistory(r.Context(), []uint64{index}, startEpoch, endEpoch)
 		if err != nil {
 			logger.Errorf("error retrieving validator income details history from bigtable: %v", err)
 			return err
@@ -2002,7 +2007,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 
 		// retrieve sync duties from bigtable
 		// note that the limit may be negative for either call, which results in the function fetching epochs for the absolute limit value in ascending ordering
-		syncDuties, err := db.BigtableClient.GetValidatorSyncDutiesHistoryOrdered(validatorIndex, firstShownEpoch-limit, firstShownEpoch, ascOrdering)
+		syncDuties, err := db.BigtableClient.GetValidatorSyncDutiesHistoryOrdered(r.Context(), validatorIndex, firstShownEpoch-limit, firstShownEpoch, ascOrdering)
 		if err != nil {
 			logger.Errorf("error retrieving validator sync duty data from bigtable: %v", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -2010,7 +2015,7 @@ func ValidatorSync(w http.ResponseWriter, r *http.Request) {
 		}
 
 		if nextPeriodLimit != 0 {
-			nextPeriodSyncDuties, err := db.BigtableClient.GetValidatorSyncDutiesHistoryOrdered(validatorIndex, lastShownEpoch-uint64(nextPeriodLimit), lastShownEpoch, ascOrdering)
+			nextPeriodSyncDuties, err := db.BigtableClient.GetValidatorSyncDutiesHistoryOrdered(r.Context(), validatorIndex, lastShownEpoch-uint64(nextPeriodLimit), lastShownEpoch, ascOrdering)
 			if err != nil {
 				logger.Errorf("error retrieving second validator sync duty data from bigtable: %v", err)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
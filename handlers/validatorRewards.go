@@ -136,7 +136,7 @@ func RewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	data := services.GetValidatorHist(validatorArr, currency, start, end)
+	data := services.GetValidatorHist(r.Context(), validatorArr, currency, start, end)
 
 	err = json.NewEncoder(w).Encode(data)
 	if err != nil {
@@ -177,7 +177,7 @@ func DownloadRewardsHistoricalData(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	hist := services.GetValidatorHist(validatorArr, currency, start, end)
+	hist := services.GetValidatorHist(r.Context(), validatorArr, currency, start, end)
 
 	if len(hist.History) == 0 {
 		w.Write([]byte("No data available"))
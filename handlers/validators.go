@@ -98,6 +98,36 @@ type ValidatorsDataQueryParams struct {
 var searchPubkeyExactRE = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{96}`)  // only search for pubkeys if string consists of 96 hex-chars
 var searchPubkeyLikeRE = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{2,96}`) // only search for pubkeys if string consists of 96 hex-chars
 
+// validatorsStateFilters maps the filterByState parameter of the validators table to the condition of the query
+var validatorsStateFilters = map[string]string{
+	"pending":          "WHERE validators.status = 'pending'",
+	"active":           "WHERE validators.status LIKE 'active%'",
+	"active_online":    "WHERE validators.status = 'active_online'",
+	"active_offline":   "WHERE validators.status = 'active_offline'",
+	"slashing":         "WHERE validators.status LIKE 'slashing%'",
+	"slashing_online":  "WHERE validators.status = 'slashing_online'",
+	"slashing_offline": "WHERE validators.status = 'slashing_offline'",
+	"slashed":          "WHERE validators.status = 'slashed'",
+	"exiting":          "WHERE validators.status LIKE 'exiting%'",
+	"exiting_online":   "WHERE validators.status = 'exiting_online'",
+	"exiting_offline":  "WHERE validators.status = 'exiting_offline'",
+	"exited":           "WHERE (validators.status = 'exited' OR validators.status = 'slashed')",
+	"voluntary":        "WHERE validators.status = 'exited'",
+	"deposited":        "WHERE validators.status = 'deposited'",
+}
+
+// validatorsOrderColumns maps the column index of the validators table to the column it is ordered by
+var validatorsOrderColumns = map[string]string{
+	"0": "pubkey",
+	"1": "validatorindex",
+	"3": "state",
+	"4": "activationepoch",
+	"5": "exitepoch",
+	"6": "withdrawableepoch",
+	"7": "lastattestationslot",
+	"8": "slashed",
+}
+
 func parseValidatorsDataQueryParams(r *http.Request) (*ValidatorsDataQueryParams, error) {
 	q := r.URL.Query()
 
@@ -126,53 +156,9 @@ func parseValidatorsDataQueryParams(r *http.Request) (*ValidatorsDataQueryParams
 		searchPubkeyLike = &pubkey
 	}
 
-	filterByState := q.Get("filterByState")
-	var qryStateFilter string
-	switch filterByState {
-	case "pending":
-		qryStateFilter = "WHERE validators.status = 'pending'"
-	case "active":
-		qryStateFilter = "WHERE validators.status LIKE 'active%'"
-	case "active_online":
-		qryStateFilter = "WHERE validators.status = 'active_online'"
-	case "active_offline":
-		qryStateFilter = "WHERE validators.status = 'active_offline'"
-	case "slashing":
-		qryStateFilter = "WHERE validators.status LIKE 'slashing%'"
-	case "slashing_online":
-		qryStateFilter = "WHERE validators.status = 'slashing_online'"
-	case "slashing_offline":
-		qryStateFilter = "WHERE validators.status = 'slashing_offline'"
-	case "slashed":
-		qryStateFilter = "WHERE validators.status = 'slashed'"
-	case "exiting":
-		qryStateFilter = "WHERE validators.status LIKE 'exiting%'"
-	case "exiting_online":
-		qryStateFilter = "WHERE validators.status = 'exiting_online'"
-	case "exiting_offline":
-		qryStateFilter = "WHERE validators.status = 'exiting_offline'"
-	case "exited":
-		qryStateFilter = "WHERE (validators.status = 'exited' OR validators.status = 'slashed')"
-	case "voluntary":
-		qryStateFilter = "WHERE validators.status = 'exited'"
-	case "deposited":
-		qryStateFilter = "WHERE validators.status = 'deposited'"
-	default:
-		qryStateFilter = ""
-	}
+	qryStateFilter := validatorsStateFilters[q.Get("filterByState")]
 
-	orderColumn := q.Get("order[0][column]")
-	orderByMap := map[string]string{
-		"0": "pubkey",
-		"1": "validatorindex",
-		"3": "state",
-		"4": "activationepoch",
-		"5": "exitepoch",
-		"6": "withdrawableepoch",
-		"7": "lastattestationslot",
-		"8": "slashed",
-	}
-	orderBy, exists := orderByMap[orderColumn]
+	orderBy, exists := validatorsOrderColumns[q.Get("order[0][column]")]
 	if !exists {
 		orderBy = "validatorindex"
 	}
@@ -265,7 +251,7 @@ func ValidatorsData(w http.ResponseWriter, r *http.Request) {
 	for i, validator := range validators {
 		indices[i] = validator.ValidatorIndex
 	}
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(indices, services.LatestEpoch(), services.LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(r.Context(), indices, services.LatestEpoch(), services.LatestEpoch())
 	if err != nil {
 		logger.WithError(err).WithField("route", r.URL.String()).Errorf("error retrieving validator balance data")
 		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
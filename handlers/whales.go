@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/templates"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/csrf"
+)
+
+// Whales will return the whale watch page listing the large transfers of the last day
+func Whales(w http.ResponseWriter, r *http.Request) {
+	templateFiles := append(layoutTemplateFiles, "whales.html")
+	var whalesTemplate = templates.GetTemplate(templateFiles...)
+
+	w.Header().Set("Content-Type", "text/html")
+	data := InitPageData(w, r, "blockchain", "/whales", "Whale Watch", templateFiles)
+
+	transfers, err := db.BigtableClient.GetWhaleTransfers(r.Context(), time.Now().Add(-time.Hour*24), 100)
+	if err != nil {
+		logger.Errorf("error retrieving whale transfers: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	names := make(map[string]string)
+	tokens := make(map[string]*types.ERC20Metadata)
+	for _, t := range transfers {
+		names[string(t.From)] = ""
+		names[string(t.To)] = ""
+		if len(t.TokenAddress) > 0 {
+			tokens[string(t.TokenAddress)] = nil
+		}
+	}
+	names, tokens, err = db.GetEth1Store().GetAddressesNamesArMetadata(r.Context(), &names, &tokens)
+	if err != nil {
+		logger.Errorf("error retrieving names and token metadata of whale transfers: %v", err)
+		http.Error(w, "Internal server error", http.StatusServiceUnavailable)
+		return
+	}
+
+	pageData := &types.WhaleWatchPageData{
+		EtherThreshold: utils.Config.WhaleAlerts.EtherThreshold,
+		Transfers:      make([]*types.WhaleTransfer, 0, len(transfers)),
+	}
+	for _, t := range transfers {
+		transfer := &types.WhaleTransfer{
+			TxHash:      utils.FormatTransactionHash(t.ParentHash),
+			BlockNumber: t.BlockNumber,
+			Time:        t.Time.AsTime(),
+			From:        utils.FormatAddress(t.From, nil, names[string(t.From)], false, false, true),
+			To:          utils.FormatAddress(t.To, nil, names[string(t.To)], false, false, true),
+			Amount:      utils.FormatBytesAmount(t.Value, "ETH", 2),
+			Token:       template.HTML("Ether"),
+		}
+		if metadata := tokens[string(t.TokenAddress)]; len(t.TokenAddress) > 0 && metadata != nil {
+			balance := &types.Eth1AddressBalance{Token: t.TokenAddress, Balance: t.Value, Metadata: metadata}
+			transfer.Amount = utils.FormatTokenValue(balance)
+			transfer.Token = template.HTML(fmt.Sprintf(`<a href="/token/0x%x" title="%s">%s</a>`, t.TokenAddress, utils.FormatTokenSymbolTitle(metadata.Symbol), utils.FormatTokenSymbol(metadata.Symbol)))
+		}
+		pageData.Transfers = append(pageData.Transfers, transfer)
+	}
+	if data.User.Authenticated {
+		pageData.CsrfField = csrf.TemplateField(r)
+		var thresholds []float64
+		err = db.FrontendWriterDB.Select(&thresholds, `
+			select coalesce(event_threshold, 0)
+			from users_subscriptions
+			where user_id = $1 and event_name = $2`, data.User.UserID, utils.GetNetwork()+":"+string(types.WhaleTransferEventName))
+		if err != nil {
+			logger.Errorf("error getting user subscriptions: %v route: %v", r.URL.String(), err)
+		}
+		if len(thresholds) > 0 {
+			pageData.Subscribed = true
+			pageData.SubscriptionThreshold = thresholds[0]
+		}
+	}
+	data.Data = pageData
+
+	if handleTemplateError(w, r, "whales.go", "Whales", "", whalesTemplate.ExecuteTemplate(w, "layout", data)) != nil {
+		return // an error has occurred and was processed
+	}
+}
@@ -1,9 +1,12 @@
 package metrics
 
 import (
+	"bufio"
 	"database/sql"
 	"eth2-exporter/utils"
 	"eth2-exporter/version"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/pprof"
 	"regexp"
@@ -65,6 +68,78 @@ var (
 		Name: "notifications_sent",
 		Help: "Counter of notifications sent with the channel and notification type in the label",
 	}, []string{"channel", "status"})
+	BigtableCorruptedRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bigtable_corrupted_rows",
+		Help: "Counter of bigtable rows that could not be decoded and were skipped while reading, with the row type in the label",
+	}, []string{"type"})
+	BigtableMissingRows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bigtable_missing_rows",
+		Help: "Counter of bigtable data rows referenced by an index that do not exist, with the row type in the label",
+	}, []string{"type"})
+	BigtableTombstonedRows = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_tombstoned_rows",
+		Help: "Counter of data rows of orphaned blocks that have been marked with a tombstone",
+	})
+	BigtableInvariantViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bigtable_invariant_violations",
+		Help: "Counter of transformer invariant violations that prevented the mutations of a block from being written, with the violated invariant in the label",
+	}, []string{"invariant"})
+	BigtableWriteRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_write_retries",
+		Help: "Counter of bulk mutations that failed with a transient error and were retried",
+	})
+	BigtableWriteFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_write_failures",
+		Help: "Counter of bulk mutations that could not be written",
+	})
+	BigtableReadRetries = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_read_retries",
+		Help: "Counter of bigtable reads that failed with a transient error and were retried",
+	})
+	BigtableReadBreakerOpened = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_read_breaker_opened",
+		Help: "Counter of how often bigtable reads were suspended after repeated failures",
+	})
+	BigtableTruncatedScans = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bigtable_truncated_scans",
+		Help: "Counter of interactive index scans that ran out of their latency budget and returned the rows read so far",
+	})
+	BigtableCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bigtable_call_duration",
+		Help:    "Duration of bigtable calls in seconds by operation and the method that issued the call",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"operation", "method"})
+	BigtableCallRows = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bigtable_call_rows",
+		Help:    "Number of rows read or written per bigtable call by operation and the method that issued the call",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"operation", "method"})
+	BigtableCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bigtable_call_errors",
+		Help: "Counter of failed bigtable calls by operation and the method that issued the call",
+	}, []string{"operation", "method"})
+	TransformerPluginDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "transformer_plugin_duration",
+		Help:    "Duration of the transformer of an eth1 indexer plugin per block in seconds, with the plugin in the label",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"plugin"})
+	TransformerPluginMutations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transformer_plugin_mutations",
+		Help: "Counter of the mutations generated by the transformer of an eth1 indexer plugin, with the plugin in the label",
+	}, []string{"plugin"})
+	TransformerPluginErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transformer_plugin_errors",
+		Help: "Counter of blocks the transformer of an eth1 indexer plugin failed for, with the plugin in the label",
+	}, []string{"plugin"})
+	Eth1StreamConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "eth1_stream_connections",
+		Help: "Number of open websocket connections subscribed to the eth1 stream",
+	})
+	SyntheticProbeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "synthetic_probe_duration",
+		Help:    "Duration of the synthetic monitoring probes in seconds by probe and result",
+		Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 20},
+	}, []string{"probe", "result"})
 )
 
 var logger = logrus.New().WithField("module", "metrics")
@@ -139,6 +214,16 @@ func (r *responseWriterDelegator) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// Hijack allows websocket connections to be upgraded behind the middleware
+func (r *responseWriterDelegator) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	return hj.Hijack()
+}
+
 // Serve serves prometheus metrics on the given address under /metrics
 func Serve(addr string) error {
 	router := http.NewServeMux()
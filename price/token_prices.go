@@ -0,0 +1,221 @@
+package price
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	coingeckoTokenPriceBatchSize = 100
+	// the time weighted average price of the uniswap pools is taken over this period, a pool has to keep enough observations
+	// to cover it
+	uniswapTWAPPeriod = 30 * 60
+)
+
+var (
+	uniswapV3Factory  = common.HexToAddress("0x1F98431c8aD98523631AE4a59f267346ea31F984")
+	uniswapV3FeeTiers = []int64{500, 3000, 10000}
+	wethAddress       = common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	usdcAddress       = common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	usdcDecimals      = uint8(6)
+
+	uniswapV3ABI = mustParseABI(`[
+		{"name":"getPool","type":"function","stateMutability":"view","inputs":[{"name":"tokenA","type":"address"},{"name":"tokenB","type":"address"},{"name":"fee","type":"uint24"}],"outputs":[{"name":"pool","type":"address"}]},
+		{"name":"token0","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+		{"name":"liquidity","type":"function","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint128"}]},
+		{"name":"observe","type":"function","stateMutability":"view","inputs":[{"name":"secondsAgos","type":"uint32[]"}],"outputs":[{"name":"tickCumulatives","type":"int56[]"},{"name":"secondsPerLiquidityCumulativeX128s","type":"uint160[]"}]}
+	]`)
+
+	// the pools of a token pair do not change once created, so they are only looked up once
+	uniswapPools    = map[[2]common.Address][]common.Address{}
+	uniswapPoolsMux = &sync.Mutex{}
+)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// FetchTokenPrices returns the USD prices of the given mainnet tokens, the values of the map are the decimals of the tokens.
+// Prices are taken from CoinGecko, tokens CoinGecko has no price for are priced with the time weighted average price of their
+// most liquid Uniswap V3 pool with WETH. Tokens without a price in either source are omitted from the result.
+func FetchTokenPrices(ctx context.Context, caller bind.ContractCaller, tokens map[common.Address]uint8) (map[common.Address]decimal.Decimal, error) {
+	addresses := make([]common.Address, 0, len(tokens))
+	for token := range tokens {
+		addresses = append(addresses, token)
+	}
+
+	prices, err := fetchCoingeckoTokenPrices(ctx, addresses)
+	if err != nil {
+		logger.Warnf("error fetching token prices from coingecko, falling back to uniswap: %v", err)
+		prices = make(map[common.Address]decimal.Decimal, len(tokens))
+	}
+	if caller == nil || len(prices) == len(tokens) {
+		return prices, nil
+	}
+
+	wethPrice, err := uniswapTWAP(ctx, caller, wethAddress, 18, usdcAddress, usdcDecimals)
+	if err != nil {
+		return prices, fmt.Errorf("error getting the uniswap price of WETH: %w", err)
+	}
+	for _, token := range addresses {
+		if _, ok := prices[token]; ok {
+			continue
+		}
+		if token == wethAddress {
+			prices[token] = wethPrice
+			continue
+		}
+		price, err := uniswapTWAP(ctx, caller, token, tokens[token], wethAddress, 18)
+		if err != nil {
+			logger.Debugf("no uniswap price for token %v: %v", token, err)
+			continue
+		}
+		prices[token] = price.Mul(wethPrice)
+	}
+	return prices, nil
+}
+
+func fetchCoingeckoTokenPrices(ctx context.Context, tokens []common.Address) (map[common.Address]decimal.Decimal, error) {
+	client := &http.Client{Timeout: time.Second * 10}
+	prices := make(map[common.Address]decimal.Decimal, len(tokens))
+
+	for start := 0; start < len(tokens); start += coingeckoTokenPriceBatchSize {
+		end := start + coingeckoTokenPriceBatchSize
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		addresses := make([]string, 0, end-start)
+		for _, token := range tokens[start:end] {
+			addresses = append(addresses, strings.ToLower(token.Hex()))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://api.coingecko.com/api/v3/simple/token_price/ethereum?contract_addresses=%s&vs_currencies=usd", strings.Join(addresses, ",")), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("error querying coingecko api: %v", resp.Status)
+		}
+
+		res := map[string]struct {
+			USD *decimal.Decimal `json:"usd"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for address, price := range res {
+			if price.USD == nil || !price.USD.IsPositive() {
+				continue
+			}
+			prices[common.HexToAddress(address)] = *price.USD
+		}
+	}
+	return prices, nil
+}
+
+// uniswapTWAP returns the price of a token in units of the quote token, averaged over the last uniswapTWAPPeriod seconds of the
+// most liquid pool of the pair that keeps enough observations
+func uniswapTWAP(ctx context.Context, caller bind.ContractCaller, token common.Address, tokenDecimals uint8, quote common.Address, quoteDecimals uint8) (decimal.Decimal, error) {
+	opts := &bind.CallOpts{Context: ctx}
+	pools, err := uniswapPoolsOf(opts, caller, token, quote)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	for _, pool := range pools {
+		contract := bind.NewBoundContract(pool, uniswapV3ABI, caller, nil, nil)
+		out := []interface{}{}
+		err := contract.Call(opts, &out, "observe", []uint32{uniswapTWAPPeriod, 0})
+		if err != nil {
+			// the pool does not have observations for the whole period
+			continue
+		}
+		tickCumulatives := out[0].([]*big.Int)
+		delta := new(big.Int).Sub(tickCumulatives[1], tickCumulatives[0])
+		tick, rem := new(big.Int).QuoRem(delta, big.NewInt(uniswapTWAPPeriod), new(big.Int))
+		// round towards negative infinity like the oracle library of uniswap
+		if rem.Sign() < 0 {
+			tick.Sub(tick, big.NewInt(1))
+		}
+
+		out = []interface{}{}
+		err = contract.Call(opts, &out, "token0")
+		if err != nil {
+			return decimal.Zero, err
+		}
+		// the tick is the price of token0 in units of token1
+		price := math.Pow(1.0001, float64(tick.Int64()))
+		if out[0].(common.Address) != token {
+			price = 1 / price
+		}
+		return decimal.NewFromFloat(price).Shift(int32(tokenDecimals) - int32(quoteDecimals)), nil
+	}
+	return decimal.Zero, fmt.Errorf("no uniswap pool of %v and %v with observations over the last %v seconds", token, quote, uniswapTWAPPeriod)
+}
+
+// uniswapPoolsOf returns the uniswap v3 pools of a token pair that hold liquidity ordered by their liquidity, most liquid first
+func uniswapPoolsOf(opts *bind.CallOpts, caller bind.ContractCaller, token, quote common.Address) ([]common.Address, error) {
+	pair := [2]common.Address{token, quote}
+	uniswapPoolsMux.Lock()
+	pools, ok := uniswapPools[pair]
+	uniswapPoolsMux.Unlock()
+
+	if !ok {
+		factory := bind.NewBoundContract(uniswapV3Factory, uniswapV3ABI, caller, nil, nil)
+		for _, fee := range uniswapV3FeeTiers {
+			out := []interface{}{}
+			err := factory.Call(opts, &out, "getPool", token, quote, big.NewInt(fee))
+			if err != nil {
+				return nil, err
+			}
+			if pool := out[0].(common.Address); pool != (common.Address{}) {
+				pools = append(pools, pool)
+			}
+		}
+		uniswapPoolsMux.Lock()
+		uniswapPools[pair] = pools
+		uniswapPoolsMux.Unlock()
+	}
+
+	liquidity := make(map[common.Address]*big.Int, len(pools))
+	sorted := make([]common.Address, 0, len(pools))
+	for _, pool := range pools {
+		out := []interface{}{}
+		err := bind.NewBoundContract(pool, uniswapV3ABI, caller, nil, nil).Call(opts, &out, "liquidity")
+		if err != nil {
+			return nil, err
+		}
+		if l := out[0].(*big.Int); l.Sign() > 0 {
+			liquidity[pool] = l
+			sorted = append(sorted, pool)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return liquidity[sorted[i]].Cmp(liquidity[sorted[j]]) > 0
+	})
+	return sorted, nil
+}
@@ -212,10 +212,6 @@ func (client *ErigonClient) GetBlock(number int64) (*types.Eth1Block, *types.Get
 					c.Transactions[trace.TransactionPosition].ErrorMsg = trace.Error
 				}
 
-				if trace.Type == "CREATE2" {
-					trace.Type = "CREATE"
-				}
-
 				tracePb := &types.Eth1InternalTransaction{
 					Type: strings.ToLower(trace.Type),
 					Path: "0",
@@ -224,7 +220,7 @@ func (client *ErigonClient) GetBlock(number int64) (*types.Eth1Block, *types.Get
 				tracePb.From = trace.From.Bytes()
 				tracePb.To = trace.To.Bytes()
 				tracePb.Value = common.FromHex(trace.Value)
-				if trace.Type == "CREATE" {
+				if trace.Type == "CREATE" || trace.Type == "CREATE2" {
 				} else if trace.Type == "SUICIDE" {
 				} else if trace.Type == "CALL" || trace.Type == "DELEGATECALL" || trace.Type == "STATICCALL" {
 				} else {
@@ -267,6 +263,10 @@ func (client *ErigonClient) GetBlock(number int64) (*types.Eth1Block, *types.Get
 			}
 
 			if trace.Type == "create" {
+				// creations via CREATE2 are kept apart to be able to tell deterministic deployments from regular ones
+				if trace.Action.CreationMethod == "create2" {
+					tracePb.Type = "create2"
+				}
 				tracePb.From = common.FromHex(trace.Action.From)
 				tracePb.To = common.FromHex(trace.Result.Address)
 				tracePb.Value = common.FromHex(trace.Action.Value)
@@ -438,6 +438,8 @@ type ParityTraceResult struct {
 		RefundAddress string `json:"refundAddress"`
 		Author        string `json:"author"`
 		RewardType    string `json:"rewardType"`
+		// CreationMethod is either create or create2 for create traces
+		CreationMethod string `json:"creationMethod"`
 	} `json:"action"`
 	BlockHash   string `json:"blockHash"`
 	BlockNumber int    `json:"blockNumber"`
@@ -3,7 +3,9 @@ package rpc
 import (
 	"context"
 	"encoding/hex"
+	"eth2-exporter/erc1155"
 	"eth2-exporter/erc20"
+	"eth2-exporter/erc721"
 	"eth2-exporter/types"
 	"fmt"
 	"math/big"
@@ -413,3 +415,26 @@ func (client *GethClient) GetERC20TokenMetadata(token []byte) (*types.ERC20Metad
 
 	return ret, err
 }
+
+// GetERC721TokenURI returns the metadata uri of a token of an ERC721 contract
+func (client *GethClient) GetERC721TokenURI(ctx context.Context, token []byte, tokenId *big.Int) (string, error) {
+	contract, err := erc721.NewErc721Caller(common.BytesToAddress(token), client.ethClient)
+	if err != nil {
+		return "", err
+	}
+	return contract.TokenURI(&bind.CallOpts{Context: ctx}, tokenId)
+}
+
+// GetERC1155URI returns the metadata uri of a token of an ERC1155 contract, the {id} placeholder of the uri is replaced with the
+// token id as defined by the standard
+func (client *GethClient) GetERC1155URI(ctx context.Context, token []byte, tokenId *big.Int) (string, error) {
+	contract, err := erc1155.NewErc1155Caller(common.BytesToAddress(token), client.ethClient)
+	if err != nil {
+		return "", err
+	}
+	uri, err := contract.Uri(&bind.CallOpts{Context: ctx}, tokenId)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenId)), nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// the address page of mega-active contracts (stablecoins, routers, ...) requires several expensive bigtable scans,
+// the first pages of the activity tables of the most visited addresses are therefore kept pre-aggregated in memory
+var addressVisits = make(map[string]uint64)
+var addressVisitsMux = &sync.Mutex{}
+
+var addressActivities = make(map[string]*types.AddressActivity)
+var addressActivitiesMux = &sync.RWMutex{}
+
+func addressActivityCacheSize() int {
+	if utils.Config.Frontend.AddressActivityCache.Size > 0 {
+		return utils.Config.Frontend.AddressActivityCache.Size
+	}
+	return 100
+}
+
+func addressActivityCacheInterval() time.Duration {
+	if utils.Config.Frontend.AddressActivityCache.Interval > 0 {
+		return utils.Config.Frontend.AddressActivityCache.Interval
+	}
+	return time.Minute
+}
+
+// RecordAddressVisit counts a visit of an address page, the most visited addresses are served from the activity cache
+func RecordAddressVisit(address []byte) {
+	if !utils.Config.Frontend.AddressActivityCache.Enabled {
+		return
+	}
+	addressVisitsMux.Lock()
+	defer addressVisitsMux.Unlock()
+	addressVisits[string(address)]++
+}
+
+// GetAddressActivity returns the cached activity of an address or nil if the address is not among the hottest addresses
+func GetAddressActivity(address []byte) *types.AddressActivity {
+	addressActivitiesMux.RLock()
+	defer addressActivitiesMux.RUnlock()
+	return addressActivities[string(address)]
+}
+
+// addressTableDataReader is the part of the bigtable client that is required to aggregate the activity of an address
+type addressTableDataReader interface {
+	GetAddressTransactionsTableData(ctx context.Context, address []byte, filter db.IndexFilter, pageToken string) (*types.DataTableResponse, error)
+	GetAddressInternalTableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error)
+	GetAddressErc20TableData(ctx context.Context, address []byte, search string, pageToken string) (*types.DataTableResponse, error)
+}
+
+func addressActivityUpdater(bt addressTableDataReader) {
+	for {
+		start := time.Now()
+		hottest := hottestAddresses(addressActivityCacheSize())
+
+		activities := make(map[string]*types.AddressActivity, len(hottest))
+		for _, address := range hottest {
+			activity, err := getAddressActivity(bt, []byte(address))
+			if err != nil {
+				logger.Errorf("error updating activity cache of address 0x%x: %v", address, err)
+				// keep serving the previous data until the next update succeeds
+				if previous := GetAddressActivity([]byte(address)); previous != nil {
+					activities[address] = previous
+				}
+				continue
+			}
+			activities[address] = activity
+		}
+
+		addressActivitiesMux.Lock()
+		addressActivities = activities
+		addressActivitiesMux.Unlock()
+
+		logger.Infof("updated the activity cache of %v addresses, took %v", len(activities), time.Since(start))
+		time.Sleep(addressActivityCacheInterval())
+	}
+}
+
+// hottestAddresses returns the most visited addresses and halves all visit counts so that the ranking follows recent traffic
+func hottestAddresses(limit int) []string {
+	addressVisitsMux.Lock()
+	defer addressVisitsMux.Unlock()
+
+	addresses := make([]string, 0, len(addressVisits))
+	for address, visits := range addressVisits {
+		if visits == 0 {
+			delete(addressVisits, address)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return addressVisits[addresses[i]] > addressVisits[addresses[j]]
+	})
+	if len(addresses) > limit {
+		addresses = addresses[:limit]
+	}
+
+	for address := range addressVisits {
+		addressVisits[address] /= 2
+	}
+	return addresses
+}
+
+func getAddressActivity(bt addressTableDataReader, address []byte) (*types.AddressActivity, error) {
+	activity := &types.AddressActivity{
+		UpdatedAt: time.Now(),
+	}
+
+	g := new(errgroup.Group)
+	g.Go(func() error {
+		var err error
+		activity.Transactions, err = bt.GetAddressTransactionsTableData(context.Background(), address, db.FILTER_TIME, "")
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		activity.InternalTransactions, err = bt.GetAddressInternalTableData(context.Background(), address, "", "")
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		activity.Erc20Transfers, err = bt.GetAddressErc20TableData(context.Background(), address, "", "")
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
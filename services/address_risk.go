@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// window in which the distinct recipients of an address are counted for the fan out indicator
+const addressRiskFanOutWindow = time.Hour * 24 * 7
+
+// window in which the counterparties of an address are checked against the flagged addresses
+const addressRiskInteractionWindow = time.Hour * 24 * 365
+
+// maximum number of counterparties that are read when looking up the counterparties of an address
+const addressRiskMaxCounterparties = 10000
+
+const (
+	AddressRiskFlagged            = "flagged"
+	AddressRiskFlaggedInteraction = "flagged_interaction"
+	AddressRiskFreshFunded        = "fresh_funded"
+	AddressRiskFanOut             = "fan_out"
+)
+
+func addressRiskFreshFundingAge() time.Duration {
+	if utils.Config.Frontend.AddressRisk.FreshFundingAge > 0 {
+		return utils.Config.Frontend.AddressRisk.FreshFundingAge
+	}
+	return time.Hour * 24 * 7
+}
+
+func addressRiskFanOutThreshold() int {
+	if utils.Config.Frontend.AddressRisk.FanOutThreshold > 0 {
+		return utils.Config.Frontend.AddressRisk.FanOutThreshold
+	}
+	return 100
+}
+
+// flaggedAddresses returns the flagged addresses of the config keyed by their lower case hex representation without 0x prefix
+func flaggedAddresses() map[string]string {
+	flagged := make(map[string]string, len(utils.Config.Frontend.AddressRisk.FlaggedAddresses))
+	for address, reason := range utils.Config.Frontend.AddressRisk.FlaggedAddresses {
+		if reason == "" {
+			reason = "flagged address"
+		}
+		flagged[strings.ToLower(strings.TrimPrefix(address, "0x"))] = reason
+	}
+	return flagged
+}
+
+// GetAddressRisk computes simple heuristic risk indicators of an address from its indexed activity:
+// interactions with flagged addresses such as mixers, funding that happened only recently and payouts to many distinct recipients.
+// Nil is returned if the risk heuristics are disabled for the deployment.
+func GetAddressRisk(address []byte) (*types.AddressRisk, error) {
+	if !utils.Config.Frontend.AddressRisk.Enabled {
+		return nil, nil
+	}
+
+	cacheKey := fmt.Sprintf("%d:frontend:addressRisk:%x", utils.Config.Chain.Config.DepositChainID, address)
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Minute*10, &types.AddressRisk{}); err == nil {
+		return cached.(*types.AddressRisk), nil
+	}
+
+	now := time.Now()
+	risk := &types.AddressRisk{Indicators: []*types.AddressRiskIndicator{}}
+	add := func(id, description string) {
+		risk.Indicators = append(risk.Indicators, &types.AddressRiskIndicator{Id: id, Description: description})
+	}
+
+	flagged := flaggedAddresses()
+	if reason, ok := flagged[fmt.Sprintf("%x", address)]; ok {
+		add(AddressRiskFlagged, fmt.Sprintf("The address is flagged: %v", reason))
+	}
+
+	if len(flagged) > 0 {
+		counterparties, _, err := db.BigtableClient.GetAddressCounterparties(context.Background(), address, now.Add(-addressRiskInteractionWindow), addressRiskMaxCounterparties, addressRiskMaxCounterparties)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range counterparties {
+			if reason, ok := flagged[strings.TrimPrefix(c.Address, "0x")]; ok {
+				add(AddressRiskFlaggedInteraction, fmt.Sprintf("Interacted with %v (%v)", utils.FixAddressCasing(c.Address), reason))
+			}
+		}
+	}
+
+	recent, _, err := db.BigtableClient.GetAddressCounterparties(context.Background(), address, now.Add(-addressRiskFanOutWindow), addressRiskMaxCounterparties, addressRiskMaxCounterparties)
+	if err != nil {
+		return nil, err
+	}
+	recipients := 0
+	for _, c := range recent {
+		for _, f := range c.Flows {
+			out, err := decimal.NewFromString(f.Out)
+			if err != nil {
+				return nil, err
+			}
+			if out.IsPositive() {
+				recipients++
+				break
+			}
+		}
+	}
+	if recipients >= addressRiskFanOutThreshold() {
+		add(AddressRiskFanOut, fmt.Sprintf("Sent funds to %v distinct addresses within the last %v days", recipients, int(addressRiskFanOutWindow.Hours()/24)))
+	}
+
+	if len(recent) > 0 {
+		age := addressRiskFreshFundingAge()
+		older, err := db.BigtableClient.HasAddressActivityBefore(context.Background(), address, now.Add(-age))
+		if err != nil {
+			return nil, err
+		}
+		if !older {
+			add(AddressRiskFreshFunded, fmt.Sprintf("The address was funded within the last %v days", int(age.Hours()/24)))
+		}
+	}
+
+	risk.Score = len(risk.Indicators)
+	switch {
+	case risk.Score == 0:
+		risk.Level = "none"
+	case risk.Score == 1:
+		risk.Level = "low"
+	case risk.Score == 2:
+		risk.Level = "medium"
+	default:
+		risk.Level = "high"
+	}
+
+	err = cache.TieredCache.Set(cacheKey, risk, time.Minute*10)
+	if err != nil {
+		logger.Errorf("error caching risk of address %x: %v", address, err)
+	}
+
+	return risk, nil
+}
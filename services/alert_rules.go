@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/shopspring/decimal"
+)
+
+// counterparties an address has interacted with during this period before the rule window are not considered new
+const alertRuleCounterpartyLookback = time.Hour * 24 * 30
+
+// maximum number of counterparties that are read when looking up the counterparties of an address
+const alertRuleMaxCounterparties = 10000
+
+type alertRuleNotification struct {
+	SubscriptionID  uint64
+	UserID          uint64
+	Epoch           uint64
+	RuleID          uint64
+	RuleName        string
+	Matches         []string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *alertRuleNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *alertRuleNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *alertRuleNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *alertRuleNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *alertRuleNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *alertRuleNotification) GetEventName() types.EventName {
+	return types.AlertRuleTriggeredEventName
+}
+
+func (n *alertRuleNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`Your alert rule "%v" has been triggered in epoch %v.`, n.RuleName, n.Epoch)
+	if len(n.Matches) > 0 {
+		generalPart += fmt.Sprintf(` Matching conditions: %v.`, strings.Join(n.Matches, ", "))
+	}
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` Learn more at https://%v/epoch/%v`, utils.Config.Frontend.SiteDomain, n.Epoch)
+	}
+	return generalPart
+}
+
+func (n *alertRuleNotification) GetTitle() string {
+	return "Alert Rule Triggered"
+}
+
+func (n *alertRuleNotification) GetEventFilter() string {
+	return fmt.Sprintf("%v", n.RuleID)
+}
+
+func (n *alertRuleNotification) GetInfoMarkdown() string {
+	return n.GetInfo(false) + fmt.Sprintf(` ([epoch %[1]v](https://%[2]v/epoch/%[1]v))`, n.Epoch, utils.Config.Frontend.SiteDomain)
+}
+
+// alertRuleEvaluator evaluates the conditions of alert rules at an epoch, metric values are shared by all rules of a collection run
+type alertRuleEvaluator struct {
+	epoch  uint64
+	values map[string]float64
+}
+
+func newAlertRuleEvaluator(epoch uint64) *alertRuleEvaluator {
+	return &alertRuleEvaluator{epoch: epoch, values: make(map[string]float64)}
+}
+
+// evaluate returns whether the condition holds and the descriptions of the leaf conditions that contributed to the result
+func (e *alertRuleEvaluator) evaluate(c *types.AlertCondition, window uint64) (bool, []string, error) {
+	switch c.Operator {
+	case types.AlertOperatorAnd:
+		matches := []string{}
+		for _, child := range c.Conditions {
+			ok, childMatches, err := e.evaluate(child, window)
+			if err != nil || !ok {
+				return false, nil, err
+			}
+			matches = append(matches, childMatches...)
+		}
+		return true, matches, nil
+	case types.AlertOperatorOr:
+		for _, child := range c.Conditions {
+			ok, childMatches, err := e.evaluate(child, window)
+			if err != nil {
+				return false, nil, err
+			}
+			if ok {
+				return true, childMatches, nil
+			}
+		}
+		return false, nil, nil
+	case types.AlertOperatorNot:
+		ok, _, err := e.evaluate(c.Conditions[0], window)
+		return !ok && err == nil, nil, err
+	}
+
+	value, err := e.value(c.Metric, c.Target, window)
+	if err != nil {
+		return false, nil, err
+	}
+	if !c.Compare(value) {
+		return false, nil, nil
+	}
+	return true, []string{fmt.Sprintf("%v of %v is %v (%v %v)", c.Metric, c.Target, value, c.Comparator, c.Threshold)}, nil
+}
+
+func (e *alertRuleEvaluator) value(metric types.AlertRuleMetric, target string, window uint64) (float64, error) {
+	key := fmt.Sprintf("%v:%v:%v", metric, strings.ToLower(target), window)
+	if value, ok := e.values[key]; ok {
+		return value, nil
+	}
+
+	startEpoch := uint64(0)
+	if e.epoch >= window {
+		startEpoch = e.epoch - window + 1
+	}
+
+	var value float64
+	var err error
+	if metric.IsValidatorMetric() {
+		var validatorIndex uint64
+		validatorIndex, err = strconv.ParseUint(target, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		switch metric {
+		case types.AlertMetricValidatorBalanceDecrease:
+			value, err = alertRuleBalanceDecrease(validatorIndex, startEpoch, e.epoch)
+		case types.AlertMetricValidatorMissedAttestations:
+			value, err = alertRuleMissedAttestations(validatorIndex, startEpoch, e.epoch)
+		case types.AlertMetricValidatorMissedProposals:
+			value, err = alertRuleMissedProposals(validatorIndex, startEpoch, e.epoch)
+		}
+	} else if metric == types.AlertMetricAddressReceivedFromNewCounterparty {
+		value, err = alertRuleReceivedFromNewCounterparty(common.HexToAddress(target).Bytes(), utils.EpochToTime(startEpoch))
+	} else {
+		err = fmt.Errorf("unsupported alert rule metric %v", metric)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error getting %v of %v: %w", metric, target, err)
+	}
+
+	e.values[key] = value
+	return value, nil
+}
+
+func alertRuleBalanceDecrease(validatorIndex, startEpoch, endEpoch uint64) (float64, error) {
+	// the balance at the end of the epoch before the window is the reference of the decrease
+	if startEpoch > 0 {
+		startEpoch--
+	}
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(context.Background(), []uint64{validatorIndex}, startEpoch, endEpoch)
+	if err != nil {
+		return 0, err
+	}
+
+	var first, last *types.ValidatorBalance
+	for _, b := range balances[validatorIndex] {
+		if first == nil || b.Epoch < first.Epoch {
+			first = b
+		}
+		if last == nil || b.Epoch > last.Epoch {
+			last = b
+		}
+	}
+	if first == nil {
+		return 0, nil
+	}
+	return (float64(first.Balance) - float64(last.Balance)) / 1e9, nil
+}
+
+func alertRuleMissedAttestations(validatorIndex, startEpoch, endEpoch uint64) (float64, error) {
+	attestations, err := db.BigtableClient.GetValidatorAttestationHistory(context.Background(), []uint64{validatorIndex}, startEpoch, endEpoch)
+	if err != nil {
+		return 0, err
+	}
+
+	missed := 0
+	for _, a := range attestations[validatorIndex] {
+		if a.Status == 0 {
+			missed++
+		}
+	}
+	return float64(missed), nil
+}
+
+func alertRuleMissedProposals(validatorIndex, startEpoch, endEpoch uint64) (float64, error) {
+	var missed uint64
+	err := db.ReaderDb.Get(&missed, `
+		SELECT COUNT(*)
+		FROM blocks
+		WHERE proposer = $1 AND epoch >= $2 AND epoch <= $3 AND status = '2'`, validatorIndex, startEpoch, endEpoch)
+	if err != nil {
+		return 0, err
+	}
+	return float64(missed), nil
+}
+
+// alertRuleReceivedFromNewCounterparty returns the largest amount of ether the address received since windowStart from a counterparty
+// it has not interacted with during the lookback period before the window
+func alertRuleReceivedFromNewCounterparty(address []byte, windowStart time.Time) (float64, error) {
+	recent, _, err := db.BigtableClient.GetAddressCounterparties(context.Background(), address, windowStart, alertRuleMaxCounterparties, alertRuleMaxCounterparties)
+	if err != nil {
+		return 0, err
+	}
+	if len(recent) == 0 {
+		return 0, nil
+	}
+	lookback, _, err := db.BigtableClient.GetAddressCounterparties(context.Background(), address, windowStart.Add(-alertRuleCounterpartyLookback), alertRuleMaxCounterparties, alertRuleMaxCounterparties)
+	if err != nil {
+		return 0, err
+	}
+	lookbackTransfers := make(map[string]uint64, len(lookback))
+	for _, c := range lookback {
+		lookbackTransfers[c.Address] = c.Transfers
+	}
+
+	largest := decimal.Zero
+	for _, c := range recent {
+		// a counterparty is new if all its transfers of the lookback period happened within the window
+		if lookbackTransfers[c.Address] > c.Transfers {
+			continue
+		}
+		for _, f := range c.Flows {
+			if f.Token != "" {
+				continue
+			}
+			in, err := decimal.NewFromString(f.In)
+			if err != nil {
+				return 0, err
+			}
+			if in.GreaterThan(largest) {
+				largest = in
+			}
+		}
+	}
+	value, _ := largest.Float64()
+	return value, nil
+}
+
+// collectAlertRuleNotifications evaluates the alert rules of all users that have not been triggered within their window
+func collectAlertRuleNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	rules, err := db.GetDueAlertRules(utils.GetNetwork(), epoch)
+	if err != nil {
+		return err
+	}
+
+	evaluator := newAlertRuleEvaluator(epoch)
+	for _, rule := range rules {
+		triggered, matches, err := evaluator.evaluate(&rule.Condition, rule.Window)
+		if err != nil {
+			// a single rule must not block the notifications of all other users
+			logger.WithError(err).Errorf("error evaluating alert rule %v of user %v", rule.ID, rule.UserID)
+			continue
+		}
+		if !triggered {
+			continue
+		}
+
+		n := &alertRuleNotification{
+			SubscriptionID:  rule.SubscriptionID,
+			UserID:          rule.UserID,
+			Epoch:           epoch,
+			RuleID:          rule.ID,
+			RuleName:        rule.Name,
+			Matches:         matches,
+			UnsubscribeHash: rule.UnsubscribeHash,
+		}
+		if _, exists := notificationsByUserID[rule.UserID]; !exists {
+			notificationsByUserID[rule.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[rule.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[rule.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[rule.UserID][n.GetEventName()] = append(notificationsByUserID[rule.UserID][n.GetEventName()], n)
+		metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// number of completed epochs the current value of a metric is compared against (~1 day)
+	anomalyHistoryEpochs = 225
+	// series shorter than this are not evaluated as their variance is not meaningful yet
+	anomalyMinHistory = 32
+	anomalyEWMAAlpha  = 0.1
+	anomalyThreshold  = 4.0
+	// number of detected anomalies that are kept for the status page
+	anomalyHistorySize = 50
+)
+
+// anomalyMetric is a per epoch series of an indexed chain metric
+type anomalyMetric struct {
+	name  string
+	query string
+}
+
+// all queries must return the epoch and the value of the metric in ascending epoch order
+var anomalyMetrics = []anomalyMetric{
+	{
+		name: "Transaction count",
+		query: `
+			SELECT epoch, COALESCE(SUM(exec_transactions_count), 0)::FLOAT AS value
+			FROM blocks
+			WHERE epoch > $1 AND epoch < $2 AND status = '1'
+			GROUP BY epoch
+			ORDER BY epoch`,
+	},
+	{
+		name: "Base fee",
+		query: `
+			SELECT epoch, COALESCE(AVG(exec_base_fee_per_gas), 0)::FLOAT AS value
+			FROM blocks
+			WHERE epoch > $1 AND epoch < $2 AND status = '1' AND exec_block_number IS NOT NULL
+			GROUP BY epoch
+			ORDER BY epoch`,
+	},
+	{
+		name: "Missed proposals",
+		query: `
+			SELECT epoch, COUNT(*) FILTER (WHERE status = '2')::FLOAT AS value
+			FROM blocks
+			WHERE epoch > $1 AND epoch < $2
+			GROUP BY epoch
+			ORDER BY epoch`,
+	},
+	{
+		name: "Participation rate",
+		query: `
+			SELECT epoch, globalparticipationrate AS value
+			FROM epochs
+			WHERE epoch > $1 AND epoch < $2 AND globalparticipationrate IS NOT NULL
+			ORDER BY epoch`,
+	},
+}
+
+// The anomaly detection service compares the latest value of several chain metrics against their recent history
+// and reports an error status for the monitoring_anomalies module if a value deviates too much
+func startAnomalyDetectionService() {
+	name := "monitoring_anomalies"
+	firstRun := true
+	for {
+		if !firstRun {
+			time.Sleep(time.Minute * 5)
+		}
+		firstRun = false
+
+		latestEpoch := LatestEpoch()
+		if latestEpoch < anomalyHistoryEpochs+2 {
+			ReportStatus(name, "OK", nil)
+			continue
+		}
+
+		// the current and the previous epoch are still in progress and would always look anomalous
+		toEpoch := latestEpoch - 1
+		fromEpoch := toEpoch - anomalyHistoryEpochs - 1
+
+		detected := []*types.ChainAnomaly{}
+		for _, metric := range anomalyMetrics {
+			anomaly, err := detectAnomaly(metric, fromEpoch, toEpoch)
+			if err != nil {
+				logger.Errorf("error evaluating anomalies of metric %v: %v", metric.name, err)
+				continue
+			}
+			if anomaly != nil {
+				detected = append(detected, anomaly)
+			}
+		}
+
+		if len(detected) == 0 {
+			ReportStatus(name, "OK", nil)
+			continue
+		}
+
+		err := storeAnomalies(detected)
+		if err != nil {
+			logger.Errorf("error storing detected anomalies: %v", err)
+		}
+
+		descriptions := make([]string, 0, len(detected))
+		for _, anomaly := range detected {
+			descriptions = append(descriptions, fmt.Sprintf("%v in epoch %v is %.2f (expected %.2f, z-score %.1f)", anomaly.Metric, anomaly.Epoch, anomaly.Value, anomaly.Expected, anomaly.ZScore))
+		}
+		errorMsg := fmt.Errorf("error: anomalies detected: %v", strings.Join(descriptions, "; "))
+		utils.LogError(nil, errorMsg, 0)
+		ReportStatus(name, errorMsg.Error(), nil)
+	}
+}
+
+func detectAnomaly(metric anomalyMetric, fromEpoch, toEpoch uint64) (*types.ChainAnomaly, error) {
+	points := []struct {
+		Epoch uint64  `db:"epoch"`
+		Value float64 `db:"value"`
+	}{}
+	err := db.ReaderDb.Select(&points, metric.query, fromEpoch, toEpoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < anomalyMinHistory {
+		return nil, nil
+	}
+
+	series := make([]float64, len(points))
+	for i, p := range points {
+		series[i] = p.Value
+	}
+
+	expected, zScore := utils.AnomalyScore(series, anomalyEWMAAlpha)
+	if math.Abs(zScore) < anomalyThreshold {
+		return nil, nil
+	}
+
+	last := points[len(points)-1]
+	return &types.ChainAnomaly{
+		Metric:     metric.name,
+		Epoch:      last.Epoch,
+		Value:      last.Value,
+		Expected:   expected,
+		ZScore:     zScore,
+		DetectedAt: time.Now(),
+	}, nil
+}
+
+func anomaliesCacheKey() string {
+	return fmt.Sprintf("%d:frontend:anomalies", utils.Config.Chain.Config.DepositChainID)
+}
+
+// storeAnomalies adds newly detected anomalies to the list of recent anomalies, an anomaly is only stored once per metric and epoch
+func storeAnomalies(detected []*types.ChainAnomaly) error {
+	anomalies := LatestAnomalies()
+
+	known := make(map[string]bool, len(anomalies))
+	for _, anomaly := range anomalies {
+		known[fmt.Sprintf("%s:%d", anomaly.Metric, anomaly.Epoch)] = true
+	}
+	for _, anomaly := range detected {
+		if !known[fmt.Sprintf("%s:%d", anomaly.Metric, anomaly.Epoch)] {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].DetectedAt.After(anomalies[j].DetectedAt)
+	})
+	if len(anomalies) > anomalyHistorySize {
+		anomalies = anomalies[:anomalyHistorySize]
+	}
+
+	return cache.TieredCache.Set(anomaliesCacheKey(), anomalies, time.Hour*24*7)
+}
+
+// LatestAnomalies returns the most recently detected chain metric anomalies, newest first
+func LatestAnomalies() []*types.ChainAnomaly {
+	wanted := &[]*types.ChainAnomaly{}
+	if cached, err := cache.TieredCache.GetWithLocalTimeout(anomaliesCacheKey(), time.Minute, wanted); err == nil {
+		return *cached.(*[]*types.ChainAnomaly)
+	}
+	return []*types.ChainAnomaly{}
+}
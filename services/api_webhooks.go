@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var apiWebhookClient = &http.Client{Timeout: time.Second * 10}
+
+// startApiWebhookService delivers the operational webhooks of the api keys: index_lag events while the execution data index
+// lags behind the blocks table by more than the threshold of a webhook and newly announced maintenance and deprecation incidents
+func startApiWebhookService() {
+
+	name := "monitoring_api_webhooks"
+	firstRun := true
+	for {
+		if !firstRun {
+			time.Sleep(time.Minute)
+		}
+		firstRun = false
+
+		err := dispatchIndexLagWebhooks()
+		if err != nil {
+			ReportStatus(name, err.Error(), nil)
+			continue
+		}
+
+		err = dispatchIncidentWebhooks()
+		if err != nil {
+			ReportStatus(name, err.Error(), nil)
+			continue
+		}
+		ReportStatus(name, "OK", nil)
+	}
+}
+
+func dispatchIndexLagWebhooks() error {
+	numberBlocksTable, err := db.GetEth1Store().GetLastBlockInBlocksTable(context.Background())
+	if err != nil {
+		return fmt.Errorf("error: could not retrieve latest block number from the blocks table: %v", err)
+	}
+	numberDataTable, err := db.GetEth1Store().GetLastBlockInDataTable(context.Background())
+	if err != nil {
+		return fmt.Errorf("error: could not retrieve latest block number from the data table: %v", err)
+	}
+
+	lag := uint64(0)
+	if numberBlocksTable > numberDataTable {
+		lag = uint64(numberBlocksTable - numberDataTable)
+	}
+	if lag == 0 {
+		return nil
+	}
+
+	webhooks, err := db.GetApiWebhooksForEvent(types.ApiWebhookIndexLag)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if lag <= webhook.LagThreshold {
+			continue
+		}
+		if webhook.LastLagSent.Valid && now.Sub(webhook.LastLagSent.Time) < types.ApiWebhookLagInterval {
+			continue
+		}
+
+		ok := deliverApiWebhook(webhook, &types.ApiWebhookPayload{
+			Event:   types.ApiWebhookIndexLag,
+			Network: utils.Config.Chain.Name,
+			Time:    now,
+			IndexLag: &types.ApiWebhookIndexLagData{
+				BlocksTable: uint64(numberBlocksTable),
+				DataTable:   uint64(numberDataTable),
+				Lag:         lag,
+				Threshold:   webhook.LagThreshold,
+			},
+		})
+		if ok {
+			err = db.SetApiWebhookLagSent(webhook.ID, now)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func dispatchIncidentWebhooks() error {
+	// webhooks only start receiving incidents announced after their creation, so it is enough to load the incidents after the oldest delivered one
+	webhooks := map[types.ApiWebhookEvent][]*types.ApiWebhook{}
+	minIncident := uint64(0)
+	first := true
+	for _, event := range []types.ApiWebhookEvent{types.ApiWebhookMaintenance, types.ApiWebhookDeprecation} {
+		eventWebhooks, err := db.GetApiWebhooksForEvent(event)
+		if err != nil {
+			return err
+		}
+		for _, webhook := range eventWebhooks {
+			if first || webhook.LastIncidentID < minIncident {
+				minIncident = webhook.LastIncidentID
+				first = false
+			}
+		}
+		webhooks[event] = eventWebhooks
+	}
+	if first {
+		return nil
+	}
+
+	incidents, err := db.GetApiIncidentsAfter(minIncident)
+	if err != nil {
+		return err
+	}
+
+	for _, incident := range incidents {
+		payload := &types.ApiWebhookPayload{
+			Event:    incident.Kind,
+			Network:  utils.Config.Chain.Name,
+			Time:     time.Now(),
+			Incident: incident,
+		}
+		if incident.StartTs.Valid {
+			payload.StartTs = &incident.StartTs.Time
+		}
+		if incident.EndTs.Valid {
+			payload.EndTs = &incident.EndTs.Time
+		}
+
+		for _, webhook := range webhooks[incident.Kind] {
+			if webhook.LastIncidentID >= incident.ID {
+				continue
+			}
+			// incidents are delivered in order, a failed delivery is retried in the next run before any later incident is sent
+			if !deliverApiWebhook(webhook, payload) {
+				webhook.LastIncidentID = ^uint64(0)
+				continue
+			}
+			webhook.LastIncidentID = incident.ID
+			err = db.SetApiWebhookLastIncident(webhook.ID, incident.ID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deliverApiWebhook posts the payload to the url of the webhook and records the outcome, returns true if the delivery succeeded
+func deliverApiWebhook(webhook *types.ApiWebhook, payload *types.ApiWebhookPayload) bool {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logger.Errorf("error marshalling api webhook payload: %v", err)
+		return false
+	}
+
+	success := false
+	resp, err := apiWebhookClient.Post(webhook.Url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		logger.Warnf("error delivering %v event to api webhook %v: %v", payload.Event, webhook.ID, err)
+	} else {
+		resp.Body.Close()
+		success = resp.StatusCode >= 200 && resp.StatusCode < 300
+		if !success {
+			logger.Warnf("api webhook %v responded with status %v to %v event", webhook.ID, resp.StatusCode, payload.Event)
+		}
+	}
+
+	err = db.UpdateApiWebhookDelivery(webhook.ID, success)
+	if err != nil {
+		logger.Errorf("error updating delivery of api webhook %v: %v", webhook.ID, err)
+	}
+	return success
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+)
+
+// GetValidatorBenchmark ranks the daily consensus income and attestation participation of a validator set within the
+// network wide distributions of the last exported statistics days
+func GetValidatorBenchmark(validators []uint64, days uint64) (*types.APIValidatorBenchmark, error) {
+	var lastDay uint64
+	err := db.ReaderDb.Get(&lastDay, "SELECT COALESCE(MAX(day), 0) FROM validator_stats_status WHERE status")
+	if err != nil {
+		return nil, fmt.Errorf("error getting last exported statistics day: %w", err)
+	}
+	fromDay := uint64(0)
+	if lastDay+1 > days {
+		fromDay = lastDay + 1 - days
+	}
+
+	benchmark := &types.APIValidatorBenchmark{
+		Validators: len(validators),
+		FromDay:    fromDay,
+		ToDay:      lastDay,
+		Days:       make([]*types.APIValidatorBenchmarkDayData, 0, days),
+	}
+	dayData := make(map[uint64]*types.APIValidatorBenchmarkDayData, days)
+
+	for _, metric := range []string{types.ValidatorStatsMetricClRewards, types.ValidatorStatsMetricAttestationParticipation} {
+		distributions, err := db.GetValidatorStatsDistributions(metric, fromDay, lastDay)
+		if err != nil {
+			return nil, err
+		}
+		averages, err := db.GetValidatorSetDailyAverage(validators, metric, fromDay, lastDay)
+		if err != nil {
+			return nil, err
+		}
+
+		total := &types.APIValidatorBenchmarkMetric{}
+		count := 0
+		for day := fromDay; day <= lastDay; day++ {
+			percentiles, ok := distributions[day]
+			if !ok || len(percentiles) == 0 {
+				continue
+			}
+			value, ok := averages[day]
+			if !ok {
+				continue
+			}
+
+			result := &types.APIValidatorBenchmarkMetric{
+				Value:         value,
+				NetworkMedian: percentiles[len(percentiles)/2],
+				Percentile:    utils.PercentileRank(percentiles, value),
+			}
+			total.Value += result.Value
+			total.NetworkMedian += result.NetworkMedian
+			total.Percentile += result.Percentile
+			count++
+
+			if dayData[day] == nil {
+				dayData[day] = &types.APIValidatorBenchmarkDayData{Day: day}
+			}
+			switch metric {
+			case types.ValidatorStatsMetricClRewards:
+				dayData[day].ClRewards = result
+			case types.ValidatorStatsMetricAttestationParticipation:
+				dayData[day].AttestationParticipation = result
+			}
+		}
+		if count == 0 {
+			continue
+		}
+
+		total.Value /= float64(count)
+		total.NetworkMedian /= float64(count)
+		total.Percentile /= float64(count)
+		switch metric {
+		case types.ValidatorStatsMetricClRewards:
+			benchmark.ClRewards = total
+		case types.ValidatorStatsMetricAttestationParticipation:
+			benchmark.AttestationParticipation = total
+		}
+	}
+
+	for day := fromDay; day <= lastDay; day++ {
+		if dayData[day] != nil {
+			benchmark.Days = append(benchmark.Days, dayData[day])
+		}
+	}
+	return benchmark, nil
+}
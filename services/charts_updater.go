@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"eth2-exporter/cache"
 	"eth2-exporter/db"
 	"eth2-exporter/metrics"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	"github.com/aybabtme/uniplot/histogram"
+	"github.com/lib/pq"
 )
 
 type chartHandler struct {
@@ -53,9 +55,17 @@ var ChartHandlers = map[string]chartHandler{
 	"block_count_chart_data":    {26, BlockCountChartData},
 	"block_time_avg_chart_data": {27, BlockTimeAvgChartData},
 	// "avg_gas_price":                      {25, AvgGasPrice},
-	"avg_gas_limit_chart_data":  {28, AvgGasLimitChartData},
-	"avg_block_util_chart_data": {29, AvgBlockUtilChartData},
-	"tx_count_chart_data":       {31, TxCountChartData},
+	"avg_gas_limit_chart_data":           {28, AvgGasLimitChartData},
+	"avg_block_util_chart_data":          {29, AvgBlockUtilChartData},
+	"tx_count_chart_data":                {31, TxCountChartData},
+	"avg_payload_size_chart_data":        {32, AvgPayloadSizeChartData},
+	"block_space_composition_chart_data": {33, BlockSpaceCompositionChartData},
+	"tx_type_distribution_chart_data":    {34, TxTypeDistributionChartData},
+	"nft_mints_chart_data":               {35, NFTMintsChartData},
+	"contract_deployments_chart_data":    {36, ContractDeploymentsChartData},
+	"aa_user_operations_chart_data":      {37, AAUserOperationsChartData},
+	"gas_price_history_chart_data":       {38, GasPriceHistoryChartData},
+	"burned-fees":                        {39, TotalBurnedFeesChartData},
 	// "avg_block_size_chart_data":          {32, AvgBlockSizeChartData},
 }
 
@@ -104,6 +114,17 @@ func chartsPageDataUpdater(wg *sync.WaitGroup) {
 		cacheKey := fmt.Sprintf("%d:frontend:chartsPageData", utils.Config.Chain.Config.DepositChainID)
 		cache.TieredCache.Set(cacheKey, data, time.Hour*24)
 
+		if EdgeCachePurgeEnabled() {
+			keys := []string{utils.EdgeCacheChartKey("all")}
+			for _, chart := range data {
+				keys = append(keys, utils.EdgeCacheChartKey(chart.Path))
+			}
+			err = PurgeEdgeCache(context.Background(), keys)
+			if err != nil {
+				logger.WithField("epoch", latestEpoch).Errorf("error purging charts from the edge cache: %v", err)
+			}
+		}
+
 		prevEpoch = latestEpoch
 
 		if firstun {
@@ -637,7 +658,7 @@ func balanceDistributionChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory([]uint64{}, epoch, epoch)
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(context.Background(), []uint64{}, epoch, epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -687,7 +708,7 @@ func effectiveBalanceDistributionChartData() (*types.GenericChartData, error) {
 		return nil, fmt.Errorf("chart-data not available pre-genesis")
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory([]uint64{}, epoch, epoch)
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(context.Background(), []uint64{}, epoch, epoch)
 	if err != nil {
 		return nil, err
 	}
@@ -1762,6 +1783,458 @@ func TxCountChartData() (*types.GenericChartData, error) {
 	return chartData, nil
 }
 
+func AvgPayloadSizeChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day   time.Time `db:"time"`
+		Value float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	err := db.ReaderDb.Select(&rows, "SELECT time, ROUND(value / 1024, 2) as value FROM chart_series WHERE time < $1 and indicator = 'AVG_PAYLOAD_SIZE' ORDER BY time", ts)
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := [][]float64{}
+
+	for _, row := range rows {
+		seriesData = append(seriesData, []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Average Payload Size",
+		Subtitle:                        "The average size of the transaction calldata contained in an execution payload",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Payload Size [KiB]",
+		StackingMode:                    "false",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "average",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Payload Size",
+				Data: seriesData,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func BlockSpaceCompositionChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	// BLOB_GAS is only present for days where the indexed blocks carry blob gas usage
+	err := db.ReaderDb.Select(&rows, "SELECT time, indicator, value FROM chart_series WHERE time < $1 and indicator = ANY('{CALLDATA_GAS,EXECUTION_GAS,BLOB_GAS}') ORDER BY time", ts)
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := map[string][][]float64{
+		"CALLDATA_GAS":  {},
+		"EXECUTION_GAS": {},
+		"BLOB_GAS":      {},
+	}
+
+	for _, row := range rows {
+		seriesData[row.Indicator] = append(seriesData[row.Indicator], []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Block Space Composition",
+		Subtitle:                        "Share of the daily gas used by calldata, execution and blobs",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Gas Share [%]",
+		StackingMode:                    "percent",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "sum",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Calldata",
+				Data: seriesData["CALLDATA_GAS"],
+			},
+			{
+				Name: "Execution",
+				Data: seriesData["EXECUTION_GAS"],
+			},
+			{
+				Name: "Blobs",
+				Data: seriesData["BLOB_GAS"],
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func TxTypeDistributionChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	err := db.ReaderDb.Select(&rows, "SELECT time, indicator, value FROM chart_series WHERE time < $1 and indicator = ANY($2) ORDER BY time", ts, pq.Array(types.TxTypeIndicators))
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := map[string][][]float64{}
+	for _, indicator := range types.TxTypeIndicators {
+		seriesData[indicator] = [][]float64{}
+	}
+
+	for _, row := range rows {
+		seriesData[row.Indicator] = append(seriesData[row.Indicator], []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Transaction Types",
+		Subtitle:                        "Daily number of transactions per transaction type",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Tx Count [#]",
+		StackingMode:                    "normal",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "sum",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Legacy",
+				Data: seriesData[types.TxTypeLegacyIndicator],
+			},
+			{
+				Name: "Access List (EIP-2930)",
+				Data: seriesData[types.TxTypeAccessListIndicator],
+			},
+			{
+				Name: "Dynamic Fee (EIP-1559)",
+				Data: seriesData[types.TxTypeEIP1559Indicator],
+			},
+			{
+				Name: "Blob (EIP-4844)",
+				Data: seriesData[types.TxTypeBlobIndicator],
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func NFTMintsChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	err := db.ReaderDb.Select(&rows, "SELECT time, indicator, value FROM chart_series WHERE time < $1 and indicator = ANY($2) ORDER BY time", ts, pq.Array(types.NFTMintIndicators))
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := map[string][][]float64{}
+	for _, indicator := range types.NFTMintIndicators {
+		seriesData[indicator] = [][]float64{}
+	}
+
+	for _, row := range rows {
+		seriesData[row.Indicator] = append(seriesData[row.Indicator], []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "NFT Mints",
+		Subtitle:                        "Daily number of minted ERC-721 and ERC-1155 tokens",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Mints [#]",
+		StackingMode:                    "normal",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "sum",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "ERC-721",
+				Data: seriesData[types.NFTMintsERC721Indicator],
+			},
+			{
+				Name: "ERC-1155",
+				Data: seriesData[types.NFTMintsERC1155Indicator],
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func ContractDeploymentsChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	err := db.ReaderDb.Select(&rows, "SELECT time, indicator, value FROM chart_series WHERE time < $1 and indicator = ANY($2) ORDER BY time", ts, pq.Array(types.ContractDeploymentIndicators))
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := map[string][][]float64{}
+	for _, indicator := range types.ContractDeploymentIndicators {
+		seriesData[indicator] = [][]float64{}
+	}
+
+	for _, row := range rows {
+		seriesData[row.Indicator] = append(seriesData[row.Indicator], []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Contract Deployments",
+		Subtitle:                        "Daily number of deployed contracts by transactions and by factory contracts via CREATE and CREATE2",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Contracts [#]",
+		StackingMode:                    "normal",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "sum",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Transaction",
+				Data: seriesData[types.ContractsDeployedTxIndicator],
+			},
+			{
+				Name: "Factory (CREATE)",
+				Data: seriesData[types.ContractsDeployedCreateIndicator],
+			},
+			{
+				Name: "Factory (CREATE2)",
+				Data: seriesData[types.ContractsDeployedCreate2Indicator],
+			},
+			{
+				// the factories are not stacked on top of the deployments
+				Name:  "Active Factories",
+				Data:  seriesData[types.ContractFactoriesIndicator],
+				Stack: "factories",
+				Type:  "line",
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func AAUserOperationsChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	rows := []struct {
+		Day       time.Time `db:"time"`
+		Indicator string    `db:"indicator"`
+		Value     float64   `db:"value"`
+	}{}
+
+	epoch := LatestEpoch()
+	if epoch > 0 {
+		epoch--
+	}
+	ts := utils.EpochToTime(epoch)
+
+	err := db.ReaderDb.Select(&rows, "SELECT time, indicator, value FROM chart_series WHERE time < $1 and indicator = ANY($2) ORDER BY time", ts, pq.Array(types.AAUserOpsIndicators))
+	if err != nil {
+		return nil, err
+	}
+
+	seriesData := map[string][][]float64{}
+	for _, indicator := range types.AAUserOpsIndicators {
+		seriesData[indicator] = [][]float64{}
+	}
+
+	for _, row := range rows {
+		seriesData[row.Indicator] = append(seriesData[row.Indicator], []float64{
+			float64(row.Day.UnixMilli()),
+			row.Value,
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "User Operations",
+		Subtitle:                        "Daily number of ERC-4337 user operations by who paid for the gas",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "User Ops [#]",
+		StackingMode:                    "normal",
+		Type:                            "area",
+		ColumnDataGroupingApproximation: "sum",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Sponsored by Paymaster",
+				Data: seriesData[types.AAUserOpsSponsoredIndicator],
+			},
+			{
+				Name: "Paid by Account",
+				Data: seriesData[types.AAUserOpsSelfPaidIndicator],
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+func GasPriceHistoryChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	history, err := db.GetGasPriceHistory(90 * 24)
+	if err != nil {
+		return nil, err
+	}
+
+	p10 := make([][]float64, 0, len(history))
+	p50 := make([][]float64, 0, len(history))
+	p90 := make([][]float64, 0, len(history))
+	for _, h := range history {
+		ts := float64(h.Hour.UnixMilli())
+		p10 = append(p10, []float64{ts, float64(h.P10) / 1e9})
+		p50 = append(p50, []float64{ts, float64(h.P50) / 1e9})
+		p90 = append(p90, []float64{ts, float64(h.P90) / 1e9})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Gas Price History",
+		Subtitle:                        "Hourly 10th, 50th and 90th percentile of the gas prices paid by transactions.",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Gas Price [GWei]",
+		StackingMode:                    "false",
+		Type:                            "line",
+		ColumnDataGroupingApproximation: "average",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "10th Percentile",
+				Data: p10,
+			},
+			{
+				Name: "Median",
+				Data: p50,
+			},
+			{
+				Name: "90th Percentile",
+				Data: p90,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
+// TotalBurnedFeesChartData shows the cumulative burn of the aggregation maintained by the eth1 indexer
+func TotalBurnedFeesChartData() (*types.GenericChartData, error) {
+	if LatestEpoch() == 0 {
+		return nil, fmt.Errorf("chart-data not available pre-genesis")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+	burned, err := db.BigtableClient.GetBurnedFees(ctx, math.MaxInt32)
+	if err != nil {
+		return nil, err
+	}
+
+	// the days only cover the blocks indexed since the aggregation started, the cumulative series is anchored at the total
+	cumulative := burned.Total.In(types.Ether)
+	for _, day := range burned.Days {
+		cumulative = cumulative.Sub(day.BurnedFees.In(types.Ether))
+	}
+	seriesData := make([][]float64, 0, len(burned.Days))
+	for _, day := range burned.Days {
+		cumulative = cumulative.Add(day.BurnedFees.In(types.Ether))
+		seriesData = append(seriesData, []float64{
+			float64(day.Day.UnixMilli()),
+			cumulative.InexactFloat64(),
+		})
+	}
+
+	chartData := &types.GenericChartData{
+		Title:                           "Total Burned Fees",
+		Subtitle:                        "Cumulative number of Ether burned with EIP 1559 at the end of each day",
+		XAxisTitle:                      "",
+		YAxisTitle:                      "Burned Fees [ETH]",
+		StackingMode:                    "false",
+		Type:                            "line",
+		ColumnDataGroupingApproximation: "close",
+		Series: []*types.GenericChartDataSeries{
+			{
+				Name: "Total Burned Fees",
+				Data: seriesData,
+			},
+		},
+	}
+
+	return chartData, nil
+}
+
 func AvgBlockSizeChartData() (*types.GenericChartData, error) {
 	return nil, fmt.Errorf("unimplemented")
 }
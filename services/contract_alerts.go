@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"time"
+)
+
+// maximum number of calls or reverts that are read for a contract alert, larger numbers are reported as lower bound
+const contractAlertReadLimit = 1000
+
+type contractAlertNotification struct {
+	SubscriptionID  uint64
+	UserID          uint64
+	Epoch           uint64
+	AlertID         uint64
+	Kind            string
+	Contract        []byte
+	Method          string
+	Count           uint64
+	LatestTx        []byte
+	UnsubscribeHash sql.NullString
+}
+
+func (n *contractAlertNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *contractAlertNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *contractAlertNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *contractAlertNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *contractAlertNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *contractAlertNotification) GetEventName() types.EventName {
+	return types.ContractInteractionEventName
+}
+
+func (n *contractAlertNotification) count() string {
+	if n.Count >= contractAlertReadLimit {
+		return fmt.Sprintf("more than %v", contractAlertReadLimit)
+	}
+	return fmt.Sprintf("%v", n.Count)
+}
+
+func (n *contractAlertNotification) GetInfo(includeUrl bool) string {
+	contract := utils.FixAddressCasing(fmt.Sprintf("%x", n.Contract))
+	var generalPart string
+	if n.Kind == types.ContractAlertRevertRate {
+		generalPart = fmt.Sprintf(`%v transactions to your contract %v reverted within the last hour.`, n.count(), contract)
+	} else {
+		generalPart = fmt.Sprintf(`The method %v of your contract %v has been called %v times, the latest call is 0x%x.`, n.Method, contract, n.count(), n.LatestTx)
+	}
+	if includeUrl {
+		if len(n.LatestTx) > 0 {
+			return generalPart + fmt.Sprintf(` Learn more at https://%v/tx/0x%x`, utils.Config.Frontend.SiteDomain, n.LatestTx)
+		}
+		return generalPart + fmt.Sprintf(` Learn more at https://%v/address/%v`, utils.Config.Frontend.SiteDomain, contract)
+	}
+	return generalPart
+}
+
+func (n *contractAlertNotification) GetTitle() string {
+	if n.Kind == types.ContractAlertRevertRate {
+		return "Contract Reverts"
+	}
+	return "Contract Called"
+}
+
+func (n *contractAlertNotification) GetEventFilter() string {
+	return fmt.Sprintf("%v", n.AlertID)
+}
+
+func (n *contractAlertNotification) GetInfoMarkdown() string {
+	return n.GetInfo(false) + fmt.Sprintf(` ([contract](https://%v/address/0x%x))`, utils.Config.Frontend.SiteDomain, n.Contract)
+}
+
+// collectContractAlertNotifications notifies verified contract owners about calls of the methods they subscribed to since their
+// last notification and about contracts whose reverted transactions within the last hour reached the threshold of the alert
+func collectContractAlertNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	alerts, err := db.GetDueContractAlerts(utils.GetNetwork())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, alert := range alerts {
+		n := &contractAlertNotification{
+			SubscriptionID:  alert.SubscriptionID,
+			UserID:          alert.UserID,
+			Epoch:           epoch,
+			AlertID:         alert.ID,
+			Kind:            alert.Kind,
+			Contract:        alert.Contract,
+			UnsubscribeHash: alert.UnsubscribeHash,
+		}
+
+		switch alert.Kind {
+		case types.ContractAlertMethodCalled:
+			// calls older than an hour are not notified, neither are the ones before the alert was created
+			since := now.Add(-time.Hour)
+			if alert.LastSent.Valid && alert.LastSent.Time.After(since) {
+				since = alert.LastSent.Time
+			}
+			if alert.CreatedTs.After(since) {
+				since = alert.CreatedTs
+			}
+			calls, err := db.BigtableClient.GetContractMethodCalls(context.Background(), alert.Contract, alert.MethodID, since, contractAlertReadLimit)
+			if err != nil && !db.IsPartialResult(err) {
+				// a single alert must not block the notifications of all other users
+				logger.WithError(err).Errorf("error getting calls of contract alert %v of user %v", alert.ID, alert.UserID)
+				continue
+			}
+			if len(calls) == 0 {
+				continue
+			}
+			n.Count = uint64(len(calls))
+			n.LatestTx = calls[0].Hash
+			n.Method = db.BigtableClient.GetMethodLabel(context.Background(), alert.MethodID, true)
+		case types.ContractAlertRevertRate:
+			n.Count, err = db.BigtableClient.CountContractReverts(context.Background(), alert.Contract, now.Add(-time.Hour), contractAlertReadLimit)
+			if err != nil {
+				logger.WithError(err).Errorf("error counting reverts of contract alert %v of user %v", alert.ID, alert.UserID)
+				continue
+			}
+			if n.Count < alert.RevertThreshold {
+				continue
+			}
+		default:
+			continue
+		}
+
+		if _, exists := notificationsByUserID[alert.UserID]; !exists {
+			notificationsByUserID[alert.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[alert.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[alert.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[alert.UserID][n.GetEventName()] = append(notificationsByUserID[alert.UserID][n.GetEventName()], n)
+		metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/utils"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// edgeCachePurgeBatch is the maximum number of surrogate keys purged per request
+const edgeCachePurgeBatch = 256
+
+// edgeCachePurgeMaxBlocks limits the blocks purged per index run, the responses touched by the blocks indexed while catching
+// up expire with the max age of the edge cache
+const edgeCachePurgeMaxBlocks = 100
+
+var edgeCacheClient = &http.Client{Timeout: time.Second * 10}
+
+// EdgeCachePurgeEnabled returns true if cached responses are purged from the cdn
+func EdgeCachePurgeEnabled() bool {
+	return utils.Config.Frontend.EdgeCache.Enabled && utils.Config.Frontend.EdgeCache.PurgeURL != ""
+}
+
+// PurgeEdgeCache purges the responses tagged with any of the surrogate keys from the cdn
+func PurgeEdgeCache(ctx context.Context, keys []string) error {
+	if !EdgeCachePurgeEnabled() {
+		return nil
+	}
+	for start := 0; start < len(keys); start += edgeCachePurgeBatch {
+		end := start + edgeCachePurgeBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		body, err := json.Marshal(map[string][]string{"keys": keys[start:end]})
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, utils.Config.Frontend.EdgeCache.PurgeURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if utils.Config.Frontend.EdgeCache.PurgeToken != "" {
+			req.Header.Set("Authorization", "Bearer "+utils.Config.Frontend.EdgeCache.PurgeToken)
+		}
+		resp, err := edgeCacheClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("error purging surrogate keys: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("error purging surrogate keys: unexpected status %v", resp.Status)
+		}
+	}
+	return nil
+}
+
+// PurgeEdgeCacheBlocks purges the cached responses of the blocks from (inclusive) to to (inclusive) and of the addresses
+// they touch, only the last edgeCachePurgeMaxBlocks blocks of a larger range are purged
+func PurgeEdgeCacheBlocks(ctx context.Context, bt *db.Bigtable, from, to uint64) error {
+	if to >= edgeCachePurgeMaxBlocks && from < to-edgeCachePurgeMaxBlocks+1 {
+		from = to - edgeCachePurgeMaxBlocks + 1
+	}
+	keys := []string{}
+	seen := make(map[string]bool)
+	for number := from; number <= to; number++ {
+		block, err := bt.GetBlockFromBlocksTable(ctx, number)
+		if err != nil {
+			return fmt.Errorf("error reading block %v: %w", number, err)
+		}
+		blockKeys, err := db.EdgeCacheBlockKeys(block)
+		if err != nil {
+			return fmt.Errorf("error collecting the surrogate keys of block %v: %w", number, err)
+		}
+		for _, key := range blockKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return PurgeEdgeCache(ctx, keys)
+}
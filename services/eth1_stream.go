@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// The eth1 indexer publishes each newly indexed block to a redis channel, the frontends relay the blocks to the websocket
+// clients subscribed to /ws. Every frontend receives every block, the subscriptions of its clients are matched locally.
+
+// eth1StreamMaxBlocks limits the blocks published per index run, the stream is meant for live updates and not to replay the
+// blocks indexed while catching up
+const eth1StreamMaxBlocks = 100
+
+// eth1StreamSubscriberBuffer is the number of blocks a subscriber may fall behind before it is dropped
+const eth1StreamSubscriberBuffer = 16
+
+func eth1StreamChannel() string {
+	return fmt.Sprintf("%d:eth1:stream", utils.Config.Chain.Config.DepositChainID)
+}
+
+// NewEth1StreamClient connects to the redis instance configured as cache, which is shared by the indexer and the frontends
+func NewEth1StreamClient(ctx context.Context) (*redis.Client, error) {
+	if utils.Config.RedisCacheEndpoint == "" {
+		return nil, fmt.Errorf("the eth1 stream requires a redis cache endpoint")
+	}
+	rdc := redis.NewClient(&redis.Options{
+		Addr:        utils.Config.RedisCacheEndpoint,
+		ReadTimeout: time.Second * 20,
+	})
+	if err := rdc.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+	return rdc, nil
+}
+
+// PublishEth1StreamBlocks publishes the blocks from (inclusive) to to (inclusive) to the frontends, only the last
+// eth1StreamMaxBlocks blocks of a larger range are published
+func PublishEth1StreamBlocks(ctx context.Context, rdc *redis.Client, bt *db.Bigtable, from, to uint64) error {
+	if to >= eth1StreamMaxBlocks && from < to-eth1StreamMaxBlocks+1 {
+		from = to - eth1StreamMaxBlocks + 1
+	}
+	for number := from; number <= to; number++ {
+		block, err := bt.GetBlockFromBlocksTable(ctx, number)
+		if err != nil {
+			return fmt.Errorf("error reading block %v: %w", number, err)
+		}
+		streamBlock, err := db.Eth1StreamBlockFromBlock(block)
+		if err != nil {
+			return fmt.Errorf("error extracting the activity of block %v: %w", number, err)
+		}
+		message, err := json.Marshal(streamBlock)
+		if err != nil {
+			return err
+		}
+		err = rdc.Publish(ctx, eth1StreamChannel(), message).Err()
+		if err != nil {
+			return fmt.Errorf("error publishing block %v: %w", number, err)
+		}
+	}
+	return nil
+}
+
+var eth1StreamSubscribers = make(map[chan *types.Eth1StreamBlock]bool)
+var eth1StreamSubscribersMux = &sync.Mutex{}
+
+// SubscribeEth1Stream returns a channel receiving the newly indexed blocks. The channel is closed by UnsubscribeEth1Stream or
+// once the subscriber has fallen eth1StreamSubscriberBuffer blocks behind.
+func SubscribeEth1Stream() chan *types.Eth1StreamBlock {
+	blocks := make(chan *types.Eth1StreamBlock, eth1StreamSubscriberBuffer)
+	eth1StreamSubscribersMux.Lock()
+	defer eth1StreamSubscribersMux.Unlock()
+	eth1StreamSubscribers[blocks] = true
+	return blocks
+}
+
+// UnsubscribeEth1Stream removes a subscriber, a subscriber that has already been dropped is ignored
+func UnsubscribeEth1Stream(blocks chan *types.Eth1StreamBlock) {
+	eth1StreamSubscribersMux.Lock()
+	defer eth1StreamSubscribersMux.Unlock()
+	if eth1StreamSubscribers[blocks] {
+		delete(eth1StreamSubscribers, blocks)
+		close(blocks)
+	}
+}
+
+func relayEth1StreamBlock(block *types.Eth1StreamBlock) {
+	eth1StreamSubscribersMux.Lock()
+	defer eth1StreamSubscribersMux.Unlock()
+	for blocks := range eth1StreamSubscribers {
+		select {
+		case blocks <- block:
+		default:
+			// a slow subscriber must not hold up the others
+			delete(eth1StreamSubscribers, blocks)
+			close(blocks)
+		}
+	}
+}
+
+// InitEth1Stream starts relaying the blocks published by the eth1 indexer to the subscribers of the frontend
+func InitEth1Stream() {
+	rdc, err := NewEth1StreamClient(context.Background())
+	if err != nil {
+		logger.Fatalf("error connecting to the eth1 stream: %v", err)
+	}
+	go func() {
+		// the channel of the subscription reconnects on its own and is only closed with the subscription
+		pubsub := rdc.Subscribe(context.Background(), eth1StreamChannel())
+		defer pubsub.Close()
+		for message := range pubsub.Channel() {
+			block := &types.Eth1StreamBlock{}
+			err := json.Unmarshal([]byte(message.Payload), block)
+			if err != nil {
+				logger.Errorf("error decoding eth1 stream block: %v", err)
+				continue
+			}
+			relayEth1StreamBlock(block)
+		}
+		logger.Errorf("eth1 stream subscription closed")
+	}()
+}
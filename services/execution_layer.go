@@ -1,8 +1,11 @@
 package services
 
 import (
+	"context"
+	"database/sql"
 	"eth2-exporter/cache"
 	"eth2-exporter/db"
+	"eth2-exporter/types"
 	"eth2-exporter/utils"
 	"fmt"
 	"sync"
@@ -14,7 +17,7 @@ func latestBlockUpdater(wg *sync.WaitGroup) {
 	firstRun := true
 
 	for {
-		recent, err := db.BigtableClient.GetMostRecentBlockFromDataTable()
+		recent, err := db.BigtableClient.GetMostRecentBlockFromDataTable(context.Background())
 		if err != nil {
 			logger.WithError(err).Error("error getting most recent eth1 block")
 		}
@@ -45,3 +48,91 @@ func LatestEth1BlockNumber() uint64 {
 	}
 	return 0
 }
+
+// finalityCheckpointsUpdater maps the latest justified and finalized checkpoints of the beacon chain to the execution blocks they contain
+func finalityCheckpointsUpdater(wg *sync.WaitGroup) {
+	firstRun := true
+
+	for {
+		checkpoints, err := getEth1FinalityCheckpoints()
+		if err != nil {
+			logger.Errorf("error retrieving eth1 finality checkpoints: %v", err)
+		} else {
+			cacheKey := fmt.Sprintf("%d:frontend:eth1FinalityCheckpoints", utils.Config.Chain.Config.DepositChainID)
+			err = cache.TieredCache.Set(cacheKey, checkpoints, time.Hour*24)
+			if err != nil {
+				logger.Errorf("error caching eth1 finality checkpoints: %v", err)
+			}
+		}
+
+		if firstRun {
+			logger.Info("initialized eth1 finality checkpoints updater")
+			wg.Done()
+			firstRun = false
+		}
+		ReportStatus("finalityCheckpointsUpdater", "Running", nil)
+		time.Sleep(time.Second * 12)
+	}
+}
+
+func getEth1FinalityCheckpoints() (*types.Eth1FinalityCheckpoints, error) {
+	liveness := struct {
+		JustifiedEpoch uint64 `db:"justifiedepoch"`
+		FinalizedEpoch uint64 `db:"finalizedepoch"`
+	}{}
+	err := db.ReaderDb.Get(&liveness, "SELECT justifiedepoch, finalizedepoch FROM network_liveness ORDER BY headepoch DESC LIMIT 1")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving latest checkpoints: %w", err)
+	}
+
+	checkpoints := &types.Eth1FinalityCheckpoints{
+		SafeEpoch:      liveness.JustifiedEpoch,
+		FinalizedEpoch: liveness.FinalizedEpoch,
+	}
+	checkpoints.SafeBlockNumber, checkpoints.SafeBlockHash, err = getCheckpointExecutionBlock(liveness.JustifiedEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving execution block of justified epoch %v: %w", liveness.JustifiedEpoch, err)
+	}
+	checkpoints.FinalizedBlockNumber, checkpoints.FinalizedBlockHash, err = getCheckpointExecutionBlock(liveness.FinalizedEpoch)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving execution block of finalized epoch %v: %w", liveness.FinalizedEpoch, err)
+	}
+	return checkpoints, nil
+}
+
+// getCheckpointExecutionBlock returns the execution block of the checkpoint block of an epoch, which is the last proposed block at or before the first slot of the epoch
+func getCheckpointExecutionBlock(epoch uint64) (uint64, []byte, error) {
+	block := struct {
+		Number uint64 `db:"exec_block_number"`
+		Hash   []byte `db:"exec_block_hash"`
+	}{}
+	err := db.ReaderDb.Get(&block, `
+		SELECT exec_block_number, exec_block_hash
+		FROM blocks
+		WHERE slot <= $1 AND status = '1' AND exec_block_number > 0
+		ORDER BY slot DESC
+		LIMIT 1`, epoch*utils.Config.Chain.Config.SlotsPerEpoch)
+	if err == sql.ErrNoRows {
+		// the checkpoint is before the merge
+		return 0, nil, nil
+	}
+	return block.Number, block.Hash, err
+}
+
+// LatestEth1FinalityCheckpoints returns the execution blocks of the latest safe and finalized checkpoints
+func LatestEth1FinalityCheckpoints() *types.Eth1FinalityCheckpoints {
+	wanted := &types.Eth1FinalityCheckpoints{}
+	cacheKey := fmt.Sprintf("%d:frontend:eth1FinalityCheckpoints", utils.Config.Chain.Config.DepositChainID)
+
+	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Second*5, wanted); err == nil {
+		return wanted.(*types.Eth1FinalityCheckpoints)
+	} else {
+		logger.Errorf("error retrieving eth1FinalityCheckpoints from cache: %v", err)
+	}
+	return &types.Eth1FinalityCheckpoints{}
+}
+
+// Eth1BlockFinality returns whether an execution block is finalized, safe or neither
+func Eth1BlockFinality(number uint64) types.Eth1BlockFinality {
+	return LatestEth1FinalityCheckpoints().Finality(number)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/hex"
+	"eth2-exporter/db"
+	"eth2-exporter/metrics"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type feeRecipientMismatchNotification struct {
+	SubscriptionID  uint64
+	ValidatorIndex  uint64
+	Epoch           uint64
+	Slot            uint64
+	FeeRecipient    []byte
+	Expected        [][]byte
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *feeRecipientMismatchNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *feeRecipientMismatchNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *feeRecipientMismatchNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *feeRecipientMismatchNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *feeRecipientMismatchNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *feeRecipientMismatchNotification) GetEventName() types.EventName {
+	return types.ValidatorFeeRecipientMismatchEventName
+}
+
+// expectedList returns the expected fee recipients in checksum format
+func (n *feeRecipientMismatchNotification) expectedList() string {
+	list := ""
+	for i, recipient := range n.Expected {
+		if i > 0 {
+			list += ", "
+		}
+		list += common.BytesToAddress(recipient).Hex()
+	}
+	return list
+}
+
+func (n *feeRecipientMismatchNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`Validator %v proposed the block of slot %v paying the fee recipient %v instead of %v.`, n.ValidatorIndex, n.Slot, common.BytesToAddress(n.FeeRecipient).Hex(), n.expectedList())
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *feeRecipientMismatchNotification) GetTitle() string {
+	return "Fee Recipient Mismatch"
+}
+
+func (n *feeRecipientMismatchNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *feeRecipientMismatchNotification) GetInfoMarkdown() string {
+	generalPart := fmt.Sprintf(`Validator [%[1]v](https://%[5]v/validator/%[1]v) proposed the block of slot [%[2]v](https://%[5]v/slot/%[2]v) paying the fee recipient `+"`%[3]v`"+` instead of `+"`%[4]v`"+`.`, n.ValidatorIndex, n.Slot, common.BytesToAddress(n.FeeRecipient).Hex(), n.expectedList(), utils.Config.Frontend.SiteDomain)
+	return generalPart
+}
+
+// collectFeeRecipientMismatchNotifications collects notifications for the proposals of an epoch that paid a fee recipient the subscribed
+// user has not configured as expected. Users without expected fee recipients are not notified.
+func collectFeeRecipientMismatchNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorFeeRecipientMismatchEventName)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for fee recipient mismatches %w", err)
+	}
+	if len(subMap) == 0 {
+		return nil
+	}
+
+	expectedByUser, err := db.GetFeeRecipientsByUser(utils.GetNetwork())
+	if err != nil {
+		return err
+	}
+
+	proposals, err := db.GetEpochProposalFeeRecipients(epoch)
+	if err != nil {
+		return fmt.Errorf("error getting proposal fee recipients from database, err: %w", err)
+	}
+
+	for _, proposal := range proposals {
+		subscribers, ok := subMap[hex.EncodeToString(proposal.Pubkey)]
+		if !ok {
+			continue
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId or subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= epoch || epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+			expected := expectedByUser[*sub.UserID]
+			if len(expected) == 0 || isExpectedFeeRecipient(proposal.FeeRecipient(), expected) {
+				continue
+			}
+
+			n := &feeRecipientMismatchNotification{
+				SubscriptionID:  *sub.ID,
+				ValidatorIndex:  proposal.ValidatorIndex,
+				Epoch:           epoch,
+				Slot:            proposal.Slot,
+				FeeRecipient:    proposal.FeeRecipient(),
+				Expected:        expected,
+				EventFilter:     hex.EncodeToString(proposal.Pubkey),
+				UnsubscribeHash: sub.UnsubscribeHash,
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsByUserID[*sub.UserID][n.GetEventName()]; !exists {
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = []types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+			metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+		}
+	}
+
+	return nil
+}
+
+func isExpectedFeeRecipient(recipient []byte, expected [][]byte) bool {
+	for _, address := range expected {
+		if bytes.Equal(recipient, address) {
+			return true
+		}
+	}
+	return false
+}
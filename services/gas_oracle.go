@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/price"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sync"
+	"time"
+)
+
+func gasOracleBlocks() int {
+	if utils.Config.Frontend.GasOracle.Blocks > 0 {
+		return utils.Config.Frontend.GasOracle.Blocks
+	}
+	return 20
+}
+
+func gasOracleInterval() time.Duration {
+	if utils.Config.Frontend.GasOracle.Interval > 0 {
+		return utils.Config.Frontend.GasOracle.Interval
+	}
+	return time.Millisecond * 500
+}
+
+// gasOracleUpdater replaces the gasNowUpdater if the gas oracle is enabled. It polls the data table for new blocks and
+// recomputes the estimates once per block, the estimates are cached with the poll interval as local timeout so that the api
+// serves a new block within a second.
+func gasOracleUpdater(wg *sync.WaitGroup) {
+	firstRun := true
+	lastBlock := 0
+	lastHistory := time.Time{}
+
+	for {
+		time.Sleep(gasOracleInterval())
+
+		latest, err := db.BigtableClient.GetLastBlockInDataTable(context.Background())
+		if err != nil {
+			logger.Warnf("error getting the latest block of the gas oracle: %v", err)
+			continue
+		}
+		if latest == lastBlock {
+			continue
+		}
+
+		data, err := getGasOracleData(uint64(latest))
+		if err != nil {
+			logger.Warnf("error retrieving gas oracle data: %v", err)
+			continue
+		}
+		lastBlock = latest
+
+		cacheKey := fmt.Sprintf("%d:frontend:gasNow", utils.Config.Chain.Config.DepositChainID)
+		err = cache.TieredCache.Set(cacheKey, data, time.Hour*24)
+		if err != nil {
+			logger.Errorf("error caching gas oracle data: %v", err)
+		}
+
+		// the history has a resolution of one minute
+		if time.Since(lastHistory) > time.Minute {
+			err = db.BigtableClient.SaveGasNowHistory(context.Background(), data.Data.Slow, data.Data.Standard, data.Data.Fast, data.Data.Rapid)
+			if err != nil {
+				logger.WithError(err).Error("error updating gas now history")
+			} else {
+				lastHistory = time.Now()
+			}
+		}
+
+		if firstRun {
+			wg.Done()
+			firstRun = false
+		}
+	}
+}
+
+// getGasOracleData estimates the gas prices from the blocks up to the latest block of the data table
+func getGasOracleData(latest uint64) (*types.GasNowPageData, error) {
+	count := uint64(gasOracleBlocks())
+	if latest <= count {
+		return nil, fmt.Errorf("not enough blocks indexed for the gas oracle")
+	}
+
+	// the low block of the range is exclusive
+	stream := make(chan *types.Eth1Block, count)
+	err := db.BigtableClient.GetFullBlocksDescending(context.Background(), stream, latest, latest-count)
+	close(stream)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*types.Eth1Block, 0, count)
+	for block := range stream {
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 || blocks[0].GetNumber() != latest {
+		return nil, fmt.Errorf("block %v has not been stored yet", latest)
+	}
+
+	oracle, err := db.GasOracleFromBlocks(blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	data := &types.GasNowPageData{}
+	data.Code = 200
+	data.Data.Timestamp = time.Now().UnixNano() / 1e6
+	data.Data.Slow = oracle.Slow
+	data.Data.Standard = oracle.Standard
+	data.Data.Fast = oracle.Fast
+	data.Data.Rapid = oracle.Rapid
+	data.Data.Block = oracle.Block
+	data.Data.BaseFee = oracle.BaseFee
+	data.Data.NextBaseFee = oracle.NextBaseFee
+	data.Data.BaseFeeTrend = oracle.BaseFeeTrend
+	data.Data.Price = price.GetEthPrice("USD")
+	data.Data.Currency = "USD"
+	return data, nil
+}
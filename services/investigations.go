@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/db"
+	"time"
+)
+
+const (
+	// limits of searches executed as a background job
+	transferPathJobMaxAddresses = 20000
+	transferPathJobMaxPaths     = 100
+	transferPathJobTimeout      = time.Minute * 15
+)
+
+// transferPathJobsProcessor picks up pending transfer path searches and executes them one after another
+func transferPathJobsProcessor() {
+	for {
+		job, err := db.ClaimPendingTransferPathJob()
+		if err != nil {
+			logger.Errorf("error claiming transfer path job: %v", err)
+			time.Sleep(time.Second * 30)
+			continue
+		}
+		if job == nil {
+			time.Sleep(time.Second * 5)
+			continue
+		}
+
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), transferPathJobTimeout)
+		result, err := db.BigtableClient.FindTransferPaths(ctx, job.Search(), transferPathJobMaxAddresses, transferPathJobMaxPaths)
+		cancel()
+		if err != nil {
+			logger.Errorf("error executing transfer path job %v: %v", job.ID, err)
+			err = db.FailTransferPathJob(job.ID, err)
+			if err != nil {
+				logger.Errorf("error marking transfer path job %v as failed: %v", job.ID, err)
+			}
+			continue
+		}
+
+		err = db.CompleteTransferPathJob(job.ID, result)
+		if err != nil {
+			logger.Errorf("error saving result of transfer path job %v: %v", job.ID, err)
+			continue
+		}
+		logger.Infof("completed transfer path job %v, found %v paths visiting %v addresses in %v", job.ID, len(result.Paths), result.VisitedAddresses, time.Since(start))
+	}
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	geth_rpc "github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// pending transactions are fetched in batches of hashes received from the subscription
+	mempoolWatcherBatchSize     = 100
+	mempoolWatcherBatchInterval = time.Millisecond * 250
+	// new transactions are ignored once the pool is full until transactions are included or expire
+	mempoolWatcherMaxTxs = 100000
+	// blocks behind the last processed one are only checked for included transactions up to this gap, older transactions expire
+	mempoolWatcherMaxBlockGap = 64
+	mempoolWatcherSnapshot    = time.Second * 5
+	// pending transactions listed on the page of an address
+	mempoolAddressLimit = 100
+)
+
+func mempoolWatcherTTL() time.Duration {
+	if utils.Config.Frontend.MempoolWatcher.TTL > 0 {
+		return utils.Config.Frontend.MempoolWatcher.TTL
+	}
+	return time.Minute * 30
+}
+
+func mempoolWatcherEndpoint() string {
+	if utils.Config.Frontend.MempoolWatcher.Endpoint != "" {
+		return utils.Config.Frontend.MempoolWatcher.Endpoint
+	}
+	return utils.Config.Eth1GethEndpoint
+}
+
+type pendingTx struct {
+	tx   *types.RawMempoolTransaction
+	seen time.Time
+}
+
+// mempool holds the pending transactions received by the watcher, indexed by hash and by the addresses of sender and recipient
+type mempool struct {
+	mu        sync.RWMutex
+	txs       map[common.Hash]*pendingTx
+	byAddress map[common.Address]map[common.Hash]bool
+}
+
+var watchedMempool = &mempool{
+	txs:       make(map[common.Hash]*pendingTx),
+	byAddress: make(map[common.Address]map[common.Hash]bool),
+}
+
+func (m *mempool) add(tx *types.RawMempoolTransaction, seen time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if tx.From == nil || tx.Nonce == nil {
+		return
+	}
+	if _, exists := m.txs[tx.Hash]; exists || len(m.txs) >= mempoolWatcherMaxTxs {
+		return
+	}
+	if tx.GasPrice == nil {
+		tx.GasPrice = tx.GasFeeCap
+	}
+	m.txs[tx.Hash] = &pendingTx{tx: tx, seen: seen}
+	for _, address := range []*common.Address{tx.From, tx.To} {
+		if address == nil {
+			continue
+		}
+		if m.byAddress[*address] == nil {
+			m.byAddress[*address] = make(map[common.Hash]bool)
+		}
+		m.byAddress[*address][tx.Hash] = true
+	}
+}
+
+// remove has to be called with the lock held
+func (m *mempool) remove(hash common.Hash) {
+	p := m.txs[hash]
+	if p == nil {
+		return
+	}
+	delete(m.txs, hash)
+	for _, address := range []*common.Address{p.tx.From, p.tx.To} {
+		if address == nil {
+			continue
+		}
+		delete(m.byAddress[*address], hash)
+		if len(m.byAddress[*address]) == 0 {
+			delete(m.byAddress, *address)
+		}
+	}
+}
+
+// removeIncluded removes the transactions of a block together with all pending transactions of their senders with the same or a
+// lower nonce, those have been replaced and can not be included anymore
+func (m *mempool) removeIncluded(block *types.Eth1Block) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	before := len(m.txs)
+	for _, tx := range block.GetTransactions() {
+		m.remove(common.BytesToHash(tx.GetHash()))
+
+		from := common.BytesToAddress(tx.GetFrom())
+		for hash := range m.byAddress[from] {
+			p := m.txs[hash]
+			if *p.tx.From == from && p.tx.Nonce.ToInt().Uint64() <= tx.GetNonce() {
+				m.remove(hash)
+			}
+		}
+	}
+	return before - len(m.txs)
+}
+
+func (m *mempool) expire(before time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	expired := 0
+	for hash, p := range m.txs {
+		if p.seen.Before(before) {
+			m.remove(hash)
+			expired++
+		}
+	}
+	return expired
+}
+
+// snapshot returns the pending transactions in the format of the txpool_content response of the node
+func (m *mempool) snapshot() *types.RawMempoolResponse {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := &types.RawMempoolResponse{
+		Pending:   make(map[string]map[int]*types.RawMempoolTransaction),
+		TxsByHash: make(map[common.Hash]*types.RawMempoolTransaction, len(m.txs)),
+	}
+	for hash, p := range m.txs {
+		res.TxsByHash[hash] = p.tx
+		from := p.tx.From.Hex()
+		if res.Pending[from] == nil {
+			res.Pending[from] = make(map[int]*types.RawMempoolTransaction)
+		}
+		res.Pending[from][int(p.tx.Nonce.ToInt().Int64())] = p.tx
+	}
+	return res
+}
+
+func (m *mempool) ofAddress(address common.Address) []*types.RawMempoolTransaction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	txs := make([]*types.RawMempoolTransaction, 0, len(m.byAddress[address]))
+	for hash := range m.byAddress[address] {
+		txs = append(txs, m.txs[hash].tx)
+	}
+	return txs
+}
+
+// mempoolWatcher replaces the mempoolUpdater if the mempool watcher is enabled. It subscribes to the pending transactions of the
+// node and removes them once they are included in a block of the data table or expire.
+func mempoolWatcher(wg *sync.WaitGroup) {
+	go subscribePendingTransactions()
+
+	firstRun := true
+	lastBlock := 0
+	lastSnapshot := time.Time{}
+
+	for {
+		latest, err := db.BigtableClient.GetLastBlockInDataTable(context.Background())
+		if err != nil {
+			logger.Warnf("error getting the latest block of the mempool watcher: %v", err)
+		} else if lastBlock == 0 {
+			lastBlock = latest
+		} else if latest > lastBlock {
+			low := lastBlock
+			if latest-low > mempoolWatcherMaxBlockGap {
+				low = latest - mempoolWatcherMaxBlockGap
+			}
+			included, err := removeIncludedTransactions(uint64(latest), uint64(low))
+			if err != nil {
+				logger.Warnf("error removing the included transactions of blocks %v to %v from the mempool: %v", low+1, latest, err)
+			} else {
+				logger.Debugf("removed %v included transactions of blocks %v to %v from the mempool", included, low+1, latest)
+				lastBlock = latest
+			}
+		}
+
+		if time.Since(lastSnapshot) >= mempoolWatcherSnapshot {
+			watchedMempool.expire(time.Now().Add(-mempoolWatcherTTL()))
+
+			cacheKey := fmt.Sprintf("%d:frontend:mempool", utils.Config.Chain.Config.DepositChainID)
+			err = cache.TieredCache.Set(cacheKey, watchedMempool.snapshot(), mempoolWatcherTTL())
+			if err != nil {
+				logger.Errorf("error caching mempool data: %v", err)
+			}
+			lastSnapshot = time.Now()
+
+			if firstRun {
+				logger.Info("initialized mempool watcher")
+				wg.Done()
+				firstRun = false
+			}
+			ReportStatus("mempoolUpdater", "Running", nil)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// removeIncludedTransactions removes the transactions included in the blocks after low up to high from the mempool
+func removeIncludedTransactions(high, low uint64) (int, error) {
+	stream := make(chan *types.Eth1Block, high-low)
+	err := db.BigtableClient.GetFullBlocksDescending(context.Background(), stream, high, low)
+	close(stream)
+	if err != nil {
+		return 0, err
+	}
+	included := 0
+	for block := range stream {
+		included += watchedMempool.removeIncluded(block)
+	}
+	return included, nil
+}
+
+// subscribePendingTransactions adds the transactions announced by the node to the mempool, it reconnects if the subscription fails
+func subscribePendingTransactions() {
+	for {
+		err := watchPendingTransactions(context.Background())
+		logger.Errorf("error watching pending transactions, reconnecting: %v", err)
+		time.Sleep(time.Second * 10)
+	}
+}
+
+func watchPendingTransactions(ctx context.Context) error {
+	client, err := geth_rpc.DialContext(ctx, mempoolWatcherEndpoint())
+	if err != nil {
+		return fmt.Errorf("error connecting to %v: %w", mempoolWatcherEndpoint(), err)
+	}
+	defer client.Close()
+
+	hashes := make(chan common.Hash, mempoolWatcherBatchSize*10)
+	sub, err := client.EthSubscribe(ctx, hashes, "newPendingTransactions")
+	if err != nil {
+		return fmt.Errorf("error subscribing to pending transactions: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	ticker := time.NewTicker(mempoolWatcherBatchInterval)
+	defer ticker.Stop()
+
+	batch := make([]common.Hash, 0, mempoolWatcherBatchSize)
+	for {
+		select {
+		case err := <-sub.Err():
+			return err
+		case hash := <-hashes:
+			batch = append(batch, hash)
+			if len(batch) < mempoolWatcherBatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		err = addPendingTransactions(ctx, client, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+	}
+}
+
+func addPendingTransactions(ctx context.Context, client *geth_rpc.Client, hashes []common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	reqs := make([]geth_rpc.BatchElem, len(hashes))
+	txs := make([]*types.RawMempoolTransaction, len(hashes))
+	for i, hash := range hashes {
+		reqs[i] = geth_rpc.BatchElem{
+			Method: "eth_getTransactionByHash",
+			Args:   []interface{}{hash},
+			Result: &txs[i],
+		}
+	}
+	err := client.BatchCallContext(ctx, reqs)
+	if err != nil {
+		return fmt.Errorf("error retrieving pending transactions: %w", err)
+	}
+
+	now := time.Now()
+	for i, req := range reqs {
+		// the transaction may have been dropped or included in the meantime
+		if req.Error != nil || txs[i] == nil || txs[i].TransactionIndex != nil {
+			continue
+		}
+		watchedMempool.add(txs[i], now)
+	}
+	return nil
+}
+
+// PendingTransactionsOfAddress returns the pending transactions sent or received by an address ordered by sender and nonce. The
+// transactions are taken from the mempool watcher if it is enabled and from the cached txpool of the node otherwise.
+func PendingTransactionsOfAddress(address common.Address) []*types.RawMempoolTransaction {
+	var txs []*types.RawMempoolTransaction
+	if utils.Config.Frontend.MempoolWatcher.Enabled {
+		txs = watchedMempool.ofAddress(address)
+	} else {
+		for _, tx := range LatestMempoolTransactions().TxsByHash {
+			if tx.From == nil || tx.Nonce == nil {
+				continue
+			}
+			if *tx.From == address || (tx.To != nil && *tx.To == address) {
+				txs = append(txs, tx)
+			}
+		}
+	}
+
+	sort.Slice(txs, func(i, j int) bool {
+		if *txs[i].From != *txs[j].From {
+			return txs[i].From.Hex() < txs[j].From.Hex()
+		}
+		return txs[i].Nonce.ToInt().Cmp(txs[j].Nonce.ToInt()) < 0
+	})
+	if len(txs) > mempoolAddressLimit {
+		txs = txs[:mempoolAddressLimit]
+	}
+	return txs
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"eth2-exporter/db"
 	"eth2-exporter/utils"
 	"fmt"
@@ -19,10 +20,13 @@ func startMonitoringService(wg *sync.WaitGroup) {
 
 	go startClDataMonitoringService()
 	go startElDataMonitoringService()
+	go startApiWebhookService()
 	go startRedisMonitoringService()
 	go startApiMonitoringService()
 	go startAppMonitoringService()
 	go startServicesMonitoringService()
+	go startAnomalyDetectionService()
+	go startSyntheticProbeService()
 }
 
 // The cl data monitoring service will check that the data in the validators, blocks & epochs tables is up to date
@@ -96,13 +100,13 @@ func startElDataMonitoringService() {
 		firstRun = false
 
 		// check latest eth1 indexed block
-		numberBlocksTable, err := db.BigtableClient.GetLastBlockInBlocksTable()
+		numberBlocksTable, err := db.GetEth1Store().GetLastBlockInBlocksTable(context.Background())
 		if err != nil {
 			errorMsg := fmt.Errorf("error: could not retrieve latest block number from the blocks table: %v", err)
 			ReportStatus(name, errorMsg.Error(), nil)
 			continue
 		}
-		blockBlocksTable, err := db.BigtableClient.GetBlockFromBlocksTable(uint64(numberBlocksTable))
+		blockBlocksTable, err := db.GetEth1Store().GetBlockFromBlocksTable(context.Background(), uint64(numberBlocksTable))
 		if err != nil {
 			errorMsg := fmt.Errorf("error: could not retrieve latest block from the blocks table: %v", err)
 			ReportStatus(name, errorMsg.Error(), nil)
@@ -115,7 +119,7 @@ func startElDataMonitoringService() {
 		}
 
 		// check if eth1 indices are up to date
-		numberDataTable, err := db.BigtableClient.GetLastBlockInDataTable()
+		numberDataTable, err := db.GetEth1Store().GetLastBlockInDataTable(context.Background())
 		if err != nil {
 			errorMsg := fmt.Errorf("error: could not retrieve latest block number from the data table: %v", err)
 			ReportStatus(name, errorMsg.Error(), nil)
@@ -127,7 +131,21 @@ func startElDataMonitoringService() {
 			ReportStatus(name, errorMsg.Error(), nil)
 			continue
 		}
-		ReportStatus(name, "OK", nil)
+
+		// report the rows of blocks orphaned during the last day (~7200 blocks) that are hidden by tombstones
+		sinceBlock := uint64(0)
+		if numberBlocksTable > 7200 {
+			sinceBlock = uint64(numberBlocksTable - 7200)
+		}
+		var report *json.RawMessage
+		orphanedBlocks, tombstonedRows, err := db.GetEth1Store().GetTombstoneCounts(context.Background(), sinceBlock)
+		if err != nil {
+			logger.Errorf("error retrieving tombstone counts: %v", err)
+		} else {
+			raw := json.RawMessage(fmt.Sprintf(`{"orphanedBlocks":%d,"tombstonedRows":%d}`, orphanedBlocks, tombstonedRows))
+			report = &raw
+		}
+		ReportStatus(name, "OK", report)
 	}
 }
 
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"eth2-exporter/types"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var nodeCrawlCountryRE = regexp.MustCompile("^[A-Z]{2}$")
+
+// AggregateNodeCrawl condenses the nodes of a crawl into a daily snapshot of the client, version and country distribution
+func AggregateNodeCrawl(report *types.NodeCrawlReport) *types.NetworkNodeSnapshot {
+	crawledAt := report.CrawledAt.UTC()
+	if crawledAt.IsZero() {
+		crawledAt = time.Now().UTC()
+	}
+
+	snapshot := &types.NetworkNodeSnapshot{
+		Day:         crawledAt.Truncate(time.Hour * 24),
+		CrawledTime: crawledAt,
+		NodeCount:   uint64(len(report.Nodes)),
+		Clients:     types.NodeCounts{},
+		Versions:    types.NodeCounts{},
+		Countries:   types.NodeCounts{},
+	}
+
+	peers := make([]uint64, 0, len(report.Nodes))
+	totalPeers := uint64(0)
+	for _, node := range report.Nodes {
+		if node == nil {
+			continue
+		}
+		client := strings.ToLower(strings.TrimSpace(node.Client))
+		if client == "" {
+			client = "unknown"
+		}
+		version := strings.TrimSpace(node.Version)
+		if version == "" {
+			version = "unknown"
+		}
+		country := strings.ToUpper(strings.TrimSpace(node.Country))
+		if !nodeCrawlCountryRE.MatchString(country) {
+			country = "unknown"
+		}
+
+		snapshot.Clients[client]++
+		snapshot.Versions[client+"/"+version]++
+		snapshot.Countries[country]++
+		peers = append(peers, node.Peers)
+		totalPeers += node.Peers
+	}
+
+	if len(peers) > 0 {
+		sort.Slice(peers, func(i, j int) bool { return peers[i] < peers[j] })
+		snapshot.AvgPeerCount = float64(totalPeers) / float64(len(peers))
+		if len(peers)%2 == 0 {
+			snapshot.MedianPeerCount = float64(peers[len(peers)/2-1]+peers[len(peers)/2]) / 2
+		} else {
+			snapshot.MedianPeerCount = float64(peers[len(peers)/2])
+		}
+	}
+	return snapshot
+}
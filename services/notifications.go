@@ -33,6 +33,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 	"github.com/rocket-pool/rocketpool-go/utils/eth"
+	"github.com/shopspring/decimal"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -253,6 +254,13 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 	}
 	logger.Infof("collecting block proposal missed notifications took: %v\n", time.Since(start))
 
+	err = collectFeeRecipientMismatchNotifications(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_fee_recipient_mismatch").Inc()
+		return nil, fmt.Errorf("error collecting fee recipient mismatch notifications: %v", err)
+	}
+	logger.Infof("collecting fee recipient mismatch notifications took: %v\n", time.Since(start))
+
 	err = collectValidatorGotSlashedNotifications(notificationsByUserID, epoch)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_validator_got_slashed").Inc()
@@ -267,6 +275,13 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 	}
 	logger.Infof("collecting withdrawal notifications took: %v\n", time.Since(start))
 
+	err = collectCredentialsChangedNotifications(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_validator_credentials_changed").Inc()
+		return nil, fmt.Errorf("error collecting credentials changed notifications: %v", err)
+	}
+	logger.Infof("collecting credentials changed notifications took: %v\n", time.Since(start))
+
 	err = collectNetworkNotifications(notificationsByUserID, types.NetworkLivenessIncreasedEventName)
 	if err != nil {
 		metrics.Errors.WithLabelValues("notifications_collect_network").Inc()
@@ -274,6 +289,36 @@ func collectNotifications(epoch uint64) (map[uint64]map[types.EventName][]types.
 	}
 	logger.Infof("collecting network notifications took: %v\n", time.Since(start))
 
+	if utils.Config.WhaleAlerts.EtherThreshold > 0 || len(utils.Config.WhaleAlerts.TokenThresholds) > 0 {
+		err = collectWhaleTransferNotifications(notificationsByUserID, epoch)
+		if err != nil {
+			metrics.Errors.WithLabelValues("notifications_collect_whale_transfer").Inc()
+			return nil, fmt.Errorf("error collecting whale transfer notifications: %v", err)
+		}
+		logger.Infof("collecting whale transfer notifications took: %v\n", time.Since(start))
+	}
+
+	err = collectDeepReorgNotifications(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_deep_reorg").Inc()
+		return nil, fmt.Errorf("error collecting deep reorg notifications: %v", err)
+	}
+	logger.Infof("collecting deep reorg notifications took: %v\n", time.Since(start))
+
+	err = collectAlertRuleNotifications(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_alert_rules").Inc()
+		return nil, fmt.Errorf("error collecting alert rule notifications: %v", err)
+	}
+	logger.Infof("collecting alert rule notifications took: %v\n", time.Since(start))
+
+	err = collectContractAlertNotifications(notificationsByUserID, epoch)
+	if err != nil {
+		metrics.Errors.WithLabelValues("notifications_collect_contract_alerts").Inc()
+		return nil, fmt.Errorf("error collecting contract alert notifications: %v", err)
+	}
+	logger.Infof("collecting contract alert notifications took: %v\n", time.Since(start))
+
 	// Rocketpool
 	{
 		var ts int64
@@ -1197,7 +1242,7 @@ func collectBlockProposalNotifications(notificationsByUserID map[uint64]map[type
 		}
 
 		if len(blockList) > 0 {
-			blocks, err := db.BigtableClient.GetBlocksIndexedMultiple(blockList, 10000)
+			blocks, err := db.GetEth1Store().GetBlocksIndexedMultiple(context.Background(), blockList, 10000)
 			if err != nil {
 				logger.WithError(err).Errorf("can not load blocks from bigtable for notification")
 				return err
@@ -1371,7 +1416,7 @@ func collectAttestationAndOfflineValidatorNotifications(notificationsByUserID ma
 	}
 
 	// get attestations for all validators for the last n epochs
-	attestations, err := db.BigtableClient.GetValidatorAttestationHistory([]uint64{}, epoch-4, epoch) // retrieve attestation data of the last 3 epochs
+	attestations, err := db.BigtableClient.GetValidatorAttestationHistory(context.Background(), []uint64{}, epoch-4, epoch) // retrieve attestation data of the last 3 epochs
 	if err != nil {
 		return fmt.Errorf("error getting validator attestations from bigtable %w", err)
 	}
@@ -2039,6 +2084,116 @@ func collectWithdrawalNotifications(notificationsByUserID map[uint64]map[types.E
 	return nil
 }
 
+type validatorCredentialsChangedNotification struct {
+	SubscriptionID  uint64
+	ValidatorIndex  uint64
+	Epoch           uint64
+	Slot            uint64
+	FromCredentials []byte
+	ToCredentials   []byte
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+}
+
+func (n *validatorCredentialsChangedNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *validatorCredentialsChangedNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *validatorCredentialsChangedNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *validatorCredentialsChangedNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *validatorCredentialsChangedNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *validatorCredentialsChangedNotification) GetEventName() types.EventName {
+	return types.ValidatorCredentialsChangedEventName
+}
+
+func (n *validatorCredentialsChangedNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`The withdrawal credentials of validator %v changed from %#x to %#x in slot %v.`, n.ValidatorIndex, n.FromCredentials, n.ToCredentials, n.Slot)
+	if includeUrl {
+		return generalPart + getUrlPart(n.ValidatorIndex)
+	}
+	return generalPart
+}
+
+func (n *validatorCredentialsChangedNotification) GetTitle() string {
+	return "Withdrawal Credentials Changed"
+}
+
+func (n *validatorCredentialsChangedNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *validatorCredentialsChangedNotification) GetInfoMarkdown() string {
+	generalPart := fmt.Sprintf(`The withdrawal credentials of validator [%[1]v](https://%[5]v/validator/%[1]v) changed from `+"`%#[2]x`"+` to `+"`%#[3]x`"+` in slot [%[4]v](https://%[5]v/slot/%[4]v).`, n.ValidatorIndex, n.FromCredentials, n.ToCredentials, n.Slot, utils.Config.Frontend.SiteDomain)
+	return generalPart
+}
+
+// collectCredentialsChangedNotifications collects notifications for validators whose withdrawal credentials were rotated during the epoch
+func collectCredentialsChangedNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	_, subMap, err := db.GetSubsForEventFilter(types.ValidatorCredentialsChangedEventName)
+	if err != nil {
+		return fmt.Errorf("error getting subscriptions for credentials changed %w", err)
+	}
+
+	rotations, err := db.GetEpochCredentialRotations(epoch)
+	if err != nil {
+		return fmt.Errorf("error getting credential rotations from database, err: %w", err)
+	}
+
+	for _, rotation := range rotations {
+		subscribers, ok := subMap[hex.EncodeToString(rotation.Pubkey)]
+		if !ok {
+			continue
+		}
+		for _, sub := range subscribers {
+			if sub.UserID == nil || sub.ID == nil {
+				return fmt.Errorf("error expected userId or subId to be defined but got user: %v, sub: %v", sub.UserID, sub.ID)
+			}
+			if sub.LastEpoch != nil {
+				lastSentEpoch := *sub.LastEpoch
+				if lastSentEpoch >= epoch || epoch < sub.CreatedEpoch {
+					continue
+				}
+			}
+			n := &validatorCredentialsChangedNotification{
+				SubscriptionID:  *sub.ID,
+				ValidatorIndex:  rotation.ValidatorIndex,
+				Epoch:           epoch,
+				Slot:            rotation.Slot,
+				FromCredentials: rotation.FromCredentials,
+				ToCredentials:   rotation.ToCredentials,
+				EventFilter:     hex.EncodeToString(rotation.Pubkey),
+				UnsubscribeHash: sub.UnsubscribeHash,
+			}
+			if _, exists := notificationsByUserID[*sub.UserID]; !exists {
+				notificationsByUserID[*sub.UserID] = map[types.EventName][]types.Notification{}
+			}
+			if _, exists := notificationsByUserID[*sub.UserID][n.GetEventName()]; !exists {
+				notificationsByUserID[*sub.UserID][n.GetEventName()] = []types.Notification{}
+			}
+			notificationsByUserID[*sub.UserID][n.GetEventName()] = append(notificationsByUserID[*sub.UserID][n.GetEventName()], n)
+			metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+		}
+	}
+
+	return nil
+}
+
 type ethClientNotification struct {
 	SubscriptionID  uint64
 	UserID          uint64
@@ -2310,7 +2465,7 @@ func collectMonitoringMachine(
 		rowKeys = append(rowKeys, db.GetMachineRowKey(data.UserID, "system", data.MachineName))
 	}
 
-	machineDataOfSubscribed, err := db.BigtableClient.GetMachineMetricsForNotifications(rowKeys)
+	machineDataOfSubscribed, err := db.BigtableClient.GetMachineMetricsForNotifications(context.Background(), rowKeys)
 	if err != nil {
 		return err
 	}
@@ -2680,6 +2835,307 @@ func collectNetworkNotifications(notificationsByUserID map[uint64]map[types.Even
 	return nil
 }
 
+type whaleTransferNotification struct {
+	SubscriptionID  uint64
+	UserID          uint64
+	Epoch           uint64
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+	Transfers       int
+	Largest         *types.Eth1ERC20Indexed
+	// private labels of the user for the sender and receiver of the largest transfer
+	AddressLabels map[string]string
+}
+
+func (n *whaleTransferNotification) formatAddress(address []byte) string {
+	if label, exists := n.AddressLabels[string(address)]; exists {
+		return fmt.Sprintf("%v (%v)", label, utils.FormatHashRaw(address))
+	}
+	return utils.FormatHashRaw(address)
+}
+
+func (n *whaleTransferNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *whaleTransferNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *whaleTransferNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *whaleTransferNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *whaleTransferNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *whaleTransferNotification) GetEventName() types.EventName {
+	return types.WhaleTransferEventName
+}
+
+func (n *whaleTransferNotification) GetInfo(includeUrl bool) string {
+	generalPart := fmt.Sprintf(`%v large transfers have been registered by the network.`, n.Transfers)
+	if n.Largest != nil {
+		generalPart = fmt.Sprintf(`%v large transfers have been registered by the network, the largest one sent %v from %v to %v.`, n.Transfers, utils.FormatCurrentBalance(new(big.Int).Div(new(big.Int).SetBytes(n.Largest.Value), big.NewInt(1e9)).Uint64(), "ETH"), n.formatAddress(n.Largest.From), n.formatAddress(n.Largest.To))
+	}
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` Learn more at https://%v/whales`, utils.Config.Frontend.SiteDomain)
+	}
+	return generalPart
+}
+
+func (n *whaleTransferNotification) GetTitle() string {
+	return "Whale Alert"
+}
+
+func (n *whaleTransferNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *whaleTransferNotification) GetInfoMarkdown() string {
+	return n.GetInfo(false) + fmt.Sprintf(` ([view all](https://%v/whales))`, utils.Config.Frontend.SiteDomain)
+}
+
+// collectWhaleTransferNotifications notifies subscribers about the whale transfers that happened since their last notification.
+// Ether transfers are only considered if they reach the threshold of the subscription, token transfers are always included.
+func collectWhaleTransferNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	var dbResult []struct {
+		SubscriptionID  uint64         `db:"id"`
+		UserID          uint64         `db:"user_id"`
+		EventFilter     string         `db:"event_filter"`
+		EventThreshold  float64        `db:"event_threshold"`
+		UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
+		LastSent        sql.NullTime   `db:"last_sent_ts"`
+	}
+
+	err := db.FrontendWriterDB.Select(&dbResult, `
+		SELECT us.id, us.user_id, us.event_filter, COALESCE(us.event_threshold, 0) AS event_threshold, ENCODE(us.unsubscribe_hash, 'hex') AS unsubscribe_hash, us.last_sent_ts
+		FROM users_subscriptions AS us
+		WHERE us.event_name=$1 AND (us.last_sent_ts <= NOW() - INTERVAL '1 hour' OR us.last_sent_ts IS NULL);
+		`,
+		utils.GetNetwork()+":"+string(types.WhaleTransferEventName))
+	if err != nil {
+		return err
+	}
+	if len(dbResult) == 0 {
+		return nil
+	}
+
+	transfers, err := db.BigtableClient.GetWhaleTransfers(context.Background(), time.Now().Add(-time.Hour*2), 1000)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range dbResult {
+		since := time.Now().Add(-time.Hour)
+		if r.LastSent.Valid && r.LastSent.Time.After(since.Add(-time.Hour)) {
+			since = r.LastSent.Time
+		}
+		threshold := decimal.NewFromFloat(r.EventThreshold).Shift(18).BigInt()
+
+		n := &whaleTransferNotification{
+			SubscriptionID:  r.SubscriptionID,
+			UserID:          r.UserID,
+			Epoch:           epoch,
+			EventFilter:     r.EventFilter,
+			UnsubscribeHash: r.UnsubscribeHash,
+		}
+		for _, t := range transfers {
+			if !t.Time.AsTime().After(since) {
+				continue
+			}
+			if len(t.TokenAddress) == 0 {
+				if new(big.Int).SetBytes(t.Value).Cmp(threshold) < 0 {
+					continue
+				}
+				if n.Largest == nil || new(big.Int).SetBytes(t.Value).Cmp(new(big.Int).SetBytes(n.Largest.Value)) > 0 {
+					n.Largest = t
+				}
+			}
+			n.Transfers++
+		}
+		if n.Transfers == 0 {
+			continue
+		}
+		if n.Largest != nil {
+			n.AddressLabels, err = db.GetUserAddressLabelsByAddress(r.UserID, [][]byte{n.Largest.From, n.Largest.To})
+			if err != nil {
+				logger.WithError(err).Errorf("error getting address labels of user %v for whale transfer notification", r.UserID)
+			}
+		}
+
+		if _, exists := notificationsByUserID[r.UserID]; !exists {
+			notificationsByUserID[r.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[r.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[r.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[r.UserID][n.GetEventName()] = append(notificationsByUserID[r.UserID][n.GetEventName()], n)
+		metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+	}
+
+	return nil
+}
+
+// reorgs of at least this depth are notified if the subscription has no threshold
+const defaultDeepReorgThreshold = 2
+
+type deepReorgNotification struct {
+	SubscriptionID  uint64
+	UserID          uint64
+	Epoch           uint64
+	EventFilter     string
+	UnsubscribeHash sql.NullString
+	Reorgs          []*types.Reorg
+}
+
+func (n *deepReorgNotification) GetLatestState() string {
+	return ""
+}
+
+func (n *deepReorgNotification) GetUnsubscribeHash() string {
+	if n.UnsubscribeHash.Valid {
+		return n.UnsubscribeHash.String
+	}
+	return ""
+}
+
+func (n *deepReorgNotification) GetEmailAttachment() *types.EmailAttachment {
+	return nil
+}
+
+func (n *deepReorgNotification) GetSubscriptionID() uint64 {
+	return n.SubscriptionID
+}
+
+func (n *deepReorgNotification) GetEpoch() uint64 {
+	return n.Epoch
+}
+
+func (n *deepReorgNotification) GetEventName() types.EventName {
+	return types.NetworkDeepReorgEventName
+}
+
+func (n *deepReorgNotification) deepest() *types.Reorg {
+	var deepest *types.Reorg
+	for _, reorg := range n.Reorgs {
+		if deepest == nil || reorg.Depth > deepest.Depth {
+			deepest = reorg
+		}
+	}
+	return deepest
+}
+
+func (n *deepReorgNotification) GetInfo(includeUrl bool) string {
+	deepest := n.deepest()
+	layer := "consensus layer"
+	height := fmt.Sprintf("slot %v", deepest.Height)
+	if deepest.Layer == types.ExecutionLayerReorg {
+		layer = "execution layer"
+		height = fmt.Sprintf("block %v", deepest.Height)
+	}
+	generalPart := fmt.Sprintf(`A %v reorg with a depth of %v blocks has been detected at %v.`, layer, deepest.Depth, height)
+	if len(n.Reorgs) > 1 {
+		generalPart = fmt.Sprintf(`%v deep reorgs have been detected, the deepest one is a %v reorg with a depth of %v blocks at %v.`, len(n.Reorgs), layer, deepest.Depth, height)
+	}
+	if includeUrl {
+		return generalPart + fmt.Sprintf(` Learn more at https://%v/reorgs`, utils.Config.Frontend.SiteDomain)
+	}
+	return generalPart
+}
+
+func (n *deepReorgNotification) GetTitle() string {
+	return "Deep Reorg Detected"
+}
+
+func (n *deepReorgNotification) GetEventFilter() string {
+	return n.EventFilter
+}
+
+func (n *deepReorgNotification) GetInfoMarkdown() string {
+	return n.GetInfo(false) + fmt.Sprintf(` ([view all](https://%v/reorgs))`, utils.Config.Frontend.SiteDomain)
+}
+
+// collectDeepReorgNotifications notifies subscribers about reorgs that reach the depth threshold of their subscription
+// and have been detected since their last notification.
+func collectDeepReorgNotifications(notificationsByUserID map[uint64]map[types.EventName][]types.Notification, epoch uint64) error {
+	var dbResult []struct {
+		SubscriptionID  uint64         `db:"id"`
+		UserID          uint64         `db:"user_id"`
+		EventFilter     string         `db:"event_filter"`
+		EventThreshold  float64        `db:"event_threshold"`
+		UnsubscribeHash sql.NullString `db:"unsubscribe_hash"`
+		LastSent        sql.NullTime   `db:"last_sent_ts"`
+	}
+
+	err := db.FrontendWriterDB.Select(&dbResult, `
+		SELECT us.id, us.user_id, us.event_filter, COALESCE(us.event_threshold, 0) AS event_threshold, ENCODE(us.unsubscribe_hash, 'hex') AS unsubscribe_hash, us.last_sent_ts
+		FROM users_subscriptions AS us
+		WHERE us.event_name=$1;
+		`,
+		utils.GetNetwork()+":"+string(types.NetworkDeepReorgEventName))
+	if err != nil {
+		return err
+	}
+	if len(dbResult) == 0 {
+		return nil
+	}
+
+	reorgs, err := db.GetReorgsDetectedSince(time.Now().Add(-time.Hour), defaultDeepReorgThreshold)
+	if err != nil {
+		return err
+	}
+	if len(reorgs) == 0 {
+		return nil
+	}
+
+	for _, r := range dbResult {
+		threshold := uint64(defaultDeepReorgThreshold)
+		if r.EventThreshold > defaultDeepReorgThreshold {
+			threshold = uint64(r.EventThreshold)
+		}
+
+		n := &deepReorgNotification{
+			SubscriptionID:  r.SubscriptionID,
+			UserID:          r.UserID,
+			Epoch:           epoch,
+			EventFilter:     r.EventFilter,
+			UnsubscribeHash: r.UnsubscribeHash,
+		}
+		for _, reorg := range reorgs {
+			if r.LastSent.Valid && !reorg.DetectedTime.After(r.LastSent.Time) {
+				continue
+			}
+			if reorg.Depth < threshold {
+				continue
+			}
+			n.Reorgs = append(n.Reorgs, reorg)
+		}
+		if len(n.Reorgs) == 0 {
+			continue
+		}
+
+		if _, exists := notificationsByUserID[r.UserID]; !exists {
+			notificationsByUserID[r.UserID] = map[types.EventName][]types.Notification{}
+		}
+		if _, exists := notificationsByUserID[r.UserID][n.GetEventName()]; !exists {
+			notificationsByUserID[r.UserID][n.GetEventName()] = []types.Notification{}
+		}
+		notificationsByUserID[r.UserID][n.GetEventName()] = append(notificationsByUserID[r.UserID][n.GetEventName()], n)
+		metrics.NotificationsCollected.WithLabelValues(string(n.GetEventName())).Inc()
+	}
+
+	return nil
+}
+
 type rocketpoolNotification struct {
 	SubscriptionID  uint64
 	UserID          uint64
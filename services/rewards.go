@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"eth2-exporter/db"
 	"eth2-exporter/types"
 	"eth2-exporter/utils"
@@ -23,7 +24,7 @@ type rewardHistory struct {
 	Validators    []uint64   `json:"validators"`
 }
 
-func GetValidatorHist(validatorArr []uint64, currency string, start uint64, end uint64) rewardHistory {
+func GetValidatorHist(ctx context.Context, validatorArr []uint64, currency string, start uint64, end uint64) rewardHistory {
 	var err error
 
 	var pricesDb []types.Price
@@ -38,7 +39,7 @@ func GetValidatorHist(validatorArr []uint64, currency string, start uint64, end
 	lowerBound := utils.TimeToDay(start)
 	upperBound := utils.TimeToDay(end)
 
-	income, _, err := db.GetValidatorIncomeHistory(validatorArr, lowerBound+1, upperBound)
+	income, _, err := db.GetValidatorIncomeHistory(ctx, validatorArr, lowerBound+1, upperBound)
 	if err != nil {
 		logger.Errorf("error getting income history for validator hist: %v", err)
 	}
@@ -281,7 +282,7 @@ func GeneratePdfReport(hist rewardHistory, currency string) []byte {
 }
 
 func GetPdfReport(validatorArr []uint64, currency string, start uint64, end uint64) []byte {
-	hist := GetValidatorHist(validatorArr, currency, start, end)
+	hist := GetValidatorHist(context.Background(), validatorArr, currency, start, end)
 	return GeneratePdfReport(hist, currency)
 }
 
@@ -298,7 +299,7 @@ func getValidatorDetails(validators []uint64) [][]string {
 		return [][]string{}
 	}
 
-	balances, err := db.BigtableClient.GetValidatorBalanceHistory(validators, LatestEpoch(), LatestEpoch())
+	balances, err := db.BigtableClient.GetValidatorBalanceHistory(context.Background(), validators, LatestEpoch(), LatestEpoch())
 	if err != nil {
 		logger.Errorf("error getting validator balance data for getValidatorDetails function: %v", err)
 		return [][]string{}
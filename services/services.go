@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
@@ -48,6 +49,9 @@ func Init() {
 	ready.Add(1)
 	go latestBlockUpdater(ready)
 
+	ready.Add(1)
+	go finalityCheckpointsUpdater(ready)
+
 	ready.Add(1)
 	go slotVizUpdater(ready)
 
@@ -67,13 +71,24 @@ func Init() {
 	go statsUpdater(ready)
 
 	ready.Add(1)
-	go mempoolUpdater(ready)
+	if utils.Config.Frontend.MempoolWatcher.Enabled {
+		go mempoolWatcher(ready)
+	} else {
+		go mempoolUpdater(ready)
+	}
 
 	ready.Add(1)
 	go burnUpdater(ready)
 
 	ready.Add(1)
-	go gasNowUpdater(ready)
+	if utils.Config.Frontend.GasOracle.Enabled {
+		go gasOracleUpdater(ready)
+	} else {
+		go gasNowUpdater(ready)
+	}
+
+	ready.Add(1)
+	go topMovementsUpdater(ready)
 
 	ready.Add(1)
 	go ethStoreStatisticsDataUpdater(ready)
@@ -81,6 +96,17 @@ func Init() {
 	ready.Add(1)
 	go startMonitoringService(ready)
 
+	go transferPathJobsProcessor()
+	go slaReportJobsProcessor()
+
+	if utils.Config.Frontend.Snapshots.Enabled {
+		go snapshotUpdater()
+	}
+
+	if utils.Config.Frontend.AddressActivityCache.Enabled {
+		go addressActivityUpdater(db.GetEth1Store())
+	}
+
 	ready.Wait()
 }
 
@@ -992,7 +1018,12 @@ func LatestProposedSlot() uint64 {
 func LatestMempoolTransactions() *types.RawMempoolResponse {
 	wanted := &types.RawMempoolResponse{}
 	cacheKey := fmt.Sprintf("%d:frontend:mempool", utils.Config.Chain.Config.DepositChainID)
-	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Second*60, wanted); err == nil {
+	localTimeout := time.Second * 60
+	if utils.Config.Frontend.MempoolWatcher.Enabled {
+		// included transactions are removed from the snapshot within seconds
+		localTimeout = mempoolWatcherSnapshot
+	}
+	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, localTimeout, wanted); err == nil {
 		return wanted.(*types.RawMempoolResponse)
 	} else {
 		logger.Errorf("error retrieving mempool data from cache: %v", err)
@@ -1093,7 +1124,12 @@ func LatestGasNowData() *types.GasNowPageData {
 	wanted := &types.GasNowPageData{}
 	cacheKey := fmt.Sprintf("%d:frontend:gasNow", utils.Config.Chain.Config.DepositChainID)
 
-	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Second*5, wanted); err == nil {
+	// the gas oracle updates once per block, its estimates are only kept locally for a single poll interval
+	localTimeout := time.Second * 5
+	if utils.Config.Frontend.GasOracle.Enabled {
+		localTimeout = gasOracleInterval()
+	}
+	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, localTimeout, wanted); err == nil {
 		return wanted.(*types.GasNowPageData)
 	} else {
 		logger.Errorf("error retrieving gasNow from cache: %v", err)
@@ -1331,7 +1367,7 @@ func getGasNowData() (*types.GasNowPageData, error) {
 		gpoData.Data.Slow = header.BaseFee
 	}
 
-	err = db.BigtableClient.SaveGasNowHistory(gpoData.Data.Slow, gpoData.Data.Standard, gpoData.Data.Fast, gpoData.Data.Rapid)
+	err = db.BigtableClient.SaveGasNowHistory(context.Background(), gpoData.Data.Slow, gpoData.Data.Standard, gpoData.Data.Fast, gpoData.Data.Rapid)
 	if err != nil {
 		logrus.WithError(err).Error("error updating gas now history")
 	}
@@ -1520,7 +1556,7 @@ func getBurnPageData() (*types.BurnPageData, error) {
 	// }
 
 	// swap this for GetEpochIncomeHistory in the future
-	income, err := db.BigtableClient.GetValidatorIncomeDetailsHistory([]uint64{}, latestEpoch-10, latestBlock)
+	income, err := db.BigtableClient.GetValidatorIncomeDetailsHistory(context.Background(), []uint64{}, latestEpoch-10, latestBlock)
 	if err != nil {
 		logger.WithError(err).Error("error getting validator income history")
 	}
@@ -1584,7 +1620,7 @@ func getBurnPageData() (*types.BurnPageData, error) {
 		return nil, fmt.Errorf("error retrieving block utilization from blocks table: %v", err)
 	}
 
-	blocks, err := db.BigtableClient.GetBlocksDescending(latestBlock, 1000)
+	blocks, err := db.GetEth1Store().GetBlocksDescending(context.Background(), latestBlock, 1000)
 	if err != nil {
 		return nil, err
 	}
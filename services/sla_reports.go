@@ -0,0 +1,328 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const slaReportMonthLayout = "2006-01"
+
+// slaReportJobsProcessor picks up pending sla report jobs and generates the reports one after another
+func slaReportJobsProcessor() {
+	for {
+		job, err := db.ClaimPendingSlaReportJob()
+		if err != nil {
+			logger.Errorf("error claiming sla report job: %v", err)
+			time.Sleep(time.Second * 30)
+			continue
+		}
+		if job == nil {
+			time.Sleep(time.Second * 5)
+			continue
+		}
+
+		start := time.Now()
+		result, err := generateSlaReport(job)
+		if err != nil {
+			logger.Errorf("error executing sla report job %v: %v", job.ID, err)
+			err = db.FailSlaReportJob(job.ID, err)
+			if err != nil {
+				logger.Errorf("error marking sla report job %v as failed: %v", job.ID, err)
+			}
+			continue
+		}
+
+		err = db.CompleteSlaReportJob(job.ID, result)
+		if err != nil {
+			logger.Errorf("error saving result of sla report job %v: %v", job.ID, err)
+			continue
+		}
+		logger.Infof("completed sla report job %v for %v validators in %v", job.ID, len(job.Validators), time.Since(start))
+	}
+}
+
+// ParseSlaReportMonth parses a month formatted as YYYY-MM and returns an error if no statistics can exist for it
+func ParseSlaReportMonth(month string) (time.Time, error) {
+	monthStart, err := time.Parse(slaReportMonthLayout, month)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month %v, the month must be formatted as YYYY-MM", month)
+	}
+	if !monthStart.AddDate(0, 1, 0).After(time.Unix(int64(utils.Config.Chain.GenesisTimestamp), 0)) {
+		return time.Time{}, fmt.Errorf("invalid month %v, the month ends before genesis", month)
+	}
+	if monthStart.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("invalid month %v, the month lies in the future", month)
+	}
+	return monthStart, nil
+}
+
+// firstDayStartingAt returns the first statistics day that starts at or after ts
+func firstDayStartingAt(ts time.Time) uint64 {
+	if !ts.After(time.Unix(int64(utils.Config.Chain.GenesisTimestamp), 0)) {
+		return 0
+	}
+	day := utils.TimeToDay(uint64(ts.Unix()))
+	if utils.DayToTime(int64(day)).Before(ts) {
+		day++
+	}
+	return day
+}
+
+func generateSlaReport(job *types.SlaReportJob) ([]byte, error) {
+	monthStart, err := ParseSlaReportMonth(job.Month)
+	if err != nil {
+		return nil, err
+	}
+
+	// statistics days do not align with calendar days, a day is attributed to the month it starts in
+	startDay := firstDayStartingAt(monthStart)
+	endDay := firstDayStartingAt(monthStart.AddDate(0, 1, 0))
+	if endDay == 0 {
+		return nil, fmt.Errorf("no statistics days start in month %v", job.Month)
+	}
+	endDay--
+
+	var lastExportedDay uint64
+	err = db.ReaderDb.Get(&lastExportedDay, "SELECT COALESCE(MAX(day), 0) FROM validator_stats_status WHERE status")
+	if err != nil {
+		return nil, fmt.Errorf("error getting last exported statistics day: %w", err)
+	}
+	if lastExportedDay < endDay {
+		endDay = lastExportedDay
+	}
+	if startDay > endDay {
+		return nil, fmt.Errorf("no validator statistics have been exported for month %v yet", job.Month)
+	}
+
+	report := &types.SlaReport{
+		Month:      job.Month,
+		StartDay:   startDay,
+		EndDay:     endDay,
+		StartEpoch: startDay * utils.EpochsPerDay(),
+		EndEpoch:   (endDay + 1) * utils.EpochsPerDay(),
+	}
+
+	validators := make([]uint64, 0, len(job.Validators))
+	for _, validator := range job.Validators {
+		validators = append(validators, uint64(validator))
+	}
+
+	report.Validators, err = db.GetSlaReportValidators(validators, startDay, endDay)
+	if err != nil {
+		return nil, err
+	}
+	if len(report.Validators) == 0 {
+		return nil, fmt.Errorf("none of the requested validators exist")
+	}
+
+	slashings, err := db.GetSlashingsOfValidators(validators, report.StartEpoch, report.EndEpoch)
+	if err != nil {
+		return nil, err
+	}
+	validatorsByIndex := make(map[uint64]*types.SlaReportValidator, len(report.Validators))
+	for _, validator := range report.Validators {
+		validatorsByIndex[validator.Index] = validator
+
+		// a validator has to attest once per epoch while it is active
+		from, to := validator.ActivationEpoch, validator.ExitEpoch
+		if from < report.StartEpoch {
+			from = report.StartEpoch
+		}
+		if to > report.EndEpoch {
+			to = report.EndEpoch
+		}
+		if to > from {
+			validator.AttestationDuties = to - from
+		}
+	}
+	for _, slashing := range slashings {
+		if validator, ok := validatorsByIndex[slashing.ValidatorIndex]; ok {
+			validator.Slashings = append(validator.Slashings, slashing)
+		}
+	}
+
+	switch job.Format {
+	case types.SlaReportFormatCsv:
+		return generateSlaReportCsv(report)
+	case types.SlaReportFormatPdf:
+		return generateSlaReportPdf(report)
+	}
+	return nil, fmt.Errorf("unknown report format %v", job.Format)
+}
+
+func generateSlaReportCsv(report *types.SlaReport) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer := csv.NewWriter(buf)
+
+	err := writer.Write([]string{"validator_index", "pubkey", "attestation_duties", "missed_attestations", "attestation_participation", "proposed_blocks", "missed_blocks", "orphaned_blocks", "participated_sync", "missed_sync", "slashings", "slashing_slots"})
+	if err != nil {
+		return nil, err
+	}
+	for _, validator := range report.Validators {
+		slots := make([]string, 0, len(validator.Slashings))
+		for _, slashing := range validator.Slashings {
+			slots = append(slots, fmt.Sprintf("%d", slashing.Slot))
+		}
+		err = writer.Write([]string{
+			fmt.Sprintf("%d", validator.Index),
+			fmt.Sprintf("0x%x", validator.Pubkey),
+			fmt.Sprintf("%d", validator.AttestationDuties),
+			fmt.Sprintf("%d", validator.MissedAttestations),
+			fmt.Sprintf("%.5f", validator.AttestationParticipation()),
+			fmt.Sprintf("%d", validator.ProposedBlocks),
+			fmt.Sprintf("%d", validator.MissedBlocks),
+			fmt.Sprintf("%d", validator.OrphanedBlocks),
+			fmt.Sprintf("%d", validator.ParticipatedSync),
+			fmt.Sprintf("%d", validator.MissedSync),
+			fmt.Sprintf("%d", len(validator.Slashings)),
+			strings.Join(slots, " "),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func generateSlaReportPdf(report *types.SlaReport) ([]byte, error) {
+	total := &types.SlaReportValidator{}
+	slashingCount := 0
+	for _, validator := range report.Validators {
+		total.AttestationDuties += validator.AttestationDuties
+		total.MissedAttestations += validator.MissedAttestations
+		total.ProposedBlocks += validator.ProposedBlocks
+		total.MissedBlocks += validator.MissedBlocks
+		total.OrphanedBlocks += validator.OrphanedBlocks
+		slashingCount += len(validator.Slashings)
+	}
+
+	periodStart := utils.DayToTime(int64(report.StartDay)).UTC()
+	periodEnd := utils.DayToTime(int64(report.EndDay + 1)).UTC()
+
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetTopMargin(15)
+	pdf.SetHeaderFuncMode(func() {
+		pdf.SetY(5)
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Beaconcha.in Validator SLA Report (%s)", report.Month), "", 0, "C", false, 0, "")
+	}, true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()),
+			"", 0, "C", false, 0, "")
+	})
+
+	pdf.AddPage()
+	pdf.SetTextColor(24, 24, 24)
+	pdf.SetFont("Times", "", 10)
+
+	summary := []string{
+		fmt.Sprintf("Period: %s - %s (epochs %d - %d)", periodStart.Format(time.RFC822), periodEnd.Format(time.RFC822), report.StartEpoch, report.EndEpoch-1),
+		fmt.Sprintf("Validators: %d", len(report.Validators)),
+		fmt.Sprintf("Attestation participation: %.3f%% (%d of %d attestations missed)", total.AttestationParticipation()*100, total.MissedAttestations, total.AttestationDuties),
+		fmt.Sprintf("Proposals: %d proposed, %d missed, %d orphaned", total.ProposedBlocks, total.MissedBlocks, total.OrphanedBlocks),
+		fmt.Sprintf("Slashing events: %d", slashingCount),
+	}
+	for _, line := range summary {
+		pdf.CellFormat(0, 6, line, "", 1, "LM", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	header := []string{"Index", "Att. Duties", "Att. Missed", "Participation", "Proposed", "Missed", "Orphaned", "Sync Part.", "Sync Missed", "Slashed"}
+	rows := make([][]string, 0, len(report.Validators))
+	for _, validator := range report.Validators {
+		slashed := "No"
+		if len(validator.Slashings) > 0 {
+			slashed = "Yes"
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", validator.Index),
+			fmt.Sprintf("%d", validator.AttestationDuties),
+			fmt.Sprintf("%d", validator.MissedAttestations),
+			fmt.Sprintf("%.3f%%", validator.AttestationParticipation()*100),
+			fmt.Sprintf("%d", validator.ProposedBlocks),
+			fmt.Sprintf("%d", validator.MissedBlocks),
+			fmt.Sprintf("%d", validator.OrphanedBlocks),
+			fmt.Sprintf("%d", validator.ParticipatedSync),
+			fmt.Sprintf("%d", validator.MissedSync),
+			slashed,
+		})
+	}
+	slaReportPdfTable(pdf, header, rows, 27)
+
+	if slashingCount > 0 {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(0, 6, "Slashing Events", "", 1, "CM", false, 0, "")
+		pdf.Ln(4)
+		pdf.SetFont("Times", "", 10)
+
+		rows = rows[:0]
+		for _, validator := range report.Validators {
+			for _, slashing := range validator.Slashings {
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", slashing.ValidatorIndex),
+					fmt.Sprintf("%d", slashing.Slot),
+					utils.SlotToTime(slashing.Slot).UTC().Format(time.RFC822),
+					slashing.Reason,
+				})
+			}
+		}
+		slaReportPdfTable(pdf, []string{"Index", "Slot", "Time", "Reason"}, rows, 60)
+	}
+
+	buf := new(bytes.Buffer)
+	err := pdf.Output(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// slaReportPdfTable renders a table with alternating row colors and repeats the header on every page
+func slaReportPdfTable(pdf *gofpdf.Fpdf, header []string, rows [][]string, colWd float64) {
+	const rowHt = 5.5
+	_, pageHt := pdf.GetPageSize()
+	_, _, _, bottomMargin := pdf.GetMargins()
+
+	writeHeader := func() {
+		pdf.SetTextColor(224, 224, 224)
+		pdf.SetFillColor(64, 64, 64)
+		for _, col := range header {
+			pdf.CellFormat(colWd, rowHt, col, "1", 0, "CM", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetTextColor(24, 24, 24)
+	}
+
+	writeHeader()
+	for i, row := range rows {
+		if pdf.GetY()+rowHt > pageHt-bottomMargin-15 {
+			pdf.AddPage()
+			writeHeader()
+		}
+		pdf.SetFillColor(255, 255, 255)
+		if i%2 != 0 {
+			pdf.SetFillColor(191, 191, 191)
+		}
+		for _, col := range row {
+			pdf.CellFormat(colWd, rowHt, col, "1", 0, "LM", true, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/price"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+const (
+	SnapshotLatestBlocks = "latestBlocks"
+	SnapshotGasOracle    = "gasOracle"
+	SnapshotNetworkStats = "networkStats"
+
+	snapshotLatestBlocksCount = 25
+)
+
+// SnapshotNames lists all snapshots generated by the snapshot updater
+var SnapshotNames = []string{SnapshotLatestBlocks, SnapshotGasOracle, SnapshotNetworkStats}
+
+// SnapshotInterval returns the configured refresh interval of the static json snapshots
+func SnapshotInterval() time.Duration {
+	if utils.Config.Frontend.Snapshots.Interval > 0 {
+		return utils.Config.Frontend.Snapshots.Interval
+	}
+	return time.Second * 15
+}
+
+// snapshotUpdater periodically renders the hot api data into static json documents and uploads them to the configured bucket
+func snapshotUpdater() {
+	var client *storage.Client
+	if utils.Config.Frontend.Snapshots.Bucket != "" {
+		var err error
+		client, err = storage.NewClient(context.Background())
+		if err != nil {
+			logger.Errorf("error creating storage client for snapshots, snapshots will only be served from cache: %v", err)
+		} else {
+			defer client.Close()
+		}
+	}
+
+	for {
+		start := time.Now()
+		for _, name := range SnapshotNames {
+			data, err := getSnapshotData(name)
+			if err != nil {
+				logger.Errorf("error generating %v snapshot: %v", name, err)
+				continue
+			}
+
+			snapshot, err := json.Marshal(&types.APISnapshot{
+				Name:        name,
+				GeneratedAt: start.Unix(),
+				Data:        data,
+			})
+			if err != nil {
+				logger.Errorf("error serializing %v snapshot: %v", name, err)
+				continue
+			}
+
+			err = cache.TieredCache.SetString(snapshotCacheKey(name), string(snapshot), SnapshotInterval()*4)
+			if err != nil {
+				logger.Errorf("error caching %v snapshot: %v", name, err)
+			}
+
+			if client != nil {
+				err = uploadSnapshot(client, name, snapshot)
+				if err != nil {
+					logger.Errorf("error uploading %v snapshot: %v", name, err)
+				}
+			}
+		}
+
+		ReportStatus("snapshotUpdater", "Running", nil)
+		time.Sleep(time.Until(start.Add(SnapshotInterval())))
+	}
+}
+
+func snapshotCacheKey(name string) string {
+	return fmt.Sprintf("%d:frontend:snapshot:%s", utils.Config.Chain.Config.DepositChainID, name)
+}
+
+// SnapshotObjectName returns the path of a snapshot inside the bucket, relative to the public snapshot url
+func SnapshotObjectName(name string) string {
+	return fmt.Sprintf("%d/%s.json", utils.Config.Chain.Config.DepositChainID, name)
+}
+
+func uploadSnapshot(client *storage.Client, name string, snapshot []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	w := client.Bucket(utils.Config.Frontend.Snapshots.Bucket).Object(SnapshotObjectName(name)).NewWriter(ctx)
+	w.ContentType = "application/json"
+	w.CacheControl = fmt.Sprintf("public, max-age=%d", int(SnapshotInterval().Seconds()))
+	_, err := w.Write(snapshot)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// LatestSnapshot returns the most recent serialized snapshot with the given name
+func LatestSnapshot(name string) ([]byte, error) {
+	snapshot, err := cache.TieredCache.GetStringWithLocalTimeout(snapshotCacheKey(name), SnapshotInterval())
+	if err != nil {
+		return nil, err
+	}
+	return []byte(snapshot), nil
+}
+
+func getSnapshotData(name string) (interface{}, error) {
+	switch name {
+	case SnapshotLatestBlocks:
+		return getLatestBlocksSnapshot()
+	case SnapshotGasOracle:
+		gasNow := LatestGasNowData()
+		if gasNow == nil {
+			return nil, fmt.Errorf("gas now data is not available")
+		}
+		return gasNow.Data, nil
+	case SnapshotNetworkStats:
+		return getNetworkStatsSnapshot(), nil
+	}
+	return nil, fmt.Errorf("unknown snapshot %v", name)
+}
+
+func getLatestBlocksSnapshot() ([]*types.APISnapshotBlock, error) {
+	latest := LatestEth1BlockNumber()
+	if latest < snapshotLatestBlocksCount {
+		return nil, fmt.Errorf("latest block %v is not available yet", latest)
+	}
+
+	blocks, err := db.GetEth1Store().GetBlocksDescending(context.Background(), latest, snapshotLatestBlocksCount)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make([]*types.APISnapshotBlock, 0, len(blocks))
+	for _, block := range blocks {
+		snapshot = append(snapshot, &types.APISnapshotBlock{
+			Number:           block.GetNumber(),
+			Hash:             fmt.Sprintf("0x%x", block.GetHash()),
+			Timestamp:        block.GetTime().AsTime().Unix(),
+			FeeRecipient:     utils.FixAddressCasing(fmt.Sprintf("%x", block.GetCoinbase())),
+			GasUsed:          block.GetGasUsed(),
+			GasLimit:         block.GetGasLimit(),
+			BaseFee:          new(big.Int).SetBytes(block.GetBaseFee()).String(),
+			TransactionCount: block.GetTransactionCount(),
+		})
+	}
+	return snapshot, nil
+}
+
+func getNetworkStatsSnapshot() *types.APISnapshotNetworkStats {
+	indexPageData := LatestIndexPageData()
+	return &types.APISnapshotNetworkStats{
+		LatestBlock:           LatestEth1BlockNumber(),
+		CurrentEpoch:          LatestEpoch(),
+		CurrentSlot:           LatestSlot(),
+		CurrentFinalizedEpoch: LatestFinalizedEpoch(),
+		FinalityDelay:         FinalizationDelay(),
+		ActiveValidators:      indexPageData.ActiveValidators,
+		EnteringValidators:    indexPageData.EnteringValidators,
+		ExitingValidators:     indexPageData.ExitingValidators,
+		StakedEther:           indexPageData.StakedEther,
+		EthPriceUSD:           price.GetEthPrice("USD"),
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"eth2-exporter/metrics"
+	"eth2-exporter/utils"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// number of consecutive failed or slow runs after which a probe is reported as degraded
+const syntheticProbeDegradedRuns = 3
+
+// syntheticProbe is a request exercising a user journey of the explorer
+type syntheticProbe struct {
+	name string
+	path string
+}
+
+func syntheticProbes() []syntheticProbe {
+	cfg := utils.Config.SyntheticProbes
+
+	address := cfg.Address
+	if address == "" {
+		address = utils.Config.Chain.Config.DepositContractAddress
+	}
+
+	probes := []syntheticProbe{
+		{name: "address", path: "/address/" + address},
+		{name: "validator", path: fmt.Sprintf("/validator/%d", cfg.Validator)},
+		{name: "api_address_tokens", path: fmt.Sprintf("/api/v1/execution/address/%s/tokens", address)},
+	}
+	if cfg.Block != 0 {
+		probes = append(probes, syntheticProbe{name: "block", path: fmt.Sprintf("/block/%d", cfg.Block)})
+	}
+	return probes
+}
+
+// The synthetic probe service periodically requests representative pages of the explorer and reports a probe as degraded
+// once it failed or exceeded the latency threshold several times in a row
+func startSyntheticProbeService() {
+	name := "monitoring_synthetic_probes"
+	firstRun := true
+
+	threshold := utils.Config.SyntheticProbes.LatencyThreshold
+	if threshold == 0 {
+		threshold = time.Second * 5
+	}
+
+	client := &http.Client{
+		Timeout: time.Second * 30,
+	}
+
+	probes := syntheticProbes()
+	degradedRuns := make(map[string]int, len(probes))
+
+	for {
+		if !firstRun {
+			time.Sleep(time.Minute)
+		}
+		firstRun = false
+
+		degraded := []string{}
+		for _, probe := range probes {
+			duration, err := runSyntheticProbe(client, probe)
+
+			result := "ok"
+			if err != nil {
+				result = "error"
+			} else if duration > threshold {
+				result = "slow"
+				err = fmt.Errorf("took %v", duration.Round(time.Millisecond))
+			}
+			metrics.SyntheticProbeDuration.WithLabelValues(probe.name, result).Observe(duration.Seconds())
+
+			if err == nil {
+				degradedRuns[probe.name] = 0
+				continue
+			}
+			logger.Warnf("synthetic probe %v (%v) is degraded: %v", probe.name, probe.path, err)
+
+			degradedRuns[probe.name]++
+			if degradedRuns[probe.name] >= syntheticProbeDegradedRuns {
+				degraded = append(degraded, fmt.Sprintf("%v: %v", probe.name, err))
+			}
+		}
+
+		if len(degraded) == 0 {
+			ReportStatus(name, "OK", nil)
+			continue
+		}
+
+		errorMsg := fmt.Errorf("error: synthetic probes degraded for %v runs: %v", syntheticProbeDegradedRuns, strings.Join(degraded, "; "))
+		utils.LogError(nil, errorMsg, 0)
+		ReportStatus(name, errorMsg.Error(), nil)
+	}
+}
+
+// runSyntheticProbe requests the path of a probe and returns the time it took to receive the whole response
+func runSyntheticProbe(client *http.Client, probe syntheticProbe) (time.Duration, error) {
+	start := time.Now()
+
+	resp, err := client.Get("https://" + utils.Config.Frontend.SiteDomain + probe.path)
+	if err != nil {
+		return time.Since(start), err
+	}
+	defer resp.Body.Close()
+
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		return time.Since(start), err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return time.Since(start), fmt.Errorf("returned a non 200 status: %v", resp.StatusCode)
+	}
+	return time.Since(start), nil
+}
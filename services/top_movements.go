@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/cache"
+	"eth2-exporter/db"
+	"eth2-exporter/types"
+	"eth2-exporter/utils"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// number of recent blocks the top movements are computed from
+const topMovementsBlocks = 100
+
+// number of transfers and contracts listed in the top movements
+const topMovementsEntries = 10
+
+// blockMovements is the part of the top movements contributed by a single block
+type blockMovements struct {
+	number       uint64
+	hash         []byte
+	parentHash   []byte
+	transactions uint64
+	transfers    []*types.TopMovementTransfer
+	contracts    map[string]*types.TopMovementContract
+	gasUsed      uint64
+	gasLimit     uint64
+	baseFee      float64
+	gasPrices    []float64
+	burned       float64
+}
+
+// topMovementsUpdater keeps a rolling window of the most recent execution blocks and summarizes their largest transfers,
+// most active contracts and gas usage
+func topMovementsUpdater(wg *sync.WaitGroup) {
+	firstRun := true
+	window := make([]*blockMovements, 0, topMovementsBlocks)
+
+	for {
+		var err error
+		window, err = advanceMovementsWindow(window, LatestEth1BlockNumber())
+		if err != nil {
+			logger.Errorf("error updating top movements: %v", err)
+		} else if len(window) > 0 {
+			cacheKey := fmt.Sprintf("%d:frontend:topMovements", utils.Config.Chain.Config.DepositChainID)
+			err = cache.TieredCache.Set(cacheKey, summarizeMovements(window), time.Hour)
+			if err != nil {
+				logger.Errorf("error caching top movements: %v", err)
+			}
+		}
+
+		if firstRun {
+			logger.Info("initialized top movements updater")
+			wg.Done()
+			firstRun = false
+		}
+		ReportStatus("topMovementsUpdater", "Running", nil)
+		time.Sleep(time.Second * 12)
+	}
+}
+
+// LatestTopMovements returns the summary of the most recent execution blocks, nil is returned if it is not available yet
+func LatestTopMovements() *types.TopMovements {
+	wanted := &types.TopMovements{}
+	cacheKey := fmt.Sprintf("%d:frontend:topMovements", utils.Config.Chain.Config.DepositChainID)
+
+	if wanted, err := cache.TieredCache.GetWithLocalTimeout(cacheKey, time.Second*5, wanted); err == nil {
+		return wanted.(*types.TopMovements)
+	} else {
+		logger.Errorf("error retrieving top movements from cache: %v", err)
+	}
+	return nil
+}
+
+// advanceMovementsWindow appends the blocks up to latest to the window and drops the blocks that fell out of it.
+// The window is rebuilt if a new block does not extend it, which happens after a reorg.
+func advanceMovementsWindow(window []*blockMovements, latest uint64) ([]*blockMovements, error) {
+	if latest == 0 {
+		return window, nil
+	}
+
+	start := uint64(1)
+	if latest >= topMovementsBlocks {
+		start = latest - topMovementsBlocks + 1
+	}
+	if len(window) > 0 && window[len(window)-1].number >= start {
+		start = window[len(window)-1].number + 1
+	}
+	if start > latest {
+		return window, nil
+	}
+
+	blocks := make([]*blockMovements, latest-start+1)
+	g := new(errgroup.Group)
+	g.SetLimit(10)
+	for n := start; n <= latest; n++ {
+		n := n
+		g.Go(func() error {
+			block, err := db.GetEth1Store().GetBlockFromBlocksTable(context.Background(), n)
+			if err != nil {
+				return fmt.Errorf("error retrieving block %v: %w", n, err)
+			}
+			blocks[n-start] = blockMovementsOf(block)
+			return nil
+		})
+	}
+	err := g.Wait()
+	if err != nil {
+		return window, err
+	}
+
+	if len(window) > 0 && !bytes.Equal(window[len(window)-1].hash, blocks[0].parentHash) {
+		logger.Infof("block %v does not extend the top movements window, rebuilding it", blocks[0].number)
+		return advanceMovementsWindow(window[:0], latest)
+	}
+
+	window = append(window, blocks...)
+	if len(window) > topMovementsBlocks {
+		window = append(window[:0], window[len(window)-topMovementsBlocks:]...)
+	}
+	return window, nil
+}
+
+func blockMovementsOf(block *types.Eth1Block) *blockMovements {
+	m := &blockMovements{
+		number:       block.GetNumber(),
+		hash:         block.GetHash(),
+		parentHash:   block.GetParentHash(),
+		transactions: uint64(len(block.GetTransactions())),
+		contracts:    make(map[string]*types.TopMovementContract),
+		gasUsed:      block.GetGasUsed(),
+		gasLimit:     block.GetGasLimit(),
+		gasPrices:    make([]float64, 0, len(block.GetTransactions())),
+	}
+
+	baseFee := new(big.Int).SetBytes(block.GetBaseFee())
+	m.baseFee = weiToGwei(baseFee)
+	m.burned = weiToEther(new(big.Int).Mul(baseFee, new(big.Int).SetUint64(block.GetGasUsed())))
+
+	for _, tx := range block.GetTransactions() {
+		m.gasPrices = append(m.gasPrices, weiToGwei(new(big.Int).SetBytes(tx.GetGasPrice())))
+
+		value := new(big.Int).SetBytes(tx.GetValue())
+		if value.Sign() > 0 {
+			m.transfers = append(m.transfers, &types.TopMovementTransfer{
+				Hash:  fmt.Sprintf("0x%x", tx.GetHash()),
+				Block: block.GetNumber(),
+				From:  common.BytesToAddress(tx.GetFrom()).Hex(),
+				To:    common.BytesToAddress(tx.GetTo()).Hex(),
+				Value: weiToEther(value),
+			})
+		}
+
+		// plain transfers to externally owned accounts use exactly 21000 gas
+		if len(tx.GetTo()) == 0 || (len(tx.GetItx()) == 0 && tx.GetGasUsed() <= 21000 && tx.GetErrorMsg() == "") {
+			continue
+		}
+		contract := common.BytesToAddress(tx.GetTo()).Hex()
+		if m.contracts[contract] == nil {
+			m.contracts[contract] = &types.TopMovementContract{Address: contract}
+		}
+		m.contracts[contract].Calls++
+		m.contracts[contract].GasUsed += tx.GetGasUsed()
+	}
+
+	sortTransfers(m.transfers)
+	if len(m.transfers) > topMovementsEntries {
+		m.transfers = m.transfers[:topMovementsEntries]
+	}
+	return m
+}
+
+func summarizeMovements(window []*blockMovements) *types.TopMovements {
+	summary := &types.TopMovements{
+		FromBlock: window[0].number,
+		ToBlock:   window[len(window)-1].number,
+		UpdatedAt: time.Now(),
+	}
+
+	transfers := make([]*types.TopMovementTransfer, 0, len(window)*topMovementsEntries)
+	contracts := make(map[string]*types.TopMovementContract)
+	gasPrices := make([]float64, 0)
+	gasLimit := uint64(0)
+	summary.Gas.MinBaseFee = window[0].baseFee
+	for _, m := range window {
+		summary.Transactions += m.transactions
+		transfers = append(transfers, m.transfers...)
+		for address, c := range m.contracts {
+			if contracts[address] == nil {
+				contracts[address] = &types.TopMovementContract{Address: address}
+			}
+			contracts[address].Calls += c.Calls
+			contracts[address].GasUsed += c.GasUsed
+		}
+		gasPrices = append(gasPrices, m.gasPrices...)
+
+		summary.Gas.GasUsed += m.gasUsed
+		gasLimit += m.gasLimit
+		summary.Gas.AvgBaseFee += m.baseFee / float64(len(window))
+		if m.baseFee < summary.Gas.MinBaseFee {
+			summary.Gas.MinBaseFee = m.baseFee
+		}
+		if m.baseFee > summary.Gas.MaxBaseFee {
+			summary.Gas.MaxBaseFee = m.baseFee
+		}
+		summary.Gas.Burned += m.burned
+	}
+	if gasLimit > 0 {
+		summary.Gas.Utilization = float64(summary.Gas.GasUsed) / float64(gasLimit)
+	}
+	if len(gasPrices) > 0 {
+		sort.Float64s(gasPrices)
+		summary.Gas.MedianGasPrice = gasPrices[len(gasPrices)/2]
+	}
+
+	sortTransfers(transfers)
+	if len(transfers) > topMovementsEntries {
+		transfers = transfers[:topMovementsEntries]
+	}
+	summary.LargestTransfers = transfers
+
+	summary.ActiveContracts = make([]*types.TopMovementContract, 0, len(contracts))
+	for _, c := range contracts {
+		summary.ActiveContracts = append(summary.ActiveContracts, c)
+	}
+	sort.Slice(summary.ActiveContracts, func(i, j int) bool {
+		if summary.ActiveContracts[i].Calls != summary.ActiveContracts[j].Calls {
+			return summary.ActiveContracts[i].Calls > summary.ActiveContracts[j].Calls
+		}
+		return summary.ActiveContracts[i].Address < summary.ActiveContracts[j].Address
+	})
+	summary.ActiveContracts = withoutDestroyedContracts(summary.ActiveContracts, topMovementsEntries)
+
+	names := make(map[string]string)
+	for _, t := range summary.LargestTransfers {
+		names[string(common.HexToAddress(t.From).Bytes())] = ""
+		names[string(common.HexToAddress(t.To).Bytes())] = ""
+	}
+	for _, c := range summary.ActiveContracts {
+		names[string(common.HexToAddress(c.Address).Bytes())] = ""
+	}
+	err := db.GetEth1Store().GetAddressNames(context.Background(), names)
+	if err != nil {
+		logger.Errorf("error retrieving names of the top movements: %v", err)
+		return summary
+	}
+	for _, t := range summary.LargestTransfers {
+		t.FromName = names[string(common.HexToAddress(t.From).Bytes())]
+		t.ToName = names[string(common.HexToAddress(t.To).Bytes())]
+	}
+	for _, c := range summary.ActiveContracts {
+		c.Name = names[string(common.HexToAddress(c.Address).Bytes())]
+	}
+
+	return summary
+}
+
+// withoutDestroyedContracts returns up to limit of the ranked contracts, contracts with a recorded self destruct are skipped
+func withoutDestroyedContracts(ranked []*types.TopMovementContract, limit int) []*types.TopMovementContract {
+	active := make([]*types.TopMovementContract, 0, limit)
+	for _, c := range ranked {
+		if len(active) == limit {
+			break
+		}
+		destruct, err := db.GetEth1Store().GetContractSelfDestruct(context.Background(), common.HexToAddress(c.Address).Bytes())
+		if err != nil {
+			logger.Errorf("error retrieving the self destruct of contract %v: %v", c.Address, err)
+		} else if destruct != nil {
+			continue
+		}
+		active = append(active, c)
+	}
+	return active
+}
+
+func sortTransfers(transfers []*types.TopMovementTransfer) {
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].Value != transfers[j].Value {
+			return transfers[i].Value > transfers[j].Value
+		}
+		return transfers[i].Hash < transfers[j].Hash
+	})
+}
+
+func weiToEther(wei *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return f
+}
+
+func weiToGwei(wei *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Float64()
+	return f
+}
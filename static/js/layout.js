@@ -80,7 +80,7 @@ function setUtc() {
 function setLocal() {
   if ($("#optionUtc").is(":checked") || $("#optionTs").is(":checked")) {
     var unixTs = $("#unixTs").text()
-    var ts = luxon.DateTime.fromMillis(unixTs * 1000)
+    var ts = applyTimezone(luxon.DateTime.fromMillis(unixTs * 1000), getTimestampPreferences().timezone)
     $("#timestamp").text(ts.toFormat("MMM-dd-yyyy HH:mm:ss") + " UTC" + ts.toFormat("Z"))
   }
 }
@@ -489,17 +489,17 @@ function formatAriaEthereumDate(elem) {
     format = "ff"
   }
 
+  var tsLuxon = applyTimezone(luxon.DateTime.fromMillis(dt * 1000), getTimestampPreferences().timezone)
   if (format === "FROMNOW") {
-    $(elem).text(getRelativeTime(luxon.DateTime.fromMillis(dt * 1000)))
-    $(elem).attr("title", luxon.DateTime.fromMillis(dt * 1000).toFormat("ff"))
+    $(elem).text(getRelativeTime(tsLuxon))
+    $(elem).attr("title", tsLuxon.toFormat("ff"))
     $(elem).attr("data-toggle", "tooltip")
   } else if (format === "LOCAL") {
-    var local = luxon.DateTime.fromMillis(dt * 1000)
-    $(elem).text(local.toFormat("MMM-dd-yyyy HH:mm:ss") + " UTC" + local.toFormat("Z"))
-    $(elem).attr("title", luxon.DateTime.fromMillis(dt * 1000).toFormat("ff"))
+    $(elem).text(tsLuxon.toFormat("MMM-dd-yyyy HH:mm:ss") + " UTC" + tsLuxon.toFormat("Z"))
+    $(elem).attr("title", tsLuxon.toFormat("ff"))
     $(elem).attr("data-toggle", "tooltip")
   } else {
-    $(elem).text(luxon.DateTime.fromMillis(dt * 1000).toFormat(format))
+    $(elem).text(tsLuxon.toFormat(format))
   }
 }
 
@@ -508,12 +508,19 @@ function formatTimestamps(selStr) {
   if (selStr !== undefined) {
     sel = $(selStr)
   }
+  var prefs = getTimestampPreferences()
   sel.find(".timestamp").each(function () {
     var ts = $(this).data("timestamp")
-    var tsLuxon = luxon.DateTime.fromMillis(ts * 1000)
-    $(this).attr("data-original-title", tsLuxon.toFormat("ff"))
+    var tsLuxon = applyTimezone(luxon.DateTime.fromMillis(ts * 1000), $(this).data("timezone") || prefs.timezone)
+    var mode = $(this).data("timestamp-mode") || prefs.mode
 
-    $(this).text(getRelativeTime(tsLuxon))
+    if (mode === "absolute") {
+      $(this).attr("data-original-title", getRelativeTime(tsLuxon))
+      $(this).text(tsLuxon.toFormat("ff ZZZZ"))
+    } else {
+      $(this).attr("data-original-title", tsLuxon.toFormat("ff ZZZZ"))
+      $(this).text(getRelativeTime(tsLuxon))
+    }
   })
 
   if (sel.find('[data-toggle="tooltip"]').tooltip) {
@@ -521,6 +528,27 @@ function formatTimestamps(selStr) {
   }
 }
 
+function getCookieValue(name) {
+  var match = document.cookie.match(new RegExp("(?:^|; )" + name + "=([^;]*)"))
+  return match ? decodeURIComponent(match[1]) : ""
+}
+
+// returns the timezone and relative / absolute mode the user selected for rendering timestamps
+function getTimestampPreferences() {
+  return {
+    timezone: getCookieValue("timezone"),
+    mode: getCookieValue("timestamp_mode") === "absolute" ? "absolute" : "relative",
+  }
+}
+
+function applyTimezone(tsLuxon, timezone) {
+  if (!timezone) {
+    return tsLuxon
+  }
+  var zoned = tsLuxon.setZone(timezone)
+  return zoned.isValid ? zoned : tsLuxon
+}
+
 function getLuxonDateFromTimestamp(ts) {
   if (!ts) {
     return
@@ -0,0 +1,107 @@
+/**
+ * Adds a select of the saved filters of the user for a table to the given container. Selecting a filter passes its parameters
+ * to applyParams, saving stores the parameters returned by currentParams under a name chosen by the user.
+ * @param {string} table The table the filters belong to, e.g. "address_transactions" or "validators"
+ * @param {string} containerId The id of the element the controls are rendered into
+ * @param {function} currentParams Returns the filter parameters the table is currently requested with
+ * @param {function} applyParams Reloads the table with the given filter parameters
+ */
+function setupSavedFilters(table, containerId, currentParams, applyParams) {
+  const container = document.getElementById(containerId)
+  if (!container) {
+    return
+  }
+  let csrfToken = ""
+  let filters = []
+
+  const select = document.createElement("select")
+  select.classList.add("custom-select", "custom-select-sm", "w-auto", "ml-2")
+  select.title = "Saved filters"
+  const saveButton = document.createElement("button")
+  saveButton.classList.add("btn", "btn-sm", "btn-outline-secondary", "ml-1")
+  saveButton.title = "Save the current filter"
+  saveButton.innerHTML = '<i class="fas fa-save"></i>'
+  const deleteButton = document.createElement("button")
+  deleteButton.classList.add("btn", "btn-sm", "btn-outline-secondary", "ml-1", "d-none")
+  deleteButton.title = "Delete the selected filter"
+  deleteButton.innerHTML = '<i class="fas fa-trash"></i>'
+  container.append(select, saveButton, deleteButton)
+
+  function render(selected) {
+    select.innerHTML = ""
+    const none = document.createElement("option")
+    none.value = ""
+    none.innerText = filters.length ? "Saved filters" : "No saved filters"
+    select.appendChild(none)
+    for (const filter of filters) {
+      const option = document.createElement("option")
+      option.value = filter.id
+      option.innerText = filter.name
+      select.appendChild(option)
+    }
+    select.value = selected || ""
+    deleteButton.classList.toggle("d-none", !select.value)
+  }
+
+  function load(selected) {
+    return fetch(`/user/filters?table=${table}`, { credentials: "include" })
+      .then(function (res) {
+        csrfToken = res.headers.get("X-CSRF-Token") || csrfToken
+        return res.json()
+      })
+      .then(function (res) {
+        if (res.status !== "OK") {
+          return
+        }
+        filters = res.data[0] || []
+        render(selected)
+      })
+      .catch(function (err) {
+        console.error("error loading saved filters", err)
+      })
+  }
+
+  select.addEventListener("change", function () {
+    deleteButton.classList.toggle("d-none", !select.value)
+    const filter = filters.find((f) => String(f.id) === select.value)
+    applyParams(filter ? filter.params : {})
+  })
+
+  saveButton.addEventListener("click", function () {
+    const name = prompt("Name of the filter")
+    if (!name) {
+      return
+    }
+    fetch("/user/filters/save", {
+      method: "POST",
+      headers: { "X-CSRF-Token": csrfToken, "Content-Type": "application/json" },
+      credentials: "include",
+      body: JSON.stringify({ table: table, name: name, params: currentParams() }),
+    })
+      .then(function (res) {
+        return res.json()
+      })
+      .then(function (res) {
+        if (res.status !== "OK") {
+          alert(res.status)
+          return
+        }
+        load(String(res.data[0].id))
+      })
+  })
+
+  deleteButton.addEventListener("click", function () {
+    if (!select.value || !confirm("Do you really want to delete this filter?")) {
+      return
+    }
+    fetch(`/user/filters/${select.value}/delete`, {
+      method: "POST",
+      headers: { "X-CSRF-Token": csrfToken },
+      credentials: "include",
+    }).then(function () {
+      load("")
+    })
+  })
+
+  load("")
+}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+)
+
+type AlertRuleMetric string
+
+const (
+	// decrease of the validator balance in ETH over the rule window
+	AlertMetricValidatorBalanceDecrease AlertRuleMetric = "validator_balance_decrease"
+	// number of missed attestations of the validator within the rule window
+	AlertMetricValidatorMissedAttestations AlertRuleMetric = "validator_missed_attestations"
+	// number of missed proposals of the validator within the rule window
+	AlertMetricValidatorMissedProposals AlertRuleMetric = "validator_missed_proposals"
+	// largest amount of ETH the address received within the rule window from a single counterparty it has not interacted with before
+	AlertMetricAddressReceivedFromNewCounterparty AlertRuleMetric = "address_received_from_new_counterparty"
+)
+
+var AlertRuleMetrics = []AlertRuleMetric{
+	AlertMetricValidatorBalanceDecrease,
+	AlertMetricValidatorMissedAttestations,
+	AlertMetricValidatorMissedProposals,
+	AlertMetricAddressReceivedFromNewCounterparty,
+}
+
+// IsValidatorMetric returns true if the target of the metric is a validator index, otherwise it is an address
+func (m AlertRuleMetric) IsValidatorMetric() bool {
+	return m == AlertMetricValidatorBalanceDecrease || m == AlertMetricValidatorMissedAttestations || m == AlertMetricValidatorMissedProposals
+}
+
+const (
+	AlertOperatorAnd = "and"
+	AlertOperatorOr  = "or"
+	AlertOperatorNot = "not"
+)
+
+var alertComparators = map[string]func(value, threshold float64) bool{
+	"gt":  func(value, threshold float64) bool { return value > threshold },
+	"gte": func(value, threshold float64) bool { return value >= threshold },
+	"lt":  func(value, threshold float64) bool { return value < threshold },
+	"lte": func(value, threshold float64) bool { return value <= threshold },
+	"eq":  func(value, threshold float64) bool { return value == threshold },
+}
+
+const (
+	MaxAlertConditionDepth  = 4
+	MaxAlertConditionLeaves = 10
+	MaxAlertRuleWindow      = 225
+)
+
+// AlertCondition is a node of the condition tree of an alert rule. Composite nodes combine their child conditions
+// with the operator, leaf nodes compare a metric of the target against the threshold.
+type AlertCondition struct {
+	Operator   string            `json:"operator,omitempty"`
+	Conditions []*AlertCondition `json:"conditions,omitempty"`
+	Metric     AlertRuleMetric   `json:"metric,omitempty"`
+	Target     string            `json:"target,omitempty"`
+	Comparator string            `json:"comparator,omitempty"`
+	Threshold  float64           `json:"threshold,omitempty"`
+}
+
+func (c *AlertCondition) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &c)
+}
+
+func (c AlertCondition) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// IsLeaf returns true if the condition compares a metric instead of combining other conditions
+func (c *AlertCondition) IsLeaf() bool {
+	return c.Operator == ""
+}
+
+// Compare applies the comparator of a leaf condition to the value of its metric
+func (c *AlertCondition) Compare(value float64) bool {
+	compare, ok := alertComparators[c.Comparator]
+	return ok && compare(value, c.Threshold)
+}
+
+// Leaves returns all leaf conditions of the tree
+func (c *AlertCondition) Leaves() []*AlertCondition {
+	if c.IsLeaf() {
+		return []*AlertCondition{c}
+	}
+	leaves := []*AlertCondition{}
+	for _, child := range c.Conditions {
+		if child != nil {
+			leaves = append(leaves, child.Leaves()...)
+		}
+	}
+	return leaves
+}
+
+// Validate checks the structure of the condition tree as well as the metrics, targets and comparators of its leaves
+func (c *AlertCondition) Validate() error {
+	if len(c.Leaves()) > MaxAlertConditionLeaves {
+		return fmt.Errorf("a rule may contain at most %v conditions", MaxAlertConditionLeaves)
+	}
+	return c.validate(1)
+}
+
+func (c *AlertCondition) validate(depth int) error {
+	if depth > MaxAlertConditionDepth {
+		return fmt.Errorf("conditions may be nested at most %v levels deep", MaxAlertConditionDepth)
+	}
+
+	switch c.Operator {
+	case AlertOperatorAnd, AlertOperatorOr:
+		if len(c.Conditions) < 2 {
+			return fmt.Errorf("operator %v requires at least two conditions", c.Operator)
+		}
+	case AlertOperatorNot:
+		if len(c.Conditions) != 1 {
+			return fmt.Errorf("operator %v requires exactly one condition", c.Operator)
+		}
+	case "":
+		return c.validateLeaf()
+	default:
+		return fmt.Errorf("unknown operator %q", c.Operator)
+	}
+
+	for _, child := range c.Conditions {
+		if child == nil {
+			return fmt.Errorf("empty condition")
+		}
+		err := child.validate(depth + 1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *AlertCondition) validateLeaf() error {
+	if len(c.Conditions) > 0 {
+		return fmt.Errorf("condition on metric %v must not contain further conditions", c.Metric)
+	}
+	known := false
+	for _, m := range AlertRuleMetrics {
+		if m == c.Metric {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown metric %q", c.Metric)
+	}
+	if _, ok := alertComparators[c.Comparator]; !ok {
+		return fmt.Errorf("unknown comparator %q", c.Comparator)
+	}
+
+	if c.Metric.IsValidatorMetric() {
+		if _, err := strconv.ParseUint(c.Target, 10, 64); err != nil {
+			return fmt.Errorf("the target of metric %v must be a validator index", c.Metric)
+		}
+	} else if !common.IsHexAddress(c.Target) {
+		return fmt.Errorf("the target of metric %v must be an address", c.Metric)
+	}
+	return nil
+}
+
+// AlertRule is a user defined composite alert that is evaluated by the notification collector every epoch
+type AlertRule struct {
+	ID        uint64         `db:"id" json:"id"`
+	UserID    uint64         `db:"user_id" json:"-"`
+	Name      string         `db:"name" json:"name"`
+	Condition AlertCondition `db:"condition" json:"condition"`
+	// number of epochs the metrics of the conditions are aggregated over
+	Window    uint64    `db:"window_epochs" json:"window"`
+	CreatedTs time.Time `db:"created_ts" json:"created_ts"`
+}
+
+// ApiAlertRuleBuilderResponse describes the building blocks of alert rule conditions
+type ApiAlertRuleBuilderResponse struct {
+	Metrics     []ApiAlertRuleMetric `json:"metrics"`
+	Comparators []string             `json:"comparators"`
+	Operators   []string             `json:"operators"`
+	MaxWindow   uint64               `json:"max_window"`
+	MaxDepth    int                  `json:"max_depth"`
+	MaxLeaves   int                  `json:"max_leaves"`
+}
+
+type ApiAlertRuleMetric struct {
+	Metric      AlertRuleMetric `json:"metric"`
+	Target      string          `json:"target"`
+	Unit        string          `json:"unit,omitempty"`
+	Description string          `json:"description"`
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Denomination is a unit an amount can be expressed in, Decimals is the exponent of the unit relative to the smallest unit
+type Denomination struct {
+	Unit     string
+	Decimals int32
+}
+
+var (
+	Wei   = Denomination{Unit: "Wei", Decimals: 0}
+	GWei  = Denomination{Unit: "GWei", Decimals: 9}
+	Ether = Denomination{Unit: "ETH", Decimals: 18}
+)
+
+// DenominationByUnit returns the denomination of an ether unit, the lookup is case insensitive and accepts "Ether" as well as "ETH"
+func DenominationByUnit(unit string) (Denomination, bool) {
+	switch strings.ToLower(unit) {
+	case "wei":
+		return Wei, true
+	case "gwei":
+		return GWei, true
+	case "eth", "ether":
+		return Ether, true
+	}
+	return Denomination{}, false
+}
+
+// Amount is a value in the smallest unit of its currency (wei for ether) together with the number of decimals and the symbol
+// of the currency. Amounts are serialized to json as strings of the value in the smallest unit to not lose any precision.
+type Amount struct {
+	Value    *big.Int
+	Decimals int32
+	Symbol   string
+}
+
+// NewEtherAmount returns an amount of wei
+func NewEtherAmount(wei *big.Int) Amount {
+	if wei == nil {
+		wei = new(big.Int)
+	}
+	return Amount{Value: wei, Decimals: Ether.Decimals, Symbol: Ether.Unit}
+}
+
+// EtherAmountFromBytes returns an amount of wei stored as big endian bytes, the encoding of the amounts in the Eth1*Indexed protos
+func EtherAmountFromBytes(wei []byte) Amount {
+	return NewEtherAmount(new(big.Int).SetBytes(wei))
+}
+
+// TokenAmountFromBytes returns an amount of an erc20 token, the decimals are taken from the metadata of the token if it is known
+func TokenAmountFromBytes(value []byte, metadata *ERC20Metadata) Amount {
+	amount := Amount{Value: new(big.Int).SetBytes(value)}
+	if metadata != nil {
+		amount.Decimals = int32(new(big.Int).SetBytes(metadata.Decimals).Int64())
+		amount.Symbol = metadata.Symbol
+	}
+	return amount
+}
+
+// BigInt returns the value in the smallest unit, it is never nil
+func (a Amount) BigInt() *big.Int {
+	if a.Value == nil {
+		return new(big.Int)
+	}
+	return a.Value
+}
+
+// Bytes returns the big endian bytes of the value as stored in the Eth1*Indexed protos
+func (a Amount) Bytes() []byte {
+	return a.BigInt().Bytes()
+}
+
+func (a Amount) IsZero() bool {
+	return a.BigInt().Sign() == 0
+}
+
+// Add returns the sum of two amounts of the same currency
+func (a Amount) Add(b Amount) Amount {
+	a.Value = new(big.Int).Add(a.BigInt(), b.BigInt())
+	return a
+}
+
+// Decimal returns the value in whole units of the currency
+func (a Amount) Decimal() decimal.Decimal {
+	return decimal.NewFromBigInt(a.BigInt(), -a.Decimals)
+}
+
+// In returns the value of an ether amount in the given denomination
+func (a Amount) In(d Denomination) decimal.Decimal {
+	return decimal.NewFromBigInt(a.BigInt(), -d.Decimals)
+}
+
+// String returns the exact value in whole units of the currency, e.g. "1.5" for 1.5 ETH
+func (a Amount) String() string {
+	return a.Decimal().String()
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.BigInt().String())
+}
+
+// UnmarshalJSON accepts the value in the smallest unit as string or as number, the currency is not part of the json
+// representation and is kept
+func (a *Amount) UnmarshalJSON(p []byte) error {
+	s := strings.Trim(string(p), `"`)
+	if s == "null" {
+		return nil
+	}
+	value, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal Amount: invalid value %q", s)
+	}
+	a.Value = value
+	return nil
+}
+
+func (x *Eth1BlockIndexed) BaseFeeAmount() Amount {
+	return EtherAmountFromBytes(x.GetBaseFee())
+}
+
+func (x *Eth1BlockIndexed) BurnedFeesAmount() Amount {
+	return EtherAmountFromBytes(x.GetBurnedFees())
+}
+
+func (x *Eth1BlockIndexed) TxRewardAmount() Amount {
+	return EtherAmountFromBytes(x.GetTxReward())
+}
+
+func (x *Eth1BlockIndexed) UncleRewardAmount() Amount {
+	return EtherAmountFromBytes(x.GetUncleReward())
+}
+
+func (x *Eth1BlockIndexed) MevAmount() Amount {
+	return EtherAmountFromBytes(x.GetMev())
+}
+
+func (x *Eth1TransactionIndexed) ValueAmount() Amount {
+	return EtherAmountFromBytes(x.GetValue())
+}
+
+func (x *Eth1TransactionIndexed) TxFeeAmount() Amount {
+	return EtherAmountFromBytes(x.GetTxFee())
+}
+
+func (x *Eth1TransactionIndexed) GasPriceAmount() Amount {
+	return EtherAmountFromBytes(x.GetGasPrice())
+}
+
+func (x *Eth1InternalTransactionIndexed) ValueAmount() Amount {
+	return EtherAmountFromBytes(x.GetValue())
+}
+
+// ValueAmount returns the transferred amount of the token, metadata may be nil if the token is unknown
+func (x *Eth1ERC20Indexed) ValueAmount(metadata *ERC20Metadata) Amount {
+	return TokenAmountFromBytes(x.GetValue(), metadata)
+}
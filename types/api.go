@@ -12,6 +12,14 @@ import (
 type ApiResponse struct {
 	Status string      `json:"status"`
 	Data   interface{} `json:"data"`
+	// position of a paginated list response, pass Next as the cursor parameter to receive the following page
+	Cursor *ApiCursor `json:"cursor,omitempty"`
+}
+
+type ApiCursor struct {
+	Next  string `json:"next,omitempty"`
+	Limit uint64 `json:"limit"`
+	Order string `json:"order"`
 }
 
 type StatsSystem struct {
@@ -209,6 +217,16 @@ func (a DiscordReq) Value() (driver.Value, error) {
 	return json.Marshal(a)
 }
 
+// ApiEth1AddressBalanceAtResponse holds the balance of an address at a historical block, Balance is denominated in the
+// smallest unit of the token (wei for ether), Source is either "history" or "node"
+type ApiEth1AddressBalanceAtResponse struct {
+	Address string `json:"address"`
+	Token   string `json:"token"`
+	Block   uint64 `json:"block"`
+	Balance string `json:"balance"`
+	Source  string `json:"source"`
+}
+
 type ApiEth1AddressResponse struct {
 	Address string `json:"address"`
 	Ether   string `json:"ether"`
@@ -220,6 +238,34 @@ type ApiEth1AddressResponse struct {
 		Price    float64 `json:"price,omitempty"`
 		Currency string  `json:"currency,omitempty"`
 	} `json:"tokens"`
+	Risk *AddressRisk `json:"risk,omitempty"`
+}
+
+// ApiEth1AddressTokenBalancesResponse lists the balances of an address, balances are adjusted by the decimals of their token. The USD
+// values are only set for tokens with a known price.
+type ApiEth1AddressTokenBalancesResponse struct {
+	Address      string                         `json:"address"`
+	Ether        *ApiEth1AddressTokenBalance    `json:"ether"`
+	ERC20        []*ApiEth1AddressTokenBalance  `json:"erc20"`
+	NFTs         []*ApiEth1AddressNFTCollection `json:"nfts"`
+	NFTsComplete bool                           `json:"nfts_complete"`
+	ValueUSD     float64                        `json:"value_usd"`
+}
+
+type ApiEth1AddressTokenBalance struct {
+	Token    string  `json:"token,omitempty"`
+	Symbol   string  `json:"symbol"`
+	Decimals uint64  `json:"decimals"`
+	Balance  string  `json:"balance"`
+	PriceUSD float64 `json:"price_usd,omitempty"`
+	ValueUSD float64 `json:"value_usd,omitempty"`
+}
+
+type ApiEth1AddressNFTCollection struct {
+	Token    string `json:"token"`
+	Standard string `json:"standard"`
+	Tokens   uint64 `json:"tokens"`
+	Balance  string `json:"balance"`
 }
 
 type APIEth1AddressTxResponse struct {
@@ -227,6 +273,74 @@ type APIEth1AddressTxResponse struct {
 	Page         string                  `json:"page"`
 }
 
+type APIEth1AddressCounterpartiesResponse struct {
+	Address        string                 `json:"address"`
+	Since          time.Time              `json:"since"`
+	Truncated      bool                   `json:"truncated"`
+	Counterparties []*AddressCounterparty `json:"counterparties"`
+}
+
+type APIEth1AddressContractInteraction struct {
+	Contract     string `json:"contract"`
+	Name         string `json:"name,omitempty"`
+	Transactions uint64 `json:"transactions"`
+	GasUsed      uint64 `json:"gas_used"`
+	Fees         string `json:"fees"`
+}
+
+// APISnapshot is a periodically generated copy of frequently requested data that can be served by a CDN
+type APISnapshot struct {
+	Name        string      `json:"name"`
+	GeneratedAt int64       `json:"generated_at"`
+	Data        interface{} `json:"data"`
+}
+
+type APISnapshotBlock struct {
+	Number           uint64 `json:"number"`
+	Hash             string `json:"hash"`
+	Timestamp        int64  `json:"timestamp"`
+	FeeRecipient     string `json:"fee_recipient"`
+	GasUsed          uint64 `json:"gas_used"`
+	GasLimit         uint64 `json:"gas_limit"`
+	BaseFee          string `json:"base_fee"`
+	TransactionCount uint64 `json:"transaction_count"`
+}
+
+type APISnapshotNetworkStats struct {
+	LatestBlock           uint64  `json:"latest_block"`
+	CurrentEpoch          uint64  `json:"current_epoch"`
+	CurrentSlot           uint64  `json:"current_slot"`
+	CurrentFinalizedEpoch uint64  `json:"current_finalized_epoch"`
+	FinalityDelay         uint64  `json:"finality_delay"`
+	ActiveValidators      uint64  `json:"active_validators"`
+	EnteringValidators    uint64  `json:"entering_validators"`
+	ExitingValidators     uint64  `json:"exiting_validators"`
+	StakedEther           string  `json:"staked_ether"`
+	EthPriceUSD           float64 `json:"eth_price_usd"`
+}
+
+type APIEth1TransferPathResponse struct {
+	JobID  string              `json:"job_id,omitempty"`
+	Status string              `json:"status"`
+	Result *TransferPathResult `json:"result,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+type AddressCounterparty struct {
+	Address   string                 `json:"address"`
+	Name      string                 `json:"name,omitempty"`
+	Transfers uint64                 `json:"transfers"`
+	Flows     []*AddressTransferFlow `json:"flows"`
+}
+
+// AddressTransferFlow holds the decimal adjusted amounts of a single asset sent to (out) and received from (in) a counterparty
+type AddressTransferFlow struct {
+	Token  string `json:"token,omitempty"`
+	Symbol string `json:"symbol"`
+	In     string `json:"in"`
+	Out    string `json:"out"`
+}
+
 type Eth1TransactionParsed struct {
 	Hash               string    `json:"hash,omitempty"`
 	BlockNumber        uint64    `json:"block,omitempty"`
@@ -241,6 +355,50 @@ type Eth1TransactionParsed struct {
 	InvokesContract    bool      `json:"invokes_contract,omitempty"`
 }
 
+// APIEth1TxResponse is a transaction together with its logs and internal transactions, Status is either "success" or "failed"
+type APIEth1TxResponse struct {
+	Transaction          Eth1TransactionParsed           `json:"transaction"`
+	TxIndex              uint64                          `json:"tx_index"`
+	Status               string                          `json:"status"`
+	Error                string                          `json:"error,omitempty"`
+	Logs                 []Eth1LogParsed                 `json:"logs"`
+	InternalTransactions []Eth1InternalTransactionParsed `json:"internal_transactions"`
+	Warnings             []string                        `json:"warnings,omitempty"`
+}
+
+// APIEth1TransactionsRequest is the body of the bulk transaction lookup
+type APIEth1TransactionsRequest struct {
+	Hashes []string `json:"hashes"`
+}
+
+// APIEth1TransactionsResponse contains the indexed transactions of a bulk lookup, hashes that have not been indexed are listed in NotFound
+type APIEth1TransactionsResponse struct {
+	Transactions []APIEth1TransactionsEntry `json:"transactions"`
+	NotFound     []string                   `json:"not_found"`
+	Warnings     []string                   `json:"warnings,omitempty"`
+}
+
+type APIEth1TransactionsEntry struct {
+	Transaction Eth1TransactionParsed `json:"transaction"`
+	TxIndex     uint64                `json:"tx_index"`
+	Status      string                `json:"status"`
+	Error       string                `json:"error,omitempty"`
+}
+
+// ApiEnsResponse is an ENS name together with the address it resolves to
+type ApiEnsResponse struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+type Eth1LogParsed struct {
+	Index   uint64   `json:"index"`
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+	Removed bool     `json:"removed,omitempty"`
+}
+
 type APIEth1AddressItxResponse struct {
 	InternalTransactions []Eth1InternalTransactionParsed `json:"internal_transactions"`
 	Page                 string                          `json:"page"`
@@ -254,6 +412,8 @@ type Eth1InternalTransactionParsed struct {
 	From        string    `json:"from"`
 	To          string    `json:"to"`
 	Value       string    `json:"value"`
+	Path        string    `json:"path,omitempty"`
+	Index       uint64    `json:"index"`
 }
 
 type APIEth1AddressBlockResponse struct {
@@ -301,6 +461,23 @@ type Eth1UncleParsed struct {
 	Reward      string    `json:"reward,omitempty"`
 }
 
+type APIEth1AddressLogResponse struct {
+	Logs []*Eth1ContractLogParsed `json:"logs"`
+	Page string                   `json:"page"`
+}
+
+type Eth1ContractLogParsed struct {
+	TxHash      string    `json:"transaction"`
+	BlockNumber uint64    `json:"block"`
+	Time        time.Time `json:"time"`
+	TxIndex     uint64    `json:"tx_index"`
+	LogIndex    uint64    `json:"log_index"`
+	Address     string    `json:"address"`
+	Event       string    `json:"event,omitempty"`
+	Topics      []string  `json:"topics"`
+	Data        string    `json:"data"`
+}
+
 type APIEth1TokenResponse struct {
 	TokenTxs []*Eth1TokenTxParsed `json:"transactions"`
 	Page     string               `json:"page"`
@@ -322,6 +499,10 @@ type ApiWithdrawalCredentialsResponse struct {
 	ValidatorIndex uint64 `json:"validatorindex"`
 }
 
+type ApiWatchlistImportResponse struct {
+	Validators []string `json:"validators"`
+}
+
 type APIEpochResponse struct {
 	Epoch                   uint64 `json:"epoch"`
 	Ts                      uint64 `json:"ts"`
@@ -655,6 +836,19 @@ type ApiValidatorAttestationsResponse struct {
 	WeekEnd        time.Time `json:"week_end"`
 }
 
+// ApiValidatorDailyAttestationsResponse is the attestation result of a validator downsampled to a day
+type ApiValidatorDailyAttestationsResponse struct {
+	ValidatorIndex       uint64    `db:"validatorindex" json:"validatorindex"`
+	Day                  uint64    `db:"day" json:"day"`
+	DayStart             time.Time `db:"-" json:"day_start"`
+	DayEnd               time.Time `db:"-" json:"day_end"`
+	AssignedAttestations uint64    `db:"assigned_attestations" json:"assigned_attestations"`
+	ExecutedAttestations uint64    `db:"-" json:"executed_attestations"`
+	MissedAttestations   uint64    `db:"missed_attestations" json:"missed_attestations"`
+	OrphanedAttestations uint64    `db:"orphaned_attestations" json:"orphaned_attestations"`
+	ParticipationRate    float64   `db:"-" json:"participation_rate"`
+}
+
 // convert this json object to a golang struct called ApiValidatorProposalsResponse
 type ApiValidatorProposalsResponse struct {
 	Attestationscount          uint64  `db:"attestationscount" json:"attestationscount"`
@@ -694,3 +888,105 @@ type ApiValidatorProposalsResponse struct {
 	SyncaggregateSignature     string  `db:"syncaggregate_signature" json:"syncaggregate_signature"`
 	Voluntaryexitscount        uint64  `db:"voluntaryexitscount" json:"voluntaryexitscount"`
 }
+
+// metrics of the daily validator statistics for which network wide distributions are exported
+const (
+	ValidatorStatsMetricClRewards                = "cl_rewards_gwei"
+	ValidatorStatsMetricAttestationParticipation = "attestation_participation"
+)
+
+// APIValidatorBenchmark compares the daily averages of a validator set against the network wide distributions of the same days
+type APIValidatorBenchmark struct {
+	Validators               int                             `json:"validators"`
+	FromDay                  uint64                          `json:"from_day"`
+	ToDay                    uint64                          `json:"to_day"`
+	ClRewards                *APIValidatorBenchmarkMetric    `json:"cl_rewards_gwei"`
+	AttestationParticipation *APIValidatorBenchmarkMetric    `json:"attestation_participation"`
+	Days                     []*APIValidatorBenchmarkDayData `json:"days"`
+}
+
+// APIValidatorBenchmarkMetric holds the average daily value of a metric for the validator set and the network median,
+// Percentile is the average rank (0-100) of the validator set within the network distributions
+type APIValidatorBenchmarkMetric struct {
+	Value         float64 `json:"value"`
+	NetworkMedian float64 `json:"network_median"`
+	Percentile    float64 `json:"percentile"`
+}
+
+type APIValidatorBenchmarkDayData struct {
+	Day                      uint64                       `json:"day"`
+	ClRewards                *APIValidatorBenchmarkMetric `json:"cl_rewards_gwei"`
+	AttestationParticipation *APIValidatorBenchmarkMetric `json:"attestation_participation"`
+}
+
+// APINFTCollectionResponse summarizes an ERC721 or ERC1155 collection, the owner count is a lower bound if OwnersComplete is false
+type APINFTCollectionResponse struct {
+	Address        string                   `json:"address"`
+	Name           string                   `json:"name"`
+	Symbol         string                   `json:"symbol"`
+	Standard       string                   `json:"standard"`
+	Owners         uint64                   `json:"owners"`
+	OwnersComplete bool                     `json:"owners_complete"`
+	DailyMints     []*APINFTCollectionMints `json:"daily_mints"`
+}
+
+// APINFTMetadataResponse is the metadata of an ERC721 or ERC1155 token, Preview is the path of a thumbnail of the image served by
+// the explorer
+type APINFTMetadataResponse struct {
+	Address      string          `json:"address"`
+	TokenId      string          `json:"token_id"`
+	Standard     string          `json:"standard"`
+	TokenURI     string          `json:"token_uri"`
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	Image        string          `json:"image"`
+	Preview      string          `json:"preview"`
+	AnimationURL string          `json:"animation_url"`
+	ExternalURL  string          `json:"external_url"`
+	Attributes   []*NFTAttribute `json:"attributes"`
+	Error        string          `json:"error,omitempty"`
+}
+
+type APINFTCollectionMints struct {
+	Day     time.Time `json:"day"`
+	Mints   uint64    `json:"mints"`
+	Minters uint64    `json:"minters"`
+}
+
+type APINFTCollectionTransfersResponse struct {
+	Transfers []*APINFTTransfer `json:"transfers"`
+	Page      string            `json:"page"`
+}
+
+type APINFTTransfer struct {
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block"`
+	Time        time.Time `json:"time"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	TokenId     string    `json:"token_id"`
+	Quantity    string    `json:"quantity"`
+}
+
+type APICreate2Response struct {
+	Address     string               `json:"address"`
+	HasActivity bool                 `json:"has_activity"`
+	Creation    *APIContractCreation `json:"creation,omitempty"`
+}
+
+type APIContractCreation struct {
+	TxHash      string    `json:"tx_hash"`
+	BlockNumber uint64    `json:"block"`
+	Time        time.Time `json:"time"`
+	Deployer    string    `json:"deployer"`
+	Type        string    `json:"type"`
+}
+
+type APIAAOperator struct {
+	Address     string  `json:"address"`
+	Operations  uint64  `json:"operations"`
+	Successful  uint64  `json:"successful"`
+	SuccessRate float64 `json:"success_rate"`
+	GasUsed     string  `json:"gas_used"`
+	GasCost     string  `json:"gas_cost"`
+}
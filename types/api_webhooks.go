@@ -0,0 +1,81 @@
+package types
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ApiWebhookEvent is an operational event api consumers can receive through the webhooks of their api key
+type ApiWebhookEvent string
+
+const (
+	// the execution data index lags behind the blocks table by more than the threshold of the webhook
+	ApiWebhookIndexLag ApiWebhookEvent = "index_lag"
+	// a planned maintenance has been announced
+	ApiWebhookMaintenance ApiWebhookEvent = "maintenance"
+	// a part of the api or of its response schema has been deprecated
+	ApiWebhookDeprecation ApiWebhookEvent = "deprecation"
+)
+
+var ApiWebhookEvents = []ApiWebhookEvent{ApiWebhookIndexLag, ApiWebhookMaintenance, ApiWebhookDeprecation}
+
+const (
+	DefaultApiWebhookLagThreshold = 32
+	MaxApiWebhooks                = 5
+	// index_lag events are repeated at most once per interval while the lag persists
+	ApiWebhookLagInterval = time.Hour
+)
+
+type ApiWebhook struct {
+	ID             uint64         `db:"id" json:"id"`
+	UserID         uint64         `db:"user_id" json:"-"`
+	Url            string         `db:"url" json:"url"`
+	Events         pq.StringArray `db:"events" json:"events"`
+	LagThreshold   uint64         `db:"lag_threshold" json:"lag_threshold"`
+	LastLagSent    sql.NullTime   `db:"last_lag_sent" json:"-"`
+	LastIncidentID uint64         `db:"last_incident_id" json:"-"`
+	Retries        uint64         `db:"retries" json:"retries"`
+	CreatedTs      time.Time      `db:"created_ts" json:"created_ts"`
+}
+
+// Subscribed returns true if the webhook receives the given event
+func (w *ApiWebhook) Subscribed(event ApiWebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == string(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApiIncident is a planned maintenance or a deprecation announced to the api consumers
+type ApiIncident struct {
+	ID          uint64          `db:"id" json:"id"`
+	Kind        ApiWebhookEvent `db:"kind" json:"kind"`
+	Title       string          `db:"title" json:"title"`
+	Description string          `db:"description" json:"description"`
+	StartTs     sql.NullTime    `db:"start_ts" json:"-"`
+	EndTs       sql.NullTime    `db:"end_ts" json:"-"`
+	CreatedTs   time.Time       `db:"created_ts" json:"created_ts"`
+}
+
+type ApiWebhookIndexLagData struct {
+	BlocksTable uint64 `json:"blocks_table"`
+	DataTable   uint64 `json:"data_table"`
+	Lag         uint64 `json:"lag"`
+	Threshold   uint64 `json:"threshold"`
+}
+
+// ApiWebhookPayload is the body posted to the webhooks
+type ApiWebhookPayload struct {
+	Event    ApiWebhookEvent         `json:"event"`
+	Network  string                  `json:"network"`
+	Time     time.Time               `json:"time"`
+	IndexLag *ApiWebhookIndexLagData `json:"index_lag,omitempty"`
+	Incident *ApiIncident            `json:"incident,omitempty"`
+	// start and end of a maintenance window, unset for other events
+	StartTs *time.Time `json:"start_ts,omitempty"`
+	EndTs   *time.Time `json:"end_ts,omitempty"`
+}
@@ -24,6 +24,10 @@ type Config struct {
 	Bigtable struct {
 		Project  string `yaml:"project" envconfig:"BIGTABLE_PROJECT"`
 		Instance string `yaml:"instance" envconfig:"BIGTABLE_INSTANCE"`
+		// index rows older than the hot window are read from the archive table once they have been moved there
+		Archive bool `yaml:"archive" envconfig:"BIGTABLE_ARCHIVE"`
+		// number of attempts of reads that fail with a transient error, defaults to 3
+		ReadAttempts int `yaml:"readAttempts" envconfig:"BIGTABLE_READ_ATTEMPTS"`
 	} `yaml:"bigtable"`
 	LastAttestationCachePath string `yaml:"lastAttestationCachePath" envconfig:"LAST_ATTESTATION_CACHE_PATH"`
 	Chain                    struct {
@@ -39,6 +43,7 @@ type Config struct {
 	Eth1GethEndpoint    string `yaml:"eth1GethEndpoint" envconfig:"ETH1_GETH_ENDPOINT"`
 	EtherscanAPIKey     string `yaml:"etherscanApiKey" envconfig:"ETHERSCAN_API_KEY"`
 	EtherscanAPIBaseURL string `yaml:"etherscanApiBaseUrl" envconfig:"ETHERSCAN_API_BASEURL"`
+	SourcifyAPIBaseURL  string `yaml:"sourcifyApiBaseUrl" envconfig:"SOURCIFY_API_BASEURL"`
 	RedisCacheEndpoint  string `yaml:"redisCacheEndpoint" envconfig:"REDIS_CACHE_ENDPOINT"`
 	TieredCacheProvider string `yaml:"tieredCacheProvider" envconfig:"CACHE_PROVIDER"`
 	ReportServiceStatus bool   `yaml:"reportServiceStatus" envconfig:"REPORT_SERVICE_STATUS"`
@@ -87,6 +92,8 @@ type Config struct {
 			Port string `yaml:"port" envconfig:"FRONTEND_SERVER_PORT"`
 			Host string `yaml:"host" envconfig:"FRONTEND_SERVER_HOST"`
 		} `yaml:"server"`
+		// additional (test) networks served next to the configured chain under /{name}/
+		Networks       []NetworkProfile `yaml:"networks"`
 		ReaderDatabase struct {
 			Username string `yaml:"user" envconfig:"FRONTEND_READER_DB_USERNAME"`
 			Password string `yaml:"password" envconfig:"FRONTEND_READER_DB_PASSWORD"`
@@ -148,6 +155,69 @@ type Config struct {
 		Validator struct {
 			ShowProposerRewards bool `yaml:"showProposerRewards" envconfig:"FRONTEND_SHOW_PROPOSER_REWARDS"`
 		} `yaml:"validator"`
+		// header in which a trusted reverse proxy passes the ip of the client (e.g. CF-Connecting-IP or X-Forwarded-For), the proxy
+		// has to set or append to it. The remote address of the connection is used if it is empty.
+		ClientIpHeader string `yaml:"clientIpHeader" envconfig:"FRONTEND_CLIENT_IP_HEADER"`
+		RpcProxy       struct {
+			Enabled           bool `yaml:"enabled" envconfig:"FRONTEND_RPC_PROXY_ENABLED"`
+			RequestsPerMinute int  `yaml:"requestsPerMinute" envconfig:"FRONTEND_RPC_PROXY_REQUESTS_PER_MINUTE"`
+			MaxBatchSize      int  `yaml:"maxBatchSize" envconfig:"FRONTEND_RPC_PROXY_MAX_BATCH_SIZE"`
+		} `yaml:"rpcProxy"`
+		Snapshots struct {
+			Enabled   bool          `yaml:"enabled" envconfig:"FRONTEND_SNAPSHOTS_ENABLED"`
+			Bucket    string        `yaml:"bucket" envconfig:"FRONTEND_SNAPSHOTS_BUCKET"`
+			PublicURL string        `yaml:"publicUrl" envconfig:"FRONTEND_SNAPSHOTS_PUBLIC_URL"`
+			Interval  time.Duration `yaml:"interval" envconfig:"FRONTEND_SNAPSHOTS_INTERVAL"`
+		} `yaml:"snapshots"`
+		NodeCrawler struct {
+			Enabled bool   `yaml:"enabled" envconfig:"FRONTEND_NODE_CRAWLER_ENABLED"`
+			Secret  string `yaml:"secret" envconfig:"FRONTEND_NODE_CRAWLER_SECRET"`
+		} `yaml:"nodeCrawler"`
+		// time the index scans of an interactive address table query may take before the rows found so far are returned, defaults to 2s
+		AddressQueryBudget   time.Duration `yaml:"addressQueryBudget" envconfig:"FRONTEND_ADDRESS_QUERY_BUDGET"`
+		AddressActivityCache struct {
+			Enabled  bool          `yaml:"enabled" envconfig:"FRONTEND_ADDRESS_ACTIVITY_CACHE_ENABLED"`
+			Size     int           `yaml:"size" envconfig:"FRONTEND_ADDRESS_ACTIVITY_CACHE_SIZE"`
+			Interval time.Duration `yaml:"interval" envconfig:"FRONTEND_ADDRESS_ACTIVITY_CACHE_INTERVAL"`
+		} `yaml:"addressActivityCache"`
+		AddressRisk struct {
+			Enabled          bool              `yaml:"enabled" envconfig:"FRONTEND_ADDRESS_RISK_ENABLED"`
+			FlaggedAddresses map[string]string `yaml:"flaggedAddresses" envconfig:"FRONTEND_ADDRESS_RISK_FLAGGED_ADDRESSES"` // address => reason, e.g. the name of a mixer
+			FreshFundingAge  time.Duration     `yaml:"freshFundingAge" envconfig:"FRONTEND_ADDRESS_RISK_FRESH_FUNDING_AGE"`  // addresses without activity before this age are considered fresh
+			FanOutThreshold  int               `yaml:"fanOutThreshold" envconfig:"FRONTEND_ADDRESS_RISK_FAN_OUT_THRESHOLD"`  // minimal number of distinct recipients within the fan out window
+		} `yaml:"addressRisk"`
+		// websocket stream of the blocks and address activity published by the eth1 indexer, requires the redis cache endpoint
+		Eth1Stream struct {
+			Enabled          bool `yaml:"enabled" envconfig:"FRONTEND_ETH1_STREAM_ENABLED"`
+			MaxConnections   int  `yaml:"maxConnections" envconfig:"FRONTEND_ETH1_STREAM_MAX_CONNECTIONS"`     // defaults to 1000
+			MaxSubscriptions int  `yaml:"maxSubscriptions" envconfig:"FRONTEND_ETH1_STREAM_MAX_SUBSCRIPTIONS"` // per connection, defaults to 10
+		} `yaml:"eth1Stream"`
+		// surrogate keys and ttls for a cdn in front of the explorer, the cdn has to bypass its cache for requests carrying the
+		// session, currency or timezone cookie. Cached entries are purged by posting {"keys":[...]} to the purge url once the underlying entity changes.
+		EdgeCache struct {
+			Enabled    bool          `yaml:"enabled" envconfig:"FRONTEND_EDGE_CACHE_ENABLED"`
+			MaxAge     time.Duration `yaml:"maxAge" envconfig:"FRONTEND_EDGE_CACHE_MAX_AGE"`       // defaults to 24h
+			KeyHeader  string        `yaml:"keyHeader" envconfig:"FRONTEND_EDGE_CACHE_KEY_HEADER"` // defaults to Surrogate-Key
+			PurgeURL   string        `yaml:"purgeUrl" envconfig:"FRONTEND_EDGE_CACHE_PURGE_URL"`
+			PurgeToken string        `yaml:"purgeToken" envconfig:"FRONTEND_EDGE_CACHE_PURGE_TOKEN"`
+		} `yaml:"edgeCache"`
+		// estimates the gas prices of the gasnow page and api from the most recent blocks of the data table instead of the txpool of the node
+		GasOracle struct {
+			Enabled  bool          `yaml:"enabled" envconfig:"FRONTEND_GAS_ORACLE_ENABLED"`
+			Blocks   int           `yaml:"blocks" envconfig:"FRONTEND_GAS_ORACLE_BLOCKS"`     // defaults to 20
+			Interval time.Duration `yaml:"interval" envconfig:"FRONTEND_GAS_ORACLE_INTERVAL"` // poll interval for new blocks, defaults to 500ms
+		} `yaml:"gasOracle"`
+		// subscribes to the pending transactions of the eth1 node instead of polling its txpool, pending transactions are shown on the
+		// mempool page and the pages of their sender and recipient until they are included in an indexed block
+		MempoolWatcher struct {
+			Enabled  bool          `yaml:"enabled" envconfig:"FRONTEND_MEMPOOL_WATCHER_ENABLED"`
+			Endpoint string        `yaml:"endpoint" envconfig:"FRONTEND_MEMPOOL_WATCHER_ENDPOINT"` // websocket endpoint of the node, defaults to eth1GethEndpoint
+			TTL      time.Duration `yaml:"ttl" envconfig:"FRONTEND_MEMPOOL_WATCHER_TTL"`           // pending transactions are dropped after the ttl, defaults to 30m
+		} `yaml:"mempoolWatcher"`
+		// the metadata and images of nfts are fetched from the uri of the token, ipfs uris are tried on the gateways in order
+		NFTMetadata struct {
+			IPFSGateways []string `yaml:"ipfsGateways" envconfig:"FRONTEND_NFT_METADATA_IPFS_GATEWAYS"` // defaults to ipfs.io, cloudflare-ipfs.com and dweb.link
+		} `yaml:"nftMetadata"`
 		HttpReadTimeout  time.Duration `yaml:"httpReadTimeout" envconfig:"FRONTEND_HTTP_READ_TIMEOUT"`
 		HttpWriteTimeout time.Duration `yaml:"httpWriteTimeout" envconfig:"FRONTEND_HTTP_WRITE_TIMEOUT"`
 		HttpIdleTimeout  time.Duration `yaml:"httpIdleTimeout" envconfig:"FRONTEND_HTTP_IDLE_TIMEOUT"`
@@ -179,6 +249,16 @@ type Config struct {
 		Enabled bool   `yaml:"enabled" envconfig:"PPROF_ENABLED"`
 		Port    string `yaml:"port" envconfig:"PPROF_PORT"`
 	} `yaml:"pprof"`
+	WhaleAlerts struct {
+		EtherThreshold  float64            `yaml:"etherThreshold" envconfig:"WHALE_ALERTS_ETHER_THRESHOLD"`   // transfers of at least this amount of ether are added to the whale feed, 0 disables ether alerts
+		TokenThresholds map[string]float64 `yaml:"tokenThresholds" envconfig:"WHALE_ALERTS_TOKEN_THRESHOLDS"` // token contract address => minimal decimal adjusted amount
+	} `yaml:"whaleAlerts"`
+	SyntheticProbes struct {
+		Address          string        `yaml:"address" envconfig:"SYNTHETIC_PROBES_ADDRESS"`                    // address with token transfers, defaults to the deposit contract
+		Block            uint64        `yaml:"block" envconfig:"SYNTHETIC_PROBES_BLOCK"`                        // execution block with many transactions, the block probe is skipped if not set
+		Validator        uint64        `yaml:"validator" envconfig:"SYNTHETIC_PROBES_VALIDATOR"`                // validator whose page is requested
+		LatencyThreshold time.Duration `yaml:"latencyThreshold" envconfig:"SYNTHETIC_PROBES_LATENCY_THRESHOLD"` // probes slower than this are counted as degraded, defaults to 5s
+	} `yaml:"syntheticProbes"`
 	NodeJobsProcessor struct {
 		ElEndpoint string `yaml:"elEndpoint" envconfig:"NODE_JOBS_PROCESSOR_EL_ENDPOINT"`
 		ClEndpoint string `yaml:"clEndpoint" envconfig:"NODE_JOBS_PROCESSOR_CL_ENDPOINT"`
@@ -192,3 +272,25 @@ type DatabaseConfig struct {
 	Host     string
 	Port     string
 }
+
+// NetworkProfile holds the data sources of an additional network the frontend serves execution layer pages for
+type NetworkProfile struct {
+	// url prefix of the network, e.g. goerli
+	Name    string `yaml:"name"`
+	Label   string `yaml:"label"`
+	Sandbox bool   `yaml:"sandbox"`
+	// execution layer chain id the bigtable rows of the network are keyed by, also selects the network in the execution api
+	ChainId string `yaml:"chainId"`
+	// the rows of the network are read from the bigtable instance of the frontend if no project is set
+	Bigtable struct {
+		Project  string `yaml:"project"`
+		Instance string `yaml:"instance"`
+	} `yaml:"bigtable"`
+	ReaderDatabase struct {
+		Username string `yaml:"user"`
+		Password string `yaml:"password"`
+		Name     string `yaml:"name"`
+		Host     string `yaml:"host"`
+		Port     string `yaml:"port"`
+	} `yaml:"readerDatabase"`
+}
@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+const (
+	// triggers when the method of the contract has been called
+	ContractAlertMethodCalled = "method_called"
+	// triggers when the number of reverted transactions of the contract within an hour reaches the threshold
+	ContractAlertRevertRate = "revert_rate"
+)
+
+// MaxContractAlertsPerContract limits the number of alerts a user can define for a single contract
+const MaxContractAlertsPerContract = 20
+
+// ContractAlert is an alert of a verified contract owner on the interactions with the contract
+type ContractAlert struct {
+	ID              uint64    `db:"id"`
+	UserID          uint64    `db:"user_id"`
+	Contract        []byte    `db:"contract_address"`
+	Kind            string    `db:"kind"`
+	MethodID        []byte    `db:"method_id"`
+	RevertThreshold uint64    `db:"revert_threshold"`
+	CreatedTs       time.Time `db:"created_ts"`
+}
+
+// ApiContractAlert is the api representation of a ContractAlert, Method is only set for method_called and RevertThreshold only for revert_rate alerts
+type ApiContractAlert struct {
+	ID              uint64    `json:"id"`
+	Contract        string    `json:"contract"`
+	Kind            string    `json:"kind"`
+	Method          string    `json:"method,omitempty"`
+	RevertThreshold uint64    `json:"revert_threshold,omitempty"`
+	CreatedTs       time.Time `json:"created_ts"`
+}
+
+// ApiContractOwnershipChallenge is the message the deployer of a contract has to sign to verify the ownership of the contract
+type ApiContractOwnershipChallenge struct {
+	Contract string `json:"contract"`
+	Deployer string `json:"deployer"`
+	Message  string `json:"message"`
+}
+
+// ApiContractOwnershipProof is the signature of the ownership challenge by the deployer of the contract
+type ApiContractOwnershipProof struct {
+	Signature string `json:"signature"`
+}
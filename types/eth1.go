@@ -1,9 +1,11 @@
 package types
 
 import (
+	"math/big"
 	"time"
 
 	gcp_bigtable "cloud.google.com/go/bigtable"
+	"github.com/shopspring/decimal"
 )
 
 type GetBlockTimings struct {
@@ -16,3 +18,414 @@ type BulkMutations struct {
 	Keys []string
 	Muts []*gcp_bigtable.Mutation
 }
+
+// AddressContractInteraction aggregates all calls of an address to a single contract, Fees is denominated in wei
+type AddressContractInteraction struct {
+	Contract     []byte
+	Name         string
+	Transactions uint64
+	GasUsed      uint64
+	Fees         []byte
+}
+
+// Eth1FinalityCheckpoints maps the justified (safe) and finalized consensus layer checkpoints to execution blocks
+type Eth1FinalityCheckpoints struct {
+	SafeEpoch            uint64 `json:"safe_epoch"`
+	SafeBlockNumber      uint64 `json:"safe_block_number"`
+	SafeBlockHash        []byte `json:"safe_block_hash"`
+	FinalizedEpoch       uint64 `json:"finalized_epoch"`
+	FinalizedBlockNumber uint64 `json:"finalized_block_number"`
+	FinalizedBlockHash   []byte `json:"finalized_block_hash"`
+}
+
+type Eth1BlockFinality string
+
+const (
+	Eth1BlockUnsafe    Eth1BlockFinality = ""
+	Eth1BlockSafe      Eth1BlockFinality = "safe"
+	Eth1BlockFinalized Eth1BlockFinality = "finalized"
+)
+
+// Finality returns whether an execution block is finalized, safe or neither according to the checkpoints
+func (c *Eth1FinalityCheckpoints) Finality(number uint64) Eth1BlockFinality {
+	switch {
+	case c.FinalizedBlockNumber > 0 && number <= c.FinalizedBlockNumber:
+		return Eth1BlockFinalized
+	case c.SafeBlockNumber > 0 && number <= c.SafeBlockNumber:
+		return Eth1BlockSafe
+	}
+	return Eth1BlockUnsafe
+}
+
+// chart_series indicators holding the daily number of transactions per transaction type
+const (
+	TxTypeLegacyIndicator     = "TX_COUNT_LEGACY"
+	TxTypeAccessListIndicator = "TX_COUNT_ACCESS_LIST"
+	TxTypeEIP1559Indicator    = "TX_COUNT_EIP1559"
+	TxTypeBlobIndicator       = "TX_COUNT_BLOB"
+)
+
+var TxTypeIndicators = []string{TxTypeLegacyIndicator, TxTypeAccessListIndicator, TxTypeEIP1559Indicator, TxTypeBlobIndicator}
+
+// TxTypeDayCounts holds the number of transactions of a day per transaction type (legacy, EIP-2930, EIP-1559 and EIP-4844)
+type TxTypeDayCounts struct {
+	Day        time.Time `json:"day"`
+	Legacy     uint64    `json:"legacy"`
+	AccessList uint64    `json:"access_list"`
+	EIP1559    uint64    `json:"eip1559"`
+	Blob       uint64    `json:"blob"`
+}
+
+// chart_series indicators holding the daily number of minted NFTs per token standard
+const (
+	NFTMintsERC721Indicator  = "NFT_MINTS_ERC721"
+	NFTMintsERC1155Indicator = "NFT_MINTS_ERC1155"
+)
+
+var NFTMintIndicators = []string{NFTMintsERC721Indicator, NFTMintsERC1155Indicator}
+
+// NFTTransfer is a transfer of an ERC721 or ERC1155 token, mints are transfers from the zero address
+type NFTTransfer struct {
+	Standard     string
+	TxHash       []byte
+	BlockNumber  uint64
+	Time         time.Time
+	LogIndex     int
+	TokenAddress []byte
+	From         []byte
+	To           []byte
+	Operator     []byte
+	TokenId      []byte
+	Value        []byte
+}
+
+// NFTCollectionMints holds the number of tokens a collection minted on a day and the number of distinct recipients of the mints
+type NFTCollectionMints struct {
+	Day          time.Time `db:"day" json:"day"`
+	TokenAddress []byte    `db:"token_address" json:"token_address"`
+	Standard     string    `db:"standard" json:"standard"`
+	Mints        uint64    `db:"mints" json:"mints"`
+	Minters      uint64    `db:"minters" json:"minters"`
+}
+
+// NFTHolding is the current balance of an address for a single token id of an ERC721 or ERC1155 collection
+type NFTHolding struct {
+	TokenAddress []byte
+	TokenId      []byte
+	Standard     string
+	Balance      []byte
+}
+
+// NFTMetadata is the metadata of an ERC721 or ERC1155 token as returned by the uri of the token, Error is set if the uri or its
+// metadata could not be resolved
+type NFTMetadata struct {
+	Standard     string          `json:"standard"`
+	TokenURI     string          `json:"token_uri"`
+	Name         string          `json:"name,omitempty"`
+	Description  string          `json:"description,omitempty"`
+	Image        string          `json:"image,omitempty"`
+	AnimationURL string          `json:"animation_url,omitempty"`
+	ExternalURL  string          `json:"external_url,omitempty"`
+	Attributes   []*NFTAttribute `json:"attributes,omitempty"`
+	Error        string          `json:"error,omitempty"`
+}
+
+type NFTAttribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"`
+}
+
+// NFTCollectionBalance is the number of token ids of an ERC721 or ERC1155 collection an address holds, Balance sums up the
+// amounts of all of them
+type NFTCollectionBalance struct {
+	TokenAddress []byte
+	Standard     string
+	Tokens       uint64
+	Balance      *big.Int
+}
+
+// AddressTokenBalances are the ether and ERC20 balances of an address written by the metadata updates worker together with the
+// NFT collections it holds. NFTsComplete is false if the address holds more NFTs than could be counted.
+type AddressTokenBalances struct {
+	Ether        *Eth1AddressBalance
+	ERC20        []*Eth1AddressBalance
+	NFTs         []*NFTCollectionBalance
+	NFTsComplete bool
+}
+
+// Eth1TxByHash is an indexed transaction merged with the logs it emitted and the internal transactions of its trace
+type Eth1TxByHash struct {
+	Transaction          *Eth1TransactionIndexed
+	Logs                 []*Eth1Log
+	InternalTransactions []*Eth1InternalTransactionIndexed
+}
+
+// Eth1BalanceSnapshot is the ether balance of an address observed in the snapshot interval starting at Block
+type Eth1BalanceSnapshot struct {
+	Block   uint64 `json:"block"`
+	Balance []byte `json:"-"`
+}
+
+// TokenHolder is the current balance of an address for an ERC20 token
+type TokenHolder struct {
+	Address []byte
+	Balance []byte
+}
+
+// kinds of contract deployments, the internal transaction types of factory deployments are used as they are
+const (
+	ContractCreationTx      = "tx"
+	ContractCreationCreate  = "create"
+	ContractCreationCreate2 = "create2"
+)
+
+// chart_series indicators holding the daily number of deployed contracts per kind of deployment and the number of distinct factories
+const (
+	ContractsDeployedTxIndicator      = "CONTRACTS_DEPLOYED_TX"
+	ContractsDeployedCreateIndicator  = "CONTRACTS_DEPLOYED_CREATE"
+	ContractsDeployedCreate2Indicator = "CONTRACTS_DEPLOYED_CREATE2"
+	ContractFactoriesIndicator        = "CONTRACT_FACTORIES"
+)
+
+var ContractDeploymentIndicators = []string{ContractsDeployedTxIndicator, ContractsDeployedCreateIndicator, ContractsDeployedCreate2Indicator, ContractFactoriesIndicator}
+
+// ContractDeploymentDayCounts holds the number of contracts deployed on a day by transactions and by factories via CREATE and CREATE2,
+// Factories is the number of distinct contracts that deployed at least one contract
+type ContractDeploymentDayCounts struct {
+	Day       time.Time `json:"day"`
+	Direct    uint64    `json:"direct"`
+	Create    uint64    `json:"create"`
+	Create2   uint64    `json:"create2"`
+	Factories uint64    `json:"factories"`
+}
+
+// AddressActivity holds the pre-aggregated first pages of the activity tables of a frequently visited address
+type AddressActivity struct {
+	Transactions         *DataTableResponse
+	InternalTransactions *DataTableResponse
+	Erc20Transfers       *DataTableResponse
+	UpdatedAt            time.Time
+}
+
+// AddressSummary aggregates the transactions and value carrying internal transactions of an address, failed transactions are
+// counted but do not add to the transferred value
+type AddressSummary struct {
+	Address         []byte    `json:"address"`
+	TxCount         uint64    `json:"tx_count"`
+	SentTxCount     uint64    `json:"sent_tx_count"`
+	ReceivedTxCount uint64    `json:"received_tx_count"`
+	InternalTxCount uint64    `json:"internal_tx_count"`
+	FirstSeen       time.Time `json:"first_seen"`
+	FirstSeenBlock  uint64    `json:"first_seen_block"`
+	LastSeen        time.Time `json:"last_seen"`
+	LastSeenBlock   uint64    `json:"last_seen_block"`
+	ValueSent       []byte    `json:"value_sent"`
+	ValueReceived   []byte    `json:"value_received"`
+	IsContract      bool      `json:"is_contract"`
+}
+
+// AddressTransferExport is a transaction or token transfer of an address as exported to csv. The value of transactions is in
+// ether, the one of ERC20 transfers in token units and the one of ERC1155 transfers the number of transferred tokens.
+type AddressTransferExport struct {
+	Type        string
+	Hash        []byte
+	BlockNumber uint64
+	Time        time.Time
+	From        []byte
+	To          []byte
+	Token       []byte
+	TokenSymbol string
+	TokenId     []byte
+	Value       string
+}
+
+// IndexerProgress is the progress of an eth1 indexing pipeline as periodically reported by the indexer
+type IndexerProgress struct {
+	Name             string    `db:"name" json:"name"`
+	StartBlock       int64     `db:"start_block" json:"start_block"`
+	EndBlock         int64     `db:"end_block" json:"end_block"`
+	CurrentBlock     int64     `db:"current_block" json:"current_block"`
+	ProcessedBlocks  int64     `db:"processed_blocks" json:"processed_blocks"`
+	BlocksPerSecond  float64   `db:"blocks_per_second" json:"blocks_per_second"`
+	PendingMutations int64     `db:"pending_mutations" json:"pending_mutations"`
+	StartedTime      time.Time `db:"started_time" json:"started_time"`
+	UpdatedTime      time.Time `db:"updated_time" json:"updated_time"`
+}
+
+// Completion returns the share of processed blocks of the indexed range
+func (p *IndexerProgress) Completion() float64 {
+	total := p.EndBlock - p.StartBlock + 1
+	if total <= 0 {
+		return 1
+	}
+	return float64(p.ProcessedBlocks) / float64(total)
+}
+
+// EstimatedCompletionTime extrapolates the current throughput, the zero time is returned if nothing is being processed
+func (p *IndexerProgress) EstimatedCompletionTime() time.Time {
+	remaining := p.EndBlock - p.StartBlock + 1 - p.ProcessedBlocks
+	if remaining <= 0 {
+		return p.UpdatedTime
+	}
+	if p.BlocksPerSecond <= 0 {
+		return time.Time{}
+	}
+	return p.UpdatedTime.Add(time.Duration(float64(remaining) / p.BlocksPerSecond * float64(time.Second)))
+}
+
+// IndexerStatus extends the reported progress of an indexing pipeline with the derived completion estimates
+type IndexerStatus struct {
+	*IndexerProgress
+	Completion              float64    `json:"completion"`
+	EstimatedCompletionTime *time.Time `json:"estimated_completion_time"`
+}
+
+// UserOperation is an ERC-4337 user operation as reported by the UserOperationEvent of the entry point,
+// the bundler is the sender of the transaction that submitted the operation
+type UserOperation struct {
+	Hash          []byte
+	EntryPoint    []byte
+	Sender        []byte
+	Paymaster     []byte
+	Bundler       []byte
+	Success       bool
+	ActualGasCost *big.Int
+	ActualGasUsed *big.Int
+}
+
+// roles of the account abstraction operators whose daily activity is aggregated
+const (
+	AAOperatorBundler   = "bundler"
+	AAOperatorPaymaster = "paymaster"
+)
+
+// chart_series indicators holding the daily number of user operations whose gas was paid by a paymaster or by the account itself
+const (
+	AAUserOpsSponsoredIndicator = "AA_USER_OPS_SPONSORED"
+	AAUserOpsSelfPaidIndicator  = "AA_USER_OPS_SELF_PAID"
+)
+
+var AAUserOpsIndicators = []string{AAUserOpsSponsoredIndicator, AAUserOpsSelfPaidIndicator}
+
+// AAOperatorStats holds the user operations a bundler submitted or a paymaster sponsored, GasCost is the actual gas cost in wei
+type AAOperatorStats struct {
+	Day        time.Time       `db:"day" json:"day"`
+	Role       string          `db:"role" json:"role"`
+	Address    []byte          `db:"address" json:"address"`
+	Operations uint64          `db:"operations" json:"operations"`
+	Successful uint64          `db:"successful" json:"successful"`
+	GasUsed    decimal.Decimal `db:"gas_used" json:"gas_used"`
+	GasCost    decimal.Decimal `db:"gas_cost" json:"gas_cost"`
+}
+
+// GasPricePercentiles are the percentiles of the gas prices in wei paid by the transactions of an hour
+type GasPricePercentiles struct {
+	Hour         time.Time `db:"ts" json:"ts"`
+	Transactions uint64    `db:"tx_count" json:"tx_count"`
+	P10          uint64    `db:"p10" json:"p10"`
+	P50          uint64    `db:"p50" json:"p50"`
+	P90          uint64    `db:"p90" json:"p90"`
+}
+
+// BurnedFees is the amount of wei burned with EIP 1559 in total and per day, up to the given block
+type BurnedFees struct {
+	Total Amount           `json:"total"`
+	Block uint64           `json:"block"`
+	Days  []*BurnedFeesDay `json:"days"`
+}
+
+// BurnedFeesDay is the amount of wei burned by the blocks of a (UTC) day
+type BurnedFeesDay struct {
+	Day        time.Time `json:"day"`
+	BurnedFees Amount    `json:"burned_fees"`
+}
+
+type GasOracleTrend string
+
+const (
+	GasOracleTrendRising  GasOracleTrend = "rising"
+	GasOracleTrendFalling GasOracleTrend = "falling"
+	GasOracleTrendStable  GasOracleTrend = "stable"
+)
+
+// GasOracle holds the gas prices in wei estimated from the priority fees paid in the most recent blocks, each price is the
+// next base fee plus a percentile of the priority fees
+type GasOracle struct {
+	Block        uint64
+	Blocks       int
+	Slow         *big.Int
+	Standard     *big.Int
+	Fast         *big.Int
+	Rapid        *big.Int
+	BaseFee      *big.Int
+	NextBaseFee  *big.Int
+	BaseFeeTrend GasOracleTrend
+}
+
+// TxFeeBreakdown splits the fee of a transaction into the part burned by the base fee and the priority fee paid to the fee
+// recipient of the block. Transactions of blocks before London burn nothing and pay the whole fee as tip.
+type TxFeeBreakdown struct {
+	GasUsed           uint64 `json:"gas_used"`
+	EffectiveGasPrice Amount `json:"effective_gas_price"`
+	BaseFee           Amount `json:"base_fee"`
+	Burned            Amount `json:"burned"`
+	Tip               Amount `json:"tip"`
+	Total             Amount `json:"total"`
+}
+
+// NewTxFeeBreakdown returns the fee breakdown of a transaction paying the effective gas price in a block with the given base fee,
+// baseFee is nil for blocks before London
+func NewTxFeeBreakdown(gasUsed uint64, effectiveGasPrice, baseFee *big.Int) *TxFeeBreakdown {
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	gas := new(big.Int).SetUint64(gasUsed)
+	total := new(big.Int).Mul(effectiveGasPrice, gas)
+	burned := new(big.Int).Mul(baseFee, gas)
+	return &TxFeeBreakdown{
+		GasUsed:           gasUsed,
+		EffectiveGasPrice: NewEtherAmount(effectiveGasPrice),
+		BaseFee:           NewEtherAmount(baseFee),
+		Burned:            NewEtherAmount(burned),
+		Tip:               NewEtherAmount(new(big.Int).Sub(total, burned)),
+		Total:             NewEtherAmount(total),
+	}
+}
+
+// FeeBreakdown returns the fee breakdown of an indexed transaction, nil if the transaction has been indexed before the effective
+// gas price was stored
+func (x *Eth1TransactionIndexed) FeeBreakdown() *TxFeeBreakdown {
+	if len(x.GetEffectiveGasPrice()) == 0 {
+		return nil
+	}
+	var baseFee *big.Int
+	if len(x.GetBlockBaseFee()) > 0 {
+		baseFee = new(big.Int).SetBytes(x.GetBlockBaseFee())
+	}
+	return NewTxFeeBreakdown(x.GetGasUsed(), new(big.Int).SetBytes(x.GetEffectiveGasPrice()), baseFee)
+}
+
+// ItxTreeNode is a call frame of the trace of a transaction together with the calls it made
+type ItxTreeNode struct {
+	Type     string         `json:"type"`
+	From     []byte         `json:"from"`
+	To       []byte         `json:"to"`
+	Value    Amount         `json:"value"`
+	Path     string         `json:"path"`
+	Index    uint64         `json:"index"`
+	Depth    uint32         `json:"depth"`
+	Children []*ItxTreeNode `json:"children,omitempty"`
+}
+
+// AddressRisk holds the risk indicators that apply to an address, the score is the number of indicators
+type AddressRisk struct {
+	Score      int                     `json:"score"`
+	Level      string                  `json:"level"`
+	Indicators []*AddressRiskIndicator `json:"indicators"`
+}
+
+type AddressRiskIndicator struct {
+	Id          string `json:"id"`
+	Description string `json:"description"`
+}
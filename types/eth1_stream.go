@@ -0,0 +1,74 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Eth1StreamBlock is published by the eth1 indexer for each newly indexed block, it holds the transactions and token transfers
+// of the block that websocket clients can subscribe to
+type Eth1StreamBlock struct {
+	Number   uint64                `json:"number"`
+	Hash     string                `json:"hash"`
+	Time     time.Time             `json:"time"`
+	Miner    string                `json:"miner"`
+	TxCount  int                   `json:"tx_count"`
+	GasUsed  uint64                `json:"gas_used"`
+	BaseFee  string                `json:"base_fee"`
+	Activity []*Eth1StreamActivity `json:"activity,omitempty"`
+}
+
+// Eth1StreamActivity is a transaction or token transfer of a newly indexed block. Token transfers carry the method of the
+// transaction that emitted them.
+type Eth1StreamActivity struct {
+	Type        string `json:"type"` // transaction, erc20, erc721 or erc1155
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Method      string `json:"method,omitempty"`
+	Token       string `json:"token,omitempty"`
+	TokenId     string `json:"token_id,omitempty"`
+	Value       string `json:"value"`
+	Failed      bool   `json:"failed,omitempty"`
+	Airdrop     bool   `json:"airdrop,omitempty"`
+}
+
+// Eth1StreamFilter selects the activity a websocket client is subscribed to, all of the given fields have to match.
+// Addresses and methods are 0x prefixed hex strings.
+type Eth1StreamFilter struct {
+	Address string `json:"address,omitempty"` // sender or recipient
+	Token   string `json:"token,omitempty"`
+	Method  string `json:"method,omitempty"`
+}
+
+// Matches returns true if the activity passes the filter
+func (f *Eth1StreamFilter) Matches(activity *Eth1StreamActivity) bool {
+	if f.Address != "" && !strings.EqualFold(activity.From, f.Address) && !strings.EqualFold(activity.To, f.Address) {
+		return false
+	}
+	if f.Token != "" && !strings.EqualFold(activity.Token, f.Token) {
+		return false
+	}
+	if f.Method != "" && !strings.EqualFold(activity.Method, f.Method) {
+		return false
+	}
+	return true
+}
+
+// Eth1StreamRequest is sent by websocket clients to subscribe to the blocks or to the activity selected by a filter and to
+// cancel a subscription, the id is chosen by the client and is sent along with the messages of the subscription
+type Eth1StreamRequest struct {
+	Op     string           `json:"op"` // subscribe or unsubscribe
+	Id     string           `json:"id"`
+	Topic  string           `json:"topic,omitempty"` // blocks or activity
+	Filter Eth1StreamFilter `json:"filter"`
+}
+
+// Eth1StreamMessage is sent to websocket clients, either in reply to a request or carrying a block or activity of a subscription
+type Eth1StreamMessage struct {
+	Op    string      `json:"op"` // subscribed, unsubscribed, error, block or activity
+	Id    string      `json:"id,omitempty"`
+	Error string      `json:"error,omitempty"`
+	Data  interface{} `json:"data,omitempty"`
+}
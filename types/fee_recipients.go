@@ -0,0 +1,28 @@
+package types
+
+// MaxUserFeeRecipients limits the number of expected fee recipients a user can configure
+const MaxUserFeeRecipients = 20
+
+// ProposalFeeRecipient is the fee recipient a canonical block has paid. For blocks built through a relay the execution payload
+// pays the builder, the proposer is paid the fee recipient reported by the relay instead.
+type ProposalFeeRecipient struct {
+	ValidatorIndex    uint64 `db:"proposer"`
+	Pubkey            []byte `db:"pubkey"`
+	Slot              uint64 `db:"slot"`
+	ExecBlockNumber   uint64 `db:"exec_block_number"`
+	ExecFeeRecipient  []byte `db:"exec_fee_recipient"`
+	RelayFeeRecipient []byte `db:"proposer_fee_recipient"`
+}
+
+// FeeRecipient returns the address the proposer of the block has been paid
+func (p *ProposalFeeRecipient) FeeRecipient() []byte {
+	if len(p.RelayFeeRecipient) > 0 {
+		return p.RelayFeeRecipient
+	}
+	return p.ExecFeeRecipient
+}
+
+// ApiUserFeeRecipients are the fee recipients a user expects the proposals of the validators on the watchlist to pay
+type ApiUserFeeRecipients struct {
+	FeeRecipients []string `json:"fee_recipients"`
+}
@@ -49,6 +49,12 @@ const (
 	RocketpoolCollateralMinReached                   EventName = "rocketpool_colleteral_min"
 	RocketpoolCollateralMaxReached                   EventName = "rocketpool_colleteral_max"
 	SyncCommitteeSoon                                EventName = "validator_synccommittee_soon"
+	WhaleTransferEventName                           EventName = "whale_transfer"
+	NetworkDeepReorgEventName                        EventName = "network_deep_reorg"
+	ValidatorCredentialsChangedEventName             EventName = "validator_credentials_changed"
+	AlertRuleTriggeredEventName                      EventName = "alert_rule_triggered"
+	ContractInteractionEventName                     EventName = "contract_interaction"
+	ValidatorFeeRecipientMismatchEventName           EventName = "validator_fee_recipient_mismatch"
 )
 
 var UserIndexEvents = []EventName{
@@ -92,6 +98,12 @@ var EventLabel map[EventName]string = map[EventName]string{
 	RocketpoolCollateralMinReached:                   "You reached the rocketpool min collateral",
 	RocketpoolCollateralMaxReached:                   "You reached the rocketpool max collateral",
 	SyncCommitteeSoon:                                "Your validator(s) will soon be part of the sync committee",
+	WhaleTransferEventName:                           "A large transfer has been registered by the network",
+	NetworkDeepReorgEventName:                        "A deep reorg has been detected by the network",
+	ValidatorCredentialsChangedEventName:             "The withdrawal credentials of your validator(s) changed",
+	AlertRuleTriggeredEventName:                      "One of your alert rules has been triggered",
+	ContractInteractionEventName:                     "One of your contract alerts has been triggered",
+	ValidatorFeeRecipientMismatchEventName:           "Your validator(s) proposed a block paying an unexpected fee recipient",
 }
 
 func IsUserIndexed(event EventName) bool {
@@ -132,6 +144,12 @@ var EventNames = []EventName{
 	RocketpoolCollateralMinReached,
 	RocketpoolCollateralMaxReached,
 	SyncCommitteeSoon,
+	WhaleTransferEventName,
+	NetworkDeepReorgEventName,
+	ValidatorCredentialsChangedEventName,
+	AlertRuleTriggeredEventName,
+	ContractInteractionEventName,
+	ValidatorFeeRecipientMismatchEventName,
 }
 
 type EventNameDesc struct {
@@ -178,6 +196,14 @@ var AddWatchlistEvents = []EventNameDesc{
 		Event:   ValidatorMissedAttestationEventName,
 		Warning: template.HTML(`<i data-toggle="tooltip" title="Will trigger every epoch (6.4 minutes) during downtime" class="fas fa-exclamation-circle text-warning"></i>`),
 	},
+	{
+		Desc:  "Withdrawal credentials changed",
+		Event: ValidatorCredentialsChangedEventName,
+	},
+	{
+		Desc:  "Fee recipient mismatch",
+		Event: ValidatorFeeRecipientMismatchEventName,
+	},
 	{
 		Desc:  "Withdrawal processed",
 		Event: ValidatorReceivedWithdrawalEventName,
@@ -304,6 +330,36 @@ type UserWithPremium struct {
 	Product sql.NullString `db:"product_id"`
 }
 
+// AddressLabel is a private label a user has assigned to an execution layer address
+type AddressLabel struct {
+	Address []byte `db:"address"`
+	Label   string `db:"label"`
+}
+
+// ApiAddressLabel is the import and export format of the private address labels of a user
+type ApiAddressLabel struct {
+	Address string `json:"address"`
+	Label   string `json:"label"`
+}
+
+// ApiBulkSubscription is a notification subscription of a bulk request, the filter is the public key of a validator for validator
+// events and the node address for rocket pool collateral events
+type ApiBulkSubscription struct {
+	EventName      string  `json:"event_name"`
+	EventFilter    string  `json:"event_filter"`
+	EventThreshold float64 `json:"event_threshold,omitempty"`
+}
+
+type ApiBulkSubscriptionsRequest struct {
+	Subscriptions []ApiBulkSubscription `json:"subscriptions"`
+}
+
+type ApiBulkSubscriptionsResponse struct {
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+	Deleted int64 `json:"deleted"`
+}
+
 type TransitEmail struct {
 	Id      uint64       `db:"id,omitempty"`
 	Created sql.NullTime `db:"created"`
@@ -514,6 +570,11 @@ type GasNowPageData struct {
 		Price     float64  `json:"price,omitempty"`
 		PriceUSD  float64  `json:"priceUSD"`
 		Currency  string   `json:"currency,omitempty"`
+		// only set by the gas oracle, which estimates the prices from the recently indexed blocks
+		Block        uint64         `json:"block,omitempty"`
+		BaseFee      *big.Int       `json:"baseFee,omitempty"`
+		NextBaseFee  *big.Int       `json:"nextBaseFee,omitempty"`
+		BaseFeeTrend GasOracleTrend `json:"baseFeeTrend,omitempty"`
 	} `json:"data"`
 }
 
@@ -523,6 +584,34 @@ type Eth1AddressSearchItem struct {
 	Token   string `json:"token"`
 }
 
+type SearchSuggestionType string
+
+const (
+	SearchSuggestionBlock       SearchSuggestionType = "block"
+	SearchSuggestionTransaction SearchSuggestionType = "transaction"
+	SearchSuggestionAddress     SearchSuggestionType = "address"
+	SearchSuggestionToken       SearchSuggestionType = "token"
+	SearchSuggestionValidator   SearchSuggestionType = "validator"
+	SearchSuggestionEns         SearchSuggestionType = "ens"
+	SearchSuggestionGraffiti    SearchSuggestionType = "graffiti"
+)
+
+// SearchSuggestion is a single typed result of the keyboard search
+type SearchSuggestion struct {
+	Type  SearchSuggestionType   `json:"type"`
+	Icon  string                 `json:"icon"`
+	Label string                 `json:"label"`
+	Value string                 `json:"value"`
+	Path  string                 `json:"path"`
+	Meta  map[string]interface{} `json:"meta,omitempty"`
+}
+
+type SearchSuggestionsResponse struct {
+	Suggestions []*SearchSuggestion `json:"suggestions"`
+	// Incomplete is set if at least one lookup did not finish within the latency budget
+	Incomplete bool `json:"incomplete"`
+}
+
 type RawMempoolResponse struct {
 	Pending map[string]map[int]*RawMempoolTransaction `json:"pending"`
 	Queued  map[string]map[int]*RawMempoolTransaction `json:"queued"`
@@ -581,3 +670,149 @@ type Signature struct {
 	Hex       string `json:"hex_signature"`
 	Bytes     string `json:"bytes_signature"`
 }
+
+type DashboardWidgetType string
+
+const (
+	DashboardWidgetValidators DashboardWidgetType = "validators"
+	DashboardWidgetAddresses  DashboardWidgetType = "addresses"
+	DashboardWidgetGasOracle  DashboardWidgetType = "gas_oracle"
+	DashboardWidgetChart      DashboardWidgetType = "chart"
+	DashboardWidgetBenchmark  DashboardWidgetType = "benchmark"
+)
+
+// UserDashboardWidget is a single widget of a custom dashboard, the position and size are given in grid cells
+type UserDashboardWidget struct {
+	Type       DashboardWidgetType `json:"type"`
+	Title      string              `json:"title"`
+	Validators []uint64            `json:"validators,omitempty"`
+	Addresses  []string            `json:"addresses,omitempty"`
+	Chart      string              `json:"chart,omitempty"`
+	X          int                 `json:"x"`
+	Y          int                 `json:"y"`
+	Width      int                 `json:"width"`
+	Height     int                 `json:"height"`
+}
+
+type UserDashboardLayout []*UserDashboardWidget
+
+func (l *UserDashboardLayout) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &l)
+}
+
+func (l UserDashboardLayout) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+type UserDashboard struct {
+	ID        uint64              `db:"id" json:"id"`
+	Name      string              `db:"name" json:"name"`
+	Layout    UserDashboardLayout `db:"layout" json:"layout"`
+	UpdatedAt time.Time           `db:"updated_at" json:"updated_at"`
+}
+
+// UserDashboardWidgetData holds the hydrated data of a dashboard widget, Error is set if the data could not be retrieved
+type UserDashboardWidgetData struct {
+	Type  DashboardWidgetType `json:"type"`
+	Title string              `json:"title"`
+	Data  interface{}         `json:"data,omitempty"`
+	Error string              `json:"error,omitempty"`
+}
+
+type UserDashboardValidatorsSummary struct {
+	Validators       uint64 `db:"validators" json:"validators"`
+	Active           uint64 `db:"active" json:"active"`
+	Pending          uint64 `db:"pending" json:"pending"`
+	Exited           uint64 `db:"exited" json:"exited"`
+	Slashed          uint64 `db:"slashed" json:"slashed"`
+	Balance          uint64 `db:"balance" json:"balance"`
+	EffectiveBalance uint64 `db:"effective_balance" json:"effective_balance"`
+}
+
+type UserDashboardAddressActivity struct {
+	Address      string                            `json:"address"`
+	Name         string                            `json:"name,omitempty"`
+	Balance      string                            `json:"balance"`
+	Transactions []*UserDashboardAddressActivityTx `json:"transactions"`
+}
+
+type UserDashboardAddressActivityTx struct {
+	Hash        string    `json:"hash"`
+	BlockNumber uint64    `json:"block_number"`
+	Time        time.Time `json:"time"`
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Value       string    `json:"value"`
+	Method      string    `json:"method"`
+}
+
+type SavedFilterTable string
+
+const (
+	SavedFilterAddressTransactions SavedFilterTable = "address_transactions"
+	SavedFilterValidators          SavedFilterTable = "validators"
+)
+
+// SavedFilterParams are the query parameters a saved filter adds to the data requests of its table
+type SavedFilterParams map[string]string
+
+func (p *SavedFilterParams) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &p)
+}
+
+func (p SavedFilterParams) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// UserSavedFilter is a named filter preset of a table, saving a filter with the name of an existing one of the same table replaces it
+type UserSavedFilter struct {
+	ID        uint64            `db:"id" json:"id"`
+	Table     SavedFilterTable  `db:"table_name" json:"table"`
+	Name      string            `db:"name" json:"name"`
+	Params    SavedFilterParams `db:"params" json:"params"`
+	UpdatedAt time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// SupportUserData is the read-only view on the account of a user that support staff can look up
+type SupportUserData struct {
+	UserID        uint64                      `json:"user_id"`
+	Email         string                      `json:"email"`
+	Subscriptions []*SupportUserSubscription  `json:"subscriptions,omitempty"`
+	ApiUsage      *SupportUserApiUsage        `json:"api_usage,omitempty"`
+	Watchlist     []*SupportUserWatchlistItem `json:"watchlist,omitempty"`
+}
+
+type SupportUserSubscription struct {
+	EventName      string     `json:"event_name"`
+	EventFilter    string     `json:"event_filter"`
+	EventThreshold float64    `json:"event_threshold"`
+	CreatedTime    time.Time  `json:"created_ts"`
+	LastSent       *time.Time `json:"last_sent_ts,omitempty"`
+}
+
+// SupportUserApiUsage omits the api key itself, support staff only needs to know whether one exists
+type SupportUserApiUsage struct {
+	HasApiKey   bool    `json:"has_api_key"`
+	PriceID     *string `json:"price_id,omitempty"`
+	Active      *bool   `json:"active,omitempty"`
+	Daily       int     `json:"daily"`
+	Monthly     int     `json:"monthly"`
+	MaxDaily    int     `json:"max_daily"`
+	MaxMonthly  int     `json:"max_monthly"`
+	WebhookURLs int     `json:"webhooks"`
+}
+
+type SupportUserWatchlistItem struct {
+	Pubkey         string  `json:"pubkey"`
+	ValidatorIndex *uint64 `json:"validator_index,omitempty"`
+}
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TransferPathJobStatus string
+
+const PendingTransferPathJobStatus TransferPathJobStatus = "PENDING"     // job is waiting to be processed
+const RunningTransferPathJobStatus TransferPathJobStatus = "RUNNING"     // job has been picked up by a processor
+const CompletedTransferPathJobStatus TransferPathJobStatus = "COMPLETED" // search has finished, result is available
+const FailedTransferPathJobStatus TransferPathJobStatus = "FAILED"       // search has been aborted, see error
+
+// TransferPathSearch describes a search for transfer paths from Source to Destination within a block range
+type TransferPathSearch struct {
+	Source      []byte `json:"source"`
+	Destination []byte `json:"destination"`
+	FromBlock   uint64 `json:"from_block"`
+	ToBlock     uint64 `json:"to_block"`
+	MaxDepth    int    `json:"max_depth"`
+}
+
+// TransferPathHop is a single transfer of a path, Value is denominated in wei for ether and in the smallest unit of the token for ERC20 transfers
+type TransferPathHop struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	Token       string `json:"token,omitempty"`
+	Value       string `json:"value"`
+}
+
+type TransferPathResult struct {
+	Paths            [][]*TransferPathHop `json:"paths"`
+	VisitedAddresses int                  `json:"visited_addresses"`
+	Truncated        bool                 `json:"truncated"`
+}
+
+type TransferPathJob struct {
+	ID            string                `db:"id"`
+	Status        TransferPathJobStatus `db:"status"`
+	CreatedTime   time.Time             `db:"created_time"`
+	CompletedTime sql.NullTime          `db:"completed_time"`
+	Source        []byte                `db:"source"`
+	Destination   []byte                `db:"destination"`
+	FromBlock     uint64                `db:"from_block"`
+	ToBlock       uint64                `db:"to_block"`
+	MaxDepth      int                   `db:"max_depth"`
+	RawResult     []byte                `db:"result"`
+	Error         sql.NullString        `db:"error"`
+}
+
+func (j *TransferPathJob) Search() *TransferPathSearch {
+	return &TransferPathSearch{
+		Source:      j.Source,
+		Destination: j.Destination,
+		FromBlock:   j.FromBlock,
+		ToBlock:     j.ToBlock,
+		MaxDepth:    j.MaxDepth,
+	}
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// NodeCrawlReport is submitted by the p2p network crawler after each crawl
+type NodeCrawlReport struct {
+	CrawledAt time.Time        `json:"crawled_at"`
+	Nodes     []*NodeCrawlPeer `json:"nodes"`
+}
+
+// NodeCrawlPeer is a single node discovered by the crawler, Country is an ISO 3166-1 alpha-2 code
+type NodeCrawlPeer struct {
+	Client  string `json:"client"`
+	Version string `json:"version"`
+	Country string `json:"country"`
+	Peers   uint64 `json:"peers"`
+}
+
+// NodeCounts maps a client, client version or country to the number of nodes
+type NodeCounts map[string]uint64
+
+func (c *NodeCounts) Scan(value interface{}) error {
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, &c)
+}
+
+func (c NodeCounts) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// NodeCountEntry is a single entry of NodeCounts
+type NodeCountEntry struct {
+	Name  string  `json:"name"`
+	Count uint64  `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// Sorted returns the entries ordered by count, the remaining entries after limit are combined as "other"
+func (c NodeCounts) Sorted(limit int) []*NodeCountEntry {
+	total := uint64(0)
+	entries := make([]*NodeCountEntry, 0, len(c))
+	for name, count := range c {
+		total += count
+		entries = append(entries, &NodeCountEntry{Name: name, Count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count == entries[j].Count {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Count > entries[j].Count
+	})
+
+	if limit > 0 && len(entries) > limit {
+		other := &NodeCountEntry{Name: "other"}
+		for _, entry := range entries[limit:] {
+			other.Count += entry.Count
+		}
+		entries = append(entries[:limit], other)
+	}
+	for _, entry := range entries {
+		if total > 0 {
+			entry.Share = float64(entry.Count) / float64(total)
+		}
+	}
+	return entries
+}
+
+// NetworkNodeSnapshot aggregates the latest crawl of a day
+type NetworkNodeSnapshot struct {
+	Day             time.Time  `db:"day" json:"day"`
+	CrawledTime     time.Time  `db:"crawled_time" json:"crawled_time"`
+	NodeCount       uint64     `db:"node_count" json:"node_count"`
+	AvgPeerCount    float64    `db:"avg_peer_count" json:"avg_peer_count"`
+	MedianPeerCount float64    `db:"median_peer_count" json:"median_peer_count"`
+	Clients         NodeCounts `db:"clients" json:"clients"`
+	Versions        NodeCounts `db:"versions" json:"versions"`
+	Countries       NodeCounts `db:"countries" json:"countries"`
+}
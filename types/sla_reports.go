@@ -0,0 +1,87 @@
+package types
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type SlaReportJobStatus string
+
+const PendingSlaReportJobStatus SlaReportJobStatus = "PENDING"     // job is waiting to be processed
+const RunningSlaReportJobStatus SlaReportJobStatus = "RUNNING"     // job has been picked up by a processor
+const CompletedSlaReportJobStatus SlaReportJobStatus = "COMPLETED" // report has been generated and can be downloaded
+const FailedSlaReportJobStatus SlaReportJobStatus = "FAILED"       // report generation has been aborted, see error
+
+type SlaReportFormat string
+
+const SlaReportFormatPdf SlaReportFormat = "pdf"
+const SlaReportFormatCsv SlaReportFormat = "csv"
+
+// SlaReportJob is a queued request to generate the monthly uptime report of a validator set, Month is formatted as YYYY-MM
+type SlaReportJob struct {
+	ID            string             `db:"id"`
+	Status        SlaReportJobStatus `db:"status"`
+	CreatedTime   time.Time          `db:"created_time"`
+	CompletedTime sql.NullTime       `db:"completed_time"`
+	Validators    pq.Int64Array      `db:"validators"`
+	Month         string             `db:"month"`
+	Format        SlaReportFormat    `db:"format"`
+	Result        []byte             `db:"result"`
+	Error         sql.NullString     `db:"error"`
+}
+
+// SlaReportValidator holds the duties and performance of a single validator during the report period
+type SlaReportValidator struct {
+	Index              uint64 `db:"validatorindex"`
+	Pubkey             []byte `db:"pubkey"`
+	ActivationEpoch    uint64 `db:"activationepoch"`
+	ExitEpoch          uint64 `db:"exitepoch"`
+	MissedAttestations uint64 `db:"missed_attestations"`
+	ProposedBlocks     uint64 `db:"proposed_blocks"`
+	MissedBlocks       uint64 `db:"missed_blocks"`
+	OrphanedBlocks     uint64 `db:"orphaned_blocks"`
+	ParticipatedSync   uint64 `db:"participated_sync"`
+	MissedSync         uint64 `db:"missed_sync"`
+	// number of epochs of the report period in which the validator had to attest
+	AttestationDuties uint64 `db:"-"`
+	// slashings of the validator that were included during the report period
+	Slashings []*SlaReportSlashing `db:"-"`
+}
+
+// AttestationParticipation returns the share of attestation duties that have been fulfilled, validators without duties count as fully participating
+func (v *SlaReportValidator) AttestationParticipation() float64 {
+	if v.AttestationDuties == 0 {
+		return 1
+	}
+	if v.MissedAttestations >= v.AttestationDuties {
+		return 0
+	}
+	return float64(v.AttestationDuties-v.MissedAttestations) / float64(v.AttestationDuties)
+}
+
+type SlaReportSlashing struct {
+	ValidatorIndex uint64 `db:"validatorindex"`
+	Slot           uint64 `db:"slot"`
+	Reason         string `db:"reason"`
+}
+
+// SlaReport is the monthly uptime and performance report of a validator set, the period covers the days [StartDay, EndDay]
+type SlaReport struct {
+	Month      string
+	StartDay   uint64
+	EndDay     uint64
+	StartEpoch uint64
+	EndEpoch   uint64
+	Validators []*SlaReportValidator
+}
+
+type APISlaReportResponse struct {
+	JobID      string  `json:"job_id"`
+	Status     string  `json:"status"`
+	Month      string  `json:"month"`
+	Format     string  `json:"format"`
+	Validators []int64 `json:"validators"`
+	Error      string  `json:"error,omitempty"`
+}
@@ -42,16 +42,39 @@ type PageData struct {
 	InfoBanner            *template.HTML
 	ClientsUpdated        bool
 	// IsUserClientUpdated   func(uint64) bool
-	ChainConfig         ChainConfig
-	Lang                string
-	NoAds               bool
-	Debug               bool
-	DebugTemplates      []string
-	DebugSession        map[string]interface{}
-	GasNow              *GasNowPageData
-	GlobalNotification  template.HTML
-	AvailableCurrencies []string
-	MainMenuItems       []MainMenuItem
+	ChainConfig            ChainConfig
+	Lang                   string
+	NoAds                  bool
+	Debug                  bool
+	DebugTemplates         []string
+	DebugSession           map[string]interface{}
+	GasNow                 *GasNowPageData
+	GlobalNotification     template.HTML
+	AvailableCurrencies    []string
+	MainMenuItems          []MainMenuItem
+	Timezone               string
+	TimestampMode          string
+	AvailableTimezones     []string
+	NumberLocale           string
+	AvailableNumberLocales []NumberLocale
+	// the network the page is served for, nil for the configured chain
+	Network  *PageNetwork
+	Networks []PageNetwork
+}
+
+// PageNetwork is a network selectable in the network switcher
+type PageNetwork struct {
+	Name    string
+	Label   string
+	Sandbox bool
+}
+
+// NumberLocale defines the separators used to render numbers and amounts
+type NumberLocale struct {
+	Code               string
+	Name               string
+	ThousandsSeparator string
+	DecimalSeparator   string
 }
 
 type MainMenuItem struct {
@@ -203,6 +226,7 @@ type IndexPageData struct {
 	DepositDistribution       *ChartsPageDataChart
 	Countdown                 interface{}
 	SlotVizData               *SlotVizPageData `json:"slotVizData"`
+	TopMovements              *TopMovements    `json:"top_movements"`
 }
 
 type SlotVizPageData struct {
@@ -424,6 +448,7 @@ type ValidatorPageData struct {
 	ShowMultipleWithdrawalCredentialsWarning bool
 	CappellaHasHappened                      bool
 	BLSChange                                *BLSChange
+	CredentialRotations                      []*ValidatorCredentialRotation
 	IsWithdrawableAddress                    bool
 	EstimatedNextWithdrawal                  template.HTML
 	AddValidatorWatchlistModal               *AddValidatorWatchlistModal
@@ -824,6 +849,12 @@ type DataTableResponse struct {
 	PageLength      uint64          `json:"pageLength"`
 	DisplayStart    uint64          `json:"displayStart"`
 	PagingToken     string          `json:"pagingToken"`
+	// token of the page before the current one, only set by tables that can be paged backwards
+	PreviousPagingToken string `json:"previousPagingToken,omitempty"`
+	// notices about rows that could not be loaded, the data is incomplete if set
+	Warnings []string `json:"warnings,omitempty"`
+	// set if the query ran out of its latency budget, the page only contains the rows found until then
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // EpochsPageData is a struct to hold epoch data for the epochs page
@@ -1210,6 +1241,8 @@ type UserSettingsPageData struct {
 	Diamond             *string
 	ShareMonitoringData bool
 	ApiStatistics       *ApiStatistics
+	ApiWebhooks         []*ApiWebhook
+	ApiWebhookEvents    []ApiWebhookEvent
 }
 
 type PairedDevice struct {
@@ -1455,6 +1488,13 @@ type UserWebhookRowError struct {
 	ContentResponse template.HTML
 }
 
+type UserDashboardsPageData struct {
+	Dashboards []*UserDashboard
+	Selected   *UserDashboard
+	Charts     []string
+	CsrfField  template.HTML
+}
+
 type WebhookPageData struct {
 	WebhookRows  []UserWebhookRow
 	Webhooks     []UserWebhook
@@ -1554,22 +1594,86 @@ type DataTableSaveStateColumns struct {
 }
 
 type Eth1AddressPageData struct {
-	Address            string `json:"address"`
-	IsContract         bool
-	QRCode             string `json:"qr_code_base64"`
-	QRCodeInverse      string
-	Metadata           *Eth1AddressMetadata
-	WithdrawalsSummary template.HTML
-	BlocksMinedTable   *DataTableResponse
-	UnclesMinedTable   *DataTableResponse
-	TransactionsTable  *DataTableResponse
-	InternalTxnsTable  *DataTableResponse
-	Erc20Table         *DataTableResponse
-	Erc721Table        *DataTableResponse
-	Erc1155Table       *DataTableResponse
-	WithdrawalsTable   *DataTableResponse
-	EtherValue         template.HTML
-	Tabs               []Eth1AddressPageTabs
+	Address                   string `json:"address"`
+	IsContract                bool
+	SelfDestruct              *Eth1InternalTransactionIndexed
+	ContractCreation          *Eth1InternalTransactionIndexed
+	FundedBy                  *Eth1InternalTransactionIndexed
+	Risk                      *AddressRisk
+	QRCode                    string `json:"qr_code_base64"`
+	QRCodeInverse             string
+	Metadata                  *Eth1AddressMetadata
+	PrivateLabel              string
+	WithdrawalsSummary        template.HTML
+	Summary                   *AddressSummary
+	BlocksMinedTable          *DataTableResponse
+	UnclesMinedTable          *DataTableResponse
+	TransactionsTable         *DataTableResponse
+	InternalTxnsTable         *DataTableResponse
+	Erc20Table                *DataTableResponse
+	Erc721Table               *DataTableResponse
+	Erc1155Table              *DataTableResponse
+	WithdrawalsTable          *DataTableResponse
+	ContractInteractionsTable *DataTableResponse
+	NFTsTable                 *DataTableResponse
+	EtherValue                template.HTML
+	Tabs                      []Eth1AddressPageTabs
+	// set if corrupted or missing rows were skipped while loading the tables
+	PartialResult bool
+}
+
+// EvmInstruction is a single instruction of disassembled evm bytecode, Truncated is set if the code ends within the operand of a push
+type EvmInstruction struct {
+	Pc        uint64 `json:"pc"`
+	Opcode    string `json:"opcode"`
+	Operand   string `json:"operand,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// Eth1AddressCode is the disassembled runtime code of a contract together with its verified source code
+type Eth1AddressCode struct {
+	Size         int                  `json:"size"`
+	CodeHash     string               `json:"code_hash"`
+	Instructions []EvmInstruction     `json:"instructions"`
+	Metadata     string               `json:"metadata,omitempty"`
+	ContractName string               `json:"contract_name,omitempty"`
+	Verified     *Eth1AddressVerified `json:"verified,omitempty"`
+}
+
+// Eth1AddressVerified is the verified source code of a contract, the constructor arguments are decoded with its abi
+type Eth1AddressVerified struct {
+	Source          string                `json:"source"`
+	Match           string                `json:"match,omitempty"`
+	CompilerVersion string                `json:"compiler_version"`
+	Files           []ContractSourceFile  `json:"files"`
+	Abi             json.RawMessage       `json:"abi"`
+	ConstructorArgs []Eth1DecodedArgument `json:"constructor_args,omitempty"`
+	Events          []Eth1AbiEvent        `json:"events"`
+}
+
+type Eth1DecodedArgument struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Eth1AbiEvent is an event of a contract abi, Topic is the hash of the signature that identifies logs of the event
+type Eth1AbiEvent struct {
+	Signature string `json:"signature"`
+	Topic     string `json:"topic"`
+}
+
+// StorageWordDecoding is the value of a storage slot interpreted as the value types that fit into a single slot,
+// Address and String are only set if the word can represent them
+type StorageWordDecoding struct {
+	Slot    string `json:"slot"`
+	Label   string `json:"label,omitempty"`
+	Raw     string `json:"raw"`
+	Uint    string `json:"uint"`
+	Int     string `json:"int"`
+	Bool    bool   `json:"bool"`
+	Address string `json:"address,omitempty"`
+	String  string `json:"string,omitempty"`
 }
 
 type Eth1AddressPageTabs struct {
@@ -1579,6 +1683,149 @@ type Eth1AddressPageTabs struct {
 	Data *DataTableResponse
 }
 
+// ChainAnomaly is a data point of an indexed chain metric that deviates strongly from its recent history
+type ChainAnomaly struct {
+	Metric     string    `json:"metric"`
+	Epoch      uint64    `json:"epoch"`
+	Value      float64   `json:"value"`
+	Expected   float64   `json:"expected"`
+	ZScore     float64   `json:"z_score"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+type StatusPageData struct {
+	Modules   []*StatusPageModule
+	Anomalies []*ChainAnomaly
+}
+
+type StatusPageModule struct {
+	Name       string    `db:"name"`
+	Status     string    `db:"status"`
+	LastUpdate time.Time `db:"last_update"`
+}
+
+type WhaleWatchPageData struct {
+	EtherThreshold        float64
+	Transfers             []*WhaleTransfer
+	CsrfField             template.HTML
+	Subscribed            bool
+	SubscriptionThreshold float64
+}
+
+type WhaleTransfer struct {
+	TxHash      template.HTML
+	BlockNumber uint64
+	Time        time.Time
+	From        template.HTML
+	To          template.HTML
+	Amount      template.HTML
+	Token       template.HTML
+}
+
+type NFTMintsPageData struct {
+	Collections []*NFTMintsCollection
+	Mints       []*NFTMintsRow
+}
+
+type NFTMintsCollection struct {
+	Day        time.Time
+	Collection template.HTML
+	Standard   string
+	Mints      uint64
+	Minters    uint64
+}
+
+type NFTMintsRow struct {
+	TxHash      template.HTML
+	BlockNumber uint64
+	Time        time.Time
+	Collection  template.HTML
+	Standard    string
+	TokenId     string
+	Quantity    string
+	To          template.HTML
+}
+
+type AAOperatorsPageData struct {
+	Days       uint64
+	Bundlers   []*AAOperatorRow
+	Paymasters []*AAOperatorRow
+}
+
+type AAOperatorRow struct {
+	Address     template.HTML
+	Operations  uint64
+	SuccessRate float64
+	GasUsed     uint64
+	GasCost     template.HTML
+}
+
+type NFTCollectionPageData struct {
+	Address        string
+	Name           string
+	Symbol         string
+	Standard       string
+	Owners         uint64
+	OwnersComplete bool
+	Mints          uint64
+	DailyMints     []*NFTCollectionMints
+	TransfersTable *DataTableResponse
+}
+
+type Create2PageData struct {
+	Deployer     string
+	Salt         string
+	InitCodeHash string
+	Error        string
+	Address      string
+	HasActivity  bool
+	Creation     *Eth1InternalTransactionIndexed
+}
+
+type ReorgLayer string
+
+const (
+	ExecutionLayerReorg ReorgLayer = "el"
+	ConsensusLayerReorg ReorgLayer = "cl"
+)
+
+// Reorg is a block that has been replaced by the canonical chain, Depth is the number of consecutive replaced blocks up to and including this one
+type Reorg struct {
+	Layer         ReorgLayer `db:"layer"`
+	Height        uint64     `db:"height"`
+	Hash          []byte     `db:"hash"`
+	CanonicalHash []byte     `db:"canonical_hash"`
+	Depth         uint64     `db:"depth"`
+	BlockTime     time.Time  `db:"block_time"`
+	DetectedTime  time.Time  `db:"detected_time"`
+}
+
+type ReorgsPageData struct {
+	Layer                 ReorgLayer
+	Reorgs                []*Reorg
+	FrequencyConsensus    []*ChartDataPoint
+	FrequencyExecution    []*ChartDataPoint
+	CsrfField             template.HTML
+	Subscribed            bool
+	SubscriptionThreshold float64
+}
+
+type NetworkNodesPageData struct {
+	Snapshots []*NetworkNodeSnapshot
+	Latest    *NetworkNodeSnapshot
+	Clients   []*NodeCountEntry
+	Versions  []*NodeCountEntry
+	Countries []*NodeCountEntry
+	// ClientNames are the clients that are shown as separate series in the client share chart
+	ClientNames []string
+}
+
+type Eth1AddressGraphPageData struct {
+	Address string
+	Name    string
+	Days    uint64
+}
+
 type Eth1AddressMetadata struct {
 	Balances   []*Eth1AddressBalance
 	ERC20      *ERC20Metadata
@@ -1598,6 +1845,7 @@ type ERC20TokenPrice struct {
 	Token       []byte
 	Price       []byte
 	TotalSupply []byte
+	Timestamp   time.Time
 }
 
 type ERC20Metadata struct {
@@ -1624,6 +1872,23 @@ type ContractMetadata struct {
 	Name    string
 	ABI     *abi.ABI `msgpack:"-"`
 	ABIJson []byte
+	// set if the source code of the contract has been verified
+	Verification *ContractVerification
+}
+
+// ContractVerification is the verified source code of a contract, Source is either sourcify or etherscan and
+// Match is the sourcify match type (full or partial)
+type ContractVerification struct {
+	Source          string               `json:"source"`
+	Match           string               `json:"match,omitempty"`
+	CompilerVersion string               `json:"compiler_version"`
+	ConstructorArgs []byte               `json:"constructor_args,omitempty"`
+	Files           []ContractSourceFile `json:"files"`
+}
+
+type ContractSourceFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
 }
 
 type Eth1TokenPageData struct {
@@ -1651,6 +1916,9 @@ type Transfer struct {
 	To     template.HTML
 	Amount template.HTML
 	Token  template.HTML
+	// position of internal transfers in the call trace of the transaction
+	TraceIndex uint64
+	TracePath  string
 }
 
 type DepositContractInteraction struct {
@@ -1663,6 +1931,7 @@ type Eth1TxData struct {
 	From         common.Address
 	To           *common.Address
 	InternalTxns []Transfer
+	ItxTree      []*ItxTreeNode
 	FromName     string
 	ToName       string
 	Gas          struct {
@@ -1675,7 +1944,8 @@ type Eth1TxData struct {
 		TxFee          []byte
 		EffectiveFee   []byte
 	}
-	Epoch struct {
+	FeeBreakdown *TxFeeBreakdown
+	Epoch        struct {
 		Finalized     bool    `db:"finalized"`
 		Participation float64 `db:"globalparticipationrate"`
 	}
@@ -1683,6 +1953,8 @@ type Eth1TxData struct {
 	Type                        uint8
 	Nonce                       uint64
 	TxnPosition                 uint
+	BlockTxCount                uint64
+	GasPricePercentile          uint64
 	Hash                        common.Hash
 	Value                       []byte
 	Receipt                     *geth_types.Receipt
@@ -1722,31 +1994,14 @@ type SourcifyContractMetadata struct {
 	} `json:"compiler"`
 	Language string `json:"language"`
 	Output   struct {
-		Abi []struct {
-			Anonymous bool `json:"anonymous"`
-			Inputs    []struct {
-				Indexed      bool   `json:"indexed"`
-				InternalType string `json:"internalType"`
-				Name         string `json:"name"`
-				Type         string `json:"type"`
-			} `json:"inputs"`
-			Name    string `json:"name"`
-			Outputs []struct {
-				InternalType string `json:"internalType"`
-				Name         string `json:"name"`
-				Type         string `json:"type"`
-			} `json:"outputs"`
-			StateMutability string `json:"stateMutability"`
-			Type            string `json:"type"`
-		} `json:"abi"`
+		Abi json.RawMessage `json:"abi"`
 	} `json:"output"`
 	Settings struct {
-		CompilationTarget struct {
-			Browser_Stakehavens_sol string `json:"browser/Stakehavens.sol"`
-		} `json:"compilationTarget"`
-		EvmVersion string   `json:"evmVersion"`
-		Libraries  struct{} `json:"libraries"`
-		Metadata   struct {
+		// maps the path of the compiled source file to the name of the contract
+		CompilationTarget map[string]string `json:"compilationTarget"`
+		EvmVersion        string            `json:"evmVersion"`
+		Libraries         struct{}          `json:"libraries"`
+		Metadata          struct {
 			BytecodeHash string `json:"bytecodeHash"`
 		} `json:"metadata"`
 		Optimizer struct {
@@ -1755,15 +2010,23 @@ type SourcifyContractMetadata struct {
 		} `json:"optimizer"`
 		Remappings []interface{} `json:"remappings"`
 	} `json:"settings"`
-	Sources struct {
-		Browser_Stakehavens_sol struct {
-			Keccak256 string   `json:"keccak256"`
-			Urls      []string `json:"urls"`
-		} `json:"browser/Stakehavens.sol"`
+	Sources map[string]struct {
+		Keccak256 string   `json:"keccak256"`
+		Urls      []string `json:"urls"`
 	} `json:"sources"`
 	Version int64 `json:"version"`
 }
 
+// SourcifyFilesResponse is the response of the files endpoint of the sourcify server, Status is either full or partial
+type SourcifyFilesResponse struct {
+	Status string `json:"status"`
+	Files  []struct {
+		Name    string `json:"name"`
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	} `json:"files"`
+}
+
 type EtherscanContractMetadata struct {
 	Message string `json:"message"`
 	Result  []struct {
@@ -1812,6 +2075,7 @@ type Eth1BlockPageData struct {
 	Txs                   []Eth1BlockPageTransaction
 	Uncles                []Eth1BlockPageData
 	State                 string
+	Finality              template.HTML
 }
 
 type Eth1BlockPageTransaction struct {
@@ -1913,6 +2177,44 @@ type BurnPageData struct {
 	Currency         string               `json:"currency"`
 }
 
+// TopMovements summarizes the most recent execution blocks, ether values are denominated in ether and gas prices in gwei
+type TopMovements struct {
+	FromBlock        uint64                 `json:"from_block"`
+	ToBlock          uint64                 `json:"to_block"`
+	Transactions     uint64                 `json:"transactions"`
+	LargestTransfers []*TopMovementTransfer `json:"largest_transfers"`
+	ActiveContracts  []*TopMovementContract `json:"active_contracts"`
+	Gas              TopMovementGas         `json:"gas"`
+	UpdatedAt        time.Time              `json:"updated_at"`
+}
+
+type TopMovementTransfer struct {
+	Hash     string  `json:"hash"`
+	Block    uint64  `json:"block"`
+	From     string  `json:"from"`
+	FromName string  `json:"from_name,omitempty"`
+	To       string  `json:"to"`
+	ToName   string  `json:"to_name,omitempty"`
+	Value    float64 `json:"value"`
+}
+
+type TopMovementContract struct {
+	Address string `json:"address"`
+	Name    string `json:"name,omitempty"`
+	Calls   uint64 `json:"calls"`
+	GasUsed uint64 `json:"gas_used"`
+}
+
+type TopMovementGas struct {
+	GasUsed        uint64  `json:"gas_used"`
+	Utilization    float64 `json:"utilization"`
+	MinBaseFee     float64 `json:"min_base_fee"`
+	AvgBaseFee     float64 `json:"avg_base_fee"`
+	MaxBaseFee     float64 `json:"max_base_fee"`
+	MedianGasPrice float64 `json:"median_gas_price"`
+	Burned         float64 `json:"burned"`
+}
+
 type CorrelationDataResponse struct {
 	Status  string      `json:"status"`
 	Data    interface{} `json:"data"`
@@ -1959,6 +2261,15 @@ type ValidatorsBLSChange struct {
 	WithdrawalCredentialsOld []byte `db:"withdrawalcredentials" json:"withdrawalcredentials,omitempty"`
 }
 
+// ValidatorCredentialRotation is a change of the withdrawal credentials of a validator, e.g. from 0x00 to 0x01 credentials
+type ValidatorCredentialRotation struct {
+	ValidatorIndex  uint64 `db:"validatorindex" json:"validatorindex"`
+	Pubkey          []byte `db:"pubkey" json:"pubkey"`
+	Slot            uint64 `db:"block_slot" json:"slot"`
+	FromCredentials []byte `db:"from_credentials" json:"from_credentials"`
+	ToCredentials   []byte `db:"to_credentials" json:"to_credentials"`
+}
+
 // AdConfig is a struct to hold the configuration for one specific ad banner placement
 type AdConfig struct {
 	Id              string `db:"id"`
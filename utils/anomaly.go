@@ -0,0 +1,39 @@
+package utils
+
+import "math"
+
+// maximum absolute z-score reported for series without any variance
+const maxAnomalyZScore = 100
+
+// AnomalyScore compares the last value of a series against the exponentially weighted moving average (EWMA) and
+// standard deviation of all preceding values. It returns the expected value and the z-score of the last value.
+// alpha is the smoothing factor of the EWMA, higher values give recent observations more weight.
+func AnomalyScore(series []float64, alpha float64) (expected float64, zScore float64) {
+	if len(series) < 2 {
+		return 0, 0
+	}
+
+	mean := series[0]
+	variance := 0.0
+	for _, v := range series[1 : len(series)-1] {
+		diff := v - mean
+		incr := alpha * diff
+		mean += incr
+		variance = (1 - alpha) * (variance + diff*incr)
+	}
+
+	last := series[len(series)-1]
+	stdDev := math.Sqrt(variance)
+	if stdDev == 0 {
+		switch {
+		case last > mean:
+			return mean, maxAnomalyZScore
+		case last < mean:
+			return mean, -maxAnomalyZScore
+		}
+		return mean, 0
+	}
+
+	zScore = (last - mean) / stdDev
+	return mean, math.Max(-maxAnomalyZScore, math.Min(maxAnomalyZScore, zScore))
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnomalyScore(t *testing.T) {
+	steady := make([]float64, 0, 100)
+	for i := 0; i < 100; i++ {
+		steady = append(steady, 100+10*math.Sin(float64(i)))
+	}
+
+	tests := []struct {
+		name      string
+		series    []float64
+		anomalous bool
+	}{
+		{"too short", []float64{1}, false},
+		{"steady", steady, false},
+		{"spike", append(append([]float64{}, steady...), 300), true},
+		{"drop", append(append([]float64{}, steady...), 0), true},
+		{"constant", []float64{5, 5, 5, 5, 5}, false},
+		{"constant with change", []float64{0, 0, 0, 0, 3}, true},
+	}
+	for _, tt := range tests {
+		_, z := AnomalyScore(tt.series, 0.1)
+		if anomalous := math.Abs(z) >= 4; anomalous != tt.anomalous {
+			t.Errorf("wrong anomaly detection for series %v: z-score %v", tt.name, z)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+
+	// responses with a known length below this threshold are sent uncompressed
+	compressionMinLength = 1024
+	// brotli levels above 5 are too slow for dynamic content
+	brotliCompressionLevel = 5
+)
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	},
+}
+
+var brotliWriterPool = sync.Pool{
+	New: func() interface{} {
+		return brotli.NewWriterLevel(io.Discard, brotliCompressionLevel)
+	},
+}
+
+// content types that are already compressed and would only grow when compressed again
+var incompressibleContentTypes = []string{
+	"image/",
+	"video/",
+	"audio/",
+	"font/woff",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/x-brotli",
+	"application/zstd",
+	"application/pdf",
+	"application/octet-stream",
+}
+
+// CompressionMiddleware compresses responses using brotli or gzip, depending on the Accept-Encoding header of the request.
+// Compressed data is flushed along with the response, so streamed and chunked responses keep working.
+func CompressionMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Upgrade") != "" || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		defer func() {
+			err := cw.Close()
+			if err != nil {
+				logger.Errorf("error closing %v writer for %v: %v", encoding, r.URL.Path, err)
+			}
+		}()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the preferred supported encoding of an Accept-Encoding header or an empty string if none is acceptable
+func negotiateEncoding(acceptEncoding string) string {
+	best := ""
+	bestQ := 0.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if coding != encodingBrotli && coding != encodingGzip {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		// prefer brotli if both encodings are equally acceptable
+		if q > bestQ || (q == bestQ && q > 0 && coding == encodingBrotli) {
+			best = coding
+			bestQ = q
+		}
+	}
+	return best
+}
+
+// compressResponseWriter decides whether to compress the response once the headers are written
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	writer      io.WriteCloser
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (cw *compressResponseWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+
+	if cw.shouldCompress(status) {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+
+		switch cw.encoding {
+		case encodingBrotli:
+			bw := brotliWriterPool.Get().(*brotli.Writer)
+			bw.Reset(cw.ResponseWriter)
+			cw.writer = bw
+		case encodingGzip:
+			gw := gzipWriterPool.Get().(*gzip.Writer)
+			gw.Reset(cw.ResponseWriter)
+			cw.writer = gw
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+func (cw *compressResponseWriter) shouldCompress(status int) bool {
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	h := cw.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	if length := h.Get("Content-Length"); length != "" {
+		if l, err := strconv.Atoi(length); err == nil && l < compressionMinLength {
+			return false
+		}
+	}
+
+	contentType := strings.ToLower(h.Get("Content-Type"))
+	if contentType == "image/svg+xml" {
+		return true
+	}
+	for _, t := range incompressibleContentTypes {
+		if strings.HasPrefix(contentType, t) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cw *compressResponseWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if cw.writer == nil {
+		return cw.ResponseWriter.Write(b)
+	}
+	return cw.writer.Write(b)
+}
+
+// Flush writes all buffered compressed data to the client, which allows handlers to stream their responses
+func (cw *compressResponseWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+
+	if gw, ok := cw.writer.(*gzip.Writer); ok {
+		if err := gw.Flush(); err != nil {
+			logger.Errorf("error flushing gzip writer: %v", err)
+		}
+	} else if bw, ok := cw.writer.(*brotli.Writer); ok {
+		if err := bw.Flush(); err != nil {
+			logger.Errorf("error flushing brotli writer: %v", err)
+		}
+	}
+
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (cw *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := cw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the underlying response writer does not implement http.Hijacker")
+	}
+	cw.hijacked = true
+	return hj.Hijack()
+}
+
+// Close finishes the compressed stream and returns the writer to its pool
+func (cw *compressResponseWriter) Close() error {
+	if cw.writer == nil || cw.hijacked {
+		return nil
+	}
+
+	err := cw.writer.Close()
+	switch w := cw.writer.(type) {
+	case *brotli.Writer:
+		w.Reset(io.Discard)
+		brotliWriterPool.Put(w)
+	case *gzip.Writer:
+		w.Reset(io.Discard)
+		gzipWriterPool.Put(w)
+	}
+	cw.writer = nil
+	return err
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"fmt"
+)
+
+// The surrogate keys are prefixed with the chain id so that the explorers of several networks can share a cdn
+
+// EdgeCacheBlockKey returns the surrogate key of the responses showing an execution block
+func EdgeCacheBlockKey(number uint64) string {
+	return fmt.Sprintf("%d-block-%d", Config.Chain.Config.DepositChainID, number)
+}
+
+// EdgeCacheAddressKey returns the surrogate key of the responses showing an address
+func EdgeCacheAddressKey(address []byte) string {
+	return fmt.Sprintf("%d-address-%x", Config.Chain.Config.DepositChainID, address)
+}
+
+// EdgeCacheChartKey returns the surrogate key of the responses showing a chart, the charts page is keyed by the chart "all"
+func EdgeCacheChartKey(chart string) string {
+	return fmt.Sprintf("%d-chart-%s", Config.Chain.Config.DepositChainID, chart)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EnsRegistryAddress is the address of the ENS registry (identical on mainnet and the public testnets)
+var EnsRegistryAddress = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// EnsNamehash computes the EIP-137 namehash of an ens name
+func EnsNamehash(name string) common.Hash {
+	node := common.Hash{}
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(strings.ToLower(name), ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = common.BytesToHash(crypto.Keccak256(node.Bytes(), crypto.Keccak256([]byte(labels[i]))))
+	}
+	return node
+}
+
+// EnsReverseName returns the name of the reverse record of an address, e.g. 314159265dd8dbb310642f98f50c066173c1259b.addr.reverse
+func EnsReverseName(address []byte) string {
+	return fmt.Sprintf("%x.addr.reverse", address)
+}
+
+// IsEnsName returns true if the given string looks like a resolvable ens name
+func IsEnsName(name string) bool {
+	return len(name) > 4 && strings.HasSuffix(strings.ToLower(name), ".eth") && !strings.Contains(name, " ")
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEnsNamehash(t *testing.T) {
+	tests := map[string]string{
+		"":        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+		"FOO.eth": "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range tests {
+		if got := EnsNamehash(name); got != common.HexToHash(want) {
+			t.Errorf("namehash of %q: got %v, want %v", name, got.Hex(), want)
+		}
+	}
+}
+
+func TestEnsReverseName(t *testing.T) {
+	address := common.HexToAddress("0x314159265dD8dbb310642f98f50C066173C1259b")
+	if got := EnsReverseName(address.Bytes()); got != "314159265dd8dbb310642f98f50c066173c1259b.addr.reverse" {
+		t.Errorf("unexpected reverse name %v", got)
+	}
+}
@@ -131,3 +131,16 @@ func VerifyVoluntaryExitSignature(op *phase0.SignedVoluntaryExit, forkVersion, p
 func FixAddressCasing(add string) string {
 	return common.HexToAddress(add).Hex()
 }
+
+// CalldataGas returns the intrinsic gas charged for the calldata of a transaction (EIP-2028)
+func CalldataGas(data []byte) uint64 {
+	gas := uint64(0)
+	for _, b := range data {
+		if b == 0 {
+			gas += 4
+		} else {
+			gas += 16
+		}
+	}
+	return gas
+}
@@ -91,6 +91,32 @@ func FormatTransactionHash(hash []byte) template.HTML {
 	return template.HTML(fmt.Sprintf(`<a class="text-monospace" href="/tx/0x%x">0x%x…%x</a>`, hash, hash[:3], hash[len(hash)-3:]))
 }
 
+// AddressLabelSlug returns the url slug of an address name, e.g. "Beacon Deposit Contract" becomes "beacon-deposit-contract"
+func AddressLabelSlug(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(name) {
+		if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(c)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+// FormatItxHash links to the call frame of an internal transaction in the trace of its parent transaction
+func FormatItxHash(hash []byte, index uint64) template.HTML {
+	if len(hash) < 20 {
+		return template.HTML("N/A")
+	}
+	return template.HTML(fmt.Sprintf(`<a class="text-monospace" href="/tx/0x%x#itx-%d">0x%x…%x</a>`, hash, index, hash[:3], hash[len(hash)-3:]))
+}
+
 func FormatInOutSelf(address, from, to []byte) template.HTML {
 	if address == nil && len(address) == 0 {
 		return ""
@@ -279,18 +305,29 @@ func formatAmount(amount *big.Int, unit string, digits int, maxPreCommaDigitsBef
 	// define display unit & digits used per unit max
 	displayUnit := " " + unit
 	var unitDigits int
-	if unit == "ETH" || unit == "Ether" {
-		unitDigits = 18
-	} else if unit == "GWei" {
-		unitDigits = 9
+	if denomination, ok := types.DenominationByUnit(unit); ok {
+		unitDigits = int(denomination.Decimals)
 	} else {
 		displayUnit = " ?"
 		unitDigits = 0
 	}
 
+	return formatAmountDigits(amount, displayUnit, unitDigits, digits, maxPreCommaDigitsBeforeTrim, fullAmountTooltip, smallUnit, newLineForUnit)
+}
+
+// FormatTypedAmount formats an amount in whole units of its currency, token amounts are shown with their symbol
+func FormatTypedAmount(amount types.Amount, digits int) template.HTML {
+	unit := " " + template.HTMLEscapeString(amount.Symbol)
+	if amount.Symbol == "" {
+		unit = ""
+	}
+	return formatAmountDigits(amount.BigInt(), unit, int(amount.Decimals), digits, 0, true, false, false)
+}
+
+func formatAmountDigits(amount *big.Int, displayUnit string, unitDigits int, digits int, maxPreCommaDigitsBeforeTrim int, fullAmountTooltip bool, smallUnit bool, newLineForUnit bool) template.HTML {
 	// small unit & new line for unit handling
 	{
-		unit = displayUnit
+		unit := displayUnit
 		if newLineForUnit {
 			displayUnit = "<BR />"
 		} else {
@@ -314,7 +351,7 @@ func formatAmount(amount *big.Int, unit string, digits int, maxPreCommaDigitsBef
 	}
 
 	// done, convert to HTML & return
-	return template.HTML(fmt.Sprintf("<span%s>%s%s</span>", tooltip, trimmedAmount, displayUnit))
+	return template.HTML(fmt.Sprintf("<span%s>%s%s</span>", tooltip, FormatNumberSeparators(trimmedAmount), displayUnit))
 }
 
 func trimAmount(amount *big.Int, unitDigits int, maxPreCommaDigitsBeforeTrim int, digits int, addPositiveSign bool) (trimmedAmount, fullAmount string) {
@@ -383,6 +420,7 @@ func trimAmount(amount *big.Int, unitDigits int, maxPreCommaDigitsBeforeTrim int
 }
 
 func FormatMethod(method string) template.HTML {
+	method = template.HTMLEscapeString(method)
 	return template.HTML(fmt.Sprintf(`<span class="badge badge-light text-truncate mw-100" data-toggle="tooltip" title="%s">%s</span>`, method, method))
 }
 
@@ -434,3 +472,24 @@ func FormatTokenIcon(icon []byte, size int) template.HTML {
 	icon64 := base64.StdEncoding.EncodeToString(icon)
 	return template.HTML(fmt.Sprintf("<img class=\"mb-1 mr-1\" src=\"data:image/gif;base64,%v\" width=\"%v\" height=\"%v\">", icon64, size, size))
 }
+
+// FormatNFTHolding renders a token held by an address as a gallery card linking to its collection, the quantity is only shown for balances other than one
+func FormatNFTHolding(tokenAddress []byte, name string, tokenId *big.Int, standard string, balance *big.Int) template.HTML {
+	quantity := ""
+	if balance.Cmp(big.NewInt(1)) != 0 {
+		quantity = fmt.Sprintf(`<span class="badge badge-secondary font-weight-normal">x%v</span>`, balance)
+	}
+	return template.HTML(fmt.Sprintf(`<div class="card h-100"><div class="d-flex align-items-center justify-content-center text-muted position-relative" style="height: 140px;" title="%v"><i class="fas fa-palette fa-3x"></i><img class="nft-preview position-absolute w-100 h-100" style="object-fit: contain; top: 0; left: 0;" loading="lazy" alt="" src="%v"></div><div class="card-body p-2"><div class="text-truncate">%v</div><div class="d-flex justify-content-between align-items-center small"><span class="text-monospace text-truncate" title="%v">#%v</span>%v</div></div></div>`,
+		standard, NFTPreviewURL(tokenAddress, tokenId), FormatAddressWithLimits(tokenAddress, name, true, "collection", 15, 18, false), tokenId, tokenId, quantity))
+}
+
+// NFTPreviewURL returns the path of the thumbnail of the image of an nft
+func NFTPreviewURL(tokenAddress []byte, tokenId *big.Int) string {
+	return fmt.Sprintf("/nft/0x%x/%v/image", tokenAddress, tokenId)
+}
+
+// FormatNFTTokenId returns the id of an nft preceded by a small preview of its image
+func FormatNFTTokenId(tokenAddress []byte, tokenId *big.Int) template.HTML {
+	return template.HTML(fmt.Sprintf(`<span class="d-inline-flex align-items-center"><img class="nft-preview rounded mr-1" style="width: 24px; height: 24px; object-fit: cover;" loading="lazy" alt="" src="%v"><span class="text-monospace text-truncate" style="max-width: 12rem;" title="%v">%v</span></span>`,
+		NFTPreviewURL(tokenAddress, tokenId), tokenId, tokenId))
+}
@@ -93,3 +93,20 @@ func TestVerifyVoluntaryExitSignature(t *testing.T) {
 		}
 	}
 }
+
+func TestCalldataGas(t *testing.T) {
+	tests := []struct {
+		data []byte
+		gas  uint64
+	}{
+		{nil, 0},
+		{[]byte{0x00}, 4},
+		{[]byte{0x01}, 16},
+		{[]byte{0xa9, 0x05, 0x9c, 0xbb, 0x00, 0x00}, 72},
+	}
+	for _, tt := range tests {
+		if got := CalldataGas(tt.data); got != tt.gas {
+			t.Errorf("CalldataGas(%x) = %v, want %v", tt.data, got, tt.gas)
+		}
+	}
+}
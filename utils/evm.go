@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/binary"
+	"eth2-exporter/types"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KnownStorageSlots labels the storage slots that proxy standards reserve at fixed positions
+var KnownStorageSlots = map[common.Hash]string{
+	// bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+	common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc"): "EIP-1967 implementation",
+	// bytes32(uint256(keccak256("eip1967.proxy.admin")) - 1)
+	common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103"): "EIP-1967 admin",
+	// bytes32(uint256(keccak256("eip1967.proxy.beacon")) - 1)
+	common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50"): "EIP-1967 beacon",
+	// keccak256("PROXIABLE")
+	common.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7"): "EIP-1822 implementation",
+}
+
+// DisassembleBytecode decodes the instructions of evm bytecode. The metadata solidity and vyper append to the runtime code
+// is not part of the instructions and returned separately.
+func DisassembleBytecode(code []byte) ([]types.EvmInstruction, []byte) {
+	code, metadata := splitBytecodeMetadata(code)
+
+	instructions := make([]types.EvmInstruction, 0, len(code))
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		instruction := types.EvmInstruction{Pc: uint64(pc), Opcode: op.String()}
+		if strings.HasPrefix(instruction.Opcode, "opcode ") {
+			instruction.Opcode = fmt.Sprintf("INVALID(0x%02x)", code[pc])
+		}
+		if op.IsPush() {
+			size := int(op-vm.PUSH1) + 1
+			end := pc + 1 + size
+			if end > len(code) {
+				// the operand of a push at the end of the code is implicitly padded with zeros
+				end = len(code)
+				instruction.Truncated = true
+			}
+			instruction.Operand = fmt.Sprintf("0x%x", code[pc+1:end])
+			pc = end - 1
+		}
+		instructions = append(instructions, instruction)
+	}
+	return instructions, metadata
+}
+
+// splitBytecodeMetadata splits the cbor encoded metadata from the end of the bytecode, the last two bytes of the code hold the length of the metadata
+func splitBytecodeMetadata(code []byte) ([]byte, []byte) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	length := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	start := len(code) - 2 - length
+	// the metadata is a cbor map with at most a handful of entries
+	if length == 0 || start < 0 || code[start] < 0xa1 || code[start] > 0xa5 {
+		return code, nil
+	}
+	return code[:start], code[start : len(code)-2]
+}
+
+// StorageMappingSlot returns the slot of the value of a key in a mapping that is stored at slot
+func StorageMappingSlot(key []byte, slot common.Hash) common.Hash {
+	return crypto.Keccak256Hash(common.LeftPadBytes(key, 32), slot.Bytes())
+}
+
+// DecodeStorageWord interprets a storage word as the value types that are commonly stored in a single slot
+func DecodeStorageWord(slot common.Hash, word []byte) *types.StorageWordDecoding {
+	word = common.LeftPadBytes(word, 32)
+	value := new(big.Int).SetBytes(word)
+
+	decoding := &types.StorageWordDecoding{
+		Slot:  slot.Hex(),
+		Raw:   fmt.Sprintf("0x%x", word),
+		Label: KnownStorageSlots[slot],
+		Uint:  value.String(),
+		Int:   value.String(),
+		Bool:  value.Cmp(big.NewInt(1)) == 0,
+	}
+	if word[0]&0x80 != 0 {
+		decoding.Int = new(big.Int).Sub(value, new(big.Int).Lsh(big.NewInt(1), 256)).String()
+	}
+	if value.BitLen() <= 160 {
+		decoding.Address = common.BytesToAddress(word).Hex()
+	}
+
+	// solidity stores strings and bytes shorter than 32 bytes left aligned with twice their length in the lowest byte
+	if length := int(word[31]); length > 0 && length%2 == 0 && length/2 < 32 {
+		data := word[:length/2]
+		if utf8.Valid(data) && !strings.ContainsRune(string(data), 0) && new(big.Int).SetBytes(word[length/2:31]).Sign() == 0 {
+			decoding.String = string(data)
+		}
+	}
+	return decoding
+}
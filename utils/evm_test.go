@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDisassembleBytecode(t *testing.T) {
+	// PUSH1 0x80 PUSH1 0x40 MSTORE 0xfe PUSH2 0x01 (truncated) followed by a 3 byte metadata map and its length
+	code := common.FromHex("0x6080604052fe6101a161000003")
+	instructions, metadata := DisassembleBytecode(code)
+
+	want := []string{"PUSH1 0x80", "PUSH1 0x40", "MSTORE", "INVALID", "PUSH2 0x01"}
+	if len(instructions) != len(want) {
+		t.Fatalf("got %v instructions, want %v: %+v", len(instructions), len(want), instructions)
+	}
+	for i, instruction := range instructions {
+		got := instruction.Opcode
+		if instruction.Operand != "" {
+			got += " " + instruction.Operand
+		}
+		if got != want[i] {
+			t.Errorf("instruction %v: got %q, want %q", i, got, want[i])
+		}
+	}
+	if !instructions[4].Truncated || instructions[4].Pc != 6 {
+		t.Errorf("expected a truncated push at pc 6, got %+v", instructions[4])
+	}
+	if common.Bytes2Hex(metadata) != "a16100" {
+		t.Errorf("unexpected metadata %x", metadata)
+	}
+}
+
+func TestDecodeStorageWord(t *testing.T) {
+	// "beaconcha.in" stored as a short solidity string
+	word := common.FromHex("0x626561636f6e6368612e696e0000000000000000000000000000000000000018")
+	decoding := DecodeStorageWord(common.Hash{}, word)
+	if decoding.String != "beaconcha.in" {
+		t.Errorf("unexpected string %q", decoding.String)
+	}
+	if decoding.Address != "" {
+		t.Errorf("a string word must not decode to an address, got %v", decoding.Address)
+	}
+
+	minusOne := common.FromHex("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")
+	if decoding := DecodeStorageWord(common.Hash{}, minusOne); decoding.Int != "-1" {
+		t.Errorf("unexpected int %v", decoding.Int)
+	}
+
+	slot := common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	decoding = DecodeStorageWord(slot, common.HexToAddress("0x00000000219ab540356cBB839Cbe05303d7705Fa").Bytes())
+	if decoding.Label != "EIP-1967 implementation" || decoding.Address != "0x00000000219ab540356cBB839Cbe05303d7705Fa" {
+		t.Errorf("unexpected decoding of the implementation slot %+v", decoding)
+	}
+}
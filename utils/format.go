@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"database/sql"
 	"encoding/base64"
 	"encoding/hex"
@@ -247,7 +248,7 @@ func FormatAddCommasFormated(num float64, precision uint) template.HTML {
 	if precision > 0 {
 		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
 	}
-	return template.HTML(strings.ReplaceAll(string([]rune(p.Sprintf(s, num))), ",", `<span class="thousands-separator"></span>`))
+	return template.HTML(FormatNumberSeparators(p.Sprintf(s, num)))
 }
 
 func FormatBigNumberAddCommasFormated(val hexutil.Big, precision uint) template.HTML {
@@ -255,10 +256,7 @@ func FormatBigNumberAddCommasFormated(val hexutil.Big, precision uint) template.
 }
 
 func FormatAddCommas(n uint64) template.HTML {
-	number := FormatFloat(float64(n), 2)
-
-	number = strings.ReplaceAll(number, ",", `<span class="thousands-separator"></span>`)
-	return template.HTML(number)
+	return template.HTML(FormatNumberSeparators(FormatFloat(float64(n), 2)))
 }
 
 // FormatBlockRoot will return the block-root formated as html
@@ -319,6 +317,17 @@ func FormatBlockStatus(status uint64) template.HTML {
 	}
 }
 
+// FormatEth1BlockFinality will return a badge showing whether an execution block is safe or finalized
+func FormatEth1BlockFinality(finality types.Eth1BlockFinality) template.HTML {
+	switch finality {
+	case types.Eth1BlockFinalized:
+		return `<span title="This block is part of a finalized checkpoint and can not be reverted" data-toggle="tooltip" class="badge badge-pill bg-success text-white" style="font-size: 12px; font-weight: 500;"><i class="fas fa-lock mr-1"></i>Finalized</span>`
+	case types.Eth1BlockSafe:
+		return `<span title="This block is part of a justified checkpoint and is unlikely to be reverted" data-toggle="tooltip" class="badge badge-pill bg-info text-white" style="font-size: 12px; font-weight: 500;"><i class="fas fa-shield-alt mr-1"></i>Safe</span>`
+	}
+	return `<span title="This block is not justified yet and might be reverted by a reorg" data-toggle="tooltip" class="badge badge-pill bg-light text-dark" style="font-size: 12px; font-weight: 500;">Unfinalized</span>`
+}
+
 // FormatBlockStatusShort will return an html status for a block.
 func FormatBlockStatusShort(status uint64) template.HTML {
 	// genesis <span class="badge text-dark" style="background: rgba(179, 159, 70, 0.8) none repeat scroll 0% 0%;">Genesis</span>
@@ -505,6 +514,14 @@ func AddressToWithdrawalCredentials(address []byte) ([]byte, error) {
 	return nil, fmt.Errorf("invalid eth1 address")
 }
 
+// BLSPubkeyToWithdrawalCredentials returns the 0x00 withdrawal credentials of a BLS withdrawal public key
+func BLSPubkeyToWithdrawalCredentials(pubkey []byte) []byte {
+	hash := sha256.Sum256(pubkey)
+	credentials := make([]byte, 32)
+	copy(credentials[1:], hash[1:])
+	return credentials
+}
+
 func FormatHashWithCopy(hash []byte) template.HTML {
 	if len(hash) == 0 {
 		return "N/A"
@@ -816,6 +833,113 @@ func FormatTimestampTs(ts time.Time) template.HTML {
 	return template.HTML(fmt.Sprintf("<span class=\"timestamp\" title=\"%v\" data-timestamp=\"%d\"></span>", ts, ts.Unix()))
 }
 
+const (
+	TimestampModeRelative = "relative"
+	TimestampModeAbsolute = "absolute"
+)
+
+// AvailableTimezones lists the timezones offered in the timestamp preferences, an empty selection renders timestamps in the browser timezone
+var AvailableTimezones = []string{
+	"UTC",
+	"America/Los_Angeles",
+	"America/Chicago",
+	"America/New_York",
+	"America/Sao_Paulo",
+	"Europe/London",
+	"Europe/Berlin",
+	"Europe/Moscow",
+	"Asia/Dubai",
+	"Asia/Kolkata",
+	"Asia/Singapore",
+	"Asia/Shanghai",
+	"Asia/Tokyo",
+	"Asia/Seoul",
+	"Australia/Sydney",
+}
+
+// DefaultNumberLocale is used if the user did not select a number format
+const DefaultNumberLocale = "en"
+
+// AvailableNumberLocales lists the number formats offered in the display preferences. Amounts are rendered with
+// separator elements whose content is set by the stylesheet of the selected locale, so cached html stays locale independent
+var AvailableNumberLocales = []types.NumberLocale{
+	{Code: "en", Name: "1,234.56", ThousandsSeparator: ",", DecimalSeparator: "."},
+	{Code: "de", Name: "1.234,56", ThousandsSeparator: ".", DecimalSeparator: ","},
+	{Code: "fr", Name: "1 234,56", ThousandsSeparator: "\u202f", DecimalSeparator: ","},
+	{Code: "ch", Name: "1'234.56", ThousandsSeparator: "'", DecimalSeparator: "."},
+}
+
+// IsNumberLocale returns true if code is one of the available number locales
+func IsNumberLocale(code string) bool {
+	for _, l := range AvailableNumberLocales {
+		if l.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatNumberSeparators converts a plain or english formatted number like -1,234.56 into html with locale
+// independent thousands and decimal separator elements. The decimal point is kept as hidden text so that the
+// text content of the element remains a parsable number.
+func FormatNumberSeparators(number string) string {
+	number = strings.ReplaceAll(number, ",", "")
+	sign := ""
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(`<span class="thousands-separator"></span>`)
+		}
+		b.WriteRune(r)
+	}
+	if hasFraction {
+		b.WriteString(`<span class="decimal-separator"><span>.</span></span>`)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// FormatTimestampTz will return a timestamp formated as html in the given timezone and mode (relative or absolute).
+// The server-side rendering serves as fallback, client-side js will update the timestamp using the same preferences
+func FormatTimestampTz(ts int64, timezone, mode string) template.HTML {
+	t := time.Unix(ts, 0).UTC()
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			t = t.In(loc)
+		} else {
+			timezone = ""
+		}
+	}
+	if mode != TimestampModeAbsolute {
+		mode = TimestampModeRelative
+	}
+
+	absolute := t.Format("Jan-02-2006 15:04:05 MST")
+	text := absolute
+	title := HumanizeTime(t)
+	if mode == TimestampModeRelative {
+		text, title = title, absolute
+	}
+
+	return template.HTML(fmt.Sprintf("<span class=\"timestamp\" title=\"%v\" data-toggle=\"tooltip\" data-placement=\"top\" data-timestamp=\"%d\" data-timezone=\"%v\" data-timestamp-mode=\"%v\">%v</span>", title, ts, html.EscapeString(timezone), mode, text))
+}
+
+// FormatTimestampUInt64Tz will return a timestamp formated as html in the given timezone and mode
+func FormatTimestampUInt64Tz(ts uint64, timezone, mode string) template.HTML {
+	return FormatTimestampTz(int64(ts), timezone, mode)
+}
+
+// FormatTimestampTsTz will return a timestamp formated as html in the given timezone and mode
+func FormatTimestampTsTz(ts time.Time, timezone, mode string) template.HTML {
+	return FormatTimestampTz(ts.Unix(), timezone, mode)
+}
+
 // FormatValidatorStatus will return the validator-status formated as html
 // possible states
 // pending, active_online, active_offline, exiting_online, exciting_offline, slashing_online, slashing_offline, exited, slashed
@@ -1171,7 +1295,7 @@ func FormatTokenBalance(balance *types.Eth1AddressBalance) template.HTML {
 		<div class="token-price p-1">
 			<span class="text-muted" style="font-size: 90%%;">@ $%.2f</span>
 		</div>
-	</div>`, balance.Token, balance.Address, logo, symbolTitle, symbol, bflt, FormatThousandsEnglish(strconv.FormatFloat(flt, 'f', -1, 64)), pflt))
+	</div>`, balance.Token, balance.Address, logo, symbolTitle, symbol, bflt, FormatNumberSeparators(strconv.FormatFloat(flt, 'f', -1, 64)), pflt))
 }
 
 func FormatAddressEthBalance(balance *types.Eth1AddressBalance) template.HTML {
@@ -1196,15 +1320,55 @@ func FormatTokenValue(balance *types.Eth1AddressBalance) template.HTML {
 	num := decimal.NewFromBigInt(new(big.Int).SetBytes(balance.Balance), 0)
 	f, _ := num.DivRound(mul, int32(decimals.Int64())).Float64()
 
-	return template.HTML(p.Sprintf("%s", FormatThousandsEnglish(strconv.FormatFloat(f, 'f', -1, 64))))
+	return template.HTML(p.Sprintf("%s", FormatNumberSeparators(strconv.FormatFloat(f, 'f', -1, 64))))
 }
 
-func FormatErc20Decimals(balance []byte, metadata *types.ERC20Metadata) decimal.Decimal {
-	decimals := new(big.Int).SetBytes(metadata.Decimals)
-	mul := decimal.NewFromFloat(float64(10)).Pow(decimal.NewFromBigInt(decimals, 0))
-	num := decimal.NewFromBigInt(new(big.Int).SetBytes(balance), 0)
+// FormatTokenUSDValue returns the USD value of a token amount at the given price, or nothing if the price of the token is unknown
+func FormatTokenUSDValue(balance *types.Eth1AddressBalance, price decimal.Decimal) template.HTML {
+	if price.IsZero() || balance.Metadata == nil {
+		return ""
+	}
+	value := FormatErc20Decimals(balance.Balance, balance.Metadata).Mul(price)
+	return template.HTML(fmt.Sprintf(`<span class="text-muted ml-1" data-toggle="tooltip" title="at the current price of $%s">($%s)</span>`, price.String(), FormatNumberSeparators(value.StringFixed(2))))
+}
 
-	return num.DivRound(mul, int32(decimals.Int64()))
+// FormatTokenBalancesUSDValue returns the summed USD value of the given token balances, tokens without a known price are not counted
+func FormatTokenBalancesUSDValue(balances []*types.Eth1AddressBalance) template.HTML {
+	total := decimal.Zero
+	for _, balance := range balances {
+		if balance.Metadata == nil || len(balance.Metadata.Price) == 0 {
+			continue
+		}
+		price, err := decimal.NewFromString(string(balance.Metadata.Price))
+		if err != nil {
+			continue
+		}
+		total = total.Add(FormatErc20Decimals(balance.Balance, balance.Metadata).Mul(price))
+	}
+	if total.IsZero() {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<span class="text-muted ml-1">($%s)</span>`, FormatNumberSeparators(total.StringFixed(2))))
+}
+
+// FormatTokenUSDValueAt returns the USD value of a token amount like FormatTokenUSDValue, the tooltip adds the value at the close
+// price of the given day. The value at the close price is shown if the current price of the token is unknown.
+func FormatTokenUSDValueAt(balance *types.Eth1AddressBalance, price, closePrice decimal.Decimal, day time.Time) template.HTML {
+	if closePrice.IsZero() || balance.Metadata == nil {
+		return FormatTokenUSDValue(balance, price)
+	}
+	amount := FormatErc20Decimals(balance.Balance, balance.Metadata)
+	value := amount.Mul(closePrice)
+	title := fmt.Sprintf("$%s at the close price of $%s on %s", FormatNumberSeparators(value.StringFixed(2)), closePrice.String(), day.UTC().Format("2006-01-02"))
+	if !price.IsZero() {
+		title = fmt.Sprintf("at the current price of $%s, %s", price.String(), title)
+		value = amount.Mul(price)
+	}
+	return template.HTML(fmt.Sprintf(`<span class="text-muted ml-1" data-toggle="tooltip" title="%s">($%s)</span>`, title, FormatNumberSeparators(value.StringFixed(2))))
+}
+
+func FormatErc20Decimals(balance []byte, metadata *types.ERC20Metadata) decimal.Decimal {
+	return types.TokenAmountFromBytes(balance, metadata).Decimal()
 }
 
 func FormatTokenName(balance *types.Eth1AddressBalance) template.HTML {
@@ -0,0 +1,308 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// responses of nft uris larger than these limits are not fetched
+	NFTMetadataMaxBytes = 1 << 20
+	NFTImageMaxBytes    = 10 << 20
+
+	// images with more pixels are not decoded to create a thumbnail
+	nftThumbnailMaxPixels = 25000000
+)
+
+var defaultIPFSGateways = []string{"https://ipfs.io/ipfs/", "https://cloudflare-ipfs.com/ipfs/", "https://dweb.link/ipfs/"}
+
+// the uris of nfts are chosen by the contract, so only public addresses may be connected to
+var nftHttpClient = &http.Client{
+	Timeout: time.Second * 15,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: time.Second * 5,
+			Control: dialPublicAddressesOnly,
+		}).DialContext,
+		TLSHandshakeTimeout: time.Second * 5,
+		MaxIdleConnsPerHost: 4,
+	},
+}
+
+// nonPublicNetworks are the special purpose ranges of the IANA IPv4 and IPv6 registries that are not globally reachable, and
+// the IPv6 ranges that embed IPv4 addresses. IPv4 mapped addresses are converted before they are checked
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",          // this network
+	"10.0.0.0/8",         // private
+	"100.64.0.0/10",      // shared address space (carrier grade nat)
+	"127.0.0.0/8",        // loopback
+	"169.254.0.0/16",     // link local
+	"172.16.0.0/12",      // private
+	"192.0.0.0/24",       // ietf protocol assignments
+	"192.0.2.0/24",       // documentation
+	"192.88.99.0/24",     // 6to4 relay anycast
+	"192.168.0.0/16",     // private
+	"198.18.0.0/15",      // benchmarking
+	"198.51.100.0/24",    // documentation
+	"203.0.113.0/24",     // documentation
+	"224.0.0.0/4",        // multicast
+	"240.0.0.0/4",        // reserved
+	"255.255.255.255/32", // limited broadcast
+	"::/128",             // unspecified
+	"::1/128",            // loopback
+	"64:ff9b::/96",       // ipv4/ipv6 translation
+	"64:ff9b:1::/48",     // local ipv4/ipv6 translation
+	"100::/64",           // discard only
+	"2001::/23",          // ietf protocol assignments (incl. teredo)
+	"2001:db8::/32",      // documentation
+	"2002::/16",          // 6to4
+	"fc00::/7",           // unique local
+	"fe80::/10",          // link local
+	"ff00::/8",           // multicast
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// isPublicIP returns whether ip is globally reachable, ipv4 mapped ipv6 addresses are checked as ipv4 addresses
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func dialPublicAddressesOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("refusing to connect to non public address %v", host)
+	}
+	return nil
+}
+
+func ipfsGateways() []string {
+	if len(Config.Frontend.NFTMetadata.IPFSGateways) > 0 {
+		return Config.Frontend.NFTMetadata.IPFSGateways
+	}
+	return defaultIPFSGateways
+}
+
+// NFTURICandidates returns the urls the uri of an nft or its image can be fetched from in the order they should be tried. Ipfs
+// uris and links to ipfs gateways are mapped to all configured gateways, arweave uris to the arweave gateway. Nil is returned
+// for uris that can not be fetched via http.
+func NFTURICandidates(uri string) []string {
+	uri = strings.TrimSpace(uri)
+	lower := strings.ToLower(uri)
+
+	ipfsPath := ""
+	candidates := []string{}
+	switch {
+	case strings.HasPrefix(lower, "ipfs://"):
+		ipfsPath = strings.TrimPrefix(uri[len("ipfs://"):], "ipfs/")
+	case strings.HasPrefix(lower, "ar://"):
+		return []string{"https://arweave.net/" + uri[len("ar://"):]}
+	case strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "http://"):
+		candidates = append(candidates, uri)
+		if i := strings.Index(uri, "/ipfs/"); i >= 0 {
+			ipfsPath = uri[i+len("/ipfs/"):]
+		}
+	default:
+		return nil
+	}
+
+	if ipfsPath != "" {
+		for _, gateway := range ipfsGateways() {
+			if candidate := strings.TrimSuffix(gateway, "/") + "/" + ipfsPath; candidate != uri {
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+	return candidates
+}
+
+// FetchNFTURI returns the content of the uri of an nft and its media type. Data uris are decoded, other uris are fetched from
+// the candidates of NFTURICandidates until one of them succeeds.
+func FetchNFTURI(ctx context.Context, uri string, maxBytes int64) ([]byte, string, error) {
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(uri)), "data:") {
+		return parseDataURI(strings.TrimSpace(uri), maxBytes)
+	}
+
+	candidates := NFTURICandidates(uri)
+	if len(candidates) == 0 {
+		return nil, "", fmt.Errorf("unsupported uri %q", uri)
+	}
+	var err error
+	for _, candidate := range candidates {
+		var data []byte
+		var mediaType string
+		data, mediaType, err = fetchNFTURL(ctx, candidate, maxBytes)
+		if err == nil {
+			return data, mediaType, nil
+		}
+	}
+	return nil, "", err
+}
+
+func fetchNFTURL(ctx context.Context, u string, maxBytes int64) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := nftHttpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("error fetching %v: %v", u, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("error fetching %v: response is larger than %v bytes", u, maxBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// parseDataURI decodes a data uri of the form data:[<mediatype>][;base64],<data>
+func parseDataURI(uri string, maxBytes int64) ([]byte, string, error) {
+	header, payload, found := strings.Cut(uri[len("data:"):], ",")
+	if !found {
+		return nil, "", fmt.Errorf("invalid data uri")
+	}
+
+	mediaType := header
+	var data []byte
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		mediaType = header[:len(header)-len(";base64")]
+		if int64(base64.StdEncoding.DecodedLen(len(payload))) > maxBytes+2 {
+			return nil, "", fmt.Errorf("data uri is larger than %v bytes", maxBytes)
+		}
+		var err error
+		data, err = base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			// some contracts omit the padding
+			data, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid base64 data uri: %w", err)
+			}
+		}
+	} else {
+		unescaped, err := url.PathUnescape(payload)
+		if err != nil {
+			// on chain metadata is often not escaped at all
+			unescaped = payload
+		}
+		data = []byte(unescaped)
+	}
+
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("data uri is larger than %v bytes", maxBytes)
+	}
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+	return data, mediaType, nil
+}
+
+// NFTThumbnail scales a png, jpeg or gif image down to fit into a square of the given size and encodes it as png, smaller images
+// keep their size. Images of other formats can not be decoded and return an error.
+func NFTThumbnail(data []byte, size int) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > nftThumbnailMaxPixels {
+		return nil, fmt.Errorf("image of %vx%v pixels is too large", config.Width, config.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w == 0 || h == 0 {
+		return nil, fmt.Errorf("empty image")
+	}
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+		if tw == 0 {
+			tw = 1
+		}
+		if th == 0 {
+			th = 1
+		}
+	}
+
+	// every pixel of the thumbnail is the average of the source pixels it covers
+	thumbnail := image.NewRGBA64(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := bounds.Min.Y+y*h/th, bounds.Min.Y+(y+1)*h/th
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < tw; x++ {
+			x0, x1 := bounds.Min.X+x*w/tw, bounds.Min.X+(x+1)*w/tw
+			if x1 == x0 {
+				x1++
+			}
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			thumbnail.SetRGBA64(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	err = png.Encode(buf, thumbnail)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"eth2-exporter/types"
+	"image"
+	"image/color"
+	"image/png"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNFTURICandidates(t *testing.T) {
+	Config = &types.Config{}
+	gateways := []string{"https://ipfs.io/ipfs/", "https://cloudflare-ipfs.com/ipfs/", "https://dweb.link/ipfs/"}
+	cid := "QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG/1.json"
+	tests := []struct {
+		uri  string
+		want []string
+	}{
+		{"ipfs://" + cid, []string{gateways[0] + cid, gateways[1] + cid, gateways[2] + cid}},
+		{"ipfs://ipfs/" + cid, []string{gateways[0] + cid, gateways[1] + cid, gateways[2] + cid}},
+		{"https://ipfs.io/ipfs/" + cid, []string{gateways[0] + cid, gateways[1] + cid, gateways[2] + cid}},
+		{"https://gateway.pinata.cloud/ipfs/" + cid, []string{"https://gateway.pinata.cloud/ipfs/" + cid, gateways[0] + cid, gateways[1] + cid, gateways[2] + cid}},
+		{"ar://abc/1.json", []string{"https://arweave.net/abc/1.json"}},
+		{" https://example.com/1.json ", []string{"https://example.com/1.json"}},
+		{"file:///etc/passwd", nil},
+		{"1.json", nil},
+	}
+	for _, tt := range tests {
+		got := NFTURICandidates(tt.uri)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrong candidates for %q: got %v, want %v", tt.uri, got, tt.want)
+		}
+	}
+}
+
+func TestFetchNFTURIData(t *testing.T) {
+	tests := []struct {
+		uri       string
+		data      string
+		mediaType string
+	}{
+		{"data:application/json;base64,eyJuYW1lIjoiMSJ9", `{"name":"1"}`, "application/json"},
+		{"data:application/json;base64,eyJuYW1lIjoiMSJ", `{"name":"1"`, "application/json"},
+		{`data:application/json,{"name":"%231"}`, `{"name":"#1"}`, "application/json"},
+		{`data:application/json;utf8,{"name":"100%"}`, `{"name":"100%"}`, "application/json;utf8"},
+		{"data:,hello", "hello", "text/plain"},
+	}
+	for _, tt := range tests {
+		data, mediaType, err := FetchNFTURI(context.Background(), tt.uri, NFTMetadataMaxBytes)
+		if err != nil {
+			t.Errorf("error decoding %q: %v", tt.uri, err)
+			continue
+		}
+		if string(data) != tt.data || mediaType != tt.mediaType {
+			t.Errorf("wrong decoding of %q: got %q %q, want %q %q", tt.uri, data, mediaType, tt.data, tt.mediaType)
+		}
+	}
+
+	_, _, err := FetchNFTURI(context.Background(), "data:,hello", 4)
+	if err == nil {
+		t.Errorf("expected an error for a data uri larger than the limit")
+	}
+}
+
+func TestNFTThumbnail(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			src.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	buf := &bytes.Buffer{}
+	err := png.Encode(buf, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	thumbnail, err := NFTThumbnail(buf.Bytes(), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(thumbnail))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 100 || img.Bounds().Dy() != 50 {
+		t.Errorf("wrong thumbnail size: got %vx%v, want 100x50", img.Bounds().Dx(), img.Bounds().Dy())
+	}
+	if r, g, b, a := img.At(50, 25).RGBA(); r != 0xffff || g != 0 || b != 0 || a != 0xffff {
+		t.Errorf("wrong thumbnail color: got %v %v %v %v", r, g, b, a)
+	}
+
+	_, err = NFTThumbnail([]byte("<svg></svg>"), 100)
+	if err == nil {
+		t.Errorf("expected an error for an svg image")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"1.1.1.1", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"255.255.255.255", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+		{"::ffff:1.1.1.1", true},
+		{"64:ff9b::a00:1", false},
+		{"2002:a00:1::", false},
+		{"fd00::1", false},
+		{"::1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("isPublicIP(%v) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "sort"
+
+// PercentileRank returns the rank (0-100) of value within a distribution described by its evenly spaced percentiles
+// in ascending order, e.g. the 0th to 100th percentile. Values between two percentiles are interpolated linearly,
+// values matching a range of equal percentiles are ranked in the middle of that range.
+func PercentileRank(percentiles []float64, value float64) float64 {
+	n := len(percentiles)
+	if n < 2 {
+		return 0
+	}
+	step := 100 / float64(n-1)
+
+	first := sort.SearchFloat64s(percentiles, value)
+	last := sort.Search(n, func(i int) bool { return percentiles[i] > value }) - 1
+	switch {
+	case first <= last:
+		return float64(first+last) / 2 * step
+	case first == 0:
+		return 0
+	case first == n:
+		return 100
+	}
+
+	lower, upper := percentiles[last], percentiles[first]
+	return (float64(last) + (value-lower)/(upper-lower)) * step
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentileRank(t *testing.T) {
+	linear := make([]float64, 101)
+	for i := range linear {
+		linear[i] = float64(i * 10)
+	}
+	plateau := []float64{0, 0.5, 1, 1, 1}
+
+	tests := []struct {
+		name        string
+		percentiles []float64
+		value       float64
+		rank        float64
+	}{
+		{"empty", []float64{}, 5, 0},
+		{"below", linear, -1, 0},
+		{"above", linear, 2000, 100},
+		{"exact", linear, 500, 50},
+		{"interpolated", linear, 255, 25.5},
+		{"plateau", plateau, 1, 75},
+		{"below plateau", plateau, 0.75, 37.5},
+	}
+	for _, tt := range tests {
+		if rank := PercentileRank(tt.percentiles, tt.value); math.Abs(rank-tt.rank) > 1e-9 {
+			t.Errorf("wrong percentile rank for %v: got %v, expected %v", tt.name, rank, tt.rank)
+		}
+	}
+}
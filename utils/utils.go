@@ -19,6 +19,7 @@ import (
 	"log"
 	"math"
 	"math/big"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -26,6 +27,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -37,6 +39,7 @@ import (
 
 	"github.com/asaskevich/govalidator"
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/kataras/i18n"
@@ -122,6 +125,8 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatTimestamp":                         FormatTimestamp,
 		"formatTsWithoutTooltip":                  FormatTsWithoutTooltip,
 		"formatTimestampTs":                       FormatTimestampTs,
+		"formatTimestampTz":                       FormatTimestampTz,
+		"formatTimestampTsTz":                     FormatTimestampTsTz,
 		"formatTime":                              FormatTime,
 		"formatValidatorName":                     FormatValidatorName,
 		"formatAttestationInclusionEffectiveness": FormatAttestationInclusionEffectiveness,
@@ -134,6 +139,7 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatExchangedAmount":                   FormatExchangedAmount,
 		"formatBigAmount":                         FormatBigAmount,
 		"formatBytesAmount":                       FormatBytesAmount,
+		"formatTypedAmount":                       FormatTypedAmount,
 		"formatYesNo":                             FormatYesNo,
 		"formatAmountFormatted":                   FormatAmountFormatted,
 		"formatAddressAsLink":                     FormatAddressAsLink,
@@ -188,9 +194,10 @@ func GetTemplateFuncs() template.FuncMap {
 		"formatAddCommas": FormatAddCommas,
 		"encodeToString":  hex.EncodeToString,
 
-		"formatTokenBalance":      FormatTokenBalance,
-		"formatAddressEthBalance": FormatAddressEthBalance,
-		"toBase64":                ToBase64,
+		"formatTokenBalance":          FormatTokenBalance,
+		"formatAddressEthBalance":     FormatAddressEthBalance,
+		"formatTokenBalancesUSDValue": FormatTokenBalancesUSDValue,
+		"toBase64":                    ToBase64,
 		"bytesToNumberString": func(input []byte) string {
 			return new(big.Int).SetBytes(input).String()
 		},
@@ -214,9 +221,10 @@ func GetTemplateFuncs() template.FuncMap {
 			return num
 		},
 		// ETH1 related formatting
-		"formatEth1TxStatus":    FormatEth1TxStatus,
-		"formatTimestampUInt64": FormatTimestampUInt64,
-		"formatEth1AddressFull": FormatEth1AddressFull,
+		"formatEth1TxStatus":      FormatEth1TxStatus,
+		"formatTimestampUInt64":   FormatTimestampUInt64,
+		"formatTimestampUInt64Tz": FormatTimestampUInt64Tz,
+		"formatEth1AddressFull":   FormatEth1AddressFull,
 		"byteToString": func(num []byte) string {
 			return string(num)
 		},
@@ -536,6 +544,25 @@ func IsApiRequest(r *http.Request) bool {
 	return ok && len(query) > 0 && query[0] == "json"
 }
 
+// ClientIp returns the ip of the client of a request. Behind a reverse proxy it is taken from the header configured as clientIpHeader,
+// of a list (X-Forwarded-For) only the last entry is used as that is the one the proxy appended, the entries before it are sent by the client.
+// The remote address of the connection is returned if no header is configured or it does not contain an ip.
+func ClientIp(r *http.Request) string {
+	if header := Config.Frontend.ClientIpHeader; header != "" {
+		if values := r.Header.Values(header); len(values) > 0 {
+			entries := strings.Split(values[len(values)-1], ",")
+			if ip := net.ParseIP(strings.TrimSpace(entries[len(entries)-1])); ip != nil {
+				return ip.String()
+			}
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
 var eth1AddressRE = regexp.MustCompile("^(0x)?[0-9a-fA-F]{40}$")
 var withdrawalCredentialsRE = regexp.MustCompile("^(0x)?00[0-9a-fA-F]{62}$")
 var withdrawalCredentialsAddressRE = regexp.MustCompile("^(0x)?010000000000000000000000[0-9a-fA-F]{40}$")
@@ -830,52 +857,91 @@ func ElementExists(arr []string, el string) bool {
 	return false
 }
 
+// TryFetchContractMetadata fetches the verified source and abi of a contract from sourcify and, if an etherscan api is configured, from etherscan
 func TryFetchContractMetadata(address []byte) (*types.ContractMetadata, error) {
+	meta, err := getABIFromSourcify(address)
+	if err != nil {
+		logger.Warnf("error fetching contract metadata of 0x%x from sourcify: %v", address, err)
+	}
+	if meta != nil {
+		return meta, nil
+	}
 	return getABIFromEtherscan(address)
 }
 
-// func getABIFromSourcify(address []byte) (*types.ContractMetadata, error) {
-// 	httpClient := http.Client{
-// 		Timeout: time.Second * 5,
-// 	}
-
-// 	resp, err := httpClient.Get(fmt.Sprintf("https://sourcify.dev/server/repository/contracts/full_match/%d/0x%x/metadata.json", 1, address))
-// 	if err != nil {
-// 		return nil, err
-// 	}
-
-// 	if resp.StatusCode == 200 {
-// 		body, err := ioutil.ReadAll(resp.Body)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-
-// 		data := &types.SourcifyContractMetadata{}
-// 		err = json.Unmarshal(body, data)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-
-// 		abiString, err := json.Marshal(data.Output.Abi)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-
-// 		contractAbi, err := abi.JSON(bytes.NewReader(abiString))
-// 		if err != nil {
-// 			return nil, err
-// 		}
-
-// 		meta := &types.ContractMetadata{}
-// 		meta.ABIJson = abiString
-// 		meta.ABI = &contractAbi
-// 		meta.Name = ""
-
-// 		return meta, nil
-// 	} else {
-// 		return nil, fmt.Errorf("sourcify contract code not found")
-// 	}
-// }
+func GetSourcifyAPIBaseUrl() string {
+	if len(Config.SourcifyAPIBaseURL) > 0 {
+		return Config.SourcifyAPIBaseURL
+	}
+	return "https://sourcify.dev/server"
+}
+
+// getABIFromSourcify fetches the files of a contract that has been verified by sourcify, full matches are preferred over partial matches
+func getABIFromSourcify(address []byte) (*types.ContractMetadata, error) {
+	httpClient := http.Client{Timeout: time.Second * 5}
+	resp, err := httpClient.Get(fmt.Sprintf("%s/files/any/%d/%s", GetSourcifyAPIBaseUrl(), Config.Chain.Config.DepositChainID, common.BytesToAddress(address).Hex()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, ErrRateLimit
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("StatusCode: '%d', Status: '%s'", resp.StatusCode, resp.Status)
+	}
+
+	files := &types.SourcifyFilesResponse{}
+	err = json.NewDecoder(resp.Body).Decode(files)
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &types.ContractVerification{
+		Source: "sourcify",
+		Match:  files.Status,
+		Files:  make([]types.ContractSourceFile, 0, len(files.Files)),
+	}
+	var data *types.SourcifyContractMetadata
+	for _, file := range files.Files {
+		switch file.Name {
+		case "metadata.json":
+			data = &types.SourcifyContractMetadata{}
+			err = json.Unmarshal([]byte(file.Content), data)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding sourcify metadata: %w", err)
+			}
+		case "constructor-args.txt":
+			verification.ConstructorArgs = common.FromHex(strings.TrimSpace(file.Content))
+		default:
+			if strings.Contains(file.Path, "/sources/") {
+				verification.Files = append(verification.Files, types.ContractSourceFile{Path: file.Path[strings.Index(file.Path, "/sources/")+len("/sources/"):], Content: file.Content})
+			}
+		}
+	}
+	if data == nil {
+		return nil, fmt.Errorf("sourcify files of 0x%x do not contain a metadata.json", address)
+	}
+	verification.CompilerVersion = data.Compiler.Version
+
+	contractAbi, err := abi.JSON(bytes.NewReader(data.Output.Abi))
+	if err != nil {
+		return nil, err
+	}
+	meta := &types.ContractMetadata{
+		ABIJson:      data.Output.Abi,
+		ABI:          &contractAbi,
+		Verification: verification,
+	}
+	for _, name := range data.Settings.CompilationTarget {
+		meta.Name = name
+	}
+	return meta, nil
+}
 
 func GetEtherscanAPIBaseUrl(provideDefault bool) string {
 	const mainnetBaseUrl = "api.etherscan.io"
@@ -957,9 +1023,49 @@ func getABIFromEtherscan(address []byte) (*types.ContractMetadata, error) {
 	meta.ABIJson = []byte(data.Result[0].Abi)
 	meta.ABI = &contractAbi
 	meta.Name = data.Result[0].ContractName
+	meta.Verification = &types.ContractVerification{
+		Source:          "etherscan",
+		CompilerVersion: data.Result[0].CompilerVersion,
+		ConstructorArgs: common.FromHex(data.Result[0].ConstructorArguments),
+		Files:           etherscanSourceFiles(data.Result[0].ContractName, data.Result[0].SourceCode),
+	}
 	return meta, nil
 }
 
+// etherscanSourceFiles splits the source code returned by etherscan into its files. Multi file contracts are returned as
+// solidity standard json input wrapped in an additional pair of braces or as a plain map of their sources.
+func etherscanSourceFiles(name, sourceCode string) []types.ContractSourceFile {
+	sources := map[string]struct {
+		Content string `json:"content"`
+	}{}
+	if strings.HasPrefix(sourceCode, "{{") && strings.HasSuffix(sourceCode, "}}") {
+		input := &struct {
+			Sources json.RawMessage `json:"sources"`
+		}{}
+		if json.Unmarshal([]byte(sourceCode[1:len(sourceCode)-1]), input) != nil || json.Unmarshal(input.Sources, &sources) != nil {
+			sources = nil
+		}
+	} else if strings.HasPrefix(sourceCode, "{") {
+		if json.Unmarshal([]byte(sourceCode), &sources) != nil {
+			sources = nil
+		}
+	} else {
+		sources = nil
+	}
+
+	if len(sources) == 0 {
+		return []types.ContractSourceFile{{Path: name + ".sol", Content: sourceCode}}
+	}
+	files := make([]types.ContractSourceFile, 0, len(sources))
+	for path, source := range sources {
+		files = append(files, types.ContractSourceFile{Path: path, Content: source.Content})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files
+}
+
 func FormatThousandsEnglish(number string) string {
 	runes := []rune(number)
 	cnt := 0
@@ -1223,3 +1329,11 @@ func GetSigningDomain() ([]byte, error) {
 
 	return domain, err
 }
+
+// EscapeCSVCell prefixes cells that spreadsheet applications would evaluate as a formula with a quote
+func EscapeCSVCell(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
@@ -1,6 +1,12 @@
 package utils
 
 import (
+	"encoding/json"
+	"eth2-exporter/types"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -26,3 +32,105 @@ func TestIsValidUrl(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatNumberSeparators(t *testing.T) {
+	sep := `<span class="thousands-separator"></span>`
+	dec := `<span class="decimal-separator"><span>.</span></span>`
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"0", "0"},
+		{"123", "123"},
+		{"1234", "1" + sep + "234"},
+		{"1,234,567", "1" + sep + "234" + sep + "567"},
+		{"-1234.5", "-1" + sep + "234" + dec + "5"},
+		{"+0.00042", "+0" + dec + "00042"},
+		{"123456.789", "123" + sep + "456" + dec + "789"},
+	}
+	for _, tt := range tests {
+		got := FormatNumberSeparators(tt.number)
+		if got != tt.want {
+			t.Errorf("wrong formatting of %v: got %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestAmount(t *testing.T) {
+	wei, _ := new(big.Int).SetString("1234567891234567891234", 10)
+	amount := types.NewEtherAmount(wei)
+
+	if got := amount.In(types.Ether).String(); got != "1234.567891234567891234" {
+		t.Errorf("wrong ether value: got %v", got)
+	}
+	if got := amount.In(types.GWei).String(); got != "1234567891234.567891234" {
+		t.Errorf("wrong gwei value: got %v", got)
+	}
+
+	b, err := json.Marshal(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `"1234567891234567891234"` {
+		t.Errorf("expected the amount to be serialized as wei string, got %s", b)
+	}
+	decoded := types.NewEtherAmount(nil)
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded.BigInt().Cmp(wei) != 0 || decoded.Symbol != "ETH" {
+		t.Errorf("wrong decoded amount %v (%v)", decoded.BigInt(), err)
+	}
+
+	token := types.TokenAmountFromBytes(big.NewInt(1500000).Bytes(), &types.ERC20Metadata{Decimals: big.NewInt(6).Bytes(), Symbol: "<b>USDC"})
+	if got := token.String(); got != "1.5" {
+		t.Errorf("wrong token value: got %v", got)
+	}
+	if got := string(FormatTypedAmount(token, 2)); !strings.Contains(got, "&lt;b&gt;USDC") {
+		t.Errorf("expected the escaped symbol in %v", got)
+	}
+
+	for _, unit := range []string{"Wei", "GWei", "ETH", "Ether"} {
+		if got := string(FormatAmount(big.NewInt(1), unit, 2)); strings.Contains(got, "?") {
+			t.Errorf("unit %v is not resolved: %v", unit, got)
+		}
+	}
+}
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"USDC":                   "USDC",
+		"0x1234":                 "0x1234",
+		`=HYPERLINK("http://x")`: `'=HYPERLINK("http://x")`,
+		"+1":                     "'+1",
+		"-1":                     "'-1",
+		"@SUM(A1)":               "'@SUM(A1)",
+		"\tX":                    "'\tX",
+		"\rX":                    "'\rX",
+	}
+	for cell, want := range tests {
+		if got := EscapeCSVCell(cell); got != want {
+			t.Errorf("EscapeCSVCell(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestClientIp(t *testing.T) {
+	Config = &types.Config{}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 2.2.2.2")
+
+	if got := ClientIp(r); got != "10.0.0.1" {
+		t.Errorf("expected the remote address without a configured header, got %v", got)
+	}
+
+	// only the entry appended by the proxy is trusted
+	Config.Frontend.ClientIpHeader = "X-Forwarded-For"
+	if got := ClientIp(r); got != "2.2.2.2" {
+		t.Errorf("expected the last forwarded entry, got %v", got)
+	}
+
+	r.Header.Set("X-Forwarded-For", "not an ip")
+	if got := ClientIp(r); got != "10.0.0.1" {
+		t.Errorf("expected the remote address for an invalid header, got %v", got)
+	}
+}